@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hadi77ir/go-query/executor"
+	gormexec "github.com/hadi77ir/go-query/executors/gorm"
+	"github.com/hadi77ir/go-query/executors/memory"
+	"github.com/hadi77ir/go-query/executors/mongodb"
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// backendKind is the kind of data source a connection string names
+type backendKind int
+
+const (
+	backendJSON backendKind = iota
+	backendMongo
+	backendPostgres
+	backendSQLite
+)
+
+// connection is a parsed connection argument
+type connection struct {
+	kind backendKind
+	// target is the URI, DSN or file path passed to the driver
+	target string
+}
+
+// parseConnection recognizes the data source of a connection argument
+func parseConnection(conn string) (connection, error) {
+	switch {
+	case strings.HasPrefix(conn, "mongodb://"), strings.HasPrefix(conn, "mongodb+srv://"):
+		return connection{kind: backendMongo, target: conn}, nil
+	case strings.HasPrefix(conn, "postgres://"), strings.HasPrefix(conn, "postgresql://"):
+		return connection{kind: backendPostgres, target: conn}, nil
+	case strings.HasPrefix(conn, "sqlite://"):
+		return connection{kind: backendSQLite, target: strings.TrimPrefix(conn, "sqlite://")}, nil
+	case strings.HasPrefix(conn, "sqlite:"):
+		return connection{kind: backendSQLite, target: strings.TrimPrefix(conn, "sqlite:")}, nil
+	}
+	switch strings.ToLower(filepath.Ext(conn)) {
+	case ".json", ".jsonl", ".ndjson":
+		return connection{kind: backendJSON, target: conn}, nil
+	case ".db", ".sqlite", ".sqlite3":
+		return connection{kind: backendSQLite, target: conn}, nil
+	}
+	return connection{}, fmt.Errorf("unrecognized connection %q: use mongodb://, postgres://, sqlite: or a .json/.jsonl file", conn)
+}
+
+// defaultIDField is the ID field of the backend's records
+func (c connection) defaultIDField() string {
+	if c.kind == backendMongo {
+		return "_id"
+	}
+	return "id"
+}
+
+// open connects to the data source and returns an executor over table, with a
+// function releasing the connection
+func (c connection) open(ctx context.Context, table string, opts *query.ExecutorOptions) (executor.Executor, func(), error) {
+	if c.kind == backendJSON {
+		items, err := loadJSON(c.target)
+		if err != nil {
+			return nil, nil, err
+		}
+		return memory.NewExecutor(items, opts), func() {}, nil
+	}
+	if table == "" {
+		return nil, nil, errNeedsTable
+	}
+
+	if c.kind == backendMongo {
+		return openMongo(ctx, c.target, table, opts)
+	}
+
+	dialector := postgres.Open(c.target)
+	if c.kind == backendSQLite {
+		dialector = sqlite.Open(c.target)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, nil, err
+	}
+	closeFn := func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+	return gormexec.NewExecutor(db.Table(table), opts), closeFn, nil
+}
+
+// openMongo connects to uri and returns an executor over the collection of
+// the URI's database
+func openMongo(ctx context.Context, uri, collection string, opts *query.ExecutorOptions) (executor.Executor, func(), error) {
+	cs, err := connstring.ParseAndValidate(uri)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cs.Database == "" {
+		return nil, nil, fmt.Errorf("connection %q names no database, e.g. mongodb://localhost:27017/shop", uri)
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, nil, err
+	}
+	closeFn := func() {
+		client.Disconnect(context.Background())
+	}
+	coll := client.Database(cs.Database).Collection(collection)
+	return mongodb.NewExecutor(coll, opts), closeFn, nil
+}
+
+// loadJSON reads a JSON array of objects, or one object per line for
+// .jsonl and .ndjson files
+func loadJSON(path string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	items := []map[string]interface{}{}
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("%s: expected an array of objects: %w", path, err)
+		}
+		return items, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var item map[string]interface{}
+		if err := json.Unmarshal(text, &item); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
@@ -0,0 +1,48 @@
+module github.com/hadi77ir/go-query/cmd/goquery
+
+go 1.24.0
+
+require (
+	github.com/hadi77ir/go-query v1.4.0
+	github.com/hadi77ir/go-query/executors/gorm v1.4.0
+	github.com/hadi77ir/go-query/executors/memory v1.4.0
+	github.com/hadi77ir/go-query/executors/mongodb v1.4.0
+	github.com/stretchr/testify v1.11.1
+	go.mongodb.org/mongo-driver v1.13.1
+	gorm.io/driver/postgres v1.6.0
+	gorm.io/driver/sqlite v1.6.0
+	gorm.io/gorm v1.31.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fxamacker/cbor/v2 v2.9.0 // indirect
+	github.com/golang/snappy v0.0.4 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/pgx/v5 v5.6.0 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.32 // indirect
+	github.com/montanaflynn/stats v0.7.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	github.com/youmark/pkcs8 v0.0.0-20201027041543-1326539a0a0a // indirect
+	golang.org/x/crypto v0.37.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	golang.org/x/text v0.30.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/hadi77ir/go-query => ../..
+
+replace github.com/hadi77ir/go-query/executors/gorm => ../../executors/gorm
+
+replace github.com/hadi77ir/go-query/executors/memory => ../../executors/memory
+
+replace github.com/hadi77ir/go-query/executors/mongodb => ../../executors/mongodb
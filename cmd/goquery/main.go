@@ -0,0 +1,174 @@
+// Command goquery runs a query against a database or a JSON file and prints
+// what it returns, e.g. to debug what a user's query matches.
+//
+// Usage:
+//
+//	goquery [flags] <connection> <query>
+//
+// The connection is one of:
+//
+//	mongodb://host:27017/db     MongoDB (mongodb+srv:// too); -table names the collection
+//	postgres://user@host/db     PostgreSQL; -table names the table
+//	sqlite:path/to/file.db      SQLite; -table names the table
+//	path/to/items.json          a JSON array of objects, or JSON Lines (.jsonl, .ndjson)
+//
+// With -format json one page is printed with its result metadata; pass the
+// next_page_cursor to -cursor for the next page. -format csv and jsonl follow
+// the cursors and write every matching item, up to -limit rows.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/export"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+)
+
+var (
+	table    = flag.String("table", "", "collection or table to query (MongoDB and SQL connections)")
+	format   = flag.String("format", "json", "output format: json, jsonl or csv")
+	fields   = flag.String("fields", "", "comma-separated fields to output (jsonl and csv)")
+	limit    = flag.Int("limit", 0, "maximum rows to write with jsonl and csv, 0 for all")
+	cursor   = flag.String("cursor", "", "cursor of the page to start at")
+	idField  = flag.String("id", "", `ID field used for cursors (default "_id" for MongoDB, "id" otherwise)`)
+	count    = flag.Bool("count", false, "print the number of matching items instead of the items")
+	explain  = flag.Bool("explain", false, "include the query plan (json)")
+	pageSize = flag.Int("max-page-size", 1000, "largest page_size the query may ask for")
+	timeout  = flag.Duration("timeout", 30*time.Second, "time limit for connecting and running the query")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: goquery [flags] <connection> <query>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	err := run(ctx, flag.Arg(0), flag.Arg(1), os.Stdout)
+	cancel()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "goquery:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, conn, text string, w io.Writer) error {
+	outFormat, err := parseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	q, err := parser.NewParserCache(1).Parse(text)
+	if err != nil {
+		return err
+	}
+	q.ExplainRequested = *explain
+
+	backend, err := parseConnection(conn)
+	if err != nil {
+		return err
+	}
+	opts := query.DefaultExecutorOptions()
+	// A query without matches prints an empty page or export, not an error
+	opts.AllowEmptyResults = true
+	opts.MaxPageSize = *pageSize
+	opts.IDFieldName = backend.defaultIDField()
+	if *idField != "" {
+		opts.IDFieldName = *idField
+	}
+	opts.DefaultSortField = opts.IDFieldName
+
+	exec, closeFn, err := backend.open(ctx, *table, opts)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	if *count {
+		n, err := exec.Count(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, n)
+		return err
+	}
+	if outFormat == formatJSON {
+		return writePage(ctx, w, exec, q)
+	}
+
+	exportOpts := &export.Options{Limit: *limit, Cursor: *cursor}
+	if outFormat == formatJSONL {
+		exportOpts.Format = export.FormatJSONL
+	}
+	if *fields != "" {
+		exportOpts.Fields = strings.Split(*fields, ",")
+	}
+	_, err = export.Export[map[string]interface{}](ctx, exec, q, w, exportOpts)
+	return err
+}
+
+// outputFormat is the -format of the command
+type outputFormat int
+
+const (
+	formatJSON outputFormat = iota
+	formatJSONL
+	formatCSV
+)
+
+func parseFormat(s string) (outputFormat, error) {
+	switch strings.ToLower(s) {
+	case "json":
+		return formatJSON, nil
+	case "jsonl", "ndjson":
+		return formatJSONL, nil
+	case "csv":
+		return formatCSV, nil
+	default:
+		return formatJSON, fmt.Errorf("unknown format %q: use json, jsonl or csv", s)
+	}
+}
+
+// page is the output of -format json
+type page struct {
+	Items  []map[string]interface{} `json:"items"`
+	Result *query.Result            `json:"result"`
+	Error  string                   `json:"error,omitempty"`
+}
+
+// writePage executes one page of q and prints it as indented JSON
+func writePage(ctx context.Context, w io.Writer, exec executor.Executor, q *query.Query) error {
+	out := page{Items: []map[string]interface{}{}}
+	result, err := exec.Execute(ctx, q, *cursor, &out.Items)
+	if err != nil {
+		return err
+	}
+	if out.Items == nil {
+		out.Items = []map[string]interface{}{}
+	}
+	out.Result = result
+	if result.Error != nil {
+		out.Error = result.Error.Error()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// errNeedsTable is returned when a database connection is given without -table
+var errNeedsTable = errors.New("-table is required for database connections")
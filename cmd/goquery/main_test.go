@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConnection(t *testing.T) {
+	tests := []struct {
+		conn   string
+		kind   backendKind
+		target string
+	}{
+		{"mongodb://localhost:27017/shop", backendMongo, "mongodb://localhost:27017/shop"},
+		{"postgresql://app@db/shop", backendPostgres, "postgresql://app@db/shop"},
+		{"sqlite:data/shop.db", backendSQLite, "data/shop.db"},
+		{"sqlite:///tmp/shop.db", backendSQLite, "/tmp/shop.db"},
+		{"shop.sqlite3", backendSQLite, "shop.sqlite3"},
+		{"products.JSONL", backendJSON, "products.JSONL"},
+	}
+	for _, tt := range tests {
+		c, err := parseConnection(tt.conn)
+		require.NoError(t, err, tt.conn)
+		assert.Equal(t, tt.kind, c.kind, tt.conn)
+		assert.Equal(t, tt.target, c.target, tt.conn)
+	}
+
+	_, err := parseConnection("mysql://localhost/shop")
+	assert.Error(t, err)
+}
+
+func TestRun_JSONFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "products.jsonl")
+	require.NoError(t, os.WriteFile(file, []byte(`{"id": 1, "name": "Cable", "price": 9.5}
+{"id": 2, "name": "Charger", "price": 25}
+
+{"id": 3, "name": "Mouse", "price": 19.99}
+`), 0o644))
+	ctx := context.Background()
+
+	t.Run("csv follows cursors", func(t *testing.T) {
+		*format, *fields = "csv", "id,name"
+		defer func() { *format, *fields = "json", "" }()
+
+		var out bytes.Buffer
+		require.NoError(t, run(ctx, file, "price > 10 page_size = 1", &out))
+		assert.Equal(t, "id,name\n2,Charger\n3,Mouse\n", out.String())
+	})
+
+	t.Run("json prints one page", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, run(ctx, file, "sort_by = price sort_order = desc page_size = 1", &out))
+		assert.Contains(t, out.String(), `"name": "Charger"`)
+		assert.Contains(t, out.String(), `"total_items": 3`)
+		assert.Contains(t, out.String(), `"next_page_cursor": "`)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, run(ctx, file, "price > 100", &out))
+		assert.Contains(t, out.String(), `"items": []`)
+
+		*format, *fields = "csv", "id,name"
+		defer func() { *format, *fields = "json", "" }()
+		out.Reset()
+		require.NoError(t, run(ctx, file, "price > 100", &out))
+		assert.Equal(t, "id,name\n", out.String())
+
+		*format = "jsonl"
+		out.Reset()
+		require.NoError(t, run(ctx, file, "price > 100", &out))
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("count", func(t *testing.T) {
+		*count = true
+		defer func() { *count = false }()
+
+		var out bytes.Buffer
+		require.NoError(t, run(ctx, file, `name CONTAINS "a"`, &out))
+		assert.Equal(t, "2\n", out.String())
+	})
+
+	t.Run("database without table", func(t *testing.T) {
+		err := run(ctx, "sqlite:shop.db", "price > 10", &bytes.Buffer{})
+		assert.ErrorIs(t, err, errNeedsTable)
+	})
+}
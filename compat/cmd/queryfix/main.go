@@ -0,0 +1,98 @@
+// Command queryfix rewrites go-query v1 call sites that use the compat package
+// to the v2 API, where the cursor is an argument of Execute.
+//
+// Usage:
+//
+//	queryfix [-w] [-l] [path ...]
+//
+// Paths are Go files or directories, walked recursively; the default is the
+// current directory. Rewritten files are printed to standard output unless -w
+// is set. Uses that need manual migration are reported on standard error.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hadi77ir/go-query/compat"
+)
+
+var (
+	write = flag.Bool("w", false, "write result to (source) file instead of stdout")
+	list  = flag.Bool("l", false, "list files whose source is rewritten")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: queryfix [-w] [-l] [path ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	failed := false
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if file != path && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(file, ".go") {
+				return nil
+			}
+			return fixFile(file)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func fixFile(file string) error {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	out, notes, err := compat.Fix(file, src)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		fmt.Fprintln(os.Stderr, note)
+	}
+	if bytes.Equal(src, out) {
+		return nil
+	}
+	if *list {
+		fmt.Println(file)
+	}
+	if *write {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(file, out, info.Mode().Perm())
+	}
+	if !*list {
+		_, err = os.Stdout.Write(out)
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+// Package compat keeps v1 code, which passes the pagination cursor inside the
+// query, compiling against v2 executors, which take the cursor as an argument.
+// Large codebases can switch imports first and migrate call sites one at a time:
+//
+//	// v1
+//	q.Cursor = cursor
+//	result, err := exec.Execute(ctx, q, &users)
+//
+//	// compat
+//	result, err := compat.Adapt(exec).Execute(ctx, compat.WithCursor(q, cursor), &users)
+//
+//	// v2
+//	result, err := exec.Execute(ctx, q, cursor, &users)
+//
+// Fix rewrites compat calls to the v2 API; the queryfix command runs it over
+// source files like go fix.
+//
+// Deprecated: compat exists for migration only. Use executor.Executor and pass
+// the cursor to Execute.
+package compat
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Query is a v1 query: a v2 query with its pagination cursor
+//
+// Deprecated: Pass Query.Query and Query.Cursor to executor.Executor.Execute.
+type Query struct {
+	*query.Query
+
+	// Cursor is the pagination cursor, empty for the first page
+	Cursor string
+}
+
+// WithCursor returns q with a pagination cursor. q is not copied
+//
+// Deprecated: Pass the cursor to executor.Executor.Execute.
+func WithCursor(q *query.Query, cursor string) *Query {
+	return &Query{Query: q, Cursor: cursor}
+}
+
+// Executor is the v1 executor interface
+//
+// Deprecated: Use executor.Executor.
+type Executor interface {
+	// Execute runs the query from the page of q.Cursor and stores results in dest
+	Execute(ctx context.Context, q *Query, dest interface{}) (*query.Result, error)
+
+	// Count returns the total number of items matching the query
+	Count(ctx context.Context, q *Query) (int64, error)
+
+	// Name returns the name of this executor
+	Name() string
+
+	// Close cleans up any resources used by the executor
+	Close() error
+}
+
+// Adapt exposes a v2 executor through the v1 interface
+//
+// Deprecated: Use the v2 executor directly.
+func Adapt(e executor.Executor) Executor {
+	return &adapter{inner: e}
+}
+
+// Unwrap returns the v2 executor behind e, or nil when e was not created by Adapt
+func Unwrap(e Executor) executor.Executor {
+	if a, ok := e.(*adapter); ok {
+		return a.inner
+	}
+	return nil
+}
+
+// Execute runs a v1 query on a v2 executor
+//
+// Deprecated: Use e.Execute(ctx, q.Query, q.Cursor, dest).
+func Execute(ctx context.Context, e executor.Executor, q *Query, dest interface{}) (*query.Result, error) {
+	return e.Execute(ctx, q.unwrap(), q.cursor(), dest)
+}
+
+// Count counts the items matching a v1 query on a v2 executor
+//
+// Deprecated: Use e.Count(ctx, q.Query).
+func Count(ctx context.Context, e executor.Executor, q *Query) (int64, error) {
+	return e.Count(ctx, q.unwrap())
+}
+
+// adapter implements Executor over a v2 executor
+type adapter struct {
+	inner executor.Executor
+}
+
+func (a *adapter) Execute(ctx context.Context, q *Query, dest interface{}) (*query.Result, error) {
+	return Execute(ctx, a.inner, q, dest)
+}
+
+func (a *adapter) Count(ctx context.Context, q *Query) (int64, error) {
+	return Count(ctx, a.inner, q)
+}
+
+func (a *adapter) Name() string {
+	return a.inner.Name()
+}
+
+func (a *adapter) Close() error {
+	return a.inner.Close()
+}
+
+// unwrap returns the v2 query of q; a nil q is passed on as a nil query
+func (q *Query) unwrap() *query.Query {
+	if q == nil {
+		return nil
+	}
+	return q.Query
+}
+
+func (q *Query) cursor() string {
+	if q == nil {
+		return ""
+	}
+	return q.Cursor
+}
@@ -0,0 +1,76 @@
+package compat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingExecutor records the arguments of the last call
+type recordingExecutor struct {
+	q      *query.Query
+	cursor string
+	closed bool
+}
+
+func (r *recordingExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	r.q, r.cursor = q, cursor
+	return &query.Result{TotalItems: 7}, nil
+}
+
+func (r *recordingExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	r.q, r.cursor = q, ""
+	return 3, nil
+}
+
+func (r *recordingExecutor) Name() string { return "recording" }
+
+func (r *recordingExecutor) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestAdapt(t *testing.T) {
+	inner := &recordingExecutor{}
+	exec := Adapt(inner)
+	q := &query.Query{Filter: query.Eq("name", "Alice")}
+
+	var dest []struct{}
+	result, err := exec.Execute(context.Background(), WithCursor(q, "abc"), &dest)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), result.TotalItems)
+	assert.Same(t, q, inner.q)
+	assert.Equal(t, "abc", inner.cursor)
+
+	count, err := exec.Count(context.Background(), &Query{Query: q, Cursor: "ignored"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Same(t, q, inner.q)
+
+	_, err = exec.Execute(context.Background(), nil, &dest)
+	require.NoError(t, err)
+	assert.Nil(t, inner.q)
+	assert.Equal(t, "", inner.cursor)
+
+	assert.Equal(t, "recording", exec.Name())
+	require.NoError(t, exec.Close())
+	assert.True(t, inner.closed)
+	assert.Same(t, inner, Unwrap(exec).(*recordingExecutor))
+}
+
+func TestExecuteAndCount(t *testing.T) {
+	inner := &recordingExecutor{}
+	q := &query.Query{}
+
+	_, err := Execute(context.Background(), inner, WithCursor(q, "next"), nil)
+	require.NoError(t, err)
+	assert.Same(t, q, inner.q)
+	assert.Equal(t, "next", inner.cursor)
+
+	count, err := Count(context.Background(), inner, WithCursor(q, "next"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
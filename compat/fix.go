@@ -0,0 +1,265 @@
+package compat
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+// ImportPath is the import path of this package
+const ImportPath = "github.com/hadi77ir/go-query/compat"
+
+// Note reports a use of this package that Fix could not rewrite
+type Note struct {
+	Pos     token.Position
+	Message string
+}
+
+func (n Note) String() string {
+	return fmt.Sprintf("%s: %s", n.Pos, n.Message)
+}
+
+// Fix rewrites calls of a Go source file from this package to the v2 API:
+//
+//	compat.Execute(ctx, e, compat.WithCursor(q, c), dest) => e.Execute(ctx, q, c, dest)
+//	compat.Adapt(e).Execute(ctx, &compat.Query{Query: q}, dest) => e.Execute(ctx, q, "", dest)
+//	compat.Count(ctx, e, v1q) => e.Count(ctx, v1q.Query)
+//
+// Fix works on syntax only, like go fix: other uses, such as variables of type
+// Executor, are reported as notes for manual migration. The import is removed
+// once nothing uses it. Files that do not import this package are returned unchanged
+func Fix(filename string, src []byte) ([]byte, []Note, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	name, spec := importName(file)
+	if spec == nil {
+		return src, nil, nil
+	}
+	if name == "." || name == "_" {
+		return src, []Note{{Pos: fset.Position(spec.Pos()), Message: "dot and blank imports of compat are not rewritten"}}, nil
+	}
+
+	f := &fixer{name: name}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			f.rewrite(call)
+		}
+		return true
+	})
+
+	var notes []Note
+	used := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := f.qualified(n); ok {
+			used = true
+			if deprecated[sel.Sel.Name] {
+				notes = append(notes, Note{Pos: fset.Position(sel.Pos()), Message: fmt.Sprintf("%s.%s needs manual migration", name, sel.Sel.Name)})
+			}
+		}
+		return true
+	})
+	if !f.changed {
+		return src, notes, nil
+	}
+	if !used {
+		removeImport(file, spec)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), notes, nil
+}
+
+// importName returns the local name of this package in file and its import spec
+func importName(file *ast.File) (string, *ast.ImportSpec) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || path != ImportPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, spec
+		}
+		return "compat", spec
+	}
+	return "", nil
+}
+
+func removeImport(file *ast.File, spec *ast.ImportSpec) {
+	for i, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for j, s := range gen.Specs {
+			if s != spec {
+				continue
+			}
+			gen.Specs = append(gen.Specs[:j], gen.Specs[j+1:]...)
+			if len(gen.Specs) == 0 {
+				file.Decls = append(file.Decls[:i], file.Decls[i+1:]...)
+			}
+			break
+		}
+	}
+	for i, s := range file.Imports {
+		if s == spec {
+			file.Imports = append(file.Imports[:i], file.Imports[i+1:]...)
+			break
+		}
+	}
+}
+
+// deprecated lists the v1 API of this package
+var deprecated = map[string]bool{
+	"Query": true, "WithCursor": true, "Executor": true, "Adapt": true,
+	"Unwrap": true, "Execute": true, "Count": true,
+}
+
+type fixer struct {
+	name    string // local name of this package
+	changed bool
+}
+
+// qualified returns n as a selector of this package, e.g. compat.Execute
+func (f *fixer) qualified(n ast.Node) (*ast.SelectorExpr, bool) {
+	sel, ok := n.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	// Identifiers resolved to a local object shadow the package name
+	if !ok || ident.Name != f.name || ident.Obj != nil {
+		return nil, false
+	}
+	return sel, true
+}
+
+// call returns the arguments of n when it calls fn of this package
+func (f *fixer) call(n ast.Expr, fn string) ([]ast.Expr, bool) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok || call.Ellipsis.IsValid() {
+		return nil, false
+	}
+	sel, ok := f.qualified(call.Fun)
+	if !ok || sel.Sel.Name != fn {
+		return nil, false
+	}
+	return call.Args, true
+}
+
+// rewrite rewrites call in place when it is a v1 Execute or Count call
+func (f *fixer) rewrite(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || call.Ellipsis.IsValid() {
+		return
+	}
+	method := sel.Sel.Name
+	var exec ast.Expr
+	var args []ast.Expr
+	if _, ok := f.qualified(sel); ok {
+		// compat.Execute(ctx, e, q, dest), compat.Count(ctx, e, q)
+		if len(call.Args) < 2 {
+			return
+		}
+		exec = call.Args[1]
+		args = append([]ast.Expr{call.Args[0]}, call.Args[2:]...)
+	} else if adapted, ok := f.call(sel.X, "Adapt"); ok && len(adapted) == 1 {
+		// compat.Adapt(e).Execute(ctx, q, dest), compat.Adapt(e).Count(ctx, q)
+		exec = adapted[0]
+		args = call.Args
+	} else {
+		return
+	}
+
+	switch {
+	case method == "Execute" && len(args) == 3:
+		q, cursor, ok := f.split(args[1])
+		if !ok {
+			return
+		}
+		args = []ast.Expr{args[0], q, cursor, args[2]}
+	case method == "Count" && len(args) == 2:
+		q, _, ok := f.split(args[1])
+		if !ok {
+			return
+		}
+		args = []ast.Expr{args[0], q}
+	default:
+		return
+	}
+	call.Fun = &ast.SelectorExpr{X: primary(exec), Sel: &ast.Ident{NamePos: sel.Sel.NamePos, Name: method}}
+	call.Args = args
+	f.changed = true
+}
+
+// split returns the v2 query and cursor expressions of a v1 query expression
+func (f *fixer) split(q ast.Expr) (ast.Expr, ast.Expr, bool) {
+	if args, ok := f.call(q, "WithCursor"); ok && len(args) == 2 {
+		return args[0], args[1], true
+	}
+	if unary, ok := q.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		if lit, ok := unary.X.(*ast.CompositeLit); ok {
+			if typ, ok := f.qualified(lit.Type); ok && typ.Sel.Name == "Query" {
+				return f.fields(lit)
+			}
+		}
+	}
+	switch q.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.ParenExpr:
+		// Evaluating q twice has no side effects
+		base := primary(q)
+		return &ast.SelectorExpr{X: base, Sel: ast.NewIdent("Query")},
+			&ast.SelectorExpr{X: base, Sel: ast.NewIdent("Cursor")}, true
+	}
+	return nil, nil, false
+}
+
+// fields returns the Query and Cursor fields of a compat.Query literal
+func (f *fixer) fields(lit *ast.CompositeLit) (ast.Expr, ast.Expr, bool) {
+	var q, cursor ast.Expr = ast.NewIdent("nil"), &ast.BasicLit{Kind: token.STRING, Value: `""`}
+	for i, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				return nil, nil, false
+			}
+			switch key.Name {
+			case "Query":
+				q = kv.Value
+			case "Cursor":
+				cursor = kv.Value
+			default:
+				return nil, nil, false
+			}
+			continue
+		}
+		switch i {
+		case 0:
+			q = elt
+		case 1:
+			cursor = elt
+		default:
+			return nil, nil, false
+		}
+	}
+	return q, cursor, true
+}
+
+// primary parenthesizes expressions that cannot be the operand of a selector
+func primary(e ast.Expr) ast.Expr {
+	switch e.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr, *ast.ParenExpr, *ast.TypeAssertExpr:
+		return e
+	}
+	return &ast.ParenExpr{X: e}
+}
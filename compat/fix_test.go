@@ -0,0 +1,115 @@
+package compat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFix(t *testing.T) {
+	src := `package users
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/compat"
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+func list(ctx context.Context, exec executor.Executor, q *query.Query, cursor string) {
+	var users []User
+	// First page
+	compat.Execute(ctx, exec, compat.WithCursor(q, cursor), &users)
+	compat.Adapt(exec).Execute(ctx, &compat.Query{Query: q}, &users)
+	compat.Adapt(exec).Execute(ctx, &compat.Query{q, "abc"}, &users)
+	compat.Execute(ctx, <-execs, compat.WithCursor(q, ""), &users)
+	compat.Count(ctx, exec, compat.WithCursor(q, cursor))
+	compat.Adapt(exec).Count(ctx, compat.WithCursor(q, cursor))
+}
+`
+	expected := `package users
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+func list(ctx context.Context, exec executor.Executor, q *query.Query, cursor string) {
+	var users []User
+	// First page
+	exec.Execute(ctx, q, cursor, &users)
+	exec.Execute(ctx, q, "", &users)
+	exec.Execute(ctx, q, "abc", &users)
+	(<-execs).Execute(ctx, q, "", &users)
+	exec.Count(ctx, q)
+	exec.Count(ctx, q)
+}
+`
+	out, notes, err := Fix("users.go", []byte(src))
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestFix_ManualMigration(t *testing.T) {
+	src := `package users
+
+import (
+	"context"
+
+	v1 "github.com/hadi77ir/go-query/compat"
+)
+
+func list(ctx context.Context, exec v1.Executor, e executor.Executor, q *v1.Query, pages []*v1.Query) {
+	exec.Execute(ctx, q, nil)
+	v1.Execute(ctx, e, q, nil)
+	v1.Execute(ctx, e, pages[0], nil)
+	v1.Count(ctx, e, next())
+}
+`
+	expected := `package users
+
+import (
+	"context"
+
+	v1 "github.com/hadi77ir/go-query/compat"
+)
+
+func list(ctx context.Context, exec v1.Executor, e executor.Executor, q *v1.Query, pages []*v1.Query) {
+	exec.Execute(ctx, q, nil)
+	e.Execute(ctx, q.Query, q.Cursor, nil)
+	e.Execute(ctx, pages[0].Query, pages[0].Cursor, nil)
+	v1.Count(ctx, e, next())
+}
+`
+	out, notes, err := Fix("users.go", []byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	// The import is kept while v1 types are in use
+	var messages []string
+	for _, note := range notes {
+		messages = append(messages, note.String())
+	}
+	assert.Equal(t, []string{
+		"users.go:9:37: v1.Executor needs manual migration",
+		"users.go:9:74: v1.Query needs manual migration",
+		"users.go:9:93: v1.Query needs manual migration",
+		"users.go:13:2: v1.Count needs manual migration",
+	}, messages)
+}
+
+func TestFix_Unchanged(t *testing.T) {
+	src := []byte("package users\n\nimport  \"fmt\"\n\nfunc f() { fmt.Println() }\n")
+	out, notes, err := Fix("users.go", src)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+	assert.Equal(t, src, out)
+
+	_, _, err = Fix("users.go", []byte("package"))
+	assert.Error(t, err)
+}
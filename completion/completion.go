@@ -0,0 +1,469 @@
+// Package completion suggests what may be typed at a position of a partial
+// query: field names where a condition starts, the operators valid for the
+// field's type, values of enumerated fields and query options, and AND/OR
+// after a complete condition. It powers search boxes with autocomplete; the
+// lsp package serves the same completions to editors.
+//
+//	result := completion.Complete(`price >= 10 AND bra`, 19, &completion.Options{
+//	    Schema:          schema,
+//	    ExecutorOptions: execOpts, // offer only allowed fields and operators
+//	    Values: func(field, prefix string) []string {
+//	        if field == "brand" {
+//	            return brands.WithPrefix(prefix)
+//	        }
+//	        return nil
+//	    },
+//	})
+//	// result.Candidates: brand; typing it replaces input[result.Start:result.End]
+package completion
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Kind is the kind of a candidate
+type Kind int
+
+const (
+	// KindField is a field name
+	KindField Kind = iota
+	// KindOperator is a comparison operator
+	KindOperator
+	// KindValue is a value of a field or query option
+	KindValue
+	// KindKeyword is AND, OR, an array modifier or a query option
+	KindKeyword
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindOperator:
+		return "operator"
+	case KindValue:
+		return "value"
+	case KindKeyword:
+		return "keyword"
+	default:
+		return "field"
+	}
+}
+
+// Candidate is one completion
+type Candidate struct {
+	// Label is the text shown and inserted
+	Label string
+
+	// Kind is what the candidate is
+	Kind Kind
+
+	// Detail is the schema kind of a field, e.g. "float"
+	Detail string
+
+	// Documentation describes the candidate
+	Documentation string
+}
+
+// ValuesFunc returns the values offered for field, e.g. the enum values of a
+// status field or the brands in a catalog. prefix is what was typed of the
+// value, without quotes; returned values are filtered by it again
+type ValuesFunc func(field, prefix string) []string
+
+// Options configure completion
+type Options struct {
+	// Schema lists the fields offered and types them, so only the operators
+	// valid for a field are offered after it
+	Schema query.Schema
+
+	// Descriptions documents fields. Documented fields are offered too
+	Descriptions map[string]string
+
+	// ParserOptions are used to read the input, e.g. for keyword aliases
+	ParserOptions *parser.ParserOptions
+
+	// ExecutorOptions, if set, hides fields outside AllowedFields and
+	// operators FieldPolicy forbids
+	ExecutorOptions *query.ExecutorOptions
+
+	// Values returns the values offered after a field's operator. Bool
+	// fields are offered true and false without it
+	Values ValuesFunc
+}
+
+// Result holds the completions at a position
+type Result struct {
+	// Candidates are the completions, in the order fields, operators or
+	// values, then keywords
+	Candidates []Candidate
+
+	// Start and End are the byte offsets of the word being typed, which a
+	// chosen candidate replaces
+	Start, End int
+}
+
+// Query options that may appear in a query, with their documentation
+var queryOptions = map[string]string{
+	"sort_by":           "Field to sort by. `_score` sorts by relevance.",
+	"sort_order":        "Sort direction: `asc`, `desc` or `random`.",
+	"page_size":         "Number of items per page.",
+	"page":              "Page number to jump to; pages after it follow cursors.",
+	"limit":             "Maximum number of items returned across all pages.",
+	"preserve_in_order": "Return results in the order of the values of the IN condition.",
+	"include_deleted":   "Include soft-deleted rows, when the server allows it.",
+	"distinct":          "Drop results equal to an earlier result.",
+	"distinct_on":       "Return only the first result, in sort order, for each value of the field.",
+	"random_seed":       "Seed of `sort_order = random`; the same seed returns the same order.",
+}
+
+// Operators in completion order, with their documentation
+var operatorDocs = []struct {
+	op  query.ComparisonOperator
+	doc string
+}{
+	{query.OpEqual, "Equal to the value."},
+	{query.OpNotEqual, "Not equal to the value."},
+	{query.OpGreaterThan, "Greater than the value."},
+	{query.OpGreaterThanOrEqual, "Greater than or equal to the value."},
+	{query.OpLessThan, "Less than the value."},
+	{query.OpLessThanOrEqual, "Less than or equal to the value."},
+	{query.OpLike, "SQL LIKE pattern: `%` matches any text, `_` one character."},
+	{query.OpNotLike, "Does not match the LIKE pattern."},
+	{query.OpContains, "Contains the text (case-sensitive)."},
+	{query.OpIContains, "Contains the text (case-insensitive)."},
+	{query.OpStartsWith, "Starts with the text."},
+	{query.OpEndsWith, "Ends with the text."},
+	{query.OpRegex, "Matches the regular expression."},
+	{query.OpIn, "Equal to one of the listed values: `[a, b]`."},
+	{query.OpNotIn, "Equal to none of the listed values."},
+	{query.OpMatch, "Full-text match of all search terms."},
+}
+
+// Array modifiers offered after array fields, with their documentation
+var modifierDocs = []struct {
+	modifier query.ArrayModifier
+	doc      string
+}{
+	{query.ArrayModifierLength, "Compares the number of elements: `tags LENGTH > 3`."},
+	{query.ArrayModifierAny, "At least one element matches: `tags ANY = \"usb\"`."},
+	{query.ArrayModifierAll, "Contains all of the listed values: `tags ALL IN [a, b]`."},
+}
+
+// Documentation of the logical keywords
+const (
+	andDoc = "Both conditions must match."
+	orDoc  = "Either condition must match."
+)
+
+// OptionDoc documents a query option such as sort_by, or returns "" when name
+// is not an option
+func OptionDoc(name string) string {
+	return queryOptions[name]
+}
+
+// OperatorDoc documents a comparison operator
+func OperatorDoc(op query.ComparisonOperator) string {
+	for _, o := range operatorDocs {
+		if o.op == op {
+			return o.doc
+		}
+	}
+	return ""
+}
+
+// OptionName returns the query option name is read as, following the option
+// aliases of opts, and whether it is an option
+func OptionName(name string, opts *parser.ParserOptions) (string, bool) {
+	lower := strings.ToLower(name)
+	if opts != nil {
+		for alias, option := range opts.OptionAliases {
+			if strings.ToLower(alias) == lower {
+				lower = strings.ToLower(option)
+				break
+			}
+		}
+	}
+	_, ok := queryOptions[lower]
+	return lower, ok
+}
+
+// Complete returns the completions at cursor, a byte offset into input.
+// Nothing is offered inside an unterminated string
+func Complete(input string, cursor int, opts *Options) *Result {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	if cursor > len(input) {
+		cursor = len(input)
+	}
+
+	// The word being typed is replaced by the completion
+	start := cursor
+	for start > 0 && isWordByte(input[start-1]) {
+		start--
+	}
+	result := &Result{Start: start, End: cursor}
+	prefix := strings.ToLower(input[start:cursor])
+
+	tokens, err := lex(input[:start], opts.ParserOptions)
+	if err != nil {
+		// Inside an unterminated string or block comment
+		return result
+	}
+
+	var candidates []Candidate
+	c := completionContext(tokens)
+	switch c.state {
+	case stateField:
+		candidates = append(fieldCandidates(opts), optionCandidates()...)
+	case stateOperator:
+		if _, ok := OptionName(c.field, opts.ParserOptions); ok {
+			candidates = []Candidate{{Label: "=", Kind: KindOperator}}
+			break
+		}
+		candidates = operatorCandidates(c, opts)
+	case stateValue:
+		candidates = valueCandidates(c, input[start:cursor], opts)
+	case stateAfterValue:
+		candidates = append(keywordCandidates(), optionCandidates()...)
+	}
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate.Label), prefix) {
+			result.Candidates = append(result.Candidates, candidate)
+		}
+	}
+	return result
+}
+
+// lex tokenizes text up to the first lexer error
+func lex(text string, opts *parser.ParserOptions) ([]parser.Token, error) {
+	l, err := parser.NewLexerWithOptions(text, opts)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []parser.Token
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			return tokens, err
+		}
+		if tok.Type == parser.TokenEOF {
+			return tokens, nil
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+// completion states
+const (
+	stateField = iota
+	stateOperator
+	stateValue
+	stateAfterValue
+)
+
+// completion describes what may follow the tokens before the cursor
+type completion struct {
+	state    int
+	field    string              // field or option of the current condition
+	modifier query.ArrayModifier // LENGTH, ANY or ALL was typed after the field
+	negated  bool                // NOT was typed after the field
+	inList   bool                // inside [ ... ]
+	bracket  bool                // inside a bracket-quoted field name ["..."]
+}
+
+// completionContext walks the tokens before the cursor
+func completionContext(tokens []parser.Token) completion {
+	c := completion{state: stateField}
+	for _, tok := range tokens {
+		switch tok.Type {
+		case parser.TokenAnd, parser.TokenOr, parser.TokenLeftParen:
+			c = completion{state: stateField}
+		case parser.TokenNot:
+			if c.state == stateOperator {
+				c.negated = true
+			} else {
+				c = completion{state: stateField}
+			}
+		case parser.TokenQuotedIdentifier:
+			c = completion{state: stateOperator, field: tok.Value}
+		case parser.TokenIdentifier, parser.TokenString, parser.TokenNumber, parser.TokenPlaceholder, parser.TokenParameter:
+			switch {
+			case c.bracket:
+				c.field = tok.Value
+			case c.state == stateValue && c.inList:
+			case c.state == stateValue:
+				c.state = stateAfterValue
+			case c.state == stateOperator && c.modifier == query.ArrayModifierNone && !c.negated && isModifier(tok.Value):
+				c.modifier, _ = query.ParseArrayModifier(tok.Value)
+			case tok.Type == parser.TokenIdentifier:
+				// A new condition or query option (a bare term followed by another term)
+				c = completion{state: stateOperator, field: tok.Value}
+			default:
+				c.state = stateAfterValue
+			}
+		case parser.TokenLeftBracket:
+			if c.state == stateField || c.state == stateAfterValue {
+				// A bracket-quoted field name starts a new condition
+				c = completion{state: stateField, bracket: true}
+				break
+			}
+			c.state, c.inList = stateValue, true
+		case parser.TokenComma:
+			c.state = stateValue
+		case parser.TokenRightBracket:
+			if c.bracket {
+				c = completion{state: stateOperator, field: c.field}
+				break
+			}
+			c.state, c.inList = stateAfterValue, false
+		case parser.TokenRightParen:
+			c.state = stateAfterValue
+		default:
+			// Comparison operators
+			c.state = stateValue
+		}
+	}
+	return c
+}
+
+// knownFields returns the schema and documented field names, sorted, without
+// the fields ExecutorOptions does not allow
+func knownFields(opts *Options) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if seen[name] || (opts.ExecutorOptions != nil && !opts.ExecutorOptions.IsFieldAllowed(name)) {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range opts.Schema {
+		add(name)
+	}
+	for name := range opts.Descriptions {
+		add(name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fieldCandidates returns the documented and schema fields
+func fieldCandidates(opts *Options) []Candidate {
+	var candidates []Candidate
+	for _, name := range knownFields(opts) {
+		candidate := Candidate{Label: name, Kind: KindField, Documentation: opts.Descriptions[name]}
+		if kind, ok := opts.Schema[name]; ok {
+			candidate.Detail = kind.String()
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// optionCandidates returns the query options
+func optionCandidates() []Candidate {
+	names := make([]string, 0, len(queryOptions))
+	for name := range queryOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	candidates := make([]Candidate, len(names))
+	for i, name := range names {
+		candidates[i] = Candidate{Label: name, Kind: KindKeyword, Documentation: queryOptions[name]}
+	}
+	return candidates
+}
+
+// isModifier reports whether word is an array modifier keyword
+func isModifier(word string) bool {
+	_, ok := query.ParseArrayModifier(word)
+	return ok
+}
+
+// operatorCandidates returns the operators valid for the field's schema kind,
+// or for its array modifier, that FieldPolicy allows. Array fields are also
+// offered the modifiers
+func operatorCandidates(c completion, opts *Options) []Candidate {
+	kind, typed := opts.Schema[c.field]
+	var candidates []Candidate
+	for _, o := range operatorDocs {
+		if c.modifier != query.ArrayModifierNone {
+			if !c.modifier.AllowsOperator(o.op) {
+				continue
+			}
+		} else if typed && !kind.AllowsOperator(o.op) {
+			continue
+		}
+		if opts.ExecutorOptions != nil && opts.ExecutorOptions.CheckOperator(c.field, o.op) != nil {
+			continue
+		}
+		label := o.op.String()
+		if c.negated {
+			// Only NOT LIKE and NOT IN exist
+			if o.op != query.OpNotLike && o.op != query.OpNotIn {
+				continue
+			}
+			label = strings.TrimPrefix(label, "NOT ")
+		}
+		candidates = append(candidates, Candidate{Label: label, Kind: KindOperator, Documentation: o.doc})
+	}
+	if typed && kind == query.FieldKindArray && c.modifier == query.ArrayModifierNone && !c.negated {
+		for _, m := range modifierDocs {
+			candidates = append(candidates, Candidate{Label: m.modifier.String(), Kind: KindKeyword, Documentation: m.doc})
+		}
+	}
+	return candidates
+}
+
+// valueCandidates returns the values known for the current option or field
+func valueCandidates(c completion, prefix string, opts *Options) []Candidate {
+	switch strings.ToLower(c.field) {
+	case "sort_by":
+		return append(fieldCandidates(opts),
+			Candidate{Label: query.ScoreField, Kind: KindField, Documentation: "Relevance of MATCH conditions and bare search terms."},
+			Candidate{Label: query.MatchCountField, Kind: KindField, Documentation: "Number of CONTAINS and ICONTAINS conditions matched."})
+	case "sort_order":
+		return valueLabels("asc", "desc", "random")
+	case "preserve_in_order", "include_deleted", "distinct":
+		return valueLabels("true", "false")
+	case "distinct_on":
+		return fieldCandidates(opts)
+	}
+	if opts.Values != nil && c.modifier != query.ArrayModifierLength {
+		if values := opts.Values(c.field, prefix); len(values) > 0 {
+			return valueLabels(values...)
+		}
+	}
+	if kind, ok := opts.Schema[c.field]; ok && kind == query.FieldKindBool {
+		return valueLabels("true", "false")
+	}
+	return nil
+}
+
+func valueLabels(labels ...string) []Candidate {
+	candidates := make([]Candidate, len(labels))
+	for i, label := range labels {
+		candidates[i] = Candidate{Label: label, Kind: KindValue}
+	}
+	return candidates
+}
+
+func keywordCandidates() []Candidate {
+	return []Candidate{
+		{Label: "AND", Kind: KindKeyword, Documentation: andDoc},
+		{Label: "OR", Kind: KindKeyword, Documentation: orDoc},
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '.' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
@@ -0,0 +1,93 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchema = query.Schema{
+	"name":     query.FieldKindString,
+	"email":    query.FieldKindString,
+	"status":   query.FieldKindString,
+	"price":    query.FieldKindFloat,
+	"featured": query.FieldKindBool,
+}
+
+func labels(result *Result) []string {
+	var names []string
+	for _, candidate := range result.Candidates {
+		names = append(names, candidate.Label)
+	}
+	return names
+}
+
+func TestComplete(t *testing.T) {
+	opts := &Options{Schema: testSchema}
+
+	result := Complete(`price > 10 AND na`, 17, opts)
+	assert.Equal(t, []string{"name"}, labels(result))
+	assert.Equal(t, 15, result.Start)
+	assert.Equal(t, 17, result.End)
+	assert.Equal(t, KindField, result.Candidates[0].Kind)
+	assert.Equal(t, "string", result.Candidates[0].Detail)
+
+	// The cursor need not be at the end of the input
+	result = Complete(`pri > 10`, 3, opts)
+	assert.Equal(t, []string{"price"}, labels(result))
+	assert.Equal(t, 0, result.Start)
+
+	got := labels(Complete(`price `, 6, opts))
+	assert.Contains(t, got, ">=")
+	assert.NotContains(t, got, "CONTAINS")
+
+	assert.Equal(t, []string{"AND", "OR"}, labels(Complete(`price > 10 `, 11, &Options{}))[:2])
+	assert.Empty(t, Complete(`name = "unfinished `, 19, opts).Candidates)
+}
+
+func TestComplete_ExecutorOptions(t *testing.T) {
+	execOpts := query.DefaultExecutorOptions()
+	execOpts.AllowedFields = []string{"name", "email", "price"}
+	execOpts.FieldPolicy = map[string][]query.ComparisonOperator{
+		"email": {query.OpEqual, query.OpIn},
+	}
+	opts := &Options{Schema: testSchema, ExecutorOptions: execOpts}
+
+	got := labels(Complete(``, 0, opts))
+	assert.Contains(t, got, "name")
+	assert.NotContains(t, got, "featured")
+	assert.NotContains(t, got, "status")
+
+	assert.Equal(t, []string{"=", "IN"}, labels(Complete(`email `, 6, opts)))
+}
+
+func TestComplete_Values(t *testing.T) {
+	var gotField, gotPrefix string
+	opts := &Options{
+		Schema: testSchema,
+		Values: func(field, prefix string) []string {
+			gotField, gotPrefix = field, prefix
+			if field == "status" {
+				return []string{"active", "archived", "draft"}
+			}
+			return nil
+		},
+	}
+
+	result := Complete(`status = ar`, 11, opts)
+	assert.Equal(t, []string{"archived"}, labels(result))
+	assert.Equal(t, KindValue, result.Candidates[0].Kind)
+	assert.Equal(t, "status", gotField)
+	assert.Equal(t, "ar", gotPrefix)
+
+	assert.Equal(t, []string{"active", "archived", "draft"}, labels(Complete(`status IN ["draft", `, 20, opts)))
+	assert.Equal(t, []string{"true", "false"}, labels(Complete(`featured = `, 11, opts)))
+	assert.Empty(t, Complete(`name = `, 7, opts).Candidates)
+}
+
+func TestDocs(t *testing.T) {
+	assert.Equal(t, "Number of items per page.", OptionDoc("page_size"))
+	assert.Empty(t, OptionDoc("price"))
+	assert.Equal(t, "Starts with the text.", OperatorDoc(query.OpStartsWith))
+}
@@ -0,0 +1,75 @@
+package decorators
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// WithBaseFilter ANDs filter into every query before it reaches the wrapped executor.
+// Use it to enforce mandatory predicates such as tenant scoping or soft-delete flags.
+// The caller's query is not modified.
+func WithBaseFilter(filter query.Node) Decorator {
+	return func(inner executor.Executor) executor.Executor {
+		return &baseFilterExecutor{base: base{inner: inner}, filter: filter}
+	}
+}
+
+type baseFilterExecutor struct {
+	base
+	filter query.Node
+}
+
+// Execute runs the query with the base filter applied
+func (e *baseFilterExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	return e.inner.Execute(ctx, e.apply(q), cursor, dest)
+}
+
+// Count counts matching items with the base filter applied
+func (e *baseFilterExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	return e.inner.Count(ctx, e.apply(q))
+}
+
+// Facets counts facets with the base filter applied
+func (e *baseFilterExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	return executor.Facets(ctx, e.inner, e.apply(q), specs)
+}
+
+// DeleteWhere deletes matching items with the base filter applied
+func (e *baseFilterExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	return executor.DeleteWhere(ctx, e.inner, e.applyWrite(q))
+}
+
+// UpdateWhere updates matching items with the base filter applied
+func (e *baseFilterExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	return executor.UpdateWhere(ctx, e.inner, e.applyWrite(q), changes)
+}
+
+// applyWrite applies the base filter to queries with a filter of their own.
+// Queries without one are passed on as they are, so the wrapped executor
+// still rejects writes to every item in scope
+func (e *baseFilterExecutor) applyWrite(q *query.Query) *query.Query {
+	if q == nil || q.Filter == nil {
+		return q
+	}
+	return e.apply(q)
+}
+
+// apply returns a copy of q with the base filter ANDed in
+func (e *baseFilterExecutor) apply(q *query.Query) *query.Query {
+	if e.filter == nil {
+		return q
+	}
+	scoped := *q
+	if q.Filter == nil {
+		scoped.Filter = e.filter
+	} else {
+		scoped.Filter = &query.BinaryOpNode{
+			Operator: query.BinaryOpAnd,
+			Left:     e.filter,
+			Right:    q.Filter,
+		}
+	}
+	return &scoped
+}
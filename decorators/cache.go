@@ -0,0 +1,252 @@
+package decorators
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// MetadataCache is the Result.Metadata key under which WithCache records
+// whether a page came from the cache (CacheHit) or the executor (CacheMiss)
+const MetadataCache = "cache"
+
+// Cache statuses recorded under MetadataCache
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// Cache stores the entries of WithCacheStore. Implementations must be safe
+// for concurrent use. Entries hold the destination page as a slice value, so
+// caches shared between processes must serialize Page, e.g. with gob
+type Cache interface {
+	// Get returns a live entry, or false when key is missing or expired
+	Get(key string) (*CacheEntry, bool)
+
+	// Set stores entry under key for ttl
+	Set(key string, entry *CacheEntry, ttl time.Duration)
+
+	// Clear drops every entry. Call it when the underlying data changes:
+	//
+	//	store.OnChange(cache.Clear)
+	Clear()
+}
+
+// CacheEntry is a cached Execute or Count outcome
+type CacheEntry struct {
+	Result query.Result
+	Page   interface{} // copy of the destination slice (Execute only)
+	Count  int64       // Count only
+}
+
+// WithCache caches successful Execute and Count results for ttl in a MemoryCache
+// of maxEntries entries (0 means 1000); see WithCacheStore.
+func WithCache(ttl time.Duration, maxEntries int) Decorator {
+	return WithCacheStore(NewMemoryCache(maxEntries), ttl)
+}
+
+// WithCacheStore caches successful Execute and Count results in cache for ttl.
+// Entries are keyed on the executor name, the canonical filter (see
+// query.CanonicalFilter), sort, page size, page, limit, whether highlights are
+// requested, the cursor and the destination type, so one cache can serve
+// several executors. Cached pages are copied into dest, so callers never share
+// slices. Query metadata is not part of the key.
+// Queries with ExplainRequested bypass the cache, so their plan describes an
+// actual run. Queries with placeholders or relative times bypass it too: their
+// values depend on the request and the clock, which the key cannot see, so
+// one tenant's @tenant page is never served to another.
+//
+// Hits are served without calling the wrapped executor, so per-request checks
+// it runs, such as the FieldAuthorizer of query.ExecutorOptions, do not run on
+// them. Only cache executors whose authorization does not depend on the
+// request, or apply it in a decorator outside the cache.
+//
+// DeleteWhere and UpdateWhere through the decorator clear the cache. Clear it
+// yourself after writes that bypass it.
+func WithCacheStore(cache Cache, ttl time.Duration) Decorator {
+	return func(inner executor.Executor) executor.Executor {
+		return &cacheExecutor{base: base{inner: inner}, cache: cache, ttl: ttl}
+	}
+}
+
+type cacheExecutor struct {
+	base
+	cache Cache
+	ttl   time.Duration
+}
+
+// Execute returns a cached page if available, otherwise runs the query and caches the page
+func (e *cacheExecutor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.Elem().Kind() != reflect.Slice || !cacheable(q) || q.ExplainRequested {
+		return e.inner.Execute(ctx, q, cursorParam, dest)
+	}
+
+	key := fmt.Sprintf("execute|%s|%x|%d|%d|%d|%t|%s|%s", e.Name(), queryHash(q), q.PageSize, q.Page, q.Limit, q.HighlightRequested, cursorParam, destVal.Type())
+	if entry, ok := e.cache.Get(key); ok {
+		if page := reflect.ValueOf(entry.Page); page.Type() == destVal.Elem().Type() {
+			destVal.Elem().Set(copySlice(page))
+			return withCacheStatus(entry.Result, CacheHit), nil
+		}
+	}
+
+	result, err := e.inner.Execute(ctx, q, cursorParam, dest)
+	if err != nil || result == nil {
+		return result, err
+	}
+	e.cache.Set(key, &CacheEntry{Result: *result, Page: copySlice(destVal.Elem()).Interface()}, e.ttl)
+	return withCacheStatus(*result, CacheMiss), nil
+}
+
+// Count returns a cached count if available, otherwise counts and caches the result
+func (e *cacheExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	if !cacheable(q) {
+		return e.inner.Count(ctx, q)
+	}
+	key := fmt.Sprintf("count|%s|%x", e.Name(), queryHash(q))
+	if entry, ok := e.cache.Get(key); ok {
+		return entry.Count, nil
+	}
+
+	count, err := e.inner.Count(ctx, q)
+	if err != nil {
+		return count, err
+	}
+	e.cache.Set(key, &CacheEntry{Count: count}, e.ttl)
+	return count, nil
+}
+
+// Facets counts facets with the wrapped executor; facet counts are not cached
+func (e *cacheExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	return executor.Facets(ctx, e.inner, q, specs)
+}
+
+// DeleteWhere deletes matching items and clears the cache
+func (e *cacheExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	defer e.cache.Clear()
+	return executor.DeleteWhere(ctx, e.inner, q)
+}
+
+// UpdateWhere updates matching items and clears the cache
+func (e *cacheExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	defer e.cache.Clear()
+	return executor.UpdateWhere(ctx, e.inner, q, changes)
+}
+
+// cacheable reports whether the results of q can be cached: its filter has
+// no values resolved per request
+func cacheable(q *query.Query) bool {
+	return q != nil && !query.HasDynamicValues(q.Filter)
+}
+
+// queryHash hashes q with its filter in canonical form
+func queryHash(q *query.Query) uint64 {
+	return cursor.QueryHash(query.Normalize(q))
+}
+
+// MemoryCache is an in-process Cache holding a bounded number of entries
+type MemoryCache struct {
+	maxEntries int
+	now        func() time.Time // For testing
+
+	mu      sync.Mutex
+	entries map[string]*memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	entry     *CacheEntry
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a cache holding at most maxEntries entries (0 means 1000).
+// When full, expired entries are evicted first, then the one expiring soonest
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*memoryCacheEntry),
+	}
+}
+
+// Get returns a live entry
+func (c *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(cached.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return cached.entry, true
+}
+
+// Set stores an entry, evicting expired entries (or the one expiring soonest) when full
+func (c *MemoryCache) Set(key string, entry *CacheEntry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= c.maxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for k, v := range c.entries {
+			if !now.Before(v.expiresAt) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldestKey == "" || v.expiresAt.Before(oldest) {
+				oldestKey, oldest = k, v.expiresAt
+			}
+		}
+		if len(c.entries) >= c.maxEntries && oldestKey != "" {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = &memoryCacheEntry{entry: entry, expiresAt: now.Add(ttl)}
+}
+
+// Clear drops every entry
+func (c *MemoryCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*memoryCacheEntry)
+}
+
+// Len returns the number of entries, including expired ones not yet evicted
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// withCacheStatus returns a copy of result with status recorded in its metadata.
+// The metadata map is copied so cached entries are never modified
+func withCacheStatus(result query.Result, status string) *query.Result {
+	metadata := make(map[string]interface{}, len(result.Metadata)+1)
+	for k, v := range result.Metadata {
+		metadata[k] = v
+	}
+	metadata[MetadataCache] = status
+	result.Metadata = metadata
+	return &result
+}
+
+// copySlice returns a shallow copy of a slice value
+func copySlice(src reflect.Value) reflect.Value {
+	dst := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+	reflect.Copy(dst, src)
+	return dst
+}
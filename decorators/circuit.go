@@ -0,0 +1,122 @@
+package decorators
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// WithCircuitBreaker stops calling the wrapped executor after threshold consecutive
+// execution failures. While open, calls fail fast with query.ErrCircuitOpen.
+// After cooldown, a single trial call is let through; success closes the circuit,
+// failure re-opens it for another cooldown.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Decorator {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return func(inner executor.Executor) executor.Executor {
+		return &circuitExecutor{
+			base:      base{inner: inner},
+			threshold: threshold,
+			cooldown:  cooldown,
+			now:       time.Now,
+		}
+	}
+}
+
+type circuitExecutor struct {
+	base
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time // For testing
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// Execute runs the query unless the circuit is open
+func (e *circuitExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	if err := e.allow(); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	result, err := e.inner.Execute(ctx, q, cursor, dest)
+	e.record(err)
+	return result, err
+}
+
+// Count counts matching items unless the circuit is open
+func (e *circuitExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	if err := e.allow(); err != nil {
+		return 0, err
+	}
+	count, err := e.inner.Count(ctx, q)
+	e.record(err)
+	return count, err
+}
+
+// Facets counts facets unless the circuit is open
+func (e *circuitExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	if err := e.allow(); err != nil {
+		return nil, err
+	}
+	results, err := executor.Facets(ctx, e.inner, q, specs)
+	e.record(err)
+	return results, err
+}
+
+// DeleteWhere deletes matching items unless the circuit is open
+func (e *circuitExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	if err := e.allow(); err != nil {
+		return 0, err
+	}
+	affected, err := executor.DeleteWhere(ctx, e.inner, q)
+	e.record(err)
+	return affected, err
+}
+
+// UpdateWhere updates matching items unless the circuit is open
+func (e *circuitExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	if err := e.allow(); err != nil {
+		return 0, err
+	}
+	affected, err := executor.UpdateWhere(ctx, e.inner, q, changes)
+	e.record(err)
+	return affected, err
+}
+
+// allow returns ErrCircuitOpen if calls should not reach the wrapped executor
+func (e *circuitExecutor) allow() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.failures < e.threshold {
+		return nil
+	}
+	if e.trial || e.now().Sub(e.openedAt) < e.cooldown {
+		return query.ErrCircuitOpen
+	}
+	// Cooldown elapsed: let one trial call through
+	e.trial = true
+	return nil
+}
+
+// record updates the failure counter with the outcome of a call
+func (e *circuitExecutor) record(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.trial = false
+	if !IsExecutionFailure(err) {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures >= e.threshold {
+		e.openedAt = e.now()
+	}
+}
@@ -0,0 +1,64 @@
+// Package decorators provides composable wrappers around executor.Executor for
+// cross-cutting concerns such as retries, caching, metrics, auditing, hooks, circuit
+// breaking, rate and concurrency limiting, mandatory base filters and per-role
+// result masking.
+//
+// Decorators compose with Chain. The first decorator is the outermost one:
+//
+//	exec := decorators.Chain(inner,
+//	    decorators.WithAudit(auditLog),
+//	    decorators.WithMetrics(recorder),
+//	    decorators.WithCircuitBreaker(5, 30*time.Second),
+//	    decorators.WithRetry(3, 100*time.Millisecond, nil),
+//	    decorators.WithBaseFilter(tenantFilter),
+//	)
+package decorators
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Decorator wraps an executor with additional behavior
+type Decorator func(executor.Executor) executor.Executor
+
+// Chain applies decorators to inner. The first decorator becomes the outermost wrapper.
+func Chain(inner executor.Executor, decorators ...Decorator) executor.Executor {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		inner = decorators[i](inner)
+	}
+	return inner
+}
+
+// base forwards Name and Close to the wrapped executor
+// Decorators embed it so they stay transparent to callers. Facets, DeleteWhere
+// and UpdateWhere are not forwarded: each decorator implements them, so facet
+// counts and writes cannot bypass it
+type base struct {
+	inner executor.Executor
+}
+
+// Name returns the name of the wrapped executor
+func (b base) Name() string {
+	return b.inner.Name()
+}
+
+// Close closes the wrapped executor
+func (b base) Close() error {
+	return b.inner.Close()
+}
+
+// IsExecutionFailure reports whether err is a backend execution failure
+// (as opposed to validation errors or ErrNoRecordsFound).
+// Retry and circuit breaker decorators only react to execution failures by default.
+func IsExecutionFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var execErr *query.ExecutionError
+	return errors.As(err, &execErr) || errors.Is(err, query.ErrExecutionFailed) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
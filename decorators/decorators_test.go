@@ -0,0 +1,666 @@
+package decorators
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExecutor records calls and returns scripted errors
+type fakeExecutor struct {
+	mu        sync.Mutex
+	calls     int
+	errs      []error // returned in order, nil once exhausted
+	lastQuery *query.Query
+	closed    bool
+}
+
+func (f *fakeExecutor) nextErr() error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastQuery = q
+	if err := f.nextErr(); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if items, ok := dest.(*[]string); ok {
+		*items = []string{"a", "b"}
+	}
+	return &query.Result{ItemsReturned: 2, TotalItems: 2}, nil
+}
+
+func (f *fakeExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastQuery = q
+	if err := f.nextErr(); err != nil {
+		return 0, err
+	}
+	return 2, nil
+}
+
+func (f *fakeExecutor) Name() string { return "fake" }
+
+func (f *fakeExecutor) Close() error {
+	f.closed = true
+	return nil
+}
+
+var errBackend = query.NewExecutionError("execute query", errors.New("connection reset"))
+
+func TestChain(t *testing.T) {
+	var order []string
+	tag := func(name string) Decorator {
+		return WithAudit(func(ctx context.Context, event AuditEvent) {
+			order = append(order, name)
+		})
+	}
+
+	inner := &fakeExecutor{}
+	exec := Chain(inner, tag("outer"), tag("inner"))
+	_, err := exec.Count(context.Background(), &query.Query{})
+	require.NoError(t, err)
+
+	// Audit runs after the call returns, so the innermost decorator reports first
+	assert.Equal(t, []string{"inner", "outer"}, order)
+	assert.Equal(t, "fake", exec.Name())
+	require.NoError(t, exec.Close())
+	assert.True(t, inner.closed)
+}
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries execution failures", func(t *testing.T) {
+		inner := &fakeExecutor{errs: []error{errBackend, errBackend}}
+		exec := Chain(inner, WithRetry(3, 0, nil))
+
+		var items []string
+		_, err := exec.Execute(ctx, &query.Query{}, "", &items)
+		require.NoError(t, err)
+		assert.Equal(t, 3, inner.calls)
+	})
+
+	t.Run("gives up after attempts", func(t *testing.T) {
+		inner := &fakeExecutor{errs: []error{errBackend, errBackend, errBackend}}
+		exec := Chain(inner, WithRetry(2, 0, nil))
+
+		_, err := exec.Count(ctx, &query.Query{})
+		assert.ErrorIs(t, err, errBackend)
+		assert.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("does not retry validation errors", func(t *testing.T) {
+		inner := &fakeExecutor{errs: []error{query.FieldNotAllowedError("secret")}}
+		exec := Chain(inner, WithRetry(3, 0, nil))
+
+		_, err := exec.Count(ctx, &query.Query{})
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+		assert.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("stops on cancelled context", func(t *testing.T) {
+		inner := &fakeExecutor{errs: []error{errBackend, errBackend}}
+		exec := Chain(inner, WithRetry(3, time.Hour, nil))
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := exec.Count(cancelled, &query.Query{})
+		assert.ErrorIs(t, err, errBackend)
+		assert.Equal(t, 1, inner.calls)
+	})
+}
+
+func TestWithCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeExecutor{}
+	exec := Chain(inner, WithCache(time.Minute, 10))
+	q := &query.Query{Filter: &query.ComparisonNode{Field: "a", Operator: query.OpEqual, Value: query.IntValue(1)}}
+
+	var first []string
+	result, err := exec.Execute(ctx, q, "", &first)
+	require.NoError(t, err)
+	status, _ := result.GetMetadata(MetadataCache)
+	assert.Equal(t, CacheMiss, status)
+
+	var second []string
+	result, err = exec.Execute(ctx, q, "", &second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, []string{"a", "b"}, second)
+	assert.Equal(t, 2, result.ItemsReturned)
+	status, _ = result.GetMetadata(MetadataCache)
+	assert.Equal(t, CacheHit, status)
+
+	// Cached pages are copies
+	second[0] = "changed"
+	var third []string
+	_, err = exec.Execute(ctx, q, "", &third)
+	require.NoError(t, err)
+	assert.Equal(t, "a", third[0])
+
+	// Different cursor is a different entry
+	_, err = exec.Execute(ctx, q, "other-cursor", &third)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	// So is a different page
+	paged := *q
+	paged.Page = 3
+	_, err = exec.Execute(ctx, &paged, "", &third)
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.calls)
+
+	// And highlights
+	highlighted := *q
+	highlighted.HighlightRequested = true
+	_, err = exec.Execute(ctx, &highlighted, "", &third)
+	require.NoError(t, err)
+	assert.Equal(t, 4, inner.calls)
+
+	// Count is cached separately
+	_, _ = exec.Count(ctx, q)
+	_, _ = exec.Count(ctx, q)
+	assert.Equal(t, 5, inner.calls)
+
+	// Explained queries always run
+	explained := *q
+	explained.ExplainRequested = true
+	_, err = exec.Execute(ctx, &explained, "", &third)
+	require.NoError(t, err)
+	assert.Equal(t, 6, inner.calls)
+
+	// Placeholders resolve per request, so their queries always run
+	tenant := &query.Query{Filter: query.Eq("tenant_id", query.PlaceholderValue("tenant"))}
+	_, err = exec.Execute(ctx, tenant, "", &third)
+	require.NoError(t, err)
+	_, err = exec.Execute(ctx, tenant, "", &third)
+	require.NoError(t, err)
+	_, _ = exec.Count(ctx, tenant)
+	assert.Equal(t, 9, inner.calls)
+}
+
+func TestWithCache_ExpiryAndErrors(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeExecutor{errs: []error{errBackend}}
+	cache := NewMemoryCache(1)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	cached := WithCacheStore(cache, time.Minute)(inner)
+	q := &query.Query{}
+
+	// Errors are not cached
+	_, err := cached.Count(ctx, q)
+	assert.Error(t, err)
+	_, err = cached.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	// Entries expire after ttl
+	now = now.Add(2 * time.Minute)
+	_, err = cached.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.calls)
+}
+
+func TestWithCacheStore(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeExecutor{}
+	cache := NewMemoryCache(10)
+	exec := Chain(inner, WithCacheStore(cache, time.Minute))
+	a, b := query.Eq("a", 1), query.Eq("b", 2)
+
+	// Equivalent filters share an entry
+	var items []string
+	_, err := exec.Execute(ctx, &query.Query{Filter: query.And(a, b)}, "", &items)
+	require.NoError(t, err)
+	result, err := exec.Execute(ctx, &query.Query{Filter: query.And(b, a)}, "", &items)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	status, _ := result.GetMetadata(MetadataCache)
+	assert.Equal(t, CacheHit, status)
+
+	// Clear invalidates every entry
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+	_, err = exec.Execute(ctx, &query.Query{Filter: query.And(a, b)}, "", &items)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	// Writes through the decorator clear it too
+	writer := Chain(&writerExecutor{}, WithCacheStore(cache, time.Minute))
+	_, err = writer.Count(ctx, &query.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cache.Len())
+	_, err = executor.UpdateWhere(ctx, writer, &query.Query{Filter: a}, map[string]interface{}{"b": 3})
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Len())
+	_, _ = writer.Count(ctx, &query.Query{})
+	_, err = executor.DeleteWhere(ctx, writer, &query.Query{Filter: a})
+	require.NoError(t, err)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestWithMetrics(t *testing.T) {
+	var observed []string
+	recorder := MetricsRecorderFunc(func(name, operation string, d time.Duration, err error) {
+		observed = append(observed, name+":"+operation)
+	})
+
+	exec := Chain(&fakeExecutor{}, WithMetrics(recorder))
+	var items []string
+	_, _ = exec.Execute(context.Background(), &query.Query{}, "", &items)
+	_, _ = exec.Count(context.Background(), &query.Query{})
+
+	assert.Equal(t, []string{"fake:execute", "fake:count"}, observed)
+}
+
+func TestWithAudit(t *testing.T) {
+	var events []AuditEvent
+	exec := Chain(&fakeExecutor{errs: []error{errBackend}}, WithAudit(func(ctx context.Context, event AuditEvent) {
+		events = append(events, event)
+	}))
+
+	q := &query.Query{PageSize: 5}
+	var items []string
+	_, _ = exec.Execute(context.Background(), q, "cursor", &items)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, OperationExecute, events[0].Operation)
+	assert.Same(t, q, events[0].Query)
+	assert.Equal(t, "cursor", events[0].Cursor)
+	assert.ErrorIs(t, events[0].Err, errBackend)
+
+	// Writes are recorded with the items they affected
+	events = nil
+	writer := Chain(&writerExecutor{}, WithAudit(func(ctx context.Context, event AuditEvent) {
+		events = append(events, event)
+	}))
+	changes := map[string]interface{}{"status": "archived"}
+	_, err := executor.UpdateWhere(context.Background(), writer, q, changes)
+	require.NoError(t, err)
+	_, err = executor.DeleteWhere(context.Background(), writer, q)
+	require.NoError(t, err)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, OperationUpdateWhere, events[0].Operation)
+	assert.Equal(t, changes, events[0].Changes)
+	assert.Equal(t, int64(1), events[0].Count)
+	assert.Equal(t, OperationDeleteWhere, events[1].Operation)
+	assert.Same(t, q, events[1].Query)
+	assert.Equal(t, int64(1), events[1].Count)
+}
+
+func TestWithHooks(t *testing.T) {
+	type spanKey struct{}
+	var before, after []AuditEvent
+	exec := Chain(&fakeExecutor{}, WithHooks(HookFuncs{
+		Before: func(ctx context.Context, event AuditEvent) context.Context {
+			before = append(before, event)
+			return context.WithValue(ctx, spanKey{}, event.Operation)
+		},
+		After: func(ctx context.Context, event AuditEvent) {
+			assert.Equal(t, event.Operation, ctx.Value(spanKey{}))
+			after = append(after, event)
+		},
+	}))
+
+	q := &query.Query{PageSize: 5}
+	var items []string
+	_, err := exec.Execute(context.Background(), q, "cursor", &items)
+	require.NoError(t, err)
+	_, err = exec.Count(context.Background(), q)
+	require.NoError(t, err)
+	_, err = executor.Facets(context.Background(), exec, q, []query.FacetSpec{{Field: "brand"}})
+	assert.ErrorIs(t, err, query.ErrFacetsNotSupported)
+
+	require.Len(t, before, 3)
+	assert.Equal(t, AuditEvent{Executor: "fake", Operation: OperationExecute, Query: q, Cursor: "cursor"}, before[0])
+	assert.Equal(t, AuditEvent{Executor: "fake", Operation: OperationCount, Query: q}, before[1])
+	assert.Equal(t, AuditEvent{Executor: "fake", Operation: OperationFacets, Query: q}, before[2])
+	require.Len(t, after, 3)
+	assert.Equal(t, 2, after[0].Result.ItemsReturned)
+	assert.Equal(t, int64(2), after[1].Count)
+	assert.ErrorIs(t, after[2].Err, query.ErrFacetsNotSupported)
+
+	// Unset functions are skipped
+	exec = Chain(&fakeExecutor{}, WithHooks(HookFuncs{}))
+	_, err = exec.Execute(context.Background(), q, "", &items)
+	require.NoError(t, err)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	inner := &fakeExecutor{errs: []error{errBackend, errBackend, errBackend}}
+	breaker := WithCircuitBreaker(2, time.Minute)(inner).(*circuitExecutor)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	_, err := breaker.Count(ctx, &query.Query{})
+	assert.ErrorIs(t, err, errBackend)
+	_, err = breaker.Count(ctx, &query.Query{})
+	assert.ErrorIs(t, err, errBackend)
+
+	// Circuit is open: fail fast without calling inner
+	_, err = breaker.Count(ctx, &query.Query{})
+	assert.ErrorIs(t, err, query.ErrCircuitOpen)
+	assert.Equal(t, 2, inner.calls)
+
+	// After cooldown a failing trial re-opens the circuit
+	now = now.Add(2 * time.Minute)
+	_, err = breaker.Count(ctx, &query.Query{})
+	assert.ErrorIs(t, err, errBackend)
+	_, err = breaker.Count(ctx, &query.Query{})
+	assert.ErrorIs(t, err, query.ErrCircuitOpen)
+
+	// A successful trial closes it again
+	now = now.Add(2 * time.Minute)
+	_, err = breaker.Count(ctx, &query.Query{})
+	require.NoError(t, err)
+	_, err = breaker.Count(ctx, &query.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, inner.calls)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	inner := &fakeExecutor{}
+	limiter := WithRateLimit(2, 2, nil)(inner).(*rateLimitExecutor)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	alice := ContextWithRateLimitKey(context.Background(), "alice")
+	bob := ContextWithRateLimitKey(context.Background(), "bob")
+
+	// A burst of 2, then the third call is rejected until a token refills
+	_, err := limiter.Count(alice, &query.Query{})
+	require.NoError(t, err)
+	_, err = limiter.Execute(alice, &query.Query{}, "", nil)
+	require.NoError(t, err)
+	result, err := limiter.Execute(alice, &query.Query{}, "", nil)
+	assert.ErrorIs(t, err, query.ErrRateLimited)
+	assert.Equal(t, err, result.Error)
+	var limitErr *query.RateLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "alice", limitErr.Key)
+	assert.Equal(t, 500*time.Millisecond, limitErr.RetryAfter)
+	assert.Equal(t, 2, inner.calls)
+
+	// Keys have their own buckets
+	_, err = limiter.Count(bob, &query.Query{})
+	require.NoError(t, err)
+
+	now = now.Add(500 * time.Millisecond)
+	_, err = limiter.Count(alice, &query.Query{})
+	require.NoError(t, err)
+	_, err = limiter.Count(alice, &query.Query{})
+	assert.ErrorIs(t, err, query.ErrRateLimited)
+
+	// Facets and writes share the limit
+	carol := ContextWithRateLimitKey(context.Background(), "carol")
+	_, err = executor.DeleteWhere(carol, limiter, &query.Query{})
+	assert.ErrorIs(t, err, query.ErrWritesNotSupported)
+	_, err = executor.UpdateWhere(carol, limiter, &query.Query{}, nil)
+	assert.ErrorIs(t, err, query.ErrWritesNotSupported)
+	_, err = executor.Facets(carol, limiter, &query.Query{}, nil)
+	assert.ErrorIs(t, err, query.ErrRateLimited)
+
+	// Refilled buckets are dropped
+	now = now.Add(time.Minute)
+	_, err = limiter.Count(context.Background(), &query.Query{})
+	require.NoError(t, err)
+	assert.Len(t, limiter.buckets, 1)
+}
+
+// blockingExecutor holds every call until release is closed
+type blockingExecutor struct {
+	fakeExecutor
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return 2, nil
+}
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	ctx := context.Background()
+	inner := &blockingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	exec := WithConcurrencyLimit(1, 0)(inner)
+
+	done := make(chan error)
+	go func() {
+		_, err := exec.Count(ctx, &query.Query{})
+		done <- err
+	}()
+	<-inner.started
+
+	// The only slot is taken
+	_, err := exec.Count(ctx, &query.Query{})
+	assert.ErrorIs(t, err, query.ErrRateLimited)
+
+	waiting := WithConcurrencyLimit(1, time.Millisecond)(inner)
+	go func() {
+		_, _ = waiting.Count(ctx, &query.Query{})
+	}()
+	<-inner.started
+	_, err = waiting.Count(ctx, &query.Query{})
+	assert.ErrorIs(t, err, query.ErrRateLimited)
+
+	// Writes need a slot too
+	_, err = executor.DeleteWhere(ctx, exec, &query.Query{})
+	assert.ErrorIs(t, err, query.ErrRateLimited)
+
+	close(inner.release)
+	require.NoError(t, <-done)
+
+	// Freed slots are reused
+	go func() { <-inner.started }()
+	_, err = exec.Count(ctx, &query.Query{})
+	require.NoError(t, err)
+}
+
+func TestWithBaseFilter(t *testing.T) {
+	tenant := &query.ComparisonNode{Field: "tenant_id", Operator: query.OpEqual, Value: query.IntValue(7)}
+	inner := &fakeExecutor{}
+	var exec executor.Executor = Chain(inner, WithBaseFilter(tenant))
+
+	t.Run("empty filter becomes base filter", func(t *testing.T) {
+		_, err := exec.Count(context.Background(), &query.Query{})
+		require.NoError(t, err)
+		assert.Same(t, tenant, inner.lastQuery.Filter)
+	})
+
+	t.Run("existing filter is ANDed without mutating caller query", func(t *testing.T) {
+		userFilter := &query.ComparisonNode{Field: "name", Operator: query.OpEqual, Value: query.StringValue("x")}
+		q := &query.Query{Filter: userFilter}
+
+		var items []string
+		_, err := exec.Execute(context.Background(), q, "", &items)
+		require.NoError(t, err)
+
+		and, ok := inner.lastQuery.Filter.(*query.BinaryOpNode)
+		require.True(t, ok)
+		assert.Equal(t, query.BinaryOpAnd, and.Operator)
+		assert.Same(t, tenant, and.Left)
+		assert.Same(t, userFilter, and.Right)
+		assert.Same(t, userFilter, q.Filter)
+	})
+
+	t.Run("writes", func(t *testing.T) {
+		inner := &writerExecutor{}
+		exec := Chain(inner, WithBaseFilter(tenant))
+		userFilter := &query.ComparisonNode{Field: "status", Operator: query.OpEqual, Value: query.StringValue("draft")}
+
+		_, err := executor.UpdateWhere(context.Background(), exec, &query.Query{Filter: userFilter}, map[string]interface{}{"status": "archived"})
+		require.NoError(t, err)
+		and, ok := inner.lastQuery.Filter.(*query.BinaryOpNode)
+		require.True(t, ok)
+		assert.Same(t, tenant, and.Left)
+
+		// Without a filter of its own the write is passed on unscoped, for the executor to reject
+		_, err = executor.DeleteWhere(context.Background(), exec, &query.Query{})
+		require.NoError(t, err)
+		assert.Nil(t, inner.lastQuery.Filter)
+
+		_, err = executor.DeleteWhere(context.Background(), Chain(&fakeExecutor{}, WithBaseFilter(tenant)), &query.Query{Filter: userFilter})
+		assert.ErrorIs(t, err, query.ErrWritesNotSupported)
+	})
+}
+
+// writerExecutor records the queries of writes
+type writerExecutor struct {
+	fakeExecutor
+}
+
+func (w *writerExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	w.lastQuery = q
+	return 1, nil
+}
+
+func (w *writerExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	w.lastQuery = q
+	return 1, nil
+}
+
+type maskedAuthor struct {
+	Name  string
+	Email string `json:"email"`
+}
+
+type maskedUser struct {
+	Name   string
+	Email  string `json:"email"`
+	Salary float64
+	SSN    string `json:"ssn"`
+	Author *maskedAuthor
+}
+
+// usersExecutor fills dest with fresh users
+type usersExecutor struct {
+	fakeExecutor
+}
+
+func (u *usersExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	if _, err := u.fakeExecutor.Execute(ctx, q, cursor, nil); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	*dest.(*[]maskedUser) = []maskedUser{
+		{Name: "ann", Email: "ann@example.com", Salary: 100, SSN: "123-45-6789", Author: &maskedAuthor{Name: "bo", Email: "bo@example.com"}},
+		{Name: "cy", Email: "cy@example.com", Salary: 200, SSN: "987-65-4321"},
+	}
+	return &query.Result{ItemsReturned: 2}, nil
+}
+
+type roleKey struct{}
+
+func TestWithFieldMask(t *testing.T) {
+	restrictions := Restrictions{
+		"email":        {Roles: []string{"admin", "support"}},
+		"salary":       {Roles: []string{"admin"}},
+		"ssn":          {Roles: []string{"admin"}, Mask: func(v interface{}) interface{} { return "***-**-" + v.(string)[7:] }},
+		"author.email": {Roles: []string{"admin"}, Mask: Redact("hidden")},
+	}
+	role := func(ctx context.Context) string {
+		r, _ := ctx.Value(roleKey{}).(string)
+		return r
+	}
+	exec := Chain(&usersExecutor{}, WithFieldMask(restrictions, role))
+	run := func(t *testing.T, r string) []maskedUser {
+		var users []maskedUser
+		_, err := exec.Execute(context.WithValue(context.Background(), roleKey{}, r), &query.Query{}, "", &users)
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		return users
+	}
+
+	t.Run("admin sees everything", func(t *testing.T) {
+		users := run(t, "admin")
+		assert.Equal(t, "ann@example.com", users[0].Email)
+		assert.Equal(t, 100.0, users[0].Salary)
+		assert.Equal(t, "123-45-6789", users[0].SSN)
+		assert.Equal(t, "bo@example.com", users[0].Author.Email)
+	})
+
+	t.Run("public fields are masked or removed", func(t *testing.T) {
+		users := run(t, "")
+		assert.Equal(t, "ann", users[0].Name)
+		assert.Empty(t, users[0].Email)
+		assert.Zero(t, users[0].Salary)
+		assert.Equal(t, "***-**-6789", users[0].SSN)
+		assert.Equal(t, "***-**-4321", users[1].SSN)
+		assert.Equal(t, "hidden", users[0].Author.Email)
+		assert.Equal(t, "bo", users[0].Author.Name)
+		assert.Nil(t, users[1].Author)
+	})
+
+	t.Run("roles are checked per restriction", func(t *testing.T) {
+		users := run(t, "support")
+		assert.Equal(t, "ann@example.com", users[0].Email)
+		assert.Zero(t, users[0].Salary)
+	})
+
+	t.Run("facets on restricted fields are rejected", func(t *testing.T) {
+		public := context.WithValue(context.Background(), roleKey{}, "")
+		for _, field := range []string{"Email", "author.email"} {
+			_, err := executor.Facets(public, exec, &query.Query{}, []query.FacetSpec{{Field: field}})
+			assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+		}
+
+		// Allowed facets reach the executor, which counts none here
+		_, err := executor.Facets(public, exec, &query.Query{}, []query.FacetSpec{{Field: "name"}})
+		assert.ErrorIs(t, err, query.ErrFacetsNotSupported)
+		admin := context.WithValue(context.Background(), roleKey{}, "admin")
+		_, err = executor.Facets(admin, exec, &query.Query{}, []query.FacetSpec{{Field: "email"}})
+		assert.ErrorIs(t, err, query.ErrFacetsNotSupported)
+	})
+
+	t.Run("errors are not masked", func(t *testing.T) {
+		failing := Chain(&usersExecutor{fakeExecutor{errs: []error{errBackend}}}, WithFieldMask(restrictions, role))
+		var users []maskedUser
+		_, err := failing.Execute(context.Background(), &query.Query{}, "", &users)
+		assert.ErrorIs(t, err, errBackend)
+	})
+}
+
+func TestRestrictions_ApplyMaps(t *testing.T) {
+	restrictions := Restrictions{
+		"email":      {Roles: []string{"*"}},
+		"salary":     {},
+		"phone":      {Mask: Redact("redacted")},
+		"owner.name": {Roles: []string{"admin"}, Mask: Redact("someone")},
+	}
+	rows := []map[string]interface{}{
+		{"name": "ann", "email": "a@example.com", "Salary": 10, "phone": "555", "owner": map[string]interface{}{"name": "bo"}},
+		{"name": "cy", "salary": 20},
+	}
+	require.NoError(t, restrictions.Apply(&rows, "viewer"))
+
+	assert.Equal(t, map[string]interface{}{
+		"name": "ann", "email": "a@example.com", "phone": "redacted", "owner": map[string]interface{}{"name": "someone"},
+	}, rows[0])
+	assert.Equal(t, map[string]interface{}{"name": "cy"}, rows[1])
+
+	t.Run("incompatible mask values are rejected", func(t *testing.T) {
+		users := []maskedUser{{Salary: 10}}
+		err := Restrictions{"salary": {Mask: Redact("n/a")}}.Apply(&users, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,261 @@
+package decorators
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// MaskFunc replaces the value of a restricted field for roles that may not see it
+type MaskFunc func(value interface{}) interface{}
+
+// Restriction marks a result field as restricted
+type Restriction struct {
+	// Roles that see the field unchanged. "*" matches every role.
+	Roles []string
+
+	// Mask computes the value other roles see. Nil removes the field:
+	// map keys are deleted and struct fields are set to their zero value.
+	Mask MaskFunc
+}
+
+// Restrictions maps result field names to their restrictions.
+// Names match map keys, struct field names (case-insensitive) and json/bson tags.
+// Dotted names such as "author.email" reach into nested structs, maps and slices.
+type Restrictions map[string]Restriction
+
+// RoleFunc returns the role of the caller making the request
+type RoleFunc func(ctx context.Context) string
+
+// Redact returns a MaskFunc that replaces every value with replacement
+func Redact(replacement interface{}) MaskFunc {
+	return func(interface{}) interface{} {
+		return replacement
+	}
+}
+
+// WithFieldMask removes or masks restricted fields in every page written to dest,
+// based on the role returned by role. One executor can then serve both
+// privileged and public clients.
+//
+// Place it outside WithCache: cached pages are shared between roles.
+// Facets on restricted fields the role may not see fail with
+// query.ErrFieldNotAllowed, since bucket values would reveal them.
+// Masking only shapes results; deny filtering on restricted fields with a
+// policy so their values cannot be probed through queries.
+func WithFieldMask(restrictions Restrictions, role RoleFunc) Decorator {
+	return func(inner executor.Executor) executor.Executor {
+		return &maskExecutor{base: base{inner: inner}, restrictions: restrictions, role: role}
+	}
+}
+
+type maskExecutor struct {
+	base
+	restrictions Restrictions
+	role         RoleFunc
+}
+
+// Execute runs the query and masks restricted fields in dest
+func (e *maskExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	result, err := e.inner.Execute(ctx, q, cursor, dest)
+	if err != nil {
+		return result, err
+	}
+	if err := e.restrictions.Apply(dest, e.role(ctx)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Count counts matching items; counts are not masked
+func (e *maskExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	return e.inner.Count(ctx, q)
+}
+
+// Facets counts facets unless a spec counts a field the role may not see
+func (e *maskExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	role := e.role(ctx)
+	for _, spec := range specs {
+		if !e.restrictions.Allows(spec.Field, role) {
+			return nil, query.NewFieldError(spec.Field, query.ErrFieldNotAllowed)
+		}
+	}
+	return executor.Facets(ctx, e.inner, q, specs)
+}
+
+// DeleteWhere deletes matching items; writes return no fields to mask
+func (e *maskExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	return executor.DeleteWhere(ctx, e.inner, q)
+}
+
+// UpdateWhere updates matching items; writes return no fields to mask
+func (e *maskExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	return executor.UpdateWhere(ctx, e.inner, q, changes)
+}
+
+// Allows reports whether role sees field unchanged: no restriction applies
+// to it or to a field containing it, such as "author" for "author.email",
+// or all that apply allow role
+func (r Restrictions) Allows(field string, role string) bool {
+	for name, restriction := range r {
+		if restriction.allows(role) {
+			continue
+		}
+		if strings.EqualFold(name, field) || (len(field) > len(name) &&
+			strings.EqualFold(field[:len(name)], name) && field[len(name)] == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply removes or masks the fields role may not see in dest, in place.
+// dest is a pointer to a slice, struct or map, or a map itself.
+func (r Restrictions) Apply(dest interface{}, role string) error {
+	val := reflect.ValueOf(dest)
+	for name, restriction := range r {
+		if restriction.allows(role) {
+			continue
+		}
+		if err := maskPath(val, strings.Split(name, "."), restriction.Mask); err != nil {
+			return fmt.Errorf("cannot mask field %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// allows reports whether role sees the field unchanged
+func (r Restriction) allows(role string) bool {
+	for _, candidate := range r.Roles {
+		if candidate == "*" || candidate == role {
+			return true
+		}
+	}
+	return false
+}
+
+// maskPath masks the field at path inside val, descending into pointers,
+// interfaces, slices and nested fields
+func maskPath(val reflect.Value, path []string, mask MaskFunc) error {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		elem := val.Elem()
+		if val.Kind() == reflect.Interface && elem.Kind() == reflect.Struct {
+			// Structs held in interfaces are not addressable; mask a copy and store it back
+			copied := reflect.New(elem.Type()).Elem()
+			copied.Set(elem)
+			if err := maskPath(copied, path, mask); err != nil {
+				return err
+			}
+			val.Set(copied)
+			return nil
+		}
+		return maskPath(elem, path, mask)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			if err := maskPath(val.Index(i), path, mask); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if val.IsNil() || val.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			if !strings.EqualFold(key.String(), path[0]) {
+				continue
+			}
+			if len(path) > 1 {
+				// Map values are not addressable; descend through a settable copy
+				copied := reflect.New(iter.Value().Type()).Elem()
+				copied.Set(iter.Value())
+				if err := maskPath(copied, path[1:], mask); err != nil {
+					return err
+				}
+				val.SetMapIndex(key, copied)
+				continue
+			}
+			if mask == nil {
+				val.SetMapIndex(key, reflect.Value{})
+				continue
+			}
+			masked, err := maskedValue(iter.Value(), val.Type().Elem(), mask)
+			if err != nil {
+				return err
+			}
+			val.SetMapIndex(key, masked)
+		}
+		return nil
+
+	case reflect.Struct:
+		field, ok := structField(val, path[0])
+		if !ok {
+			return nil
+		}
+		if len(path) > 1 {
+			return maskPath(field, path[1:], mask)
+		}
+		if !field.CanSet() {
+			return fmt.Errorf("field is not settable")
+		}
+		if mask == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		masked, err := maskedValue(field, field.Type(), mask)
+		if err != nil {
+			return err
+		}
+		field.Set(masked)
+		return nil
+	}
+	return nil
+}
+
+// maskedValue applies mask to current and converts the result to typ
+func maskedValue(current reflect.Value, typ reflect.Type, mask MaskFunc) (reflect.Value, error) {
+	replacement := mask(current.Interface())
+	if replacement == nil {
+		return reflect.Zero(typ), nil
+	}
+	rv := reflect.ValueOf(replacement)
+	if rv.Type().AssignableTo(typ) {
+		return rv, nil
+	}
+	if rv.Kind() == typ.Kind() && rv.Type().ConvertibleTo(typ) {
+		return rv.Convert(typ), nil
+	}
+	return reflect.Value{}, fmt.Errorf("mask value of type %T is not assignable to %s", replacement, typ)
+}
+
+// structField finds an exported struct field by name (case-insensitive) or json/bson tag
+func structField(val reflect.Value, name string) (reflect.Value, bool) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if strings.EqualFold(field.Name, name) {
+			return val.Field(i), true
+		}
+		for _, key := range []string{"json", "bson"} {
+			if tag := field.Tag.Get(key); tag != "" && strings.EqualFold(strings.Split(tag, ",")[0], name) {
+				return val.Field(i), true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
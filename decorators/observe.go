@@ -0,0 +1,185 @@
+package decorators
+
+import (
+	"context"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Operation names reported to metrics recorders and audit hooks
+const (
+	OperationExecute     = "execute"
+	OperationCount       = "count"
+	OperationFacets      = "facets"
+	OperationDeleteWhere = "delete_where"
+	OperationUpdateWhere = "update_where"
+)
+
+// MetricsRecorder receives one observation per call of the executor
+type MetricsRecorder interface {
+	ObserveQuery(executorName string, operation string, duration time.Duration, err error)
+}
+
+// MetricsRecorderFunc adapts a function to the MetricsRecorder interface
+type MetricsRecorderFunc func(executorName string, operation string, duration time.Duration, err error)
+
+// ObserveQuery calls f
+func (f MetricsRecorderFunc) ObserveQuery(executorName string, operation string, duration time.Duration, err error) {
+	f(executorName, operation, duration, err)
+}
+
+// WithMetrics reports the duration and outcome of every call to recorder
+func WithMetrics(recorder MetricsRecorder) Decorator {
+	return WithAudit(func(ctx context.Context, event AuditEvent) {
+		recorder.ObserveQuery(event.Executor, event.Operation, event.Duration, event.Err)
+	})
+}
+
+// AuditEvent describes a completed Execute, Count, Facets, DeleteWhere or
+// UpdateWhere call
+type AuditEvent struct {
+	Executor  string
+	Operation string
+	Query     *query.Query
+	Cursor    string
+	Result    *query.Result          // only set for Execute
+	Count     int64                  // set for Count, and to the affected items for writes
+	Facets    []query.FacetResult    // only set for Facets
+	Changes   map[string]interface{} // only set for UpdateWhere
+	Duration  time.Duration
+	Err       error
+}
+
+// AuditFunc is called after every call of the executor
+type AuditFunc func(ctx context.Context, event AuditEvent)
+
+// WithAudit calls fn after every call, reads and writes alike, with the query
+// and its outcome
+func WithAudit(fn AuditFunc) Decorator {
+	return WithHooks(HookFuncs{After: fn})
+}
+
+// Hooks observe Execute, Count, Facets, DeleteWhere and UpdateWhere calls, e.g. to start and end telemetry spans.
+// Both methods receive the operation in event.Operation.
+type Hooks interface {
+	// BeforeExecute is called before the call with the executor name, operation,
+	// query and cursor set. The returned context is passed to the executor and
+	// to AfterExecute, so it can carry a span
+	BeforeExecute(ctx context.Context, event AuditEvent) context.Context
+
+	// AfterExecute is called after the call with the outcome filled in
+	AfterExecute(ctx context.Context, event AuditEvent)
+}
+
+// HookFuncs implements Hooks with optional functions
+type HookFuncs struct {
+	Before func(ctx context.Context, event AuditEvent) context.Context
+	After  func(ctx context.Context, event AuditEvent)
+}
+
+// BeforeExecute calls Before, if set
+func (h HookFuncs) BeforeExecute(ctx context.Context, event AuditEvent) context.Context {
+	if h.Before == nil {
+		return ctx
+	}
+	return h.Before(ctx, event)
+}
+
+// AfterExecute calls After, if set
+func (h HookFuncs) AfterExecute(ctx context.Context, event AuditEvent) {
+	if h.After != nil {
+		h.After(ctx, event)
+	}
+}
+
+// WithHooks calls hooks around every call of the executor, reads and writes.
+// Telemetry can then be plugged in once instead of in every executor
+func WithHooks(hooks Hooks) Decorator {
+	return func(inner executor.Executor) executor.Executor {
+		return &hookExecutor{base: base{inner: inner}, hooks: hooks}
+	}
+}
+
+type hookExecutor struct {
+	base
+	hooks Hooks
+}
+
+// Execute runs the query between the hooks
+func (e *hookExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	event := AuditEvent{
+		Executor:  e.inner.Name(),
+		Operation: OperationExecute,
+		Query:     q,
+		Cursor:    cursor,
+	}
+	ctx = e.hooks.BeforeExecute(ctx, event)
+	start := time.Now()
+	result, err := e.inner.Execute(ctx, q, cursor, dest)
+	event.Result, event.Duration, event.Err = result, time.Since(start), err
+	e.hooks.AfterExecute(ctx, event)
+	return result, err
+}
+
+// Count counts matching items between the hooks
+func (e *hookExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	event := AuditEvent{
+		Executor:  e.inner.Name(),
+		Operation: OperationCount,
+		Query:     q,
+	}
+	ctx = e.hooks.BeforeExecute(ctx, event)
+	start := time.Now()
+	count, err := e.inner.Count(ctx, q)
+	event.Count, event.Duration, event.Err = count, time.Since(start), err
+	e.hooks.AfterExecute(ctx, event)
+	return count, err
+}
+
+// Facets counts facets between the hooks
+func (e *hookExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	event := AuditEvent{
+		Executor:  e.inner.Name(),
+		Operation: OperationFacets,
+		Query:     q,
+	}
+	ctx = e.hooks.BeforeExecute(ctx, event)
+	start := time.Now()
+	results, err := executor.Facets(ctx, e.inner, q, specs)
+	event.Facets, event.Duration, event.Err = results, time.Since(start), err
+	e.hooks.AfterExecute(ctx, event)
+	return results, err
+}
+
+// DeleteWhere deletes matching items between the hooks
+func (e *hookExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	event := AuditEvent{
+		Executor:  e.inner.Name(),
+		Operation: OperationDeleteWhere,
+		Query:     q,
+	}
+	ctx = e.hooks.BeforeExecute(ctx, event)
+	start := time.Now()
+	affected, err := executor.DeleteWhere(ctx, e.inner, q)
+	event.Count, event.Duration, event.Err = affected, time.Since(start), err
+	e.hooks.AfterExecute(ctx, event)
+	return affected, err
+}
+
+// UpdateWhere updates matching items between the hooks
+func (e *hookExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	event := AuditEvent{
+		Executor:  e.inner.Name(),
+		Operation: OperationUpdateWhere,
+		Query:     q,
+		Changes:   changes,
+	}
+	ctx = e.hooks.BeforeExecute(ctx, event)
+	start := time.Now()
+	affected, err := executor.UpdateWhere(ctx, e.inner, q, changes)
+	event.Count, event.Duration, event.Err = affected, time.Since(start), err
+	e.hooks.AfterExecute(ctx, event)
+	return affected, err
+}
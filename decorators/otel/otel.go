@@ -0,0 +1,143 @@
+// Package otel traces and measures go-query executors with OpenTelemetry.
+// It is a separate module so the core library does not depend on OpenTelemetry.
+//
+// Each call, reads and writes alike, becomes a client span named after the
+// executor and operation, e.g. "GORM execute", and its duration is recorded
+// in the go_query.duration histogram:
+//
+//	exec := decorators.Chain(inner, otel.WithTelemetry(nil))
+//
+// Filters are recorded with query.NormalizeFilter, so values never reach
+// telemetry backends.
+package otel
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hadi77ir/go-query/decorators"
+	"github.com/hadi77ir/go-query/query"
+	global "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter
+const ScopeName = "github.com/hadi77ir/go-query/decorators/otel"
+
+// Attribute keys set on spans; the duration histogram uses the backend,
+// operation and error keys
+const (
+	AttrBackend       = attribute.Key("go_query.backend")
+	AttrOperation     = attribute.Key("go_query.operation")
+	AttrFilter        = attribute.Key("go_query.filter")
+	AttrSortBy        = attribute.Key("go_query.sort_by")
+	AttrPageSize      = attribute.Key("go_query.page_size")
+	AttrLimit         = attribute.Key("go_query.limit")
+	AttrItemsReturned = attribute.Key("go_query.items_returned")
+	AttrTotalItems    = attribute.Key("go_query.total_items")
+	AttrCount         = attribute.Key("go_query.count")
+	AttrError         = attribute.Key("go_query.error")
+)
+
+// Options configures the telemetry hooks
+type Options struct {
+	// TracerProvider creates the tracer. Defaults to the global provider
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider creates the duration histogram. Defaults to the global provider
+	MeterProvider metric.MeterProvider
+}
+
+// WithTelemetry traces and measures every call of the executor.
+// opts may be nil to use the global providers
+func WithTelemetry(opts *Options) decorators.Decorator {
+	return decorators.WithHooks(NewHooks(opts))
+}
+
+// NewHooks returns hooks that trace and measure every call of the executor,
+// for use with decorators.WithHooks. opts may be nil to use the global providers
+func NewHooks(opts *Options) decorators.Hooks {
+	if opts == nil {
+		opts = &Options{}
+	}
+	tp, mp := opts.TracerProvider, opts.MeterProvider
+	if tp == nil {
+		tp = global.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = global.GetMeterProvider()
+	}
+	h := &hooks{tracer: tp.Tracer(ScopeName)}
+	duration, err := mp.Meter(ScopeName).Float64Histogram("go_query.duration",
+		metric.WithDescription("Duration of go-query executor calls"),
+		metric.WithUnit("s"))
+	if err != nil {
+		global.Handle(err)
+	} else {
+		h.duration = duration
+	}
+	return h
+}
+
+type hooks struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram // nil when it could not be created
+}
+
+// BeforeExecute starts the span of a call
+func (h *hooks) BeforeExecute(ctx context.Context, event decorators.AuditEvent) context.Context {
+	attrs := []attribute.KeyValue{
+		AttrBackend.String(event.Executor),
+		AttrOperation.String(event.Operation),
+	}
+	if q := event.Query; q != nil {
+		attrs = append(attrs, AttrFilter.String(query.NormalizeFilter(q.Filter)))
+		if event.Operation == decorators.OperationExecute {
+			attrs = append(attrs, AttrPageSize.Int(q.PageSize))
+			if q.SortBy != "" {
+				attrs = append(attrs, AttrSortBy.String(q.SortBy))
+			}
+			if q.Limit > 0 {
+				attrs = append(attrs, AttrLimit.Int(q.Limit))
+			}
+		}
+	}
+	ctx, _ = h.tracer.Start(ctx, event.Executor+" "+event.Operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	return ctx
+}
+
+// AfterExecute ends the span of a call and records its duration.
+// ErrNoRecordsFound is an empty result, not a failure
+func (h *hooks) AfterExecute(ctx context.Context, event decorators.AuditEvent) {
+	failed := event.Err != nil && !errors.Is(event.Err, query.ErrNoRecordsFound)
+	if h.duration != nil {
+		h.duration.Record(ctx, event.Duration.Seconds(), metric.WithAttributes(
+			AttrBackend.String(event.Executor),
+			AttrOperation.String(event.Operation),
+			AttrError.Bool(failed),
+		))
+	}
+
+	span := trace.SpanFromContext(ctx)
+	switch {
+	case event.Operation == decorators.OperationCount,
+		event.Operation == decorators.OperationDeleteWhere,
+		event.Operation == decorators.OperationUpdateWhere:
+		span.SetAttributes(AttrCount.Int64(event.Count))
+	case event.Result != nil:
+		span.SetAttributes(
+			AttrItemsReturned.Int(event.Result.ItemsReturned),
+			AttrTotalItems.Int64(event.Result.TotalItems),
+		)
+	}
+	if failed {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,200 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/hadi77ir/go-query/decorators"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// recorder collects spans and histogram records
+type recorder struct {
+	mu      sync.Mutex
+	spans   []*fakeSpan
+	records []fakeRecord
+}
+
+type fakeSpan struct {
+	tracenoop.Span
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	errs   []error
+	ended  bool
+}
+
+func (s *fakeSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+func (s *fakeSpan) RecordError(err error, _ ...trace.EventOption) { s.errs = append(s.errs, err) }
+func (s *fakeSpan) SetStatus(code codes.Code, _ string)           { s.status = code }
+func (s *fakeSpan) End(...trace.SpanEndOption)                    { s.ended = true }
+
+type fakeTracerProvider struct {
+	tracenoop.TracerProvider
+	r *recorder
+}
+
+func (p fakeTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return fakeTracer{r: p.r}
+}
+
+type fakeTracer struct {
+	tracenoop.Tracer
+	r *recorder
+}
+
+func (t fakeTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	span := &fakeSpan{name: name, kind: cfg.SpanKind(), attrs: map[attribute.Key]attribute.Value{}}
+	span.SetAttributes(cfg.Attributes()...)
+	t.r.mu.Lock()
+	t.r.spans = append(t.r.spans, span)
+	t.r.mu.Unlock()
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type fakeRecord struct {
+	name  string
+	attrs attribute.Set
+}
+
+type fakeMeterProvider struct {
+	metricnoop.MeterProvider
+	r *recorder
+}
+
+func (p fakeMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return fakeMeter{r: p.r}
+}
+
+type fakeMeter struct {
+	metricnoop.Meter
+	r *recorder
+}
+
+func (m fakeMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return fakeHistogram{name: name, r: m.r}, nil
+}
+
+type fakeHistogram struct {
+	metricnoop.Float64Histogram
+	name string
+	r    *recorder
+}
+
+func (h fakeHistogram) Record(_ context.Context, _ float64, opts ...metric.RecordOption) {
+	h.r.mu.Lock()
+	defer h.r.mu.Unlock()
+	h.r.records = append(h.r.records, fakeRecord{name: h.name, attrs: metric.NewRecordConfig(opts).Attributes()})
+}
+
+// fakeExecutor returns scripted errors and checks that it runs inside a span
+type fakeExecutor struct {
+	t   *testing.T
+	err error
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	_, inSpan := trace.SpanFromContext(ctx).(*fakeSpan)
+	assert.True(f.t, inSpan, "executor runs inside the span")
+	if f.err != nil {
+		return &query.Result{Error: f.err}, f.err
+	}
+	return &query.Result{ItemsReturned: 2, TotalItems: 7}, nil
+}
+
+func (f *fakeExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	return 7, f.err
+}
+
+func (f *fakeExecutor) Name() string { return "fake" }
+func (f *fakeExecutor) Close() error { return nil }
+
+func newTelemetry(t *testing.T, err error) (*recorder, *fakeExecutor, decorators.Decorator) {
+	r := &recorder{}
+	return r, &fakeExecutor{t: t, err: err}, WithTelemetry(&Options{
+		TracerProvider: fakeTracerProvider{r: r},
+		MeterProvider:  fakeMeterProvider{r: r},
+	})
+}
+
+func TestWithTelemetry_Execute(t *testing.T) {
+	r, inner, telemetry := newTelemetry(t, nil)
+	exec := decorators.Chain(inner, telemetry)
+
+	q := &query.Query{Filter: query.And(query.Eq("email", "a@example.com"), query.Gt("age", 30)), SortBy: "name", PageSize: 20}
+	var items []string
+	_, err := exec.Execute(context.Background(), q, "", &items)
+	require.NoError(t, err)
+
+	require.Len(t, r.spans, 1)
+	span := r.spans[0]
+	assert.Equal(t, "fake execute", span.name)
+	assert.Equal(t, trace.SpanKindClient, span.kind)
+	assert.True(t, span.ended)
+	assert.Equal(t, codes.Unset, span.status)
+	assert.Equal(t, map[attribute.Key]attribute.Value{
+		AttrBackend:       attribute.StringValue("fake"),
+		AttrOperation:     attribute.StringValue("execute"),
+		AttrFilter:        attribute.StringValue("email = ? AND age > ?"),
+		AttrSortBy:        attribute.StringValue("name"),
+		AttrPageSize:      attribute.IntValue(20),
+		AttrItemsReturned: attribute.IntValue(2),
+		AttrTotalItems:    attribute.Int64Value(7),
+	}, span.attrs)
+
+	require.Len(t, r.records, 1)
+	assert.Equal(t, "go_query.duration", r.records[0].name)
+	assert.Equal(t, attribute.NewSet(
+		AttrBackend.String("fake"), AttrOperation.String("execute"), AttrError.Bool(false),
+	), r.records[0].attrs)
+}
+
+func TestWithTelemetry_Count(t *testing.T) {
+	r, inner, telemetry := newTelemetry(t, nil)
+	exec := decorators.Chain(inner, telemetry)
+
+	_, err := exec.Count(context.Background(), &query.Query{Filter: query.Eq("a", 1), PageSize: 20})
+	require.NoError(t, err)
+
+	require.Len(t, r.spans, 1)
+	assert.Equal(t, "fake count", r.spans[0].name)
+	assert.Equal(t, attribute.Int64Value(7), r.spans[0].attrs[AttrCount])
+	assert.NotContains(t, r.spans[0].attrs, AttrPageSize)
+}
+
+func TestWithTelemetry_Errors(t *testing.T) {
+	errBackend := errors.New("connection reset")
+	r, inner, telemetry := newTelemetry(t, errBackend)
+	exec := decorators.Chain(inner, telemetry)
+
+	var items []string
+	_, err := exec.Execute(context.Background(), &query.Query{}, "", &items)
+	assert.ErrorIs(t, err, errBackend)
+	assert.Equal(t, codes.Error, r.spans[0].status)
+	assert.Equal(t, []error{errBackend}, r.spans[0].errs)
+	failed, _ := r.records[0].attrs.Value(AttrError)
+	assert.True(t, failed.AsBool())
+
+	// Empty results are not failures
+	inner.err = query.ErrNoRecordsFound
+	_, err = exec.Execute(context.Background(), &query.Query{}, "", &items)
+	assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+	assert.Equal(t, codes.Unset, r.spans[1].status)
+	assert.Empty(t, r.spans[1].errs)
+}
@@ -0,0 +1,195 @@
+// Package prometheus exports go-query executor metrics to Prometheus. It is a
+// separate module so the core library does not depend on the Prometheus client.
+//
+// Metrics is a prometheus.Collector: register it once in any registry and
+// decorate each executor with it. The backend label tells executors apart:
+//
+//	metrics := prometheus.NewMetrics(nil)
+//	registry.MustRegister(metrics)
+//	exec := decorators.Chain(inner, metrics.Decorator())
+//
+// Every metric has a fingerprint label holding query.Fingerprint, the hash of
+// the query's shape with values left out, so slow or failing query shapes can
+// be found without exporting user values.
+package prometheus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hadi77ir/go-query/decorators"
+	"github.com/hadi77ir/go-query/query"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace prefixes the metric names unless Options.Namespace is set
+const DefaultNamespace = "go_query"
+
+// Label names of the metrics
+const (
+	LabelBackend     = "backend"
+	LabelOperation   = "operation"
+	LabelError       = "error"
+	LabelFingerprint = "fingerprint"
+)
+
+// invalidQueryErrors are the errors caused by the query rather than the backend
+var invalidQueryErrors = []error{
+	query.ErrInvalidQuery,
+	query.ErrInvalidFieldName,
+	query.ErrFieldNotAllowed,
+	query.ErrUnknownField,
+	query.ErrCursorQueryMismatch,
+	query.ErrPageSizeExceeded,
+	query.ErrPageTooDeep,
+	query.ErrRegexNotSupported,
+	query.ErrRandomOrderNotAllowed,
+	query.ErrIncludeDeletedNotAllowed,
+	query.ErrUnknownPlaceholder,
+	query.ErrUnboundParameter,
+	query.ErrTypeMismatch,
+	query.ErrPolicyViolation,
+	query.ErrInvalidSortField,
+	query.ErrOperatorNotAllowed,
+	query.ErrQueryTooComplex,
+	query.ErrUnsafeRegex,
+	query.ErrSearchTermsIgnored,
+}
+
+// Options configures the metrics
+type Options struct {
+	// Namespace prefixes the metric names. Defaults to DefaultNamespace
+	Namespace string
+
+	// Buckets are the upper bounds of the duration histogram in seconds.
+	// Defaults to prometheus.DefBuckets
+	Buckets []float64
+
+	// DisableFingerprint leaves the fingerprint label empty. Every distinct
+	// query shape is a time series of its own, so disable it when callers
+	// can send arbitrary filters
+	DisableFingerprint bool
+}
+
+// Metrics records the calls of decorated executors:
+//
+//   - duration_seconds: histogram of call durations by backend, operation,
+//     error (true for failures other than ErrNoRecordsFound) and fingerprint
+//   - rows_returned_total: items returned by Execute
+//   - no_records_total: calls that failed with ErrNoRecordsFound
+//   - invalid_queries_total: calls rejected because of the query, such as
+//     ErrFieldNotAllowed or ErrInvalidQuery
+//   - cursor_errors_total: Execute calls with a cursor that could not be
+//     decoded (ErrInvalidCursor)
+type Metrics struct {
+	duration       *prom.HistogramVec
+	rows           *prom.CounterVec
+	noRecords      *prom.CounterVec
+	invalidQueries *prom.CounterVec
+	cursorErrors   *prom.CounterVec
+
+	noFingerprint bool
+}
+
+// NewMetrics creates the metrics. opts may be nil to use the defaults.
+// Register the result in a registry to export them
+func NewMetrics(opts *Options) *Metrics {
+	if opts == nil {
+		opts = &Options{}
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	buckets := opts.Buckets
+	if buckets == nil {
+		buckets = prom.DefBuckets
+	}
+	counter := func(name, help string) *prom.CounterVec {
+		return prom.NewCounterVec(prom.CounterOpts{Namespace: namespace, Name: name, Help: help},
+			[]string{LabelBackend, LabelOperation, LabelFingerprint})
+	}
+	return &Metrics{
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "duration_seconds",
+			Help:      "Duration of go-query executor calls",
+			Buckets:   buckets,
+		}, []string{LabelBackend, LabelOperation, LabelError, LabelFingerprint}),
+		rows:           counter("rows_returned_total", "Items returned by go-query Execute calls"),
+		noRecords:      counter("no_records_total", "go-query calls that found no records"),
+		invalidQueries: counter("invalid_queries_total", "go-query calls rejected because of an invalid or disallowed query"),
+		cursorErrors:   counter("cursor_errors_total", "go-query Execute calls with a cursor that could not be decoded"),
+		noFingerprint:  opts.DisableFingerprint,
+	}
+}
+
+// Decorator returns a decorator recording the calls of an executor
+func (m *Metrics) Decorator() decorators.Decorator {
+	return decorators.WithHooks(m)
+}
+
+// Describe sends the descriptors of the metrics to ch
+func (m *Metrics) Describe(ch chan<- *prom.Desc) {
+	m.duration.Describe(ch)
+	m.rows.Describe(ch)
+	m.noRecords.Describe(ch)
+	m.invalidQueries.Describe(ch)
+	m.cursorErrors.Describe(ch)
+}
+
+// Collect sends the current values of the metrics to ch
+func (m *Metrics) Collect(ch chan<- prom.Metric) {
+	m.duration.Collect(ch)
+	m.rows.Collect(ch)
+	m.noRecords.Collect(ch)
+	m.invalidQueries.Collect(ch)
+	m.cursorErrors.Collect(ch)
+}
+
+// BeforeExecute does nothing; durations are measured by decorators.WithHooks
+func (m *Metrics) BeforeExecute(ctx context.Context, event decorators.AuditEvent) context.Context {
+	return ctx
+}
+
+// AfterExecute records a completed call
+func (m *Metrics) AfterExecute(ctx context.Context, event decorators.AuditEvent) {
+	var fingerprint string
+	if !m.noFingerprint {
+		fingerprint = query.Fingerprint(event.Query)
+	}
+	failed := event.Err != nil && !errors.Is(event.Err, query.ErrNoRecordsFound)
+	m.duration.WithLabelValues(event.Executor, event.Operation, boolLabel(failed), fingerprint).
+		Observe(event.Duration.Seconds())
+
+	labels := []string{event.Executor, event.Operation, fingerprint}
+	if event.Result != nil {
+		m.rows.WithLabelValues(labels...).Add(float64(event.Result.ItemsReturned))
+	}
+	switch {
+	case event.Err == nil:
+	case errors.Is(event.Err, query.ErrNoRecordsFound):
+		m.noRecords.WithLabelValues(labels...).Inc()
+	case errors.Is(event.Err, query.ErrInvalidCursor):
+		m.cursorErrors.WithLabelValues(labels...).Inc()
+	case isInvalidQuery(event.Err):
+		m.invalidQueries.WithLabelValues(labels...).Inc()
+	}
+}
+
+// isInvalidQuery reports whether err was caused by the query
+func isInvalidQuery(err error) bool {
+	for _, target := range invalidQueryErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func boolLabel(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
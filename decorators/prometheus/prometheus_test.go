@@ -0,0 +1,101 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/decorators"
+	"github.com/hadi77ir/go-query/query"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExecutor returns a fixed result or error
+type fakeExecutor struct {
+	items int
+	err   error
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	if f.err != nil {
+		return &query.Result{Error: f.err}, f.err
+	}
+	return &query.Result{ItemsReturned: f.items, TotalItems: int64(f.items)}, nil
+}
+
+func (f *fakeExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	return int64(f.items), f.err
+}
+
+func (f *fakeExecutor) Name() string { return "fake" }
+func (f *fakeExecutor) Close() error { return nil }
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics(nil)
+	registry := prom.NewRegistry()
+	require.NoError(t, registry.Register(metrics))
+
+	ctx := context.Background()
+	q := &query.Query{Filter: query.Eq("brand", "Sony")}
+	fingerprint := query.Fingerprint(q)
+	run := func(inner *fakeExecutor) {
+		exec := decorators.Chain(inner, metrics.Decorator())
+		_, _ = exec.Execute(ctx, q, "", nil)
+	}
+
+	run(&fakeExecutor{items: 3})
+	run(&fakeExecutor{items: 2})
+	run(&fakeExecutor{err: query.ErrNoRecordsFound})
+	run(&fakeExecutor{err: query.FieldNotAllowedError("secret")})
+	run(&fakeExecutor{err: query.ErrInvalidCursor})
+	run(&fakeExecutor{err: query.NewExecutionError("execute query", assert.AnError)})
+
+	assert.Equal(t, 5.0, testutil.ToFloat64(metrics.rows.WithLabelValues("fake", "execute", fingerprint)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.noRecords.WithLabelValues("fake", "execute", fingerprint)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.invalidQueries.WithLabelValues("fake", "execute", fingerprint)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.cursorErrors.WithLabelValues("fake", "execute", fingerprint)))
+
+	// No records is an empty result, not a failure
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.duration, "go_query_duration_seconds"))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "go_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			assert.Equal(t, fingerprint, labels[LabelFingerprint])
+			// Three calls of each: successes and no records, other errors
+			assert.Equal(t, uint64(3), metric.GetHistogram().GetSampleCount(), labels[LabelError])
+		}
+	}
+}
+
+func TestMetrics_Options(t *testing.T) {
+	metrics := NewMetrics(&Options{Namespace: "search", Buckets: []float64{0.1, 1}, DisableFingerprint: true})
+	exec := decorators.Chain(&fakeExecutor{items: 4}, metrics.Decorator())
+
+	_, err := exec.Count(context.Background(), &query.Query{Filter: query.Eq("brand", "Sony")})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics, "search_duration_seconds"))
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics, "search_rows_returned_total"))
+
+	registry := prom.NewRegistry()
+	require.NoError(t, registry.Register(metrics))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	for _, label := range families[0].GetMetric()[0].GetLabel() {
+		if label.GetName() == LabelFingerprint {
+			assert.Empty(t, label.GetValue())
+		}
+	}
+	assert.Len(t, families[0].GetMetric()[0].GetHistogram().GetBucket(), 2)
+}
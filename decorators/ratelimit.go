@@ -0,0 +1,252 @@
+package decorators
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// KeyFunc returns the rate limit key of a call, e.g. the client IP or API key
+type KeyFunc func(ctx context.Context) string
+
+type rateLimitKey struct{}
+
+// ContextWithRateLimitKey returns a context whose calls WithRateLimit counts
+// under key when it is given no KeyFunc
+func ContextWithRateLimitKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, rateLimitKey{}, key)
+}
+
+// RateLimitKey returns the key set by ContextWithRateLimitKey, or "" for
+// calls sharing one limit
+func RateLimitKey(ctx context.Context) string {
+	key, _ := ctx.Value(rateLimitKey{}).(string)
+	return key
+}
+
+// WithRateLimit allows perSecond calls per key on average, with
+// bursts of up to burst calls (a token bucket). key extracts the key of a
+// call; nil uses RateLimitKey. Calls over the limit fail with a
+// query.RateLimitError matching query.ErrRateLimited, whose RetryAfter is the
+// time until the key's next call is allowed. Execute, Count, Facets,
+// DeleteWhere and UpdateWhere calls share the limit. perSecond must be positive
+func WithRateLimit(perSecond float64, burst int, key KeyFunc) Decorator {
+	if burst < 1 {
+		burst = 1
+	}
+	if key == nil {
+		key = RateLimitKey
+	}
+	return func(inner executor.Executor) executor.Executor {
+		return &rateLimitExecutor{
+			base:    base{inner: inner},
+			rate:    perSecond,
+			burst:   float64(burst),
+			key:     key,
+			now:     time.Now,
+			buckets: make(map[string]*tokenBucket),
+		}
+	}
+}
+
+type rateLimitExecutor struct {
+	base
+	rate  float64
+	burst float64
+	key   KeyFunc
+	now   func() time.Time // For testing
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+// tokenBucket holds the calls a key may make, as of last
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Execute runs the query if the key's limit allows it
+func (e *rateLimitExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	if err := e.allow(ctx); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	return e.inner.Execute(ctx, q, cursor, dest)
+}
+
+// Count counts matching items if the key's limit allows it
+func (e *rateLimitExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	if err := e.allow(ctx); err != nil {
+		return 0, err
+	}
+	return e.inner.Count(ctx, q)
+}
+
+// Facets counts facets if the key's limit allows it
+func (e *rateLimitExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	if err := e.allow(ctx); err != nil {
+		return nil, err
+	}
+	return executor.Facets(ctx, e.inner, q, specs)
+}
+
+// DeleteWhere deletes matching items if the key's limit allows it
+func (e *rateLimitExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	if err := e.allow(ctx); err != nil {
+		return 0, err
+	}
+	return executor.DeleteWhere(ctx, e.inner, q)
+}
+
+// UpdateWhere updates matching items if the key's limit allows it
+func (e *rateLimitExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	if err := e.allow(ctx); err != nil {
+		return 0, err
+	}
+	return executor.UpdateWhere(ctx, e.inner, q, changes)
+}
+
+// allow takes a token from the bucket of the call's key
+func (e *rateLimitExecutor) allow(ctx context.Context) error {
+	key := e.key(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.sweep(now)
+	b, ok := e.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: e.burst, last: now}
+		e.buckets[key] = b
+	}
+	b.tokens = e.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		retryAfter := time.Duration(math.Ceil((1 - b.tokens) / e.rate * float64(time.Second)))
+		return query.NewRateLimitError(key, retryAfter)
+	}
+	b.tokens--
+	return nil
+}
+
+// refill returns the tokens of b at now
+func (e *rateLimitExecutor) refill(b *tokenBucket, now time.Time) float64 {
+	return math.Min(e.burst, b.tokens+now.Sub(b.last).Seconds()*e.rate)
+}
+
+// sweep drops the buckets that have refilled, since a new bucket starts full,
+// at most once per refill period so idle keys do not accumulate
+func (e *rateLimitExecutor) sweep(now time.Time) {
+	if now.Sub(e.swept).Seconds()*e.rate < e.burst {
+		return
+	}
+	e.swept = now
+	for key, b := range e.buckets {
+		if e.refill(b, now) >= e.burst {
+			delete(e.buckets, key)
+		}
+	}
+}
+
+// WithConcurrencyLimit runs at most limit calls of the wrapped executor at a
+// time, counting Execute, Count, Facets, DeleteWhere and UpdateWhere alike. A call that finds every slot taken waits up to wait
+// for one, or until its context is done, and then fails with a
+// query.RateLimitError matching query.ErrRateLimited. wait 0 rejects it
+// immediately
+func WithConcurrencyLimit(limit int, wait time.Duration) Decorator {
+	if limit < 1 {
+		limit = 1
+	}
+	return func(inner executor.Executor) executor.Executor {
+		return &concurrencyExecutor{
+			base:  base{inner: inner},
+			slots: make(chan struct{}, limit),
+			wait:  wait,
+		}
+	}
+}
+
+type concurrencyExecutor struct {
+	base
+	slots chan struct{}
+	wait  time.Duration
+}
+
+// Execute runs the query in a free slot
+func (e *concurrencyExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	if err := e.acquire(ctx); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	defer e.release()
+	return e.inner.Execute(ctx, q, cursor, dest)
+}
+
+// Count counts matching items in a free slot
+func (e *concurrencyExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	if err := e.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer e.release()
+	return e.inner.Count(ctx, q)
+}
+
+// Facets counts facets in a free slot
+func (e *concurrencyExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	if err := e.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer e.release()
+	return executor.Facets(ctx, e.inner, q, specs)
+}
+
+// DeleteWhere deletes matching items in a free slot
+func (e *concurrencyExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	if err := e.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer e.release()
+	return executor.DeleteWhere(ctx, e.inner, q)
+}
+
+// UpdateWhere updates matching items in a free slot
+func (e *concurrencyExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	if err := e.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer e.release()
+	return executor.UpdateWhere(ctx, e.inner, q, changes)
+}
+
+// acquire takes a slot, waiting up to e.wait for one
+func (e *concurrencyExecutor) acquire(ctx context.Context) error {
+	select {
+	case e.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if e.wait <= 0 {
+		return query.NewRateLimitError("", 0)
+	}
+
+	timer := time.NewTimer(e.wait)
+	defer timer.Stop()
+	select {
+	case e.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return query.NewRateLimitError("", 0)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot of a finished call
+func (e *concurrencyExecutor) release() {
+	<-e.slots
+}
@@ -0,0 +1,107 @@
+package decorators
+
+import (
+	"context"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// RetryableFunc decides whether an error should be retried
+type RetryableFunc func(err error) bool
+
+// WithRetry retries Execute, Count and Facets up to attempts times in total.
+// backoff is the delay before the first retry and doubles after each attempt.
+// If retryable is nil, IsExecutionFailure is used.
+// Retries stop early when the context is cancelled. DeleteWhere and
+// UpdateWhere run once, since a failed write may have changed items.
+func WithRetry(attempts int, backoff time.Duration, retryable RetryableFunc) Decorator {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if retryable == nil {
+		retryable = IsExecutionFailure
+	}
+	return func(inner executor.Executor) executor.Executor {
+		return &retryExecutor{
+			base:      base{inner: inner},
+			attempts:  attempts,
+			backoff:   backoff,
+			retryable: retryable,
+		}
+	}
+}
+
+type retryExecutor struct {
+	base
+	attempts  int
+	backoff   time.Duration
+	retryable RetryableFunc
+}
+
+// Execute runs the query, retrying retryable failures
+func (e *retryExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	var result *query.Result
+	err := e.retry(ctx, func() error {
+		var err error
+		result, err = e.inner.Execute(ctx, q, cursor, dest)
+		return err
+	})
+	return result, err
+}
+
+// Count counts matching items, retrying retryable failures
+func (e *retryExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	var count int64
+	err := e.retry(ctx, func() error {
+		var err error
+		count, err = e.inner.Count(ctx, q)
+		return err
+	})
+	return count, err
+}
+
+// Facets counts facets, retrying retryable failures
+func (e *retryExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	var results []query.FacetResult
+	err := e.retry(ctx, func() error {
+		var err error
+		results, err = executor.Facets(ctx, e.inner, q, specs)
+		return err
+	})
+	return results, err
+}
+
+// DeleteWhere deletes matching items once; writes are not retried
+func (e *retryExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	return executor.DeleteWhere(ctx, e.inner, q)
+}
+
+// UpdateWhere updates matching items once; writes are not retried
+func (e *retryExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	return executor.UpdateWhere(ctx, e.inner, q, changes)
+}
+
+// retry calls fn until it succeeds, returns a non-retryable error or attempts are exhausted
+func (e *retryExecutor) retry(ctx context.Context, fn func() error) error {
+	delay := e.backoff
+	var err error
+	for attempt := 1; attempt <= e.attempts; attempt++ {
+		err = fn()
+		if err == nil || !e.retryable(err) || attempt == e.attempts {
+			return err
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+	}
+	return err
+}
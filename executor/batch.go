@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	query "github.com/hadi77ir/go-query/query"
+)
+
+// BatchResult is the outcome of one query of a batch
+type BatchResult struct {
+	Result *query.Result
+	Err    error
+}
+
+// BatchExecutor is implemented by executors that run several queries together,
+// e.g. in one SQL transaction, one MongoDB session or on one snapshot of
+// in-memory data, so the queries see the same data and skip per-query setup.
+// Every query is executed like Execute with an empty cursor and fills the
+// destination at the same index. There is one result per query, in order
+type BatchExecutor interface {
+	ExecuteBatch(ctx context.Context, queries []*query.Query, dests []interface{}) ([]BatchResult, error)
+}
+
+// ExecuteBatch runs queries with e, storing the items of queries[i] in dests[i].
+// Executors that do not implement BatchExecutor run the queries concurrently.
+// Each query has its own result and error; the returned error only reports a
+// batch that could not run at all, such as mismatched lengths.
+// Example: results, err := executor.ExecuteBatch(ctx, e, []*query.Query{q1, q2}, []interface{}{&users, &orders})
+func ExecuteBatch(ctx context.Context, e Executor, queries []*query.Query, dests []interface{}) ([]BatchResult, error) {
+	if b, ok := e.(BatchExecutor); ok {
+		return b.ExecuteBatch(ctx, queries, dests)
+	}
+	if err := ValidateBatch(queries, dests); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(queries))
+	var wg sync.WaitGroup
+	for i := range queries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i].Result, results[i].Err = e.Execute(ctx, queries[i], "", dests[i])
+		}(i)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// ValidateBatch checks that every query of a batch has a destination
+func ValidateBatch(queries []*query.Query, dests []interface{}) error {
+	if len(queries) != len(dests) {
+		return fmt.Errorf("%w: %d destinations for %d queries", query.ErrInvalidDestination, len(dests), len(queries))
+	}
+	for i, q := range queries {
+		if q == nil {
+			return fmt.Errorf("%w: query %d is nil", query.ErrInvalidQuery, i)
+		}
+	}
+	return nil
+}
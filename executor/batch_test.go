@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	query "github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchExecutor records the batches it is given
+type batchExecutor struct {
+	sliceExecutor
+	batches int
+}
+
+func (b *batchExecutor) ExecuteBatch(ctx context.Context, queries []*query.Query, dests []interface{}) ([]BatchResult, error) {
+	b.batches++
+	return make([]BatchResult, len(queries)), nil
+}
+
+func TestExecuteBatch(t *testing.T) {
+	ctx := context.Background()
+	inner := &sliceExecutor{users: []user{{Name: "Alice"}, {Name: "Bob"}}}
+
+	t.Run("runs each query", func(t *testing.T) {
+		var first, second []user
+		var wrong []string
+		results, err := ExecuteBatch(ctx, inner,
+			[]*query.Query{{}, {}, {}},
+			[]interface{}{&first, &wrong, &second})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, 2, results[0].Result.ItemsReturned)
+		assert.ErrorIs(t, results[1].Err, query.ErrInvalidDestination)
+		require.NoError(t, results[2].Err)
+		assert.Equal(t, inner.users, first)
+		assert.Equal(t, inner.users, second)
+	})
+
+	t.Run("mismatched lengths", func(t *testing.T) {
+		var users []user
+		_, err := ExecuteBatch(ctx, inner, []*query.Query{{}, {}}, []interface{}{&users})
+		assert.ErrorIs(t, err, query.ErrInvalidDestination)
+	})
+
+	t.Run("nil query", func(t *testing.T) {
+		var users []user
+		_, err := ExecuteBatch(ctx, inner, []*query.Query{nil}, []interface{}{&users})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+
+	t.Run("uses BatchExecutor", func(t *testing.T) {
+		b := &batchExecutor{}
+		results, err := ExecuteBatch(ctx, b, []*query.Query{{}}, []interface{}{nil})
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, 1, b.batches)
+	})
+}
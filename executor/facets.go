@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"context"
+
+	query "github.com/hadi77ir/go-query/query"
+)
+
+// Faceter is implemented by executors that count facet buckets. Facets
+// applies the query's filter like Count does; sorting, pagination and
+// distinct options are ignored. There is one result per spec, in order
+type Faceter interface {
+	Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error)
+}
+
+// Facets counts the facets of q with e, or returns query.ErrFacetsNotSupported when e
+// does not implement Faceter.
+// Example: results, err := executor.Facets(ctx, e, q, []query.FacetSpec{{Field: "brand"}})
+func Facets(ctx context.Context, e Executor, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	f, ok := e.(Faceter)
+	if !ok {
+		return nil, query.ErrFacetsNotSupported
+	}
+	return f.Facets(ctx, q, specs)
+}
@@ -0,0 +1,48 @@
+package executor
+
+import (
+	"context"
+
+	query "github.com/hadi77ir/go-query/query"
+)
+
+// TypedExecutor runs queries into slices of T, so the destination is checked
+// at compile time instead of failing with ErrInvalidDestination at runtime
+type TypedExecutor[T any] struct {
+	inner Executor
+}
+
+// Typed wraps e so Execute returns the items as a []T.
+// Example: users, result, err := executor.Typed[User](e).Execute(ctx, q, "")
+func Typed[T any](e Executor) *TypedExecutor[T] {
+	return &TypedExecutor[T]{inner: e}
+}
+
+// Execute runs the query and returns the items of the page. cursor is empty
+// for the first page. The items are returned even when err is not nil if the
+// executor filled them
+func (t *TypedExecutor[T]) Execute(ctx context.Context, q *query.Query, cursor string) ([]T, *query.Result, error) {
+	var items []T
+	result, err := t.inner.Execute(ctx, q, cursor, &items)
+	return items, result, err
+}
+
+// Count returns the total number of items matching the query
+func (t *TypedExecutor[T]) Count(ctx context.Context, q *query.Query) (int64, error) {
+	return t.inner.Count(ctx, q)
+}
+
+// Name returns the name of the wrapped executor
+func (t *TypedExecutor[T]) Name() string {
+	return t.inner.Name()
+}
+
+// Close closes the wrapped executor
+func (t *TypedExecutor[T]) Close() error {
+	return t.inner.Close()
+}
+
+// Executor returns the wrapped executor
+func (t *TypedExecutor[T]) Executor() Executor {
+	return t.inner
+}
@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	query "github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name string
+}
+
+// sliceExecutor fills destinations of type *[]user
+type sliceExecutor struct {
+	users  []user
+	err    error
+	closed bool
+}
+
+func (s *sliceExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	users, ok := dest.(*[]user)
+	if !ok {
+		return nil, query.ErrInvalidDestination
+	}
+	*users = append(*users, s.users...)
+	return &query.Result{ItemsReturned: len(s.users), TotalItems: int64(len(s.users))}, s.err
+}
+
+func (s *sliceExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	return int64(len(s.users)), s.err
+}
+
+func (s *sliceExecutor) Name() string { return "slice" }
+
+func (s *sliceExecutor) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestTyped(t *testing.T) {
+	inner := &sliceExecutor{users: []user{{Name: "Alice"}, {Name: "Bob"}}}
+	typed := Typed[user](inner)
+
+	users, result, err := typed.Execute(context.Background(), &query.Query{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []user{{Name: "Alice"}, {Name: "Bob"}}, users)
+	assert.Equal(t, 2, result.ItemsReturned)
+
+	count, err := typed.Count(context.Background(), &query.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.Equal(t, "slice", typed.Name())
+	assert.Same(t, inner, typed.Executor())
+	require.NoError(t, typed.Close())
+	assert.True(t, inner.closed)
+
+	t.Run("errors", func(t *testing.T) {
+		inner := &sliceExecutor{err: errors.New("boom")}
+		_, _, err := Typed[user](inner).Execute(context.Background(), &query.Query{}, "")
+		assert.EqualError(t, err, "boom")
+	})
+}
@@ -0,0 +1,39 @@
+package executor
+
+import (
+	"context"
+
+	query "github.com/hadi77ir/go-query/query"
+)
+
+// Writer is implemented by executors that delete or update the items matching
+// a query's filter. The filter is validated, authorized and scoped like Count
+// does; sorting, pagination and distinct options are ignored. Writes need
+// ExecutorOptions.AllowWrites and a filter, and return the number of items
+// deleted or updated
+type Writer interface {
+	DeleteWhere(ctx context.Context, q *query.Query) (int64, error)
+	UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error)
+}
+
+// DeleteWhere deletes the items matching q with e, or returns
+// query.ErrWritesNotSupported when e does not implement Writer.
+// Example: deleted, err := executor.DeleteWhere(ctx, e, q)
+func DeleteWhere(ctx context.Context, e Executor, q *query.Query) (int64, error) {
+	w, ok := e.(Writer)
+	if !ok {
+		return 0, query.ErrWritesNotSupported
+	}
+	return w.DeleteWhere(ctx, q)
+}
+
+// UpdateWhere sets the fields in changes on the items matching q with e, or
+// returns query.ErrWritesNotSupported when e does not implement Writer.
+// Example: updated, err := executor.UpdateWhere(ctx, e, q, map[string]interface{}{"status": "archived"})
+func UpdateWhere(ctx context.Context, e Executor, q *query.Query, changes map[string]interface{}) (int64, error) {
+	w, ok := e.(Writer)
+	if !ok {
+		return 0, query.ErrWritesNotSupported
+	}
+	return w.UpdateWhere(ctx, q, changes)
+}
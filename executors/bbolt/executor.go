@@ -0,0 +1,598 @@
+// Package bbolt executes queries against JSON- or gob-encoded values stored in
+// a bbolt (BoltDB) bucket. Filters are evaluated with the memory executor's
+// engine while scanning, so items never have to be loaded into a slice.
+package bbolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/executors/memory"
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/query"
+	bolt "go.etcd.io/bbolt"
+)
+
+// KeyField is the pseudo-field that sorts by bucket key (sort_by = _key).
+// Key order is the bucket's natural order and uses key-range pagination;
+// sorting by any other field loads the matching items and sorts them in memory.
+const KeyField = "_key"
+
+// DecodeFunc decodes a stored value into v, a pointer
+type DecodeFunc func(data []byte, v interface{}) error
+
+// GobDecode decodes gob-encoded values
+func GobDecode(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// Options configures the bbolt executor
+type Options struct {
+	*query.ExecutorOptions
+
+	// Bucket is the name of the top-level bucket holding the items
+	Bucket []byte
+
+	// Decode decodes stored values. Defaults to json.Unmarshal; use GobDecode for gob
+	Decode DecodeFunc
+
+	// NewItem returns a pointer to a new value that Count decodes items into.
+	// Defaults to *map[string]interface{}, which suits JSON values.
+	// Set it when values are gob-encoded, e.g. func() interface{} { return &Product{} }
+	NewItem func() interface{}
+
+	// FieldGetter is an optional custom function to retrieve field values
+	// If nil, reflection is used like in the memory executor
+	FieldGetter memory.FieldGetterFunc
+}
+
+// Executor is the bbolt implementation of the executor interface
+type Executor struct {
+	db              *bolt.DB
+	options         *Options
+	optionsProvider query.OptionsProvider
+}
+
+// NewExecutor creates a new bbolt executor.
+// If opts.ExecutorOptions is nil, default options sorted by KeyField are used.
+func NewExecutor(db *bolt.DB, opts *Options) executor.Executor {
+	if opts == nil {
+		opts = &Options{}
+	}
+	resolved := *opts
+	if resolved.ExecutorOptions == nil {
+		resolved.ExecutorOptions = DefaultExecutorOptions()
+	}
+	return &Executor{db: db, options: &resolved}
+}
+
+// NewExecutorWithOptionsProvider creates a new bbolt executor whose executor options are
+// fetched from the provider on every Execute/Count call. opts supplies the bucket and decoding settings;
+// its ExecutorOptions are ignored
+func NewExecutorWithOptionsProvider(db *bolt.DB, opts *Options, provider query.OptionsProvider) executor.Executor {
+	if opts == nil {
+		opts = &Options{}
+	}
+	resolved := *opts
+	resolved.ExecutorOptions = query.ResolveOptions(provider)
+	return &Executor{db: db, options: &resolved, optionsProvider: provider}
+}
+
+// DefaultExecutorOptions returns query.DefaultExecutorOptions sorted by KeyField
+func DefaultExecutorOptions() *query.ExecutorOptions {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = KeyField
+	return opts
+}
+
+// withCurrentOptions returns an executor bound to the provider's current options snapshot
+// Executors without a provider are returned unchanged
+func (e *Executor) withCurrentOptions() *Executor {
+	if e.optionsProvider == nil {
+		return e
+	}
+	bound := *e
+	resolved := *e.options
+	resolved.ExecutorOptions = query.ResolveOptions(e.optionsProvider)
+	bound.options = &resolved
+	return &bound
+}
+
+// Name returns the executor name
+func (e *Executor) Name() string {
+	return "bbolt"
+}
+
+// Close does nothing; the database is owned by the caller
+func (e *Executor) Close() error {
+	return nil
+}
+
+// Execute runs the query and stores results in dest
+// dest must be a pointer to a slice whose elements the stored values decode into
+func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "execute", q)
+	result, err := e.executeQuery(ctx, q, cursorParam, dest, &entry)
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+		result.Warnings = e.options.SearchTermWarnings(q)
+	}
+	e.options.LogExecute(ctx, entry, result, err)
+	return query.WrapResult(e.Name(), "execute", result, err)
+}
+
+func (e *Executor) executeQuery(ctx context.Context, q *query.Query, cursorParam string, dest interface{}, entry *query.QueryLog) (*query.Result, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.Elem().Kind() != reflect.Slice {
+		return nil, query.ErrInvalidDestination
+	}
+
+	sortField := q.SortBy
+	if sortField == "" {
+		sortField = e.options.DefaultSortField
+	}
+	if sortField != KeyField || q.SortOrder == query.SortOrderRandom || q.PreserveInOrder || q.Distinct || q.DistinctOn != "" {
+		entry.Filter = e.options.ScopedQuery(q).Filter
+		return e.executeInMemory(ctx, q, cursorParam, destVal)
+	}
+
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return nil, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
+	cursorData, err := cursor.Decode(cursorParam)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", query.ErrInvalidCursor, err)
+	}
+	if err := cursorData.CheckQuery(q); err != nil {
+		return nil, err
+	}
+	if cursorData == nil {
+		offset, err := e.options.PageOffset(q, e.options.ValidatePageSize(q.PageSize))
+		if err != nil {
+			return nil, err
+		}
+		cursorData = cursor.Jump(offset)
+	}
+	return e.executeKeyRange(ctx, q, cursorData, destVal)
+}
+
+// executeKeyRange pages through the bucket in key order, seeking past the
+// last key of the previous page. Pages jumped to by number have no last key
+// and skip their offset in matches instead
+func (e *Executor) executeKeyRange(ctx context.Context, q *query.Query, cursorData *cursor.CursorData, destVal reflect.Value) (*query.Result, error) {
+	pageSize := e.options.ValidatePageSize(q.PageSize)
+	itemsReturnedSoFar, offset := 0, 0
+	if cursorData != nil {
+		itemsReturnedSoFar = cursorData.ItemsReturned
+		offset = cursorData.Offset
+	}
+
+	// A prev cursor walks backwards from the first key of the page it came from
+	descending := q.SortOrder == query.SortOrderDesc
+	backwards := cursorData != nil && cursorData.Direction == "prev"
+	var explain *query.Explain
+	if q.ExplainRequested {
+		direction := "ascending"
+		if descending != backwards {
+			direction = "descending"
+		}
+		explain = &query.Explain{
+			Backend:   e.Name(),
+			Statement: query.FormatFilter(q.Filter),
+			Plan:      fmt.Sprintf("%s key range scan of bucket %q", direction, e.options.Bucket),
+		}
+	}
+	if q.Limit > 0 && !backwards {
+		remaining := q.Limit - itemsReturnedSoFar
+		if remaining <= 0 {
+			// Limit already reached, return empty result
+			total := query.TotalUnknown
+			err := e.db.View(func(tx *bolt.Tx) error {
+				if e.options.CountMode == query.CountNone {
+					return nil
+				}
+				var err error
+				total, err = e.count(ctx, tx, q.Filter, memory.NewMatcher(e.memoryOptions()), nil)
+				return err
+			})
+			if err != nil {
+				return nil, wrapError("count", err)
+			}
+			return &query.Result{TotalItems: total, Explain: explain}, nil
+		}
+		if pageSize > remaining {
+			pageSize = remaining
+		}
+	}
+	var after []byte
+	skip := 0
+	if cursorData != nil {
+		if key, ok := cursorData.LastID.(string); ok {
+			after = []byte(key)
+		} else if !backwards {
+			skip = offset
+		}
+	}
+
+	matcher := memory.NewMatcher(e.memoryOptions())
+	elemType := destVal.Elem().Type().Elem()
+	var (
+		page     []reflect.Value
+		keys     [][]byte
+		total    int64
+		hasAfter bool
+	)
+	err := e.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(e.options.Bucket)
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		forward := descending == backwards
+		k, v := seek(c, after, forward)
+		for ; k != nil; k, v = step(c, forward) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			item, err := e.decode(v, elemType)
+			if err != nil {
+				return err
+			}
+			match, err := matcher.Match(q.Filter, item.Interface())
+			if err != nil {
+				return err
+			}
+			if !match {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(page) == pageSize {
+				hasAfter = true
+				break
+			}
+			page = append(page, item)
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		// Counting takes a second scan of the bucket, which CountNone skips
+		if e.options.CountMode == query.CountNone {
+			total = query.TotalUnknown
+			return nil
+		}
+		var err error
+		total, err = e.count(ctx, tx, q.Filter, matcher, elemType)
+		return err
+	})
+	if err != nil {
+		return nil, wrapError("scan bucket", err)
+	}
+
+	if backwards {
+		reverseValues(page)
+		reverseKeys(keys)
+		offset -= len(page)
+		if offset < 0 {
+			offset = 0
+		}
+	}
+
+	destSlice := destVal.Elem()
+	destSlice.Set(reflect.MakeSlice(destSlice.Type(), 0, len(page)))
+	for _, item := range page {
+		destSlice.Set(reflect.Append(destSlice, item))
+	}
+
+	result := &query.Result{
+		TotalItems:    total,
+		ItemsReturned: len(page),
+		Explain:       explain,
+	}
+	if len(page) == 0 {
+		noRecords := total == 0 || total == query.TotalUnknown && cursorData == nil
+		if noRecords && !e.options.AllowEmptyResults {
+			return result, query.ErrNoRecordsFound
+		}
+		return result, nil
+	}
+	result.ShowingFrom = offset + 1
+	result.ShowingTo = offset + len(page)
+
+	// Items returned before and through this page, for limit enforcement
+	itemsBefore, itemsThrough := itemsReturnedSoFar, itemsReturnedSoFar+len(page)
+	if backwards {
+		itemsBefore, itemsThrough = itemsReturnedSoFar-len(page), itemsReturnedSoFar
+		if itemsBefore < 0 {
+			itemsBefore = 0
+		}
+	}
+
+	queryHash := cursor.QueryHash(q)
+	// Walking backwards always leaves the page the cursor came from ahead
+	hasNext := hasAfter || backwards
+	if q.Limit > 0 && itemsThrough >= q.Limit {
+		hasNext = false
+	}
+	if hasNext {
+		result.NextPageCursor, _ = cursor.Encode(&cursor.CursorData{
+			LastID:        string(keys[len(keys)-1]),
+			Offset:        offset + len(page),
+			Direction:     "next",
+			ItemsReturned: itemsThrough,
+			QueryHash:     queryHash,
+		})
+	}
+	if offset > 0 {
+		result.PrevPageCursor, _ = cursor.Encode(&cursor.CursorData{
+			LastID:        string(keys[0]),
+			Offset:        offset,
+			Direction:     "prev",
+			ItemsReturned: itemsBefore,
+			QueryHash:     queryHash,
+		})
+	}
+	return result, nil
+}
+
+// executeInMemory decodes every item and lets the memory executor filter,
+// sort and paginate them. Used for sorts that do not follow key order.
+func (e *Executor) executeInMemory(ctx context.Context, q *query.Query, cursorParam string, destVal reflect.Value) (*query.Result, error) {
+	items, err := e.scan(ctx, destVal.Elem().Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+	result, err := memory.NewExecutorWithOptions(items.Interface(), e.memoryOptions()).Execute(ctx, q, cursorParam, destVal.Interface())
+	// Report the error as this executor's
+	var memoryErr *query.Error
+	if errors.As(err, &memoryErr) {
+		err = memoryErr.Err
+	}
+	if result != nil && result.Explain != nil {
+		result.Explain.Backend = e.Name()
+		result.Explain.Plan = fmt.Sprintf("scan of bucket %q, sorted in memory; %s", e.options.Bucket, result.Explain.Plan)
+	}
+	return result, err
+}
+
+// scan decodes every value of the bucket into a slice of elemType
+func (e *Executor) scan(ctx context.Context, elemType reflect.Type) (reflect.Value, error) {
+	items := reflect.MakeSlice(reflect.SliceOf(elemType), 0, 0)
+	err := e.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(e.options.Bucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			item, err := e.decode(v, elemType)
+			if err != nil {
+				return err
+			}
+			items = reflect.Append(items, item)
+			return nil
+		})
+	})
+	if err != nil {
+		return items, wrapError("scan bucket", err)
+	}
+	return items, nil
+}
+
+// countInMemory counts distinct items with the memory executor, decoding
+// them into NewItem values
+func (e *Executor) countInMemory(ctx context.Context, q *query.Query) (int64, error) {
+	items, err := e.scan(ctx, reflect.TypeOf(e.newItem()).Elem())
+	if err != nil {
+		return 0, err
+	}
+	count, err := memory.NewExecutorWithOptions(items.Interface(), e.memoryOptions()).Count(ctx, q)
+	// Report the error as this executor's
+	var memoryErr *query.Error
+	if errors.As(err, &memoryErr) {
+		err = memoryErr.Err
+	}
+	return count, err
+}
+
+// Facets decodes every item into NewItem values and counts the facet buckets
+// of those matching the query with the memory executor
+func (e *Executor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	e = e.withCurrentOptions()
+	items, err := e.scan(ctx, reflect.TypeOf(e.newItem()).Elem())
+	if err != nil {
+		return nil, query.WrapError(e.Name(), "facets", err)
+	}
+	results, err := memory.NewExecutorWithOptions(items.Interface(), e.memoryOptions()).Facets(ctx, q, specs)
+	// Report the error as this executor's
+	var memoryErr *query.Error
+	if errors.As(err, &memoryErr) {
+		err = memoryErr.Err
+	}
+	return results, query.WrapError(e.Name(), "facets", err)
+}
+
+// Count returns the total number of items that would be returned by the given query
+func (e *Executor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "count", q)
+	count, err := e.countQuery(ctx, q, &entry)
+	e.options.LogCount(ctx, entry, count, err)
+	return count, query.WrapError(e.Name(), "count", err)
+}
+
+func (e *Executor) countQuery(ctx context.Context, q *query.Query, entry *query.QueryLog) (int64, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
+	}
+	if q.Distinct || q.DistinctOn != "" {
+		entry.Filter = e.options.ScopedQuery(q).Filter
+		return e.countInMemory(ctx, q)
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return 0, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
+	matcher := memory.NewMatcher(e.memoryOptions())
+	var total int64
+	err = e.db.View(func(tx *bolt.Tx) error {
+		var err error
+		total, err = e.count(ctx, tx, q.Filter, matcher, nil)
+		return err
+	})
+	if err != nil {
+		return 0, wrapError("count", err)
+	}
+	return total, nil
+}
+
+// count counts the items matching filter. Items are decoded into elemType,
+// or into NewItem values when elemType is nil
+func (e *Executor) count(ctx context.Context, tx *bolt.Tx, filter query.Node, matcher *memory.Matcher, elemType reflect.Type) (int64, error) {
+	bucket := tx.Bucket(e.options.Bucket)
+	if bucket == nil {
+		return 0, nil
+	}
+	if filter == nil {
+		return int64(bucket.Stats().KeyN), nil
+	}
+	var total int64
+	err := bucket.ForEach(func(k, v []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var item interface{}
+		if elemType != nil {
+			decoded, err := e.decode(v, elemType)
+			if err != nil {
+				return err
+			}
+			item = decoded.Interface()
+		} else {
+			item = e.newItem()
+			if err := e.decodeFunc()(v, item); err != nil {
+				return fmt.Errorf("decode %q: %w", k, err)
+			}
+		}
+		match, err := matcher.Match(filter, item)
+		if err != nil {
+			return err
+		}
+		if match {
+			total++
+		}
+		return nil
+	})
+	return total, err
+}
+
+// decode decodes a stored value into a new value of type t
+func (e *Executor) decode(data []byte, t reflect.Type) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	if err := e.decodeFunc()(data, ptr.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("decode value: %w", err)
+	}
+	return ptr.Elem(), nil
+}
+
+func (e *Executor) decodeFunc() DecodeFunc {
+	if e.options.Decode != nil {
+		return e.options.Decode
+	}
+	return json.Unmarshal
+}
+
+func (e *Executor) newItem() interface{} {
+	if e.options.NewItem != nil {
+		return e.options.NewItem()
+	}
+	return &map[string]interface{}{}
+}
+
+func (e *Executor) memoryOptions() *memory.MemoryExecutorOptions {
+	// This executor logs its queries, not the memory engine
+	opts := *e.options.ExecutorOptions
+	opts.QueryLogger = nil
+	return &memory.MemoryExecutorOptions{
+		ExecutorOptions: &opts,
+		FieldGetter:     e.options.FieldGetter,
+	}
+}
+
+// wrapError wraps storage errors as execution errors, keeping errors that
+// already carry a query error (e.g. from filter evaluation) intact
+func wrapError(operation string, err error) error {
+	var execErr *query.ExecutionError
+	if errors.As(err, &execErr) {
+		return err
+	}
+	return query.NewExecutionError(operation, err)
+}
+
+// seek positions the cursor on the first key after (or before, when walking
+// backwards) the given key, or on the first key in walking order when key is nil
+func seek(c *bolt.Cursor, key []byte, forward bool) ([]byte, []byte) {
+	if key == nil {
+		if forward {
+			return c.First()
+		}
+		return c.Last()
+	}
+	k, v := c.Seek(key)
+	if forward {
+		if k != nil && bytes.Equal(k, key) {
+			return c.Next()
+		}
+		return k, v
+	}
+	if k == nil {
+		return c.Last()
+	}
+	return c.Prev()
+}
+
+func step(c *bolt.Cursor, forward bool) ([]byte, []byte) {
+	if forward {
+		return c.Next()
+	}
+	return c.Prev()
+}
+
+func reverseValues(s []reflect.Value) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+func reverseKeys(s [][]byte) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
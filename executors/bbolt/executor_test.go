@@ -0,0 +1,333 @@
+package bbolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+type Product struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Category string  `json:"category"`
+	Price    float64 `json:"price"`
+}
+
+var bucket = []byte("products")
+
+func setupDB(t *testing.T, encode func(Product) []byte) *bolt.DB {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0o600, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket(bucket)
+		if err != nil {
+			return err
+		}
+		for i := 1; i <= 10; i++ {
+			category := "books"
+			if i%2 == 0 {
+				category = "electronics"
+			}
+			p := Product{ID: i, Name: fmt.Sprintf("Product %d", i), Category: category, Price: float64(i * 10)}
+			if err := b.Put([]byte(fmt.Sprintf("p%03d", i)), encode(p)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func jsonEncode(p Product) []byte {
+	data, _ := json.Marshal(p)
+	return data
+}
+
+func parse(t *testing.T, input string) *query.Query {
+	t.Helper()
+	p, err := parser.NewParser(input)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	return q
+}
+
+func ids(products []Product) []int {
+	result := make([]int, len(products))
+	for i, p := range products {
+		result[i] = p.ID
+	}
+	return result
+}
+
+func TestExecutor_KeyRangePagination(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: bucket})
+	ctx := context.Background()
+	q := parse(t, "category = electronics page_size = 2")
+
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 4}, ids(page))
+	assert.Equal(t, int64(5), result.TotalItems)
+	assert.Equal(t, 1, result.ShowingFrom)
+	assert.Equal(t, 2, result.ShowingTo)
+	assert.False(t, result.HasPrevPage())
+
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{6, 8}, ids(page))
+	assert.Equal(t, 3, result.ShowingFrom)
+
+	last, err := executor.Execute(ctx, q, result.NextPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{10}, ids(page))
+	assert.False(t, last.HasNextPage())
+
+	result, err = executor.Execute(ctx, q, last.PrevPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{6, 8}, ids(page))
+	assert.Equal(t, 3, result.ShowingFrom)
+	assert.True(t, result.HasNextPage())
+	assert.True(t, result.HasPrevPage())
+}
+
+func TestExecutor_KeyRangePageJump(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	opts := DefaultExecutorOptions()
+	opts.MaxPageOffset = 4
+	executor := NewExecutor(db, &Options{Bucket: bucket, ExecutorOptions: opts})
+	ctx := context.Background()
+	q := parse(t, "category = electronics page_size = 2 page = 2")
+
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{6, 8}, ids(page))
+	assert.Equal(t, 3, result.ShowingFrom)
+
+	next, err := executor.Execute(ctx, q, result.NextPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{10}, ids(page))
+	assert.Equal(t, 5, next.ShowingFrom)
+
+	_, err = executor.Execute(ctx, q, result.PrevPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 4}, ids(page))
+
+	_, err = executor.Execute(ctx, parse(t, "page_size = 2 page = 4"), "", &page)
+	assert.ErrorIs(t, err, query.ErrPageTooDeep)
+}
+
+func TestExecutor_KeyRangeDescending(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: bucket})
+	ctx := context.Background()
+	q := parse(t, "price > 50 sort_order = desc page_size = 3")
+
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 9, 8}, ids(page))
+
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{7, 6}, ids(page))
+	assert.False(t, result.HasNextPage())
+}
+
+func TestExecutor_Limit(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: bucket})
+	ctx := context.Background()
+	q := parse(t, "page_size = 2 limit = 3")
+
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids(page))
+
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, ids(page))
+	assert.False(t, result.HasNextPage())
+	assert.Equal(t, int64(10), result.TotalItems)
+}
+
+func TestExecutor_SortByField(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: bucket})
+	ctx := context.Background()
+	q := parse(t, "category = books sort_by = price sort_order = desc page_size = 2")
+
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{9, 7}, ids(page))
+	assert.Equal(t, int64(5), result.TotalItems)
+
+	_, err = executor.Execute(ctx, q, result.NextPageCursor, &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 3}, ids(page))
+}
+
+func TestExecutor_Explain(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: bucket})
+	ctx := context.Background()
+
+	q := parse(t, "category = books sort_order = desc page_size = 2")
+	q.ExplainRequested = true
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, &query.Explain{
+		Backend:   "bbolt",
+		Statement: `category = "books"`,
+		Plan:      `descending key range scan of bucket "products"`,
+	}, result.Explain)
+	assert.Positive(t, int64(result.ExecutionTime))
+
+	q = parse(t, "category = books sort_by = price page_size = 2")
+	q.ExplainRequested = true
+	result, err = executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, &query.Explain{
+		Backend:   "bbolt",
+		Statement: `category = "books"`,
+		Plan:      `scan of bucket "products", sorted in memory; full scan: 10 items`,
+	}, result.Explain)
+}
+
+func TestExecutor_Count(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	opts := DefaultExecutorOptions()
+	opts.BaseFilter = query.Eq("category", "books")
+	executor := NewExecutor(db, &Options{ExecutorOptions: opts, Bucket: bucket})
+	ctx := context.Background()
+
+	count, err := executor.Count(ctx, parse(t, "price >= 50"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	count, err = executor.Count(ctx, &query.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestExecutor_DistinctOn(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: bucket})
+	ctx := context.Background()
+
+	// The most expensive product of each category
+	q := parse(t, "distinct_on = category sort_by = price sort_order = desc")
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 9}, ids(page))
+	assert.Equal(t, int64(2), result.TotalItems)
+
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestExecutor_Gob(t *testing.T) {
+	db := setupDB(t, func(p Product) []byte {
+		var buf bytes.Buffer
+		_ = gob.NewEncoder(&buf).Encode(p)
+		return buf.Bytes()
+	})
+	executor := NewExecutor(db, &Options{
+		Bucket:  bucket,
+		Decode:  GobDecode,
+		NewItem: func() interface{} { return &Product{} },
+	})
+	ctx := context.Background()
+	q := parse(t, `name = "Product 3"`)
+
+	var results []Product
+	_, err := executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, ids(results))
+
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestExecutor_Errors(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	opts := DefaultExecutorOptions()
+	opts.AllowedFields = []string{"name"}
+	executor := NewExecutor(db, &Options{ExecutorOptions: opts, Bucket: bucket})
+	ctx := context.Background()
+
+	var results []Product
+	_, err := executor.Execute(ctx, parse(t, "price > 5"), "", &results)
+	assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+
+	_, err = executor.Execute(ctx, parse(t, "name = x"), "", results)
+	assert.True(t, errors.Is(err, query.ErrInvalidDestination))
+
+	_, err = executor.Execute(ctx, parse(t, "name = x"), "not-a-cursor", &results)
+	assert.True(t, errors.Is(err, query.ErrInvalidCursor))
+}
+
+func TestExecutor_MissingBucket(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: []byte("missing")})
+
+	var results []Product
+	result, err := executor.Execute(context.Background(), &query.Query{}, "", &results)
+	require.ErrorIs(t, err, query.ErrNoRecordsFound)
+	assert.Empty(t, results)
+	assert.Equal(t, int64(0), result.TotalItems)
+
+	opts := query.DefaultExecutorOptions()
+	opts.AllowEmptyResults = true
+	executor = NewExecutor(db, &Options{Bucket: []byte("missing"), ExecutorOptions: opts})
+	result, err = executor.Execute(context.Background(), &query.Query{}, "", &results)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.TotalItems)
+}
+
+func TestExecutor_Facets(t *testing.T) {
+	db := setupDB(t, jsonEncode)
+	executor := NewExecutor(db, &Options{Bucket: bucket})
+	ctx := context.Background()
+
+	results, err := executor.(*Executor).Facets(ctx, parse(t, "price > 30"), []query.FacetSpec{
+		{Field: "category"},
+		{Field: "price", Ranges: []query.FacetRange{{To: 60}, {From: 60}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []query.FacetBucket{
+		{Value: "electronics", Count: 4},
+		{Value: "books", Count: 3},
+	}, results[0].Buckets)
+	assert.Equal(t, []query.FacetBucket{
+		{Value: "*-60", Count: 2},
+		{Value: "60-*", Count: 5},
+	}, results[1].Buckets)
+
+	_, err = executor.(*Executor).Facets(ctx, &query.Query{}, []query.FacetSpec{{Field: "price", Ranges: []query.FacetRange{{}}}})
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+}
@@ -0,0 +1,24 @@
+module github.com/hadi77ir/go-query/executors/bbolt
+
+go 1.24.0
+
+require (
+	github.com/hadi77ir/go-query v1.4.0
+	github.com/hadi77ir/go-query/executors/memory v1.4.0
+	github.com/stretchr/testify v1.10.0
+	go.etcd.io/bbolt v1.3.10
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fxamacker/cbor/v2 v2.9.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/sys v0.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace (
+	github.com/hadi77ir/go-query => ../..
+	github.com/hadi77ir/go-query/executors/memory => ../memory
+)
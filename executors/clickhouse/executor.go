@@ -0,0 +1,472 @@
+// Package clickhouse executes queries against ClickHouse through database/sql.
+// It generates ClickHouse SQL directly (ILIKE, match(), startsWith(), LIMIT BY,
+// SAMPLE-based totals) instead of the generic SQL produced by the GORM executor.
+// Register a driver such as github.com/ClickHouse/clickhouse-go/v2 and pass the *sql.DB.
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Options configures the ClickHouse executor
+type Options struct {
+	*query.ExecutorOptions
+
+	// Table is the table, view or table function to query, e.g. "events" or "db.events"
+	Table string
+
+	// Columns lists the selected columns. Empty selects all columns.
+	// The sort and ID columns are added when missing so pages can be chained
+	Columns []string
+
+	// LimitBy returns at most LimitByCount rows per distinct combination of these
+	// columns (LIMIT n BY col, ...), e.g. the latest 3 events per user.
+	// Pages use offsets instead of keyset cursors when LimitBy is set
+	LimitBy []string
+
+	// LimitByCount is the n in LIMIT n BY. Defaults to 1 when LimitBy is set
+	LimitByCount int
+
+	// CountSampleRatio estimates TotalItems from a SAMPLE of the table
+	// (sum(_sample_factor) ... SAMPLE ratio) instead of an exact count().
+	// The table must declare SAMPLE BY. Result.TotalItemsEstimated marks estimates.
+	// Count is always exact. 0 (or 1 and above) counts exactly
+	CountSampleRatio float64
+}
+
+// Executor is the ClickHouse implementation of the executor interface
+type Executor struct {
+	db              *sql.DB
+	options         *Options
+	optionsProvider query.OptionsProvider
+}
+
+// NewExecutor creates a new ClickHouse executor
+// If opts.ExecutorOptions is nil, default options are used
+func NewExecutor(db *sql.DB, opts *Options) executor.Executor {
+	if opts == nil {
+		opts = &Options{}
+	}
+	resolved := *opts
+	if resolved.ExecutorOptions == nil {
+		resolved.ExecutorOptions = DefaultExecutorOptions()
+	}
+	return &Executor{db: db, options: &resolved}
+}
+
+// DefaultExecutorOptions returns query.DefaultExecutorOptions sorted by the "id" column
+func DefaultExecutorOptions() *query.ExecutorOptions {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	return opts
+}
+
+// NewExecutorWithOptionsProvider creates a new ClickHouse executor whose executor options
+// are fetched from the provider on every Execute/Count call. opts supplies the table and
+// ClickHouse settings; its ExecutorOptions are ignored
+func NewExecutorWithOptionsProvider(db *sql.DB, opts *Options, provider query.OptionsProvider) executor.Executor {
+	if opts == nil {
+		opts = &Options{}
+	}
+	resolved := *opts
+	resolved.ExecutorOptions = query.ResolveOptions(provider)
+	return &Executor{db: db, options: &resolved, optionsProvider: provider}
+}
+
+// withCurrentOptions returns an executor bound to the provider's current options snapshot
+// Executors without a provider are returned unchanged
+func (e *Executor) withCurrentOptions() *Executor {
+	if e.optionsProvider == nil {
+		return e
+	}
+	bound := *e
+	resolved := *e.options
+	resolved.ExecutorOptions = query.ResolveOptions(e.optionsProvider)
+	bound.options = &resolved
+	return &bound
+}
+
+// Name returns the name of this executor
+func (e *Executor) Name() string {
+	return "ClickHouse"
+}
+
+// Close cleans up resources (the *sql.DB is owned by the caller)
+func (e *Executor) Close() error {
+	return nil
+}
+
+// Execute runs the query and stores results in dest
+// dest must be a pointer to a slice of structs or map[string]interface{}.
+// Struct fields are matched to columns by `ch`, `db` or `json` tag, then by name
+func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "execute", q)
+	result, err := e.executeQuery(ctx, q, cursorParam, dest, &entry)
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+		result.Warnings = e.options.SearchTermWarnings(q)
+	}
+	e.options.LogExecute(ctx, entry, result, err)
+	return query.WrapResult(e.Name(), "execute", result, err)
+}
+
+func (e *Executor) executeQuery(ctx context.Context, q *query.Query, cursorParam string, dest interface{}, entry *query.QueryLog) (*query.Result, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.ValidateDistinct(q); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+	result := &query.Result{}
+
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		result.Error = query.ErrInvalidDestination
+		return result, result.Error
+	}
+
+	pageSize := e.options.ValidatePageSize(q.PageSize)
+
+	where, args, err := e.buildWhere(q.Filter)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	// Count total items; CountAsync counts while the page is fetched
+	waitCount := e.startCount(ctx, q, where, args)
+	finishCount := func() error {
+		counted := waitCount()
+		if counted.err != nil {
+			result.Error = counted.err
+			return result.Error
+		}
+		result.TotalItems, result.TotalItemsEstimated = counted.total, counted.estimated
+		return nil
+	}
+
+	// Handle cursor-based pagination
+	cursorData, err := cursor.Decode(cursorParam)
+	if err != nil {
+		result.Error = fmt.Errorf("%w: %v", query.ErrInvalidCursor, err)
+		return result, result.Error
+	}
+	if err := cursorData.CheckQuery(q); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	if cursorData == nil {
+		offset, err := e.options.PageOffset(q, pageSize)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		cursorData = cursor.Jump(offset)
+	}
+
+	page, err := e.buildPage(q, cursorData)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	// Handle limit enforcement
+	itemsReturnedSoFar := 0
+	if cursorData != nil {
+		itemsReturnedSoFar = cursorData.ItemsReturned
+	}
+	if q.Limit > 0 && !page.reversed {
+		remaining := q.Limit - itemsReturnedSoFar
+		if remaining <= 0 {
+			// Limit already reached, return empty result
+			return result, finishCount()
+		}
+		if pageSize > remaining {
+			pageSize = remaining
+		}
+	}
+
+	if page.where != "" {
+		if where == "" {
+			where, args = page.where, page.whereArgs
+		} else {
+			where = fmt.Sprintf("(%s) AND (%s)", where, page.where)
+			args = append(append([]interface{}{}, args...), page.whereArgs...)
+		}
+	}
+
+	stmt, stmtArgs := e.buildSelect(where, args, page, pageSize+1)
+	if q.ExplainRequested {
+		result.Explain = e.explain(ctx, stmt, stmtArgs)
+	}
+	rows, err := e.db.QueryContext(ctx, stmt, stmtArgs...)
+	if err != nil {
+		result.Error = query.NewExecutionError("execute query", err)
+		return result, result.Error
+	}
+	defer rows.Close()
+
+	keys, err := scanRows(rows, destValue.Elem(), e.getIDFieldName(), page.sortField)
+	if err != nil {
+		result.Error = query.NewExecutionError("fetch results", err)
+		return result, result.Error
+	}
+	if err := finishCount(); err != nil {
+		return result, err
+	}
+
+	sliceValue := destValue.Elem()
+	itemsCount := sliceValue.Len()
+
+	// Check if any records were found
+	// Without a total, an empty first page means none
+	noRecords := result.TotalItems == 0 || result.TotalItems == query.TotalUnknown && cursorData == nil
+	if itemsCount == 0 && noRecords && !e.options.AllowEmptyResults {
+		result.Error = query.ErrNoRecordsFound
+		return result, result.Error
+	}
+
+	// Check if there are more results
+	hasMore := itemsCount > pageSize
+	if hasMore {
+		sliceValue.Set(sliceValue.Slice(0, pageSize))
+		keys = keys[:pageSize]
+		itemsCount = pageSize
+	}
+	if page.reversed {
+		// A prev cursor walks backwards; restore the requested order.
+		// The page the cursor came from is always ahead
+		swap := reflect.Swapper(sliceValue.Interface())
+		for i, j := 0, itemsCount-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+		hasMore = true
+	}
+	result.ItemsReturned = itemsCount
+	if itemsCount == 0 {
+		return result, nil
+	}
+
+	// Offsets count the items before the page, which is also the number of
+	// items returned before it for limit enforcement
+	currentOffset := 0
+	if cursorData != nil {
+		currentOffset = cursorData.Offset
+		if page.reversed {
+			currentOffset -= itemsCount
+			if currentOffset < 0 {
+				currentOffset = 0
+			}
+		}
+	}
+	result.ShowingFrom = currentOffset + 1
+	result.ShowingTo = currentOffset + itemsCount
+	if q.Limit > 0 && currentOffset+itemsCount >= q.Limit {
+		hasMore = false
+	}
+
+	queryHash := cursor.QueryHash(q)
+	if hasMore {
+		next := &cursor.CursorData{
+			Direction:     "next",
+			Offset:        currentOffset + itemsCount,
+			ItemsReturned: currentOffset + itemsCount,
+			RandomSeed:    page.seed,
+			QueryHash:     queryHash,
+		}
+		if !page.offsetPaging {
+			last := keys[len(keys)-1]
+			next.LastID, next.LastSortValue = last.id, last.sort
+		}
+		if result.NextPageCursor, err = cursor.Encode(next); err != nil {
+			result.Error = query.NewExecutionError("encode next cursor", err)
+			return result, result.Error
+		}
+	}
+	if currentOffset > 0 {
+		prev := &cursor.CursorData{
+			Direction:  "prev",
+			Offset:     currentOffset,
+			RandomSeed: page.seed,
+			QueryHash:  queryHash,
+		}
+		if page.offsetPaging {
+			prev.Offset = currentOffset - pageSize
+			if prev.Offset < 0 {
+				prev.Offset = 0
+			}
+		} else {
+			prev.LastID, prev.LastSortValue = keys[0].id, keys[0].sort
+		}
+		prev.ItemsReturned = prev.Offset
+		if result.PrevPageCursor, err = cursor.Encode(prev); err != nil {
+			result.Error = query.NewExecutionError("encode prev cursor", err)
+			return result, result.Error
+		}
+	}
+	return result, nil
+}
+
+// countResult is the outcome of a count started by startCount
+type countResult struct {
+	total     int64
+	estimated bool
+	err       error
+}
+
+// startCount starts computing TotalItems for Execute as CountMode asks and
+// returns a function that waits for it. With CountAsync the count runs while
+// the caller fetches the page
+func (e *Executor) startCount(ctx context.Context, q *query.Query, where string, args []interface{}) func() countResult {
+	count := func() countResult {
+		total, estimated, err := e.countTotal(ctx, q, where, args)
+		return countResult{total: total, estimated: estimated, err: err}
+	}
+	switch e.options.CountMode {
+	case query.CountNone:
+		return func() countResult { return countResult{total: query.TotalUnknown} }
+	case query.CountAsync:
+		counted := make(chan countResult, 1)
+		go func() { counted <- count() }()
+		return func() countResult { return <-counted }
+	}
+	counted := count()
+	return func() countResult { return counted }
+}
+
+// countTotal returns the total for Execute, estimated from a SAMPLE when
+// CountSampleRatio is set. With CountEstimated, unfiltered queries read
+// total_rows from system.tables. Distinct queries are always counted exactly
+func (e *Executor) countTotal(ctx context.Context, q *query.Query, where string, args []interface{}) (int64, bool, error) {
+	if e.options.CountMode == query.CountEstimated && where == "" && !q.Distinct && q.DistinctOn == "" {
+		if total, ok := e.tableRows(ctx); ok {
+			return total, true, nil
+		}
+	}
+	ratio := e.options.CountSampleRatio
+	if ratio <= 0 || ratio >= 1 || q.Distinct || q.DistinctOn != "" {
+		total, err := e.count(ctx, q, where, args)
+		return total, false, err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "SELECT toInt64(round(sum(_sample_factor))) FROM %s SAMPLE %g", e.options.Table, ratio)
+	if where != "" {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(where)
+	}
+	var total sql.NullInt64
+	if err := e.db.QueryRowContext(ctx, sb.String(), args...).Scan(&total); err != nil {
+		return 0, false, query.NewExecutionError("estimate count", err)
+	}
+	return total.Int64, true, nil
+}
+
+// tableRows returns the row count ClickHouse keeps for the table in
+// system.tables. ok is false for tables without one, such as views
+func (e *Executor) tableRows(ctx context.Context) (int64, bool) {
+	stmt := "SELECT total_rows FROM system.tables WHERE database = currentDatabase() AND name = ?"
+	args := []interface{}{e.options.Table}
+	if db, table, ok := strings.Cut(e.options.Table, "."); ok {
+		stmt = "SELECT total_rows FROM system.tables WHERE database = ? AND name = ?"
+		args = []interface{}{db, table}
+	}
+	var total sql.NullInt64
+	if err := e.db.QueryRowContext(ctx, stmt, args...).Scan(&total); err != nil || !total.Valid {
+		return 0, false
+	}
+	return total.Int64, true
+}
+
+// count counts matching rows exactly. Distinct rows are counted in a subquery
+// that deduplicates them the way Execute does
+func (e *Executor) count(ctx context.Context, q *query.Query, where string, args []interface{}) (int64, error) {
+	if where != "" {
+		where = " WHERE " + where
+	}
+	stmt := fmt.Sprintf("SELECT count() FROM %s%s", e.options.Table, where)
+	switch {
+	case q.DistinctOn != "":
+		if !isValidField(q.DistinctOn) {
+			return 0, query.InvalidFieldNameError(q.DistinctOn)
+		}
+		stmt = fmt.Sprintf("SELECT count() FROM (SELECT %s FROM %s%s LIMIT 1 BY %s)",
+			q.DistinctOn, e.options.Table, where, q.DistinctOn)
+	case q.Distinct:
+		stmt = fmt.Sprintf("SELECT count() FROM (SELECT DISTINCT %s FROM %s%s)",
+			e.selectColumns(&page{}), e.options.Table, where)
+	}
+	var total int64
+	if err := e.db.QueryRowContext(ctx, stmt, args...).Scan(&total); err != nil {
+		return 0, query.NewExecutionError("count items", err)
+	}
+	return total, nil
+}
+
+// Count returns the total number of items that would be returned by the given query
+// This does not apply pagination - it counts all matching items
+func (e *Executor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "count", q)
+	count, err := e.countQuery(ctx, q, &entry)
+	e.options.LogCount(ctx, entry, count, err)
+	return count, query.WrapError(e.Name(), "count", err)
+}
+
+func (e *Executor) countQuery(ctx context.Context, q *query.Query, entry *query.QueryLog) (int64, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateDistinct(q); err != nil {
+		return 0, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
+	where, args, err := e.buildWhere(q.Filter)
+	if err != nil {
+		return 0, err
+	}
+	return e.count(ctx, q, where, args)
+}
+
+// getIDFieldName returns the ID field name to use, with fallback defaults
+func (e *Executor) getIDFieldName() string {
+	if e.options.IDFieldName != "" {
+		return e.options.IDFieldName
+	}
+	return "id"
+}
+
+// isIDField checks if a field name is the ID field
+func (e *Executor) isIDField(fieldName string) bool {
+	return strings.EqualFold(fieldName, e.getIDFieldName())
+}
@@ -0,0 +1,340 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResponse is the result set returned for one statement
+type fakeResponse struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeDriver replays scripted responses in order and records the statements it receives
+type fakeDriver struct {
+	mu        sync.Mutex
+	responses []fakeResponse
+	stmts     []string
+	args      [][]interface{}
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+func (d *fakeDriver) reply(columns []string, rows ...[]driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.responses = append(d.responses, fakeResponse{columns: columns, rows: rows})
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(_ context.Context, stmt string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.d.stmts = append(c.d.stmts, stmt)
+	c.d.args = append(c.d.args, values)
+	if len(c.d.responses) == 0 {
+		return nil, errors.New("unexpected statement: " + stmt)
+	}
+	resp := c.d.responses[0]
+	c.d.responses = c.d.responses[1:]
+	return &fakeRows{resp: resp}, nil
+}
+
+type fakeRows struct {
+	resp fakeResponse
+	pos  int
+}
+
+func (r *fakeRows) Columns() []string { return r.resp.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.resp.rows) {
+		return io.EOF
+	}
+	copy(dest, r.resp.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+type fakeConnector struct{ d *fakeDriver }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c fakeConnector) Driver() driver.Driver                        { return c.d }
+
+func setupFake(t *testing.T) (*sql.DB, *fakeDriver) {
+	t.Helper()
+	d := &fakeDriver{}
+	db := sql.OpenDB(fakeConnector{d: d})
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+type Event struct {
+	ID     int64  `ch:"id"`
+	UserID int64  `ch:"user_id"`
+	Path   string `json:"path"`
+	Hidden string `ch:"-"`
+}
+
+var eventColumns = []string{"id", "user_id", "path", "extra"}
+
+func eventRow(id int64) []driver.Value {
+	return []driver.Value{id, id * 10, "/p", "ignored"}
+}
+
+func TestExecutor_ExecuteKeysetPages(t *testing.T) {
+	db, d := setupFake(t)
+	exec := NewExecutor(db, &Options{Table: "events"})
+	ctx := context.Background()
+	q := &query.Query{Filter: query.Eq("path", "/p"), PageSize: 2}
+
+	d.reply([]string{"count()"}, []driver.Value{int64(5)})
+	d.reply(eventColumns, eventRow(1), eventRow(2), eventRow(3))
+
+	var events []Event
+	result, err := exec.Execute(ctx, q, "", &events)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{{ID: 1, UserID: 10, Path: "/p"}, {ID: 2, UserID: 20, Path: "/p"}}, events)
+	assert.Equal(t, int64(5), result.TotalItems)
+	assert.False(t, result.TotalItemsEstimated)
+	assert.Equal(t, 1, result.ShowingFrom)
+	assert.Equal(t, 2, result.ShowingTo)
+	assert.NotEmpty(t, result.NextPageCursor)
+	assert.Empty(t, result.PrevPageCursor)
+	assert.Equal(t, "SELECT count() FROM events WHERE path = ?", d.stmts[0])
+	assert.Equal(t, "SELECT * FROM events WHERE path = ? ORDER BY id ASC LIMIT 3", d.stmts[1])
+
+	d.reply([]string{"count()"}, []driver.Value{int64(5)})
+	d.reply(eventColumns, eventRow(3), eventRow(4), eventRow(5))
+
+	result, err = exec.Execute(ctx, q, result.NextPageCursor, &events)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, int64(3), events[0].ID)
+	assert.Equal(t, 3, result.ShowingFrom)
+	assert.NotEmpty(t, result.PrevPageCursor)
+	assert.Equal(t, "SELECT * FROM events WHERE (path = ?) AND (id > ?) ORDER BY id ASC LIMIT 3", d.stmts[3])
+	assert.Equal(t, []interface{}{"/p", int64(2)}, d.args[3])
+
+	// Walking back returns the first page in its original order
+	d.reply([]string{"count()"}, []driver.Value{int64(5)})
+	d.reply(eventColumns, eventRow(2), eventRow(1))
+
+	result, err = exec.Execute(ctx, q, result.PrevPageCursor, &events)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, int64(1), events[0].ID)
+	assert.Equal(t, int64(2), events[1].ID)
+	assert.NotEmpty(t, result.NextPageCursor)
+	assert.Equal(t, "SELECT * FROM events WHERE (path = ?) AND (id < ?) ORDER BY id DESC LIMIT 3", d.stmts[5])
+}
+
+func TestExecutor_ExecuteMaps(t *testing.T) {
+	db, d := setupFake(t)
+	exec := NewExecutor(db, &Options{Table: "events", Columns: []string{"user_id"}})
+
+	d.reply([]string{"count()"}, []driver.Value{int64(1)})
+	d.reply([]string{"user_id", "id"}, []driver.Value{int64(10), int64(1)})
+
+	var rows []map[string]interface{}
+	_, err := exec.Execute(context.Background(), &query.Query{}, "", &rows)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"user_id": int64(10), "id": int64(1)}}, rows)
+	assert.Equal(t, "SELECT user_id, id FROM events ORDER BY id ASC LIMIT 11", d.stmts[1])
+}
+
+func TestExecutor_ExecuteSampledTotal(t *testing.T) {
+	db, d := setupFake(t)
+	exec := NewExecutor(db, &Options{Table: "events", CountSampleRatio: 0.1})
+
+	d.reply([]string{"total"}, []driver.Value{int64(12340)})
+	d.reply(eventColumns, eventRow(1))
+
+	var events []Event
+	result, err := exec.Execute(context.Background(), &query.Query{Filter: query.Gt("user_id", 5)}, "", &events)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12340), result.TotalItems)
+	assert.True(t, result.TotalItemsEstimated)
+	assert.Equal(t, "SELECT toInt64(round(sum(_sample_factor))) FROM events SAMPLE 0.1 WHERE user_id > ?", d.stmts[0])
+}
+
+func TestExecutor_ExecuteCountModes(t *testing.T) {
+	ctx := context.Background()
+	newExec := func(mode query.CountMode) (executor.Executor, *fakeDriver) {
+		db, d := setupFake(t)
+		opts := DefaultExecutorOptions()
+		opts.CountMode = mode
+		return NewExecutor(db, &Options{ExecutorOptions: opts, Table: "analytics.events"}), d
+	}
+
+	t.Run("estimated from table statistics", func(t *testing.T) {
+		exec, d := newExec(query.CountEstimated)
+		d.reply([]string{"total_rows"}, []driver.Value{int64(98765)})
+		d.reply(eventColumns, eventRow(1))
+
+		var events []Event
+		result, err := exec.Execute(ctx, &query.Query{}, "", &events)
+		require.NoError(t, err)
+		assert.Equal(t, int64(98765), result.TotalItems)
+		assert.True(t, result.TotalItemsEstimated)
+		assert.Equal(t, "SELECT total_rows FROM system.tables WHERE database = ? AND name = ?", d.stmts[0])
+		assert.Equal(t, []interface{}{"analytics", "events"}, d.args[0])
+	})
+
+	t.Run("estimated counts filtered queries", func(t *testing.T) {
+		exec, d := newExec(query.CountEstimated)
+		d.reply([]string{"count()"}, []driver.Value{int64(3)})
+		d.reply(eventColumns, eventRow(1))
+
+		var events []Event
+		result, err := exec.Execute(ctx, &query.Query{Filter: query.Eq("path", "/p")}, "", &events)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.TotalItems)
+		assert.False(t, result.TotalItemsEstimated)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		exec, d := newExec(query.CountNone)
+		d.reply(eventColumns, eventRow(1))
+
+		var events []Event
+		result, err := exec.Execute(ctx, &query.Query{}, "", &events)
+		require.NoError(t, err)
+		assert.Equal(t, query.TotalUnknown, result.TotalItems)
+		assert.Len(t, d.stmts, 1, "no count statement")
+
+		d.reply(eventColumns)
+		_, err = exec.Execute(ctx, &query.Query{}, "", &events)
+		assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+	})
+}
+
+func TestExecutor_Count(t *testing.T) {
+	db, d := setupFake(t)
+	opts := DefaultExecutorOptions()
+	opts.BaseFilter = query.Eq("tenant", "acme")
+	exec := NewExecutor(db, &Options{ExecutorOptions: opts, Table: "events", CountSampleRatio: 0.5})
+
+	d.reply([]string{"count()"}, []driver.Value{int64(7)})
+
+	count, err := exec.Count(context.Background(), &query.Query{Filter: query.Eq("path", "/")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+	assert.Equal(t, "SELECT count() FROM events WHERE (path = ?) AND (tenant = ?)", d.stmts[0])
+}
+
+func TestExecutor_Distinct(t *testing.T) {
+	db, d := setupFake(t)
+	exec := NewExecutor(db, &Options{Table: "events", LimitBy: []string{"path"}, CountSampleRatio: 0.1})
+	ctx := context.Background()
+
+	// distinct_on replaces the configured LIMIT BY and counts exactly
+	q := &query.Query{Filter: query.Eq("path", "/p"), DistinctOn: "user_id", SortBy: "id", SortOrder: query.SortOrderDesc, PageSize: 2}
+	d.reply([]string{"count()"}, []driver.Value{int64(3)})
+	d.reply(eventColumns, eventRow(3), eventRow(2), eventRow(1))
+
+	var events []Event
+	result, err := exec.Execute(ctx, q, "", &events)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, int64(3), result.TotalItems)
+	assert.False(t, result.TotalItemsEstimated)
+	assert.Equal(t, "SELECT count() FROM (SELECT user_id FROM events WHERE path = ? LIMIT 1 BY user_id)", d.stmts[0])
+	assert.Equal(t, "SELECT * FROM events WHERE path = ? ORDER BY id DESC LIMIT 1 BY user_id LIMIT 3", d.stmts[1])
+
+	d.reply([]string{"count()"}, []driver.Value{int64(3)})
+	d.reply(eventColumns, eventRow(1))
+	_, err = exec.Execute(ctx, q, result.NextPageCursor, &events)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM events WHERE path = ? ORDER BY id DESC LIMIT 1 BY user_id LIMIT 3 OFFSET 2", d.stmts[3])
+
+	d.reply([]string{"count()"}, []driver.Value{int64(4)})
+	count, err := exec.Count(ctx, &query.Query{Distinct: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+	assert.Equal(t, "SELECT count() FROM (SELECT DISTINCT * FROM events)", d.stmts[4])
+
+	_, err = exec.Count(ctx, &query.Query{DistinctOn: "user id"})
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
+
+func TestExecutor_ExecuteErrors(t *testing.T) {
+	db, d := setupFake(t)
+	exec := NewExecutor(db, &Options{Table: "events"})
+	ctx := context.Background()
+
+	var events []Event
+	_, err := exec.Execute(ctx, &query.Query{}, "", events)
+	assert.ErrorIs(t, err, query.ErrInvalidDestination)
+
+	d.reply([]string{"count()"}, []driver.Value{int64(0)})
+	d.reply(eventColumns)
+	_, err = exec.Execute(ctx, &query.Query{}, "", &events)
+	assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+
+	// No scripted response makes the driver fail the statement
+	_, err = exec.Execute(ctx, &query.Query{}, "", &events)
+	var execErr *query.ExecutionError
+	assert.ErrorAs(t, err, &execErr)
+}
+
+func TestExecutor_Explain(t *testing.T) {
+	db, d := setupFake(t)
+	exec := NewExecutor(db, &Options{Table: "events"})
+	q := &query.Query{Filter: query.Eq("path", "/p"), ExplainRequested: true}
+
+	d.reply([]string{"count()"}, []driver.Value{int64(1)})
+	d.reply([]string{"explain"},
+		[]driver.Value{"Expression ((Projection + Before ORDER BY))"},
+		[]driver.Value{"  ReadFromMergeTree (default.events)"},
+		[]driver.Value{"  Indexes:"},
+		[]driver.Value{"    PrimaryKey"},
+		[]driver.Value{"    Skip"},
+		[]driver.Value{"      Name: path_idx"},
+	)
+	d.reply(eventColumns, eventRow(1))
+
+	var events []Event
+	result, err := exec.Execute(context.Background(), q, "", &events)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "EXPLAIN indexes = 1 SELECT * FROM events WHERE path = ? ORDER BY id ASC LIMIT 11", d.stmts[1])
+	assert.Equal(t, &query.Explain{
+		Backend:   "ClickHouse",
+		Statement: "SELECT * FROM events WHERE path = ? ORDER BY id ASC LIMIT 11",
+		Args:      []interface{}{"/p"},
+		Plan:      "Expression ((Projection + Before ORDER BY))\n  ReadFromMergeTree (default.events)\n  Indexes:\n    PrimaryKey\n    Skip\n      Name: path_idx",
+		Indexes:   []string{"PrimaryKey", "path_idx"},
+	}, result.Explain)
+
+	// Plan errors do not fail the query
+	d.reply([]string{"count()"}, []driver.Value{int64(1)})
+	d.reply([]string{"a", "b"}, []driver.Value{"x", "y"})
+	d.reply(eventColumns, eventRow(1))
+	result, err = exec.Execute(context.Background(), q, "", &events)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.NotEmpty(t, result.Explain.PlanError)
+	assert.Empty(t, result.Explain.Plan)
+}
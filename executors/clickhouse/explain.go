@@ -0,0 +1,44 @@
+package clickhouse
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// explain returns stmt with the output of EXPLAIN indexes = 1 for it. Index
+// names come from the "Name:" lines of skip indexes; "PrimaryKey" is listed
+// when the primary key prunes granules. Failing to get the plan is reported in
+// PlanError
+func (e *Executor) explain(ctx context.Context, stmt string, args []interface{}) *query.Explain {
+	explain := &query.Explain{Backend: e.Name(), Statement: stmt, Args: args}
+	rows, err := e.db.QueryContext(ctx, "EXPLAIN indexes = 1 "+stmt, args...)
+	if err != nil {
+		explain.PlanError = err.Error()
+		return explain
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			explain.PlanError = err.Error()
+			return explain
+		}
+		lines = append(lines, line)
+		switch trimmed := strings.TrimSpace(line); {
+		case trimmed == "PrimaryKey":
+			explain.Indexes = append(explain.Indexes, trimmed)
+		case strings.HasPrefix(trimmed, "Name: "):
+			explain.Indexes = append(explain.Indexes, strings.TrimPrefix(trimmed, "Name: "))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		explain.PlanError = err.Error()
+		return explain
+	}
+	explain.Plan = strings.Join(lines, "\n")
+	return explain
+}
@@ -0,0 +1,199 @@
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Facets counts the buckets of each spec among the rows matching the query:
+// value facets and histograms GROUP BY the column or its bin, range facets
+// use one countIf per range.
+// Each spec runs one statement
+func (e *Executor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	e = e.withCurrentOptions()
+	results, err := e.facets(ctx, q, specs)
+	return results, query.WrapError(e.Name(), "facets", err)
+}
+
+func (e *Executor) facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return nil, err
+	}
+	specs, err = e.options.ValidateFacets(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+	q = e.options.ScopedQuery(q)
+
+	where, args, err := e.buildWhere(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]query.FacetResult, len(specs))
+	for i, spec := range specs {
+		stmt, stmtArgs, err := e.buildFacet(spec, where, args)
+		if err != nil {
+			return nil, err
+		}
+		var buckets []query.FacetBucket
+		switch {
+		case len(spec.Ranges) > 0:
+			buckets, err = e.countRanges(ctx, spec, stmt, stmtArgs)
+		case spec.IsHistogram():
+			buckets, err = e.countBins(ctx, stmt, stmtArgs)
+		default:
+			buckets, err = e.countValues(ctx, spec, stmt, stmtArgs)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[i] = query.FacetResult{Field: spec.Field, Buckets: buckets}
+	}
+	return results, nil
+}
+
+// buildFacet builds the statement counting the buckets of spec among the rows
+// matching where
+func (e *Executor) buildFacet(spec query.FacetSpec, where string, args []interface{}) (string, []interface{}, error) {
+	column, err := e.column(spec.Field)
+	if err != nil {
+		return "", nil, err
+	}
+	if spec.IsHistogram() {
+		if where != "" {
+			where = "(" + where + ") AND "
+		}
+		where = " WHERE " + where + column + " IS NOT NULL"
+		return fmt.Sprintf("SELECT %s AS facet_value, count() AS facet_count FROM %s%s GROUP BY facet_value ORDER BY facet_value",
+			histogramBin(column, spec), e.options.Table, where), args, nil
+	}
+	if where != "" {
+		where = " WHERE " + where
+	}
+	if len(spec.Ranges) == 0 {
+		stmt := fmt.Sprintf("SELECT %s AS facet_value, count() AS facet_count FROM %s%s GROUP BY %s ORDER BY facet_count DESC, facet_value",
+			column, e.options.Table, where, column)
+		if spec.Limit > 0 {
+			stmt += fmt.Sprintf(" LIMIT %d", spec.Limit)
+		}
+		return stmt, args, nil
+	}
+
+	counts := make([]string, len(spec.Ranges))
+	var stmtArgs []interface{}
+	for i, r := range spec.Ranges {
+		var conditions []string
+		for _, bound := range []struct {
+			op    string
+			value interface{}
+		}{{">=", r.From}, {"<", r.To}} {
+			if bound.value == nil {
+				continue
+			}
+			value, err := e.convertValue(spec.Field, bound.value)
+			if err != nil {
+				return "", nil, query.NewFieldError(spec.Field, err)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s ?", column, bound.op))
+			stmtArgs = append(stmtArgs, value)
+		}
+		counts[i] = fmt.Sprintf("countIf(%s)", strings.Join(conditions, " AND "))
+	}
+	stmt := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(counts, ", "), e.options.Table, where)
+	return stmt, append(stmtArgs, args...), nil
+}
+
+// countValues runs a value facet statement
+func (e *Executor) countValues(ctx context.Context, spec query.FacetSpec, stmt string, args []interface{}) ([]query.FacetBucket, error) {
+	rows, err := e.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, query.NewExecutionError("count facet values", err)
+	}
+	defer rows.Close()
+
+	var buckets []query.FacetBucket
+	for rows.Next() {
+		var bucket query.FacetBucket
+		if err := rows.Scan(&bucket.Value, &bucket.Count); err != nil {
+			return nil, query.NewExecutionError("scan facet values", err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, query.NewExecutionError("scan facet values", err)
+	}
+	return query.SortFacetBuckets(buckets, spec.Limit), nil
+}
+
+// countBins runs a histogram statement
+func (e *Executor) countBins(ctx context.Context, stmt string, args []interface{}) ([]query.FacetBucket, error) {
+	rows, err := e.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, query.NewExecutionError("count histogram", err)
+	}
+	defer rows.Close()
+
+	var buckets []query.FacetBucket
+	for rows.Next() {
+		var bucket query.FacetBucket
+		if err := rows.Scan(&bucket.Value, &bucket.Count); err != nil {
+			return nil, query.NewExecutionError("scan histogram", err)
+		}
+		if t, ok := bucket.Value.(time.Time); ok {
+			bucket.Value = t.UTC()
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, query.NewExecutionError("scan histogram", err)
+	}
+	return buckets, nil
+}
+
+// histogramBin returns the start of the bin holding column:
+// floor(column / interval) * interval for numbers, or toStartOfDay and its
+// siblings for dates, in UTC
+func histogramBin(column string, spec query.FacetSpec) string {
+	if spec.DateInterval == "" {
+		interval := strconv.FormatFloat(spec.Interval, 'f', -1, 64)
+		return fmt.Sprintf("floor(%s / %s) * %s", column, interval, interval)
+	}
+	function := map[query.DateInterval]string{
+		query.DateIntervalHour:  "toStartOfHour",
+		query.DateIntervalDay:   "toStartOfDay",
+		query.DateIntervalWeek:  "toMonday",
+		query.DateIntervalMonth: "toStartOfMonth",
+		query.DateIntervalYear:  "toStartOfYear",
+	}[spec.DateInterval]
+	return fmt.Sprintf("%s(toDateTime(%s, 'UTC'))", function, column)
+}
+
+// countRanges runs a range facet statement
+func (e *Executor) countRanges(ctx context.Context, spec query.FacetSpec, stmt string, args []interface{}) ([]query.FacetBucket, error) {
+	counts := make([]sql.NullInt64, len(spec.Ranges))
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := e.db.QueryRowContext(ctx, stmt, args...).Scan(dest...); err != nil {
+		return nil, query.NewExecutionError("count facet ranges", err)
+	}
+	buckets := make([]query.FacetBucket, len(spec.Ranges))
+	for i, r := range spec.Ranges {
+		buckets[i] = query.FacetBucket{Value: r.Label, Count: counts[i].Int64}
+	}
+	return buckets, nil
+}
@@ -0,0 +1,137 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// rowKey holds the cursor values of one scanned row
+type rowKey struct {
+	id   interface{}
+	sort interface{}
+}
+
+// scanRows scans all rows into slice, replacing its contents, and returns the
+// ID and sort values of each row for cursors.
+// Elements may be structs, pointers to structs or map[string]interface{}.
+func scanRows(rows *sql.Rows, slice reflect.Value, idField, sortField string) ([]rowKey, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	idIndex, sortIndex := columnIndex(columns, idField), columnIndex(columns, sortField)
+
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	baseType := elemType
+	if isPtr {
+		baseType = elemType.Elem()
+	}
+	isMap := baseType.Kind() == reflect.Map
+	if isMap && (baseType.Key().Kind() != reflect.String || baseType.Elem().Kind() != reflect.Interface) {
+		return nil, fmt.Errorf("unsupported destination element %s", elemType)
+	}
+	if !isMap && baseType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported destination element %s", elemType)
+	}
+
+	var fields [][]int
+	if !isMap {
+		fields = structFields(baseType, columns)
+	}
+
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	var keys []rowKey
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		targets := make([]interface{}, len(columns))
+		elem := reflect.New(baseType).Elem()
+		if isMap {
+			elem.Set(reflect.MakeMap(baseType))
+		}
+		for i := range columns {
+			if !isMap && fields[i] != nil {
+				targets[i] = elem.FieldByIndex(fields[i]).Addr().Interface()
+			} else {
+				targets[i] = &values[i]
+			}
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+
+		// Read cursor values from whichever target received them
+		key := rowKey{}
+		if idIndex >= 0 {
+			key.id = reflect.ValueOf(targets[idIndex]).Elem().Interface()
+		}
+		if sortIndex >= 0 {
+			key.sort = reflect.ValueOf(targets[sortIndex]).Elem().Interface()
+		}
+		keys = append(keys, key)
+
+		if isMap {
+			for i, column := range columns {
+				elem.SetMapIndex(reflect.ValueOf(column), reflect.ValueOf(&values[i]).Elem())
+			}
+		}
+		if isPtr {
+			elem = elem.Addr()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return keys, rows.Err()
+}
+
+// structFields maps each column to a struct field index, or nil if no field matches.
+// Fields are matched by `ch`, `db` or `json` tag, then case-insensitively by name
+func structFields(t reflect.Type, columns []string) [][]int {
+	byName := make(map[string][]int)
+	byFold := make(map[string][]int)
+fieldLoop:
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		for _, tag := range []string{"ch", "db", "json"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				continue fieldLoop
+			}
+			if name != "" {
+				if _, ok := byName[name]; !ok {
+					byName[name] = f.Index
+				}
+				break
+			}
+		}
+		if _, ok := byFold[strings.ToLower(f.Name)]; !ok {
+			byFold[strings.ToLower(f.Name)] = f.Index
+		}
+	}
+
+	fields := make([][]int, len(columns))
+	for i, column := range columns {
+		if index, ok := byName[column]; ok {
+			fields[i] = index
+		} else if index, ok := byFold[strings.ToLower(column)]; ok {
+			fields[i] = index
+		}
+	}
+	return fields
+}
+
+// columnIndex returns the position of field among columns, or -1
+func columnIndex(columns []string, field string) int {
+	if field == "" {
+		return -1
+	}
+	for i, column := range columns {
+		if strings.EqualFold(column, field) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,529 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// page describes the ordering and position of one page of results
+type page struct {
+	// orderBy is the ORDER BY expression, with orderArgs bound to its placeholders
+	orderBy   string
+	orderArgs []interface{}
+
+	// where restricts keyset pages to rows after (or before) the cursor position
+	where     string
+	whereArgs []interface{}
+
+	// sortField is the column whose value keyset cursors record ("" for offset paging)
+	sortField string
+
+	// offset is the number of rows skipped: the cursor position under offset
+	// paging, or the start of a page jumped to by number under keyset ordering
+	offset       int
+	offsetPaging bool
+
+	// reversed is set when a prev cursor walks backwards through a keyset ordering
+	reversed bool
+
+	// limitBy keeps at most limitByCount rows per combination of these columns
+	limitBy      []string
+	limitByCount int
+
+	// distinct selects with SELECT DISTINCT
+	distinct bool
+
+	// seed is the random ordering seed carried in cursors
+	seed int64
+}
+
+// buildPage builds the ordering for a query. Regular sorting pages by keyset
+// (sort value, ID); preserved IN order, match counts, random order and LIMIT BY page by offset.
+// distinct_on is LIMIT 1 BY the field, replacing Options.LimitBy
+func (e *Executor) buildPage(q *query.Query, cursorData *cursor.CursorData) (*page, error) {
+	if err := e.options.ValidateSortField(q.SortBy); err != nil {
+		return nil, err
+	}
+	sortField := q.SortBy
+	if sortField == "" {
+		sortField = e.options.DefaultSortField
+	}
+	sortOrder := q.SortOrder
+	// If sort order is not explicitly set (remains default), use executor default
+	if sortOrder == query.SortOrderAsc {
+		sortOrder = e.options.DefaultSortOrder
+	}
+
+	// Relevance scores are not available from plain SQL
+	if sortField == query.ScoreField {
+		return nil, fmt.Errorf("%w: sorting by %s is not supported by the ClickHouse executor", query.ErrInvalidQuery, query.ScoreField)
+	}
+
+	inOrder, err := query.InOrderCondition(q)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &page{distinct: q.Distinct && q.DistinctOn == ""}
+	if cursorData != nil {
+		p.offset = cursorData.Offset
+	}
+	if q.DistinctOn != "" {
+		if !isValidField(q.DistinctOn) {
+			return nil, query.InvalidFieldNameError(q.DistinctOn)
+		}
+		p.limitBy, p.limitByCount = []string{q.DistinctOn}, 1
+	} else if len(e.options.LimitBy) > 0 {
+		p.limitBy, p.limitByCount = e.options.LimitBy, e.options.LimitByCount
+		if p.limitByCount <= 0 {
+			p.limitByCount = 1
+		}
+	}
+	idField := e.getIDFieldName()
+	if !isValidField(idField) {
+		return nil, query.InvalidFieldNameError(idField)
+	}
+
+	switch {
+	case inOrder != nil:
+		// indexOf returns the 1-based position of the value in the array literal
+		values, err := e.convertArrayValue(inOrder.Field, inOrder.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !isValidField(inOrder.Field) {
+			return nil, query.InvalidFieldNameError(inOrder.Field)
+		}
+		p.offsetPaging = true
+		if len(values) > 0 {
+			p.orderBy = fmt.Sprintf("indexOf([%s], %s)", placeholders(len(values)), inOrder.Field)
+			p.orderArgs = values
+		}
+		return p, nil
+
+	case sortField == query.MatchCountField:
+		// Most matched CONTAINS conditions first, ties by ID
+		clauses, err := e.options.MatchClauses(q.Filter)
+		if err != nil {
+			return nil, err
+		}
+		terms := make([]string, len(clauses))
+		for i, c := range clauses {
+			where, args, err := e.buildFilter(c)
+			if err != nil {
+				return nil, err
+			}
+			terms[i] = fmt.Sprintf("if(%s, 1, 0)", where)
+			p.orderArgs = append(p.orderArgs, args...)
+		}
+		p.offsetPaging = true
+		p.orderBy = fmt.Sprintf("(%s) DESC, %s", strings.Join(terms, " + "), idField)
+		return p, nil
+
+	case sortOrder == query.SortOrderRandom:
+		if !e.options.AllowRandomOrder {
+			return nil, query.ErrRandomOrderNotAllowed
+		}
+		// Hashing the ID with a seed gives a random order that is stable across pages
+		var cursorSeed int64
+		if cursorData != nil {
+			cursorSeed = cursorData.RandomSeed
+		}
+		p.seed = query.ResolveRandomSeed(q, cursorSeed)
+		p.offsetPaging = true
+		p.orderBy = fmt.Sprintf("cityHash64(%s, ?), %s", idField, idField)
+		p.orderArgs = []interface{}{p.seed}
+		return p, nil
+	}
+
+	if !isValidField(sortField) {
+		return nil, query.InvalidFieldNameError(sortField)
+	}
+	direction := "ASC"
+	if sortOrder == query.SortOrderDesc {
+		direction = "DESC"
+	}
+
+	if len(p.limitBy) > 0 {
+		// Keyset positions are meaningless once LIMIT BY drops rows per group
+		p.offsetPaging = true
+		p.orderBy = orderClause(sortField, idField, direction, e.isIDField(sortField))
+		return p, nil
+	}
+
+	p.sortField = sortField
+	if cursorData != nil && cursorData.LastID != nil {
+		if cursorData.Direction == "prev" {
+			p.reversed = true
+			direction = flip(direction)
+		}
+		p.where, p.whereArgs = e.buildCursorFilter(cursorData, sortField, idField, direction)
+		p.offset = 0
+	}
+	p.orderBy = orderClause(sortField, idField, direction, e.isIDField(sortField))
+	return p, nil
+}
+
+// buildCursorFilter restricts rows to those after the cursor position in the given direction
+func (e *Executor) buildCursorFilter(cursorData *cursor.CursorData, sortField, idField, direction string) (string, []interface{}) {
+	op := ">"
+	if direction == "DESC" {
+		op = "<"
+	}
+	if e.isIDField(sortField) {
+		return fmt.Sprintf("%s %s ?", idField, op), []interface{}{cursorData.LastID}
+	}
+	// Ties on the sort field are broken by ID
+	return fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", sortField, op, sortField, idField, op),
+		[]interface{}{cursorData.LastSortValue, cursorData.LastSortValue, cursorData.LastID}
+}
+
+// buildSelect assembles the SELECT statement for a page
+func (e *Executor) buildSelect(where string, args []interface{}, p *page, limit int) (string, []interface{}) {
+	var sb strings.Builder
+	stmtArgs := append([]interface{}{}, args...)
+
+	sb.WriteString("SELECT ")
+	if p.distinct {
+		sb.WriteString("DISTINCT ")
+	}
+	fmt.Fprintf(&sb, "%s FROM %s", e.selectColumns(p), e.options.Table)
+	if where != "" {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(where)
+	}
+	if p.orderBy != "" {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(p.orderBy)
+		stmtArgs = append(stmtArgs, p.orderArgs...)
+	}
+	if len(p.limitBy) > 0 {
+		fmt.Fprintf(&sb, " LIMIT %d BY %s", p.limitByCount, strings.Join(p.limitBy, ", "))
+	}
+	fmt.Fprintf(&sb, " LIMIT %d", limit)
+	if p.offset > 0 {
+		fmt.Fprintf(&sb, " OFFSET %d", p.offset)
+	}
+	return sb.String(), stmtArgs
+}
+
+// selectColumns returns the select list, adding the columns cursors need
+func (e *Executor) selectColumns(p *page) string {
+	if len(e.options.Columns) == 0 {
+		return "*"
+	}
+	columns := append([]string{}, e.options.Columns...)
+	for _, required := range []string{e.getIDFieldName(), p.sortField} {
+		if required != "" && !containsFold(columns, required) {
+			columns = append(columns, required)
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// buildWhere converts the filter into a WHERE expression with positional arguments
+func (e *Executor) buildWhere(node query.Node) (string, []interface{}, error) {
+	if node == nil {
+		return "", nil, nil
+	}
+	if len(e.options.LimitBy) > 0 {
+		for _, column := range e.options.LimitBy {
+			if !isValidField(column) {
+				return "", nil, query.InvalidFieldNameError(column)
+			}
+		}
+	}
+	return e.buildFilter(node)
+}
+
+// buildFilter converts the AST filter into ClickHouse SQL
+func (e *Executor) buildFilter(node query.Node) (string, []interface{}, error) {
+	switch n := node.(type) {
+	case *query.BinaryOpNode:
+		if n.Operator != query.BinaryOpAnd && n.Operator != query.BinaryOpOr {
+			return "", nil, query.ErrInvalidQuery
+		}
+		// Chains of one operator are written flat: (a) AND (b) AND (c)
+		var clauses []string
+		var args []interface{}
+		for _, operand := range query.Operands(n) {
+			clause, operandArgs, err := e.buildFilter(operand)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses = append(clauses, "("+clause+")")
+			args = append(args, operandArgs...)
+		}
+		return strings.Join(clauses, " "+strings.ToUpper(n.Operator.String())+" "), args, nil
+
+	case *query.ComparisonNode:
+		field := n.Field
+		if field == query.SearchField {
+			if expanded := e.options.ExpandPhrase(n, true); expanded != n {
+				return e.buildFilter(expanded)
+			}
+			if len(e.options.DefaultSearchFields) > 0 {
+				// Expand bare terms to an OR across all default search fields
+				return e.buildFilter(query.ExpandDefaultSearch(n, e.options.DefaultSearchFields))
+			}
+			field = e.options.DefaultSearchField
+		}
+
+		// Check if field is in allowed list (security)
+		if !e.options.IsFieldAllowed(field) {
+			return "", nil, query.FieldNotAllowedError(field, e.options.AllowedFields...)
+		}
+		// Validate field name to prevent SQL injection
+		column, err := e.column(field)
+		if err != nil {
+			return "", nil, err
+		}
+		if n.Modifier != query.ArrayModifierNone {
+			return e.buildArrayComparison(field, column, n)
+		}
+		return e.buildComparison(field, column, n)
+
+	default:
+		return "", nil, query.ErrInvalidQuery
+	}
+}
+
+// buildComparison translates a single comparison of column, which holds
+// field, using ClickHouse functions
+func (e *Executor) buildComparison(field, column string, n *query.ComparisonNode) (string, []interface{}, error) {
+	switch n.Operator {
+	case query.OpIn, query.OpNotIn:
+		arr, err := e.convertArrayValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(arr) == 0 {
+			if n.Operator == query.OpIn {
+				return "0", nil, nil // Empty IN matches nothing
+			}
+			return "1", nil, nil
+		}
+		op := "IN"
+		if n.Operator == query.OpNotIn {
+			op = "NOT IN"
+		}
+		return fmt.Sprintf("%s %s (%s)", column, op, placeholders(len(arr))), arr, nil
+	}
+
+	val, err := e.convertValue(field, n.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	str := fmt.Sprintf("%v", val)
+
+	switch n.Operator {
+	case query.OpEqual:
+		if lo, hi, ok := e.options.FloatRange(val); ok {
+			return fmt.Sprintf("%s BETWEEN ? AND ?", column), []interface{}{lo, hi}, nil
+		}
+		return fmt.Sprintf("%s = ?", column), []interface{}{val}, nil
+	case query.OpNotEqual:
+		if lo, hi, ok := e.options.FloatRange(val); ok {
+			return fmt.Sprintf("%s NOT BETWEEN ? AND ?", column), []interface{}{lo, hi}, nil
+		}
+		return fmt.Sprintf("%s != ?", column), []interface{}{val}, nil
+	case query.OpGreaterThan:
+		return fmt.Sprintf("%s > ?", column), []interface{}{val}, nil
+	case query.OpGreaterThanOrEqual:
+		return fmt.Sprintf("%s >= ?", column), []interface{}{val}, nil
+	case query.OpLessThan:
+		return fmt.Sprintf("%s < ?", column), []interface{}{val}, nil
+	case query.OpLessThanOrEqual:
+		return fmt.Sprintf("%s <= ?", column), []interface{}{val}, nil
+	case query.OpLike:
+		return fmt.Sprintf("%s LIKE ?", column), []interface{}{val}, nil
+	case query.OpNotLike:
+		return fmt.Sprintf("%s NOT LIKE ?", column), []interface{}{val}, nil
+	case query.OpContains:
+		// position() avoids LIKE wildcard handling in the search text
+		return fmt.Sprintf("position(%s, ?) > 0", column), []interface{}{str}, nil
+	case query.OpIContains:
+		return fmt.Sprintf("%s ILIKE ?", column), []interface{}{"%" + escapeLike(str) + "%"}, nil
+	case query.OpStartsWith:
+		return fmt.Sprintf("startsWith(%s, ?)", column), []interface{}{str}, nil
+	case query.OpEndsWith:
+		return fmt.Sprintf("endsWith(%s, ?)", column), []interface{}{str}, nil
+	case query.OpRegex:
+		if e.options.DisableRegex {
+			return "", nil, query.ErrRegexNotSupported
+		}
+		// match() uses RE2 and searches unanchored like REGEXP
+		return fmt.Sprintf("match(%s, ?)", column), []interface{}{e.options.ConditionPattern(n, str)}, nil
+	case query.OpMatch:
+		return e.buildMatchClause(column, str)
+	default:
+		return "", nil, query.ErrInvalidQuery
+	}
+}
+
+// arrayElement is the lambda parameter naming each element in arrayExists
+const arrayElement = "x"
+
+// buildArrayComparison translates LENGTH, ANY and ALL conditions on an Array column:
+//
+//	tags LENGTH > 3         length(tags) > ?
+//	tags ANY = "usb"        arrayExists(x -> x = ?, tags)
+//	tags ALL IN [usb, hub]  hasAll(tags, [?, ?])
+func (e *Executor) buildArrayComparison(field, column string, n *query.ComparisonNode) (string, []interface{}, error) {
+	if err := query.ValidateArrayCondition(n); err != nil {
+		return "", nil, err
+	}
+
+	switch n.Modifier {
+	case query.ArrayModifierLength:
+		count, ok := n.Value.(query.IntValue)
+		if !ok {
+			return "", nil, query.NewFieldError(field, fmt.Errorf("%w: LENGTH must be compared with an integer", query.ErrInvalidQuery))
+		}
+		return fmt.Sprintf("length(%s) %s ?", column, n.Operator), []interface{}{int64(count)}, nil
+
+	case query.ArrayModifierAny:
+		element := &query.ComparisonNode{Field: n.Field, Operator: n.Operator, Value: n.Value}
+		cond, args, err := e.buildComparison(field, arrayElement, element)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("arrayExists(%s -> %s, %s)", arrayElement, cond, column), args, nil
+
+	default:
+		arr, err := e.convertArrayValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(arr) == 0 {
+			return "1", nil, nil
+		}
+		return fmt.Sprintf("hasAll(%s, [%s])", column, placeholders(len(arr))), arr, nil
+	}
+}
+
+// buildMatchClause builds a full-text MATCH clause
+//   - FullTextTemplate, if set
+//   - Otherwise every search term must appear as a whole token, which can use
+//     tokenbf_v1 skip indexes
+func (e *Executor) buildMatchClause(field string, search string) (string, []interface{}, error) {
+	if e.options.FullTextTemplate != "" {
+		return fmt.Sprintf(e.options.FullTextTemplate, field), []interface{}{search}, nil
+	}
+	terms := query.SearchTerms(search)
+	if len(terms) == 0 {
+		return "0", nil, nil
+	}
+	clauses := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		clauses[i] = fmt.Sprintf("hasTokenCaseInsensitive(%s, ?)", field)
+		args[i] = term
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")", args, nil
+}
+
+// convertValue converts query values to driver values and applies ValueConverter if configured
+func (e *Executor) convertValue(field string, val interface{}) (interface{}, error) {
+	var baseValue interface{}
+	switch v := val.(type) {
+	case query.StringValue:
+		baseValue = string(v)
+	case query.IntValue:
+		baseValue = int64(v)
+	case query.FloatValue:
+		baseValue = float64(v)
+	case query.BoolValue:
+		baseValue = bool(v)
+	case query.DateTimeValue:
+		baseValue = time.Time(v)
+	default:
+		baseValue = val
+	}
+	return e.options.ConvertValue(field, baseValue)
+}
+
+// convertArrayValue converts an array value to a slice and applies ValueConverter if configured
+func (e *Executor) convertArrayValue(field string, val interface{}) ([]interface{}, error) {
+	arr, ok := val.(query.ArrayValue)
+	if !ok {
+		arr = query.ArrayValue{val}
+	}
+	result := make([]interface{}, len(arr))
+	for i, v := range arr {
+		converted, err := e.convertValue(field, v)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = converted
+	}
+	return result, nil
+}
+
+// column returns the column of field in a condition. Plain identifiers are
+// used as is; other names, such as "order date", are accepted only when
+// listed in AllowedFields and are quoted with backticks
+func (e *Executor) column(field string) (string, error) {
+	if isValidField(field) {
+		return field, nil
+	}
+	if !e.options.IsFieldListed(field) {
+		return "", query.InvalidFieldNameError(field)
+	}
+	return "`" + strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(field) + "`", nil
+}
+
+// isValidField validates field names to prevent SQL injection
+// Only allows alphanumeric characters and underscores, must start with letter or underscore
+func isValidField(field string) bool {
+	if len(field) == 0 {
+		return false
+	}
+	first := field[0]
+	if !((first >= 'a' && first <= 'z') || (first >= 'A' && first <= 'Z') || first == '_') {
+		return false
+	}
+	for i := 1; i < len(field); i++ {
+		c := field[i]
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func orderClause(sortField, idField, direction string, sortIsID bool) string {
+	if sortIsID {
+		return fmt.Sprintf("%s %s", idField, direction)
+	}
+	return fmt.Sprintf("%s %s, %s %s", sortField, direction, idField, direction)
+}
+
+func flip(direction string) string {
+	if direction == "ASC" {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// escapeLike escapes LIKE wildcards so the text matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package clickhouse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestExecutor(opts *Options) *Executor {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Table == "" {
+		opts.Table = "events"
+	}
+	return NewExecutor(nil, opts).(*Executor)
+}
+
+func TestBuildWhere_Operators(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	opts.DefaultSearchField = "title"
+	e := newTestExecutor(&Options{ExecutorOptions: opts})
+
+	tests := []struct {
+		input string
+		where string
+		args  []interface{}
+	}{
+		{`status = "ok"`, "status = ?", []interface{}{"ok"}},
+		{`count >= 5`, "count >= ?", []interface{}{int64(5)}},
+		{`name contains "a_b"`, "position(name, ?) > 0", []interface{}{"a_b"}},
+		{`name icontains "50%"`, "name ILIKE ?", []interface{}{`%50\%%`}},
+		{`path starts_with "/api"`, "startsWith(path, ?)", []interface{}{"/api"}},
+		{`path ends_with ".js"`, "endsWith(path, ?)", []interface{}{".js"}},
+		{`agent regex "^curl/"`, "match(agent, ?)", []interface{}{"^curl/"}},
+		{`level IN ["warn", "error"]`, "level IN (?, ?)", []interface{}{"warn", "error"}},
+		{`level NOT IN []`, "1", nil},
+		{`body match "disk full"`, "(hasTokenCaseInsensitive(body, ?) AND hasTokenCaseInsensitive(body, ?))", []interface{}{"disk", "full"}},
+		{`timeout`, "position(title, ?) > 0", []interface{}{"timeout"}},
+		{`a = 1 OR b = 2`, "(a = ?) OR (b = ?)", []interface{}{int64(1), int64(2)}},
+		{`tags LENGTH >= 2`, "length(tags) >= ?", []interface{}{int64(2)}},
+		{`tags ANY STARTS_WITH "db"`, "arrayExists(x -> startsWith(x, ?), tags)", []interface{}{"db"}},
+		{`codes ANY IN [500, 503]`, "arrayExists(x -> x IN (?, ?), codes)", []interface{}{int64(500), int64(503)}},
+		{`tags ALL IN ["disk", "full"]`, "hasAll(tags, [?, ?])", []interface{}{"disk", "full"}},
+		{`tags ALL IN []`, "1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := parser.ParseFilter(tt.input)
+			require.NoError(t, err)
+			where, args, err := e.buildWhere(filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.where, where)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestBuildWhere_Regex(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	opts.AnchorRegex = true
+	e := newTestExecutor(&Options{ExecutorOptions: opts})
+
+	filter, err := parser.ParseFilter(`agent regex "curl.*"`)
+	require.NoError(t, err)
+	_, args, err := e.buildWhere(filter)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"^(?:curl.*)$"}, args)
+
+	opts.DisableRegex = true
+	_, _, err = e.buildWhere(filter)
+	assert.ErrorIs(t, err, query.ErrRegexNotSupported)
+}
+
+func TestBuildWhere_RejectsInvalidFields(t *testing.T) {
+	e := newTestExecutor(nil)
+	_, _, err := e.buildWhere(query.Eq("name; DROP TABLE events", "x"))
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+
+	e = newTestExecutor(&Options{LimitBy: []string{"user id"}})
+	_, _, err = e.buildWhere(query.Eq("name", "x"))
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
+
+func TestBuildWhere_QuotedFields(t *testing.T) {
+	filter, err := parser.ParseFilter("`order date` >= 5 AND [\"tag`list\"] ANY = usb")
+	require.NoError(t, err)
+
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"order date", "tag`list"}
+	where, args, err := newTestExecutor(&Options{ExecutorOptions: opts}).buildWhere(filter)
+	require.NoError(t, err)
+	assert.Equal(t, "(`order date` >= ?) AND (arrayExists(x -> x = ?, `tag\\`list`))", where)
+	assert.Equal(t, []interface{}{int64(5), "usb"}, args)
+
+	// Names that are not identifiers must be listed, even when every field is allowed
+	_, _, err = newTestExecutor(nil).buildWhere(filter)
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
+
+func TestBuildPage(t *testing.T) {
+	e := newTestExecutor(nil)
+
+	t.Run("keyset", func(t *testing.T) {
+		q := &query.Query{SortBy: "created_at", SortOrder: query.SortOrderDesc}
+		p, err := e.buildPage(q, &cursor.CursorData{LastID: int64(7), LastSortValue: "2024-01-01", Direction: "next"})
+		require.NoError(t, err)
+		assert.Equal(t, "created_at DESC, id DESC", p.orderBy)
+		assert.Equal(t, "(created_at < ? OR (created_at = ? AND id < ?))", p.where)
+		assert.False(t, p.reversed)
+	})
+
+	t.Run("keyset prev", func(t *testing.T) {
+		q := &query.Query{SortBy: "id"}
+		p, err := e.buildPage(q, &cursor.CursorData{LastID: int64(7), Direction: "prev"})
+		require.NoError(t, err)
+		assert.Equal(t, "id DESC", p.orderBy)
+		assert.Equal(t, "id < ?", p.where)
+		assert.True(t, p.reversed)
+	})
+
+	t.Run("keyset jump", func(t *testing.T) {
+		// A page jumped to by number skips by offset; later keyset pages do not
+		q := &query.Query{SortBy: "id", Page: 3, PageSize: 10}
+		p, err := e.buildPage(q, cursor.Jump(20))
+		require.NoError(t, err)
+		assert.Empty(t, p.where)
+		assert.Equal(t, 20, p.offset)
+		stmt, _ := e.buildSelect("", nil, p, 11)
+		assert.Equal(t, "SELECT * FROM events ORDER BY id ASC LIMIT 11 OFFSET 20", stmt)
+
+		p, err = e.buildPage(q, &cursor.CursorData{LastID: int64(30), Offset: 30, Direction: "next"})
+		require.NoError(t, err)
+		assert.Equal(t, "id > ?", p.where)
+		assert.Zero(t, p.offset)
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		q := &query.Query{Filter: query.In("id", 3, 1, 2), PreserveInOrder: true}
+		p, err := e.buildPage(q, nil)
+		require.NoError(t, err)
+		assert.True(t, p.offsetPaging)
+		assert.Equal(t, "indexOf([?, ?, ?], id)", p.orderBy)
+	})
+
+	t.Run("score", func(t *testing.T) {
+		q := &query.Query{SortBy: query.ScoreField, SortOrder: query.SortOrderDesc}
+		_, err := e.buildPage(q, nil)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+
+	t.Run("match count", func(t *testing.T) {
+		q := &query.Query{
+			Filter: query.Or(query.F("name").Contains("usb").Node(), query.F("description").IContains("hub").Node()),
+			SortBy: query.MatchCountField,
+		}
+		p, err := e.buildPage(q, &cursor.CursorData{Offset: 10})
+		require.NoError(t, err)
+		assert.True(t, p.offsetPaging)
+		assert.Equal(t, 10, p.offset)
+		assert.Equal(t, "(if(position(name, ?) > 0, 1, 0) + if(description ILIKE ?, 1, 0)) DESC, id", p.orderBy)
+		assert.Equal(t, []interface{}{"usb", "%hub%"}, p.orderArgs)
+
+		_, err = e.buildPage(&query.Query{Filter: query.Eq("name", "x"), SortBy: query.MatchCountField}, nil)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		q := &query.Query{SortOrder: query.SortOrderRandom}
+		opts := DefaultExecutorOptions()
+		opts.AllowRandomOrder = false
+		_, err := newTestExecutor(&Options{ExecutorOptions: opts}).buildPage(q, nil)
+		assert.ErrorIs(t, err, query.ErrRandomOrderNotAllowed)
+
+		p, err := e.buildPage(q, &cursor.CursorData{RandomSeed: 42, Offset: 20})
+		require.NoError(t, err)
+		assert.Equal(t, "cityHash64(id, ?), id", p.orderBy)
+		assert.Equal(t, []interface{}{int64(42)}, p.orderArgs)
+		assert.Equal(t, 20, p.offset)
+
+		// The first page uses the query's seed
+		p, err = e.buildPage(&query.Query{SortOrder: query.SortOrderRandom, RandomSeed: 7}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{int64(7)}, p.orderArgs)
+	})
+}
+
+func TestBuildSelect_LimitBy(t *testing.T) {
+	e := newTestExecutor(&Options{Columns: []string{"user_id", "path"}, LimitBy: []string{"user_id"}, LimitByCount: 3})
+	q := &query.Query{Filter: query.Eq("path", "/"), SortBy: "created_at", SortOrder: query.SortOrderDesc}
+
+	where, args, err := e.buildWhere(q.Filter)
+	require.NoError(t, err)
+	p, err := e.buildPage(q, &cursor.CursorData{Offset: 10})
+	require.NoError(t, err)
+
+	stmt, stmtArgs := e.buildSelect(where, args, p, 11)
+	assert.Equal(t, "SELECT user_id, path, id FROM events WHERE path = ? ORDER BY created_at DESC, id DESC LIMIT 3 BY user_id LIMIT 11 OFFSET 10", stmt)
+	assert.Equal(t, []interface{}{"/"}, stmtArgs)
+}
+
+func TestBuildFacet(t *testing.T) {
+	e := newTestExecutor(nil)
+	specs, err := e.options.ValidateFacets(context.Background(), []query.FacetSpec{
+		{Field: "level", Limit: 3},
+		{Field: "duration", Ranges: []query.FacetRange{{To: 100}, {From: 100, To: 1000}}},
+	})
+	require.NoError(t, err)
+	where, args, err := e.buildWhere(query.Eq("status", "ok"))
+	require.NoError(t, err)
+
+	stmt, stmtArgs, err := e.buildFacet(specs[0], where, args)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT level AS facet_value, count() AS facet_count FROM events WHERE status = ? GROUP BY level ORDER BY facet_count DESC, facet_value LIMIT 3", stmt)
+	assert.Equal(t, []interface{}{"ok"}, stmtArgs)
+
+	stmt, stmtArgs, err = e.buildFacet(specs[1], where, args)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT countIf(duration < ?), countIf(duration >= ? AND duration < ?) FROM events WHERE status = ?", stmt)
+	assert.Equal(t, []interface{}{int64(100), int64(100), int64(1000), "ok"}, stmtArgs)
+
+	stmt, _, err = e.buildFacet(query.FacetSpec{Field: "duration", Interval: 250}, where, args)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT floor(duration / 250) * 250 AS facet_value, count() AS facet_count FROM events WHERE (status = ?) AND duration IS NOT NULL GROUP BY facet_value ORDER BY facet_value", stmt)
+
+	stmt, _, err = e.buildFacet(query.FacetSpec{Field: "ts", DateInterval: query.DateIntervalWeek}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT toMonday(toDateTime(ts, 'UTC')) AS facet_value, count() AS facet_count FROM events WHERE ts IS NOT NULL GROUP BY facet_value ORDER BY facet_value", stmt)
+
+	_, _, err = e.buildFacet(query.FacetSpec{Field: "a;b"}, "", nil)
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
@@ -0,0 +1,335 @@
+// Package federated runs the same query against several executors, e.g. hot
+// data in memory and cold data in MongoDB, and merges their results into one
+// page ordered by the query's sort, with duplicate items returned once.
+//
+// Cursors are composite: they record the position of every child, so the
+// next page continues each child where the merge stopped taking its items.
+// Pagination is forward only and page jumps (page = N) are not supported.
+//
+// ShardedExecutor builds on the merge to route each query to the shards
+// holding the tenants its filter selects.
+package federated
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// FieldGetterFunc retrieves a field value from an item
+type FieldGetterFunc func(item interface{}, field string) (interface{}, error)
+
+// Options configures a federated executor
+type Options struct {
+	*query.ExecutorOptions
+
+	// IDField names the field that identifies an item across children; items
+	// with the same ID are returned once. Defaults to IDFieldName. Nothing is
+	// deduplicated when both are empty
+	IDField string
+
+	// FieldGetter reads the sort and ID fields of items. If nil, struct fields
+	// are matched by name (case-insensitive) or json/bson tag and maps by key
+	FieldGetter FieldGetterFunc
+}
+
+// Executor runs queries against child executors and merges their results.
+// The children must sort the same way: by the query's sort field, or
+// DefaultSortField and DefaultSortOrder of the executor's options when the
+// query sets none
+type Executor struct {
+	children []executor.Executor
+	options  *Options
+
+	// names identify the children in cursors. Only set by ShardedExecutor,
+	// whose children depend on the query
+	names []string
+}
+
+// NewExecutor creates an executor merging the results of children, in order
+// of precedence: on equal sort values, items of earlier children come first.
+// opts may be nil to use query.DefaultExecutorOptions
+func NewExecutor(children []executor.Executor, opts *Options) *Executor {
+	resolved := Options{}
+	if opts != nil {
+		resolved = *opts
+	}
+	if resolved.ExecutorOptions == nil {
+		resolved.ExecutorOptions = query.DefaultExecutorOptions()
+	}
+	if resolved.IDField == "" {
+		resolved.IDField = resolved.IDFieldName
+	}
+	return &Executor{children: children, options: &resolved}
+}
+
+// Name returns the name of this executor
+func (e *Executor) Name() string {
+	return "federated"
+}
+
+// Close closes every child
+func (e *Executor) Close() error {
+	var errs []error
+	for _, child := range e.children {
+		if err := child.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Count returns the sum of the children's counts. Items stored in several
+// children are counted once per child
+func (e *Executor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	counts := make([]int64, len(e.children))
+	errs := make([]error, len(e.children))
+	var wg sync.WaitGroup
+	for i, child := range e.children {
+		wg.Add(1)
+		go func(i int, child executor.Executor) {
+			defer wg.Done()
+			counts[i], errs[i] = child.Count(ctx, q)
+		}(i, child)
+	}
+	wg.Wait()
+
+	var total int64
+	for i := range e.children {
+		if errs[i] != nil {
+			return 0, query.WrapError(e.Name(), "count", errs[i])
+		}
+		total += counts[i]
+	}
+	return total, nil
+}
+
+// Execute runs the query on every child and stores the merged page in dest.
+// TotalItems is the sum of the children's totals, so items stored in several
+// children are counted once per child
+func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	result, err := e.executeQuery(ctx, q, cursorParam, dest)
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+	}
+	return query.WrapResult(e.Name(), "execute", result, err)
+}
+
+func (e *Executor) executeQuery(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.Elem().Kind() != reflect.Slice {
+		return nil, query.ErrInvalidDestination
+	}
+	if q.SortOrder == query.SortOrderRandom {
+		return nil, fmt.Errorf("%w: random order cannot be merged across executors", query.ErrInvalidQuery)
+	}
+
+	cursorData, err := cursor.Decode(cursorParam)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", query.ErrInvalidCursor, err)
+	}
+	if err := cursorData.CheckQuery(q); err != nil {
+		return nil, err
+	}
+	positions := make([]cursor.ChildCursor, len(e.children))
+	for i, name := range e.names {
+		positions[i].Name = name
+	}
+	itemsReturnedSoFar := 0
+	if cursorData != nil {
+		if len(cursorData.Children) != len(e.children) {
+			return nil, fmt.Errorf("%w: cursor has %d children, executor has %d",
+				query.ErrInvalidCursor, len(cursorData.Children), len(e.children))
+		}
+		copy(positions, cursorData.Children)
+		for i, name := range e.names {
+			if positions[i].Name != name {
+				return nil, fmt.Errorf("%w: cursor was generated for other shards", query.ErrInvalidCursor)
+			}
+		}
+		itemsReturnedSoFar = cursorData.ItemsReturned
+	} else if q.Page > 1 {
+		return nil, fmt.Errorf("%w: page jumps cannot be merged across executors, use cursors", query.ErrInvalidQuery)
+	}
+
+	pageSize := e.options.ValidatePageSize(q.PageSize)
+	if q.Limit > 0 && q.Limit-itemsReturnedSoFar < pageSize {
+		pageSize = q.Limit - itemsReturnedSoFar
+	}
+	destSlice := destVal.Elem()
+	destSlice.Set(reflect.MakeSlice(destSlice.Type(), 0, max(pageSize, 0)))
+	if pageSize <= 0 {
+		// Limit already reached
+		return &query.Result{TotalItems: totalItems(positions, nil)}, nil
+	}
+
+	childQuery := *q
+	childQuery.PageSize = pageSize
+	childQuery.Page = 0
+
+	sizes := make([]int, len(e.children))
+	for i := range positions {
+		if !positions[i].Done {
+			sizes[i] = pageSize
+		}
+	}
+	pages, err := e.fetch(ctx, &childQuery, positions, sizes, destSlice.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	merged := e.merge(pages, q, pageSize)
+	for _, item := range merged.items {
+		destSlice.Set(reflect.Append(destSlice, item))
+	}
+	if err := e.advance(ctx, &childQuery, positions, pages, destSlice.Type()); err != nil {
+		return nil, err
+	}
+
+	itemsReturned := len(merged.items)
+	result := &query.Result{
+		TotalItems:    totalItems(positions, pages),
+		ItemsReturned: itemsReturned,
+		Scores:        merged.scores,
+		Highlights:    merged.highlights,
+	}
+	for _, page := range pages {
+		if page != nil && page.result.TotalItemsEstimated {
+			result.TotalItemsEstimated = true
+		}
+	}
+	if itemsReturned > 0 {
+		result.ShowingFrom = itemsReturnedSoFar + 1
+		result.ShowingTo = itemsReturnedSoFar + itemsReturned
+	}
+
+	hasNext := false
+	for _, position := range positions {
+		if !position.Done {
+			hasNext = true
+		}
+	}
+	if q.Limit > 0 && itemsReturnedSoFar+itemsReturned >= q.Limit {
+		hasNext = false
+	}
+	if hasNext {
+		result.NextPageCursor, _ = cursor.Encode(&cursor.CursorData{
+			Direction:     "next",
+			ItemsReturned: itemsReturnedSoFar + itemsReturned,
+			QueryHash:     cursor.QueryHash(q),
+			Children:      positions,
+		})
+	}
+
+	if itemsReturnedSoFar+itemsReturned == 0 && !e.options.AllowEmptyResults {
+		return result, query.ErrNoRecordsFound
+	}
+	return result, nil
+}
+
+// childPage is a page of items returned by one child
+type childPage struct {
+	items  reflect.Value // slice of the destination type
+	result *query.Result
+	taken  int // items consumed by the merge
+}
+
+// fetch runs q on every child with a non-zero size, from its position, with
+// that page size. Children without records return an empty page
+func (e *Executor) fetch(ctx context.Context, q *query.Query, positions []cursor.ChildCursor, sizes []int, sliceType reflect.Type) ([]*childPage, error) {
+	pages := make([]*childPage, len(e.children))
+	errs := make([]error, len(e.children))
+	var wg sync.WaitGroup
+	for i, child := range e.children {
+		if sizes[i] <= 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, child executor.Executor) {
+			defer wg.Done()
+			sized := *q
+			sized.PageSize = sizes[i]
+			items := reflect.New(sliceType)
+			result, err := child.Execute(ctx, &sized, positions[i].Cursor, items.Interface())
+			if errors.Is(err, query.ErrNoRecordsFound) {
+				err = nil
+			}
+			if result == nil {
+				result = &query.Result{}
+			}
+			pages[i], errs[i] = &childPage{items: items.Elem(), result: result}, err
+		}(i, child)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+// advance moves the position of every child past the items the merge took
+// from it. A child whose page was only partly taken runs again with a page of
+// the taken items, which yields the cursor right after them
+func (e *Executor) advance(ctx context.Context, q *query.Query, positions []cursor.ChildCursor, pages []*childPage, sliceType reflect.Type) error {
+	sizes := make([]int, len(e.children))
+	for i, page := range pages {
+		if page == nil {
+			continue
+		}
+		positions[i].TotalItems = page.result.TotalItems
+		switch {
+		case page.taken == 0 && page.items.Len() > 0:
+			// Not reached yet; stay in place
+		case page.taken == page.items.Len():
+			positions[i].Cursor = page.result.NextPageCursor
+			positions[i].Done = page.result.NextPageCursor == ""
+		default:
+			sizes[i] = page.taken
+		}
+	}
+
+	rerun, err := e.fetch(ctx, q, positions, sizes, sliceType)
+	if err != nil {
+		return err
+	}
+	for i, page := range rerun {
+		if page == nil {
+			continue
+		}
+		positions[i].Cursor = page.result.NextPageCursor
+		positions[i].Done = page.result.NextPageCursor == ""
+	}
+	return nil
+}
+
+// totalItems sums the totals of the children, from the pages they returned
+// now or their positions otherwise. It returns query.TotalUnknown if any
+// child's total is unknown
+func totalItems(positions []cursor.ChildCursor, pages []*childPage) int64 {
+	var total int64
+	for i, position := range positions {
+		childTotal := position.TotalItems
+		if pages != nil && pages[i] != nil {
+			childTotal = pages[i].result.TotalItems
+		}
+		if childTotal == query.TotalUnknown {
+			return query.TotalUnknown
+		}
+		total += childTotal
+	}
+	return total
+}
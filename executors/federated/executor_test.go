@@ -0,0 +1,164 @@
+package federated
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/executors/memory"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Product struct {
+	ID    int     `json:"id"`
+	Name  string  `json:"name"`
+	Brand string  `json:"brand"`
+	Price float64 `json:"price"`
+}
+
+func memoryOptions() *query.ExecutorOptions {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "price"
+	return opts
+}
+
+// newTestExecutor federates hot and cold products; product 4 is in both
+func newTestExecutor() *Executor {
+	hot := memory.NewExecutor([]Product{
+		{ID: 1, Name: "Cable", Brand: "Anker", Price: 10},
+		{ID: 4, Name: "Charger", Brand: "Anker", Price: 40},
+		{ID: 6, Name: "Speaker", Brand: "Sony", Price: 60},
+	}, memoryOptions())
+	cold := memory.NewExecutor([]Product{
+		{ID: 2, Name: "Hub", Brand: "Anker", Price: 20},
+		{ID: 3, Name: "Mouse", Brand: "Logitech", Price: 30},
+		{ID: 4, Name: "Charger", Brand: "Anker", Price: 40},
+		{ID: 5, Name: "Keyboard", Brand: "Logitech", Price: 50},
+		{ID: 7, Name: "Headphones", Brand: "Sony", Price: 70},
+	}, memoryOptions())
+
+	opts := memoryOptions()
+	opts.IDFieldName = "id"
+	return NewExecutor([]executor.Executor{hot, cold}, &Options{ExecutorOptions: opts})
+}
+
+// collect pages through q and returns the IDs of every page
+func collect(t *testing.T, e *Executor, q *query.Query) [][]int {
+	t.Helper()
+	var pages [][]int
+	cursor := ""
+	for {
+		var products []Product
+		result, err := e.Execute(context.Background(), q, cursor, &products)
+		require.NoError(t, err)
+		var ids []int
+		for _, p := range products {
+			ids = append(ids, p.ID)
+		}
+		pages = append(pages, ids)
+		if !result.HasNextPage() {
+			return pages
+		}
+		require.Less(t, len(pages), 10, "too many pages")
+		cursor = result.NextPageCursor
+	}
+}
+
+func TestExecutor_Merge(t *testing.T) {
+	e := newTestExecutor()
+
+	t.Run("ascending", func(t *testing.T) {
+		pages := collect(t, e, &query.Query{PageSize: 3})
+		assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, pages)
+	})
+
+	t.Run("descending", func(t *testing.T) {
+		pages := collect(t, e, &query.Query{SortBy: "price", SortOrder: query.SortOrderDesc, PageSize: 2})
+		assert.Equal(t, [][]int{{7, 6}, {5, 4}, {3, 2}, {1}}, pages)
+	})
+
+	t.Run("duplicate at a page boundary", func(t *testing.T) {
+		pages := collect(t, e, &query.Query{PageSize: 4})
+		assert.Equal(t, [][]int{{1, 2, 3, 4}, {5, 6, 7}}, pages)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		var products []Product
+		result, err := e.Execute(context.Background(), &query.Query{Filter: query.Eq("brand", "Anker"), PageSize: 10}, "", &products)
+		require.NoError(t, err)
+		assert.Len(t, products, 3)
+		assert.Equal(t, int64(4), result.TotalItems)
+		assert.Equal(t, 1, result.ShowingFrom)
+		assert.Equal(t, 3, result.ShowingTo)
+		assert.False(t, result.HasNextPage())
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		pages := collect(t, e, &query.Query{PageSize: 2, Limit: 3})
+		assert.Equal(t, [][]int{{1, 2}, {3}}, pages)
+	})
+}
+
+func TestExecutor_Totals(t *testing.T) {
+	e := newTestExecutor()
+	ctx := context.Background()
+
+	count, err := e.Count(ctx, &query.Query{Filter: query.Eq("brand", "Sony")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Totals of finished children are carried in the cursor
+	var products []Product
+	q := &query.Query{SortBy: "price", SortOrder: query.SortOrderDesc, PageSize: 3}
+	result, err := e.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), result.TotalItems)
+	result, err = e.Execute(ctx, q, result.NextPageCursor, &products)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), result.TotalItems)
+	assert.Equal(t, 4, result.ShowingFrom)
+
+	_, err = e.Execute(ctx, &query.Query{Filter: query.Eq("brand", "Apple")}, "", &products)
+	assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+}
+
+func TestExecutor_Errors(t *testing.T) {
+	e := newTestExecutor()
+	ctx := context.Background()
+	var products []Product
+
+	_, err := e.Execute(ctx, &query.Query{SortOrder: query.SortOrderRandom}, "", &products)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	_, err = e.Execute(ctx, &query.Query{Page: 2}, "", &products)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	_, err = e.Execute(ctx, &query.Query{}, "", products)
+	assert.ErrorIs(t, err, query.ErrInvalidDestination)
+
+	_, err = e.Execute(ctx, &query.Query{}, "not a cursor", &products)
+	assert.ErrorIs(t, err, query.ErrInvalidCursor)
+
+	q := &query.Query{PageSize: 2}
+	result, err := e.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	_, err = e.Execute(ctx, &query.Query{Filter: query.Eq("brand", "Sony"), PageSize: 2}, result.NextPageCursor, &products)
+	assert.ErrorIs(t, err, query.ErrCursorQueryMismatch)
+
+	single := NewExecutor(e.children[:1], e.options)
+	_, err = single.Execute(ctx, q, result.NextPageCursor, &products)
+	assert.ErrorIs(t, err, query.ErrInvalidCursor)
+
+	// Child failures fail the call
+	restricted := memoryOptions()
+	restricted.AllowedFields = []string{"price"}
+	failing := NewExecutor([]executor.Executor{e.children[0], memory.NewExecutor([]Product{{ID: 8, Brand: "Sony", Price: 80}}, restricted)}, e.options)
+	_, err = failing.Execute(ctx, &query.Query{Filter: query.Eq("brand", "Sony")}, "", &products)
+	assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	var queryErr *query.Error
+	require.True(t, errors.As(err, &queryErr))
+	assert.Equal(t, "memory", queryErr.Backend)
+}
@@ -0,0 +1,259 @@
+package federated
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// mergedPage is the page built from the children's pages
+type mergedPage struct {
+	items      []reflect.Value
+	scores     []float64
+	highlights [][]query.Highlight
+}
+
+// mergeKey is what the merge orders an item by
+type mergeKey struct {
+	value interface{} // nil sorts last in either order
+	id    interface{} // nil when the item has no ID
+}
+
+// merge takes up to pageSize items from the heads of pages in sort order,
+// skipping items whose ID was already taken, and records in each page how
+// many items were taken from it. Items tied on the sort value are ordered by
+// ID, then by child, so copies of an item stored in several children meet
+// and only the first is kept
+func (e *Executor) merge(pages []*childPage, q *query.Query, pageSize int) mergedPage {
+	sortField := q.SortBy
+	if sortField == "" {
+		sortField = e.options.DefaultSortField
+	}
+	sortOrder := q.SortOrder
+	// Ascending is the zero value, so it defers to the executor default
+	if sortOrder == query.SortOrderAsc {
+		sortOrder = e.options.DefaultSortOrder
+	}
+
+	withScores, withHighlights := false, false
+	for _, page := range pages {
+		if page != nil {
+			withScores = withScores || len(page.result.Scores) > 0
+			withHighlights = withHighlights || len(page.result.Highlights) > 0
+		}
+	}
+
+	var merged mergedPage
+	seen := make(map[string]bool)
+	heads := make([]*mergeKey, len(pages))
+	head := func(i int) *mergeKey {
+		page := pages[i]
+		if page == nil || page.taken >= page.items.Len() {
+			return nil
+		}
+		if heads[i] == nil {
+			heads[i] = e.key(page, page.taken, sortField)
+		}
+		return heads[i]
+	}
+	take := func(i int) {
+		pages[i].taken++
+		heads[i] = nil
+	}
+
+	for len(merged.items) < pageSize {
+		next := -1
+		for i := range pages {
+			if head(i) == nil {
+				continue
+			}
+			if next < 0 || keyLess(head(i), head(next), sortOrder) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		page, key, index := pages[next], head(next), pages[next].taken
+		take(next)
+		if key.id != nil {
+			id := idKey(key.id)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		merged.items = append(merged.items, page.items.Index(index))
+		if withScores {
+			var score float64
+			if index < len(page.result.Scores) {
+				score = page.result.Scores[index]
+			}
+			merged.scores = append(merged.scores, score)
+		}
+		if withHighlights {
+			var highlights []query.Highlight
+			if index < len(page.result.Highlights) {
+				highlights = page.result.Highlights[index]
+			}
+			merged.highlights = append(merged.highlights, highlights)
+		}
+	}
+
+	// Copies of the last items may head the other children; take them too so
+	// the next page does not start with a duplicate
+	for i := range pages {
+		for key := head(i); key != nil && key.id != nil && seen[idKey(key.id)]; key = head(i) {
+			take(i)
+		}
+	}
+	return merged
+}
+
+// key resolves the sort value and ID of the item at index of page
+func (e *Executor) key(page *childPage, index int, sortField string) *mergeKey {
+	item := page.items.Index(index)
+	key := &mergeKey{}
+	if sortField == query.ScoreField {
+		if index < len(page.result.Scores) {
+			key.value = page.result.Scores[index]
+		}
+	} else {
+		key.value = e.fieldValue(item, sortField)
+	}
+	if e.options.IDField != "" {
+		key.id = e.fieldValue(item, e.options.IDField)
+	}
+	return key
+}
+
+// keyLess orders merge keys by sort value in sortOrder, with nil values
+// last, then by ID ascending
+func keyLess(a, b *mergeKey, sortOrder query.SortOrder) bool {
+	if a.value == nil || b.value == nil {
+		if a.value != nil || b.value != nil {
+			return b.value == nil
+		}
+	} else if cmp := compareValues(a.value, b.value); cmp != 0 {
+		if sortOrder == query.SortOrderDesc {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	if a.id == nil || b.id == nil {
+		return false
+	}
+	return compareValues(a.id, b.id) < 0
+}
+
+// compareValues compares numbers numerically, times chronologically and
+// anything else by its formatted value
+func compareValues(a, b interface{}) int {
+	if af, ok := toFloat64(a); ok {
+		if bf, ok := toFloat64(b); ok {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Compare(bt)
+		}
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+// toFloat64 converts numeric values to float64
+func toFloat64(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// idKey returns the key deduplicating an ID, so that e.g. int and int64 IDs
+// of different children match
+func idKey(id interface{}) string {
+	if f, ok := toFloat64(id); ok {
+		return fmt.Sprintf("%v", f)
+	}
+	return fmt.Sprintf("%v", id)
+}
+
+// fieldValue reads a field of an item, dereferencing pointers. It returns
+// nil for missing fields and nil pointers
+func (e *Executor) fieldValue(item reflect.Value, field string) interface{} {
+	if e.options.FieldGetter != nil {
+		value, err := e.options.FieldGetter(item.Interface(), field)
+		if err != nil {
+			return nil
+		}
+		return deref(reflect.ValueOf(value))
+	}
+
+	for item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			return nil
+		}
+		item = item.Elem()
+	}
+	switch item.Kind() {
+	case reflect.Struct:
+		typ := item.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if strings.EqualFold(f.Name, field) ||
+				strings.EqualFold(strings.Split(f.Tag.Get("json"), ",")[0], field) ||
+				strings.EqualFold(strings.Split(f.Tag.Get("bson"), ",")[0], field) {
+				return deref(item.Field(i))
+			}
+		}
+	case reflect.Map:
+		if item.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		if value := item.MapIndex(reflect.ValueOf(field).Convert(item.Type().Key())); value.IsValid() {
+			return deref(value)
+		}
+		iter := item.MapRange()
+		for iter.Next() {
+			if strings.EqualFold(iter.Key().String(), field) {
+				return deref(iter.Value())
+			}
+		}
+	}
+	return nil
+}
+
+// deref returns the value v holds, following pointers and interfaces
+func deref(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
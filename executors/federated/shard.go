@@ -0,0 +1,201 @@
+package federated
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// MetadataShards is the Result.Metadata key under which ShardedExecutor
+// records the names of the shards a query ran on
+const MetadataShards = "shards"
+
+// ShardFunc returns the name of the shard holding the items whose shard key
+// is value
+type ShardFunc func(value interface{}) (string, error)
+
+// ShardOptions configures a sharded executor
+type ShardOptions struct {
+	// Options configure merging the shards' results, as for NewExecutor
+	Options
+
+	// ShardKey is the field the items are sharded by, e.g. "tenant_id"
+	ShardKey string
+
+	// Shard maps a shard key value to the name of its shard. Defaults to the
+	// value formatted with %v, for shards named after their key values
+	Shard ShardFunc
+
+	// RequireShardKey rejects queries whose filter does not limit the shard
+	// key with ErrInvalidQuery, instead of running them on every shard
+	RequireShardKey bool
+}
+
+// ShardedExecutor routes each query to the shards holding the shard key
+// values its filter selects, e.g. the shard of the tenant in
+// tenant_id = 42, and merges their results like Executor. The values are
+// taken from = and IN conditions on ShardKey that every result must match:
+// the filter itself or its AND operands, or both sides of an OR. Queries
+// without such conditions run on every shard.
+//
+// Cursors record the shards they were generated for, so they fail with
+// ErrInvalidCursor if the query routes elsewhere, e.g. after resharding.
+type ShardedExecutor struct {
+	shards  map[string]executor.Executor
+	names   []string
+	options *ShardOptions
+}
+
+// NewShardedExecutor creates an executor routing queries to shards by name.
+// Queries whose shard key values map to a name missing from shards fail.
+// opts may be nil to run every query on every shard
+func NewShardedExecutor(shards map[string]executor.Executor, opts *ShardOptions) *ShardedExecutor {
+	resolved := ShardOptions{}
+	if opts != nil {
+		resolved = *opts
+	}
+	merge := NewExecutor(nil, &resolved.Options)
+	resolved.Options = *merge.options
+
+	names := make([]string, 0, len(shards))
+	for name := range shards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &ShardedExecutor{shards: shards, names: names, options: &resolved}
+}
+
+// Name returns the name of this executor
+func (e *ShardedExecutor) Name() string {
+	return "sharded"
+}
+
+// Close closes every shard
+func (e *ShardedExecutor) Close() error {
+	var errs []error
+	for _, name := range e.names {
+		if err := e.shards[name].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Execute runs the query on its shards and stores the merged page in dest
+func (e *ShardedExecutor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	result, err := e.executeQuery(ctx, q, cursorParam, dest)
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+	}
+	return query.WrapResult(e.Name(), "execute", result, err)
+}
+
+func (e *ShardedExecutor) executeQuery(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	merge, err := e.route(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	result, err := merge.executeQuery(ctx, q, cursorParam, dest)
+	if result != nil {
+		result.SetMetadata(MetadataShards, merge.names)
+	}
+	return result, err
+}
+
+// Count returns the sum of the counts of the query's shards
+func (e *ShardedExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	merge, err := e.route(ctx, q)
+	if err != nil {
+		return 0, query.WrapError(e.Name(), "count", err)
+	}
+	count, err := merge.Count(ctx, q)
+	return count, query.WrapError(e.Name(), "count", err)
+}
+
+// route returns an executor merging the shards of q, in name order
+func (e *ShardedExecutor) route(ctx context.Context, q *query.Query) (*Executor, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	names := e.names
+	resolved, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := shardKeyValues(resolved.Filter, e.options.ShardKey)
+	switch {
+	case ok:
+		names, err = e.shardNames(values)
+		if err != nil {
+			return nil, err
+		}
+	case e.options.RequireShardKey:
+		return nil, fmt.Errorf("%w: filter must limit %s", query.ErrInvalidQuery, e.options.ShardKey)
+	}
+
+	children := make([]executor.Executor, len(names))
+	for i, name := range names {
+		children[i] = e.shards[name]
+	}
+	return &Executor{children: children, options: &e.options.Options, names: names}, nil
+}
+
+// shardNames returns the sorted names of the shards holding values
+func (e *ShardedExecutor) shardNames(values []interface{}) ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, value := range values {
+		name := fmt.Sprintf("%v", value)
+		if e.options.Shard != nil {
+			var err error
+			if name, err = e.options.Shard(value); err != nil {
+				return nil, query.NewExecutionError("route shard", err)
+			}
+		}
+		if _, ok := e.shards[name]; !ok {
+			return nil, query.NewExecutionError("route shard", fmt.Errorf("shard %q is not configured", name))
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// shardKeyValues returns the values of field that every item matching node
+// has one of, from = and IN conditions. It returns false when node does not
+// limit the field
+func shardKeyValues(node query.Node, field string) ([]interface{}, bool) {
+	switch n := node.(type) {
+	case *query.ComparisonNode:
+		if n.Field != field || n.Modifier != query.ArrayModifierNone {
+			return nil, false
+		}
+		switch n.Operator {
+		case query.OpEqual:
+			return []interface{}{n.Value}, true
+		case query.OpIn:
+			values, ok := n.Value.(query.ArrayValue)
+			return values, ok
+		}
+	case *query.BinaryOpNode:
+		left, leftOK := shardKeyValues(n.Left, field)
+		right, rightOK := shardKeyValues(n.Right, field)
+		if n.Operator == query.BinaryOpOr {
+			return append(left, right...), leftOK && rightOK
+		}
+		if leftOK {
+			return left, true
+		}
+		return right, rightOK
+	}
+	return nil, false
+}
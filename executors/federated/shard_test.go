@@ -0,0 +1,159 @@
+package federated
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/executors/memory"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Order struct {
+	ID       int     `json:"id"`
+	TenantID int     `json:"tenant_id"`
+	Total    float64 `json:"total"`
+}
+
+type tenantKey struct{}
+
+func tenantOptions() *query.ExecutorOptions {
+	opts := memoryOptions()
+	opts.DefaultSortField = "total"
+	opts.Placeholders = map[string]query.PlaceholderResolver{
+		"tenant": func(ctx context.Context) (interface{}, error) {
+			return ctx.Value(tenantKey{}), nil
+		},
+	}
+	return opts
+}
+
+// newShardedExecutor shards tenants 1 and 2 to "a", 3 to "b" and 4 to "c"
+func newShardedExecutor(opts *ShardOptions) *ShardedExecutor {
+	shard := func(orders ...Order) executor.Executor {
+		return memory.NewExecutor(orders, tenantOptions())
+	}
+	if opts == nil {
+		opts = &ShardOptions{}
+	}
+	opts.ExecutorOptions = tenantOptions()
+	opts.IDField = "id"
+	opts.ShardKey = "tenant_id"
+	opts.Shard = func(value interface{}) (string, error) {
+		switch fmt.Sprintf("%v", value) {
+		case "1", "2":
+			return "a", nil
+		case "3":
+			return "b", nil
+		case "4":
+			return "c", nil
+		}
+		return "", fmt.Errorf("unknown tenant %v", value)
+	}
+	return NewShardedExecutor(map[string]executor.Executor{
+		"a": shard(Order{ID: 1, TenantID: 1, Total: 10}, Order{ID: 2, TenantID: 2, Total: 20}, Order{ID: 3, TenantID: 1, Total: 30}),
+		"b": shard(Order{ID: 4, TenantID: 3, Total: 15}, Order{ID: 5, TenantID: 3, Total: 25}),
+		"c": shard(Order{ID: 6, TenantID: 4, Total: 5}),
+	}, opts)
+}
+
+func orderIDs(orders []Order) []int {
+	var ids []int
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+func TestShardedExecutor_Routing(t *testing.T) {
+	e := newShardedExecutor(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		filter query.Node
+		shards []string
+		ids    []int
+	}{
+		{"one tenant", query.Eq("tenant_id", 3), []string{"b"}, []int{4, 5}},
+		{"tenants on one shard", query.In("tenant_id", 1, 2), []string{"a"}, []int{1, 2, 3}},
+		{"tenants on several shards", query.In("tenant_id", 1, 3), []string{"a", "b"}, []int{1, 4, 5, 3}},
+		{"AND operand", &query.BinaryOpNode{Operator: query.BinaryOpAnd, Left: query.Gt("total", 12), Right: query.Eq("tenant_id", 4)}, []string{"c"}, nil},
+		{"both sides of OR", &query.BinaryOpNode{Operator: query.BinaryOpOr, Left: query.Eq("tenant_id", 4), Right: query.Eq("tenant_id", 3)}, []string{"b", "c"}, []int{6, 4, 5}},
+		{"OR without the key", &query.BinaryOpNode{Operator: query.BinaryOpOr, Left: query.Eq("tenant_id", 4), Right: query.Gt("total", 28)}, []string{"a", "b", "c"}, []int{6, 3}},
+		{"no filter", nil, []string{"a", "b", "c"}, []int{6, 1, 4, 2, 5, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var orders []Order
+			result, err := e.Execute(ctx, &query.Query{Filter: tt.filter, PageSize: 10}, "", &orders)
+			if tt.ids == nil {
+				assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NotNil(t, result)
+			shards, _ := result.GetMetadata(MetadataShards)
+			assert.Equal(t, tt.shards, shards)
+			assert.Equal(t, tt.ids, orderIDs(orders))
+		})
+	}
+
+	count, err := e.Count(ctx, &query.Query{Filter: query.In("tenant_id", 2, 3)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	_, err = e.Count(ctx, &query.Query{Filter: query.Eq("tenant_id", 9)})
+	var execErr *query.ExecutionError
+	assert.ErrorAs(t, err, &execErr)
+}
+
+func TestShardedExecutor_Pagination(t *testing.T) {
+	e := newShardedExecutor(nil)
+	ctx := context.WithValue(context.Background(), tenantKey{}, 3)
+	q := &query.Query{Filter: query.In("tenant_id", 1, 3, 4), PageSize: 2}
+
+	var pages [][]int
+	cursor := ""
+	for {
+		var orders []Order
+		result, err := e.Execute(ctx, q, cursor, &orders)
+		require.NoError(t, err)
+		pages = append(pages, orderIDs(orders))
+		if !result.HasNextPage() {
+			break
+		}
+		cursor = result.NextPageCursor
+	}
+	assert.Equal(t, [][]int{{6, 1}, {4, 5}, {3}}, pages)
+
+	// Cursors are bound to the shards they were generated for
+	tenantQuery := &query.Query{Filter: query.Eq("tenant_id", query.PlaceholderValue("tenant")), PageSize: 1}
+	var orders []Order
+	result, err := e.Execute(ctx, tenantQuery, "", &orders)
+	require.NoError(t, err)
+	assert.Equal(t, []int{4}, orderIDs(orders))
+
+	other := context.WithValue(context.Background(), tenantKey{}, 4)
+	_, err = e.Execute(other, tenantQuery, result.NextPageCursor, &orders)
+	assert.ErrorIs(t, err, query.ErrInvalidCursor)
+
+	result, err = e.Execute(ctx, tenantQuery, result.NextPageCursor, &orders)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5}, orderIDs(orders))
+}
+
+func TestShardedExecutor_RequireShardKey(t *testing.T) {
+	e := newShardedExecutor(&ShardOptions{RequireShardKey: true})
+	var orders []Order
+
+	_, err := e.Execute(context.Background(), &query.Query{Filter: query.Gt("total", 10)}, "", &orders)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	_, err = e.Execute(context.Background(), &query.Query{Filter: query.Eq("tenant_id", 2)}, "", &orders)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, orderIDs(orders))
+}
@@ -0,0 +1,88 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// arrayElementColumn is the column of the table of array elements
+const arrayElementColumn = "value"
+
+// buildArrayComparison translates LENGTH, ANY and ALL conditions on a column
+// holding a JSON array:
+//
+//	tags LENGTH > 3         json_array_length(tags) > ?
+//	tags ANY = "usb"        EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)
+//	tags ALL IN [usb, hub]  EXISTS (... value = ?) AND EXISTS (... value = ?)
+//
+// See jsonArray for the functions of each dialect
+func (e *Executor) buildArrayComparison(n *query.ComparisonNode, field, column string) (string, []interface{}, error) {
+	if err := query.ValidateArrayCondition(n); err != nil {
+		return "", nil, err
+	}
+	length, elements, ok := e.jsonArray(column)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s is not supported on %q", query.ErrInvalidQuery, n.Modifier, e.dialectName())
+	}
+
+	switch n.Modifier {
+	case query.ArrayModifierLength:
+		count, ok := n.Value.(query.IntValue)
+		if !ok {
+			return "", nil, query.NewFieldError(field, fmt.Errorf("%w: LENGTH must be compared with an integer", query.ErrInvalidQuery))
+		}
+		return fmt.Sprintf("%s %s ?", length, n.Operator), []interface{}{int64(count)}, nil
+
+	case query.ArrayModifierAny:
+		element := &query.ComparisonNode{Field: n.Field, Operator: n.Operator, Value: n.Value}
+		cond, args, err := e.buildComparison(element, field, arrayElementColumn)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", elements, cond), args, nil
+
+	default:
+		values := n.Value.(query.ArrayValue)
+		if len(values) == 0 {
+			return e.constantClause(true), []interface{}{}, nil
+		}
+		clauses := make([]string, len(values))
+		var args []interface{}
+		for i, value := range values {
+			element := &query.ComparisonNode{Field: n.Field, Operator: query.OpEqual, Value: value}
+			cond, condArgs, err := e.buildComparison(element, field, arrayElementColumn)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses[i] = fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", elements, cond)
+			args = append(args, condArgs...)
+		}
+		return strings.Join(clauses, " AND "), args, nil
+	}
+}
+
+// jsonArray returns the expression counting the elements of the JSON array in
+// column, and a table of its elements with a value column:
+//   - PostgreSQL: jsonb_array_length, jsonb_array_elements_text (elements are text)
+//   - MySQL 8: JSON_LENGTH, JSON_TABLE
+//   - SQLite: json_array_length, json_each
+//   - SQL Server: OPENJSON
+//
+// ok is false on other dialects
+func (e *Executor) jsonArray(column string) (length, elements string, ok bool) {
+	switch e.dialectName() {
+	case dialectPostgres:
+		return fmt.Sprintf("jsonb_array_length(CAST(%s AS jsonb))", column),
+			fmt.Sprintf("jsonb_array_elements_text(CAST(%s AS jsonb)) AS _elements(%s)", column, arrayElementColumn), true
+	case dialectMySQL:
+		return fmt.Sprintf("JSON_LENGTH(%s)", column),
+			fmt.Sprintf("JSON_TABLE(%s, '$[*]' COLUMNS (%s LONGTEXT PATH '$')) AS _elements", column, arrayElementColumn), true
+	case dialectSQLite:
+		return fmt.Sprintf("json_array_length(%s)", column), fmt.Sprintf("json_each(%s)", column), true
+	case dialectSQLServer:
+		return fmt.Sprintf("(SELECT COUNT(*) FROM OPENJSON(%s))", column), fmt.Sprintf("OPENJSON(%s)", column), true
+	}
+	return "", "", false
+}
@@ -0,0 +1,60 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// ExecuteBatch runs the queries in one transaction, so they read consistent
+// data over a single connection. The options are resolved once for the whole
+// batch. On PostgreSQL, where a failed statement aborts the transaction, each
+// query runs in its own savepoint so the others still run. With replicas, the
+// whole batch reads from one of them
+func (e *Executor) ExecuteBatch(ctx context.Context, queries []*query.Query, dests []interface{}) ([]executor.BatchResult, error) {
+	if err := executor.ValidateBatch(queries, dests); err != nil {
+		return nil, err
+	}
+	e = e.withCurrentOptions()
+	savepoints := e.dialectName() == dialectPostgres
+
+	var results []executor.BatchResult
+	err := e.withQueryOptions(ctx).read(func(e *Executor) error {
+		results = make([]executor.BatchResult, len(queries))
+		return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for i, q := range queries {
+				run := func(tx *gorm.DB) error {
+					results[i].Result, results[i].Err = e.inTransaction(tx).Execute(ctx, q, "", dests[i])
+					return results[i].Err
+				}
+				if savepoints {
+					_ = tx.Transaction(run)
+				} else {
+					_ = run(tx)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, query.NewExecutionError("batch transaction", err)
+	}
+	return results, nil
+}
+
+// inTransaction returns a copy of e running on tx with its current options.
+// A transaction runs one statement at a time, so CountAsync counts in line
+func (e *Executor) inTransaction(tx *gorm.DB) *Executor {
+	bound := *e
+	bound.db = tx
+	bound.optionsProvider = nil
+	bound.replicas = nil
+	if e.options.CountMode == query.CountAsync {
+		opts := *e.options
+		opts.CountMode = query.CountExact
+		bound.options = &opts
+	}
+	return &bound
+}
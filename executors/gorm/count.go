@@ -0,0 +1,79 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// countResult is the outcome of a count started by countTotal
+type countResult struct {
+	total     int64
+	estimated bool
+	err       error
+}
+
+// countTotal starts computing TotalItems for Execute as CountMode asks and
+// returns a function that waits for it. With CountAsync the count runs on its
+// own session while the caller fetches the page from tx
+func (e *Executor) countTotal(ctx context.Context, tx *gorm.DB, q *query.Query, dest interface{}) func() countResult {
+	switch e.options.CountMode {
+	case query.CountNone:
+		return func() countResult { return countResult{total: query.TotalUnknown} }
+	case query.CountEstimated:
+		if total, ok := e.tableEstimate(ctx, q, dest); ok {
+			return func() countResult { return countResult{total: total, estimated: true} }
+		}
+	case query.CountAsync:
+		session := tx.Session(&gorm.Session{Context: ctx})
+		counted := make(chan countResult, 1)
+		go func() {
+			total, err := countRows(session, q, dest)
+			counted <- countResult{total: total, err: err}
+		}()
+		return func() countResult { return <-counted }
+	}
+	total, err := countRows(tx, q, dest)
+	return func() countResult { return countResult{total: total, err: err} }
+}
+
+// tableEstimate returns the row count PostgreSQL (pg_class.reltuples) or
+// MySQL (information_schema.TABLES) keeps for the table of an unfiltered
+// query. ok is false for other dialects, for filtered or distinct queries,
+// when soft-deleted rows are excluded and for tables without statistics
+func (e *Executor) tableEstimate(ctx context.Context, q *query.Query, dest interface{}) (int64, bool) {
+	if q.Filter != nil || q.Distinct || q.DistinctOn != "" {
+		return 0, false
+	}
+	if _, filtered := e.db.Statement.Clauses["WHERE"]; filtered {
+		return 0, false
+	}
+	table, ok := e.unionTable(dest)
+	if !ok {
+		return 0, false
+	}
+	if include, err := e.options.IncludesDeleted(q); err != nil || !include {
+		if s, ok := e.modelSchema(dest); !ok || len(s.QueryClauses) > 0 {
+			return 0, false
+		}
+	}
+
+	var stmt string
+	switch e.dialectName() {
+	case dialectPostgres:
+		// reltuples is -1 for tables that were never analyzed
+		stmt = "SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass(?)"
+	case dialectMySQL:
+		stmt = "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+	default:
+		return 0, false
+	}
+	var estimate sql.NullInt64
+	row := e.db.Session(&gorm.Session{NewDB: true, Context: ctx}).Raw(stmt, table).Row()
+	if row == nil || row.Scan(&estimate) != nil || !estimate.Valid || estimate.Int64 < 0 {
+		return 0, false
+	}
+	return estimate.Int64, true
+}
@@ -0,0 +1,163 @@
+package gorm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Names of the GORM dialectors with dialect-specific SQL
+const (
+	dialectPostgres  = "postgres"
+	dialectMySQL     = "mysql"
+	dialectSQLite    = "sqlite"
+	dialectSQLServer = "sqlserver"
+)
+
+// defaultRandomFunction is the RandomFunctionName of query.DefaultExecutorOptions
+const defaultRandomFunction = "RANDOM()"
+
+// dialectName returns the name of the GORM dialector (e.g. "sqlite", "postgres", "mysql")
+func (e *Executor) dialectName() string {
+	if e.db == nil || e.db.Dialector == nil {
+		return ""
+	}
+	return e.db.Dialector.Name()
+}
+
+// quoteIdentifier quotes a column name for the dialect, doubling any closing
+// quote character inside it: backticks on MySQL, brackets on SQL Server and
+// double quotes elsewhere
+func (e *Executor) quoteIdentifier(name string) string {
+	switch e.dialectName() {
+	case dialectMySQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case dialectSQLServer:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// regexClause matches field against a regular expression
+//   - PostgreSQL: field ~ ?
+//   - SQL Server: not supported (ErrRegexNotSupported)
+//   - MySQL, SQLite and others: field REGEXP ? (SQLite needs a regexp function
+//     registered with the driver)
+func (e *Executor) regexClause(field string) (string, error) {
+	switch e.dialectName() {
+	case dialectPostgres:
+		return fmt.Sprintf("%s ~ ?", field), nil
+	case dialectSQLServer:
+		return "", query.ErrRegexNotSupported
+	}
+	return fmt.Sprintf("%s REGEXP ?", field), nil
+}
+
+// icontainsClause matches field against a LIKE pattern ignoring case:
+// ILIKE on PostgreSQL, LOWER(field) LIKE LOWER(?) elsewhere
+func (e *Executor) icontainsClause(field string) string {
+	if e.dialectName() == dialectPostgres {
+		return fmt.Sprintf("%s ILIKE ?", field)
+	}
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", field)
+}
+
+// constantClause returns a condition that is always true or always false.
+// SQL Server has no boolean literals, so 1 = 1 and 1 = 0 are used there and on
+// unknown dialects
+func (e *Executor) constantClause(value bool) string {
+	switch e.dialectName() {
+	case dialectPostgres, dialectMySQL, dialectSQLite:
+		if value {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	if value {
+		return "1 = 1"
+	}
+	return "1 = 0"
+}
+
+// seededHash returns an expression that hashes column with seed, for a
+// reproducible random order
+//   - PostgreSQL: md5(CAST(column AS TEXT) || ?)
+//   - MySQL: MD5(CONCAT(column, ?))
+//   - SQL Server: HASHBYTES('MD5', CONCAT(column, ?))
+//   - SQLite and others: x * x + c modulo the prime 2147483647, where
+//     x = column * a + b and a, b and c are derived from the seed, since SQLite
+//     has no hash function. It shuffles integer IDs only; other IDs keep their
+//     order
+func (e *Executor) seededHash(column string, seed int64) (string, []interface{}) {
+	switch e.dialectName() {
+	case dialectPostgres:
+		return fmt.Sprintf("md5(CAST(%s AS TEXT) || ?)", column), []interface{}{strconv.FormatInt(seed, 10)}
+	case dialectMySQL:
+		return fmt.Sprintf("MD5(CONCAT(%s, ?))", column), []interface{}{strconv.FormatInt(seed, 10)}
+	case dialectSQLServer:
+		return fmt.Sprintf("HASHBYTES('MD5', CONCAT(%s, ?))", column), []interface{}{strconv.FormatInt(seed, 10)}
+	}
+	// Every term stays below 2^31, so products fit in 64 bits
+	const p = 2147483647
+	mix := uint64(seed)
+	next := func() int64 {
+		// splitmix64, so nearby seeds give unrelated orders
+		mix += 0x9e3779b97f4a7c15
+		z := mix
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return int64((z ^ (z >> 31)) % p)
+	}
+	a, b, c := next()|1, next(), next()
+	x := fmt.Sprintf("(((%s %% %d) * ? + ?) %% %d)", column, p, p)
+	return fmt.Sprintf("(%s * %s + ?) %% %d", x, x, p), []interface{}{a, b, a, b, c}
+}
+
+// floorExpression rounds expr down to an integer. SQLite has no FLOOR unless
+// built with math functions, so it subtracts one from the truncated value of
+// negative non-integers
+func (e *Executor) floorExpression(expr string) string {
+	if e.dialectName() == dialectSQLite {
+		return fmt.Sprintf("(CAST(%[1]s AS INTEGER) - (%[1]s < CAST(%[1]s AS INTEGER)))", expr)
+	}
+	return fmt.Sprintf("FLOOR(%s)", expr)
+}
+
+// dateTruncExpression returns the start of the interval holding column
+//   - PostgreSQL: date_trunc('day', column)
+//   - SQL Server: DATETRUNC(day, column) (SQL Server 2022 and later)
+//   - MySQL: DATE_FORMAT(column, '%Y-%m-%d 00:00:00')
+//   - SQLite and others: strftime('%Y-%m-%d 00:00:00', column)
+//
+// Weeks start on Monday
+func (e *Executor) dateTruncExpression(column string, interval query.DateInterval) string {
+	switch e.dialectName() {
+	case dialectPostgres:
+		return fmt.Sprintf("date_trunc('%s', %s)", interval, column)
+	case dialectSQLServer:
+		if interval == query.DateIntervalWeek {
+			return fmt.Sprintf("DATETRUNC(iso_week, %s)", column)
+		}
+		return fmt.Sprintf("DATETRUNC(%s, %s)", interval, column)
+	case dialectMySQL:
+		if interval == query.DateIntervalWeek {
+			return fmt.Sprintf("DATE_FORMAT(DATE_SUB(%[1]s, INTERVAL WEEKDAY(%[1]s) DAY), '%%Y-%%m-%%d 00:00:00')", column)
+		}
+		return fmt.Sprintf("DATE_FORMAT(%s, '%s')", column, dateFormats[interval])
+	}
+	if interval == query.DateIntervalWeek {
+		// weekday 0 moves to the next Sunday unless already on one
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d 00:00:00', %s, 'weekday 0', '-6 days')", column)
+	}
+	return fmt.Sprintf("strftime('%s', %s)", dateFormats[interval], column)
+}
+
+// dateFormats are the strftime/DATE_FORMAT patterns of the start of each interval
+var dateFormats = map[query.DateInterval]string{
+	query.DateIntervalHour:  "%Y-%m-%d %H:00:00",
+	query.DateIntervalDay:   "%Y-%m-%d 00:00:00",
+	query.DateIntervalMonth: "%Y-%m-01 00:00:00",
+	query.DateIntervalYear:  "%Y-01-01 00:00:00",
+}
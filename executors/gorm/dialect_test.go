@@ -0,0 +1,173 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// namedDialector reports another dialect's name, so the SQL generated for it
+// can be checked without a database
+type namedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d namedDialector) Name() string { return d.name }
+
+func dialectExecutor(name string, opts *query.ExecutorOptions) *Executor {
+	if opts == nil {
+		opts = query.DefaultExecutorOptions()
+	}
+	db := &gorm.DB{Config: &gorm.Config{Dialector: namedDialector{Dialector: sqlite.Open(""), name: name}}}
+	return &Executor{db: db, options: opts}
+}
+
+func TestExecutor_DialectOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		dialect  string
+		expected string
+		err      error
+	}{
+		{`name REGEX "^a"`, "postgres", "name ~ ?", nil},
+		{`name REGEX "^a"`, "mysql", "name REGEXP ?", nil},
+		{`name REGEX "^a"`, "sqlite", "name REGEXP ?", nil},
+		{`name REGEX "^a"`, "sqlserver", "", query.ErrRegexNotSupported},
+		{`name ICONTAINS "a"`, "postgres", "name ILIKE ?", nil},
+		{`name ICONTAINS "a"`, "mysql", "LOWER(name) LIKE LOWER(?)", nil},
+		{`name ICONTAINS "a"`, "sqlserver", "LOWER(name) LIKE LOWER(?)", nil},
+		{`id IN []`, "postgres", "FALSE", nil},
+		{`id IN []`, "sqlite", "FALSE", nil},
+		{`id IN []`, "sqlserver", "1 = 0", nil},
+		{`id NOT IN []`, "mysql", "TRUE", nil},
+		{`id NOT IN []`, "sqlserver", "1 = 1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect+" "+tt.input, func(t *testing.T) {
+			filter, err := parser.ParseFilter(tt.input)
+			require.NoError(t, err)
+
+			clause, _, err := dialectExecutor(tt.dialect, nil).buildFilter(filter)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, clause)
+		})
+	}
+}
+
+func TestExecutor_RandomOrderClause(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres":  "md5(CAST(id AS TEXT) || ?), id ASC",
+		"mysql":     "MD5(CONCAT(id, ?)), id ASC",
+		"sqlserver": "HASHBYTES('MD5', CONCAT(id, ?)), id ASC",
+		"sqlite":    "((((id % 2147483647) * ? + ?) % 2147483647) * (((id % 2147483647) * ? + ?) % 2147483647) + ?) % 2147483647, id ASC",
+	} {
+		orderBy, err := dialectExecutor(dialect, nil).buildRandomOrderClause(42)
+		require.NoError(t, err, dialect)
+		assert.Equal(t, expected, orderBy.Expression.(clause.Expr).SQL, dialect)
+	}
+	orderBy, err := dialectExecutor("postgres", nil).buildRandomOrderClause(42)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"42"}, orderBy.Expression.(clause.Expr).Vars)
+
+	// An explicit function replaces the seeded order
+	opts := query.DefaultExecutorOptions()
+	opts.RandomFunctionName = "RAND(42)"
+	orderBy, err = dialectExecutor("mysql", opts).buildRandomOrderClause(42)
+	require.NoError(t, err)
+	assert.Equal(t, "RAND(42)", orderBy.Expression.(clause.Expr).SQL)
+}
+
+func TestExecutor_FlatChains(t *testing.T) {
+	filter, err := parser.ParseFilter("a = 1 AND (b = 2 AND (c = 3 OR (d = 4 OR d = 5)))")
+	require.NoError(t, err)
+
+	clause, args, err := dialectExecutor("postgres", nil).buildFilter(filter)
+	require.NoError(t, err)
+	assert.Equal(t, "(a = ?) AND (b = ?) AND ((c = ?) OR (d = ?) OR (d = ?))", clause)
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5)}, args)
+
+	opts := query.DefaultExecutorOptions()
+	opts.OptimizeFilter = true
+	q := opts.ScopedQuery(&query.Query{Filter: filter})
+	clause, _, err = dialectExecutor("postgres", opts).buildFilter(q.Filter)
+	require.NoError(t, err)
+	assert.Equal(t, "(a = ?) AND (b = ?) AND ((c = ?) OR (d IN (?, ?)))", clause)
+}
+
+func TestExecutor_QuotedFields(t *testing.T) {
+	filter, err := parser.ParseFilter("`order date` >= 5 AND [\"a`b\"] = 1")
+	require.NoError(t, err)
+
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"order date", "a`b"}
+	for dialect, expected := range map[string]string{
+		"postgres":  "(\"order date\" >= ?) AND (\"a`b\" = ?)",
+		"mysql":     "(`order date` >= ?) AND (`a``b` = ?)",
+		"sqlserver": "([order date] >= ?) AND ([a`b] = ?)",
+	} {
+		clause, _, err := dialectExecutor(dialect, opts).buildFilter(filter)
+		require.NoError(t, err, dialect)
+		assert.Equal(t, expected, clause, dialect)
+	}
+
+	// Names that are not identifiers must be listed, even when every field is allowed
+	_, _, err = dialectExecutor("postgres", nil).buildFilter(filter)
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
+
+func TestGORMExecutor_QuotedFields(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY, "order date" TEXT)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO events VALUES (1, '2024-01-15'), (2, '2024-02-15'), (3, '2024-03-15')`).Error)
+
+	type event struct {
+		ID        int
+		OrderDate string `gorm:"column:order date"`
+	}
+
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"id", "order date"}
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(db.Table("events"), opts)
+
+	p, err := parser.NewParser(`["order date"] >= "2024-02-01"`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	var events []event
+	result, err := executor.Execute(context.Background(), q, "", &events)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.TotalItems)
+	require.Len(t, events, 2)
+	assert.Equal(t, "2024-02-15", events[0].OrderDate)
+}
+
+func TestExecutor_HistogramExpressions(t *testing.T) {
+	for dialect, expected := range map[string][2]string{
+		"postgres":  {"date_trunc('week', created_at)", "FLOOR(x)"},
+		"mysql":     {"DATE_FORMAT(DATE_SUB(created_at, INTERVAL WEEKDAY(created_at) DAY), '%Y-%m-%d 00:00:00')", "FLOOR(x)"},
+		"sqlserver": {"DATETRUNC(iso_week, created_at)", "FLOOR(x)"},
+		"sqlite":    {"strftime('%Y-%m-%d 00:00:00', created_at, 'weekday 0', '-6 days')", "(CAST(x AS INTEGER) - (x < CAST(x AS INTEGER)))"},
+	} {
+		e := dialectExecutor(dialect, nil)
+		assert.Equal(t, expected[0], e.dateTruncExpression("created_at", query.DateIntervalWeek), dialect)
+		assert.Equal(t, expected[1], e.floorExpression("x"), dialect)
+	}
+	assert.Equal(t, "DATE_FORMAT(created_at, '%Y-%m-01 00:00:00')",
+		dialectExecutor("mysql", nil).dateTruncExpression("created_at", query.DateIntervalMonth))
+}
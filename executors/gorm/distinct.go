@@ -0,0 +1,93 @@
+package gorm
+
+import (
+	"fmt"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// distinctRowColumn numbers the rows of each distinct_on value
+const distinctRowColumn = "_distinct_row"
+
+// applyDistinct deduplicates the rows of tx, which must be filtered already.
+// With DistinctOn only the first row of each value, in the query's sort order,
+// is kept:
+//
+//	WHERE id IN (SELECT id FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY field
+//	    ORDER BY sort, id) AS _distinct_row FROM ... WHERE filter) AS _distinct
+//	    WHERE _distinct_row = 1)
+//
+// Window functions need SQLite 3.25, MySQL 8 or PostgreSQL. Random, relevance,
+// match count and IN ordering keep the row with the lowest ID instead.
+// With Distinct, rows are selected with SELECT DISTINCT
+func (e *Executor) applyDistinct(tx *gorm.DB, q *query.Query) (*gorm.DB, error) {
+	if q.DistinctOn == "" {
+		if q.Distinct {
+			tx = tx.Distinct()
+		}
+		return tx, nil
+	}
+	if !e.isValidField(q.DistinctOn) {
+		return nil, query.InvalidFieldNameError(q.DistinctOn)
+	}
+	order, err := e.distinctOrder(q)
+	if err != nil {
+		return nil, err
+	}
+
+	id := e.getIDFieldName()
+	numbered := tx.Session(&gorm.Session{}).Select(fmt.Sprintf("%s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS %s",
+		id, q.DistinctOn, order, distinctRowColumn))
+	first := tx.Session(&gorm.Session{NewDB: true}).Table("(?) AS _distinct", numbered).
+		Select(id).Where(distinctRowColumn + " = 1")
+	return tx.Where(fmt.Sprintf("%s IN (?)", id), first), nil
+}
+
+// distinctOrder returns the ORDER BY of the distinct_on window: the query's
+// regular sort with the ID as a tie-breaker, or the ID alone
+func (e *Executor) distinctOrder(q *query.Query) (string, error) {
+	id := e.getIDFieldName()
+	sortField := q.SortBy
+	if sortField == "" {
+		sortField = e.options.DefaultSortField
+	}
+	sortOrder := q.SortOrder
+	if sortOrder == query.SortOrderAsc {
+		sortOrder = e.options.DefaultSortOrder
+	}
+	if q.PreserveInOrder || sortOrder == query.SortOrderRandom ||
+		sortField == query.ScoreField || sortField == query.MatchCountField {
+		return id + " ASC", nil
+	}
+
+	direction := "ASC"
+	if sortOrder == query.SortOrderDesc {
+		direction = "DESC"
+	}
+	if !e.isValidField(sortField) {
+		return "", query.InvalidFieldNameError(sortField)
+	}
+	if e.isIDField(sortField) {
+		return fmt.Sprintf("%s %s", sortField, direction), nil
+	}
+	return fmt.Sprintf("%s %s, %s %s", sortField, direction, id, direction), nil
+}
+
+// countRows counts the rows of tx. GORM counts SELECT DISTINCT as COUNT(*), so
+// distinct rows are counted in a subquery. dest, when not nil, selects the same
+// columns as finding into dest, which matters when its type is smaller than the model
+func countRows(tx *gorm.DB, q *query.Query, dest interface{}) (int64, error) {
+	var total int64
+	if q.Distinct && q.DistinctOn == "" {
+		// Setting the context gives the session its own statement
+		rows := tx.Session(&gorm.Session{Context: tx.Statement.Context})
+		if dest != nil {
+			rows.Statement.Dest = dest
+		}
+		err := tx.Session(&gorm.Session{NewDB: true}).Table("(?) AS _distinct", rows).Count(&total).Error
+		return total, err
+	}
+	err := tx.Count(&total).Error
+	return total, err
+}
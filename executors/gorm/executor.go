@@ -5,18 +5,53 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/hadi77ir/go-query/executor"
 	"github.com/hadi77ir/go-query/internal/cursor"
 	"github.com/hadi77ir/go-query/query"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
+// Options configures the GORM executor beyond query.ExecutorOptions
+type Options struct {
+	*query.ExecutorOptions
+
+	// QueryOptions are the index hints, timeout and read policy of the
+	// executor's reads. WithQueryOptions overrides them per call
+	QueryOptions
+
+	// Replicas are read-only copies of the primary db, scoped to the same
+	// model or table. Execute, Count, Facets and ExecuteBatch read from them
+	// according to QueryOptions.ReadPolicy; DeleteWhere and UpdateWhere
+	// always use the primary
+	Replicas []*gorm.DB
+
+	// HealthCheckInterval is how often the replicas are pinged. Replicas that
+	// fail a ping, or a read because they cannot be reached, are skipped
+	// until a ping succeeds. Defaults to DefaultHealthCheckInterval; negative
+	// disables the pings. Close stops them
+	HealthCheckInterval time.Duration
+}
+
 // Executor is the GORM implementation of the executor interface
 type Executor struct {
-	db      *gorm.DB
-	model   interface{}
-	options *query.ExecutorOptions
+	db              *gorm.DB
+	model           interface{}
+	options         *query.ExecutorOptions
+	optionsProvider query.OptionsProvider
+	queryOptions    QueryOptions
+	replicas        *replicaSet
+
+	// inTables maps large IN/NOT IN comparisons to temporary tables holding their values
+	// Only set on per-execution copies created by withInTables
+	inTables map[*query.ComparisonNode]string
+
+	// schema is the parsed model used to resolve RelationMap associations
+	// Only set on per-execution copies created by applyFilter
+	schema *schema.Schema
 }
 
 // NewExecutor creates a new GORM executor
@@ -31,46 +66,148 @@ func NewExecutor(db *gorm.DB, opts *query.ExecutorOptions) executor.Executor {
 	}
 }
 
+// NewExecutorWithOptions creates a new GORM executor with GORM-specific options.
+// If opts.ExecutorOptions is nil, default options are used
+func NewExecutorWithOptions(db *gorm.DB, opts *Options) executor.Executor {
+	if opts == nil {
+		opts = &Options{}
+	}
+	execOpts := opts.ExecutorOptions
+	if execOpts == nil {
+		execOpts = query.DefaultExecutorOptions()
+	}
+	return &Executor{
+		db:           db,
+		options:      execOpts,
+		queryOptions: opts.QueryOptions,
+		replicas:     newReplicaSet(opts.Replicas, opts.HealthCheckInterval),
+	}
+}
+
+// NewExecutorWithOptionsProvider creates a new GORM executor whose options are
+// fetched from the provider on every Execute/Count call
+// This allows allowlists, page caps and other policies to be changed at runtime
+func NewExecutorWithOptionsProvider(db *gorm.DB, provider query.OptionsProvider) executor.Executor {
+	return &Executor{
+		db:              db,
+		options:         query.ResolveOptions(provider),
+		optionsProvider: provider,
+	}
+}
+
+// withCurrentOptions returns an executor bound to the provider's current options snapshot
+// Executors without a provider are returned unchanged
+func (e *Executor) withCurrentOptions() *Executor {
+	if e.optionsProvider == nil {
+		return e
+	}
+	bound := *e
+	bound.options = query.ResolveOptions(e.optionsProvider)
+	return &bound
+}
+
 // Name returns the name of this executor
 func (e *Executor) Name() string {
 	return "GORM"
 }
 
-// Close cleans up resources (GORM connections are managed separately)
+// Close stops the replica health checks (GORM connections are managed separately)
 func (e *Executor) Close() error {
+	if e.replicas != nil {
+		e.replicas.close()
+	}
 	return nil
 }
 
 // Execute runs the query and stores results in dest
 // dest must be a pointer to a slice (e.g., &[]User{})
 func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	e = e.withCurrentOptions().withQueryOptions(ctx)
+	entry := query.NewQueryLog(e.Name(), "execute", q)
+	var result *query.Result
+	err := e.read(func(e *Executor) error {
+		var err error
+		result, err = e.executeQuery(ctx, q, cursorParam, dest, &entry)
+		return err
+	})
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+		result.Warnings = e.options.SearchTermWarnings(q)
+	}
+	e.options.LogExecute(ctx, entry, result, err)
+	return query.WrapResult(e.Name(), "execute", result, err)
+}
+
+func (e *Executor) executeQuery(ctx context.Context, q *query.Query, cursorParam string, dest interface{}, entry *query.QueryLog) (*query.Result, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.ValidateDistinct(q); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
+	var result *query.Result
+	var execErr error
+	err = e.withTimeout(ctx, func(ctx context.Context, bound *Executor) error {
+		return bound.withInTables(ctx, q.Filter, func(bound *Executor) error {
+			result, execErr = bound.execute(ctx, q, cursorParam, dest)
+			return execErr
+		})
+	})
+	if result == nil && err != nil {
+		return &query.Result{Error: err}, err
+	}
+	return result, execErr
+}
+
+// execute runs the query against e.db
+func (e *Executor) execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
 	result := &query.Result{}
 
 	// Validate and adjust page size
 	pageSize := e.options.ValidatePageSize(q.PageSize)
 
 	// Build base query
-	tx := e.db.WithContext(ctx)
+	tx, err := e.applySoftDelete(e.db.WithContext(ctx), q, dest)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
 
 	// Build WHERE clause from filter
-	if q.Filter != nil {
-		whereClauses, args, err := e.buildFilter(q.Filter)
-		if err != nil {
-			result.Error = err
-			return result, err
-		}
-		if whereClauses != "" {
-			tx = tx.Where(whereClauses, args...)
-		}
+	tx, err = e.applyFilter(tx, q.Filter, dest)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+	tx = e.applyQueryHints(tx)
+	tx, err = e.applyDistinct(tx, q)
+	if err != nil {
+		result.Error = err
+		return result, err
 	}
 
-	// Count total items
-	var totalItems int64
-	if err := tx.Count(&totalItems).Error; err != nil {
-		result.Error = query.NewExecutionError("count items", err)
-		return result, result.Error
+	// Count total items; CountAsync counts while the page is fetched
+	waitCount := e.countTotal(ctx, tx, q, dest)
+	finishCount := func() error {
+		counted := waitCount()
+		if counted.err != nil {
+			result.Error = query.NewExecutionError("count items", counted.err)
+			return result.Error
+		}
+		result.TotalItems, result.TotalItemsEstimated = counted.total, counted.estimated
+		return nil
 	}
-	result.TotalItems = totalItems
 
 	// Handle cursor-based pagination
 	cursorData, err := cursor.Decode(cursorParam)
@@ -78,6 +215,18 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 		result.Error = fmt.Errorf("%w: %v", query.ErrInvalidCursor, err)
 		return result, result.Error
 	}
+	if err := cursorData.CheckQuery(q); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	if cursorData == nil {
+		offset, err := e.options.PageOffset(q, pageSize)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		cursorData = cursor.Jump(offset)
+	}
 
 	// Handle limit enforcement
 	itemsReturnedSoFar := 0
@@ -88,6 +237,9 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 		remaining := q.Limit - itemsReturnedSoFar
 		if remaining <= 0 {
 			// Limit already reached, return empty result
+			if err := finishCount(); err != nil {
+				return result, err
+			}
 			result.ItemsReturned = 0
 			result.ShowingFrom = 0
 			result.ShowingTo = 0
@@ -100,6 +252,10 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 	}
 
 	// Handle sorting
+	if err := e.options.ValidateSortField(q.SortBy); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
 	sortField := q.SortBy
 	if sortField == "" {
 		sortField = e.options.DefaultSortField
@@ -110,27 +266,71 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 		sortOrder = e.options.DefaultSortOrder
 	}
 
+	// Relevance scores are not available from plain SQL
+	if sortField == query.ScoreField {
+		result.Error = fmt.Errorf("%w: sorting by %s is not supported by the GORM executor", query.ErrInvalidQuery, query.ScoreField)
+		return result, result.Error
+	}
+
+	inOrder, err := query.InOrderCondition(q)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Preserved IN order, match counts and random ordering page by offset instead of by last ID
+	matchSort := inOrder == nil && sortField == query.MatchCountField
+	offsetPaging := inOrder != nil || matchSort || sortOrder == query.SortOrderRandom
+	// Pages jumped to by number skip by offset under any ordering; the pages
+	// after them follow keyset cursors again
+	jumped := cursorData != nil && cursorData.LastID == nil && cursorData.Offset > 0
+
 	// Handle random ordering
 	var randomSeed int64
-	if sortOrder == query.SortOrderRandom {
+	if inOrder != nil {
+		orderBy, err := e.buildInOrderClause(inOrder)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		if orderBy.Expression != nil {
+			tx = tx.Order(orderBy)
+		}
+
+		// Apply offset for cursor pagination in preserved order mode
+		if cursorData != nil && cursorData.Offset > 0 {
+			tx = tx.Offset(cursorData.Offset)
+		}
+	} else if matchSort {
+		orderBy, err := e.buildMatchCountClause(q.Filter)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		tx = tx.Order(orderBy)
+
+		// Apply offset for cursor pagination in match count mode
+		if cursorData != nil && cursorData.Offset > 0 {
+			tx = tx.Offset(cursorData.Offset)
+		}
+	} else if sortOrder == query.SortOrderRandom {
 		if !e.options.AllowRandomOrder {
 			result.Error = query.ErrRandomOrderNotAllowed
 			return result, result.Error
 		}
 
-		// Generate or reuse random seed
-		if cursorData != nil && cursorData.RandomSeed != 0 {
-			randomSeed = cursorData.RandomSeed
-		} else {
-			randomSeed = 12345 // Use a fixed seed for consistency
+		// Reuse the seed of earlier pages, so pages of one order do not overlap
+		var cursorSeed int64
+		if cursorData != nil {
+			cursorSeed = cursorData.RandomSeed
 		}
-
-		// Use configured random function for random ordering
-		randomFunc := e.options.RandomFunctionName
-		if randomFunc == "" {
-			randomFunc = "RANDOM()" // Default fallback
+		randomSeed = query.ResolveRandomSeed(q, cursorSeed)
+		orderBy, err := e.buildRandomOrderClause(randomSeed)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
 		}
-		tx = tx.Order(gorm.Expr(randomFunc))
+		tx = tx.Order(orderBy)
 
 		// Apply offset for cursor pagination in random mode
 		if cursorData != nil && cursorData.Offset > 0 {
@@ -157,17 +357,26 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 			if cursorWhere != "" {
 				tx = tx.Where(cursorWhere, cursorArgs...)
 			}
+		} else if jumped {
+			tx = tx.Offset(cursorData.Offset)
 		}
 	}
 
 	// Fetch results (one extra to check for next page)
 	tx = tx.Limit(pageSize + 1)
 
+	if q.ExplainRequested {
+		result.Explain = e.explain(tx, dest)
+	}
+
 	// Execute query - store results directly in dest
 	if err := tx.Find(dest).Error; err != nil {
 		result.Error = query.NewExecutionError("execute query", err)
 		return result, result.Error
 	}
+	if err := finishCount(); err != nil {
+		return result, err
+	}
 
 	// Get slice length using reflection to check if there are more results
 	destValue := reflect.ValueOf(dest)
@@ -179,8 +388,9 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 	sliceValue := destValue.Elem()
 	itemsCount := sliceValue.Len()
 
-	// Check if any records were found
-	if itemsCount == 0 && result.TotalItems == 0 {
+	// Check if any records were found; without a total, an empty first page means none
+	noRecords := result.TotalItems == 0 || result.TotalItems == query.TotalUnknown && cursorData == nil
+	if itemsCount == 0 && noRecords && !e.options.AllowEmptyResults {
 		result.Error = query.ErrNoRecordsFound
 		return result, result.Error
 	}
@@ -197,7 +407,7 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 
 	// Calculate showing from/to
 	var currentOffset int
-	if cursorData != nil && sortOrder == query.SortOrderRandom {
+	if cursorData != nil && (offsetPaging || jumped) {
 		currentOffset = cursorData.Offset
 	}
 
@@ -226,9 +436,10 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 			nextCursorData := &cursor.CursorData{
 				Direction:     "next",
 				ItemsReturned: itemsReturnedSoFar + result.ItemsReturned,
+				QueryHash:     cursor.QueryHash(q),
 			}
 
-			if sortOrder == query.SortOrderRandom {
+			if offsetPaging {
 				nextCursorData.Offset = currentOffset + pageSize
 				nextCursorData.RandomSeed = randomSeed
 			} else {
@@ -278,9 +489,10 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 			prevCursorData := &cursor.CursorData{
 				Direction:     "prev",
 				ItemsReturned: prevItemsReturned,
+				QueryHash:     cursor.QueryHash(q),
 			}
 
-			if sortOrder == query.SortOrderRandom {
+			if offsetPaging || jumped {
 				prevOffset := currentOffset - pageSize
 				if prevOffset < 0 {
 					prevOffset = 0
@@ -329,6 +541,77 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 	return result, nil
 }
 
+// buildInOrderClause orders rows by the position of their value in the IN
+// condition's values: ORDER BY CASE field WHEN ? THEN 0 WHEN ? THEN 1 ... END.
+// An empty IN matches nothing, so no ordering (an empty OrderBy) is returned.
+func (e *Executor) buildInOrderClause(n *query.ComparisonNode) (clause.OrderBy, error) {
+	if !e.isValidField(n.Field) {
+		return clause.OrderBy{}, query.InvalidFieldNameError(n.Field)
+	}
+	values, err := e.convertArrayValue(n.Field, n.Value)
+	if err != nil {
+		return clause.OrderBy{}, err
+	}
+	if len(values) == 0 {
+		return clause.OrderBy{}, nil
+	}
+
+	var sb strings.Builder
+	vars := make([]interface{}, 0, len(values)*2)
+	fmt.Fprintf(&sb, "CASE %s", n.Field)
+	for i, v := range values {
+		sb.WriteString(" WHEN ? THEN ?")
+		vars = append(vars, v, i)
+	}
+	sb.WriteString(" ELSE ? END")
+	vars = append(vars, len(values))
+
+	return clause.OrderBy{Expression: clause.Expr{SQL: sb.String(), Vars: vars, WithoutParentheses: true}}, nil
+}
+
+// buildMatchCountClause orders rows by the number of MatchClauses of the filter
+// they match, most first, with ties ordered by ID:
+// ORDER BY (CASE WHEN (clause) THEN 1 ELSE 0 END + ...) DESC, id ASC
+func (e *Executor) buildMatchCountClause(filter query.Node) (clause.OrderBy, error) {
+	clauses, err := e.options.MatchClauses(filter)
+	if err != nil {
+		return clause.OrderBy{}, err
+	}
+	idField := e.getIDFieldName()
+	if !e.isValidField(idField) {
+		return clause.OrderBy{}, query.InvalidFieldNameError(idField)
+	}
+
+	terms := make([]string, len(clauses))
+	var vars []interface{}
+	for i, c := range clauses {
+		where, args, err := e.buildFilter(c)
+		if err != nil {
+			return clause.OrderBy{}, err
+		}
+		terms[i] = fmt.Sprintf("CASE WHEN (%s) THEN 1 ELSE 0 END", where)
+		vars = append(vars, args...)
+	}
+	sql := fmt.Sprintf("(%s) DESC, %s ASC", strings.Join(terms, " + "), idField)
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: vars, WithoutParentheses: true}}, nil
+}
+
+// buildRandomOrderClause orders rows by a hash of their ID and seed, with ties
+// broken by ID, so the same seed gives the same order on every page. A custom
+// RandomFunctionName is used instead, unseeded
+func (e *Executor) buildRandomOrderClause(seed int64) (clause.OrderBy, error) {
+	if name := e.options.RandomFunctionName; name != "" && name != defaultRandomFunction {
+		return clause.OrderBy{Expression: clause.Expr{SQL: name, WithoutParentheses: true}}, nil
+	}
+	idField := e.getIDFieldName()
+	if !e.isValidField(idField) {
+		return clause.OrderBy{}, query.InvalidFieldNameError(idField)
+	}
+	hash, vars := e.seededHash(idField, seed)
+	sql := fmt.Sprintf("%s, %s ASC", hash, idField)
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: vars, WithoutParentheses: true}}, nil
+}
+
 // getIDFieldName returns the ID field name to use, with fallback defaults
 func (e *Executor) getIDFieldName() string {
 	if e.options.IDFieldName != "" {
@@ -386,166 +669,244 @@ func (e *Executor) isIDField(fieldName string) bool {
 func (e *Executor) buildFilter(node query.Node) (string, []interface{}, error) {
 	switch n := node.(type) {
 	case *query.BinaryOpNode:
-		left, leftArgs, err := e.buildFilter(n.Left)
-		if err != nil {
-			return "", nil, err
-		}
-		right, rightArgs, err := e.buildFilter(n.Right)
-		if err != nil {
-			return "", nil, err
+		if n.Operator != query.BinaryOpAnd && n.Operator != query.BinaryOpOr {
+			return "", nil, query.ErrInvalidQuery
 		}
-
-		args := append(leftArgs, rightArgs...)
-
-		if n.Operator == query.BinaryOpAnd {
-			return fmt.Sprintf("(%s) AND (%s)", left, right), args, nil
-		} else if n.Operator == query.BinaryOpOr {
-			return fmt.Sprintf("(%s) OR (%s)", left, right), args, nil
+		// Chains of one operator are written flat: (a) AND (b) AND (c)
+		var clauses []string
+		var args []interface{}
+		for _, operand := range query.Operands(n) {
+			clause, operandArgs, err := e.buildFilter(operand)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses = append(clauses, "("+clause+")")
+			args = append(args, operandArgs...)
 		}
-		return "", nil, query.ErrInvalidQuery
+		return strings.Join(clauses, " "+strings.ToUpper(n.Operator.String())+" "), args, nil
 
 	case *query.ComparisonNode:
 		// Handle default search field
 		field := n.Field
-		if field == "__DEFAULT_SEARCH__" {
+		if field == query.SearchField {
+			// SQL Server has no regular expressions, see regexClause
+			if expanded := e.options.ExpandPhrase(n, e.dialectName() != dialectSQLServer); expanded != n {
+				return e.buildFilter(expanded)
+			}
+			if len(e.options.DefaultSearchFields) > 0 {
+				// Expand bare terms to an OR across all default search fields
+				return e.buildFilter(query.ExpandDefaultSearch(n, e.options.DefaultSearchFields))
+			}
 			field = e.options.DefaultSearchField
 		}
 
 		// Check if field is in allowed list (security)
 		if !e.options.IsFieldAllowed(field) {
-			return "", nil, query.FieldNotAllowedError(field)
+			return "", nil, query.FieldNotAllowedError(field, e.options.AllowedFields...)
+		}
+
+		// Fields of associated models
+		if clause, args, ok, err := e.buildRelationClause(n, field); err != nil {
+			return "", nil, err
+		} else if ok {
+			return clause, args, nil
 		}
 
 		// Validate field name to prevent SQL injection
-		if !e.isValidField(field) {
-			return "", nil, query.InvalidFieldNameError(field)
+		column, err := e.column(field)
+		if err != nil {
+			return "", nil, err
 		}
 
-		switch n.Operator {
-		case query.OpEqual:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s = ?", field), []interface{}{val}, nil
-		case query.OpNotEqual:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s != ?", field), []interface{}{val}, nil
-		case query.OpGreaterThan:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s > ?", field), []interface{}{val}, nil
-		case query.OpGreaterThanOrEqual:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s >= ?", field), []interface{}{val}, nil
-		case query.OpLessThan:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s < ?", field), []interface{}{val}, nil
-		case query.OpLessThanOrEqual:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s <= ?", field), []interface{}{val}, nil
-		case query.OpLike:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s LIKE ?", field), []interface{}{val}, nil
-		case query.OpNotLike:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			return fmt.Sprintf("%s NOT LIKE ?", field), []interface{}{val}, nil
-		case query.OpContains:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			str := fmt.Sprintf("%v", val)
-			return fmt.Sprintf("%s LIKE ?", field), []interface{}{fmt.Sprintf("%%%v%%", str)}, nil
-		case query.OpIContains:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			str := fmt.Sprintf("%v", val)
-			return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", field), []interface{}{fmt.Sprintf("%%%v%%", str)}, nil
-		case query.OpStartsWith:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			str := fmt.Sprintf("%v", val)
-			return fmt.Sprintf("%s LIKE ?", field), []interface{}{fmt.Sprintf("%v%%", str)}, nil
-		case query.OpEndsWith:
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			str := fmt.Sprintf("%v", val)
-			return fmt.Sprintf("%s LIKE ?", field), []interface{}{fmt.Sprintf("%%%v", str)}, nil
-		case query.OpRegex:
-			// Check if regex is disabled
-			if e.options.DisableRegex {
-				return "", nil, query.ErrRegexNotSupported
-			}
-			// Note: Regex support varies by database
-			// PostgreSQL: ~, MySQL: REGEXP, SQLite: REGEXP (needs extension)
-			val, err := e.convertValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			str := fmt.Sprintf("%v", val)
-			return fmt.Sprintf("%s REGEXP ?", field), []interface{}{str}, nil
-		case query.OpIn:
-			arr, err := e.convertArrayValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			if len(arr) == 0 {
-				return "1 = 0", []interface{}{}, nil // Empty IN clause
-			}
-			placeholders := make([]string, len(arr))
-			for i := range arr {
-				placeholders[i] = "?"
-			}
-			return fmt.Sprintf("%s IN (%s)", field, strings.Join(placeholders, ", ")), arr, nil
-		case query.OpNotIn:
-			arr, err := e.convertArrayValue(field, n.Value)
-			if err != nil {
-				return "", nil, err
-			}
-			if len(arr) == 0 {
-				return "1 = 1", []interface{}{}, nil // Empty NOT IN clause
-			}
-			placeholders := make([]string, len(arr))
-			for i := range arr {
-				placeholders[i] = "?"
-			}
-			return fmt.Sprintf("%s NOT IN (%s)", field, strings.Join(placeholders, ", ")), arr, nil
-		default:
-			return "", nil, query.ErrInvalidQuery
+		if n.Modifier != query.ArrayModifierNone {
+			return e.buildArrayComparison(n, field, column)
 		}
+		return e.buildComparison(n, field, column)
 
 	default:
 		return "", nil, query.ErrInvalidQuery
 	}
 }
 
+// buildComparison translates the operator of n applied to column. Values are
+// converted as values of field
+func (e *Executor) buildComparison(n *query.ComparisonNode, field, column string) (string, []interface{}, error) {
+	switch n.Operator {
+	case query.OpEqual:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if lo, hi, ok := e.options.FloatRange(val); ok {
+			return fmt.Sprintf("%s BETWEEN ? AND ?", column), []interface{}{lo, hi}, nil
+		}
+		return fmt.Sprintf("%s = ?", column), []interface{}{val}, nil
+	case query.OpNotEqual:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if lo, hi, ok := e.options.FloatRange(val); ok {
+			return fmt.Sprintf("%s NOT BETWEEN ? AND ?", column), []interface{}{lo, hi}, nil
+		}
+		return fmt.Sprintf("%s != ?", column), []interface{}{val}, nil
+	case query.OpGreaterThan:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s > ?", column), []interface{}{val}, nil
+	case query.OpGreaterThanOrEqual:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s >= ?", column), []interface{}{val}, nil
+	case query.OpLessThan:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s < ?", column), []interface{}{val}, nil
+	case query.OpLessThanOrEqual:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s <= ?", column), []interface{}{val}, nil
+	case query.OpLike:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s LIKE ?", column), []interface{}{val}, nil
+	case query.OpNotLike:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s NOT LIKE ?", column), []interface{}{val}, nil
+	case query.OpContains:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		str := fmt.Sprintf("%v", val)
+		return fmt.Sprintf("%s LIKE ?", column), []interface{}{fmt.Sprintf("%%%v%%", str)}, nil
+	case query.OpIContains:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		str := fmt.Sprintf("%v", val)
+		return e.icontainsClause(column), []interface{}{fmt.Sprintf("%%%v%%", str)}, nil
+	case query.OpStartsWith:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		str := fmt.Sprintf("%v", val)
+		return fmt.Sprintf("%s LIKE ?", column), []interface{}{fmt.Sprintf("%v%%", str)}, nil
+	case query.OpEndsWith:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		str := fmt.Sprintf("%v", val)
+		return fmt.Sprintf("%s LIKE ?", column), []interface{}{fmt.Sprintf("%%%v", str)}, nil
+	case query.OpRegex:
+		// Check if regex is disabled
+		if e.options.DisableRegex {
+			return "", nil, query.ErrRegexNotSupported
+		}
+		// Regex syntax varies by database, see regexClause
+		clause, err := e.regexClause(column)
+		if err != nil {
+			return "", nil, err
+		}
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		str := e.options.ConditionPattern(n, fmt.Sprintf("%v", val))
+		return clause, []interface{}{str}, nil
+	case query.OpIn:
+		if clause, args, ok, err := e.buildLargeInClause(n, field, column, "IN"); err != nil {
+			return "", nil, err
+		} else if ok {
+			return clause, args, nil
+		}
+		arr, err := e.convertArrayValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(arr) == 0 {
+			return e.constantClause(false), []interface{}{}, nil // Empty IN clause
+		}
+		placeholders := make([]string, len(arr))
+		for i := range arr {
+			placeholders[i] = "?"
+		}
+		return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), arr, nil
+	case query.OpNotIn:
+		if clause, args, ok, err := e.buildLargeInClause(n, field, column, "NOT IN"); err != nil {
+			return "", nil, err
+		} else if ok {
+			return clause, args, nil
+		}
+		arr, err := e.convertArrayValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(arr) == 0 {
+			return e.constantClause(true), []interface{}{}, nil // Empty NOT IN clause
+		}
+		placeholders := make([]string, len(arr))
+		for i := range arr {
+			placeholders[i] = "?"
+		}
+		return fmt.Sprintf("%s NOT IN (%s)", column, strings.Join(placeholders, ", ")), arr, nil
+	case query.OpMatch:
+		val, err := e.convertValue(field, n.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return e.buildMatchClause(column, fmt.Sprintf("%v", val))
+	default:
+		return "", nil, query.ErrInvalidQuery
+	}
+}
+
+// buildMatchClause builds a full-text MATCH clause for the current dialect
+//   - FullTextTemplate, if set (e.g. "%s MATCH ?" for SQLite FTS5 tables)
+//   - PostgreSQL: to_tsvector/plainto_tsquery
+//   - MySQL: MATCH ... AGAINST in natural language mode (requires a FULLTEXT index)
+//   - Others: every search term must appear in the field (tokenized LIKE)
+func (e *Executor) buildMatchClause(field string, search string) (string, []interface{}, error) {
+	if e.options.FullTextTemplate != "" {
+		return fmt.Sprintf(e.options.FullTextTemplate, field), []interface{}{search}, nil
+	}
+
+	switch e.dialectName() {
+	case dialectPostgres:
+		return fmt.Sprintf("to_tsvector(%s) @@ plainto_tsquery(?)", field), []interface{}{search}, nil
+	case dialectMySQL:
+		return fmt.Sprintf("MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE)", field), []interface{}{search}, nil
+	}
+
+	terms := query.SearchTerms(search)
+	if len(terms) == 0 {
+		return e.constantClause(false), []interface{}{}, nil
+	}
+	clauses := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+	for i, term := range terms {
+		clauses[i] = fmt.Sprintf("LOWER(%s) LIKE ?", field)
+		args[i] = "%" + term + "%"
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")", args, nil
+}
+
 // isValidField validates field names to prevent SQL injection
 // Only allows alphanumeric characters and underscores, must start with letter or underscore
 func (e *Executor) isValidField(field string) bool {
@@ -570,6 +931,19 @@ func (e *Executor) isValidField(field string) bool {
 	return true
 }
 
+// column returns the column of field in a condition. Plain identifiers are
+// used as is; other names, such as "order date", are accepted only when
+// listed in AllowedFields and are quoted for the dialect
+func (e *Executor) column(field string) (string, error) {
+	if e.isValidField(field) {
+		return field, nil
+	}
+	if !e.options.IsFieldListed(field) {
+		return "", query.InvalidFieldNameError(field)
+	}
+	return e.quoteIdentifier(field), nil
+}
+
 // convertValue converts query values to appropriate types and applies ValueConverter if configured
 func (e *Executor) convertValue(field string, val interface{}) (interface{}, error) {
 	// First convert to base type
@@ -664,23 +1038,71 @@ func (e *Executor) buildCursorFilter(cursorData *cursor.CursorData, sortField st
 // Count returns the total number of items that would be returned by the given query
 // This does not apply pagination - it counts all matching items
 func (e *Executor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	e = e.withCurrentOptions().withQueryOptions(ctx)
+	entry := query.NewQueryLog(e.Name(), "count", q)
+	var count int64
+	err := e.read(func(e *Executor) error {
+		var err error
+		count, err = e.countQuery(ctx, q, &entry)
+		return err
+	})
+	e.options.LogCount(ctx, entry, count, err)
+	return count, query.WrapError(e.Name(), "count", err)
+}
+
+func (e *Executor) countQuery(ctx context.Context, q *query.Query, entry *query.QueryLog) (int64, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateDistinct(q); err != nil {
+		return 0, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
+	var totalItems int64
+	err = e.withTimeout(ctx, func(ctx context.Context, bound *Executor) error {
+		return bound.withInTables(ctx, q.Filter, func(bound *Executor) error {
+			var countErr error
+			totalItems, countErr = bound.count(ctx, q)
+			return countErr
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return totalItems, nil
+}
+
+// count counts matching rows in e.db
+func (e *Executor) count(ctx context.Context, q *query.Query) (int64, error) {
 	// Build base query
-	tx := e.db.WithContext(ctx)
+	tx, err := e.applySoftDelete(e.db.WithContext(ctx), q, nil)
+	if err != nil {
+		return 0, err
+	}
 
 	// Build WHERE clause from filter
-	if q.Filter != nil {
-		whereClauses, args, err := e.buildFilter(q.Filter)
-		if err != nil {
-			return 0, err
-		}
-		if whereClauses != "" {
-			tx = tx.Where(whereClauses, args...)
-		}
+	tx, err = e.applyFilter(tx, q.Filter, nil)
+	if err != nil {
+		return 0, err
+	}
+	tx = e.applyQueryHints(tx)
+	tx, err = e.applyDistinct(tx, q)
+	if err != nil {
+		return 0, err
 	}
 
 	// Count total items
-	var totalItems int64
-	if err := tx.Count(&totalItems).Error; err != nil {
+	totalItems, err := countRows(tx, q, nil)
+	if err != nil {
 		return 0, query.NewExecutionError("count items", err)
 	}
 
@@ -0,0 +1,114 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Article stores its tags and scores as JSON arrays
+type Article struct {
+	ID     uint `gorm:"primaryKey"`
+	Title  string
+	Tags   string
+	Scores string
+}
+
+func setupArticles(t *testing.T) executor.Executor {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Article{}))
+	require.NoError(t, db.Create([]Article{
+		{ID: 1, Title: "Hubs", Tags: `["usb","hub","wireless"]`, Scores: `[3, 9]`},
+		{ID: 2, Title: "Cables", Tags: `["usb","cable"]`, Scores: `[5]`},
+		{ID: 3, Title: "Speakers", Tags: `["audio","wireless","bluetooth","portable"]`, Scores: `[]`},
+		{ID: 4, Title: "Untagged", Tags: `[]`, Scores: `[1]`},
+	}).Error)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	return NewExecutor(db.Model(&Article{}), opts)
+}
+
+func TestGORMExecutor_ArrayModifiers(t *testing.T) {
+	executor := setupArticles(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		input string
+		ids   []uint
+	}{
+		{"tags LENGTH > 2", []uint{1, 3}},
+		{"tags LENGTH = 0", []uint{4}},
+		{`tags ANY = "wireless"`, []uint{1, 3}},
+		{`tags ANY STARTS_WITH "bl"`, []uint{3}},
+		{"tags ANY IN [cable, audio]", []uint{2, 3}},
+		{"scores ANY >= 5", []uint{1, 2}},
+		{"tags ALL IN [usb, wireless]", []uint{1}},
+		{"tags ALL IN [usb]", []uint{1, 2}},
+		{"tags ANY = usb AND title != Cables", []uint{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := parser.NewParser(tt.input)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var articles []Article
+			_, err = executor.Execute(ctx, q, "", &articles)
+			if len(tt.ids) == 0 {
+				assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+				return
+			}
+			require.NoError(t, err)
+			ids := make([]uint, len(articles))
+			for i, a := range articles {
+				ids[i] = a.ID
+			}
+			assert.Equal(t, tt.ids, ids)
+
+			count, err := executor.Count(ctx, q)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.ids)), count)
+		})
+	}
+}
+
+func TestExecutor_ArrayModifierDialects(t *testing.T) {
+	tests := []struct {
+		input    string
+		dialect  string
+		expected string
+	}{
+		{"tags LENGTH > 2", "postgres", "jsonb_array_length(CAST(tags AS jsonb)) > ?"},
+		{"tags LENGTH > 2", "mysql", "JSON_LENGTH(tags) > ?"},
+		{"tags LENGTH > 2", "sqlserver", "(SELECT COUNT(*) FROM OPENJSON(tags)) > ?"},
+		{"tags ANY = usb", "postgres", "EXISTS (SELECT 1 FROM jsonb_array_elements_text(CAST(tags AS jsonb)) AS _elements(value) WHERE value = ?)"},
+		{"tags ANY = usb", "sqlite", "EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)"},
+		{"tags ALL IN []", "sqlite", "TRUE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect+" "+tt.input, func(t *testing.T) {
+			filter, err := parser.ParseFilter(tt.input)
+			require.NoError(t, err)
+			clause, _, err := dialectExecutor(tt.dialect, nil).buildFilter(filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, clause)
+		})
+	}
+
+	filter, err := parser.ParseFilter("tags LENGTH > 2")
+	require.NoError(t, err)
+	_, _, err = dialectExecutor("oracle", nil).buildFilter(filter)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+}
@@ -0,0 +1,59 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_ExecuteBatch(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.CountMode = query.CountAsync
+	exec := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	var queries []*query.Query
+	for _, input := range []string{
+		"category = electronics page_size = 2",
+		"unknown_column = 1",
+		"category = nonexistent",
+		"price > 100",
+	} {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		queries = append(queries, q)
+	}
+	var electronics, invalid, none, expensive []Product
+
+	results, err := executor.ExecuteBatch(ctx, exec, queries, []interface{}{&electronics, &invalid, &none, &expensive})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, int64(5), results[0].Result.TotalItems)
+	assert.Len(t, electronics, 2)
+	assert.NotEmpty(t, results[0].Result.NextPageCursor)
+
+	assert.Error(t, results[1].Err)
+	assert.ErrorIs(t, results[2].Err, query.ErrNoRecordsFound)
+
+	require.NoError(t, results[3].Err)
+	for _, product := range expensive {
+		assert.Greater(t, product.Price, 100.0)
+	}
+	assert.Equal(t, int64(len(expensive)), results[3].Result.TotalItems)
+
+	_, err = executor.ExecuteBatch(ctx, exec, queries, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidDestination)
+}
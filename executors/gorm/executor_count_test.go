@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/hadi77ir/go-query/executor"
 	"github.com/hadi77ir/go-query/parser"
 	"github.com/hadi77ir/go-query/query"
 	"github.com/stretchr/testify/assert"
@@ -179,3 +180,67 @@ func TestGORMExecutor_Count(t *testing.T) {
 		}
 	})
 }
+
+func TestGORMExecutor_CountModes(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+	ctx := context.Background()
+
+	execWith := func(mode query.CountMode) executor.Executor {
+		opts := query.DefaultExecutorOptions()
+		opts.DefaultSortField = "id"
+		opts.CountMode = mode
+		return NewExecutor(db.Model(&Product{}), opts)
+	}
+
+	t.Run("async counts alongside the page", func(t *testing.T) {
+		p, err := parser.NewParser("category = electronics page_size = 3")
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var products []Product
+		result, err := execWith(query.CountAsync).Execute(ctx, q, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), result.TotalItems)
+		assert.False(t, result.TotalItemsEstimated)
+		assert.Len(t, products, 3)
+	})
+
+	t.Run("estimated falls back to exact on sqlite", func(t *testing.T) {
+		var products []Product
+		result, err := execWith(query.CountEstimated).Execute(ctx, &query.Query{}, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), result.TotalItems)
+		assert.False(t, result.TotalItemsEstimated)
+	})
+
+	t.Run("none skips the total", func(t *testing.T) {
+		exec := execWith(query.CountNone)
+		var products []Product
+		q := &query.Query{PageSize: 3}
+		result, err := exec.Execute(ctx, q, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, query.TotalUnknown, result.TotalItems)
+		assert.Equal(t, 1, result.ShowingFrom)
+		assert.Equal(t, 3, result.ShowingTo)
+		require.NotEmpty(t, result.NextPageCursor)
+
+		products = nil
+		result, err = exec.Execute(ctx, q, result.NextPageCursor, &products)
+		require.NoError(t, err)
+		assert.Equal(t, query.TotalUnknown, result.TotalItems)
+		assert.Len(t, products, 3)
+	})
+
+	t.Run("none still reports an empty first page", func(t *testing.T) {
+		p, err := parser.NewParser("category = nonexistent")
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var products []Product
+		_, err = execWith(query.CountNone).Execute(ctx, q, "", &products)
+		assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+	})
+}
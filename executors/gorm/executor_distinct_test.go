@@ -0,0 +1,76 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_DistinctOn(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	p, err := parser.NewParser("distinct_on = brand sort_by = price sort_order = desc page_size = 4")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	// One product per brand, the most expensive one
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), result.TotalItems)
+	require.Len(t, page, 4)
+	assert.Equal(t, []string{"Sony", "Corsair", "Logitech", "JBL"}, []string{page[0].Brand, page[1].Brand, page[2].Brand, page[3].Brand})
+	assert.Equal(t, 69.99, page[2].Price)
+
+	var next []Product
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &next)
+	require.NoError(t, err)
+	require.Len(t, next, 3)
+	assert.Equal(t, "Anker", next[0].Brand)
+	assert.Equal(t, 39.99, next[0].Price)
+	assert.Empty(t, result.NextPageCursor)
+
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+
+	// Field names are validated
+	_, err = executor.Count(ctx, &query.Query{DistinctOn: "brand; DROP TABLE products"})
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
+
+func TestGORMExecutor_Distinct(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "category"
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	// A smaller destination selects only its columns, so rows repeat without DISTINCT
+	type categoryRow struct {
+		Category string
+	}
+	var rows []categoryRow
+	result, err := executor.Execute(ctx, &query.Query{Distinct: true, PageSize: 100}, "", &rows)
+	require.NoError(t, err)
+	assert.Equal(t, []categoryRow{{"accessories"}, {"electronics"}}, rows)
+	assert.Equal(t, int64(2), result.TotalItems)
+
+	// Whole model rows are distinct already
+	count, err := executor.Count(ctx, &query.Query{Distinct: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), count)
+}
@@ -169,6 +169,39 @@ func TestGORMExecutor_ErrorHandling(t *testing.T) {
 	})
 }
 
+func TestGORMExecutor_ErrorModel(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.AllowedFields = []string{"id", "name"}
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	var products []Product
+	result, err := executor.Execute(ctx, &query.Query{Filter: query.Eq("price", 10)}, "", &products)
+	require.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	assert.Equal(t, err, result.Error)
+
+	var qerr *query.Error
+	require.True(t, errors.As(err, &qerr))
+	assert.Equal(t, "GORM", qerr.Backend)
+	assert.Equal(t, "execute", qerr.Op)
+	assert.Equal(t, "price", qerr.Field)
+
+	_, err = executor.Count(ctx, &query.Query{Filter: query.Eq("price", 10)})
+	require.True(t, errors.As(err, &qerr))
+	assert.Equal(t, "count", qerr.Op)
+
+	opts.AllowedFields = nil
+	opts.AllowEmptyResults = true
+	result, err = executor.Execute(ctx, &query.Query{Filter: query.Eq("name", "NonExistent")}, "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.TotalItems)
+	assert.Empty(t, products)
+}
+
 func TestGORMExecutor_ErrorWrapping(t *testing.T) {
 	db := setupTestDB(t)
 
@@ -234,3 +267,99 @@ Example usage documentation:
 		log.Printf("Error with field: %s", fieldErr.Field)
 	}
 */
+
+func TestGORMExecutor_CursorQueryBinding(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser("category = electronics page_size = 2")
+	q, _ := p.Parse()
+
+	var page1 []Product
+	result, err := executor.Execute(ctx, q, "", &page1)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.NextPageCursor)
+
+	p, _ = parser.NewParser("category = electronics sort_order = desc page_size = 2")
+	other, _ := p.Parse()
+
+	var page2 []Product
+	result, err = executor.Execute(ctx, other, result.NextPageCursor, &page2)
+	assert.ErrorIs(t, err, query.ErrCursorQueryMismatch)
+	assert.ErrorIs(t, result.Error, query.ErrCursorQueryMismatch)
+}
+
+func TestGORMExecutor_ScoreSortUnsupported(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	executor := NewExecutor(db.Model(&Product{}), query.DefaultExecutorOptions())
+
+	p, _ := parser.NewParser(`name MATCH "mouse" sort_by = _score`)
+	q, _ := p.Parse()
+
+	var products []Product
+	_, err := executor.Execute(context.Background(), q, "", &products)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	assert.Contains(t, err.Error(), query.ScoreField)
+}
+
+func TestGORMExecutor_SortFieldValidation(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.SortableFields = []string{"name", "price", "created_at"}
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser("sort_by = created")
+	q, _ := p.Parse()
+
+	var products []Product
+	result, err := executor.Execute(ctx, q, "", &products)
+	assert.ErrorIs(t, err, query.ErrInvalidSortField)
+	assert.ErrorIs(t, result.Error, query.ErrInvalidSortField)
+	assert.Contains(t, err.Error(), "did you mean: created_at?")
+
+	// Default sort field is always accepted
+	p, _ = parser.NewParser("sort_by = id")
+	q, _ = p.Parse()
+	_, err = executor.Execute(ctx, q, "", &products)
+	assert.NoError(t, err)
+}
+
+func TestGORMExecutor_FieldPolicy(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.FieldPolicy = map[string][]query.ComparisonOperator{
+		query.AnyField: {query.OpEqual, query.OpGreaterThan, query.OpLessThan, query.OpIn},
+	}
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser(`brand = Anker AND price < 30`)
+	q, _ := p.Parse()
+	var products []Product
+	_, err := executor.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+
+	p, _ = parser.NewParser(`name REGEX "^USB"`)
+	q, _ = p.Parse()
+	result, err := executor.Execute(ctx, q, "", &products)
+	assert.ErrorIs(t, err, query.ErrOperatorNotAllowed)
+	assert.ErrorIs(t, result.Error, query.ErrOperatorNotAllowed)
+
+	_, err = executor.Count(ctx, q)
+	assert.ErrorIs(t, err, query.ErrOperatorNotAllowed)
+}
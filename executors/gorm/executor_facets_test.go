@@ -0,0 +1,86 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_Facets(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	exec := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	p, err := parser.NewParser("category = accessories")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	results, err := executor.Facets(ctx, exec, q, []query.FacetSpec{
+		{Field: "brand"},
+		{Field: "brand", Limit: 2},
+		{Field: "price", Ranges: []query.FacetRange{
+			{To: 20},
+			{Label: "mid", From: 20, To: 35},
+			{From: 35},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, []query.FacetBucket{
+		{Value: "Anker", Count: 3},
+		{Value: "AmazonBasics", Count: 1},
+		{Value: "Razer", Count: 1},
+	}, results[0].Buckets)
+	assert.Equal(t, results[0].Buckets[:2], results[1].Buckets)
+	assert.Equal(t, []query.FacetBucket{
+		{Value: "*-20", Count: 2},
+		{Value: "mid", Count: 2},
+		{Value: "35-*", Count: 1},
+	}, results[2].Buckets)
+
+	t.Run("histograms", func(t *testing.T) {
+		results, err := executor.Facets(ctx, exec, q, []query.FacetSpec{
+			{Field: "created_at", DateInterval: query.DateIntervalWeek},
+			{Field: "created_at", DateInterval: query.DateIntervalMonth},
+			{Field: "price", Interval: 20},
+			{Field: "stock", Interval: 100},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []query.FacetBucket{
+			{Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Count: 4},
+			{Value: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), Count: 1},
+		}, results[0].Buckets)
+		assert.Equal(t, []query.FacetBucket{
+			{Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Count: 5},
+		}, results[1].Buckets)
+		assert.Equal(t, []query.FacetBucket{
+			{Value: 0.0, Count: 2},
+			{Value: 20.0, Count: 3},
+		}, results[2].Buckets)
+		assert.Equal(t, []query.FacetBucket{
+			{Value: 0.0, Count: 4},
+			{Value: 200.0, Count: 1},
+		}, results[3].Buckets)
+	})
+
+	t.Run("not allowed field", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.AllowedFields = []string{"category"}
+		exec := NewExecutor(db.Model(&Product{}), opts)
+
+		_, err := executor.Facets(ctx, exec, q, []query.FacetSpec{{Field: "brand"}})
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	})
+}
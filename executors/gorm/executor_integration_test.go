@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hadi77ir/go-query/executor"
 	"github.com/hadi77ir/go-query/parser"
 	"github.com/hadi77ir/go-query/query"
 	"github.com/stretchr/testify/assert"
@@ -379,6 +380,59 @@ func TestGORMExecutor_BareSearch(t *testing.T) {
 	}
 }
 
+func TestGORMExecutor_MultiFieldBareSearch(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.DefaultSearchFields = []string{"name", "description"}
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []uint
+	}{
+		{"term found in either field", "pad", []uint{5, 6}},
+		{"term found in description only", "ergonomic", []uint{1}},
+		{"combined with field filter", "wireless category = accessories", []uint{6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parser.NewParser(tt.query)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var products []Product
+			_, err = executor.Execute(ctx, q, "", &products)
+			require.NoError(t, err)
+
+			var ids []uint
+			for _, product := range products {
+				ids = append(ids, product.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	t.Run("disallowed search field rejected", func(t *testing.T) {
+		restricted := *opts
+		restricted.AllowedFields = []string{"name", "id"}
+		executor := NewExecutor(db.Model(&Product{}), &restricted)
+
+		p, _ := parser.NewParser("pad")
+		q, _ := p.Parse()
+
+		var products []Product
+		_, err := executor.Execute(ctx, q, "", &products)
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	})
+}
+
 func TestGORMExecutor_Pagination(t *testing.T) {
 	db := setupTestDB(t)
 	seedTestData(t, db)
@@ -636,9 +690,21 @@ func TestGORMExecutor_EdgeCases(t *testing.T) {
 	})
 
 	t.Run("unicode in search", func(t *testing.T) {
-		// Skip: Unicode bare search has parser limitations
-		// Unicode works fine in quoted strings and field comparisons
-		t.Skip("Unicode bare identifiers not supported by parser - use quoted strings instead")
+		db.Create(&Product{ID: 101, Name: "日本語 キーボード", Brand: "Sony"})
+		db.Create(&Product{ID: 102, Name: "کتاب", Brand: "علی"})
+
+		for input, id := range map[string]uint{`日本語`: 101, `brand = علی`: 102} {
+			p, err := parser.NewParser(input)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var products []Product
+			_, err = executor.Execute(ctx, q, "", &products)
+			require.NoError(t, err)
+			require.Len(t, products, 1, input)
+			assert.Equal(t, id, products[0].ID)
+		}
 	})
 
 	t.Run("boolean fields", func(t *testing.T) {
@@ -718,3 +784,205 @@ func TestGORMExecutor_ComplexRealWorld(t *testing.T) {
 		}
 	})
 }
+
+func TestGORMExecutor_MatchOperator(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	t.Run("all terms must appear", func(t *testing.T) {
+		p, _ := parser.NewParser(`description MATCH "cancelling NOISE"`)
+		q, _ := p.Parse()
+
+		var products []Product
+		result, err := executor.Execute(ctx, q, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.TotalItems)
+		assert.Equal(t, "Wireless Headphones", products[0].Name)
+	})
+
+	t.Run("combined with other filters", func(t *testing.T) {
+		p, _ := parser.NewParser(`description MATCH "wireless" OR category = accessories`)
+		q, _ := p.Parse()
+
+		count, err := executor.Count(ctx, q)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), count)
+	})
+
+	t.Run("custom full-text template", func(t *testing.T) {
+		custom := query.DefaultExecutorOptions()
+		custom.DefaultSortField = "id"
+		custom.FullTextTemplate = "instr(lower(%s), ?) > 0"
+		customExec := NewExecutor(db.Model(&Product{}), custom)
+
+		p, _ := parser.NewParser(`name MATCH "usb"`)
+		q, _ := p.Parse()
+
+		count, err := customExec.Count(ctx, q)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+}
+
+func TestGORMExecutor_FloatTolerance(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	// Simulate accumulated rounding error on a stored price
+	require.NoError(t, db.Model(&Product{}).Where("id = ?", 1).Update("price", 29.99+1e-12).Error)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	ctx := context.Background()
+
+	count := func(exec executor.Executor, input string) int64 {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		n, err := exec.Count(ctx, q)
+		require.NoError(t, err)
+		return n
+	}
+
+	exact := NewExecutor(db.Model(&Product{}), opts)
+	assert.Equal(t, int64(0), count(exact, "price = 29.99"))
+
+	tolerant := *opts
+	tolerant.FloatTolerance = 1e-6
+	executor := NewExecutor(db.Model(&Product{}), &tolerant)
+	assert.Equal(t, int64(1), count(executor, "price = 29.99"))
+	assert.Equal(t, int64(9), count(executor, "price != 29.99"))
+	assert.Equal(t, int64(1), count(executor, "stock = 100"), "integer literals compare exactly")
+}
+
+func TestGORMExecutor_PreserveInOrder(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	ids := func(products []Product) []uint {
+		var out []uint
+		for _, p := range products {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	p, _ := parser.NewParser(`id IN [5, 1, 9, 3] AND stock > 0 preserve_in_order = true page_size = 3`)
+	q, _ := p.Parse()
+
+	var page1 []Product
+	result, err := executor.Execute(ctx, q, "", &page1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{5, 1, 9}, ids(page1))
+	assert.Equal(t, 1, result.ShowingFrom)
+
+	var page2 []Product
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &page2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{3}, ids(page2))
+	assert.Equal(t, 4, result.ShowingFrom)
+	assert.NotEmpty(t, result.PrevPageCursor)
+
+	t.Run("empty IN", func(t *testing.T) {
+		p, _ := parser.NewParser(`id IN [] preserve_in_order = true`)
+		q, _ := p.Parse()
+
+		var products []Product
+		_, err := executor.Execute(ctx, q, "", &products)
+		assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+	})
+
+	t.Run("requires an IN condition", func(t *testing.T) {
+		p, _ := parser.NewParser(`stock > 0 preserve_in_order = true`)
+		q, _ := p.Parse()
+
+		var products []Product
+		_, err := executor.Execute(ctx, q, "", &products)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+}
+
+func TestGORMExecutor_MatchCountSorting(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	executor := NewExecutor(db.Model(&Product{}), query.DefaultExecutorOptions())
+	ctx := context.Background()
+
+	ids := func(products []Product) []uint {
+		var out []uint
+		for _, p := range products {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	p, _ := parser.NewParser(`name ICONTAINS wireless OR description ICONTAINS pad OR name CONTAINS Mouse OR description CONTAINS mouse sort_by = _matches page_size = 2`)
+	q, _ := p.Parse()
+
+	// 1 and 5 match three conditions, 6 two and 4 one; ties are ordered by ID
+	var page1 []Product
+	result, err := executor.Execute(ctx, q, "", &page1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{1, 5}, ids(page1))
+	assert.Equal(t, int64(4), result.TotalItems)
+
+	var page2 []Product
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &page2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{6, 4}, ids(page2))
+	assert.Equal(t, 3, result.ShowingFrom)
+
+	t.Run("requires a CONTAINS condition", func(t *testing.T) {
+		p, _ := parser.NewParser(`stock > 0 sort_by = _matches`)
+		q, _ := p.Parse()
+
+		var products []Product
+		_, err := executor.Execute(ctx, q, "", &products)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+}
+
+func TestGORMExecutor_BaseFilter(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.AllowedFields = []string{"id", "name", "category"}
+	opts.BaseFilter = query.F("brand").Eq("Anker").Node()
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser(`category = accessories OR category = electronics`)
+	q, _ := p.Parse()
+
+	var products []Product
+	result, err := executor.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.TotalItems)
+	for _, product := range products {
+		assert.Equal(t, "Anker", product.Brand)
+	}
+
+	count, err := executor.Count(ctx, &query.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// The scoped field is accepted despite AllowedFields
+	p, _ = parser.NewParser(`brand = Sony`)
+	q, _ = p.Parse()
+	_, err = executor.Execute(ctx, q, "", &products)
+	assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+}
@@ -0,0 +1,98 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_LargeInLists(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.LargeInThreshold = 3
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	t.Run("IN above threshold uses temporary table", func(t *testing.T) {
+		p, _ := parser.NewParser("id IN [1, 2, 3, 4, 5, 999]")
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var products []Product
+		result, err := executor.Execute(ctx, q, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), result.TotalItems)
+		assert.Len(t, products, 5)
+	})
+
+	t.Run("NOT IN above threshold", func(t *testing.T) {
+		p, _ := parser.NewParser("id NOT IN [1, 2, 3, 4, 5]")
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		count, err := executor.Count(ctx, q)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+	})
+
+	t.Run("string values combined with other filters", func(t *testing.T) {
+		p, _ := parser.NewParser(`brand IN ["Anker", "Sony", "JBL", "Razer"] AND price < 100`)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var products []Product
+		result, err := executor.Execute(ctx, q, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), result.TotalItems) // Anker x3, Razer, JBL
+	})
+
+	t.Run("pagination works with temporary tables", func(t *testing.T) {
+		p, _ := parser.NewParser("id IN [1, 2, 3, 4, 5, 6] page_size = 4")
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var page1 []Product
+		result, err := executor.Execute(ctx, q, "", &page1)
+		require.NoError(t, err)
+		require.Len(t, page1, 4)
+		require.NotEmpty(t, result.NextPageCursor)
+
+		var page2 []Product
+		_, err = executor.Execute(ctx, q, result.NextPageCursor, &page2)
+		require.NoError(t, err)
+		require.Len(t, page2, 2)
+		assert.Equal(t, uint(5), page2[0].ID)
+	})
+
+	t.Run("field restrictions still apply", func(t *testing.T) {
+		restricted := query.DefaultExecutorOptions()
+		restricted.DefaultSortField = "id"
+		restricted.LargeInThreshold = 3
+		restricted.AllowedFields = []string{"id"}
+		restrictedExec := NewExecutor(db.Model(&Product{}), restricted)
+
+		p, _ := parser.NewParser("stock IN [1, 2, 3, 4]")
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		_, err = restrictedExec.Count(ctx, q)
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	})
+}
+
+func TestPostgresArrayLiteral(t *testing.T) {
+	literal, arrayType := postgresArrayLiteral([]interface{}{int64(1), int64(2), int64(3)})
+	assert.Equal(t, "{1,2,3}", literal)
+	assert.Equal(t, "bigint[]", arrayType)
+
+	literal, arrayType = postgresArrayLiteral([]interface{}{"a", `b"c`, `d\e`})
+	assert.Equal(t, `{"a","b\"c","d\\e"}`, literal)
+	assert.Equal(t, "text[]", arrayType)
+}
@@ -0,0 +1,50 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_OptionsProvider(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	provider := query.NewReloadableOptions(opts)
+	executor := NewExecutorWithOptionsProvider(db.Model(&Product{}), provider)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser("category = electronics page_size = 3")
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	var products []Product
+	result, err := executor.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.ItemsReturned)
+
+	updated := query.DefaultExecutorOptions()
+	updated.DefaultSortField = "id"
+	updated.MaxPageSize = 2
+	provider.Set(updated)
+
+	products = nil
+	result, err = executor.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ItemsReturned)
+
+	restricted := query.DefaultExecutorOptions()
+	restricted.DefaultSortField = "id"
+	restricted.AllowedFields = []string{"id", "name"}
+	provider.Set(restricted)
+
+	_, err = executor.Count(ctx, q)
+	assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+}
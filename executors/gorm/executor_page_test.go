@@ -0,0 +1,75 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_PageJump(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.MaxPageOffset = 6
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	parse := func(input string) *query.Query {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		return q
+	}
+	ids := func(products []Product) []uint {
+		var ids []uint
+		for _, p := range products {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	t.Run("keyset order", func(t *testing.T) {
+		q := parse("page = 3 page_size = 3 sort_by = id")
+		var products []Product
+		result, err := executor.Execute(ctx, q, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{7, 8, 9}, ids(products))
+		assert.Equal(t, 7, result.ShowingFrom)
+		assert.Equal(t, 9, result.ShowingTo)
+
+		// The next page follows a keyset cursor; the page takes no part
+		var next []Product
+		_, err = executor.Execute(ctx, q, result.NextPageCursor, &next)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{10}, ids(next))
+
+		var prev []Product
+		prevResult, err := executor.Execute(ctx, q, result.PrevPageCursor, &prev)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{4, 5, 6}, ids(prev))
+		assert.Equal(t, 4, prevResult.ShowingFrom)
+	})
+
+	t.Run("offset order", func(t *testing.T) {
+		var all, page []Product
+		_, err := executor.Execute(ctx, parse("sort_order = random random_seed = 7 page_size = 10"), "", &all)
+		require.NoError(t, err)
+		_, err = executor.Execute(ctx, parse("sort_order = random random_seed = 7 page_size = 3 page = 2"), "", &page)
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[3:6]), ids(page))
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		var products []Product
+		_, err := executor.Execute(ctx, parse("page = 4 page_size = 3 sort_by = id"), "", &products)
+		assert.True(t, errors.Is(err, query.ErrPageTooDeep))
+	})
+}
@@ -0,0 +1,76 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestExecutor_QueryHints(t *testing.T) {
+	dialector := namedDialector{Dialector: sqlite.Open("file::memory:"), name: "mysql"}
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	e := NewExecutorWithOptions(db, &Options{QueryOptions: QueryOptions{
+		IndexHints: []string{"idx_products_brand"},
+		Timeout:    1500 * time.Millisecond,
+	}}).(*Executor)
+
+	stmt := e.applyQueryHints(db.Model(&Product{}).Where("brand = ?", "Sony")).Find(&[]Product{}).Statement
+	assert.Equal(t, "SELECT /*+ MAX_EXECUTION_TIME(1500) */ * FROM `products` USE INDEX (`idx_products_brand`) WHERE brand = ?", stmt.SQL.String())
+
+	var total int64
+	stmt = e.applyQueryHints(db.Model(&Product{})).Count(&total).Statement
+	assert.Equal(t, "SELECT /*+ MAX_EXECUTION_TIME(1500) */ count(*) FROM `products` USE INDEX (`idx_products_brand`)", stmt.SQL.String())
+
+	// Other dialects have no hints
+	sqliteDB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DryRun: true, Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	e.db = sqliteDB
+	stmt = e.applyQueryHints(sqliteDB.Model(&Product{})).Find(&[]Product{}).Statement
+	assert.Equal(t, "SELECT * FROM `products`", stmt.SQL.String())
+}
+
+func TestExecutor_QueryOptionsOverride(t *testing.T) {
+	e := NewExecutorWithOptions(nil, &Options{QueryOptions: QueryOptions{
+		IndexHints: []string{"idx_products_brand"},
+		Timeout:    time.Second,
+	}}).(*Executor)
+
+	assert.Same(t, e, e.withQueryOptions(context.Background()))
+
+	bound := e.withQueryOptions(WithQueryOptions(context.Background(), QueryOptions{Timeout: 5 * time.Second}))
+	assert.Equal(t, []string{"idx_products_brand"}, bound.queryOptions.IndexHints)
+	assert.Equal(t, 5*time.Second, bound.queryOptions.Timeout)
+	assert.Equal(t, time.Second, e.queryOptions.Timeout)
+
+	assert.Equal(t, "SET LOCAL statement_timeout = 1500", statementTimeoutSQL(1500*time.Millisecond))
+	assert.Equal(t, "SET LOCAL statement_timeout = 1", statementTimeoutSQL(time.Microsecond))
+}
+
+func TestGORMExecutor_Timeout(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	e := NewExecutorWithOptions(db.Model(&Product{}), &Options{ExecutorOptions: opts})
+
+	q := &query.Query{Filter: query.Eq("brand", "Anker"), PageSize: 10}
+	var products []Product
+	ctx := WithQueryOptions(context.Background(), QueryOptions{Timeout: 5 * time.Second})
+	result, err := e.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	assert.Len(t, products, 3)
+	assert.Equal(t, int64(3), result.TotalItems)
+
+	ctx = WithQueryOptions(context.Background(), QueryOptions{Timeout: time.Nanosecond})
+	_, err = e.Execute(ctx, q, "", &products)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,53 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_SeededRandomOrder(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	// pages collects the IDs of every page of a query
+	pages := func(input string) []uint {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var ids []uint
+		cursor := ""
+		for {
+			var products []Product
+			result, err := executor.Execute(ctx, q, cursor, &products)
+			require.NoError(t, err)
+			for _, p := range products {
+				ids = append(ids, p.ID)
+			}
+			if result.NextPageCursor == "" {
+				return ids
+			}
+			cursor = result.NextPageCursor
+		}
+	}
+
+	first := pages("sort_order = random random_seed = 7 page_size = 3")
+	assert.Equal(t, first, pages("sort_order = random random_seed = 7 page_size = 3"))
+	assert.ElementsMatch(t, []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, first)
+	assert.NotEqual(t, []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, first)
+	assert.NotEqual(t, first, pages("sort_order = random random_seed = 8 page_size = 3"))
+
+	// Without a seed, cursors carry the first page's seed
+	assert.ElementsMatch(t, first, pages("sort_order = random page_size = 3"))
+}
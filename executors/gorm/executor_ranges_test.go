@@ -0,0 +1,113 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestGORMExecutor_RangeLists(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+	ctx := context.Background()
+
+	newExecutor := func(strategy query.RangeStrategy) *Executor {
+		opts := query.DefaultExecutorOptions()
+		opts.DefaultSortField = "id"
+		opts.RangeStrategy = strategy
+		return NewExecutor(db.Model(&Product{}), opts).(*Executor)
+	}
+	ranges := "((price >= 10 AND price <= 30) OR (price >= 40 AND price <= 70))"
+
+	t.Run("auto plans closed ranges as union all", func(t *testing.T) {
+		filter, err := parser.ParseFilter(ranges + " AND category = electronics")
+		require.NoError(t, err)
+		executor := newExecutor(query.RangeStrategyAuto)
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			tx, err = executor.applyFilter(tx.Model(&Product{}), filter, nil)
+			require.NoError(t, err)
+			return tx.Find(&[]Product{})
+		})
+		assert.Contains(t, sql, "UNION ALL")
+		assert.Contains(t, sql, "AS `products`")
+		assert.Contains(t, sql, "category = \"electronics\"")
+	})
+
+	t.Run("or strategy merges ranges", func(t *testing.T) {
+		filter, err := parser.ParseFilter("(price >= 10 AND price <= 30) OR (price >= 20 AND price <= 50)")
+		require.NoError(t, err)
+		executor := newExecutor(query.RangeStrategyOr)
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			tx, err = executor.applyFilter(tx.Model(&Product{}), filter, nil)
+			require.NoError(t, err)
+			return tx.Find(&[]Product{})
+		})
+		assert.NotContains(t, sql, "UNION ALL")
+		assert.Contains(t, sql, "(price >= 10) AND (price <= 50)")
+	})
+
+	t.Run("strategies return the same rows", func(t *testing.T) {
+		for _, input := range []string{
+			ranges,
+			ranges + " AND featured = false",
+			ranges + " sort_by = price sort_order = desc",
+		} {
+			p, err := parser.NewParser(input)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var expected, actual []Product
+			expectedResult, err := newExecutor(query.RangeStrategyOr).Execute(ctx, q, "", &expected)
+			require.NoError(t, err)
+			actualResult, err := newExecutor(query.RangeStrategyUnionAll).Execute(ctx, q, "", &actual)
+			require.NoError(t, err)
+			assert.NotEmpty(t, actual, input)
+			assert.Equal(t, expected, actual, input)
+			assert.Equal(t, expectedResult.TotalItems, actualResult.TotalItems, input)
+
+			count, err := newExecutor(query.RangeStrategyUnionAll).Count(ctx, q)
+			require.NoError(t, err)
+			assert.Equal(t, expectedResult.TotalItems, count, input)
+		}
+	})
+
+	t.Run("cursor pagination over the union", func(t *testing.T) {
+		p, err := parser.NewParser(ranges + " sort_by = price page_size = 2")
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		executor := newExecutor(query.RangeStrategyUnionAll)
+
+		var prices []float64
+		cursorParam := ""
+		for {
+			var page []Product
+			result, err := executor.Execute(ctx, q, cursorParam, &page)
+			require.NoError(t, err)
+			for _, product := range page {
+				prices = append(prices, product.Price)
+			}
+			if result.NextPageCursor == "" {
+				assert.Equal(t, int(result.TotalItems), len(prices))
+				break
+			}
+			cursorParam = result.NextPageCursor
+		}
+		assert.IsIncreasing(t, prices)
+	})
+
+	t.Run("field restrictions apply to ranges", func(t *testing.T) {
+		filter, err := parser.ParseFilter(ranges)
+		require.NoError(t, err)
+		executor := newExecutor(query.RangeStrategyUnionAll)
+		executor.options.AllowedFields = []string{"id"}
+		_, err = executor.applyFilter(db.Model(&Product{}), filter, nil)
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	})
+}
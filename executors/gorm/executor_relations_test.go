@@ -0,0 +1,161 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type Author struct {
+	ID      uint `gorm:"primaryKey"`
+	Name    string
+	Country string
+}
+
+type Review struct {
+	ID     uint `gorm:"primaryKey"`
+	BookID uint
+	Rating int
+}
+
+type Genre struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+type Book struct {
+	ID       uint `gorm:"primaryKey"`
+	Name     string
+	AuthorID uint
+	Author   Author
+	Reviews  []Review
+	Genres   []Genre `gorm:"many2many:book_genres"`
+}
+
+func setupRelationDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&Author{}, &Review{}, &Genre{}, &Book{}))
+	for _, table := range []string{"book_genres", "reviews", "books", "genres", "authors"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	alice := Author{ID: 1, Name: "Alice", Country: "UK"}
+	bob := Author{ID: 2, Name: "Bob", Country: "US"}
+	fantasy := Genre{ID: 1, Name: "fantasy"}
+	crime := Genre{ID: 2, Name: "crime"}
+	books := []Book{
+		{ID: 1, Name: "Dragons", Author: alice, Genres: []Genre{fantasy}, Reviews: []Review{{ID: 1, Rating: 5}, {ID: 2, Rating: 2}}},
+		{ID: 2, Name: "Heist", Author: bob, Genres: []Genre{crime}, Reviews: []Review{{ID: 3, Rating: 3}}},
+		{ID: 3, Name: "Wizard Heist", Author: alice, Genres: []Genre{fantasy, crime}},
+	}
+	require.NoError(t, db.Create(&books).Error)
+	return db
+}
+
+func TestGORMExecutor_RelationFields(t *testing.T) {
+	db := setupRelationDB(t)
+	ctx := context.Background()
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.RelationMap = map[string]string{"author": "Author", "reviews": "Reviews", "genre": "Genres"}
+	executor := NewExecutor(db.Model(&Book{}), opts)
+
+	find := func(t *testing.T, input string) []string {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var books []Book
+		_, err = executor.Execute(ctx, q, "", &books)
+		if err != nil {
+			require.ErrorIs(t, err, query.ErrNoRecordsFound)
+		}
+		names := make([]string, len(books))
+		for i, book := range books {
+			names[i] = book.Name
+		}
+		return names
+	}
+
+	t.Run("belongs to", func(t *testing.T) {
+		assert.Equal(t, []string{"Dragons", "Wizard Heist"}, find(t, `author.name = "Alice"`))
+		assert.Equal(t, []string{"Heist"}, find(t, `author.country = US AND name CONTAINS Heist`))
+	})
+
+	t.Run("has many", func(t *testing.T) {
+		assert.Equal(t, []string{"Dragons"}, find(t, `reviews.rating >= 4`))
+		// Books are not repeated for several matching reviews
+		assert.Equal(t, []string{"Dragons", "Heist"}, find(t, `reviews.rating > 1`))
+	})
+
+	t.Run("many to many", func(t *testing.T) {
+		assert.Equal(t, []string{"Heist", "Wizard Heist"}, find(t, `genre.name = crime`))
+		assert.Equal(t, []string{"Dragons", "Wizard Heist"}, find(t, `genre.name IN [fantasy] sort_by = id`))
+	})
+
+	t.Run("with other conditions and preloads", func(t *testing.T) {
+		preloading := NewExecutor(db.Model(&Book{}).Preload("Author"), opts)
+		q, err := parser.NewParser(`author.name = "Alice" OR genre.name = crime sort_by = name sort_order = desc`)
+		require.NoError(t, err)
+		parsed, err := q.Parse()
+		require.NoError(t, err)
+
+		var books []Book
+		result, err := preloading.Execute(ctx, parsed, "", &books)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.TotalItems)
+		require.Len(t, books, 3)
+		assert.Equal(t, "Wizard Heist", books[0].Name)
+		assert.Equal(t, "Alice", books[0].Author.Name)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		filter, err := parser.ParseFilter(`author.name = Bob`)
+		require.NoError(t, err)
+		count, err := executor.Count(ctx, &query.Query{Filter: filter})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("allowed fields", func(t *testing.T) {
+		restricted := *opts
+		restricted.AllowedFields = []string{"name", "author.name"}
+		restrictedExec := NewExecutor(db.Model(&Book{}), &restricted)
+
+		filter, err := parser.ParseFilter(`author.name = Alice`)
+		require.NoError(t, err)
+		_, err = restrictedExec.Count(ctx, &query.Query{Filter: filter})
+		assert.NoError(t, err)
+
+		filter, err = parser.ParseFilter(`author.country = UK`)
+		require.NoError(t, err)
+		_, err = restrictedExec.Count(ctx, &query.Query{Filter: filter})
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	})
+
+	t.Run("invalid relation fields", func(t *testing.T) {
+		for input, target := range map[string]error{
+			`author.name.first = x`: query.ErrInvalidFieldName,
+			`missing.name = x`:      query.ErrInvalidFieldName,
+		} {
+			filter, err := parser.ParseFilter(input)
+			require.NoError(t, err)
+			_, err = executor.Count(ctx, &query.Query{Filter: filter})
+			assert.ErrorIs(t, err, target, input)
+		}
+
+		unknown := *opts
+		unknown.RelationMap = map[string]string{"publisher": "Publisher"}
+		filter, err := parser.ParseFilter(`publisher.name = x`)
+		require.NoError(t, err)
+		_, err = NewExecutor(db.Model(&Book{}), &unknown).Count(ctx, &query.Query{Filter: filter})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+}
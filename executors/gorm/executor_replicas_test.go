@@ -0,0 +1,142 @@
+package gorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openNamedDB opens an in-memory database of its own with products of brand
+func openNamedDB(t *testing.T, name, brand string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", name)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&Product{}))
+	require.NoError(t, db.Exec("DELETE FROM products").Error)
+	require.NoError(t, db.Create(&Product{ID: 1, Name: name, Brand: brand}).Error)
+	return db
+}
+
+func TestGORMExecutor_Replicas(t *testing.T) {
+	primary := openNamedDB(t, "primary", "primary")
+	first := openNamedDB(t, "replica1", "replica")
+	second := openNamedDB(t, "replica2", "replica")
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	e := NewExecutorWithOptions(primary.Model(&Product{}), &Options{
+		ExecutorOptions:     opts,
+		Replicas:            []*gorm.DB{first.Model(&Product{}), second.Model(&Product{})},
+		HealthCheckInterval: -1,
+	})
+	defer e.Close()
+	ctx := context.Background()
+
+	names := func(ctx context.Context) []string {
+		var products []Product
+		_, err := e.Execute(ctx, &query.Query{PageSize: 10}, "", &products)
+		require.NoError(t, err)
+		var names []string
+		for _, p := range products {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	t.Run("reads alternate between replicas", func(t *testing.T) {
+		seen := map[string]bool{}
+		for i := 0; i < 4; i++ {
+			seen[names(ctx)[0]] = true
+		}
+		assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, seen)
+
+		count, err := e.Count(ctx, &query.Query{Filter: query.Eq("brand", "replica")})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		var products []Product
+		results, err := executor.ExecuteBatch(ctx, e, []*query.Query{{PageSize: 10}}, []interface{}{&products})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		assert.Equal(t, "replica", products[0].Brand)
+	})
+
+	t.Run("primary policy", func(t *testing.T) {
+		primaryCtx := WithQueryOptions(ctx, QueryOptions{ReadPolicy: ReadPolicyPrimary})
+		assert.Equal(t, []string{"primary"}, names(primaryCtx))
+
+		count, err := e.Count(primaryCtx, &query.Query{Filter: query.Eq("brand", "primary")})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("writes use the primary", func(t *testing.T) {
+		writeOpts := query.DefaultExecutorOptions()
+		writeOpts.AllowWrites = true
+		writer := NewExecutorWithOptions(primary.Model(&Product{}), &Options{
+			ExecutorOptions:     writeOpts,
+			Replicas:            []*gorm.DB{first.Model(&Product{})},
+			HealthCheckInterval: -1,
+		})
+		defer writer.Close()
+		affected, err := writer.(executor.Writer).UpdateWhere(ctx, &query.Query{Filter: query.Eq("id", 1)}, map[string]interface{}{"stock": 5})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+
+		var stock int
+		require.NoError(t, primary.Raw("SELECT stock FROM products WHERE id = 1").Scan(&stock).Error)
+		assert.Equal(t, 5, stock)
+		require.NoError(t, first.Raw("SELECT stock FROM products WHERE id = 1").Scan(&stock).Error)
+		assert.Equal(t, 0, stock)
+	})
+}
+
+func TestGORMExecutor_ReplicaFailover(t *testing.T) {
+	primary := openNamedDB(t, "failover_primary", "primary")
+	down := openNamedDB(t, "failover_replica", "replica")
+	require.NoError(t, down.Callback().Query().Before("gorm:query").Register("test:bad_conn", func(db *gorm.DB) {
+		_ = db.AddError(driver.ErrBadConn)
+	}))
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	e := NewExecutorWithOptions(primary.Model(&Product{}), &Options{
+		ExecutorOptions:     opts,
+		Replicas:            []*gorm.DB{down.Model(&Product{})},
+		HealthCheckInterval: -1,
+	}).(*Executor)
+	defer e.Close()
+
+	// The unreachable replica fails over to the primary and is skipped after
+	var products []Product
+	_, err := e.Execute(context.Background(), &query.Query{PageSize: 10}, "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, "primary", products[0].Brand)
+	assert.Nil(t, e.replicas.pick())
+
+	// A successful ping brings it back
+	e.replicas.check(time.Second)
+	assert.NotNil(t, e.replicas.pick())
+
+	sqlDB, err := down.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	e.replicas.check(time.Second)
+	assert.Nil(t, e.replicas.pick())
+
+	// Statement errors do not fail over
+	assert.False(t, isConnectionError(query.ErrInvalidQuery))
+	assert.False(t, isConnectionError(context.DeadlineExceeded))
+	assert.True(t, isConnectionError(query.NewExecutionError("execute query", driver.ErrBadConn)))
+}
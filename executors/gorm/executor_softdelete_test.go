@@ -0,0 +1,114 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type Note struct {
+	ID        uint `gorm:"primaryKey"`
+	Title     string
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func setupSoftDeleteDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&Note{}))
+	db.Exec("DELETE FROM notes")
+
+	notes := []Note{{ID: 1, Title: "draft"}, {ID: 2, Title: "draft"}, {ID: 3, Title: "final"}}
+	require.NoError(t, db.Create(&notes).Error)
+	require.NoError(t, db.Delete(&Note{}, 2).Error)
+	return db
+}
+
+func TestGORMExecutor_SoftDelete(t *testing.T) {
+	db := setupSoftDeleteDB(t)
+	ctx := context.Background()
+
+	parse := func(t *testing.T, input string) *query.Query {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		return q
+	}
+	ids := func(notes []Note) []uint {
+		out := make([]uint, len(notes))
+		for i, n := range notes {
+			out[i] = n.ID
+		}
+		return out
+	}
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+
+	t.Run("deleted rows are excluded from rows and totals", func(t *testing.T) {
+		// Without a model on the DB, the count is scoped by the destination's model
+		executor := NewExecutor(db.Table("notes"), opts)
+		var notes []Note
+		result, err := executor.Execute(ctx, parse(t, `title = draft`), "", &notes)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{1}, ids(notes))
+		assert.Equal(t, int64(1), result.TotalItems)
+
+		count, err := NewExecutor(db.Model(&Note{}), opts).Count(ctx, parse(t, `title = draft`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("include_deleted requires permission", func(t *testing.T) {
+		executor := NewExecutor(db.Model(&Note{}), opts)
+		var notes []Note
+		_, err := executor.Execute(ctx, parse(t, `title = draft include_deleted = true`), "", &notes)
+		assert.ErrorIs(t, err, query.ErrIncludeDeletedNotAllowed)
+
+		_, err = executor.Count(ctx, parse(t, `include_deleted = true`))
+		assert.ErrorIs(t, err, query.ErrIncludeDeletedNotAllowed)
+	})
+
+	t.Run("include_deleted when allowed", func(t *testing.T) {
+		allowed := *opts
+		allowed.AllowIncludeDeleted = true
+		executor := NewExecutor(db.Model(&Note{}), &allowed)
+
+		var notes []Note
+		result, err := executor.Execute(ctx, parse(t, `title = draft include_deleted = true`), "", &notes)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{1, 2}, ids(notes))
+		assert.Equal(t, int64(2), result.TotalItems)
+
+		count, err := executor.Count(ctx, parse(t, `include_deleted = true`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		// Without the flag, deleted rows stay hidden
+		count, err = executor.Count(ctx, parse(t, `title = draft`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("IncludeDeleted option", func(t *testing.T) {
+		always := *opts
+		always.IncludeDeleted = true
+		executor := NewExecutor(db.Model(&Note{}), &always)
+
+		var notes []Note
+		result, err := executor.Execute(ctx, parse(t, `page_size = 2`), "", &notes)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{1, 2}, ids(notes))
+		assert.Equal(t, int64(3), result.TotalItems)
+
+		var next []Note
+		_, err = executor.Execute(ctx, parse(t, `page_size = 2`), result.NextPageCursor, &next)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{3}, ids(next))
+	})
+}
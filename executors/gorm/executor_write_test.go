@@ -0,0 +1,101 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_Writes(t *testing.T) {
+	ctx := context.Background()
+	parse := func(t *testing.T, input string) *query.Query {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		return q
+	}
+	newExec := func(t *testing.T) executor.Executor {
+		db := setupTestDB(t)
+		seedTestData(t, db)
+		opts := query.DefaultExecutorOptions()
+		opts.DefaultSortField = "id"
+		opts.AllowWrites = true
+		return NewExecutor(db.Model(&Product{}), opts)
+	}
+
+	t.Run("update matching rows", func(t *testing.T) {
+		exec := newExec(t)
+		updated, err := executor.UpdateWhere(ctx, exec, parse(t, "category = electronics AND price < 50"),
+			map[string]interface{}{"featured": true, "stock": 0})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated) // Wireless Mouse, Bluetooth Speaker
+
+		count, err := exec.Count(ctx, parse(t, "featured = true AND stock = 0"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("delete matching rows", func(t *testing.T) {
+		exec := newExec(t)
+		deleted, err := executor.DeleteWhere(ctx, exec, parse(t, "category = electronics"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), deleted)
+
+		count, err := exec.Count(ctx, &query.Query{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+	})
+
+	t.Run("range lists", func(t *testing.T) {
+		exec := newExec(t)
+		deleted, err := executor.DeleteWhere(ctx, exec, parse(t, "(price >= 10 AND price < 30) OR (price >= 60 AND price < 90)"))
+		require.NoError(t, err)
+
+		remaining, err := exec.Count(ctx, parse(t, "(price >= 10 AND price < 30) OR (price >= 60 AND price < 90)"))
+		require.NoError(t, err)
+		assert.Zero(t, remaining)
+		assert.Positive(t, deleted)
+	})
+
+	t.Run("soft deletes", func(t *testing.T) {
+		db := setupSoftDeleteDB(t)
+		opts := query.DefaultExecutorOptions()
+		opts.DefaultSortField = "id"
+		opts.AllowWrites = true
+		exec := NewExecutor(db.Model(&Note{}), opts)
+
+		deleted, err := executor.DeleteWhere(ctx, exec, parse(t, "title = draft"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted, "note 2 is already deleted")
+
+		var total int64
+		require.NoError(t, db.Unscoped().Model(&Note{}).Count(&total).Error)
+		assert.Equal(t, int64(3), total, "rows are kept")
+	})
+
+	t.Run("rejected writes", func(t *testing.T) {
+		exec := newExec(t)
+		_, err := executor.DeleteWhere(ctx, exec, &query.Query{})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		_, err = executor.UpdateWhere(ctx, exec, parse(t, "brand = Sony"), nil)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		_, err = executor.UpdateWhere(ctx, exec, parse(t, "brand = Sony"), map[string]interface{}{"bad-column": 1})
+		assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+
+		opts := query.DefaultExecutorOptions()
+		opts.DefaultSortField = "id"
+		readOnly := NewExecutor(setupTestDB(t).Model(&Product{}), opts)
+		_, err = executor.DeleteWhere(ctx, readOnly, parse(t, "brand = Sony"))
+		assert.ErrorIs(t, err, query.ErrWritesNotAllowed)
+
+		count, err := exec.Count(ctx, &query.Query{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), count)
+	})
+}
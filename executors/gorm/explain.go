@@ -0,0 +1,89 @@
+package gorm
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// planIndexPattern finds index names in plans, e.g. "SEARCH users USING INDEX
+// idx_users_email (email=?)" on SQLite or "Index Scan using users_pkey on users"
+// on PostgreSQL
+var planIndexPattern = regexp.MustCompile(`(?i)(?:using (?:covering )?index|index (?:only )?scan using) (\w+)`)
+
+// explain returns the SQL tx runs to fill dest and the database plan for it.
+// The plan runs on the connection of tx, so temporary tables are visible;
+// failing to get it is reported in PlanError
+func (e *Executor) explain(tx *gorm.DB, dest interface{}) *query.Explain {
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Find(dest).Statement
+	explain := &query.Explain{Backend: e.Name(), Statement: stmt.SQL.String(), Args: stmt.Vars}
+
+	var prefix string
+	switch e.dialectName() {
+	case dialectSQLite:
+		prefix = "EXPLAIN QUERY PLAN "
+	case dialectPostgres, dialectMySQL:
+		prefix = "EXPLAIN "
+	default:
+		explain.PlanError = fmt.Sprintf("explain is not supported on %q", e.dialectName())
+		return explain
+	}
+	rows, err := stmt.ConnPool.QueryContext(stmt.Context, prefix+explain.Statement, explain.Args...)
+	if err != nil {
+		explain.PlanError = err.Error()
+		return explain
+	}
+	defer rows.Close()
+	plan, err := readPlan(rows)
+	if err != nil {
+		explain.PlanError = err.Error()
+		return explain
+	}
+	explain.Plan = plan
+	for _, m := range planIndexPattern.FindAllStringSubmatch(plan, -1) {
+		explain.Indexes = append(explain.Indexes, m[1])
+	}
+	return explain
+}
+
+// readPlan joins the rows of an EXPLAIN, one line per row. Only the detail
+// column of SQLite plans is kept; other columns are separated by spaces
+func readPlan(rows *sql.Rows) (string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	detail := -1
+	for i, column := range columns {
+		if column == "detail" {
+			detail = i
+		}
+	}
+	var lines []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return "", err
+		}
+		if detail >= 0 {
+			lines = append(lines, values[detail].String)
+			continue
+		}
+		var fields []string
+		for _, v := range values {
+			if v.Valid {
+				fields = append(fields, v.String)
+			}
+		}
+		lines = append(lines, strings.Join(fields, " "))
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
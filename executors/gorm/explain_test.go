@@ -0,0 +1,43 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGORMExecutor_Explain(t *testing.T) {
+	db := setupTestDB(t)
+	seedTestData(t, db)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(db.Model(&Product{}), opts)
+	ctx := context.Background()
+
+	q := &query.Query{Filter: query.Eq("brand", "Sony"), ExplainRequested: true}
+	var products []Product
+	result, err := executor.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	require.NotNil(t, result.Explain)
+
+	assert.Equal(t, "GORM", result.Explain.Backend)
+	assert.Contains(t, result.Explain.Statement, "SELECT * FROM `products` WHERE brand = ?")
+	assert.Contains(t, result.Explain.Args, "Sony")
+	assert.Empty(t, result.Explain.PlanError)
+	assert.Contains(t, result.Explain.Plan, "idx_products_brand")
+	assert.Equal(t, []string{"idx_products_brand"}, result.Explain.Indexes)
+	assert.Positive(t, int64(result.ExecutionTime))
+
+	// The explained query does not change the results
+	q.ExplainRequested = false
+	var plain []Product
+	result, err = executor.Execute(ctx, q, "", &plain)
+	require.NoError(t, err)
+	assert.Nil(t, result.Explain)
+	assert.Equal(t, products, plain)
+}
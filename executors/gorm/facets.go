@@ -0,0 +1,226 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// Facets counts the values or histogram bins of each spec's field with
+// GROUP BY, or the rows in each of its ranges with SUM(CASE ...), among the
+// rows matching the query's filter. Each spec runs one statement
+func (e *Executor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	e = e.withCurrentOptions().withQueryOptions(ctx)
+	var results []query.FacetResult
+	err := e.read(func(e *Executor) error {
+		var err error
+		results, err = e.facets(ctx, q, specs)
+		return err
+	})
+	return results, query.WrapError(e.Name(), "facets", err)
+}
+
+func (e *Executor) facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return nil, err
+	}
+	specs, err = e.options.ValidateFacets(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+	q = e.options.ScopedQuery(q)
+
+	results := make([]query.FacetResult, len(specs))
+	err = e.withTimeout(ctx, func(ctx context.Context, bound *Executor) error {
+		return bound.withInTables(ctx, q.Filter, func(bound *Executor) error {
+			for i, spec := range specs {
+				buckets, err := bound.facet(ctx, q, spec)
+				if err != nil {
+					return err
+				}
+				results[i] = query.FacetResult{Field: spec.Field, Buckets: buckets}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// facet counts the buckets of one spec among the rows matching q
+func (e *Executor) facet(ctx context.Context, q *query.Query, spec query.FacetSpec) ([]query.FacetBucket, error) {
+	column, err := e.column(spec.Field)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.applySoftDelete(e.db.WithContext(ctx), q, nil)
+	if err != nil {
+		return nil, err
+	}
+	tx, err = e.applyFilter(tx, q.Filter, nil)
+	if err != nil {
+		return nil, err
+	}
+	tx = e.applyQueryHints(tx)
+	switch {
+	case len(spec.Ranges) > 0:
+		return e.rangeFacet(tx, spec, column)
+	case spec.IsHistogram():
+		return e.histogramFacet(tx, spec, column)
+	}
+	return e.valueFacet(tx, spec, column)
+}
+
+// valueFacet runs SELECT column, COUNT(*) ... GROUP BY column
+func (e *Executor) valueFacet(tx *gorm.DB, spec query.FacetSpec, column string) ([]query.FacetBucket, error) {
+	tx = tx.Select(fmt.Sprintf("%s AS facet_value, COUNT(*) AS facet_count", column)).
+		Group(column).
+		Order("facet_count DESC, facet_value")
+	if spec.Limit > 0 {
+		tx = tx.Limit(spec.Limit)
+	}
+	rows, err := tx.Rows()
+	if err != nil {
+		return nil, query.NewExecutionError("count facet values", err)
+	}
+	defer rows.Close()
+
+	var buckets []query.FacetBucket
+	for rows.Next() {
+		var bucket query.FacetBucket
+		if err := rows.Scan(&bucket.Value, &bucket.Count); err != nil {
+			return nil, query.NewExecutionError("scan facet values", err)
+		}
+		if b, ok := bucket.Value.([]byte); ok {
+			bucket.Value = string(b)
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, query.NewExecutionError("scan facet values", err)
+	}
+	// Ties are ordered by value like the other executors
+	return query.SortFacetBuckets(buckets, spec.Limit), nil
+}
+
+// rangeFacet runs SELECT SUM(CASE WHEN column >= ? AND column < ? THEN 1 ELSE 0 END), ...
+// with one sum per range
+func (e *Executor) rangeFacet(tx *gorm.DB, spec query.FacetSpec, column string) ([]query.FacetBucket, error) {
+	sums := make([]string, len(spec.Ranges))
+	var args []interface{}
+	for i, r := range spec.Ranges {
+		var conditions []string
+		for _, bound := range []struct {
+			op    string
+			value interface{}
+		}{{">=", r.From}, {"<", r.To}} {
+			if bound.value == nil {
+				continue
+			}
+			value, err := e.convertValue(spec.Field, bound.value)
+			if err != nil {
+				return nil, query.NewFieldError(spec.Field, err)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s ?", column, bound.op))
+			args = append(args, value)
+		}
+		sums[i] = fmt.Sprintf("SUM(CASE WHEN %s THEN 1 ELSE 0 END)", strings.Join(conditions, " AND "))
+	}
+
+	counts := make([]sql.NullInt64, len(spec.Ranges))
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := tx.Select(strings.Join(sums, ", "), args...).Row().Scan(dest...); err != nil {
+		return nil, query.NewExecutionError("count facet ranges", err)
+	}
+
+	buckets := make([]query.FacetBucket, len(spec.Ranges))
+	for i, r := range spec.Ranges {
+		buckets[i] = query.FacetBucket{Value: r.Label, Count: counts[i].Int64}
+	}
+	return buckets, nil
+}
+
+// histogramFacet runs SELECT bin, COUNT(*) ... GROUP BY bin, where bin is
+// FLOOR(column / interval) * interval or the truncated date
+func (e *Executor) histogramFacet(tx *gorm.DB, spec query.FacetSpec, column string) ([]query.FacetBucket, error) {
+	bin := e.dateTruncExpression(column, spec.DateInterval)
+	if spec.DateInterval == "" {
+		// The interval is inlined as a decimal so integer columns are not
+		// divided as integers
+		interval := strconv.FormatFloat(spec.Interval, 'f', -1, 64)
+		if !strings.Contains(interval, ".") {
+			interval += ".0"
+		}
+		bin = fmt.Sprintf("%s * %s", e.floorExpression(fmt.Sprintf("%s / %s", column, interval)), interval)
+	}
+	rows, err := tx.Select(fmt.Sprintf("%s AS facet_value, COUNT(*) AS facet_count", bin)).
+		Where(fmt.Sprintf("%s IS NOT NULL", column)).
+		Group(bin).
+		Rows()
+	if err != nil {
+		return nil, query.NewExecutionError("count histogram", err)
+	}
+	defer rows.Close()
+
+	var buckets []query.FacetBucket
+	for rows.Next() {
+		var value interface{}
+		var count int64
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, query.NewExecutionError("scan histogram", err)
+		}
+		start, err := histogramStart(value, spec)
+		if err != nil {
+			return nil, query.NewExecutionError("scan histogram", err)
+		}
+		if start != nil {
+			buckets = append(buckets, query.FacetBucket{Value: start, Count: count})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, query.NewExecutionError("scan histogram", err)
+	}
+	return query.SortHistogramBuckets(buckets), nil
+}
+
+// histogramStart converts a scanned bin to a float64 or a UTC time. MySQL and
+// SQLite return formatted dates and some drivers return numbers as text
+func histogramStart(value interface{}, spec query.FacetSpec) (interface{}, error) {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return v.UTC(), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		if spec.DateInterval != "" {
+			return time.ParseInLocation("2006-01-02 15:04:05", v, time.UTC)
+		}
+		return strconv.ParseFloat(v, 64)
+	}
+	return nil, fmt.Errorf("unexpected histogram bin %T", value)
+}
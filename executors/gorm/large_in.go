@@ -0,0 +1,176 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// largeInInsertBatchSize is the number of values inserted per statement when
+// filling temporary tables (kept below SQLite's historical 999 parameter limit)
+const largeInInsertBatchSize = 500
+
+// isLargeIn reports whether a comparison is an IN/NOT IN whose value list exceeds LargeInThreshold.
+// Conditions on array elements keep their placeholder lists
+func (e *Executor) isLargeIn(n *query.ComparisonNode) bool {
+	if e.options.LargeInThreshold <= 0 || n.Modifier != query.ArrayModifierNone {
+		return false
+	}
+	if n.Operator != query.OpIn && n.Operator != query.OpNotIn {
+		return false
+	}
+	arr, ok := n.Value.(query.ArrayValue)
+	return ok && len(arr) > e.options.LargeInThreshold
+}
+
+// collectLargeIns returns all large IN/NOT IN comparisons in the filter
+func (e *Executor) collectLargeIns(node query.Node) []*query.ComparisonNode {
+	switch n := node.(type) {
+	case *query.BinaryOpNode:
+		return append(e.collectLargeIns(n.Left), e.collectLargeIns(n.Right)...)
+	case *query.ComparisonNode:
+		if e.isLargeIn(n) {
+			return []*query.ComparisonNode{n}
+		}
+	}
+	return nil
+}
+
+// withInTables calls fn with an executor that can reference large IN lists.
+// On dialects other than PostgreSQL, large lists are inserted into temporary tables
+// inside a transaction (so every statement uses the same connection) and joined
+// with `field IN (SELECT value FROM table)`. PostgreSQL uses unnest on a single array
+// parameter instead and needs no setup. Without large lists, fn is called with e.
+func (e *Executor) withInTables(ctx context.Context, filter query.Node, fn func(*Executor) error) error {
+	if filter == nil || e.dialectName() == dialectPostgres {
+		return fn(e)
+	}
+	nodes := e.collectLargeIns(filter)
+	if len(nodes) == 0 {
+		return fn(e)
+	}
+
+	return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		bound := *e
+		bound.db = tx
+		bound.inTables = make(map[*query.ComparisonNode]string, len(nodes))
+
+		var created []string
+		defer func() {
+			for _, table := range created {
+				tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", table))
+			}
+		}()
+
+		for i, n := range nodes {
+			values, err := e.convertArrayValue(n.Field, n.Value)
+			if err != nil {
+				return err
+			}
+			table := fmt.Sprintf("goquery_in_%d", i)
+			if err := tx.Exec(fmt.Sprintf("CREATE TEMPORARY TABLE %s (value %s)", table, sqlColumnType(values))).Error; err != nil {
+				return query.NewExecutionError("create temporary table", err)
+			}
+			created = append(created, table)
+			if err := insertInValues(tx, table, values); err != nil {
+				return query.NewExecutionError("fill temporary table", err)
+			}
+			bound.inTables[n] = table
+		}
+
+		return fn(&bound)
+	})
+}
+
+// insertInValues inserts values into a single-column temporary table in batches
+func insertInValues(tx *gorm.DB, table string, values []interface{}) error {
+	for start := 0; start < len(values); start += largeInInsertBatchSize {
+		end := start + largeInInsertBatchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		placeholders := make([]string, end-start)
+		for i := range placeholders {
+			placeholders[i] = "(?)"
+		}
+		stmt := fmt.Sprintf("INSERT INTO %s (value) VALUES %s", table, strings.Join(placeholders, ", "))
+		if err := tx.Exec(stmt, values[start:end]...).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildLargeInClause builds an IN/NOT IN clause on column for large value lists
+// keyword is "IN" or "NOT IN". Returns ok=false when the regular placeholder list should be used.
+func (e *Executor) buildLargeInClause(n *query.ComparisonNode, field, column string, keyword string) (string, []interface{}, bool, error) {
+	if table, ok := e.inTables[n]; ok {
+		return fmt.Sprintf("%s %s (SELECT value FROM %s)", column, keyword, table), []interface{}{}, true, nil
+	}
+	if !e.isLargeIn(n) || e.dialectName() != dialectPostgres {
+		return "", nil, false, nil
+	}
+	values, err := e.convertArrayValue(field, n.Value)
+	if err != nil {
+		return "", nil, false, err
+	}
+	literal, arrayType := postgresArrayLiteral(values)
+	return fmt.Sprintf("%s %s (SELECT unnest(CAST(? AS %s)))", column, keyword, arrayType), []interface{}{literal}, true, nil
+}
+
+// sqlColumnType infers a portable column type for the given values
+func sqlColumnType(values []interface{}) string {
+	if len(values) == 0 {
+		return "TEXT"
+	}
+	switch values[0].(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "BIGINT"
+	case float32, float64:
+		return "DOUBLE PRECISION"
+	case bool:
+		return "BOOLEAN"
+	case time.Time, query.DateTimeValue:
+		return "TIMESTAMP"
+	default:
+		return "TEXT"
+	}
+}
+
+// postgresArrayLiteral formats values as a PostgreSQL array literal (e.g. {1,2,3})
+// and returns it together with the array type to cast it to
+func postgresArrayLiteral(values []interface{}) (string, string) {
+	arrayType := "text[]"
+	switch sqlColumnType(values) {
+	case "BIGINT":
+		arrayType = "bigint[]"
+	case "DOUBLE PRECISION":
+		arrayType = "double precision[]"
+	case "BOOLEAN":
+		arrayType = "boolean[]"
+	case "TIMESTAMP":
+		arrayType = "timestamp[]"
+	}
+
+	elems := make([]string, len(values))
+	for i, v := range values {
+		var str string
+		switch tv := v.(type) {
+		case time.Time:
+			str = tv.Format(time.RFC3339Nano)
+		case query.DateTimeValue:
+			str = time.Time(tv).Format(time.RFC3339Nano)
+		default:
+			str = fmt.Sprintf("%v", v)
+		}
+		if arrayType == "text[]" || arrayType == "timestamp[]" {
+			str = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(str) + `"`
+		}
+		elems[i] = str
+	}
+	return "{" + strings.Join(elems, ",") + "}", arrayType
+}
@@ -0,0 +1,148 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueryOptions bound the reads of Execute, Count and Facets. Options sets
+// them for an executor and WithQueryOptions overrides them for the calls made
+// with a context
+type QueryOptions struct {
+	// IndexHints lists the indexes MySQL may choose from (USE INDEX), for
+	// filters its optimizer plans badly. Range unions (RangeStrategyUnionAll)
+	// and other dialects ignore them
+	IndexHints []string
+
+	// Timeout bounds the statements of each call, so pathological searches
+	// cannot hold locks or saturate the database. PostgreSQL runs them in a
+	// transaction with SET LOCAL statement_timeout, MySQL adds a
+	// MAX_EXECUTION_TIME optimizer hint to its SELECTs and other dialects
+	// cancel the call's context. 0 does not bound them
+	Timeout time.Duration
+
+	// ReadPolicy chooses between the primary and Options.Replicas. Without
+	// replicas every read uses the primary
+	ReadPolicy ReadPolicy
+}
+
+type queryOptionsKey struct{}
+
+// WithQueryOptions returns a context whose Execute, Count and Facets calls
+// use opts over the executor's QueryOptions. Zero fields keep the executor's
+// setting
+//
+//	ctx = gorm.WithQueryOptions(ctx, gorm.QueryOptions{Timeout: 2 * time.Second})
+func WithQueryOptions(ctx context.Context, opts QueryOptions) context.Context {
+	return context.WithValue(ctx, queryOptionsKey{}, opts)
+}
+
+// withQueryOptions returns an executor bound to the QueryOptions of ctx
+func (e *Executor) withQueryOptions(ctx context.Context) *Executor {
+	override, ok := ctx.Value(queryOptionsKey{}).(QueryOptions)
+	if !ok {
+		return e
+	}
+	bound := *e
+	if len(override.IndexHints) > 0 {
+		bound.queryOptions.IndexHints = override.IndexHints
+	}
+	if override.Timeout > 0 {
+		bound.queryOptions.Timeout = override.Timeout
+	}
+	if override.ReadPolicy != ReadPolicyDefault {
+		bound.queryOptions.ReadPolicy = override.ReadPolicy
+	}
+	return &bound
+}
+
+// withTimeout runs fn with the Timeout of QueryOptions applied: in a
+// transaction with SET LOCAL statement_timeout on PostgreSQL and with a
+// context deadline on dialects without a server-side limit. MySQL statements
+// carry the MAX_EXECUTION_TIME hint of applyQueryHints instead
+func (e *Executor) withTimeout(ctx context.Context, fn func(context.Context, *Executor) error) error {
+	timeout := e.queryOptions.Timeout
+	if timeout <= 0 {
+		return fn(ctx, e)
+	}
+	switch e.dialectName() {
+	case dialectPostgres:
+		return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(statementTimeoutSQL(timeout)).Error; err != nil {
+				return query.NewExecutionError("set statement timeout", err)
+			}
+			return fn(ctx, e.inTransaction(tx))
+		})
+	case dialectMySQL:
+		return fn(ctx, e)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx, e)
+}
+
+// statementTimeoutSQL returns the PostgreSQL statement setting timeout for the
+// rest of the transaction. Timeouts below a millisecond are rounded up, since
+// 0 disables the limit
+func statementTimeoutSQL(timeout time.Duration) string {
+	return fmt.Sprintf("SET LOCAL statement_timeout = %d", max(timeout.Milliseconds(), 1))
+}
+
+// applyQueryHints adds the MySQL optimizer hints of QueryOptions to the
+// SELECT of tx: USE INDEX after its table, unless it reads from a range union
+// or another table expression, and MAX_EXECUTION_TIME for the Timeout
+func (e *Executor) applyQueryHints(tx *gorm.DB) *gorm.DB {
+	if e.dialectName() != dialectMySQL {
+		return tx
+	}
+	if len(e.queryOptions.IndexHints) > 0 && tx.Statement.TableExpr == nil {
+		tx = tx.Clauses(indexHint(e.queryOptions.IndexHints))
+	}
+	if e.queryOptions.Timeout > 0 {
+		tx = tx.Clauses(executionTimeHint(max(e.queryOptions.Timeout.Milliseconds(), 1)))
+	}
+	return tx
+}
+
+// indexHint is USE INDEX (...) after the table of a SELECT
+type indexHint []string
+
+// ModifyStatement places the hint after the FROM clause
+func (h indexHint) ModifyStatement(stmt *gorm.Statement) {
+	from := stmt.Clauses["FROM"]
+	from.AfterExpression = h
+	stmt.Clauses["FROM"] = from
+}
+
+// Build writes USE INDEX with the quoted index names
+func (h indexHint) Build(builder clause.Builder) {
+	builder.WriteString("USE INDEX (")
+	for i, name := range h {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteQuoted(name)
+	}
+	builder.WriteByte(')')
+}
+
+// executionTimeHint is the MAX_EXECUTION_TIME optimizer hint of a SELECT, in
+// milliseconds
+type executionTimeHint int64
+
+// ModifyStatement places the hint after the SELECT keyword
+func (h executionTimeHint) ModifyStatement(stmt *gorm.Statement) {
+	sel := stmt.Clauses["SELECT"]
+	sel.AfterNameExpression = h
+	stmt.Clauses["SELECT"] = sel
+}
+
+// Build writes the hint comment
+func (h executionTimeHint) Build(builder clause.Builder) {
+	builder.WriteString(fmt.Sprintf("/*+ MAX_EXECUTION_TIME(%d) */", int64(h)))
+}
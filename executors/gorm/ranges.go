@@ -0,0 +1,94 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// applyFilter restricts tx to the rows matching filter. Range lists planned
+// with RangeStrategyUnionAll read from a UNION ALL of one range scan per range;
+// other range lists are compiled from their merged ranges.
+// model is used to find the table and associations when the executor's DB has no model
+func (e *Executor) applyFilter(tx *gorm.DB, filter query.Node, model interface{}) (*gorm.DB, error) {
+	if filter == nil {
+		return tx, nil
+	}
+	if len(e.options.RelationMap) > 0 && e.schema == nil {
+		if s, ok := e.modelSchema(model); ok {
+			bound := *e
+			bound.schema = s
+			e = &bound
+		}
+	}
+	if plan := e.options.PlanRanges(filter); plan != nil {
+		if plan.Strategy == query.RangeStrategyUnionAll {
+			if table, ok := e.unionTable(model); ok {
+				return e.applyRangeUnion(tx, plan, table)
+			}
+		}
+		filter = plan.Node()
+	}
+
+	whereClauses, args, err := e.buildFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if whereClauses != "" {
+		tx = tx.Where(whereClauses, args...)
+	}
+	return tx, nil
+}
+
+// applyRangeUnion selects from
+// (SELECT * FROM table WHERE <range 1> AND <rest> UNION ALL SELECT ...) AS table,
+// so every branch can use an index range scan. The ranges of a plan are
+// disjoint, so no row is returned twice. The subquery is aliased as the table
+// so ordering, cursors and model scopes keep working on the outer query
+func (e *Executor) applyRangeUnion(tx *gorm.DB, plan *query.RangePlan, table string) (*gorm.DB, error) {
+	var rest string
+	var restArgs []interface{}
+	if plan.Rest != nil {
+		var err error
+		rest, restArgs, err = e.buildFilter(plan.Rest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	quoted := tx.Statement.Quote(table)
+	scans := make([]string, len(plan.Ranges))
+	var args []interface{}
+	for i, r := range plan.Ranges {
+		where, whereArgs, err := e.buildFilter(r.Node(plan.Field))
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, whereArgs...)
+		if rest != "" {
+			where = fmt.Sprintf("(%s) AND (%s)", where, rest)
+			args = append(args, restArgs...)
+		}
+		scans[i] = fmt.Sprintf("SELECT * FROM %s WHERE %s", quoted, where)
+	}
+	return tx.Table(fmt.Sprintf("(%s) AS %s", strings.Join(scans, " UNION ALL "), quoted), args...), nil
+}
+
+// unionTable returns the table range scans read from: the table set on the
+// executor's DB, or the table of its model or of model. ok is false when the
+// DB reads from a table expression or no table can be found
+func (e *Executor) unionTable(model interface{}) (string, bool) {
+	if e.db == nil || e.db.Statement == nil || e.db.Statement.TableExpr != nil {
+		return "", false
+	}
+	if e.db.Statement.Table != "" {
+		return e.db.Statement.Table, true
+	}
+	s, ok := e.modelSchema(model)
+	if !ok {
+		return "", false
+	}
+	return s.Table, true
+}
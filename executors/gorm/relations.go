@@ -0,0 +1,142 @@
+package gorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// buildRelationClause builds a condition on a field of an association listed in
+// RelationMap, such as author.name = ?. It is compiled to a subquery on the
+// associated table:
+//
+//	belongs to:   author_id IN (SELECT id FROM authors WHERE name = ?)
+//	has one/many: id IN (SELECT product_id FROM reviews WHERE rating >= ?)
+//	many to many: id IN (SELECT product_id FROM product_tags WHERE tag_id IN (SELECT id FROM tags WHERE name = ?))
+//
+// Unlike a JOIN, a subquery never repeats rows of the model for has-many
+// associations and keeps the model's own columns unambiguous. A condition on a
+// has-many or many-to-many association matches when any associated row matches.
+// ok is false for fields without a RelationMap prefix
+func (e *Executor) buildRelationClause(n *query.ComparisonNode, field string) (string, []interface{}, bool, error) {
+	prefix, column, found := strings.Cut(field, ".")
+	if !found {
+		return "", nil, false, nil
+	}
+	name, ok := e.options.RelationMap[prefix]
+	if !ok {
+		return "", nil, false, nil
+	}
+	if !e.isValidField(column) {
+		return "", nil, false, query.InvalidFieldNameError(field)
+	}
+	if e.schema == nil {
+		return "", nil, false, fmt.Errorf("%w: relation %s needs a GORM model", query.ErrInvalidQuery, prefix)
+	}
+	rel, ok := e.schema.Relationships.Relations[name]
+	if !ok {
+		return "", nil, false, fmt.Errorf("%w: model %s has no association %s", query.ErrInvalidQuery, e.schema.Name, name)
+	}
+
+	cond, args, err := e.relatedCondition(n, field, column)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	var own, related *schema.Field
+	var fixed []*schema.Reference
+	for _, ref := range rel.References {
+		switch {
+		case ref.PrimaryKey == nil:
+			// Polymorphic type column, e.g. owner_type = 'products'
+			fixed = append(fixed, ref)
+		case rel.Type == schema.Many2Many:
+			// Handled below with the join table
+		case ref.OwnPrimaryKey:
+			if own != nil {
+				return "", nil, false, fmt.Errorf("%w: association %s has a composite key", query.ErrInvalidQuery, name)
+			}
+			own, related = ref.PrimaryKey, ref.ForeignKey
+		default:
+			if own != nil {
+				return "", nil, false, fmt.Errorf("%w: association %s has a composite key", query.ErrInvalidQuery, name)
+			}
+			own, related = ref.ForeignKey, ref.PrimaryKey
+		}
+	}
+	// Polymorphic constraints apply to the table holding the foreign keys
+	var constraints string
+	for _, ref := range fixed {
+		constraints += fmt.Sprintf(" AND %s = ?", ref.ForeignKey.DBName)
+		args = append(args, ref.PrimaryValue)
+	}
+
+	quote := e.db.Statement.Quote
+	if rel.Type != schema.Many2Many {
+		if own == nil {
+			return "", nil, false, fmt.Errorf("%w: association %s has no keys", query.ErrInvalidQuery, name)
+		}
+		return fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE (%s)%s)",
+			own.DBName, related.DBName, quote(rel.FieldSchema.Table), cond, constraints), args, true, nil
+	}
+
+	var ownKey, joinOwn, joinRelated, relatedKey string
+	for _, ref := range rel.References {
+		if ref.PrimaryKey == nil {
+			continue
+		}
+		if ref.OwnPrimaryKey {
+			if ownKey != "" {
+				return "", nil, false, fmt.Errorf("%w: association %s has a composite key", query.ErrInvalidQuery, name)
+			}
+			ownKey, joinOwn = ref.PrimaryKey.DBName, ref.ForeignKey.DBName
+		} else {
+			if relatedKey != "" {
+				return "", nil, false, fmt.Errorf("%w: association %s has a composite key", query.ErrInvalidQuery, name)
+			}
+			relatedKey, joinRelated = ref.PrimaryKey.DBName, ref.ForeignKey.DBName
+		}
+	}
+	return fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s IN (SELECT %s FROM %s WHERE %s)%s)",
+		ownKey, joinOwn, quote(rel.JoinTable.Table), joinRelated, relatedKey, quote(rel.FieldSchema.Table), cond, constraints), args, true, nil
+}
+
+// relatedCondition builds the condition of n on column of the associated
+// table. The field was already checked against AllowedFields, and values are
+// converted with the field's name as it appears in the query
+func (e *Executor) relatedCondition(n *query.ComparisonNode, field, column string) (string, []interface{}, error) {
+	opts := *e.options
+	opts.AllowedFields = nil
+	opts.RelationMap = nil
+	opts.ValueConverter = func(_ string, value interface{}) (interface{}, error) {
+		return e.options.ConvertValue(field, value)
+	}
+
+	inner := &query.ComparisonNode{Field: column, Operator: n.Operator, Value: n.Value, Modifier: n.Modifier}
+	related := &Executor{db: e.db, options: &opts}
+	if table, ok := e.inTables[n]; ok {
+		related.inTables = map[*query.ComparisonNode]string{inner: table}
+	}
+	return related.buildFilter(inner)
+}
+
+// modelSchema parses the model of the executor's DB, or model when the DB has none
+func (e *Executor) modelSchema(model interface{}) (*schema.Schema, bool) {
+	if e.db == nil || e.db.Statement == nil {
+		return nil, false
+	}
+	if e.db.Statement.Model != nil {
+		model = e.db.Statement.Model
+	}
+	if model == nil {
+		return nil, false
+	}
+	stmt := &gorm.Statement{DB: e.db}
+	if err := stmt.Parse(model); err != nil || stmt.Schema == nil {
+		return nil, false
+	}
+	return stmt.Schema, true
+}
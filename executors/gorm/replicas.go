@@ -0,0 +1,153 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultHealthCheckInterval is how often replicas are pinged unless
+// Options.HealthCheckInterval is set
+const DefaultHealthCheckInterval = 10 * time.Second
+
+// ReadPolicy chooses the database that reads run on when the executor has
+// replicas
+type ReadPolicy int
+
+const (
+	// ReadPolicyDefault keeps the executor's policy. As the executor's policy
+	// it is ReadPolicyReplica
+	ReadPolicyDefault ReadPolicy = iota
+	// ReadPolicyReplica reads from the healthy replicas in turn, and from the
+	// primary when none is healthy
+	ReadPolicyReplica
+	// ReadPolicyPrimary reads from the primary, e.g. to count rows right
+	// after writing them, before replication catches up
+	ReadPolicyPrimary
+)
+
+// replicaSet holds the replicas of an executor and pings them in the
+// background until it is closed
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// replica is a read-only copy of the primary database
+type replica struct {
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// newReplicaSet returns the replicas of dbs, pinged every interval, or nil
+// when there are none
+func newReplicaSet(dbs []*gorm.DB, interval time.Duration) *replicaSet {
+	if len(dbs) == 0 {
+		return nil
+	}
+	set := &replicaSet{stop: make(chan struct{})}
+	for _, db := range dbs {
+		r := &replica{db: db}
+		r.healthy.Store(true)
+		set.replicas = append(set.replicas, r)
+	}
+	if interval == 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	if interval > 0 {
+		go set.healthCheck(interval)
+	}
+	return set
+}
+
+// pick returns the next healthy replica, or nil when none is healthy
+func (s *replicaSet) pick() *replica {
+	n := uint64(len(s.replicas))
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// healthCheck pings the replicas every interval until the set is closed
+func (s *replicaSet) healthCheck(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.check(interval)
+		}
+	}
+}
+
+// check pings every replica, waiting up to timeout for each, and marks it
+// healthy if it answers
+func (s *replicaSet) check(timeout time.Duration) {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		r.healthy.Store(r.ping(ctx) == nil)
+		cancel()
+	}
+}
+
+// close stops the health checks
+func (s *replicaSet) close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// ping checks the connection to the replica
+func (r *replica) ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// read runs fn with a copy of e reading from the database chosen by the
+// ReadPolicy of QueryOptions. A read that cannot reach its replica marks the
+// replica down until it answers a ping and runs again on the primary
+func (e *Executor) read(fn func(*Executor) error) error {
+	if e.replicas == nil || e.queryOptions.ReadPolicy == ReadPolicyPrimary {
+		return fn(e)
+	}
+	r := e.replicas.pick()
+	if r == nil {
+		return fn(e)
+	}
+	bound := *e
+	bound.db = r.db
+	bound.replicas = nil
+	err := fn(&bound)
+	if !isConnectionError(err) {
+		return err
+	}
+	r.healthy.Store(false)
+	return fn(e)
+}
+
+// isConnectionError reports whether err means the database could not be
+// reached, rather than that the statement failed
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr)
+}
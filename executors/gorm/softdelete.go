@@ -0,0 +1,38 @@
+package gorm
+
+import (
+	"reflect"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// applySoftDelete includes soft-deleted rows when the options or the query ask for
+// them. Otherwise, when the DB has no model, dest becomes the model so the total
+// count is scoped like the rows GORM finds for dest
+func (e *Executor) applySoftDelete(tx *gorm.DB, q *query.Query, dest interface{}) (*gorm.DB, error) {
+	include, err := e.options.IncludesDeleted(q)
+	if err != nil {
+		return nil, err
+	}
+	if include {
+		return tx.Unscoped(), nil
+	}
+	if tx.Statement.Model == nil && isStructSlicePtr(dest) {
+		tx = tx.Model(dest)
+	}
+	return tx, nil
+}
+
+// isStructSlicePtr reports whether dest points to a slice of structs or struct pointers
+func isStructSlicePtr(dest interface{}) bool {
+	typ := reflect.TypeOf(dest)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Slice {
+		return false
+	}
+	elem := typ.Elem().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct
+}
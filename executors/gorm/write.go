@@ -0,0 +1,104 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hadi77ir/go-query/query"
+	"gorm.io/gorm"
+)
+
+// DeleteWhere deletes the rows matching the query's filter. Models with a
+// gorm.DeletedAt field are soft deleted like db.Delete does, unless the
+// query includes deleted rows. The executor's DB needs a model
+func (e *Executor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "delete", q)
+	affected, err := e.write(ctx, q, nil, &entry)
+	e.options.LogWrite(ctx, entry, affected, err)
+	return affected, query.WrapError(e.Name(), "delete", err)
+}
+
+// UpdateWhere sets the columns in changes on the rows matching the query's
+// filter with a single UPDATE. Hooks and UpdatedAt work like db.Updates with
+// a map
+func (e *Executor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "update", q)
+	if changes == nil {
+		changes = map[string]interface{}{}
+	}
+	affected, err := e.write(ctx, q, changes, &entry)
+	e.options.LogWrite(ctx, entry, affected, err)
+	return affected, query.WrapError(e.Name(), "update", err)
+}
+
+// write deletes the rows matching q, or updates them with changes when it is not nil
+func (e *Executor) write(ctx context.Context, q *query.Query, changes map[string]interface{}, entry *query.QueryLog) (int64, error) {
+	if err := e.options.ValidateWrite(q); err != nil {
+		return 0, err
+	}
+	if changes != nil {
+		var err error
+		if changes, err = e.options.ValidateChanges(ctx, changes); err != nil {
+			return 0, err
+		}
+		for field := range changes {
+			if _, err := e.column(field); err != nil {
+				return 0, err
+			}
+		}
+	}
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return 0, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
+	// UNION ALL range scans only work in SELECTs
+	bound := *e
+	opts := *e.options
+	opts.RangeStrategy = query.RangeStrategyOr
+	bound.options = &opts
+
+	var affected int64
+	err = bound.withInTables(ctx, q.Filter, func(bound *Executor) error {
+		var writeErr error
+		affected, writeErr = bound.writeRows(ctx, q, changes)
+		return writeErr
+	})
+	return affected, err
+}
+
+// writeRows runs the DELETE or UPDATE of write in e.db
+func (e *Executor) writeRows(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	tx, err := e.applySoftDelete(e.db.WithContext(ctx), q, nil)
+	if err != nil {
+		return 0, err
+	}
+	tx, err = e.applyFilter(tx, q.Filter, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var res *gorm.DB
+	if changes == nil {
+		if tx.Statement.Model == nil {
+			return 0, fmt.Errorf("%w: DeleteWhere needs a model, e.g. db.Model(&Product{})", query.ErrInvalidQuery)
+		}
+		res = tx.Delete(tx.Statement.Model)
+	} else {
+		res = tx.Updates(changes)
+	}
+	if res.Error != nil {
+		return 0, query.NewExecutionError("write rows", res.Error)
+	}
+	return res.RowsAffected, nil
+}
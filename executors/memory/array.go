@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// compileArrayTest compiles a LENGTH, ANY or ALL condition on a slice or
+// array field. Fields of other kinds never match. LENGTH compares the number
+// of elements with the unconverted integer; ANY applies the operator to each
+// element, and ALL IN requires every value to equal one of the elements
+func (e *MemoryExecutor) compileArrayTest(field string, n *query.ComparisonNode) valueTest {
+	if err := query.ValidateArrayCondition(n); err != nil {
+		return func(interface{}, time.Time) (bool, error) { return false, err }
+	}
+
+	if n.Modifier == query.ArrayModifierLength {
+		count, ok := n.Value.(query.IntValue)
+		if !ok {
+			return func(interface{}, time.Time) (bool, error) { return false, query.ErrInvalidQuery }
+		}
+		length := e.newOperand(int64(count))
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			elements, ok := arrayValue(fieldValue)
+			if !ok {
+				return false, nil
+			}
+			return e.compareLength(length, elements.Len(), n.Operator), nil
+		}
+	}
+
+	queryValue, err := e.convertValue(field, n.Value)
+	if err != nil {
+		return func(interface{}, time.Time) (bool, error) { return false, err }
+	}
+
+	if n.Modifier == query.ArrayModifierAny {
+		test := e.compileOperator(field, n.Operator, queryValue)
+		return func(fieldValue interface{}, deadline time.Time) (bool, error) {
+			elements, ok := arrayValue(fieldValue)
+			if !ok {
+				return false, nil
+			}
+			for i := 0; i < elements.Len(); i++ {
+				element := e.derefValue(elements.Index(i).Interface())
+				if element == nil {
+					continue
+				}
+				matched, err := test(element, deadline)
+				if err != nil || matched {
+					return matched, err
+				}
+			}
+			return false, nil
+		}
+	}
+
+	values, _ := queryValue.([]interface{})
+	operands := make([]operand, len(values))
+	for i, value := range values {
+		operands[i] = e.newOperand(value)
+	}
+	return func(fieldValue interface{}, _ time.Time) (bool, error) {
+		elements, ok := arrayValue(fieldValue)
+		if !ok {
+			return false, nil
+		}
+		for _, value := range operands {
+			found := false
+			for i := 0; i < elements.Len() && !found; i++ {
+				if element := e.derefValue(elements.Index(i).Interface()); element != nil {
+					found = value.equal(e, element)
+				}
+			}
+			if !found {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// compareLength compares the number of elements of an array with length
+func (e *MemoryExecutor) compareLength(length operand, n int, op query.ComparisonOperator) bool {
+	switch op {
+	case query.OpEqual:
+		return length.equal(e, int64(n))
+	case query.OpNotEqual:
+		return !length.equal(e, int64(n))
+	default:
+		return length.compare(e, int64(n), op)
+	}
+}
+
+// arrayValue returns the slice or array held by fieldValue
+func arrayValue(fieldValue interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(fieldValue)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
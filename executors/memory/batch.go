@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// ExecuteBatch runs the queries on one snapshot of the data source and one
+// options snapshot, so every query sees the same items even while a Store is
+// updated
+func (e *MemoryExecutor) ExecuteBatch(ctx context.Context, queries []*query.Query, dests []interface{}) ([]executor.BatchResult, error) {
+	if err := executor.ValidateBatch(queries, dests); err != nil {
+		return nil, err
+	}
+	bound := *e.withCurrentOptions()
+	bound.optionsProvider = nil
+	data := e.dataSource()
+	bound.dataSource = func() interface{} { return data }
+
+	results := make([]executor.BatchResult, len(queries))
+	for i, q := range queries {
+		results[i].Result, results[i].Err = bound.Execute(ctx, q, "", dests[i])
+	}
+	return results, nil
+}
@@ -0,0 +1,521 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// predicate is a compiled filter. deadline is the RegexTimeout deadline of the
+// execution, zero when there is none
+type predicate func(item reflect.Value, deadline time.Time) (bool, error)
+
+// valueTest is a compiled operator applied to a non-nil field value
+type valueTest func(fieldValue interface{}, deadline time.Time) (bool, error)
+
+// CompiledQuery is a query prepared for repeated execution over live data.
+// Field lookups, value conversion and LIKE/REGEX patterns are resolved once,
+// when the query is compiled, instead of for every item of every execution.
+// Results are the same as executing the query directly.
+//
+// Options are resolved at compile time: compile again to pick up changes made
+// through an OptionsProvider. A CompiledQuery is safe for concurrent use.
+type CompiledQuery struct {
+	e     *MemoryExecutor
+	q     *query.Query
+	match predicate // nil matches every item
+}
+
+// Compile validates q, applies BaseFilter and compiles the filter for repeated execution.
+// Placeholders and relative times must be resolved first, with
+// ExecutorOptions.ResolvePlaceholders
+func (e *MemoryExecutor) Compile(q *query.Query) (*CompiledQuery, error) {
+	return e.withCurrentOptions().compile(q)
+}
+
+// compile compiles q with the executor's current options
+func (e *MemoryExecutor) compile(q *query.Query) (*CompiledQuery, error) {
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return nil, err
+	}
+	if err := e.options.ValidateDistinct(q); err != nil {
+		return nil, err
+	}
+	q = e.options.ScopedQuery(q)
+
+	compiled := &CompiledQuery{e: e, q: q}
+	if q.Filter != nil {
+		compiled.match = e.compileFilter(q.Filter)
+	}
+	return compiled, nil
+}
+
+// Query returns the compiled query, with BaseFilter applied
+func (c *CompiledQuery) Query() *query.Query {
+	return c.q
+}
+
+// Execute runs the compiled query on the current data of the executor
+func (c *CompiledQuery) Execute(ctx context.Context, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	entry := query.NewQueryLog(c.e.Name(), "execute", c.q)
+	result, err := c.execute(ctx, cursorParam, dest)
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+		result.Warnings = c.e.options.SearchTermWarnings(c.q)
+	}
+	c.e.options.LogExecute(ctx, entry, result, err)
+	return query.WrapResult(c.e.Name(), "execute", result, err)
+}
+
+func (c *CompiledQuery) execute(ctx context.Context, cursorParam string, dest interface{}) (*query.Result, error) {
+	return c.e.withRegexDeadline().execute(ctx, c.q, c.match, cursorParam, dest)
+}
+
+// Count returns the number of items matching the compiled query
+func (c *CompiledQuery) Count(ctx context.Context) (int64, error) {
+	entry := query.NewQueryLog(c.e.Name(), "count", c.q)
+	count, err := c.count(ctx)
+	c.e.options.LogCount(ctx, entry, count, err)
+	return count, query.WrapError(c.e.Name(), "count", err)
+}
+
+func (c *CompiledQuery) count(ctx context.Context) (int64, error) {
+	e := c.e.withRegexDeadline()
+	filtered, err := e.filterData(ctx, c.q.Filter, c.match, nil)
+	if err != nil {
+		return 0, err
+	}
+	if isDistinct(c.q) {
+		filtered, _, err = e.distinct(filtered, c.q, nil)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(filtered)), nil
+}
+
+// Match reports whether item (a struct, pointer to struct or map) satisfies
+// the compiled filter
+func (c *CompiledQuery) Match(item interface{}) (bool, error) {
+	if c.match == nil {
+		return true, nil
+	}
+	match, err := c.match(reflect.ValueOf(item), c.e.withRegexDeadline().regexDeadline)
+	if err != nil {
+		return false, wrapEvaluateError(err)
+	}
+	return match, nil
+}
+
+// filterData returns the items of the data source accepted by match, the
+// compiled form of filter. Secondary indexes narrow the items match runs on.
+// Items are evaluated in chunks, in parallel when Parallelism is set, and ctx
+// is checked between chunks. A non-nil explain receives how the items were scanned
+func (e *MemoryExecutor) filterData(ctx context.Context, filter query.Node, match predicate, explain *query.Explain) ([]reflect.Value, error) {
+	// Get source data from the data source function
+	data := e.dataSource()
+	dataVal := reflect.ValueOf(data)
+	if dataVal.Kind() == reflect.Ptr {
+		dataVal = dataVal.Elem()
+	}
+	if dataVal.Kind() != reflect.Slice {
+		return nil, query.ErrInvalidQuery
+	}
+
+	positions, fields, indexed := e.candidates(filter, dataVal)
+	count := dataVal.Len()
+	if indexed {
+		count = len(positions)
+	}
+	if explain != nil {
+		explain.Plan = fmt.Sprintf("full scan: %d items", count)
+		if indexed {
+			explain.Plan = fmt.Sprintf("index scan: %d of %d items", count, dataVal.Len())
+			explain.Indexes = dedupeFields(fields)
+		}
+	}
+	itemAt := func(i int) reflect.Value {
+		if indexed {
+			return dataVal.Index(positions[i])
+		}
+		return dataVal.Index(i)
+	}
+
+	if match == nil {
+		filtered := make([]reflect.Value, count)
+		for i := range filtered {
+			filtered[i] = itemAt(i)
+		}
+		return filtered, nil
+	}
+	return e.evaluateChunks(ctx, count, itemAt, match)
+}
+
+// wrapEvaluateError wraps filter evaluation errors in an ExecutionError.
+// Errors in the query itself, such as disallowed fields, are returned as is,
+// like the database executors that reject them before running the query
+func wrapEvaluateError(err error) error {
+	// If error is already an ExecutionError, preserve it
+	var execErr *query.ExecutionError
+	var fieldErr *query.FieldError
+	if errors.As(err, &execErr) || errors.As(err, &fieldErr) ||
+		errors.Is(err, query.ErrInvalidQuery) || errors.Is(err, query.ErrRegexNotSupported) {
+		return err
+	}
+	return query.NewExecutionError("evaluate filter", err)
+}
+
+// compileFilter compiles a filter node with the semantics of evaluateFilter.
+// Errors evaluateFilter reports while evaluating are reported the same way,
+// so compiling never fails for a filter that would match an empty data set.
+func (e *MemoryExecutor) compileFilter(node query.Node) predicate {
+	switch n := node.(type) {
+	case *query.ComparisonNode:
+		if expanded := e.options.ExpandPhrase(n, true); expanded != n {
+			return e.compileFilter(expanded)
+		}
+		if n.Field == query.SearchField && len(e.options.DefaultSearchFields) > 0 {
+			// Expand bare terms to an OR across all default search fields
+			return e.compileFilter(query.ExpandDefaultSearch(n, e.options.DefaultSearchFields))
+		}
+		return e.compileComparison(n)
+	case *query.BinaryOpNode:
+		left := e.compileFilter(n.Left)
+		right := e.compileFilter(n.Right)
+		and := n.Operator == query.BinaryOpAnd
+		return func(item reflect.Value, deadline time.Time) (bool, error) {
+			leftMatch, err := left(item, deadline)
+			if err != nil {
+				return false, err
+			}
+			rightMatch, err := right(item, deadline)
+			if err != nil {
+				return false, err
+			}
+			if and {
+				return leftMatch && rightMatch, nil
+			}
+			return leftMatch || rightMatch, nil
+		}
+	default:
+		return failWith(query.ErrInvalidQuery)
+	}
+}
+
+// failWith returns a predicate that always fails with err
+func failWith(err error) predicate {
+	return func(reflect.Value, time.Time) (bool, error) {
+		return false, err
+	}
+}
+
+// compileComparison compiles a comparison with the semantics of evaluateComparison
+func (e *MemoryExecutor) compileComparison(n *query.ComparisonNode) predicate {
+	field := n.Field
+	if field == query.SearchField {
+		field = e.options.DefaultSearchField
+	}
+	access := e.newFieldAccessor(field)
+
+	// Convert the query value once, reporting conversion errors per item like evaluateComparison
+	var test valueTest
+	if n.Modifier != query.ArrayModifierNone {
+		test = e.compileArrayTest(field, n)
+	} else if queryValue, err := e.convertValue(field, n.Value); err != nil {
+		test = func(interface{}, time.Time) (bool, error) { return false, err }
+	} else if n.Phrase && n.Operator == query.OpRegex {
+		// Whole-word patterns of phrases are not anchored, see ExpandPhrase
+		test = e.regexTest(query.CompileRegex(e.options.ConditionPattern(n, fmt.Sprintf("%v", queryValue))))
+	} else {
+		test = e.compileOperator(field, n.Operator, queryValue)
+	}
+
+	return func(item reflect.Value, deadline time.Time) (bool, error) {
+		fieldValue, err := access.get(item)
+		if err != nil {
+			// Security violations and custom field getter errors propagate,
+			// missing fields don't match
+			if e.options.FieldGetter != nil || errors.Is(err, query.ErrFieldNotAllowed) {
+				return false, err
+			}
+			return false, nil
+		}
+		if fieldValue == nil {
+			// Nil values are treated like missing fields
+			return false, nil
+		}
+		return test(fieldValue, deadline)
+	}
+}
+
+// compileOperator compiles an operator against a converted query value
+func (e *MemoryExecutor) compileOperator(field string, op query.ComparisonOperator, queryValue interface{}) valueTest {
+	switch op {
+	case query.OpEqual, query.OpNotEqual:
+		value := e.newOperand(queryValue)
+		lo, hi, ranged := e.options.FloatRange(queryValue)
+		negate := op == query.OpNotEqual
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			if ranged {
+				if f, isNum := e.toFloat64(fieldValue); isNum {
+					return (f >= lo && f <= hi) != negate, nil
+				}
+			}
+			return value.equal(e, fieldValue) != negate, nil
+		}
+
+	case query.OpGreaterThan, query.OpGreaterThanOrEqual, query.OpLessThan, query.OpLessThanOrEqual:
+		value := e.newOperand(queryValue)
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			return value.compare(e, fieldValue, op), nil
+		}
+
+	case query.OpLike, query.OpNotLike:
+		re, err := query.CompileLike(fmt.Sprintf("%v", queryValue))
+		negate := op == query.OpNotLike
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			// Invalid patterns match nothing
+			return (err == nil && re.MatchString(formatValue(fieldValue))) != negate, nil
+		}
+
+	case query.OpContains, query.OpIContains:
+		return e.compileContains(field, queryValue, op == query.OpContains)
+
+	case query.OpStartsWith:
+		prefix := fmt.Sprintf("%v", queryValue)
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			return strings.HasPrefix(formatValue(fieldValue), prefix), nil
+		}
+
+	case query.OpEndsWith:
+		suffix := fmt.Sprintf("%v", queryValue)
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			return strings.HasSuffix(formatValue(fieldValue), suffix), nil
+		}
+
+	case query.OpRegex:
+		if e.options.ExecutorOptions.DisableRegex {
+			return func(interface{}, time.Time) (bool, error) { return false, query.ErrRegexNotSupported }
+		}
+		re, err := query.CompileRegex(e.options.RegexPattern(fmt.Sprintf("%v", queryValue)))
+		return e.regexTest(re, err)
+
+	case query.OpIn, query.OpNotIn:
+		var values []operand
+		arr := reflect.ValueOf(queryValue)
+		if arr.Kind() == reflect.Slice {
+			for i := 0; i < arr.Len(); i++ {
+				// Convert each array element using ValueConverter, like evaluateIn
+				converted, err := e.convertValue(field, arr.Index(i).Interface())
+				if err != nil {
+					continue // Skip this element if conversion fails
+				}
+				values = append(values, e.newOperand(converted))
+			}
+		}
+		negate := op == query.OpNotIn
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			for _, value := range values {
+				if value.equal(e, fieldValue) {
+					return !negate, nil
+				}
+			}
+			return negate, nil
+		}
+
+	case query.OpMatch:
+		searchTerms := query.SearchTerms(fmt.Sprintf("%v", queryValue))
+		return func(fieldValue interface{}, _ time.Time) (bool, error) {
+			if len(searchTerms) == 0 {
+				return false, nil
+			}
+			fieldTerms := make(map[string]struct{})
+			for _, term := range query.SearchTerms(formatValue(fieldValue)) {
+				fieldTerms[term] = struct{}{}
+			}
+			for _, term := range searchTerms {
+				if _, ok := fieldTerms[term]; !ok {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+
+	default:
+		return func(interface{}, time.Time) (bool, error) { return false, query.ErrInvalidQuery }
+	}
+}
+
+// regexTest compiles a REGEX match against a pattern compiled with err
+func (e *MemoryExecutor) regexTest(re *regexp.Regexp, err error) valueTest {
+	return func(fieldValue interface{}, deadline time.Time) (bool, error) {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return false, fmt.Errorf("%w after %v", query.ErrRegexTimeout, e.options.RegexTimeout)
+		}
+		if err != nil {
+			// Invalid patterns match nothing
+			return false, nil
+		}
+		return re.MatchString(formatValue(fieldValue)), nil
+	}
+}
+
+// compileContains compiles CONTAINS and ICONTAINS with the semantics of evaluateContains
+func (e *MemoryExecutor) compileContains(field string, queryValue interface{}, caseSensitive bool) valueTest {
+	converted, err := e.convertValue(field, queryValue)
+	if err != nil {
+		converted = queryValue // Fallback to original if conversion fails
+	}
+	value := e.newOperand(converted)
+	substr := value.str
+	if !caseSensitive {
+		substr = strings.ToLower(substr)
+	}
+
+	return func(fieldValue interface{}, _ time.Time) (bool, error) {
+		// Array CONTAINS: check if any element in the array matches the value
+		fieldVal := reflect.ValueOf(fieldValue)
+		if fieldVal.Kind() == reflect.Slice || fieldVal.Kind() == reflect.Array {
+			for i := 0; i < fieldVal.Len(); i++ {
+				if value.equal(e, fieldVal.Index(i).Interface()) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+
+		str := formatValue(fieldValue)
+		if !caseSensitive {
+			str = strings.ToLower(str)
+		}
+		return strings.Contains(str, substr), nil
+	}
+}
+
+// operand is a query value with its numeric and string forms computed once
+type operand struct {
+	num   float64
+	isNum bool
+	str   string
+}
+
+func (e *MemoryExecutor) newOperand(v interface{}) operand {
+	num, isNum := e.toFloat64(v)
+	return operand{num: num, isNum: isNum, str: fmt.Sprintf("%v", v)}
+}
+
+// equal reports whether fieldValue equals the operand, like compareEqual
+func (o operand) equal(e *MemoryExecutor, fieldValue interface{}) bool {
+	if o.isNum {
+		if f, ok := e.toFloat64(fieldValue); ok {
+			return f == o.num
+		}
+	}
+	return formatValue(fieldValue) == o.str
+}
+
+// compare orders fieldValue against the operand, like compareGreater and compareLess
+func (o operand) compare(e *MemoryExecutor, fieldValue interface{}, op query.ComparisonOperator) bool {
+	if o.isNum {
+		if f, ok := e.toFloat64(fieldValue); ok {
+			switch op {
+			case query.OpGreaterThan:
+				return f > o.num
+			case query.OpGreaterThanOrEqual:
+				return f >= o.num
+			case query.OpLessThan:
+				return f < o.num
+			default:
+				return f <= o.num
+			}
+		}
+	}
+	str := formatValue(fieldValue)
+	switch op {
+	case query.OpGreaterThan:
+		return str > o.str
+	case query.OpGreaterThanOrEqual:
+		return str >= o.str
+	case query.OpLessThan:
+		return str < o.str
+	default:
+		return str <= o.str
+	}
+}
+
+// formatValue formats a value like fmt.Sprintf("%v"), without the
+// formatting overhead for strings
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// fieldAccessor reads one field from items, caching the struct field index
+// of every item type it sees
+type fieldAccessor struct {
+	e       *MemoryExecutor
+	name    string
+	allowed bool
+	indexes sync.Map // reflect.Type -> int, -1 when the type has no such field
+}
+
+func (e *MemoryExecutor) newFieldAccessor(name string) *fieldAccessor {
+	return &fieldAccessor{e: e, name: name, allowed: e.options.ExecutorOptions.IsFieldAllowed(name)}
+}
+
+// get returns the field value of item, like getFieldValue
+func (a *fieldAccessor) get(item reflect.Value) (interface{}, error) {
+	if !a.allowed {
+		return nil, query.FieldNotAllowedError(a.name, a.e.options.ExecutorOptions.AllowedFields...)
+	}
+	if a.e.options.FieldGetter != nil {
+		return a.e.getFieldValue(item, a.name)
+	}
+
+	if item.Kind() == reflect.Ptr {
+		item = item.Elem()
+	}
+	if item.Kind() != reflect.Struct {
+		// Maps are looked up by key; nothing to cache
+		return a.e.getFieldValue(item, a.name)
+	}
+	idx := a.index(item.Type())
+	if idx < 0 {
+		return nil, query.ErrInvalidQuery
+	}
+	return a.e.derefValue(item.Field(idx).Interface()), nil
+}
+
+// index returns the index of the field in struct type typ, matching names and
+// json/bson tags like getFieldValue
+func (a *fieldAccessor) index(typ reflect.Type) int {
+	if idx, ok := a.indexes.Load(typ); ok {
+		return idx.(int)
+	}
+	idx := -1
+	for i := 0; i < typ.NumField() && idx < 0; i++ {
+		field := typ.Field(i)
+		if strings.EqualFold(field.Name, a.name) {
+			idx = i
+			break
+		}
+		for _, key := range []string{"json", "bson"} {
+			if tag := field.Tag.Get(key); tag != "" && strings.EqualFold(strings.Split(tag, ",")[0], a.name) {
+				idx = i
+				break
+			}
+		}
+	}
+	a.indexes.Store(typ, idx)
+	return idx
+}
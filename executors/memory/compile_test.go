@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledQuery_MatchesEvaluateFilter(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSearchFields = []string{"name", "description"}
+	executor := NewExecutor(getTestData(), opts)
+
+	maps := []map[string]interface{}{
+		{"name": "Wireless Mouse", "price": 29.99, "tags": []string{"usb", "wireless"}},
+		{"Name": "USB Hub", "price": "24.99", "tags": []string{"usb"}},
+		{"name": nil, "price": 10},
+	}
+
+	inputs := []string{
+		`brand = Logitech`,
+		`category != electronics`,
+		`price > 50 and stock <= 50`,
+		`price >= 49.99 or rating < 4.2`,
+		`name LIKE "%Mouse%"`,
+		`name NOT LIKE "USB%"`,
+		`description CONTAINS "wireless"`,
+		`description ICONTAINS "USB"`,
+		`name STARTS_WITH "Wire"`,
+		`name ENDS_WITH "Pad"`,
+		`name REGEX "^(USB|Web)"`,
+		`name REGEX "[invalid"`,
+		`id IN [1, "3", 5.0]`,
+		`brand NOT IN [Anker, JBL]`,
+		`description MATCH "mouse pad"`,
+		`tags CONTAINS usb`,
+		`tags LENGTH > 1`,
+		`tags ANY = usb`,
+		`tags ANY STARTS_WITH "wire"`,
+		`tags ALL IN [usb, wireless]`,
+		`name ANY = usb`,
+		`missing = 1`,
+		`wireless`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			filter, err := parser.ParseFilter(input)
+			require.NoError(t, err)
+			compiled, err := executor.Compile(&query.Query{Filter: filter})
+			require.NoError(t, err)
+
+			items := []interface{}{}
+			for _, p := range getTestData() {
+				items = append(items, p)
+			}
+			for _, m := range maps {
+				items = append(items, m)
+			}
+			for _, item := range items {
+				expected, expectedErr := executor.evaluateFilter(filter, reflect.ValueOf(item))
+				match, err := compiled.Match(item)
+				assert.Equal(t, expectedErr != nil, err != nil, "%v", item)
+				assert.Equal(t, expected, match, "%v", item)
+			}
+		})
+	}
+}
+
+func TestCompiledQuery_LiveData(t *testing.T) {
+	data := getTestData()
+	executor := NewExecutorWithDataSource(func() interface{} { return data }, query.DefaultExecutorOptions())
+
+	p, err := parser.NewParser(`category = accessories and name LIKE "USB%" sort_by = id`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	compiled, err := executor.Compile(q)
+	require.NoError(t, err)
+
+	var products []Product
+	result, err := compiled.Execute(context.Background(), "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.TotalItems)
+
+	data = append(data, Product{ID: 11, Name: "USB Fan", Category: "accessories"})
+	result, err = compiled.Execute(context.Background(), "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.TotalItems)
+	assert.Equal(t, "USB Fan", products[2].Name)
+
+	count, err := compiled.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestCompiledQuery_Errors(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"name"}
+	executor := NewExecutor(getTestData(), opts)
+
+	compiled, err := executor.Compile(&query.Query{Filter: query.Eq("password", "x")})
+	require.NoError(t, err)
+	_, err = compiled.Count(context.Background())
+	assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+
+	opts = query.DefaultExecutorOptions()
+	opts.DisableRegex = true
+	compiled, err = NewExecutor(getTestData(), opts).Compile(&query.Query{Filter: query.F("name").Regex("^U").Node()})
+	require.NoError(t, err)
+	// Disabled operators fail when evaluated, like Execute
+	_, err = compiled.Count(context.Background())
+	assert.True(t, errors.Is(err, query.ErrRegexNotSupported))
+}
+
+func BenchmarkExecute(b *testing.B) {
+	data := make([]Product, 10000)
+	for i := range data {
+		data[i] = Product{ID: i, Name: fmt.Sprintf("Product %d", i), Category: []string{"electronics", "accessories"}[i%2], Price: float64(i % 100)}
+	}
+	executor := NewExecutor(data, query.DefaultExecutorOptions())
+	filter, err := parser.ParseFilter(`category = electronics and price >= 50 and name LIKE "%9%"`)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Evaluate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := range data {
+				_, _ = executor.evaluateFilter(filter, reflect.ValueOf(data[j]))
+			}
+		}
+	})
+	b.Run("Compiled", func(b *testing.B) {
+		compiled, err := executor.Compile(&query.Query{Filter: filter})
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = compiled.Count(context.Background())
+		}
+	})
+}
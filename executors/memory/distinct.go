@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// isDistinct reports whether q asks for deduplicated results
+func isDistinct(q *query.Query) bool {
+	return q.Distinct || q.DistinctOn != ""
+}
+
+// distinct keeps the first of the items sharing a distinct key: the value of
+// q.DistinctOn, or with Distinct the whole item. Field values equal by
+// compareEqual share a key. scores, when not nil, are filtered alongside data
+func (e *MemoryExecutor) distinct(data []reflect.Value, q *query.Query, scores []float64) ([]reflect.Value, []float64, error) {
+	seen := make(map[string]struct{}, len(data))
+	kept := data[:0:0]
+	var keptScores []float64
+	if scores != nil {
+		keptScores = make([]float64, 0, len(scores))
+	}
+	for i, item := range data {
+		var key string
+		if q.DistinctOn != "" {
+			val, err := e.getFieldValue(item, q.DistinctOn)
+			if err != nil {
+				return nil, nil, err
+			}
+			if val = e.derefValue(val); val == nil {
+				key = "nil"
+			} else {
+				key = "v:" + e.orderKey(val)
+			}
+		} else {
+			key = fmt.Sprintf("%#v", reflect.Indirect(item).Interface())
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		kept = append(kept, item)
+		if scores != nil {
+			keptScores = append(keptScores, scores[i])
+		}
+	}
+	return kept, keptScores, nil
+}
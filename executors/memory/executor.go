@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -26,6 +25,19 @@ type FieldGetterFunc func(obj interface{}, field string) (interface{}, error)
 // This allows the data to be dynamically fetched/updated between queries
 type DataSourceFunc func() interface{}
 
+// NilHandling controls how nil pointers and nil interface values take part in comparisons
+type NilHandling int
+
+const (
+	// NilAsMissing treats nil values like missing fields: no condition matches them,
+	// so neither name = x nor name != x matches an item whose name is nil (default)
+	NilAsMissing NilHandling = iota
+	// NilAsZero compares nil pointers as the zero value of the pointed-to type,
+	// so a nil *string behaves like "" and a nil *int like 0.
+	// Nil interface values have no type and are still treated as missing
+	NilAsZero
+)
+
 // MemoryExecutorOptions extends ExecutorOptions with memory-specific options
 type MemoryExecutorOptions struct {
 	*query.ExecutorOptions
@@ -34,12 +46,35 @@ type MemoryExecutorOptions struct {
 	// If nil, the executor will use reflection (default behavior)
 	// Use this for complex scenarios where reflection doesn't work well
 	FieldGetter FieldGetterFunc
+
+	// NilHandling controls how nil field values are compared.
+	// Pointer fields are always dereferenced before comparison
+	NilHandling NilHandling
+
+	// Parallelism is the number of goroutines evaluating the filter.
+	// 0 or 1 evaluates on the calling goroutine. Results keep the source order.
+	// A custom FieldGetter must be safe for concurrent use when it is above 1
+	Parallelism int
+
+	// ChunkSize is the number of items evaluated between context cancellation
+	// checks, and per goroutine task with Parallelism (default: DefaultChunkSize)
+	ChunkSize int
 }
 
 // MemoryExecutor executes queries on in-memory slices and maps
 type MemoryExecutor struct {
-	dataSource DataSourceFunc
-	options    *MemoryExecutorOptions
+	dataSource      DataSourceFunc
+	options         *MemoryExecutorOptions
+	optionsProvider query.OptionsProvider
+
+	// regexDeadline is set on per-execution copies when RegexTimeout is configured
+	regexDeadline time.Time
+
+	// indexes are the secondary indexes of NewIndexedExecutor, nil without indexes
+	indexes *indexSet
+
+	// store is the Store of NewExecutorWithStore, which DeleteWhere and UpdateWhere change
+	store *Store
 }
 
 // NewExecutor creates a new memory executor with static data
@@ -110,49 +145,112 @@ func NewExecutorWithDataSourceAndOptions(dataSource DataSourceFunc, opts *Memory
 	}
 }
 
+// NewExecutorWithStore creates a new memory executor querying the current
+// snapshot of store. DeleteWhere and UpdateWhere change the store
+// copy-on-write, like Store.Update
+func NewExecutorWithStore(store *Store, opts *query.ExecutorOptions) *MemoryExecutor {
+	e := NewExecutorWithDataSource(store.DataSource(), opts)
+	e.store = store
+	return e
+}
+
+// NewExecutorWithOptionsProvider creates a new memory executor with a dynamic data source
+// whose options are fetched from the provider on every Execute/Count call
+// This allows allowlists, page caps and other policies to be changed at runtime
+// fieldGetter is optional (nil uses reflection)
+func NewExecutorWithOptionsProvider(dataSource DataSourceFunc, provider query.OptionsProvider, fieldGetter FieldGetterFunc) *MemoryExecutor {
+	return &MemoryExecutor{
+		dataSource: dataSource,
+		options: &MemoryExecutorOptions{
+			ExecutorOptions: query.ResolveOptions(provider),
+			FieldGetter:     fieldGetter,
+		},
+		optionsProvider: provider,
+	}
+}
+
+// withCurrentOptions returns an executor bound to the provider's current options snapshot
+// Executors without a provider are returned unchanged
+func (e *MemoryExecutor) withCurrentOptions() *MemoryExecutor {
+	if e.optionsProvider == nil {
+		return e
+	}
+	bound := *e
+	opts := *e.options
+	opts.ExecutorOptions = query.ResolveOptions(e.optionsProvider)
+	bound.options = &opts
+	return &bound
+}
+
+// withRegexDeadline returns an executor whose REGEX evaluation stops at the
+// RegexTimeout deadline. Executors without a timeout are returned unchanged
+func (e *MemoryExecutor) withRegexDeadline() *MemoryExecutor {
+	if e.options.RegexTimeout <= 0 {
+		return e
+	}
+	bound := *e
+	bound.regexDeadline = time.Now().Add(e.options.RegexTimeout)
+	return &bound
+}
+
 // Execute runs the query on the in-memory data
 func (e *MemoryExecutor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "execute", q)
+	result, err := e.executeQuery(ctx, q, cursorParam, dest, &entry)
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+		result.Warnings = e.options.SearchTermWarnings(q)
+	}
+	e.options.LogExecute(ctx, entry, result, err)
+	return query.WrapResult(e.Name(), "execute", result, err)
+}
+
+func (e *MemoryExecutor) executeQuery(ctx context.Context, q *query.Query, cursorParam string, dest interface{}, entry *query.QueryLog) (*query.Result, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+	compiled, err := e.compile(q)
+	if err != nil {
+		return nil, err
+	}
+	entry.Filter = compiled.q.Filter
+	return compiled.execute(ctx, cursorParam, dest)
+}
+
+// execute runs a validated and scoped query whose filter is compiled to match
+func (e *MemoryExecutor) execute(ctx context.Context, q *query.Query, match predicate, cursorParam string, dest interface{}) (*query.Result, error) {
 	// Validate destination
 	destVal := reflect.ValueOf(dest)
 	if destVal.Kind() != reflect.Ptr || destVal.Elem().Kind() != reflect.Slice {
 		return nil, query.ErrInvalidDestination
 	}
 
-	// Get source data from the data source function
-	data := e.dataSource()
-	dataVal := reflect.ValueOf(data)
-	if dataVal.Kind() == reflect.Ptr {
-		dataVal = dataVal.Elem()
-	}
-	if dataVal.Kind() != reflect.Slice {
-		return nil, query.ErrInvalidQuery
-	}
-
 	// Filter data
-	filtered := []reflect.Value{}
-	for i := 0; i < dataVal.Len(); i++ {
-		item := dataVal.Index(i)
-		if q.Filter == nil {
-			filtered = append(filtered, item)
-		} else {
-			match, err := e.evaluateFilter(q.Filter, item)
-			if err != nil {
-				// If error is already an ExecutionError, preserve it
-				var execErr *query.ExecutionError
-				if errors.As(err, &execErr) {
-					return nil, err
-				}
-				return nil, query.NewExecutionError("evaluate filter", err)
-			}
-			if match {
-				filtered = append(filtered, item)
-			}
-		}
+	var explain *query.Explain
+	if q.ExplainRequested {
+		explain = &query.Explain{Backend: e.Name(), Statement: query.FormatFilter(q.Filter)}
+	}
+	filtered, err := e.filterData(ctx, q.Filter, match, explain)
+	if err != nil {
+		return nil, err
 	}
 
 	totalItems := int64(len(filtered))
 
 	// Handle sorting
+	if err := e.options.ValidateSortField(q.SortBy); err != nil {
+		return nil, err
+	}
+	inOrder, err := query.InOrderCondition(q)
+	if err != nil {
+		return nil, err
+	}
 	sortField := q.SortBy
 	if sortField == "" {
 		sortField = e.options.DefaultSortField
@@ -174,22 +272,59 @@ func (e *MemoryExecutor) Execute(ctx context.Context, q *query.Query, cursorPara
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", query.ErrInvalidCursor, err)
 		}
+		if err := cursorData.CheckQuery(q); err != nil {
+			return nil, err
+		}
+	} else {
+		offset, err := e.options.PageOffset(q, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		cursorData = cursor.Jump(offset)
 	}
-
-	// Handle random order
-	if sortOrder == query.SortOrderRandom {
+	queryHash := cursor.QueryHash(q)
+
+	var scores []float64
+	var randomSeed int64
+	if inOrder != nil {
+		// Keep the order of the IN values
+		e.sortInOrder(filtered, inOrder)
+	} else if sortOrder == query.SortOrderRandom {
 		if !e.options.AllowRandomOrder {
 			return nil, query.ErrRandomOrderNotAllowed
 		}
-		// Use seed from cursor or generate new one
-		seed := int64(42) // Default seed
-		if cursorData != nil && cursorData.RandomSeed != 0 {
-			seed = cursorData.RandomSeed
+		// Reuse the seed of earlier pages, so pages of one order do not overlap
+		var cursorSeed int64
+		if cursorData != nil {
+			cursorSeed = cursorData.RandomSeed
+		}
+		randomSeed = query.ResolveRandomSeed(q, cursorSeed)
+		e.sortRandom(filtered, randomSeed)
+	} else if sortField == query.ScoreField {
+		// Relevance sorting: most relevant first, regardless of order
+		scores = e.sortByScore(filtered, q.Filter)
+	} else if sortField == query.MatchCountField {
+		// Most matched CONTAINS conditions first, regardless of order
+		if err := e.sortByMatches(filtered, q.Filter); err != nil {
+			return nil, err
 		}
-		e.shuffleWithSeed(filtered, seed)
-	} else {
-		// Regular sorting
-		e.sortData(filtered, sortField, sortOrder)
+	}
+	// Regular sorting waits for the page bounds, so only the items up to
+	// the end of the page are ordered
+	regularSort := inOrder == nil && sortOrder != query.SortOrderRandom &&
+		sortField != query.ScoreField && sortField != query.MatchCountField
+
+	// Deduplication keeps the first item in sort order, so it needs all items sorted
+	if isDistinct(q) {
+		if regularSort {
+			e.sortData(filtered, sortField, sortOrder)
+			regularSort = false
+		}
+		filtered, scores, err = e.distinct(filtered, q, scores)
+		if err != nil {
+			return nil, err
+		}
+		totalItems = int64(len(filtered))
 	}
 
 	// Handle limit enforcement
@@ -209,11 +344,21 @@ func (e *MemoryExecutor) Execute(ctx context.Context, q *query.Query, cursorPara
 		}
 	}
 
+	if startIdx > len(filtered) {
+		// Jumped past the last item
+		startIdx = len(filtered)
+	}
 	endIdx := startIdx + pageSize
 	if endIdx > len(filtered) {
 		endIdx = len(filtered)
 	}
 
+	// Every item is filtered anyway, so only CountNone changes the total reported
+	reportedTotal := totalItems
+	if e.options.CountMode == query.CountNone {
+		reportedTotal = query.TotalUnknown
+	}
+
 	// Apply limit if set
 	if q.Limit > 0 {
 		remaining := q.Limit - itemsReturnedSoFar
@@ -222,10 +367,11 @@ func (e *MemoryExecutor) Execute(ctx context.Context, q *query.Query, cursorPara
 			return &query.Result{
 				NextPageCursor: "",
 				PrevPageCursor: "",
-				TotalItems:     totalItems,
+				TotalItems:     reportedTotal,
 				ShowingFrom:    0,
 				ShowingTo:      0,
 				ItemsReturned:  0,
+				Explain:        explain,
 			}, nil
 		}
 		// Adjust endIdx to not exceed limit
@@ -235,6 +381,10 @@ func (e *MemoryExecutor) Execute(ctx context.Context, q *query.Query, cursorPara
 		}
 	}
 
+	if regularSort {
+		e.sortTop(filtered, sortField, sortOrder, endIdx)
+	}
+
 	// Get page of results
 	pageData := filtered[startIdx:endIdx]
 
@@ -263,9 +413,10 @@ func (e *MemoryExecutor) Execute(ctx context.Context, q *query.Query, cursorPara
 			Offset:        endIdx,
 			Direction:     "next",
 			ItemsReturned: itemsReturnedSoFar + itemsReturned,
+			QueryHash:     queryHash,
 		}
 		if sortOrder == query.SortOrderRandom {
-			nextCursorData.RandomSeed = 42 // Use consistent seed
+			nextCursorData.RandomSeed = randomSeed
 		}
 		nextCursor, _ = cursor.Encode(nextCursorData)
 	}
@@ -279,27 +430,201 @@ func (e *MemoryExecutor) Execute(ctx context.Context, q *query.Query, cursorPara
 			Offset:        startIdx,
 			Direction:     "prev",
 			ItemsReturned: prevItemsReturned,
+			QueryHash:     queryHash,
 		}
 		if sortOrder == query.SortOrderRandom {
-			prevCursorData.RandomSeed = 42
+			prevCursorData.RandomSeed = randomSeed
 		}
 		prevCursor, _ = cursor.Encode(prevCursorData)
 	}
 
-	return &query.Result{
+	result := &query.Result{
 		NextPageCursor: nextCursor,
 		PrevPageCursor: prevCursor,
-		TotalItems:     totalItems,
+		TotalItems:     reportedTotal,
 		ShowingFrom:    startIdx + 1,
 		ShowingTo:      endIdx,
 		ItemsReturned:  len(pageData),
-	}, nil
+		Explain:        explain,
+	}
+	if scores != nil {
+		result.Scores = scores[startIdx:endIdx]
+	}
+	if q.HighlightRequested {
+		result.Highlights = make([][]query.Highlight, len(pageData))
+		for i, item := range pageData {
+			result.Highlights[i] = e.highlightItem(q.Filter, item)
+		}
+	}
+	if totalItems == 0 && !e.options.AllowEmptyResults {
+		return result, query.ErrNoRecordsFound
+	}
+	return result, nil
+}
+
+// sortInOrder stably sorts items by the position of their field value in the
+// IN condition's values. Values are matched like compareEqual does.
+func (e *MemoryExecutor) sortInOrder(data []reflect.Value, n *query.ComparisonNode) {
+	values, _ := n.Value.(query.ArrayValue)
+	positions := make(map[string]int, len(values))
+	for i, elem := range values {
+		converted, err := e.convertValue(n.Field, elem)
+		if err != nil {
+			continue
+		}
+		key := e.orderKey(converted)
+		if _, seen := positions[key]; !seen {
+			positions[key] = i
+		}
+	}
+
+	type positionedItem struct {
+		item     reflect.Value
+		position int
+	}
+	positioned := make([]positionedItem, len(data))
+	for i, item := range data {
+		position := len(values) // unmatched items go last
+		if val, err := e.getFieldValue(item, n.Field); err == nil && val != nil {
+			if pos, ok := positions[e.orderKey(val)]; ok {
+				position = pos
+			}
+		}
+		positioned[i] = positionedItem{item: item, position: position}
+	}
+	sort.SliceStable(positioned, func(i, j int) bool {
+		return positioned[i].position < positioned[j].position
+	})
+	for i, p := range positioned {
+		data[i] = p.item
+	}
+}
+
+// orderKey returns a map key under which values equal by compareEqual collide
+func (e *MemoryExecutor) orderKey(v interface{}) string {
+	if f, ok := e.toFloat64(v); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// sortByScore sorts items by relevance (highest first) and returns the
+// score of each item in the sorted order
+func (e *MemoryExecutor) sortByScore(data []reflect.Value, filter query.Node) []float64 {
+	type scoredItem struct {
+		item  reflect.Value
+		score float64
+	}
+	scored := make([]scoredItem, len(data))
+	for i, item := range data {
+		scored[i] = scoredItem{item: item, score: e.scoreItem(filter, item)}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	scores := make([]float64, len(scored))
+	for i, s := range scored {
+		data[i] = s.item
+		scores[i] = s.score
+	}
+	return scores
+}
+
+// sortByMatches sorts items by the number of MatchClauses of the filter they
+// match (highest first), keeping the source order of ties
+func (e *MemoryExecutor) sortByMatches(data []reflect.Value, filter query.Node) error {
+	clauses, err := e.options.MatchClauses(filter)
+	if err != nil {
+		return err
+	}
+	counts := make([]int, len(data))
+	indexes := make([]int, len(data))
+	for i, item := range data {
+		indexes[i] = i
+		for _, clause := range clauses {
+			match, err := e.evaluateFilter(clause, item)
+			if err != nil {
+				return query.NewExecutionError("evaluate filter", err)
+			}
+			if match {
+				counts[i]++
+			}
+		}
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return counts[indexes[i]] > counts[indexes[j]]
+	})
+
+	sorted := make([]reflect.Value, len(data))
+	for i, idx := range indexes {
+		sorted[i] = data[idx]
+	}
+	copy(data, sorted)
+	return nil
+}
+
+// scoreItem computes a simple term-frequency relevance score for an item.
+// Each MATCH condition and bare search term contributes the number of
+// occurrences of its terms divided by the number of terms in the field.
+// Bare terms are scored on every default search field, scaled by its weight.
+func (e *MemoryExecutor) scoreItem(node query.Node, item reflect.Value) float64 {
+	switch n := node.(type) {
+	case *query.BinaryOpNode:
+		return e.scoreItem(n.Left, item) + e.scoreItem(n.Right, item)
+	case *query.ComparisonNode:
+		fields := []string{n.Field}
+		switch {
+		case n.Operator == query.OpMatch:
+		case n.Field == query.SearchField && (n.Operator == query.OpContains || n.Operator == query.OpIContains):
+			fields = e.options.SearchFields()
+		default:
+			return 0
+		}
+		score := 0.0
+		for _, field := range fields {
+			fieldValue, err := e.getFieldValue(item, field)
+			if err != nil || fieldValue == nil {
+				continue
+			}
+			tf := termFrequency(fmt.Sprintf("%v", fieldValue), fmt.Sprintf("%v", n.Value))
+			score += tf * e.options.SearchFieldWeight(field)
+		}
+		return score
+	default:
+		return 0
+	}
+}
+
+// termFrequency returns the occurrences of the search terms in the text,
+// normalized by the number of terms in the text
+func termFrequency(text, search string) float64 {
+	textTerms := query.SearchTerms(text)
+	if len(textTerms) == 0 {
+		return 0
+	}
+	counts := make(map[string]int, len(textTerms))
+	for _, term := range textTerms {
+		counts[term]++
+	}
+	hits := 0
+	for _, term := range query.SearchTerms(search) {
+		hits += counts[term]
+	}
+	return float64(hits) / float64(len(textTerms))
 }
 
 // evaluateFilter evaluates a filter node against an item
 func (e *MemoryExecutor) evaluateFilter(node query.Node, item reflect.Value) (bool, error) {
 	switch n := node.(type) {
 	case *query.ComparisonNode:
+		if expanded := e.options.ExpandPhrase(n, true); expanded != n {
+			return e.evaluateFilter(expanded, item)
+		}
+		if n.Field == query.SearchField && len(e.options.DefaultSearchFields) > 0 {
+			// Expand bare terms to an OR across all default search fields
+			return e.evaluateFilter(query.ExpandDefaultSearch(n, e.options.DefaultSearchFields), item)
+		}
 		return e.evaluateComparison(n, item)
 	case *query.BinaryOpNode:
 		leftMatch, err := e.evaluateFilter(n.Left, item)
@@ -323,7 +648,7 @@ func (e *MemoryExecutor) evaluateFilter(node query.Node, item reflect.Value) (bo
 func (e *MemoryExecutor) evaluateComparison(n *query.ComparisonNode, item reflect.Value) (bool, error) {
 	// Get field name
 	field := n.Field
-	if field == "__DEFAULT_SEARCH__" {
+	if field == query.SearchField {
 		field = e.options.DefaultSearchField
 	}
 
@@ -338,6 +663,13 @@ func (e *MemoryExecutor) evaluateComparison(n *query.ComparisonNode, item reflec
 		// Field not found - no match but not an error
 		return false, nil
 	}
+	if fieldValue == nil {
+		// Nil values are treated like missing fields
+		return false, nil
+	}
+	if n.Modifier != query.ArrayModifierNone {
+		return e.compileArrayTest(field, n)(fieldValue, time.Time{})
+	}
 
 	// Convert query value using ValueConverter if configured
 	queryValue, err := e.convertValue(field, n.Value)
@@ -348,9 +680,9 @@ func (e *MemoryExecutor) evaluateComparison(n *query.ComparisonNode, item reflec
 	// Evaluate operator
 	switch n.Operator {
 	case query.OpEqual:
-		return e.compareEqual(fieldValue, queryValue), nil
+		return e.compareApproxEqual(fieldValue, queryValue), nil
 	case query.OpNotEqual:
-		return !e.compareEqual(fieldValue, queryValue), nil
+		return !e.compareApproxEqual(fieldValue, queryValue), nil
 	case query.OpGreaterThan:
 		return e.compareGreater(fieldValue, queryValue, false), nil
 	case query.OpGreaterThanOrEqual:
@@ -376,11 +708,13 @@ func (e *MemoryExecutor) evaluateComparison(n *query.ComparisonNode, item reflec
 		if e.options.ExecutorOptions.DisableRegex {
 			return false, query.ErrRegexNotSupported
 		}
-		return e.evaluateRegex(fieldValue, queryValue), nil
+		return e.evaluateRegex(fieldValue, e.options.ConditionPattern(n, fmt.Sprintf("%v", queryValue)))
 	case query.OpIn:
 		return e.evaluateIn(field, fieldValue, queryValue), nil
 	case query.OpNotIn:
 		return !e.evaluateIn(field, fieldValue, queryValue), nil
+	case query.OpMatch:
+		return e.evaluateMatch(fieldValue, queryValue), nil
 	default:
 		return false, query.ErrInvalidQuery
 	}
@@ -390,7 +724,7 @@ func (e *MemoryExecutor) evaluateComparison(n *query.ComparisonNode, item reflec
 func (e *MemoryExecutor) getFieldValue(item reflect.Value, fieldName string) (interface{}, error) {
 	// Check if field is allowed (security check)
 	if !e.options.ExecutorOptions.IsFieldAllowed(fieldName) {
-		return nil, query.FieldNotAllowedError(fieldName)
+		return nil, query.FieldNotAllowedError(fieldName, e.options.ExecutorOptions.AllowedFields...)
 	}
 
 	// Use custom field getter if provided
@@ -413,7 +747,7 @@ func (e *MemoryExecutor) getFieldValue(item reflect.Value, fieldName string) (in
 			// Wrap error with appropriate operation name
 			return nil, query.NewExecutionError("custom getter", err)
 		}
-		return val, nil
+		return e.derefValue(val), nil
 	}
 
 	// Default: Use reflection
@@ -430,14 +764,14 @@ func (e *MemoryExecutor) getFieldValue(item reflect.Value, fieldName string) (in
 			field := typ.Field(i)
 			// Check field name or json/bson tag
 			if strings.EqualFold(field.Name, fieldName) {
-				return item.Field(i).Interface(), nil
+				return e.derefValue(item.Field(i).Interface()), nil
 			}
 			// Check tags
 			if tag := field.Tag.Get("json"); tag != "" && strings.EqualFold(strings.Split(tag, ",")[0], fieldName) {
-				return item.Field(i).Interface(), nil
+				return e.derefValue(item.Field(i).Interface()), nil
 			}
 			if tag := field.Tag.Get("bson"); tag != "" && strings.EqualFold(strings.Split(tag, ",")[0], fieldName) {
-				return item.Field(i).Interface(), nil
+				return e.derefValue(item.Field(i).Interface()), nil
 			}
 		}
 		return nil, query.ErrInvalidQuery
@@ -446,14 +780,14 @@ func (e *MemoryExecutor) getFieldValue(item reflect.Value, fieldName string) (in
 		// Try exact match first
 		val := item.MapIndex(reflect.ValueOf(fieldName))
 		if val.IsValid() {
-			return val.Interface(), nil
+			return e.derefValue(val.Interface()), nil
 		}
 		// Try case-insensitive
 		iter := item.MapRange()
 		for iter.Next() {
 			key := iter.Key()
 			if key.Kind() == reflect.String && strings.EqualFold(key.String(), fieldName) {
-				return iter.Value().Interface(), nil
+				return e.derefValue(iter.Value().Interface()), nil
 			}
 		}
 		return nil, query.ErrInvalidQuery
@@ -463,6 +797,30 @@ func (e *MemoryExecutor) getFieldValue(item reflect.Value, fieldName string) (in
 	}
 }
 
+// derefValue dereferences pointer field values so they compare by what they point to.
+// Nil pointers and nil interface values become nil, or with NilAsZero, nil pointers
+// become the zero value of the pointed-to type
+func (e *MemoryExecutor) derefValue(v interface{}) interface{} {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			if e.options.NilHandling == NilAsZero {
+				typ := val.Type().Elem()
+				for typ.Kind() == reflect.Ptr {
+					typ = typ.Elem()
+				}
+				return reflect.Zero(typ).Interface()
+			}
+			return nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil
+	}
+	return val.Interface()
+}
+
 // Comparison helpers
 func (e *MemoryExecutor) compareEqual(a, b interface{}) bool {
 	aFloat, aOk := e.toFloat64(a)
@@ -473,6 +831,16 @@ func (e *MemoryExecutor) compareEqual(a, b interface{}) bool {
 	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
 }
 
+// compareApproxEqual compares values for = and !=, applying FloatTolerance to float literals
+func (e *MemoryExecutor) compareApproxEqual(fieldVal, queryVal interface{}) bool {
+	if lo, hi, ok := e.options.FloatRange(queryVal); ok {
+		if f, isNum := e.toFloat64(fieldVal); isNum {
+			return f >= lo && f <= hi
+		}
+	}
+	return e.compareEqual(fieldVal, queryVal)
+}
+
 func (e *MemoryExecutor) compareGreater(a, b interface{}, orEqual bool) bool {
 	aFloat, aOk := e.toFloat64(a)
 	bFloat, bOk := e.toFloat64(b)
@@ -534,14 +902,11 @@ func (e *MemoryExecutor) evaluateLike(fieldVal, pattern interface{}) bool {
 	str := fmt.Sprintf("%v", fieldVal)
 	patternStr := fmt.Sprintf("%v", pattern)
 
-	// Convert SQL LIKE to regex
-	regexPattern := "^" + regexp.QuoteMeta(patternStr)
-	regexPattern = strings.ReplaceAll(regexPattern, "%", ".*")
-	regexPattern = strings.ReplaceAll(regexPattern, "_", ".")
-	regexPattern += "$"
-
-	matched, _ := regexp.MatchString(regexPattern, str)
-	return matched
+	re, err := query.CompileLike(patternStr)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(str)
 }
 
 func (e *MemoryExecutor) evaluateContains(field string, fieldVal, substr interface{}, caseSensitive bool) bool {
@@ -588,11 +953,37 @@ func (e *MemoryExecutor) evaluateEndsWith(fieldVal, suffix interface{}) bool {
 	return strings.HasSuffix(str, suffixStr)
 }
 
-func (e *MemoryExecutor) evaluateRegex(fieldVal, pattern interface{}) bool {
+func (e *MemoryExecutor) evaluateRegex(fieldVal interface{}, pattern string) (bool, error) {
+	if !e.regexDeadline.IsZero() && time.Now().After(e.regexDeadline) {
+		return false, fmt.Errorf("%w after %v", query.ErrRegexTimeout, e.options.RegexTimeout)
+	}
 	str := fmt.Sprintf("%v", fieldVal)
-	patternStr := fmt.Sprintf("%v", pattern)
-	matched, _ := regexp.MatchString(patternStr, str)
-	return matched
+	re, err := query.CompileRegex(pattern)
+	if err != nil {
+		// Invalid patterns match nothing
+		return false, nil
+	}
+	return re.MatchString(str), nil
+}
+
+// evaluateMatch implements full-text MATCH with tokenized matching:
+// every search term must appear as a whole term in the field value
+func (e *MemoryExecutor) evaluateMatch(fieldVal, search interface{}) bool {
+	searchTerms := query.SearchTerms(fmt.Sprintf("%v", search))
+	if len(searchTerms) == 0 {
+		return false
+	}
+
+	fieldTerms := make(map[string]struct{})
+	for _, term := range query.SearchTerms(fmt.Sprintf("%v", fieldVal)) {
+		fieldTerms[term] = struct{}{}
+	}
+	for _, term := range searchTerms {
+		if _, ok := fieldTerms[term]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
 func (e *MemoryExecutor) evaluateIn(field string, fieldVal, arrayVal interface{}) bool {
@@ -650,37 +1041,31 @@ func (e *MemoryExecutor) convertValue(field string, val interface{}) (interface{
 	return e.options.ExecutorOptions.ConvertValue(field, baseValue)
 }
 
-// sortData sorts a slice of reflect.Values
-func (e *MemoryExecutor) sortData(data []reflect.Value, sortField string, sortOrder query.SortOrder) {
-	sort.Slice(data, func(i, j int) bool {
-		valI, errI := e.getFieldValue(data[i], sortField)
-		valJ, errJ := e.getFieldValue(data[j], sortField)
-
-		if errI != nil || errJ != nil {
-			return false
-		}
-
-		less := e.compareLess(valI, valJ, false)
-		if sortOrder == query.SortOrderDesc {
-			return !less
+// sortRandom orders data by query.RandomKey of each item's ID (IDFieldName,
+// or "id") and seed, with ties kept in their original order. Items without a
+// readable ID are keyed by their position
+func (e *MemoryExecutor) sortRandom(data []reflect.Value, seed int64) {
+	idField := e.options.IDFieldName
+	if idField == "" {
+		idField = "id"
+	}
+	type keyedItem struct {
+		item reflect.Value
+		key  uint64
+	}
+	keyed := make([]keyedItem, len(data))
+	for i, item := range data {
+		var id interface{} = i
+		if val, err := e.getFieldValue(item, idField); err == nil && val != nil {
+			id = e.orderKey(val)
 		}
-		return less
-	})
-}
-
-// shuffleWithSeed shuffles data with a seed for reproducibility
-func (e *MemoryExecutor) shuffleWithSeed(data []reflect.Value, seed int64) {
-	// Simple deterministic shuffle using seed
-	if seed == 0 {
-		seed = 42
+		keyed[i] = keyedItem{item: item, key: query.RandomKey(id, seed)}
 	}
-
-	// Fisher-Yates shuffle with seeded random
-	for i := len(data) - 1; i > 0; i-- {
-		// Simple LCG random number generator
-		seed = (seed*1103515245 + 12345) & 0x7fffffff
-		j := int(seed) % (i + 1)
-		data[i], data[j] = data[j], data[i]
+	sort.SliceStable(keyed, func(i, j int) bool {
+		return keyed[i].key < keyed[j].key
+	})
+	for i, k := range keyed {
+		data[i] = k.item
 	}
 }
 
@@ -731,37 +1116,25 @@ func (e *MemoryExecutor) Close() error {
 // Count returns the total number of items that would be returned by the given query
 // This does not apply pagination - it counts all matching items
 func (e *MemoryExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
-	// Get source data from the data source function
-	data := e.dataSource()
-	dataVal := reflect.ValueOf(data)
-	if dataVal.Kind() == reflect.Ptr {
-		dataVal = dataVal.Elem()
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "count", q)
+	count, err := e.countQuery(ctx, q, &entry)
+	e.options.LogCount(ctx, entry, count, err)
+	return count, query.WrapError(e.Name(), "count", err)
+}
+
+func (e *MemoryExecutor) countQuery(ctx context.Context, q *query.Query, entry *query.QueryLog) (int64, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
 	}
-	if dataVal.Kind() != reflect.Slice {
-		return 0, query.ErrInvalidQuery
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
 	}
-
-	// Filter data
-	filtered := []reflect.Value{}
-	for i := 0; i < dataVal.Len(); i++ {
-		item := dataVal.Index(i)
-		if q.Filter == nil {
-			filtered = append(filtered, item)
-		} else {
-			match, err := e.evaluateFilter(q.Filter, item)
-			if err != nil {
-				// If error is already an ExecutionError, preserve it
-				var execErr *query.ExecutionError
-				if errors.As(err, &execErr) {
-					return 0, err
-				}
-				return 0, query.NewExecutionError("evaluate filter", err)
-			}
-			if match {
-				filtered = append(filtered, item)
-			}
-		}
+	compiled, err := e.compile(q)
+	if err != nil {
+		return 0, err
 	}
-
-	return int64(len(filtered)), nil
+	entry.Filter = compiled.q.Filter
+	return compiled.count(ctx)
 }
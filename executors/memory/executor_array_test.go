@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Gadget struct {
+	ID     int
+	Tags   []string
+	Scores []*int
+}
+
+func TestMemoryExecutor_ArrayModifiers(t *testing.T) {
+	three, five, nine := 3, 5, 9
+	data := []Gadget{
+		{ID: 1, Tags: []string{"usb", "hub", "wireless"}, Scores: []*int{&three, &nine}},
+		{ID: 2, Tags: []string{"usb", "cable"}, Scores: []*int{&five, nil}},
+		{ID: 3, Tags: []string{"audio", "wireless", "bluetooth", "portable"}},
+		{ID: 4},
+	}
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	tests := []struct {
+		input string
+		ids   []int
+	}{
+		{"tags LENGTH > 2", []int{1, 3}},
+		{"tags LENGTH = 0", []int{4}},
+		{"tags LENGTH != 2", []int{1, 3, 4}},
+		{`tags ANY = "wireless"`, []int{1, 3}},
+		{`tags ANY NOT LIKE "usb"`, []int{1, 2, 3}},
+		{"tags ANY IN [cable, audio]", []int{2, 3}},
+		{"scores ANY >= 5", []int{1, 2}},
+		{"tags ALL IN [usb, wireless]", []int{1}},
+		{"tags ALL IN []", []int{1, 2, 3, 4}},
+		{"id ANY = 1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := parser.NewParser(tt.input + " page_size = 10")
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var items []Gadget
+			_, err = executor.Execute(ctx, q, "", &items)
+			if len(tt.ids) == 0 {
+				assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+				return
+			}
+			require.NoError(t, err)
+			ids := make([]int, len(items))
+			for i, item := range items {
+				ids[i] = item.ID
+			}
+			assert.Equal(t, tt.ids, ids)
+		})
+	}
+}
+
+func TestMemoryExecutor_ArrayModifierIndex(t *testing.T) {
+	data := []Gadget{{ID: 1, Tags: []string{"usb"}}, {ID: 2, Tags: []string{"hub"}}}
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewIndexedExecutor(data, opts, WithHashIndex("tags"))
+
+	count, err := executor.Count(context.Background(), &query.Query{Filter: query.F("tags").Any().Eq("hub").Node()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
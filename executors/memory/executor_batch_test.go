@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_ExecuteBatch(t *testing.T) {
+	store := NewStore([]Product{
+		{ID: 1, Brand: "Anker", Price: 20},
+		{ID: 2, Brand: "Sony", Price: 200},
+		{ID: 3, Brand: "Anker", Price: 40},
+	})
+	calls := 0
+	source := func() interface{} {
+		calls++
+		return store.Snapshot()
+	}
+	exec := NewExecutorWithDataSource(source, nil)
+
+	anker := &query.Query{
+		Filter:   &query.ComparisonNode{Field: "brand", Operator: query.OpEqual, Value: query.StringValue("Anker")},
+		PageSize: 10,
+	}
+	missing := &query.Query{
+		Filter:   &query.ComparisonNode{Field: "brand", Operator: query.OpEqual, Value: query.StringValue("JBL")},
+		PageSize: 10,
+	}
+	var ankers, none, all []Product
+	results, err := executor.ExecuteBatch(context.Background(), exec,
+		[]*query.Query{anker, missing, {PageSize: 10}},
+		[]interface{}{&ankers, &none, &all})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, 1, calls, "the batch reads one snapshot")
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, int64(2), results[0].Result.TotalItems)
+	assert.Len(t, ankers, 2)
+	assert.ErrorIs(t, results[1].Err, query.ErrNoRecordsFound)
+	require.NoError(t, results[2].Err)
+	assert.Len(t, all, 3)
+
+	_, err = exec.ExecuteBatch(context.Background(), []*query.Query{anker}, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidDestination)
+}
@@ -248,3 +248,46 @@ func TestMemoryExecutor_Count(t *testing.T) {
 		assert.Equal(t, int64(0), count)
 	})
 }
+
+func TestMemoryExecutor_CountModes(t *testing.T) {
+	data := getTestProductsForCount()
+	ctx := context.Background()
+
+	t.Run("none skips the total", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.CountMode = query.CountNone
+		executor := NewExecutor(data, opts)
+
+		p, _ := parser.NewParser("category = electronics page_size = 2")
+		q, _ := p.Parse()
+
+		var products []Product
+		result, err := executor.Execute(ctx, q, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, query.TotalUnknown, result.TotalItems)
+		assert.Len(t, products, 2)
+		assert.NotEmpty(t, result.NextPageCursor)
+
+		p, _ = parser.NewParser("category = nonexistent")
+		q, _ = p.Parse()
+		_, err = executor.Execute(ctx, q, "", &products)
+		assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+	})
+
+	for _, mode := range []query.CountMode{query.CountEstimated, query.CountAsync} {
+		t.Run(mode.String()+" counts exactly", func(t *testing.T) {
+			opts := query.DefaultExecutorOptions()
+			opts.CountMode = mode
+			executor := NewExecutor(data, opts)
+
+			p, _ := parser.NewParser("category = clothing")
+			q, _ := p.Parse()
+
+			var products []Product
+			result, err := executor.Execute(ctx, q, "", &products)
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), result.TotalItems)
+			assert.False(t, result.TotalItemsEstimated)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_DistinctOn(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(getTestData(), opts)
+	ctx := context.Background()
+
+	p, err := parser.NewParser("distinct_on = brand sort_by = price sort_order = desc page_size = 4")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	// One item per brand, the most expensive one
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), result.TotalItems)
+	require.Len(t, page, 4)
+	assert.Equal(t, "Sony", page[0].Brand)
+	assert.Equal(t, "Corsair", page[1].Brand)
+	assert.Equal(t, "Logitech", page[2].Brand)
+	assert.Equal(t, 69.99, page[2].Price)
+	assert.Equal(t, "JBL", page[3].Brand)
+
+	var next []Product
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &next)
+	require.NoError(t, err)
+	require.Len(t, next, 3)
+	assert.Equal(t, "Anker", next[0].Brand)
+	assert.Equal(t, 39.99, next[0].Price)
+	assert.Empty(t, result.NextPageCursor)
+
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+}
+
+func TestMemoryExecutor_Distinct(t *testing.T) {
+	data := getTestData()
+	data = append(data, data[0], data[1])
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	q := &query.Query{Distinct: true, PageSize: 100}
+	var items []Product
+	result, err := executor.Execute(ctx, q, "", &items)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)-2), result.TotalItems)
+	assert.Len(t, items, len(data)-2)
+
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)-2), count)
+}
+
+func TestMemoryExecutor_DistinctOnNotAllowed(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.AllowedFields = []string{"id", "name"}
+	executor := NewExecutor(getTestData(), opts)
+
+	q := &query.Query{DistinctOn: "brand"}
+	var items []Product
+	_, err := executor.Execute(context.Background(), q, "", &items)
+	assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+	_, err = executor.Count(context.Background(), q)
+	assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_ErrorModel(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.AllowedFields = []string{"id", "name", "brand"}
+	executor := NewExecutor(getTestData(), opts)
+	ctx := context.Background()
+
+	t.Run("empty result", func(t *testing.T) {
+		var products []Product
+		result, err := executor.Execute(ctx, &query.Query{Filter: query.Eq("brand", "Nobody")}, "", &products)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
+		assert.Equal(t, err, result.Error)
+
+		var qerr *query.Error
+		require.True(t, errors.As(err, &qerr))
+		assert.Equal(t, "memory", qerr.Backend)
+		assert.Equal(t, "execute", qerr.Op)
+	})
+
+	t.Run("disallowed field", func(t *testing.T) {
+		var products []Product
+		_, err := executor.Execute(ctx, &query.Query{Filter: query.Eq("price", 10)}, "", &products)
+		require.ErrorIs(t, err, query.ErrFieldNotAllowed)
+
+		var qerr *query.Error
+		require.True(t, errors.As(err, &qerr))
+		assert.Equal(t, "price", qerr.Field)
+		// Like the database executors, this is not an execution failure
+		var execErr *query.ExecutionError
+		assert.False(t, errors.As(err, &execErr))
+
+		_, err = executor.Count(ctx, &query.Query{Filter: query.Eq("price", 10)})
+		require.True(t, errors.As(err, &qerr))
+		assert.Equal(t, "count", qerr.Op)
+		assert.Equal(t, "price", qerr.Field)
+	})
+
+	t.Run("compiled query", func(t *testing.T) {
+		compiled, err := executor.Compile(&query.Query{Filter: query.Eq("price", 10)})
+		require.NoError(t, err)
+		var products []Product
+		_, err = compiled.Execute(ctx, "", &products)
+
+		var qerr *query.Error
+		require.True(t, errors.As(err, &qerr))
+		assert.Equal(t, "memory", qerr.Backend)
+	})
+
+	t.Run("AllowEmptyResults", func(t *testing.T) {
+		allowOpts := query.DefaultExecutorOptions()
+		allowOpts.AllowEmptyResults = true
+		var products []Product
+		result, err := NewExecutor(getTestData(), allowOpts).Execute(ctx, &query.Query{Filter: query.Eq("brand", "Nobody")}, "", &products)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), result.TotalItems)
+		assert.Empty(t, products)
+	})
+
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/decorators"
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_Facets(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	exec := NewExecutor(getTestData(), opts)
+	ctx := context.Background()
+
+	p, err := parser.NewParser("category = accessories page_size = 1")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	specs := []query.FacetSpec{
+		{Field: "brand"},
+		{Field: "brand", Limit: 2},
+		{Field: "price", Ranges: []query.FacetRange{
+			{To: 20},
+			{Label: "mid", From: 20, To: 35},
+			{From: 35},
+		}},
+	}
+	results, err := exec.Facets(ctx, q, specs)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "brand", results[0].Field)
+	assert.Equal(t, []query.FacetBucket{
+		{Value: "Anker", Count: 3},
+		{Value: "AmazonBasics", Count: 1},
+		{Value: "Razer", Count: 1},
+	}, results[0].Buckets)
+	assert.Equal(t, results[0].Buckets[:2], results[1].Buckets)
+	assert.Equal(t, []query.FacetBucket{
+		{Value: "*-20", Count: 2},
+		{Value: "mid", Count: 2},
+		{Value: "35-*", Count: 1},
+	}, results[2].Buckets)
+
+	t.Run("without filter", func(t *testing.T) {
+		results, err := exec.Facets(ctx, &query.Query{}, []query.FacetSpec{{Field: "category"}})
+		require.NoError(t, err)
+		assert.Equal(t, []query.FacetBucket{
+			{Value: "accessories", Count: 5},
+			{Value: "electronics", Count: 5},
+		}, results[0].Buckets)
+	})
+
+	t.Run("histograms", func(t *testing.T) {
+		results, err := exec.Facets(ctx, q, []query.FacetSpec{
+			{Field: "CreatedAt", DateInterval: query.DateIntervalWeek},
+			{Field: "price", Interval: 20},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []query.FacetBucket{
+			{Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Count: 4},
+			{Value: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), Count: 1},
+		}, results[0].Buckets)
+		assert.Equal(t, []query.FacetBucket{
+			{Value: 0.0, Count: 2},
+			{Value: 20.0, Count: 3},
+		}, results[1].Buckets)
+	})
+
+	t.Run("invalid facets", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.AllowedFields = []string{"category", "price"}
+		exec := NewExecutor(getTestData(), opts)
+
+		_, err := exec.Facets(ctx, q, []query.FacetSpec{{Field: "brand"}})
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+		_, err = exec.Facets(ctx, q, []query.FacetSpec{{Field: "price", Ranges: []query.FacetRange{{From: "cheap"}}}})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		_, err = exec.Facets(ctx, q, []query.FacetSpec{{Field: "price", Ranges: []query.FacetRange{{}}}})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		_, err = exec.Facets(ctx, q, []query.FacetSpec{{Field: "price", Interval: -5}})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		_, err = exec.Facets(ctx, q, []query.FacetSpec{{Field: "price", DateInterval: "fortnight"}})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+
+	t.Run("through decorators", func(t *testing.T) {
+		scoped := decorators.Chain(exec, decorators.WithBaseFilter(query.Eq("brand", "Anker")))
+		results, err := executor.Facets(ctx, scoped, &query.Query{}, []query.FacetSpec{{Field: "category"}})
+		require.NoError(t, err)
+		assert.Equal(t, []query.FacetBucket{{Value: "accessories", Count: 3}}, results[0].Buckets)
+	})
+}
@@ -297,7 +297,7 @@ func TestMemoryExecutor_LimitEdgeCases(t *testing.T) {
 
 		var products []Product
 		result, err := executor.Execute(ctx, q, "", &products)
-		require.NoError(t, err)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
 		assert.Equal(t, 0, len(products))
 		assert.Equal(t, "", result.NextPageCursor)
 	})
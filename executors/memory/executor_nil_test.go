@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nullableProduct struct {
+	ID    int
+	Name  *string
+	Price *float64
+}
+
+func nullableProducts() []nullableProduct {
+	name := func(s string) *string { return &s }
+	price := func(f float64) *float64 { return &f }
+	return []nullableProduct{
+		{ID: 1, Name: name("Mouse"), Price: price(20)},
+		{ID: 2, Name: nil, Price: nil},
+		{ID: 3, Name: name("Keyboard"), Price: price(50)},
+		{ID: 4, Name: name(""), Price: price(0)},
+	}
+}
+
+func runNilQuery(t *testing.T, opts *MemoryExecutorOptions, data interface{}, input string) []int {
+	t.Helper()
+	p, err := parser.NewParser(input)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	var results []nullableProduct
+	executor := NewExecutorWithOptions(data, opts)
+	_, err = executor.Execute(context.Background(), q, "", &results)
+	require.NoError(t, err)
+
+	ids := make([]int, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestMemoryExecutor_PointerFields(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	memOpts := &MemoryExecutorOptions{ExecutorOptions: opts}
+	data := nullableProducts()
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"equality dereferences", `name = Mouse`, []int{1}},
+		{"numeric comparison dereferences", `price > 10`, []int{1, 3}},
+		{"not equal skips nil", `name != Mouse`, []int{3, 4}},
+		{"nil does not match empty string", `name = ""`, []int{4}},
+		{"not in skips nil", `price NOT IN [20]`, []int{3, 4}},
+		{"contains skips nil", `name CONTAINS "e"`, []int{1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, runNilQuery(t, memOpts, data, tt.query))
+		})
+	}
+}
+
+func TestMemoryExecutor_NilAsZero(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	memOpts := &MemoryExecutorOptions{ExecutorOptions: opts, NilHandling: NilAsZero}
+	data := nullableProducts()
+
+	assert.Equal(t, []int{2, 4}, runNilQuery(t, memOpts, data, `name = ""`))
+	assert.Equal(t, []int{2, 3, 4}, runNilQuery(t, memOpts, data, `name != Mouse`))
+	assert.Equal(t, []int{2, 4}, runNilQuery(t, memOpts, data, `price <= 0`))
+}
+
+func TestMemoryExecutor_NilSortsLast(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	memOpts := &MemoryExecutorOptions{ExecutorOptions: opts}
+	data := nullableProducts()
+
+	assert.Equal(t, []int{4, 1, 3, 2}, runNilQuery(t, memOpts, data, `sort_by = price`))
+	assert.Equal(t, []int{3, 1, 4, 2}, runNilQuery(t, memOpts, data, `sort_by = price sort_order = desc`))
+}
+
+func TestMemoryExecutor_NilMapValues(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": 1, "name": "Mouse"},
+		{"id": 2, "name": nil},
+	}
+	executor := NewExecutor(data, query.DefaultExecutorOptions())
+
+	count, err := executor.Count(context.Background(), query.F("name").Ne("Keyboard").Build())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
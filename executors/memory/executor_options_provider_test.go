@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_OptionsProvider(t *testing.T) {
+	data := getTestData()
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	provider := query.NewReloadableOptions(opts)
+	executor := NewExecutorWithOptionsProvider(func() interface{} { return data }, provider, nil)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser("category = electronics page_size = 3")
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	t.Run("uses initial options", func(t *testing.T) {
+		var results []Product
+		result, err := executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.ItemsReturned)
+	})
+
+	t.Run("page cap change applies without recreating executor", func(t *testing.T) {
+		updated := query.DefaultExecutorOptions()
+		updated.DefaultSortField = "id"
+		updated.MaxPageSize = 2
+		provider.Set(updated)
+
+		var results []Product
+		result, err := executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.ItemsReturned)
+	})
+
+	t.Run("allowlist change applies to Execute and Count", func(t *testing.T) {
+		updated := query.DefaultExecutorOptions()
+		updated.DefaultSortField = "id"
+		updated.AllowedFields = []string{"id", "name"}
+		provider.Set(updated)
+
+		var results []Product
+		_, err := executor.Execute(ctx, q, "", &results)
+		assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+
+		_, err = executor.Count(ctx, q)
+		assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+	})
+}
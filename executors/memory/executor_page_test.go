@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_PageJump(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.MaxPageOffset = 6
+	executor := NewExecutor(getTestData(), opts)
+	ctx := context.Background()
+
+	parse := func(input string) *query.Query {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		return q
+	}
+	ids := func(products []Product) []int {
+		var ids []int
+		for _, p := range products {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	q := parse("page = 3 page_size = 3 sort_by = id")
+	var products []Product
+	result, err := executor.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	assert.Equal(t, []int{7, 8, 9}, ids(products))
+	assert.Equal(t, 7, result.ShowingFrom)
+	assert.Equal(t, 9, result.ShowingTo)
+
+	t.Run("cursors continue from the page", func(t *testing.T) {
+		var next []Product
+		_, err := executor.Execute(ctx, q, result.NextPageCursor, &next)
+		require.NoError(t, err)
+		assert.Equal(t, []int{10}, ids(next))
+
+		var prev []Product
+		_, err = executor.Execute(ctx, q, result.PrevPageCursor, &prev)
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 5, 6}, ids(prev))
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		_, err := executor.Execute(ctx, parse("page = 4 page_size = 3 sort_by = id"), "", &products)
+		assert.True(t, errors.Is(err, query.ErrPageTooDeep))
+	})
+
+	t.Run("past the last item", func(t *testing.T) {
+		opts := *opts
+		opts.MaxPageOffset = 0
+		var empty []Product
+		result, err := NewExecutor(getTestData(), &opts).Execute(ctx, parse("page = 5 page_size = 3 sort_by = id"), "", &empty)
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+		assert.Empty(t, result.NextPageCursor)
+	})
+
+	t.Run("limit counts skipped items", func(t *testing.T) {
+		var limited []Product
+		result, err := executor.Execute(ctx, parse("page = 2 page_size = 3 limit = 5 sort_by = id"), "", &limited)
+		require.NoError(t, err)
+		assert.Equal(t, []int{4, 5}, ids(limited))
+		assert.Empty(t, result.NextPageCursor)
+	})
+}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type brandKey struct{}
+
+func TestMemoryExecutor_Placeholders(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.Placeholders = map[string]query.PlaceholderResolver{
+		"my_brand": func(ctx context.Context) (interface{}, error) {
+			brand, ok := ctx.Value(brandKey{}).(string)
+			if !ok {
+				return nil, errors.New("no brand in context")
+			}
+			return brand, nil
+		},
+	}
+	executor := NewExecutor(getTestData(), opts)
+
+	// One saved query serves every requester
+	p, err := parser.NewParser("brand = @my_brand")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	for brand, expected := range map[string]int{"Anker": 3, "Logitech": 2} {
+		ctx := context.WithValue(context.Background(), brandKey{}, brand)
+		var results []Product
+		result, err := executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.ItemsReturned, brand)
+
+		count, err := executor.Count(ctx, q)
+		require.NoError(t, err)
+		assert.Equal(t, int64(expected), count, brand)
+	}
+
+	var results []Product
+	_, err = executor.Execute(context.Background(), q, "", &results)
+	assert.ErrorContains(t, err, "no brand in context")
+
+	filter, err := parser.ParseFilter("brand = @unknown")
+	require.NoError(t, err)
+	_, err = executor.Count(context.Background(), &query.Query{Filter: filter})
+	assert.True(t, errors.Is(err, query.ErrUnknownPlaceholder))
+}
+
+func TestMemoryExecutor_Parameters(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(getTestData(), opts)
+	ctx := context.Background()
+
+	// The template is parsed once and bound per request
+	cache := parser.NewParserCache(10)
+	tmpl, err := cache.Parse("brand IN :brands AND price < :max_price")
+	require.NoError(t, err)
+
+	tests := []struct {
+		params   map[string]interface{}
+		expected []int
+	}{
+		{map[string]interface{}{"brands": []string{"Anker"}, "max_price": 30}, []int{3, 7}},
+		{map[string]interface{}{"brands": []string{"Logitech", "Sony"}, "max_price": 500.0}, []int{1, 4, 9}},
+	}
+	for _, tt := range tests {
+		q, err := tmpl.Bind(tt.params)
+		require.NoError(t, err)
+
+		var results []Product
+		_, err = executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+		var ids []int
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		assert.Equal(t, tt.expected, ids)
+	}
+
+	// Unbound templates are rejected
+	var results []Product
+	_, err = executor.Execute(ctx, tmpl, "", &results)
+	assert.True(t, errors.Is(err, query.ErrUnboundParameter))
+}
+
+func TestMemoryExecutor_RelativeTimes(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.Clock = func() time.Time { return now }
+	executor := NewExecutor(getTestData(), opts)
+	ctx := context.Background()
+
+	p, err := parser.NewParser("createdat > now-5d page_size = 3")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), result.TotalItems)
+	require.Len(t, page, 3)
+	assert.Equal(t, 6, page[0].ID)
+
+	// The next page resolves now again, and the cursor still matches the query
+	now = now.Add(time.Hour)
+	var next []Product
+	result, err = executor.Execute(ctx, q, result.NextPageCursor, &next)
+	require.NoError(t, err)
+	require.Len(t, next, 2)
+	assert.Equal(t, 9, next[0].ID)
+
+	// A day later the oldest item no longer matches
+	now = now.AddDate(0, 0, 1)
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_QueryLogger(t *testing.T) {
+	var logged []query.QueryLog
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.BaseFilter = query.Gt("stock", 0)
+	opts.Placeholders = map[string]query.PlaceholderResolver{
+		"my_brand": func(ctx context.Context) (interface{}, error) { return "Anker", nil },
+	}
+	opts.QueryLogger = query.QueryLoggerFunc(func(ctx context.Context, entry query.QueryLog) {
+		logged = append(logged, entry)
+	})
+	executor := NewExecutor(getTestData(), opts)
+	ctx := context.Background()
+
+	p, err := parser.NewParser("brand = @my_brand")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	var results []Product
+	result, err := executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+
+	// Each call is logged once, with the filter that actually ran
+	require.Len(t, logged, 2)
+	assert.Equal(t, "execute", logged[0].Operation)
+	assert.Equal(t, `brand = "Anker" AND stock > 0`, logged[0].Query)
+	assert.Equal(t, result.ItemsReturned, logged[0].ItemsReturned)
+	assert.Equal(t, "count", logged[1].Operation)
+	assert.Equal(t, logged[0].Query, logged[1].Query)
+	assert.Equal(t, count, logged[1].TotalItems)
+
+	t.Run("compiled query", func(t *testing.T) {
+		logged = nil
+		// Compiled queries do not resolve placeholders
+		compiled, err := executor.Compile(&query.Query{Filter: query.Eq("brand", "Anker")})
+		require.NoError(t, err)
+		_, err = compiled.Execute(ctx, "", &results)
+		require.NoError(t, err)
+		require.Len(t, logged, 1)
+		assert.Equal(t, "memory", logged[0].Backend)
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		logged = nil
+		redacted := *opts
+		redacted.RedactQueryLog = true
+		executor := NewExecutor(getTestData(), &redacted)
+		_, err := executor.Count(ctx, q)
+		require.NoError(t, err)
+		require.Len(t, logged, 1)
+		assert.Equal(t, "brand = ? AND stock > ?", logged[0].Query)
+		assert.Nil(t, logged[0].Filter)
+	})
+}
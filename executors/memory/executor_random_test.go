@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_SeededRandomOrder(t *testing.T) {
+	ctx := context.Background()
+	data := getTestData()
+	reversed := make([]Product, len(data))
+	for i, p := range data {
+		reversed[len(data)-1-i] = p
+	}
+
+	// pages collects the IDs of every page of q
+	pages := func(data []Product, q *query.Query) []int {
+		exec := NewExecutor(data, query.DefaultExecutorOptions())
+		var ids []int
+		cursor := ""
+		for {
+			var products []Product
+			result, err := exec.Execute(ctx, q, cursor, &products)
+			require.NoError(t, err)
+			for _, p := range products {
+				ids = append(ids, p.ID)
+			}
+			if result.NextPageCursor == "" {
+				return ids
+			}
+			cursor = result.NextPageCursor
+		}
+	}
+
+	seeded := query.Where(nil).Random(7).PageSize(3).Build()
+	first := pages(data, seeded)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, first)
+	assert.NotEqual(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, first)
+
+	// The order depends on the seed and the IDs, not on how items are stored
+	assert.Equal(t, first, pages(reversed, seeded))
+	assert.NotEqual(t, first, pages(data, query.Where(nil).Random(8).PageSize(3).Build()))
+
+	// Without a seed, cursors carry the first page's seed
+	assert.ElementsMatch(t, first, pages(data, query.Where(nil).Random(0).PageSize(3).Build()))
+}
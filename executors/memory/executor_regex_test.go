@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_RegexSafety(t *testing.T) {
+	users := []User{
+		{ID: 1, Name: "Alice"},
+		{ID: 2, Name: "Alicia"},
+		{ID: 3, Name: "Bob"},
+	}
+	ctx := context.Background()
+
+	t.Run("unsafe pattern rejected", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.SafeRegex = true
+		executor := NewExecutor(users, opts)
+
+		var results []User
+		_, err := executor.Execute(ctx, query.F("name").Regex(`(a+)+$`).Build(), "", &results)
+		assert.True(t, errors.Is(err, query.ErrUnsafeRegex))
+	})
+
+	t.Run("anchored pattern", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.DefaultSortField = "id"
+		opts.AnchorRegex = true
+		executor := NewExecutor(users, opts)
+
+		var results []User
+		_, err := executor.Execute(ctx, query.F("name").Regex(`Ali`).Build(), "", &results)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
+		assert.Empty(t, results)
+
+		_, err = executor.Execute(ctx, query.F("name").Regex(`Ali.*`).Build(), "", &results)
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.RegexTimeout = time.Nanosecond
+		executor := NewExecutor(users, opts)
+
+		_, err := executor.Count(ctx, query.F("name").Regex(`^B`).Build())
+		assert.True(t, errors.Is(err, query.ErrRegexTimeout))
+	})
+}
@@ -317,6 +317,202 @@ func TestMemoryExecutor_BareSearch(t *testing.T) {
 	}
 }
 
+func TestMemoryExecutor_MultiFieldBareSearch(t *testing.T) {
+	data := getTestData()
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.DefaultSearchFields = []string{"name", "description"}
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []int
+	}{
+		{"term found in description only", "mouse", []int{1, 5}},
+		{"term found in name only", "Gaming", []int{5}},
+		{"terms matched across fields", "Wireless pad", []int{6}},
+		{"combined with field filter", "wireless category = accessories", []int{6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parser.NewParser(tt.query)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var results []Product
+			_, err = executor.Execute(ctx, q, "", &results)
+			require.NoError(t, err)
+
+			var ids []int
+			for _, r := range results {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+
+	t.Run("weighted relevance", func(t *testing.T) {
+		weighted := *opts
+		weighted.SearchFieldWeights = map[string]float64{"description": 2}
+		executor := NewExecutor(data, &weighted)
+
+		p, _ := parser.NewParser("mouse sort_by = _score")
+		q, _ := p.Parse()
+
+		var results []Product
+		result, err := executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 1, results[0].ID)
+		// Scoring ignores case: name "Wireless Mouse" = 1/2, description = 1/3 weighted by 2
+		assert.InDelta(t, 1.0/2.0+2.0/3.0, result.Scores[0], 1e-9)
+	})
+}
+
+func TestMemoryExecutor_PhraseSearch(t *testing.T) {
+	data := getTestData()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		mode     query.PhraseMatch
+		anchor   bool
+		noRegex  bool
+		query    string
+		expected []int
+	}{
+		{"substring", query.PhraseMatchSubstring, false, false, `"wireless charg"`, []int{6}},
+		{"whole words", query.PhraseMatchWords, false, false, `"wireless charg"`, nil},
+		{"whole words match", query.PhraseMatchWords, false, false, `"wireless  charging"`, []int{6}},
+		{"whole words with anchored regex", query.PhraseMatchWords, true, false, `"mouse pad"`, []int{5}},
+		{"whole words without regex", query.PhraseMatchWords, false, true, `"wireless charg"`, []int{6}},
+		{"unquoted terms are not a phrase", query.PhraseMatchWords, false, false, `wireless charg`, []int{6}},
+		{"terms", query.PhraseMatchTerms, false, false, `"pad wireless"`, []int{6}},
+		{"substring keeps order", query.PhraseMatchSubstring, false, false, `"pad wireless"`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := query.DefaultExecutorOptions()
+			opts.DefaultSortField = "id"
+			opts.DefaultSearchFields = []string{"name", "description"}
+			opts.PhraseMatch = tt.mode
+			opts.AnchorRegex = tt.anchor
+			opts.DisableRegex = tt.noRegex
+			executor := NewExecutor(data, opts)
+
+			p, err := parser.NewParser(tt.query)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var results []Product
+			_, err = executor.Execute(ctx, q, "", &results)
+			if tt.expected == nil {
+				assert.ErrorIs(t, err, query.ErrNoRecordsFound)
+				return
+			}
+			require.NoError(t, err)
+
+			var ids []int
+			for _, r := range results {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestMemoryExecutor_SearchTermExpander(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.DefaultSearchFields = []string{"name", "description"}
+	opts.SearchTermExpander = func(term string) []string {
+		if term == "mice" {
+			return []string{"mouse"}
+		}
+		return nil
+	}
+	executor := NewExecutor(getTestData(), opts)
+
+	p, err := parser.NewParser("mice price < 25")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	var results []Product
+	_, err = executor.Execute(context.Background(), q, "", &results)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 5, results[0].ID)
+}
+
+func TestMemoryExecutor_StopWords(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.DefaultSearchFields = []string{"name", "description"}
+	opts.StopWords = []string{"a", "the", "of"}
+	executor := NewExecutor(getTestData(), opts)
+
+	p, err := parser.NewParser("a the mouse")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	var results []Product
+	result, err := executor.Execute(context.Background(), q, "", &results)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, query.WarningIgnoredTerms, result.Warnings[0].Code)
+	assert.Equal(t, []string{"a", "the"}, result.Warnings[0].Terms)
+
+	p, err = parser.NewParser("a the of")
+	require.NoError(t, err)
+	q, err = p.Parse()
+	require.NoError(t, err)
+	_, err = executor.Execute(context.Background(), q, "", &results)
+	assert.ErrorIs(t, err, query.ErrSearchTermsIgnored)
+}
+
+func TestMemoryExecutor_Highlights(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.DefaultSearchFields = []string{"name", "description"}
+	executor := NewExecutor(getTestData(), opts)
+
+	p, err := parser.NewParser(`USB brand = Anker`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	q.HighlightRequested = true
+
+	var results []Product
+	result, err := executor.Execute(context.Background(), q, "", &results)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, [][]query.Highlight{
+		{{Field: "description", Start: 11, End: 14}, {Field: "name", Start: 0, End: 3}},
+		{{Field: "description", Start: 7, End: 10}, {Field: "name", Start: 0, End: 3}},
+	}, result.Highlights)
+
+	p, err = parser.NewParser(`name MATCH "wireless mouse"`)
+	require.NoError(t, err)
+	q, err = p.Parse()
+	require.NoError(t, err)
+	result, err = executor.Execute(context.Background(), q, "", &results)
+	require.NoError(t, err)
+	assert.Nil(t, result.Highlights, "only populated on request")
+	q.HighlightRequested = true
+	result, err = executor.Execute(context.Background(), q, "", &results)
+	require.NoError(t, err)
+	assert.Equal(t, [][]query.Highlight{{{Field: "name", Start: 0, End: 8}, {Field: "name", Start: 9, End: 14}}}, result.Highlights)
+}
+
 func TestMemoryExecutor_Pagination(t *testing.T) {
 	data := getTestData()
 	opts := query.DefaultExecutorOptions()
@@ -461,6 +657,25 @@ func TestMemoryExecutor_MapData(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, 30.0, results[0]["price"])
 	})
+
+	t.Run("quoted keys", func(t *testing.T) {
+		data := []map[string]interface{}{
+			{"id": 1, "order date": "2024-01-15", "unit-price": 5.0},
+			{"id": 2, "order date": "2024-02-15", "unit-price": 15.0},
+		}
+		executor := NewExecutor(data, query.DefaultExecutorOptions())
+
+		p, err := parser.NewParser("`order date` >= \"2024-02-01\" OR [\"unit-price\"] < 10 sort_by = `order date`")
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var results []map[string]interface{}
+		_, err = executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 1, results[0]["id"])
+	})
 }
 
 func TestMemoryExecutor_EdgeCases(t *testing.T) {
@@ -474,7 +689,7 @@ func TestMemoryExecutor_EdgeCases(t *testing.T) {
 
 		var products []Product
 		result, err := executor.Execute(ctx, q, "", &products)
-		require.NoError(t, err)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
 		assert.Equal(t, 0, len(products))
 		assert.Equal(t, int64(0), result.TotalItems)
 	})
@@ -499,7 +714,7 @@ func TestMemoryExecutor_EdgeCases(t *testing.T) {
 
 		var products []Product
 		result, err := emptyExecutor.Execute(ctx, q, "", &products)
-		require.NoError(t, err)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
 		assert.Equal(t, 0, len(products))
 		assert.Equal(t, int64(0), result.TotalItems)
 	})
@@ -520,7 +735,7 @@ func TestMemoryExecutor_EdgeCases(t *testing.T) {
 
 		var products []Product
 		_, err := executor.Execute(ctx, q, "", &products)
-		require.NoError(t, err)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
 		assert.Equal(t, 0, len(products))
 	})
 }
@@ -570,3 +785,264 @@ func TestMemoryExecutor_ComplexQueries(t *testing.T) {
 		}
 	})
 }
+
+func TestMemoryExecutor_CursorQueryBinding(t *testing.T) {
+	data := getTestData()
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser("category = electronics page_size = 2")
+	q, _ := p.Parse()
+
+	var page1 []Product
+	result, err := executor.Execute(ctx, q, "", &page1)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.NextPageCursor)
+
+	t.Run("same query accepts cursor", func(t *testing.T) {
+		var page2 []Product
+		_, err := executor.Execute(ctx, q, result.NextPageCursor, &page2)
+		require.NoError(t, err)
+	})
+
+	t.Run("different query rejects cursor", func(t *testing.T) {
+		p, _ := parser.NewParser("category = accessories page_size = 2")
+		other, _ := p.Parse()
+
+		var page2 []Product
+		_, err := executor.Execute(ctx, other, result.NextPageCursor, &page2)
+		assert.ErrorIs(t, err, query.ErrCursorQueryMismatch)
+	})
+}
+
+func TestMemoryExecutor_MatchOperator(t *testing.T) {
+	data := getTestData()
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.AllowEmptyResults = true
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []int
+	}{
+		{"single term", `description MATCH "wireless"`, []int{1, 6}},
+		{"all terms required in any order", `description MATCH "cancelling noise"`, []int{4}},
+		{"whole terms only", `description MATCH "wire"`, nil},
+		{"case and punctuation ignored", `description MATCH "USB-C!"`, []int{3}},
+		{"no match when a term is missing", `description MATCH "wireless keyboard"`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parser.NewParser(tt.input)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var results []Product
+			_, err = executor.Execute(ctx, q, "", &results)
+			require.NoError(t, err)
+
+			var ids []int
+			for _, r := range results {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestMemoryExecutor_ScoreSorting(t *testing.T) {
+	data := getTestData()
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	p, err := parser.NewParser(`description MATCH "mouse" sort_by = _score`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, query.ScoreField, q.SortBy)
+
+	var results []Product
+	result, err := executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+
+	// "Ergonomic wireless mouse" (1 of 3 terms) ranks above "Large extended mouse pad" (1 of 4)
+	require.Len(t, results, 2)
+	assert.Equal(t, 1, results[0].ID)
+	assert.Equal(t, 5, results[1].ID)
+	require.Len(t, result.Scores, 2)
+	assert.InDelta(t, 1.0/3.0, result.Scores[0], 1e-9)
+	assert.InDelta(t, 0.25, result.Scores[1], 1e-9)
+
+	t.Run("scores follow pagination", func(t *testing.T) {
+		q.PageSize = 1
+		var page1 []Product
+		result, err := executor.Execute(ctx, q, "", &page1)
+		require.NoError(t, err)
+		require.Len(t, result.Scores, 1)
+		assert.Equal(t, 1, page1[0].ID)
+
+		var page2 []Product
+		result, err = executor.Execute(ctx, q, result.NextPageCursor, &page2)
+		require.NoError(t, err)
+		require.Len(t, page2, 1)
+		assert.Equal(t, 5, page2[0].ID)
+		assert.InDelta(t, 0.25, result.Scores[0], 1e-9)
+	})
+
+	t.Run("no scores for regular sorting", func(t *testing.T) {
+		p, _ := parser.NewParser(`description MATCH "mouse"`)
+		q, _ := p.Parse()
+		var results []Product
+		result, err := executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+		assert.Nil(t, result.Scores)
+	})
+}
+
+func TestMemoryExecutor_MatchCountSorting(t *testing.T) {
+	executor := NewExecutor(getTestData(), query.DefaultExecutorOptions())
+	ctx := context.Background()
+
+	ids := func(products []Product) []int {
+		out := make([]int, len(products))
+		for i, p := range products {
+			out[i] = p.ID
+		}
+		return out
+	}
+
+	p, err := parser.NewParser(`name ICONTAINS wireless OR description ICONTAINS pad OR name CONTAINS Mouse OR description CONTAINS mouse sort_by = _matches page_size = 2`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	// 1 and 5 match three conditions, 6 two and 4 one; ties keep the source order
+	var page1 []Product
+	result, err := executor.Execute(ctx, q, "", &page1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 5}, ids(page1))
+	assert.Equal(t, int64(4), result.TotalItems)
+
+	var page2 []Product
+	_, err = executor.Execute(ctx, q, result.NextPageCursor, &page2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{6, 4}, ids(page2))
+
+	t.Run("requires a CONTAINS condition", func(t *testing.T) {
+		p, _ := parser.NewParser(`price < 50 sort_by = _matches`)
+		q, _ := p.Parse()
+		var results []Product
+		_, err := executor.Execute(ctx, q, "", &results)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+}
+
+func TestMemoryExecutor_FloatTolerance(t *testing.T) {
+	tenth, fifth := 0.1, 0.2
+	data := []map[string]interface{}{
+		{"id": 1, "price": tenth + fifth}, // 0.30000000000000004
+		{"id": 2, "price": 0.3},
+		{"id": 3, "price": 0.31},
+	}
+	ctx := context.Background()
+
+	run := func(executor *MemoryExecutor, input string) []interface{} {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var results []map[string]interface{}
+		_, err = executor.Execute(ctx, q, "", &results)
+		require.NoError(t, err)
+
+		var ids []interface{}
+		for _, r := range results {
+			ids = append(ids, r["id"])
+		}
+		return ids
+	}
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	exact := NewExecutor(data, opts)
+	assert.Equal(t, []interface{}{2}, run(exact, "price = 0.3"))
+
+	tolerant := *opts
+	tolerant.FloatTolerance = 1e-9
+	executor := NewExecutor(data, &tolerant)
+	assert.Equal(t, []interface{}{1, 2}, run(executor, "price = 0.3"))
+	assert.Equal(t, []interface{}{3}, run(executor, "price != 0.3"))
+	assert.Equal(t, []interface{}{1, 3}, run(executor, "price > 0.3"), "ordering operators stay exact")
+}
+
+func TestMemoryExecutor_PreserveInOrder(t *testing.T) {
+	data := getTestData()
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	parse := func(input string) *query.Query {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		return q
+	}
+	ids := func(products []Product) []int {
+		var out []int
+		for _, p := range products {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+
+	q := parse(`ID IN [5, 1, 9, 42, 3] AND Stock > 0 preserve_in_order = true`)
+	assert.True(t, q.PreserveInOrder)
+
+	var results []Product
+	_, err := executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 1, 9, 3}, ids(results))
+
+	t.Run("order survives pagination", func(t *testing.T) {
+		q := parse(`ID IN [5, 1, 9, 3] preserve_in_order = true page_size = 3`)
+		var page1, page2 []Product
+		result, err := executor.Execute(ctx, q, "", &page1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{5, 1, 9}, ids(page1))
+
+		_, err = executor.Execute(ctx, q, result.NextPageCursor, &page2)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3}, ids(page2))
+	})
+
+	t.Run("without the option the default sort applies", func(t *testing.T) {
+		var results []Product
+		_, err := executor.Execute(ctx, parse(`ID IN [5, 1, 9]`), "", &results)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 5, 9}, ids(results))
+	})
+
+	t.Run("invalid combinations", func(t *testing.T) {
+		for _, input := range []string{
+			`Stock > 0 preserve_in_order = true`,
+			`ID IN [1, 2] OR Stock > 0 preserve_in_order = true`,
+			`ID IN [1, 2] preserve_in_order = true sort_by = Price`,
+		} {
+			var results []Product
+			_, err := executor.Execute(ctx, parse(input), "", &results)
+			assert.ErrorIs(t, err, query.ErrInvalidQuery, input)
+		}
+	})
+}
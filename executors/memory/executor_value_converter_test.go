@@ -93,7 +93,7 @@ func TestMemoryExecutor_ValueConverter(t *testing.T) {
 
 		var products []ProductWithFeatures
 		_, err = executor.Execute(ctx, q, "", &products)
-		require.NoError(t, err)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
 		// This checks if features field (which is an array) contains any of the values
 		// Since we're checking array field, CONTAINS would be more appropriate
 		// But IN works with single values, so this might not match as expected
@@ -143,7 +143,8 @@ func TestMemoryExecutor_ValueConverter(t *testing.T) {
 
 		var products []ProductWithFeatures
 		_, err = executor.Execute(ctx, q, "", &products)
-		require.NoError(t, err)
+		// No conversion error, just no match
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
 		assert.Equal(t, 0, len(products))
 	})
 
@@ -225,7 +226,7 @@ func TestMemoryExecutor_ArrayContains(t *testing.T) {
 
 		var products []ProductWithArray
 		_, err = executor.Execute(ctx, q, "", &products)
-		require.NoError(t, err)
+		require.ErrorIs(t, err, query.ErrNoRecordsFound)
 		assert.Equal(t, 0, len(products))
 	})
 
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryExecutor_Writes(t *testing.T) {
+	ctx := context.Background()
+	brand := func(name string) *query.Query {
+		return &query.Query{Filter: &query.ComparisonNode{Field: "brand", Operator: query.OpEqual, Value: query.StringValue(name)}}
+	}
+	writable := func() *query.ExecutorOptions {
+		opts := query.DefaultExecutorOptions()
+		opts.AllowWrites = true
+		return opts
+	}
+
+	t.Run("store", func(t *testing.T) {
+		store := NewStore([]Product{
+			{ID: 1, Brand: "Anker", Stock: 5},
+			{ID: 2, Brand: "Sony", Stock: 3},
+			{ID: 3, Brand: "Anker", Stock: 7},
+		})
+		exec := NewExecutorWithStore(store, writable())
+		before := store.Snapshot()
+
+		updated, err := executor.UpdateWhere(ctx, exec, brand("Anker"), map[string]interface{}{"stock": 0, "featured": true})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated)
+		assert.Equal(t, []Product{
+			{ID: 1, Brand: "Anker", Stock: 0, Featured: true},
+			{ID: 2, Brand: "Sony", Stock: 3},
+			{ID: 3, Brand: "Anker", Stock: 0, Featured: true},
+		}, store.Snapshot())
+		assert.Equal(t, 5, before.([]Product)[0].Stock, "earlier snapshots are not modified")
+
+		deleted, err := executor.DeleteWhere(ctx, exec, brand("Anker"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		assert.Equal(t, []Product{{ID: 2, Brand: "Sony", Stock: 3}}, store.Snapshot())
+
+		version := store.Version()
+		deleted, err = executor.DeleteWhere(ctx, exec, brand("JBL"))
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+		assert.Equal(t, version, store.Version(), "writes that match nothing keep the snapshot")
+	})
+
+	t.Run("pointer to a slice of maps", func(t *testing.T) {
+		data := []map[string]interface{}{
+			{"id": 1, "Brand": "Anker"},
+			{"id": 2, "Brand": "Sony"},
+		}
+		exec := NewExecutor(&data, writable())
+
+		updated, err := exec.UpdateWhere(ctx, brand("Sony"), map[string]interface{}{"brand": "Sony Group", "rating": 4.5})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+		assert.Equal(t, map[string]interface{}{"id": 2, "Brand": "Sony Group", "rating": 4.5}, data[1])
+
+		deleted, err := exec.DeleteWhere(ctx, brand("Anker"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.Len(t, data, 1)
+	})
+
+	t.Run("rejected writes", func(t *testing.T) {
+		data := []Product{{ID: 1, Brand: "Anker"}}
+		exec := NewExecutor(&data, writable())
+
+		_, err := exec.UpdateWhere(ctx, brand("Anker"), map[string]interface{}{"stock": "many"})
+		assert.ErrorIs(t, err, query.ErrTypeMismatch)
+		_, err = exec.UpdateWhere(ctx, brand("Anker"), map[string]interface{}{"missing": 1})
+		assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+		_, err = exec.DeleteWhere(ctx, &query.Query{})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+		_, err = NewExecutor(data, writable()).DeleteWhere(ctx, brand("Anker"))
+		assert.ErrorIs(t, err, query.ErrWritesNotSupported)
+		_, err = NewExecutor(&data, nil).DeleteWhere(ctx, brand("Anker"))
+		assert.ErrorIs(t, err, query.ErrWritesNotAllowed)
+		assert.Equal(t, []Product{{ID: 1, Brand: "Anker"}}, data)
+	})
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Facets counts the values of each spec's field, or the items in each of its
+// ranges, among the items matching the query's filter
+func (e *MemoryExecutor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	e = e.withCurrentOptions()
+	results, err := e.facets(ctx, q, specs)
+	return results, query.WrapError(e.Name(), "facets", err)
+}
+
+func (e *MemoryExecutor) facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+	specs, err = e.options.ValidateFacets(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+	compiled, err := e.compile(q)
+	if err != nil {
+		return nil, err
+	}
+	items, err := e.withRegexDeadline().filterData(ctx, compiled.q.Filter, compiled.match, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]query.FacetResult, len(specs))
+	for i, spec := range specs {
+		results[i].Field = spec.Field
+		switch {
+		case len(spec.Ranges) > 0:
+			results[i].Buckets, err = e.rangeBuckets(items, spec)
+		case spec.IsHistogram():
+			results[i].Buckets, err = e.histogramBuckets(items, spec)
+		default:
+			results[i].Buckets, err = e.valueBuckets(items, spec)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// valueBuckets counts the items of each value of spec.Field. Values equal by
+// compareEqual share a bucket, like with distinct_on
+func (e *MemoryExecutor) valueBuckets(items []reflect.Value, spec query.FacetSpec) ([]query.FacetBucket, error) {
+	access := e.newFieldAccessor(spec.Field)
+	positions := make(map[string]int)
+	var buckets []query.FacetBucket
+	for _, item := range items {
+		value, err := e.facetValue(access, item)
+		if err != nil {
+			return nil, err
+		}
+		key := "nil"
+		if value != nil {
+			key = "v:" + e.orderKey(value)
+		}
+		if i, ok := positions[key]; ok {
+			buckets[i].Count++
+			continue
+		}
+		positions[key] = len(buckets)
+		buckets = append(buckets, query.FacetBucket{Value: value, Count: 1})
+	}
+	return query.SortFacetBuckets(buckets, spec.Limit), nil
+}
+
+// rangeBuckets counts the items in each range of spec, comparing values like
+// the >= and < operators do
+func (e *MemoryExecutor) rangeBuckets(items []reflect.Value, spec query.FacetSpec) ([]query.FacetBucket, error) {
+	access := e.newFieldAccessor(spec.Field)
+	type bounds struct{ from, to valueTest }
+	tests := make([]bounds, len(spec.Ranges))
+	for i, r := range spec.Ranges {
+		var err error
+		if tests[i].from, err = e.compileBound(spec.Field, query.OpGreaterThanOrEqual, r.From); err != nil {
+			return nil, err
+		}
+		if tests[i].to, err = e.compileBound(spec.Field, query.OpLessThan, r.To); err != nil {
+			return nil, err
+		}
+	}
+
+	buckets := make([]query.FacetBucket, len(spec.Ranges))
+	for i, r := range spec.Ranges {
+		buckets[i].Value = r.Label
+	}
+	for _, item := range items {
+		value, err := e.facetValue(access, item)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		for i, test := range tests {
+			if holds(test.from, value) && holds(test.to, value) {
+				buckets[i].Count++
+			}
+		}
+	}
+	return buckets, nil
+}
+
+// histogramBuckets counts the items in each bin of spec. Items whose value is
+// not a number (for Interval) or a time (for DateInterval) are left out
+func (e *MemoryExecutor) histogramBuckets(items []reflect.Value, spec query.FacetSpec) ([]query.FacetBucket, error) {
+	access := e.newFieldAccessor(spec.Field)
+	positions := make(map[interface{}]int)
+	var buckets []query.FacetBucket
+	for _, item := range items {
+		value, err := e.facetValue(access, item)
+		if err != nil {
+			return nil, err
+		}
+		bin, ok := histogramBin(value, spec)
+		if !ok {
+			continue
+		}
+		if i, ok := positions[bin]; ok {
+			buckets[i].Count++
+			continue
+		}
+		positions[bin] = len(buckets)
+		buckets = append(buckets, query.FacetBucket{Value: bin, Count: 1})
+	}
+	return query.SortHistogramBuckets(buckets), nil
+}
+
+// histogramBin returns the start of the bin holding value
+func histogramBin(value interface{}, spec query.FacetSpec) (interface{}, bool) {
+	if spec.DateInterval != "" {
+		t, ok := value.(time.Time)
+		if !ok {
+			return nil, false
+		}
+		return spec.DateInterval.Truncate(t), true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return query.HistogramBin(float64(v.Int()), spec.Interval), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return query.HistogramBin(float64(v.Uint()), spec.Interval), true
+	case reflect.Float32, reflect.Float64:
+		return query.HistogramBin(v.Float(), spec.Interval), true
+	}
+	return nil, false
+}
+
+// compileBound compiles the comparison of a range bound, converted with
+// ValueConverter like query values; a nil bound compiles to nil
+func (e *MemoryExecutor) compileBound(field string, op query.ComparisonOperator, bound interface{}) (valueTest, error) {
+	if bound == nil {
+		return nil, nil
+	}
+	value, err := e.convertValue(field, bound)
+	if err != nil {
+		return nil, query.NewFieldError(field, err)
+	}
+	return e.compileOperator(field, op, value), nil
+}
+
+// holds applies a bound test; a missing bound always holds
+func holds(test valueTest, value interface{}) bool {
+	if test == nil {
+		return true
+	}
+	ok, _ := test(value, time.Time{})
+	return ok
+}
+
+// facetValue reads the facet field of item. Items without the field count as
+// having no value, like missing fields do not match filters
+func (e *MemoryExecutor) facetValue(access *fieldAccessor, item reflect.Value) (interface{}, error) {
+	value, err := access.get(item)
+	if err != nil && (e.options.FieldGetter != nil || !errors.Is(err, query.ErrInvalidQuery)) {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// maxReportedItems caps the item indexes listed per assertion in FixtureError messages
+const maxReportedItems = 5
+
+// FixtureViolation is a fixture item that does not satisfy an assertion
+type FixtureViolation struct {
+	// Assertion is the query-language assertion that failed
+	Assertion string
+	// Index is the position of the item in the data set
+	Index int
+	// Item is the offending item
+	Item interface{}
+}
+
+// FixtureError reports every fixture item that failed an assertion
+type FixtureError struct {
+	Violations []FixtureViolation
+}
+
+func (e *FixtureError) Error() string {
+	var b strings.Builder
+	b.WriteString("fixture assertions failed:")
+
+	// Group indexes by assertion, keeping assertion order
+	var order []string
+	indexes := map[string][]int{}
+	for _, v := range e.Violations {
+		if _, seen := indexes[v.Assertion]; !seen {
+			order = append(order, v.Assertion)
+		}
+		indexes[v.Assertion] = append(indexes[v.Assertion], v.Index)
+	}
+	for _, assertion := range order {
+		items := indexes[assertion]
+		shown := items
+		if len(shown) > maxReportedItems {
+			shown = shown[:maxReportedItems]
+		}
+		fmt.Fprintf(&b, "\n  %q: %d item(s) failed, indexes %v", assertion, len(items), shown)
+		if len(items) > len(shown) {
+			b.WriteString(" ...")
+		}
+	}
+	return b.String()
+}
+
+// Check validates the executor's data against query-language assertions.
+// Every item must match every assertion, e.g. "price > 0 AND stock >= 0".
+// It returns a *FixtureError listing the failing items, or an error if an
+// assertion cannot be parsed or evaluated.
+//
+// Assertions are evaluated with the executor's options and field getter,
+// but without its BaseFilter, so the whole data set is checked.
+func (e *MemoryExecutor) Check(assertions ...string) error {
+	e = e.withCurrentOptions()
+
+	dataVal := reflect.ValueOf(e.dataSource())
+	if dataVal.Kind() == reflect.Ptr {
+		dataVal = dataVal.Elem()
+	}
+	if dataVal.Kind() != reflect.Slice {
+		return query.ErrInvalidQuery
+	}
+
+	var violations []FixtureViolation
+	for _, assertion := range assertions {
+		filter, err := parser.ParseFilter(assertion)
+		if err != nil {
+			return fmt.Errorf("invalid fixture assertion %q: %w", assertion, err)
+		}
+		if filter == nil {
+			continue
+		}
+		for i := 0; i < dataVal.Len(); i++ {
+			item := dataVal.Index(i)
+			match, err := e.evaluateFilter(filter, item)
+			if err != nil {
+				return fmt.Errorf("fixture assertion %q on item %d: %w", assertion, i, err)
+			}
+			if !match {
+				violations = append(violations, FixtureViolation{
+					Assertion: assertion,
+					Index:     i,
+					Item:      item.Interface(),
+				})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &FixtureError{Violations: violations}
+	}
+	return nil
+}
+
+// CheckFixtures validates a slice of seeded test data against query-language
+// assertions using default executor options. See MemoryExecutor.Check.
+//
+// Example:
+//
+//	err := memory.CheckFixtures(products,
+//	    "price > 0 AND stock >= 0",
+//	    `category IN [electronics, accessories]`,
+//	)
+func CheckFixtures(data interface{}, assertions ...string) error {
+	return NewExecutor(data, nil).Check(assertions...)
+}
+
+// TestingT is the subset of testing.TB used by AssertFixtures
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertFixtures reports a test error for every failing assertion and
+// returns whether all assertions held.
+//
+// Example:
+//
+//	func TestSeedData(t *testing.T) {
+//	    memory.AssertFixtures(t, seed.Products(), "price > 0 AND stock >= 0")
+//	}
+func AssertFixtures(t TestingT, data interface{}, assertions ...string) bool {
+	t.Helper()
+	if err := CheckFixtures(data, assertions...); err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	return true
+}
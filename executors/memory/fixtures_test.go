@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixtureProduct struct {
+	Name     string
+	Price    float64
+	Stock    int
+	Category string
+}
+
+func TestCheckFixtures(t *testing.T) {
+	products := []fixtureProduct{
+		{Name: "Mouse", Price: 29.99, Stock: 100, Category: "electronics"},
+		{Name: "Cable", Price: 0, Stock: 200, Category: "accessories"},
+		{Name: "Hub", Price: 24.99, Stock: -1, Category: "toys"},
+	}
+
+	require.NoError(t, CheckFixtures(products, "name != ''", "stock > -10"))
+	require.NoError(t, CheckFixtures(products), "no assertions")
+
+	err := CheckFixtures(products,
+		"price > 0 and stock >= 0",
+		"category IN [electronics, accessories]",
+	)
+	var fixtureErr *FixtureError
+	require.True(t, errors.As(err, &fixtureErr))
+	require.Len(t, fixtureErr.Violations, 3)
+	assert.Equal(t, FixtureViolation{Assertion: "price > 0 and stock >= 0", Index: 1, Item: products[1]}, fixtureErr.Violations[0])
+	assert.Equal(t, 2, fixtureErr.Violations[1].Index)
+	assert.Equal(t, "category IN [electronics, accessories]", fixtureErr.Violations[2].Assertion)
+	assert.Contains(t, err.Error(), `"price > 0 and stock >= 0": 2 item(s) failed, indexes [1 2]`)
+
+	t.Run("maps", func(t *testing.T) {
+		rows := []map[string]interface{}{{"price": 10}, {"price": 20}}
+		assert.NoError(t, CheckFixtures(rows, "price >= 10"))
+	})
+
+	t.Run("invalid assertions", func(t *testing.T) {
+		assert.ErrorContains(t, CheckFixtures(products, "price >"), `invalid fixture assertion "price >"`)
+		assert.ErrorContains(t, CheckFixtures(products, "price > 0 sort_by = name"), "invalid fixture assertion")
+		assert.ErrorIs(t, CheckFixtures(fixtureProduct{}, "price > 0"), query.ErrInvalidQuery)
+	})
+}
+
+func TestFixtureError_TruncatesIndexes(t *testing.T) {
+	items := make([]fixtureProduct, 8)
+	err := CheckFixtures(items, "price > 0")
+	assert.Contains(t, err.Error(), "8 item(s) failed, indexes [0 1 2 3 4] ...")
+}
+
+type recordingT struct{ errors []string }
+
+func (r *recordingT) Helper() {}
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertFixtures(t *testing.T) {
+	products := []fixtureProduct{{Name: "Mouse", Price: 29.99}}
+
+	assert.True(t, AssertFixtures(t, products, "price > 0"))
+
+	rec := &recordingT{}
+	assert.False(t, AssertFixtures(rec, products, "stock > 0"))
+	require.Len(t, rec.errors, 1)
+	assert.Contains(t, rec.errors[0], `"stock > 0"`)
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// highlightItem returns the fragments of the item's fields matched by the
+// CONTAINS, ICONTAINS, LIKE and MATCH conditions of the filter, sorted by
+// field and offset with overlapping fragments merged. Bare search terms are
+// highlighted in every default search field
+func (e *MemoryExecutor) highlightItem(filter query.Node, item reflect.Value) []query.Highlight {
+	var highlights []query.Highlight
+	var walk func(node query.Node)
+	walk = func(node query.Node) {
+		switch n := node.(type) {
+		case *query.BinaryOpNode:
+			walk(n.Left)
+			walk(n.Right)
+		case *query.ComparisonNode:
+			// Phrases are highlighted as written, or word by word with PhraseMatchTerms
+			if expanded := e.options.ExpandPhrase(n, false); expanded != n {
+				walk(expanded)
+				return
+			}
+			fields := []string{n.Field}
+			if n.Field == query.SearchField {
+				fields = e.options.SearchFields()
+			}
+			for _, field := range fields {
+				fieldValue, err := e.getFieldValue(item, field)
+				if err != nil || fieldValue == nil {
+					continue
+				}
+				for _, span := range query.MatchSpans(n, fmt.Sprintf("%v", fieldValue)) {
+					highlights = append(highlights, query.Highlight{Field: field, Start: span[0], End: span[1]})
+				}
+			}
+		}
+	}
+	walk(filter)
+	return mergeHighlights(highlights)
+}
+
+// mergeHighlights sorts highlights by field and offset and merges the
+// overlapping fragments of each field
+func mergeHighlights(highlights []query.Highlight) []query.Highlight {
+	sort.Slice(highlights, func(i, j int) bool {
+		if highlights[i].Field != highlights[j].Field {
+			return highlights[i].Field < highlights[j].Field
+		}
+		return highlights[i].Start < highlights[j].Start
+	})
+	merged := highlights[:0]
+	for _, h := range highlights {
+		if last := len(merged) - 1; last >= 0 && merged[last].Field == h.Field && h.Start <= merged[last].End {
+			if h.End > merged[last].End {
+				merged[last].End = h.End
+			}
+			continue
+		}
+		merged = append(merged, h)
+	}
+	return merged
+}
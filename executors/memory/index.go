@@ -0,0 +1,387 @@
+package memory
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Index declares a secondary index for NewIndexedExecutor
+type Index struct {
+	field  string
+	sorted bool
+}
+
+// WithHashIndex indexes field by value, for = and IN conditions
+func WithHashIndex(field string) Index {
+	return Index{field: field}
+}
+
+// WithSortedIndex indexes field in value order, for =, IN and range
+// (>, >=, <, <=) conditions
+func WithSortedIndex(field string) Index {
+	return Index{field: field, sorted: true}
+}
+
+// NewIndexedExecutor creates a memory executor over static data with secondary
+// indexes. Conditions on indexed fields narrow the items the filter is evaluated
+// on: an AND needs one indexed operand, an OR needs all of them. Other filters
+// scan every item, like NewExecutor.
+//
+// Indexes are built once; call Reindex after modifying the data.
+// Conditions that are not indexed, != and NOT IN among them, are still evaluated
+// on the candidate items, so results are the same as without indexes.
+func NewIndexedExecutor(data interface{}, opts *query.ExecutorOptions, indexes ...Index) *MemoryExecutor {
+	return NewIndexedExecutorWithDataSource(func() interface{} { return data }, opts, indexes...)
+}
+
+// NewIndexedExecutorWithDataSource creates a memory executor with a dynamic data
+// source and secondary indexes. Indexes are only used while the data source
+// returns the slice they were built from; pair it with a Store to rebuild them
+// on every change:
+//
+//	executor := memory.NewIndexedExecutorWithDataSource(store.DataSource(), opts, memory.WithHashIndex("brand"))
+//	store.OnChange(executor.Reindex)
+func NewIndexedExecutorWithDataSource(dataSource DataSourceFunc, opts *query.ExecutorOptions, indexes ...Index) *MemoryExecutor {
+	e := NewExecutorWithDataSource(dataSource, opts)
+	e.indexes = &indexSet{declared: indexes}
+	e.Reindex()
+	return e
+}
+
+// Reindex rebuilds the secondary indexes from the current data.
+// It is a no-op for executors without indexes
+func (e *MemoryExecutor) Reindex() {
+	if e.indexes == nil {
+		return
+	}
+	dataVal := reflect.ValueOf(e.dataSource())
+	if dataVal.Kind() == reflect.Ptr {
+		dataVal = dataVal.Elem()
+	}
+
+	hashes := make(map[string]map[string][]int)
+	sorted := make(map[string]*sortedIndex)
+	size, ptr := -1, uintptr(0)
+	if dataVal.Kind() == reflect.Slice {
+		size, ptr = dataVal.Len(), dataVal.Pointer()
+		for _, index := range e.indexes.declared {
+			name := strings.ToLower(index.field)
+			if index.sorted {
+				sorted[name] = e.buildSortedIndex(dataVal, index.field)
+			} else {
+				hashes[name] = e.buildHashIndex(dataVal, index.field)
+			}
+		}
+	}
+
+	e.indexes.mu.Lock()
+	defer e.indexes.mu.Unlock()
+	e.indexes.size = size
+	e.indexes.ptr = ptr
+	e.indexes.hashes = hashes
+	e.indexes.sorted = sorted
+}
+
+// indexSet holds the secondary indexes of an executor. It is shared by the
+// per-execution copies of the executor
+type indexSet struct {
+	declared []Index
+
+	mu     sync.RWMutex
+	size   int     // number of indexed items, -1 when the data is not a slice
+	ptr    uintptr // backing array of the indexed slice
+	hashes map[string]map[string][]int
+	sorted map[string]*sortedIndex
+}
+
+// sortedIndex orders items by field value the two ways values compare:
+// numerically when both sides are numbers and as formatted strings otherwise
+type sortedIndex struct {
+	nums []numEntry // numeric values, in numeric order
+	strs []strEntry // all values, in string order
+}
+
+type numEntry struct {
+	num float64
+	pos int
+}
+
+type strEntry struct {
+	str   string
+	isNum bool
+	pos   int
+}
+
+// indexedValue returns the value of field for indexing; ok is false for items
+// that no condition on the field can match
+func (e *MemoryExecutor) indexedValue(item reflect.Value, field string) (interface{}, bool) {
+	val, err := e.getFieldValue(item, field)
+	if err != nil || val == nil {
+		return nil, false
+	}
+	return val, true
+}
+
+func (e *MemoryExecutor) buildHashIndex(dataVal reflect.Value, field string) map[string][]int {
+	index := make(map[string][]int)
+	for i := 0; i < dataVal.Len(); i++ {
+		val, ok := e.indexedValue(dataVal.Index(i), field)
+		if !ok {
+			continue
+		}
+		key := e.hashKey(val)
+		index[key] = append(index[key], i)
+	}
+	return index
+}
+
+func (e *MemoryExecutor) buildSortedIndex(dataVal reflect.Value, field string) *sortedIndex {
+	index := &sortedIndex{}
+	for i := 0; i < dataVal.Len(); i++ {
+		val, ok := e.indexedValue(dataVal.Index(i), field)
+		if !ok {
+			continue
+		}
+		num, isNum := e.toFloat64(val)
+		if isNum && !math.IsNaN(num) {
+			index.nums = append(index.nums, numEntry{num: num, pos: i})
+		}
+		index.strs = append(index.strs, strEntry{str: fmt.Sprintf("%v", val), isNum: isNum, pos: i})
+	}
+	sort.SliceStable(index.nums, func(i, j int) bool { return index.nums[i].num < index.nums[j].num })
+	sort.SliceStable(index.strs, func(i, j int) bool { return index.strs[i].str < index.strs[j].str })
+	return index
+}
+
+// hashKey returns the hash index key of a field value. Numbers equal by
+// compareEqual share a key; other values are keyed by their formatted string
+func (e *MemoryExecutor) hashKey(v interface{}) string {
+	if f, ok := e.toFloat64(v); ok {
+		if f == 0 {
+			f = 0 // -0 and 0 are equal
+		}
+		return "n:" + strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return "s:" + fmt.Sprintf("%v", v)
+}
+
+// candidates returns the positions, in source order, of the items that can
+// match filter according to the indexes, and the fields of the indexes used.
+// ok is false when the indexes cannot narrow the filter or were built from a
+// different slice than dataVal, such as an older Store snapshot
+func (e *MemoryExecutor) candidates(filter query.Node, dataVal reflect.Value) ([]int, []string, bool) {
+	if e.indexes == nil || filter == nil {
+		return nil, nil, false
+	}
+	e.indexes.mu.RLock()
+	defer e.indexes.mu.RUnlock()
+	if e.indexes.size != dataVal.Len() || e.indexes.ptr != dataVal.Pointer() {
+		return nil, nil, false
+	}
+	positions, fields, ok := e.plan(filter)
+	if !ok {
+		return nil, nil, false
+	}
+	sort.Ints(positions)
+	return positions, fields, true
+}
+
+// plan returns the candidate positions for node, in any order and without
+// duplicates, and the fields of the indexes they were looked up in
+func (e *MemoryExecutor) plan(node query.Node) ([]int, []string, bool) {
+	switch n := node.(type) {
+	case *query.BinaryOpNode:
+		left, leftFields, leftOK := e.plan(n.Left)
+		right, rightFields, rightOK := e.plan(n.Right)
+		if n.Operator == query.BinaryOpAnd {
+			switch {
+			case leftOK && rightOK:
+				return intersect(left, right), append(leftFields, rightFields...), true
+			case leftOK:
+				return left, leftFields, true
+			case rightOK:
+				return right, rightFields, true
+			}
+			return nil, nil, false
+		}
+		if leftOK && rightOK {
+			return union(left, right), append(leftFields, rightFields...), true
+		}
+		return nil, nil, false
+	case *query.ComparisonNode:
+		positions, ok := e.lookup(n)
+		if !ok {
+			return nil, nil, false
+		}
+		return positions, []string{n.Field}, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// lookup returns the candidate positions for a single comparison
+func (e *MemoryExecutor) lookup(n *query.ComparisonNode) ([]int, bool) {
+	if n.Field == query.SearchField || !e.options.IsFieldAllowed(n.Field) {
+		// Disallowed fields fail when the filter is evaluated
+		return nil, false
+	}
+	if n.Modifier != query.ArrayModifierNone {
+		// Indexes hold whole field values, not array elements
+		return nil, false
+	}
+	name := strings.ToLower(n.Field)
+	hash, hasHash := e.indexes.hashes[name]
+	sorted, hasSorted := e.indexes.sorted[name]
+	if !hasHash && !hasSorted {
+		return nil, false
+	}
+
+	// Convert values like evaluateComparison and evaluateIn
+	value, err := e.convertValue(n.Field, n.Value)
+	if err != nil {
+		return nil, false
+	}
+	var values []interface{}
+	switch n.Operator {
+	case query.OpEqual:
+		if _, _, ranged := e.options.FloatRange(value); ranged {
+			return nil, false
+		}
+		values = []interface{}{value}
+	case query.OpIn:
+		arr := reflect.ValueOf(value)
+		if arr.Kind() != reflect.Slice {
+			return nil, true
+		}
+		for i := 0; i < arr.Len(); i++ {
+			if elem, err := e.convertValue(n.Field, arr.Index(i).Interface()); err == nil {
+				values = append(values, elem)
+			}
+		}
+	case query.OpGreaterThan, query.OpGreaterThanOrEqual, query.OpLessThan, query.OpLessThanOrEqual:
+		if !hasSorted {
+			return nil, false
+		}
+		return sorted.rangeOf(e, n.Operator, value), true
+	default:
+		return nil, false
+	}
+
+	var positions []int
+	for _, v := range values {
+		if hasHash {
+			positions = append(positions, hash[e.hashKey(v)]...)
+			if _, isNum := e.toFloat64(v); isNum {
+				// Non-numeric field values compare with numbers as strings
+				positions = append(positions, hash["s:"+fmt.Sprintf("%v", v)]...)
+			}
+		} else {
+			positions = append(positions, sorted.rangeOf(e, query.OpEqual, v)...)
+		}
+	}
+	return dedupe(positions), true
+}
+
+// rangeOf returns the positions of the items whose value compares with v as op
+// does in compareApproxEqual (without tolerance), compareGreater and compareLess
+func (s *sortedIndex) rangeOf(e *MemoryExecutor, op query.ComparisonOperator, v interface{}) []int {
+	var positions []int
+	num, isNum := e.toFloat64(v)
+	if isNum {
+		// Numeric field values compare numerically
+		lo, hi := bounds(len(s.nums), op, func(i int) int { return compareFloat(s.nums[i].num, num) })
+		for _, entry := range s.nums[lo:hi] {
+			positions = append(positions, entry.pos)
+		}
+	}
+	str := fmt.Sprintf("%v", v)
+	lo, hi := bounds(len(s.strs), op, func(i int) int { return strings.Compare(s.strs[i].str, str) })
+	for _, entry := range s.strs[lo:hi] {
+		if !isNum || !entry.isNum {
+			positions = append(positions, entry.pos)
+		}
+	}
+	return positions
+}
+
+// bounds returns the range [lo, hi) of a sorted list whose entries compare with
+// the query value as op requires. cmp(i) compares entry i with the value
+func bounds(n int, op query.ComparisonOperator, cmp func(i int) int) (int, int) {
+	firstGreaterOrEqual := sort.Search(n, func(i int) bool { return cmp(i) >= 0 })
+	firstGreater := sort.Search(n, func(i int) bool { return cmp(i) > 0 })
+	switch op {
+	case query.OpGreaterThan:
+		return firstGreater, n
+	case query.OpGreaterThanOrEqual:
+		return firstGreaterOrEqual, n
+	case query.OpLessThan:
+		return 0, firstGreaterOrEqual
+	case query.OpLessThanOrEqual:
+		return 0, firstGreater
+	default:
+		return firstGreaterOrEqual, firstGreater
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// intersect returns the positions present in both lists
+func intersect(a, b []int) []int {
+	in := make(map[int]struct{}, len(a))
+	for _, pos := range a {
+		in[pos] = struct{}{}
+	}
+	var result []int
+	for _, pos := range b {
+		if _, ok := in[pos]; ok {
+			result = append(result, pos)
+		}
+	}
+	return result
+}
+
+// union returns the positions present in either list, without duplicates
+func union(a, b []int) []int {
+	return dedupe(append(append([]int(nil), a...), b...))
+}
+
+// dedupe removes duplicate positions
+func dedupe(positions []int) []int {
+	seen := make(map[int]struct{}, len(positions))
+	result := positions[:0]
+	for _, pos := range positions {
+		if _, ok := seen[pos]; !ok {
+			seen[pos] = struct{}{}
+			result = append(result, pos)
+		}
+	}
+	return result
+}
+
+// dedupeFields removes repeated fields, keeping the first of each
+func dedupeFields(fields []string) []string {
+	seen := make(map[string]struct{}, len(fields))
+	var result []string
+	for _, field := range fields {
+		if _, ok := seen[field]; !ok {
+			seen[field] = struct{}{}
+			result = append(result, field)
+		}
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedExecutor_MatchesScan(t *testing.T) {
+	products := genProducts()
+	for i := range genProducts() {
+		// Repeated values exercise multi-item index entries
+		p := products[i]
+		p.ID += 100
+		products = append(products, p)
+	}
+
+	gen, err := NewQueryGenerator(genSchema, products, 7)
+	require.NoError(t, err)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	executor := NewIndexedExecutor(products, opts,
+		WithHashIndex("name"),
+		WithHashIndex("featured"),
+		WithSortedIndex("id"),
+		WithSortedIndex("price"),
+		WithSortedIndex("Stock"),
+		WithSortedIndex("createdat"),
+	)
+
+	for i := 0; i < 300; i++ {
+		c, err := gen.Case()
+		require.NoError(t, err)
+
+		var results []genProduct
+		result, err := executor.Execute(context.Background(), c.Query, "", &results)
+		if errors.Is(err, query.ErrNoRecordsFound) {
+			err = nil
+		}
+		require.NoError(t, err)
+
+		var expected, got []int
+		for _, idx := range c.Matches {
+			expected = append(expected, products[idx].ID)
+		}
+		for _, p := range results {
+			got = append(got, p.ID)
+		}
+		assert.ElementsMatch(t, expected, got, "%#v", c.Query.Filter)
+		if result != nil {
+			assert.Equal(t, int64(len(c.Matches)), result.TotalItems)
+		}
+	}
+}
+
+func TestIndexedExecutor_Candidates(t *testing.T) {
+	data := []map[string]interface{}{
+		{"id": 1, "brand": "Anker", "price": 10.0},
+		{"id": 2, "brand": "Sony", "price": "25"},
+		{"id": 3, "brand": "Anker", "price": 30},
+		{"id": 4, "brand": 7, "price": "n/a"},
+		{"id": 5, "price": 40.0},
+	}
+	executor := NewIndexedExecutor(data, nil, WithHashIndex("brand"), WithSortedIndex("price"))
+
+	tests := []struct {
+		filter    string
+		positions []int
+		indexed   bool
+	}{
+		{`brand = Anker`, []int{0, 2}, true},
+		{`brand IN [Sony, 7]`, []int{1, 3}, true},
+		{`price >= 25`, []int{1, 2, 3, 4}, true}, // "n/a" compares as a string
+		{`price < 25 AND brand = Anker`, []int{0}, true},
+		{`price > 35 OR brand = Sony`, []int{1, 3, 4}, true},
+		{`price > 35 OR id = 1`, nil, false},
+		{`brand != Anker`, nil, false},
+		{`brand = Anker AND id != 1`, []int{0, 2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := parser.ParseFilter(tt.filter)
+			require.NoError(t, err)
+			positions, _, indexed := executor.candidates(filter, reflect.ValueOf(data))
+			assert.Equal(t, tt.indexed, indexed)
+			assert.Equal(t, tt.positions, positions)
+		})
+	}
+}
+
+func TestIndexedExecutor_Reindex(t *testing.T) {
+	data := []Product{{ID: 1, Brand: "Anker"}, {ID: 2, Brand: "Sony"}}
+	executor := NewIndexedExecutor(data, nil, WithHashIndex("brand"))
+	q := &query.Query{Filter: query.Eq("brand", "JBL")}
+
+	count, err := executor.Count(context.Background(), q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	data[1].Brand = "JBL"
+	executor.Reindex()
+	count, err = executor.Count(context.Background(), q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func BenchmarkIndexedExecutor(b *testing.B) {
+	data := make([]Product, 100000)
+	for i := range data {
+		data[i] = Product{ID: i, Brand: fmt.Sprintf("brand-%d", i%1000), Price: float64(i % 5000)}
+	}
+	filter, err := parser.ParseFilter(`brand = "brand-42" AND price >= 100`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	q := &query.Query{Filter: filter}
+
+	for name, executor := range map[string]*MemoryExecutor{
+		"Scan":    NewExecutor(data, nil),
+		"Indexed": NewIndexedExecutor(data, nil, WithHashIndex("brand"), WithSortedIndex("price")),
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = executor.Count(context.Background(), q)
+			}
+		})
+	}
+}
+
+func TestIndexedExecutor_Explain(t *testing.T) {
+	data := []Product{{ID: 1, Brand: "Anker", Price: 10}, {ID: 2, Brand: "Sony", Price: 20}, {ID: 3, Brand: "Anker", Price: 30}}
+	executor := NewIndexedExecutor(data, nil, WithHashIndex("brand"))
+	ctx := context.Background()
+
+	q := &query.Query{Filter: query.And(query.Eq("brand", "Anker"), query.Gt("price", 15)), ExplainRequested: true}
+	var results []Product
+	result, err := executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, &query.Explain{
+		Backend:   "memory",
+		Statement: `brand = "Anker" AND price > 15`,
+		Plan:      "index scan: 2 of 3 items",
+		Indexes:   []string{"brand"},
+	}, result.Explain)
+
+	q = &query.Query{Filter: query.Gt("price", 15), ExplainRequested: true}
+	result, err = executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+	assert.Equal(t, "full scan: 3 items", result.Explain.Plan)
+	assert.Empty(t, result.Explain.Indexes)
+
+	// Explain is only filled on request
+	q.ExplainRequested = false
+	result, err = executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+	assert.Nil(t, result.Explain)
+	assert.Positive(t, int64(result.ExecutionTime))
+}
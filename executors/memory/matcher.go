@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Matcher evaluates filters against single items with the same semantics as
+// MemoryExecutor. It lets executors for other stores, such as embedded
+// key-value databases, reuse the memory evaluation engine without loading all
+// items into a slice.
+//
+// Filters are not validated and BaseFilter is not applied; callers run
+// ValidateFilter and ScopedQuery first, like Execute does.
+type Matcher struct {
+	e *MemoryExecutor
+}
+
+// NewMatcher creates a matcher for one query execution.
+// The RegexTimeout budget, if any, starts when the matcher is created.
+func NewMatcher(opts *MemoryExecutorOptions) *Matcher {
+	if opts == nil {
+		opts = &MemoryExecutorOptions{}
+	}
+	if opts.ExecutorOptions == nil {
+		resolved := *opts
+		resolved.ExecutorOptions = query.DefaultExecutorOptions()
+		opts = &resolved
+	}
+	e := &MemoryExecutor{options: opts}
+	return &Matcher{e: e.withRegexDeadline()}
+}
+
+// Match reports whether item (a struct, pointer to struct or map) satisfies filter.
+// A nil filter matches every item.
+func (m *Matcher) Match(filter query.Node, item interface{}) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	match, err := m.e.evaluateFilter(filter, reflect.ValueOf(item))
+	if err != nil {
+		return false, wrapEvaluateError(err)
+	}
+	return match, nil
+}
+
+// QueryMatcher tests single items against a query, e.g. to find the alert
+// rules or saved searches an incoming event satisfies, without an executor
+// or a data source. Items match like they do in Execute: the filter is
+// validated, authorized and scoped with BaseFilter. Queries with placeholders
+// or relative times (created_at > now-1h) are resolved on every Match, so they
+// follow the request and the clock; others are compiled once.
+// A QueryMatcher is safe for concurrent use.
+type QueryMatcher struct {
+	e        *MemoryExecutor
+	q        *query.Query
+	compiled *CompiledQuery // nil when q has placeholders or relative times
+}
+
+// NewQueryMatcher validates q and compiles it for matching. opts may be nil
+// for the default options
+func NewQueryMatcher(q *query.Query, opts *MemoryExecutorOptions) (*QueryMatcher, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	m := &QueryMatcher{e: NewMatcher(opts).e, q: q}
+	if !query.HasDynamicValues(q.Filter) {
+		compiled, err := m.e.compile(q)
+		if err != nil {
+			return nil, err
+		}
+		m.compiled = compiled
+	}
+	return m, nil
+}
+
+// Match reports whether item (a struct, pointer to struct or map) satisfies
+// the query. ctx is passed to placeholder resolvers and FieldAuthorizer
+func (m *QueryMatcher) Match(ctx context.Context, item interface{}) (bool, error) {
+	q, compiled := m.q, m.compiled
+	if compiled == nil {
+		var err error
+		if q, err = m.e.options.ResolvePlaceholders(ctx, q); err != nil {
+			return false, err
+		}
+		if compiled, err = m.e.compile(q); err != nil {
+			return false, err
+		}
+	}
+	if err := m.e.options.AuthorizeFields(ctx, q); err != nil {
+		return false, err
+	}
+	return compiled.Match(item)
+}
+
+// Matches reports whether item (a struct, pointer to struct or map) satisfies
+// the filter of q with the default options. Compile queries tested repeatedly
+// with NewQueryMatcher instead.
+// Example: ok, err := memory.Matches(rule.Query, event)
+func Matches(q *query.Query, item interface{}) (bool, error) {
+	m, err := NewQueryMatcher(q, nil)
+	if err != nil {
+		return false, err
+	}
+	return m.Match(context.Background(), item)
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSearchFields = []string{"name", "email"}
+	m := NewMatcher(&MemoryExecutorOptions{ExecutorOptions: opts})
+
+	alice := User{ID: 1, Name: "Alice", Email: "alice@example.com"}
+
+	match, err := m.Match(query.And(query.Gt("id", 0), query.Search("example")), alice)
+	require.NoError(t, err)
+	assert.True(t, match)
+
+	match, err = m.Match(query.Eq("name", "Bob"), &alice)
+	require.NoError(t, err)
+	assert.False(t, match)
+
+	match, err = m.Match(nil, alice)
+	require.NoError(t, err)
+	assert.True(t, match)
+
+	match, err = m.Match(query.Eq("name", "Alice"), map[string]interface{}{"name": "Alice"})
+	require.NoError(t, err)
+	assert.True(t, match)
+}
+
+func TestMatcher_Errors(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"name"}
+	m := NewMatcher(&MemoryExecutorOptions{ExecutorOptions: opts})
+
+	_, err := m.Match(query.Eq("password", "x"), User{})
+	assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
+
+	// Query errors are not execution failures
+	var fieldErr *query.FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	var execErr *query.ExecutionError
+	assert.False(t, errors.As(err, &execErr))
+
+	opts.AllowedFields = nil
+	m = NewMatcher(&MemoryExecutorOptions{
+		ExecutorOptions: opts,
+		FieldGetter: func(obj interface{}, field string) (interface{}, error) {
+			return nil, errors.New("boom")
+		},
+	})
+	_, err = m.Match(query.Eq("name", "x"), User{})
+	assert.True(t, errors.As(err, &execErr))
+}
+
+func TestQueryMatcher(t *testing.T) {
+	ctx := context.Background()
+	alice := map[string]interface{}{"name": "Alice", "tenant": 1, "created_at": time.Now().Add(-30 * time.Minute)}
+
+	t.Run("matches", func(t *testing.T) {
+		p, err := parser.NewParser("name = Alice AND created_at > now-1h")
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		match, err := Matches(q, alice)
+		require.NoError(t, err)
+		assert.True(t, match)
+
+		opts := query.DefaultExecutorOptions()
+		opts.Clock = func() time.Time { return time.Now().Add(time.Hour) }
+		m, err := NewQueryMatcher(q, &MemoryExecutorOptions{ExecutorOptions: opts})
+		require.NoError(t, err)
+		match, err = m.Match(ctx, alice)
+		require.NoError(t, err)
+		assert.False(t, match, "relative times are resolved on every match")
+	})
+
+	t.Run("placeholders and base filter", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.BaseFilter = query.Eq("tenant", 1)
+		opts.Placeholders = map[string]query.PlaceholderResolver{
+			"me": func(ctx context.Context) (interface{}, error) { return ctx.Value(userKey{}), nil },
+		}
+		m, err := NewQueryMatcher(&query.Query{Filter: query.Eq("name", query.PlaceholderValue("me"))},
+			&MemoryExecutorOptions{ExecutorOptions: opts})
+		require.NoError(t, err)
+
+		match, err := m.Match(context.WithValue(ctx, userKey{}, "Alice"), alice)
+		require.NoError(t, err)
+		assert.True(t, match)
+		match, err = m.Match(context.WithValue(ctx, userKey{}, "Alice"), map[string]interface{}{"name": "Alice", "tenant": 2})
+		require.NoError(t, err)
+		assert.False(t, match)
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.AllowedFields = []string{"name"}
+		m, err := NewQueryMatcher(&query.Query{Filter: query.Eq("password", "x")}, &MemoryExecutorOptions{ExecutorOptions: opts})
+		require.NoError(t, err)
+		_, err = m.Match(ctx, alice)
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+
+		_, err = NewQueryMatcher(&query.Query{Filter: query.Eq("name", query.PlaceholderValue("unknown"))}, nil)
+		require.NoError(t, err)
+		_, err = Matches(&query.Query{Filter: query.Eq("name", query.PlaceholderValue("unknown"))}, alice)
+		assert.ErrorIs(t, err, query.ErrUnknownPlaceholder)
+
+		_, err = Matches(nil, alice)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+}
+
+type userKey struct{}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultChunkSize is the number of items evaluated per chunk when
+// MemoryExecutorOptions.ChunkSize is not set
+const DefaultChunkSize = 4096
+
+// chunkResult holds the items of one chunk accepted by the filter
+type chunkResult struct {
+	items []reflect.Value
+	err   error
+}
+
+// evaluateChunks returns the items among count accepted by match, in order.
+// Chunks are spread over Parallelism goroutines and merged in order. On error,
+// the error of the first failing chunk is returned, as a sequential scan would
+func (e *MemoryExecutor) evaluateChunks(ctx context.Context, count int, itemAt func(int) reflect.Value, match predicate) ([]reflect.Value, error) {
+	chunkSize := e.options.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	chunks := (count + chunkSize - 1) / chunkSize
+	evaluate := func(chunk int) chunkResult {
+		start := chunk * chunkSize
+		end := start + chunkSize
+		if end > count {
+			end = count
+		}
+		var result chunkResult
+		for i := start; i < end; i++ {
+			item := itemAt(i)
+			ok, err := match(item, e.regexDeadline)
+			if err != nil {
+				result.err = wrapEvaluateError(err)
+				return result
+			}
+			if ok {
+				result.items = append(result.items, item)
+			}
+		}
+		return result
+	}
+
+	workers := e.options.Parallelism
+	if workers > chunks {
+		workers = chunks
+	}
+	if workers <= 1 {
+		var filtered []reflect.Value
+		for chunk := 0; chunk < chunks; chunk++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			result := evaluate(chunk)
+			if result.err != nil {
+				return nil, result.err
+			}
+			filtered = append(filtered, result.items...)
+		}
+		if filtered == nil {
+			filtered = []reflect.Value{}
+		}
+		return filtered, nil
+	}
+
+	results := make([]chunkResult, chunks)
+	var next atomic.Int64
+	// firstFailed is the first chunk that failed; later chunks are skipped
+	var firstFailed atomic.Int64
+	firstFailed.Store(int64(chunks))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				chunk := int(next.Add(1) - 1)
+				if chunk >= chunks || ctx.Err() != nil {
+					return
+				}
+				if int64(chunk) > firstFailed.Load() {
+					continue
+				}
+				results[chunk] = evaluate(chunk)
+				if results[chunk].err != nil {
+					for failed := firstFailed.Load(); int64(chunk) < failed; failed = firstFailed.Load() {
+						if firstFailed.CompareAndSwap(failed, int64(chunk)) {
+							break
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+		total += len(result.items)
+	}
+	filtered := make([]reflect.Value, 0, total)
+	for _, result := range results {
+		filtered = append(filtered, result.items...)
+	}
+	return filtered, nil
+}
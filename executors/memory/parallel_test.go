@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parallelOptions(parallelism, chunkSize int) *MemoryExecutorOptions {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.MaxPageSize = 1000
+	return &MemoryExecutorOptions{ExecutorOptions: opts, Parallelism: parallelism, ChunkSize: chunkSize}
+}
+
+func TestParallel_MatchesSequential(t *testing.T) {
+	products := genProducts()
+	for i := 0; i < 5; i++ {
+		for _, p := range genProducts() {
+			p.ID += (i + 1) * 100
+			products = append(products, p)
+		}
+	}
+	gen, err := NewQueryGenerator(genSchema, products, 11)
+	require.NoError(t, err)
+
+	sequential := NewExecutorWithOptions(products, parallelOptions(0, 0))
+	parallel := NewExecutorWithOptions(products, parallelOptions(4, 3))
+
+	for i := 0; i < 200; i++ {
+		c, err := gen.Case()
+		require.NoError(t, err)
+		c.Query.PageSize = 1000
+
+		var expected, got []genProduct
+		_, expectedErr := sequential.Execute(context.Background(), c.Query, "", &expected)
+		_, err = parallel.Execute(context.Background(), c.Query, "", &got)
+		assert.Equal(t, expectedErr, err)
+		// Same items in the same order
+		assert.Equal(t, expected, got, "%#v", c.Query.Filter)
+	}
+}
+
+func TestParallel_SourceOrder(t *testing.T) {
+	data := make([]map[string]interface{}, 1000)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": i, "even": i%2 == 0}
+	}
+	executor := NewExecutorWithOptions(data, parallelOptions(8, 7))
+	compiled, err := executor.Compile(&query.Query{Filter: query.Eq("even", true)})
+	require.NoError(t, err)
+
+	filtered, err := executor.filterData(context.Background(), compiled.q.Filter, compiled.match, nil)
+	require.NoError(t, err)
+	require.Len(t, filtered, 500)
+	for i, item := range filtered {
+		assert.Equal(t, i*2, item.Interface().(map[string]interface{})["id"])
+	}
+}
+
+func TestParallel_FirstError(t *testing.T) {
+	data := make([]map[string]interface{}, 100)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": i}
+	}
+	opts := parallelOptions(4, 5)
+	opts.FieldGetter = func(obj interface{}, field string) (interface{}, error) {
+		id := (*obj.(*map[string]interface{}))["id"].(int)
+		if id%10 == 7 {
+			return nil, fmt.Errorf("item %d", id)
+		}
+		return id, nil
+	}
+	executor := NewExecutorWithOptions(data, opts)
+
+	for i := 0; i < 20; i++ {
+		_, err := executor.Count(context.Background(), &query.Query{Filter: query.Eq("id", 1)})
+		require.Error(t, err)
+		// Like a sequential scan, the first failing item is reported
+		assert.Contains(t, err.Error(), "item 7")
+	}
+}
+
+func TestParallel_Cancellation(t *testing.T) {
+	data := make([]Product, 10000)
+	for _, parallelism := range []int{0, 4} {
+		t.Run(fmt.Sprint(parallelism), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			evaluated := 0
+			opts := parallelOptions(parallelism, 10)
+			opts.FieldGetter = func(obj interface{}, field string) (interface{}, error) {
+				if parallelism == 0 {
+					evaluated++
+					if evaluated == 15 {
+						cancel()
+					}
+				} else {
+					cancel()
+				}
+				return 0, nil
+			}
+			executor := NewExecutorWithOptions(data, opts)
+
+			var results []Product
+			_, err := executor.Execute(ctx, &query.Query{Filter: query.Eq("id", 0)}, "", &results)
+			assert.True(t, errors.Is(err, context.Canceled))
+			if parallelism == 0 {
+				// Cancellation is checked between chunks
+				assert.Equal(t, 20, evaluated)
+			}
+		})
+	}
+}
+
+func BenchmarkParallelFilter(b *testing.B) {
+	data := make([]Product, 500000)
+	for i := range data {
+		data[i] = Product{ID: i, Name: fmt.Sprintf("Product %d", i), Category: []string{"electronics", "accessories"}[i%2], Price: float64(i % 100)}
+	}
+	filter, err := parser.ParseFilter(`category = electronics and price >= 50 and name LIKE "%9%"`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	q := &query.Query{Filter: filter}
+
+	for _, parallelism := range []int{1, 4, 8} {
+		executor := NewExecutorWithOptions(data, parallelOptions(parallelism, 0))
+		b.Run(fmt.Sprintf("Parallelism=%d", parallelism), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = executor.Count(context.Background(), q)
+			}
+		})
+	}
+}
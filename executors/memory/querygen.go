@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// QueryGenerator produces random queries that are valid for a schema, together with
+// the results the memory executor returns for them. Use it for property-based testing
+// of new executors and dialects: load the same data set into the executor under test,
+// run each generated query and compare its results with the expected matches.
+//
+// Literal values are sampled from the data set so that generated conditions match
+// a useful share of items. Generated queries only use operators with portable
+// semantics for the field's kind; REGEX and MATCH are never generated.
+//
+// Example:
+//
+//	gen, err := memory.NewQueryGenerator(schema, products, 42)
+//	for i := 0; i < 500; i++ {
+//	    c, err := gen.Case()
+//	    // run c.Query against the executor under test and
+//	    // compare the returned IDs with products[c.Matches[...]]
+//	}
+type QueryGenerator struct {
+	// MaxDepth limits the nesting depth of generated filters. Defaults to 3
+	MaxDepth int
+
+	schema   query.Schema
+	fields   []string
+	data     reflect.Value
+	rnd      *rand.Rand
+	executor *MemoryExecutor
+	pools    map[string][]interface{}
+}
+
+// GeneratedCase is a generated query and the items it matches
+type GeneratedCase struct {
+	// Query is a valid query for the generator's schema
+	Query *query.Query
+	// Matches holds the indexes of the matching items, in data set order
+	Matches []int
+}
+
+// NewQueryGenerator creates a generator for queries over data, a slice of structs
+// or maps, restricted to the fields of schema. The same seed and data always
+// produce the same sequence of queries.
+func NewQueryGenerator(schema query.Schema, data interface{}, seed int64) (*QueryGenerator, error) {
+	dataVal := reflect.ValueOf(data)
+	if dataVal.Kind() == reflect.Ptr {
+		dataVal = dataVal.Elem()
+	}
+	if dataVal.Kind() != reflect.Slice || len(schema) == 0 {
+		return nil, query.ErrInvalidQuery
+	}
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSearchField = ""
+	g := &QueryGenerator{
+		MaxDepth: 3,
+		schema:   schema,
+		fields:   schema.FieldNames(),
+		data:     dataVal,
+		rnd:      rand.New(rand.NewSource(seed)),
+		executor: NewExecutor(data, opts),
+		pools:    make(map[string][]interface{}),
+	}
+	g.samplePools()
+	return g, nil
+}
+
+// samplePools collects the literal values of every schema field in the data set
+func (g *QueryGenerator) samplePools() {
+	for _, field := range g.fields {
+		for i := 0; i < g.data.Len(); i++ {
+			val, err := g.executor.getFieldValue(g.data.Index(i), field)
+			if err != nil {
+				continue
+			}
+			if g.schema[field] == query.FieldKindArray {
+				elems := reflect.ValueOf(val)
+				if elems.Kind() == reflect.Slice || elems.Kind() == reflect.Array {
+					for j := 0; j < elems.Len(); j++ {
+						if v := literalValue(elems.Index(j).Interface()); v != nil {
+							g.pools[field] = append(g.pools[field], v)
+						}
+					}
+				}
+				continue
+			}
+			if v := literalValue(val); v != nil {
+				g.pools[field] = append(g.pools[field], v)
+			}
+		}
+	}
+}
+
+// Filter returns a random filter over the schema fields
+func (g *QueryGenerator) Filter() query.Node {
+	return g.node(1)
+}
+
+// Query returns a random query whose page holds the whole data set, so a single
+// execution returns every match
+func (g *QueryGenerator) Query() *query.Query {
+	pageSize := g.data.Len()
+	if pageSize == 0 {
+		pageSize = 1
+	}
+	return &query.Query{
+		Filter:    g.Filter(),
+		PageSize:  pageSize,
+		SortOrder: query.SortOrderAsc,
+	}
+}
+
+// Case returns a random query and the indexes of the items the memory executor matches
+func (g *QueryGenerator) Case() (*GeneratedCase, error) {
+	q := g.Query()
+	c := &GeneratedCase{Query: q}
+	for i := 0; i < g.data.Len(); i++ {
+		match, err := g.executor.evaluateFilter(q.Filter, g.data.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			c.Matches = append(c.Matches, i)
+		}
+	}
+	return c, nil
+}
+
+// node returns a comparison or, above the depth limit, possibly an AND/OR of two nodes
+func (g *QueryGenerator) node(depth int) query.Node {
+	if depth >= g.MaxDepth || g.rnd.Intn(3) == 0 {
+		return g.comparison()
+	}
+	op := query.BinaryOpAnd
+	if g.rnd.Intn(2) == 0 {
+		op = query.BinaryOpOr
+	}
+	return &query.BinaryOpNode{
+		Operator: op,
+		Left:     g.node(depth + 1),
+		Right:    g.node(depth + 1),
+	}
+}
+
+// generatedOperators lists the operators generated for each field kind
+var generatedOperators = map[query.FieldKind][]query.ComparisonOperator{
+	query.FieldKindString: {
+		query.OpEqual, query.OpNotEqual, query.OpLike, query.OpNotLike, query.OpContains,
+		query.OpIContains, query.OpStartsWith, query.OpEndsWith, query.OpIn, query.OpNotIn,
+	},
+	query.FieldKindInt: {
+		query.OpEqual, query.OpNotEqual, query.OpGreaterThan, query.OpGreaterThanOrEqual,
+		query.OpLessThan, query.OpLessThanOrEqual, query.OpIn, query.OpNotIn,
+	},
+	query.FieldKindFloat: {
+		query.OpEqual, query.OpNotEqual, query.OpGreaterThan, query.OpGreaterThanOrEqual,
+		query.OpLessThan, query.OpLessThanOrEqual, query.OpIn, query.OpNotIn,
+	},
+	query.FieldKindDateTime: {
+		query.OpGreaterThan, query.OpGreaterThanOrEqual, query.OpLessThan, query.OpLessThanOrEqual,
+	},
+	query.FieldKindBool:  {query.OpEqual, query.OpNotEqual},
+	query.FieldKindArray: {query.OpContains},
+}
+
+// comparison returns a random comparison on a random schema field
+func (g *QueryGenerator) comparison() *query.ComparisonNode {
+	field := g.fields[g.rnd.Intn(len(g.fields))]
+	kind := g.schema[field]
+	ops := generatedOperators[kind]
+	op := ops[g.rnd.Intn(len(ops))]
+
+	n := &query.ComparisonNode{Field: field, Operator: op}
+	switch op {
+	case query.OpIn, query.OpNotIn:
+		arr := make(query.ArrayValue, 1+g.rnd.Intn(3))
+		for i := range arr {
+			arr[i] = g.sample(field, kind)
+		}
+		n.Value = arr
+	case query.OpLike, query.OpNotLike:
+		s := g.sampleString(field)
+		n.Value = query.StringValue(s[:g.rnd.Intn(len(s)+1)] + "%")
+	case query.OpContains, query.OpIContains:
+		if kind == query.FieldKindArray {
+			n.Value = g.sample(field, kind)
+			break
+		}
+		s := g.sampleString(field)
+		start := g.rnd.Intn(len(s) + 1)
+		sub := s[start : start+g.rnd.Intn(len(s)-start+1)]
+		if op == query.OpIContains {
+			sub = strings.ToUpper(sub)
+		}
+		n.Value = query.StringValue(sub)
+	case query.OpStartsWith:
+		s := g.sampleString(field)
+		n.Value = query.StringValue(s[:g.rnd.Intn(len(s)+1)])
+	case query.OpEndsWith:
+		s := g.sampleString(field)
+		n.Value = query.StringValue(s[g.rnd.Intn(len(s)+1):])
+	default:
+		n.Value = g.sample(field, kind)
+	}
+	return n
+}
+
+// sample returns a literal sampled from the field's values, or a fallback of the field's kind
+func (g *QueryGenerator) sample(field string, kind query.FieldKind) interface{} {
+	if pool := g.pools[field]; len(pool) > 0 {
+		v := pool[g.rnd.Intn(len(pool))]
+		// Keep literals compatible with the declared kind
+		switch kind {
+		case query.FieldKindFloat:
+			if i, ok := v.(query.IntValue); ok {
+				return query.FloatValue(i)
+			}
+		case query.FieldKindString:
+			if _, ok := v.(query.StringValue); !ok {
+				return query.StringValue(literalString(v))
+			}
+		}
+		return v
+	}
+	switch kind {
+	case query.FieldKindInt:
+		return query.IntValue(g.rnd.Intn(100))
+	case query.FieldKindFloat:
+		return query.FloatValue(float64(g.rnd.Intn(10000)) / 100)
+	case query.FieldKindBool:
+		return query.BoolValue(g.rnd.Intn(2) == 0)
+	case query.FieldKindDateTime:
+		return query.DateTimeValue(time.Unix(int64(g.rnd.Intn(1<<30)), 0).UTC())
+	default:
+		return query.StringValue("a")
+	}
+}
+
+// sampleString returns a sampled string value of the field; slicing works on bytes,
+// so non-ASCII samples are used whole
+func (g *QueryGenerator) sampleString(field string) string {
+	s := literalString(g.sample(field, query.FieldKindString))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return ""
+		}
+	}
+	return s
+}
+
+// literalValue converts a Go field value into a query literal, or nil if unsupported
+func literalValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return query.DateTimeValue(t)
+	}
+	val := reflect.ValueOf(v)
+	switch val.Kind() {
+	case reflect.String:
+		return query.StringValue(val.String())
+	case reflect.Bool:
+		return query.BoolValue(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return query.IntValue(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return query.IntValue(int64(val.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return query.FloatValue(val.Float())
+	default:
+		return nil
+	}
+}
+
+// literalString returns the text form of a scalar query literal
+func literalString(v interface{}) string {
+	switch val := v.(type) {
+	case query.StringValue:
+		return string(val)
+	case query.DateTimeValue:
+		return time.Time(val).String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type genProduct struct {
+	ID        int
+	Name      string
+	Price     float64
+	Stock     int
+	Featured  bool
+	Tags      []string
+	CreatedAt time.Time
+}
+
+var genSchema = query.Schema{
+	"id":        query.FieldKindInt,
+	"name":      query.FieldKindString,
+	"price":     query.FieldKindFloat,
+	"stock":     query.FieldKindInt,
+	"featured":  query.FieldKindBool,
+	"tags":      query.FieldKindArray,
+	"createdat": query.FieldKindDateTime,
+}
+
+func genProducts() []genProduct {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	names := []string{"Wireless Mouse", "USB Cable", "Keyboard", "Monitor Stand", "Webcam HD", "USB Hub"}
+	var products []genProduct
+	for i, name := range names {
+		products = append(products, genProduct{
+			ID:        i + 1,
+			Name:      name,
+			Price:     float64(10*(i+1)) + 0.99,
+			Stock:     (i * 37) % 120,
+			Featured:  i%2 == 0,
+			Tags:      []string{[]string{"usb", "office", "gaming"}[i%3], "sale"},
+			CreatedAt: base.Add(time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	return products
+}
+
+func TestQueryGenerator_Deterministic(t *testing.T) {
+	a, err := NewQueryGenerator(genSchema, genProducts(), 7)
+	require.NoError(t, err)
+	b, err := NewQueryGenerator(genSchema, genProducts(), 7)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, a.Query(), b.Query())
+	}
+}
+
+func TestQueryGenerator_Cases(t *testing.T) {
+	products := genProducts()
+	gen, err := NewQueryGenerator(genSchema, products, 42)
+	require.NoError(t, err)
+	limits := &query.ExecutorOptions{MaxFilterDepth: gen.MaxDepth}
+
+	// The executor under test; here the memory executor itself with a different sort
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.DefaultSortOrder = query.SortOrderDesc
+	executor := NewExecutor(products, opts)
+
+	var none, all, some int
+	for i := 0; i < 300; i++ {
+		c, err := gen.Case()
+		require.NoError(t, err)
+		require.NoError(t, query.ValidateAgainstSchema(c.Query, genSchema))
+		require.NoError(t, limits.ValidateFilter(c.Query.Filter))
+
+		var results []genProduct
+		result, err := executor.Execute(context.Background(), c.Query, "", &results)
+		if errors.Is(err, query.ErrNoRecordsFound) {
+			err = nil
+		}
+		require.NoError(t, err)
+
+		var expected, got []int
+		for _, idx := range c.Matches {
+			expected = append(expected, products[idx].ID)
+		}
+		for _, p := range results {
+			got = append(got, p.ID)
+		}
+		assert.ElementsMatch(t, expected, got)
+		if result != nil {
+			assert.Equal(t, int64(len(c.Matches)), result.TotalItems)
+		}
+
+		switch len(c.Matches) {
+		case 0:
+			none++
+		case len(products):
+			all++
+		default:
+			some++
+		}
+	}
+	// Sampled literals produce selective queries, not only trivial ones
+	assert.Greater(t, some, 100)
+	assert.Greater(t, none, 0)
+	assert.Greater(t, all, 0)
+}
+
+func TestQueryGenerator_Maps(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"sku": "A-1", "qty": 3},
+		{"sku": "B-2", "qty": 0},
+	}
+	gen, err := NewQueryGenerator(query.Schema{"sku": query.FieldKindString, "qty": query.FieldKindInt}, rows, 1)
+	require.NoError(t, err)
+	gen.MaxDepth = 1
+
+	c, err := gen.Case()
+	require.NoError(t, err)
+	_, isComparison := c.Query.Filter.(*query.ComparisonNode)
+	assert.True(t, isComparison)
+
+	_, err = NewQueryGenerator(query.Schema{}, rows, 1)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	_, err = NewQueryGenerator(genSchema, genProduct{}, 1)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+}
+
+func TestQueryGenerator_FormatRoundTrip(t *testing.T) {
+	// Values Format used to write ambiguously: exponents and fractional seconds
+	products := genProducts()
+	for i := range products {
+		products[i].Price = []float64{1000000.5, -0.000001, 1e21, 2}[i%4]
+		products[i].CreatedAt = products[i].CreatedAt.Add(time.Duration(i) * 1500 * time.Microsecond)
+	}
+	gen, err := NewQueryGenerator(genSchema, products, 5)
+	require.NoError(t, err)
+	opts := query.DefaultExecutorOptions()
+	opts.AllowEmptyResults = true
+	executor := NewExecutor(products, opts)
+
+	ids := func(q *query.Query) []int {
+		var results []genProduct
+		_, err := executor.Execute(context.Background(), q, "", &results)
+		require.NoError(t, err)
+		var ids []int
+		for _, p := range results {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	for i := 0; i < 300; i++ {
+		q := gen.Query()
+		formatted := query.Format(q)
+		p, err := parser.NewParser(formatted)
+		require.NoError(t, err, formatted)
+		reparsed, err := p.Parse()
+		require.NoError(t, err, formatted)
+
+		assert.Equal(t, formatted, query.Format(reparsed))
+		assert.Equal(t, ids(q), ids(reparsed), formatted)
+	}
+}
@@ -84,6 +84,22 @@ func TestMemoryExecutor_AllowedFields(t *testing.T) {
 		}
 	})
 
+	t.Run("restricted access - suggest allowed fields", func(t *testing.T) {
+		opts := query.DefaultExecutorOptions()
+		opts.AllowedFields = []string{"id", "name", "email"}
+		executor := NewExecutor(users, opts)
+
+		p, _ := parser.NewParser(`emial = "bob@example.com"`)
+		q, _ := p.Parse()
+
+		var results []User
+		_, err := executor.Execute(context.Background(), q, "", &results)
+		var fieldErr *query.FieldError
+		require.True(t, errors.As(err, &fieldErr))
+		assert.Equal(t, []string{"email"}, fieldErr.Suggestions)
+		assert.Contains(t, err.Error(), "did you mean: email?")
+	})
+
 	t.Run("restricted access - block SSN field", func(t *testing.T) {
 		opts := query.DefaultExecutorOptions()
 		opts.AllowedFields = []string{"id", "name", "email"}
@@ -214,3 +230,189 @@ func TestMemoryExecutor_MapWithAllowedFields(t *testing.T) {
 		assert.True(t, errors.Is(err, query.ErrFieldNotAllowed))
 	})
 }
+
+func TestSecurity_SortFieldValidation(t *testing.T) {
+	data := getTestData()
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.AllowedFields = []string{"id", "name", "price"}
+	executor := NewExecutor(data, opts)
+
+	p, _ := parser.NewParser("sort_by = pirce")
+	q, _ := p.Parse()
+
+	var results []Product
+	_, err := executor.Execute(context.Background(), q, "", &results)
+	assert.ErrorIs(t, err, query.ErrInvalidSortField)
+
+	var sortErr *query.SortFieldError
+	require.True(t, errors.As(err, &sortErr))
+	assert.Equal(t, []string{"price"}, sortErr.Suggestions)
+}
+
+func TestMemoryExecutor_BaseFilter(t *testing.T) {
+	type Document struct {
+		ID       int
+		TenantID int
+		Title    string
+	}
+	docs := []Document{
+		{ID: 1, TenantID: 1, Title: "Invoice"},
+		{ID: 2, TenantID: 2, Title: "Invoice"},
+		{ID: 3, TenantID: 1, Title: "Receipt"},
+		{ID: 4, TenantID: 2, Title: "Receipt"},
+	}
+
+	base, err := parser.ParseFilter("tenantid = 1")
+	require.NoError(t, err)
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.AllowedFields = []string{"id", "title"}
+	opts.BaseFilter = base
+	executor := NewExecutor(docs, opts)
+	ctx := context.Background()
+
+	ids := func(input string) []int {
+		p, err := parser.NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+
+		var results []Document
+		_, err = executor.Execute(ctx, q, "", &results)
+		if errors.Is(err, query.ErrNoRecordsFound) {
+			return nil
+		}
+		require.NoError(t, err)
+		var out []int
+		for _, d := range results {
+			out = append(out, d.ID)
+		}
+		return out
+	}
+
+	assert.Equal(t, []int{1, 3}, ids(""))
+	assert.Equal(t, []int{1}, ids("title = Invoice"))
+	// OR cannot escape the scope
+	assert.Equal(t, []int{1, 3}, ids("title = Invoice OR title = Receipt"))
+	// The scoped field is queryable, but only narrows the result
+	assert.Nil(t, ids("tenantid = 2"))
+
+	count, err := executor.Count(ctx, &query.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMemoryExecutor_FieldPolicy(t *testing.T) {
+	users := []User{
+		{ID: 1, Name: "Alice", Email: "alice@example.com"},
+		{ID: 2, Name: "Bob", Email: "bob@example.com"},
+	}
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.FieldPolicy = map[string][]query.ComparisonOperator{
+		"email": {query.OpEqual},
+	}
+	executor := NewExecutor(users, opts)
+	ctx := context.Background()
+
+	p, _ := parser.NewParser(`email = "bob@example.com"`)
+	q, _ := p.Parse()
+	var results []User
+	_, err := executor.Execute(ctx, q, "", &results)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	// Enumerating emails by pattern is rejected
+	p, _ = parser.NewParser(`name = Alice OR email ENDS_WITH "@example.com"`)
+	q, _ = p.Parse()
+	_, err = executor.Execute(ctx, q, "", &results)
+	var opErr *query.OperatorError
+	require.True(t, errors.As(err, &opErr))
+	assert.Equal(t, "email", opErr.Field)
+	assert.Equal(t, query.OpEndsWith, opErr.Operator)
+
+	_, err = executor.Count(ctx, q)
+	assert.ErrorIs(t, err, query.ErrOperatorNotAllowed)
+}
+
+type roleKey struct{}
+
+func TestMemoryExecutor_FieldAuthorizer(t *testing.T) {
+	users := []User{
+		{ID: 1, Name: "Alice", SSN: "111"},
+		{ID: 2, Name: "Bob", SSN: "222"},
+	}
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.FieldAuthorizer = func(ctx context.Context, field string, op query.ComparisonOperator) error {
+		if field == "ssn" && ctx.Value(roleKey{}) != "admin" {
+			return query.FieldNotAllowedError(field)
+		}
+		return nil
+	}
+	executor := NewExecutor(users, opts)
+	admin := context.WithValue(context.Background(), roleKey{}, "admin")
+	user := context.WithValue(context.Background(), roleKey{}, "user")
+
+	p, _ := parser.NewParser(`ssn = "222"`)
+	q, _ := p.Parse()
+	var results []User
+	_, err := executor.Execute(admin, q, "", &results)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Bob", results[0].Name)
+
+	_, err = executor.Execute(user, q, "", &results)
+	assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	_, err = executor.Count(user, q)
+	assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+
+	// Sorting by a field reveals its order, so sort_by is checked too
+	p, _ = parser.NewParser(`name != "" sort_by = ssn`)
+	q, _ = p.Parse()
+	_, err = executor.Execute(user, q, "", &results)
+	assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	_, err = executor.Execute(admin, q, "", &results)
+	assert.NoError(t, err)
+}
+
+func TestMemoryExecutor_ComplexityLimits(t *testing.T) {
+	users := []User{{ID: 1, Name: "Alice"}, {ID: 2, Name: "Bob"}}
+
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "id"
+	opts.MaxConditions = 2
+	opts.MaxInArraySize = 3
+	executor := NewExecutor(users, opts)
+	ctx := context.Background()
+
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"id = 1 OR name = Bob", false},
+		{"id = 1 OR name = Bob OR name = Carol", true},
+		{"id IN [1, 2, 3]", false},
+		{"id IN [1, 2, 3, 4]", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, _ := parser.NewParser(tt.input)
+			q, _ := p.Parse()
+
+			var results []User
+			_, err := executor.Execute(ctx, q, "", &results)
+			_, countErr := executor.Count(ctx, q)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, query.ErrQueryTooComplex)
+				assert.ErrorIs(t, countErr, query.ErrQueryTooComplex)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, countErr)
+		})
+	}
+}
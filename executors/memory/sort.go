@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"container/heap"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// partialSortRatio is how many times larger than the requested prefix a set
+// must be before sortTop selects the prefix with a bounded heap instead of
+// sorting the whole set
+const partialSortRatio = 4
+
+// sortEntry is an item with its sort field resolved once, so comparisons do
+// not look the field up (and format it) again
+type sortEntry struct {
+	item  reflect.Value
+	index int // position before sorting, used as the final tie-breaker
+
+	value   interface{} // nil for nil values and fields that could not be read
+	num     float64
+	numeric bool
+	str     string
+}
+
+// sortEntries resolves the sort field of every item
+func (e *MemoryExecutor) sortEntries(data []reflect.Value, sortField string) []sortEntry {
+	entries := make([]sortEntry, len(data))
+	for i, item := range data {
+		entry := sortEntry{item: item, index: i}
+		if val, err := e.getFieldValue(item, sortField); err == nil && val != nil {
+			entry.value = val
+			entry.num, entry.numeric = e.toFloat64(val)
+			entry.str = fmt.Sprintf("%v", val)
+		}
+		entries[i] = entry
+	}
+	return entries
+}
+
+// entryLess orders entries like compareLess orders values. Nil values sort
+// last in either order and ties keep the original order, so the ordering is
+// total and any prefix of it can be selected on its own
+func entryLess(a, b *sortEntry, sortOrder query.SortOrder) bool {
+	if a.value == nil || b.value == nil {
+		if a.value == nil && b.value == nil {
+			return a.index < b.index
+		}
+		return b.value == nil
+	}
+
+	var cmp int
+	if a.numeric && b.numeric {
+		switch {
+		case a.num < b.num:
+			cmp = -1
+		case a.num > b.num:
+			cmp = 1
+		}
+	} else {
+		switch {
+		case a.str < b.str:
+			cmp = -1
+		case a.str > b.str:
+			cmp = 1
+		}
+	}
+	if cmp == 0 {
+		return a.index < b.index
+	}
+	if sortOrder == query.SortOrderDesc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// sortData sorts a slice of reflect.Values
+func (e *MemoryExecutor) sortData(data []reflect.Value, sortField string, sortOrder query.SortOrder) {
+	entries := e.sortEntries(data, sortField)
+	sort.Slice(entries, func(i, j int) bool {
+		return entryLess(&entries[i], &entries[j], sortOrder)
+	})
+	for i := range entries {
+		data[i] = entries[i].item
+	}
+}
+
+// sortTop places the first k items of the sorted order at the start of data,
+// in order. The contents of the rest of data are unspecified.
+// When k is small compared to the set, the prefix is selected with a bounded
+// heap in O(n log k) instead of sorting the whole set
+func (e *MemoryExecutor) sortTop(data []reflect.Value, sortField string, sortOrder query.SortOrder, k int) {
+	if k <= 0 {
+		return
+	}
+	if k*partialSortRatio > len(data) {
+		e.sortData(data, sortField, sortOrder)
+		return
+	}
+
+	entries := e.sortEntries(data, sortField)
+	h := &entryHeap{order: sortOrder, entries: make([]*sortEntry, 0, k)}
+	for i := range entries {
+		entry := &entries[i]
+		if len(h.entries) < k {
+			heap.Push(h, entry)
+			continue
+		}
+		// The root is the last of the k best entries seen so far
+		if entryLess(entry, h.entries[0], sortOrder) {
+			h.entries[0] = entry
+			heap.Fix(h, 0)
+		}
+	}
+
+	top := h.entries
+	sort.Slice(top, func(i, j int) bool {
+		return entryLess(top[i], top[j], sortOrder)
+	})
+	for i, entry := range top {
+		data[i] = entry.item
+	}
+}
+
+// entryHeap is a max-heap of entries: the root is the entry that sorts last
+type entryHeap struct {
+	order   query.SortOrder
+	entries []*sortEntry
+}
+
+func (h *entryHeap) Len() int { return len(h.entries) }
+
+func (h *entryHeap) Less(i, j int) bool {
+	return entryLess(h.entries[j], h.entries[i], h.order)
+}
+
+func (h *entryHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *entryHeap) Push(x interface{}) { h.entries = append(h.entries, x.(*sortEntry)) }
+
+func (h *entryHeap) Pop() interface{} {
+	last := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return last
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tiedProducts returns products with many equal prices and some nil prices
+func tiedProducts(n int) []nullableProduct {
+	products := make([]nullableProduct, n)
+	for i := range products {
+		products[i] = nullableProduct{ID: i + 1}
+		if i%7 != 0 {
+			price := float64((i * 37) % 13)
+			products[i].Price = &price
+		}
+	}
+	return products
+}
+
+func TestSortTop_MatchesFullSort(t *testing.T) {
+	data := tiedProducts(500)
+	executor := NewExecutor(data, nil)
+	values := func() []reflect.Value {
+		items := make([]reflect.Value, len(data))
+		for i := range data {
+			items[i] = reflect.ValueOf(data[i])
+		}
+		return items
+	}
+	ids := func(items []reflect.Value) []int {
+		out := make([]int, len(items))
+		for i, item := range items {
+			out[i] = item.Interface().(nullableProduct).ID
+		}
+		return out
+	}
+
+	for _, order := range []query.SortOrder{query.SortOrderAsc, query.SortOrderDesc} {
+		full := values()
+		executor.sortData(full, "Price", order)
+		for _, k := range []int{1, 10, 37, 100, 124, 125, 500} {
+			top := values()
+			executor.sortTop(top, "Price", order, k)
+			assert.Equal(t, ids(full[:k]), ids(top[:k]), "order %s, k %d", order, k)
+		}
+	}
+}
+
+func TestSortTop_NilLast(t *testing.T) {
+	data := tiedProducts(100)
+	executor := NewExecutor(data, nil)
+	items := make([]reflect.Value, len(data))
+	for i := range data {
+		items[i] = reflect.ValueOf(data[i])
+	}
+
+	executor.sortTop(items, "Price", query.SortOrderDesc, 20)
+	for _, item := range items[:20] {
+		assert.NotNil(t, item.Interface().(nullableProduct).Price)
+	}
+}
+
+func TestMemoryExecutor_PartialSortPagination(t *testing.T) {
+	data := tiedProducts(1000)
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSortField = "ID"
+	opts.MaxPageSize = 1000
+	executor := NewExecutor(data, opts)
+	ctx := context.Background()
+
+	q := &query.Query{SortBy: "Price", SortOrder: query.SortOrderDesc, PageSize: 25}
+	seen := make(map[int]bool)
+	var pages [][]nullableProduct
+	cursorParam := ""
+	for {
+		var page []nullableProduct
+		result, err := executor.Execute(ctx, q, cursorParam, &page)
+		require.NoError(t, err)
+		for _, p := range page {
+			assert.False(t, seen[p.ID], "item %d returned twice", p.ID)
+			seen[p.ID] = true
+		}
+		pages = append(pages, page)
+		if result.NextPageCursor == "" {
+			break
+		}
+		cursorParam = result.NextPageCursor
+	}
+	assert.Len(t, seen, len(data))
+
+	// Pages line up with a single full sort
+	var all []nullableProduct
+	q.PageSize = 1000
+	_, err := executor.Execute(ctx, q, "", &all)
+	require.NoError(t, err)
+	var paged []nullableProduct
+	for _, page := range pages {
+		paged = append(paged, page...)
+	}
+	assert.Equal(t, all, paged)
+}
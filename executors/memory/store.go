@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Store holds a slice that can be modified while queries run on it.
+// Writers work on a copy of the slice (copy-on-write), so each Execute or Count
+// call reads one consistent snapshot and never races with Update.
+//
+// Snapshots are shallow copies: replace changed items instead of modifying
+// them through pointers or maps shared with earlier snapshots.
+//
+// Use DataSource to query the store:
+//
+//	store := memory.NewStore(products)
+//	executor := memory.NewExecutorWithDataSource(store.DataSource(), opts)
+//	store.Update(func(data interface{}) interface{} {
+//	    return append(data.([]Product), newProduct)
+//	})
+type Store struct {
+	// writeMu serializes writers, so an Update sees the result of the previous one
+	writeMu sync.Mutex
+
+	mu       sync.RWMutex
+	data     interface{}
+	version  uint64
+	onChange []*func()
+}
+
+// NewStore creates a store holding a copy of data, which must be a slice
+// (e.g., []MyStruct{} or []map[string]interface{}{})
+func NewStore(data interface{}) *Store {
+	return &Store{data: copySlice(data)}
+}
+
+// Snapshot returns the current data. The snapshot is never modified by the
+// store and must not be modified by the caller
+func (s *Store) Snapshot() interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data
+}
+
+// Version returns the number of changes made to the store
+func (s *Store) Version() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// DataSource returns a data source reading the current snapshot
+func (s *Store) DataSource() DataSourceFunc {
+	return s.Snapshot
+}
+
+// Update replaces the data with the result of fn. fn receives a copy of the
+// current snapshot, of the same slice type, that it may modify and append to.
+// Queries running meanwhile keep reading the previous snapshot.
+// Update panics when fn does not return a slice
+func (s *Store) Update(fn func(data interface{}) interface{}) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.set(fn(copySlice(s.Snapshot())))
+}
+
+// write replaces the data with the result of fn, which receives the current
+// snapshot and must not modify it. The data is kept when fn fails or returns
+// nil
+func (s *Store) write(fn func(data interface{}) (interface{}, error)) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	data, err := fn(s.Snapshot())
+	if err != nil || data == nil {
+		return err
+	}
+	s.set(data)
+	return nil
+}
+
+// Replace replaces the data with a copy of data, which must be a slice
+func (s *Store) Replace(data interface{}) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.set(copySlice(data))
+}
+
+// OnChange registers fn to be called after each change, e.g. to rebuild the
+// indexes of an executor or drop results cached from earlier snapshots:
+//
+//	store.OnChange(executor.Reindex)
+//
+// Hooks run in registration order on the goroutine that changed the store,
+// after the new snapshot is visible to queries. Hooks must not change the store
+func (s *Store) OnChange(fn func()) {
+	s.subscribe(fn)
+}
+
+// subscribe registers fn like OnChange and returns a function that removes it
+func (s *Store) subscribe(fn func()) (unsubscribe func()) {
+	hook := &fn
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = append(s.onChange, hook)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// set runs hooks from a copy of the slice header, so build a new slice
+		hooks := make([]*func(), 0, len(s.onChange))
+		for _, h := range s.onChange {
+			if h != hook {
+				hooks = append(hooks, h)
+			}
+		}
+		s.onChange = hooks
+	}
+}
+
+func (s *Store) set(data interface{}) {
+	if reflect.ValueOf(data).Kind() != reflect.Slice {
+		panic(fmt.Sprintf("memory: store data must be a slice, got %T", data))
+	}
+	s.mu.Lock()
+	s.data = data
+	s.version++
+	hooks := s.onChange
+	s.mu.Unlock()
+
+	for _, fn := range hooks {
+		(*fn)()
+	}
+}
+
+// copySlice returns a copy of a slice with its own backing array
+func copySlice(data interface{}) interface{} {
+	val := reflect.ValueOf(data)
+	if val.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("memory: store data must be a slice, got %T", data))
+	}
+	copied := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+	reflect.Copy(copied, val)
+	return copied.Interface()
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_CopyOnWrite(t *testing.T) {
+	data := []Product{{ID: 1, Brand: "Anker"}}
+	store := NewStore(data)
+	data[0].Brand = "Sony" // the store holds its own copy
+
+	before := store.Snapshot().([]Product)
+	store.Update(func(data interface{}) interface{} {
+		products := data.([]Product)
+		products[0].Brand = "JBL"
+		return append(products, Product{ID: 2, Brand: "Anker"})
+	})
+
+	assert.Equal(t, []Product{{ID: 1, Brand: "Anker"}}, before)
+	assert.Equal(t, []Product{{ID: 1, Brand: "JBL"}, {ID: 2, Brand: "Anker"}}, store.Snapshot())
+	assert.Equal(t, uint64(1), store.Version())
+
+	store.Replace([]Product{})
+	assert.Empty(t, store.Snapshot())
+	assert.Equal(t, uint64(2), store.Version())
+
+	assert.Panics(t, func() { store.Replace(Product{}) })
+	assert.Panics(t, func() { NewStore(nil) })
+}
+
+func TestStore_ConcurrentExecute(t *testing.T) {
+	store := NewStore([]Product{})
+	executor := NewExecutorWithDataSource(store.DataSource(), nil)
+	q := &query.Query{Filter: query.Eq("brand", "Anker")}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			store.Update(func(data interface{}) interface{} {
+				products := data.([]Product)
+				for j := range products {
+					products[j].Stock++
+				}
+				return append(products, Product{ID: i, Brand: "Anker"})
+			})
+		}
+	}()
+
+	// Run with -race: queries read snapshots while the writer updates
+	for i := 0; i < 200; i++ {
+		var results []Product
+		_, err := executor.Execute(context.Background(), q, "", &results)
+		if err != nil {
+			require.ErrorIs(t, err, query.ErrNoRecordsFound)
+		}
+		for j, p := range results {
+			// Every snapshot is consistent: older items were updated more often
+			if j > 0 {
+				assert.Equal(t, results[j-1].Stock-1, p.Stock)
+			}
+		}
+	}
+	wg.Wait()
+
+	count, err := executor.Count(context.Background(), q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), count)
+}
+
+func TestStore_OnChangeReindex(t *testing.T) {
+	store := NewStore([]Product{{ID: 1, Brand: "Anker"}, {ID: 2, Brand: "Sony"}})
+	executor := NewIndexedExecutorWithDataSource(store.DataSource(), nil, WithHashIndex("brand"))
+
+	replaceBrand := func(data interface{}) interface{} {
+		products := data.([]Product)
+		products[1].Brand = "Anker"
+		return products
+	}
+
+	// Indexes of an older snapshot are not used, even for data of the same size
+	store.Update(replaceBrand)
+	positions, _, indexed := executor.candidates(query.Eq("brand", "Anker"), reflect.ValueOf(store.Snapshot()))
+	assert.False(t, indexed)
+	assert.Nil(t, positions)
+
+	store.OnChange(executor.Reindex)
+	store.Update(func(data interface{}) interface{} { return data })
+	positions, _, indexed = executor.candidates(query.Eq("brand", "Anker"), reflect.ValueOf(store.Snapshot()))
+	assert.True(t, indexed)
+	assert.Equal(t, []int{0, 1}, positions)
+
+	count, err := executor.Count(context.Background(), &query.Query{Filter: query.Eq("brand", "Anker")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,309 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// WatchEventType is the kind of change a WatchEvent reports
+type WatchEventType int
+
+const (
+	// WatchAdd reports an item that started matching the query, or that
+	// matched when the watch started
+	WatchAdd WatchEventType = iota
+	// WatchUpdate reports a matching item that changed and still matches
+	WatchUpdate
+	// WatchRemove reports an item that stopped matching or was removed
+	WatchRemove
+	// WatchError reports that the query can no longer be evaluated. It is
+	// the last event before the channel is closed
+	WatchError
+)
+
+// String returns the string representation of WatchEventType
+func (t WatchEventType) String() string {
+	switch t {
+	case WatchAdd:
+		return "add"
+	case WatchUpdate:
+		return "update"
+	case WatchRemove:
+		return "remove"
+	case WatchError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// WatchEvent is a change in the set of items matching a watched query
+type WatchEvent struct {
+	Type WatchEventType
+
+	// ID is the value of the item's ID field
+	ID interface{}
+
+	// Item is the matching item. For WatchRemove, it is the item as it last matched
+	Item interface{}
+
+	// Previous is the item before a WatchUpdate
+	Previous interface{}
+
+	// Err is the evaluation error of a WatchError event
+	Err error
+}
+
+// WatchOptions configure WatchWithOptions
+type WatchOptions struct {
+	// Changes signals that the data may have changed, e.g. from the code that
+	// modifies the data source. Executors of a Store (NewExecutorWithStore)
+	// follow the store without it
+	Changes <-chan struct{}
+
+	// Interval re-evaluates the query periodically, for relative times
+	// (updated_at > now-5m) or data that changes without a signal. 0 disables it
+	Interval time.Duration
+}
+
+// Watch reports the items of the executor's Store that start or stop matching
+// the filter of q, e.g. to keep a live dashboard current. See WatchWithOptions
+func (e *MemoryExecutor) Watch(ctx context.Context, q *query.Query) (<-chan WatchEvent, error) {
+	return e.WatchWithOptions(ctx, q, nil)
+}
+
+// WatchWithOptions reports the items that start or stop matching the filter
+// of q. The channel first receives a WatchAdd for every matching item, then
+// the differences between consecutive evaluations, which run after each
+// change signal. Changes made while an evaluation runs or while events wait
+// to be received are coalesced, so consumers see states, not every write.
+//
+// Items are identified by IDFieldName ("id" by default), which must be set
+// and unique among matching items, and are compared with reflect.DeepEqual:
+// replace changed items instead of modifying them in place. Sorting and
+// pagination options are ignored.
+//
+// The filter is validated, authorized and scoped like Execute does, and
+// placeholders are resolved with ctx. The channel is closed when ctx is done
+// or after a WatchError event. Executors without a Store need
+// opts.Changes or opts.Interval
+func (e *MemoryExecutor) WatchWithOptions(ctx context.Context, q *query.Query, opts *WatchOptions) (<-chan WatchEvent, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	if opts == nil {
+		opts = &WatchOptions{}
+	}
+	e = e.withCurrentOptions()
+	if e.store == nil && opts.Changes == nil && opts.Interval <= 0 {
+		return nil, fmt.Errorf("%w: Watch needs a Store, WatchOptions.Changes or WatchOptions.Interval", query.ErrInvalidQuery)
+	}
+
+	entry := query.NewQueryLog(e.Name(), "watch", q)
+	events, err := e.watch(ctx, q, opts, &entry)
+	e.options.LogWatch(ctx, entry, err)
+	return events, query.WrapError(e.Name(), "watch", err)
+}
+
+// watch starts a watch of q, setting entry.Filter when q is compiled once
+func (e *MemoryExecutor) watch(ctx context.Context, q *query.Query, opts *WatchOptions, entry *query.QueryLog) (<-chan WatchEvent, error) {
+	w := &watcher{e: e, q: q, idField: e.options.IDFieldName}
+	if w.idField == "" {
+		w.idField = "id"
+	}
+	if !query.HasDynamicValues(q.Filter) {
+		compiled, err := e.compile(q)
+		if err != nil {
+			return nil, err
+		}
+		w.compiled = compiled
+		entry.Filter = compiled.q.Filter
+	}
+
+	// Subscribe before the first evaluation so no change is missed
+	signal := make(chan struct{}, 1)
+	unsubscribe := func() {}
+	if e.store != nil {
+		unsubscribe = e.store.subscribe(func() {
+			select {
+			case signal <- struct{}{}:
+			default:
+			}
+		})
+	}
+	state, err := w.evaluate(ctx)
+	if err != nil {
+		unsubscribe()
+		return nil, err
+	}
+
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		defer unsubscribe()
+		w.run(ctx, opts, signal, state, events)
+	}()
+	return events, nil
+}
+
+// watcher evaluates a watched query
+type watcher struct {
+	e        *MemoryExecutor
+	q        *query.Query
+	compiled *CompiledQuery // nil when q has placeholders or relative times
+	idField  string
+}
+
+// watchState is the set of matching items of one evaluation, in data order
+type watchState struct {
+	keys  []string
+	items map[string]watchedItem
+}
+
+type watchedItem struct {
+	id   interface{}
+	item interface{}
+}
+
+// run sends the initial state and the changes of every later evaluation
+func (w *watcher) run(ctx context.Context, opts *WatchOptions, signal <-chan struct{}, state *watchState, events chan<- WatchEvent) {
+	var tick <-chan time.Time
+	if opts.Interval > 0 {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	changes := opts.Changes
+
+	if !sendEvents(ctx, events, diffWatchStates(&watchState{}, state)) {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signal:
+		case <-tick:
+		case _, ok := <-changes:
+			if !ok {
+				changes = nil
+				continue
+			}
+		}
+
+		next, err := w.evaluate(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				sendEvents(ctx, events, []WatchEvent{{Type: WatchError, Err: err}})
+			}
+			return
+		}
+		if !sendEvents(ctx, events, diffWatchStates(state, next)) {
+			return
+		}
+		state = next
+	}
+}
+
+// evaluate returns the items of the current data matching the query
+func (w *watcher) evaluate(ctx context.Context) (*watchState, error) {
+	e := w.e.withRegexDeadline()
+	q, compiled := w.q, w.compiled
+	if compiled == nil {
+		var err error
+		if q, err = e.options.ResolvePlaceholders(ctx, q); err != nil {
+			return nil, err
+		}
+		if compiled, err = w.e.compile(q); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+
+	state := &watchState{items: make(map[string]watchedItem)}
+	data := e.dataSource()
+	if data == nil {
+		return state, nil
+	}
+	dataVal := reflect.ValueOf(data)
+	if dataVal.Kind() == reflect.Ptr {
+		dataVal = dataVal.Elem()
+	}
+	if dataVal.Kind() != reflect.Slice {
+		return nil, query.ErrInvalidQuery
+	}
+
+	for i := 0; i < dataVal.Len(); i++ {
+		if i%DefaultChunkSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		item := dataVal.Index(i)
+		if compiled.match != nil {
+			matched, err := compiled.match(item, e.regexDeadline)
+			if err != nil {
+				return nil, wrapEvaluateError(err)
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		id, err := e.getFieldValue(item, w.idField)
+		if err != nil {
+			return nil, query.NewFieldError(w.idField, err)
+		}
+		if id == nil {
+			return nil, fmt.Errorf("%w: watched item at index %d has no %s", query.ErrInvalidQuery, i, w.idField)
+		}
+		key := e.orderKey(id)
+		if _, dup := state.items[key]; dup {
+			return nil, fmt.Errorf("%w: watched items share %s %v", query.ErrInvalidQuery, w.idField, id)
+		}
+		state.keys = append(state.keys, key)
+		state.items[key] = watchedItem{id: id, item: item.Interface()}
+	}
+	return state, nil
+}
+
+// diffWatchStates returns the events turning prev into next: removals in
+// the order of prev, then additions and updates in the order of next
+func diffWatchStates(prev, next *watchState) []WatchEvent {
+	var events []WatchEvent
+	for _, key := range prev.keys {
+		if _, ok := next.items[key]; !ok {
+			old := prev.items[key]
+			events = append(events, WatchEvent{Type: WatchRemove, ID: old.id, Item: old.item})
+		}
+	}
+	for _, key := range next.keys {
+		cur := next.items[key]
+		old, ok := prev.items[key]
+		switch {
+		case !ok:
+			events = append(events, WatchEvent{Type: WatchAdd, ID: cur.id, Item: cur.item})
+		case !reflect.DeepEqual(old.item, cur.item):
+			events = append(events, WatchEvent{Type: WatchUpdate, ID: cur.id, Item: cur.item, Previous: old.item})
+		}
+	}
+	return events
+}
+
+// sendEvents sends events in order, reporting false when ctx is done first
+func sendEvents(ctx context.Context, events chan<- WatchEvent, batch []WatchEvent) bool {
+	for _, event := range batch {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
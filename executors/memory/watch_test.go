@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextEvent receives one event or fails the test after a second
+func nextEvent(t *testing.T, events <-chan WatchEvent) WatchEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "watch channel closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no watch event")
+		return WatchEvent{}
+	}
+}
+
+func TestMemoryExecutor_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := NewStore([]Product{
+		{ID: 1, Brand: "Anker", Price: 20},
+		{ID: 2, Brand: "Sony", Price: 200},
+	})
+	opts := query.DefaultExecutorOptions()
+	opts.AllowWrites = true
+	exec := NewExecutorWithStore(store, opts)
+	p, err := parser.NewParser("price < 100")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	events, err := exec.Watch(ctx, q)
+	require.NoError(t, err)
+
+	event := nextEvent(t, events)
+	assert.Equal(t, WatchAdd, event.Type)
+	assert.Equal(t, 1, event.ID)
+
+	// Sony drops below 100 and starts matching
+	store.Update(func(data interface{}) interface{} {
+		products := data.([]Product)
+		products[1].Price = 90
+		return products
+	})
+	event = nextEvent(t, events)
+	assert.Equal(t, WatchAdd, event.Type)
+	assert.Equal(t, Product{ID: 2, Brand: "Sony", Price: 90}, event.Item)
+
+	// A matching item changes
+	store.Update(func(data interface{}) interface{} {
+		products := data.([]Product)
+		products[0].Price = 25
+		return products
+	})
+	event = nextEvent(t, events)
+	assert.Equal(t, WatchUpdate, event.Type)
+	assert.Equal(t, Product{ID: 1, Brand: "Anker", Price: 20}, event.Previous)
+	assert.Equal(t, Product{ID: 1, Brand: "Anker", Price: 25}, event.Item)
+
+	// Anker is deleted and stops matching
+	_, err = exec.DeleteWhere(ctx, &query.Query{Filter: &query.ComparisonNode{Field: "brand", Operator: query.OpEqual, Value: query.StringValue("Anker")}})
+	require.NoError(t, err)
+	event = nextEvent(t, events)
+	assert.Equal(t, WatchRemove, event.Type)
+	assert.Equal(t, 1, event.ID)
+
+	cancel()
+	for range events {
+	}
+	assert.Empty(t, store.onChange, "the watch unsubscribes from the store")
+}
+
+func TestMemoryExecutor_WatchWithOptions(t *testing.T) {
+	t.Run("change feed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		data := []map[string]interface{}{{"id": "a", "status": "open"}}
+		exec := NewExecutorWithDataSource(func() interface{} { return data }, nil)
+		changes := make(chan struct{}, 1)
+		events, err := exec.WatchWithOptions(ctx, &query.Query{
+			Filter: &query.ComparisonNode{Field: "status", Operator: query.OpEqual, Value: query.StringValue("open")},
+		}, &WatchOptions{Changes: changes})
+		require.NoError(t, err)
+		assert.Equal(t, "a", nextEvent(t, events).ID)
+
+		data = []map[string]interface{}{{"id": "a", "status": "closed"}, {"id": "b", "status": "open"}}
+		changes <- struct{}{}
+		event := nextEvent(t, events)
+		assert.Equal(t, WatchRemove, event.Type)
+		assert.Equal(t, "a", event.ID)
+		event = nextEvent(t, events)
+		assert.Equal(t, WatchAdd, event.Type)
+		assert.Equal(t, "b", event.ID)
+	})
+
+	t.Run("evaluation errors end the watch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		data := []map[string]interface{}{{"id": 1}}
+		exec := NewExecutorWithDataSource(func() interface{} { return data }, nil)
+		changes := make(chan struct{}, 1)
+		events, err := exec.WatchWithOptions(ctx, &query.Query{}, &WatchOptions{Changes: changes})
+		require.NoError(t, err)
+		nextEvent(t, events)
+
+		data = append(data, map[string]interface{}{"id": 1})
+		changes <- struct{}{}
+		event := nextEvent(t, events)
+		assert.Equal(t, WatchError, event.Type)
+		assert.ErrorIs(t, event.Err, query.ErrInvalidQuery)
+		_, ok := <-events
+		assert.False(t, ok)
+	})
+
+	t.Run("logs the watch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var entries []query.QueryLog
+		opts := query.DefaultExecutorOptions()
+		opts.BaseFilter = &query.ComparisonNode{Field: "brand", Operator: query.OpEqual, Value: query.StringValue("Anker")}
+		opts.QueryLogger = query.QueryLoggerFunc(func(ctx context.Context, entry query.QueryLog) {
+			entries = append(entries, entry)
+		})
+		exec := NewExecutorWithStore(NewStore([]Product{{ID: 1, Brand: "Anker"}}), opts)
+		_, err := exec.Watch(ctx, &query.Query{})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "watch", entries[0].Operation)
+		assert.Equal(t, `brand = "Anker"`, entries[0].Query)
+	})
+
+	t.Run("invalid watches", func(t *testing.T) {
+		ctx := context.Background()
+		exec := NewExecutor([]Product{{ID: 1}}, nil)
+		_, err := exec.Watch(ctx, &query.Query{})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+		_, err = exec.WatchWithOptions(ctx, nil, &WatchOptions{Interval: time.Second})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+		exec = NewExecutorWithStore(NewStore([]Product{{ID: 1}}), &query.ExecutorOptions{AllowedFields: []string{"price"}})
+		_, err = exec.Watch(ctx, &query.Query{})
+		assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	})
+}
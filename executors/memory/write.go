@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// DeleteWhere removes the items matching the query's filter. Executors of a
+// Store (NewExecutorWithStore) change it copy-on-write, so running queries
+// are not affected. Otherwise the data source must return a pointer to a
+// slice, which is replaced without synchronization: do not query it meanwhile
+func (e *MemoryExecutor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "delete", q)
+	affected, err := e.write(ctx, q, nil, &entry)
+	e.options.LogWrite(ctx, entry, affected, err)
+	return affected, query.WrapError(e.Name(), "delete", err)
+}
+
+// UpdateWhere sets the fields in changes on the items matching the query's
+// filter, like DeleteWhere changes the data. Updated items are copies: struct
+// fields and map keys are found like the filter finds them, and values are
+// converted between numeric types. FieldGetter is not used for writes
+func (e *MemoryExecutor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "update", q)
+	if changes == nil {
+		changes = map[string]interface{}{}
+	}
+	affected, err := e.write(ctx, q, changes, &entry)
+	e.options.LogWrite(ctx, entry, affected, err)
+	return affected, query.WrapError(e.Name(), "update", err)
+}
+
+// write deletes the items matching q, or updates them with changes when it is not nil
+func (e *MemoryExecutor) write(ctx context.Context, q *query.Query, changes map[string]interface{}, entry *query.QueryLog) (int64, error) {
+	if err := e.options.ValidateWrite(q); err != nil {
+		return 0, err
+	}
+	if changes != nil {
+		var err error
+		if changes, err = e.options.ValidateChanges(ctx, changes); err != nil {
+			return 0, err
+		}
+	}
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
+	}
+	compiled, err := e.compile(q)
+	if err != nil {
+		return 0, err
+	}
+	entry.Filter = compiled.q.Filter
+
+	var affected int64
+	rewrite := func(data interface{}) (interface{}, error) {
+		var rewritten interface{}
+		var err error
+		rewritten, affected, err = e.withRegexDeadline().rewrite(ctx, data, compiled.match, changes)
+		if affected == 0 {
+			return nil, err
+		}
+		return rewritten, err
+	}
+	if e.store != nil {
+		if err := e.store.write(rewrite); err != nil {
+			return 0, err
+		}
+		return affected, nil
+	}
+
+	ptr := reflect.ValueOf(e.dataSource())
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Slice {
+		return 0, fmt.Errorf("%w: memory writes need a Store or a data source returning a pointer to a slice", query.ErrWritesNotSupported)
+	}
+	rewritten, err := rewrite(ptr.Elem().Interface())
+	if err != nil || rewritten == nil {
+		return 0, err
+	}
+	ptr.Elem().Set(reflect.ValueOf(rewritten))
+	e.Reindex()
+	return affected, nil
+}
+
+// rewrite returns a copy of the data slice without the items accepted by
+// match, or with changes applied to them when changes is not nil
+func (e *MemoryExecutor) rewrite(ctx context.Context, data interface{}, match predicate, changes map[string]interface{}) (interface{}, int64, error) {
+	dataVal := reflect.ValueOf(data)
+	if dataVal.Kind() != reflect.Slice {
+		return nil, 0, query.ErrInvalidQuery
+	}
+	rewritten := reflect.MakeSlice(dataVal.Type(), 0, dataVal.Len())
+	var affected int64
+	for i := 0; i < dataVal.Len(); i++ {
+		if i%DefaultChunkSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+		}
+		item := dataVal.Index(i)
+		matched, err := match(item, e.regexDeadline)
+		if err != nil {
+			return nil, 0, wrapEvaluateError(err)
+		}
+		if !matched {
+			rewritten = reflect.Append(rewritten, item)
+			continue
+		}
+		affected++
+		if changes == nil {
+			continue
+		}
+		updated, err := withChanges(item, changes)
+		if err != nil {
+			return nil, 0, err
+		}
+		rewritten = reflect.Append(rewritten, updated)
+	}
+	return rewritten.Interface(), affected, nil
+}
+
+// withChanges returns a copy of item (a struct, pointer or map, or an
+// interface holding one) with the fields in changes set
+func withChanges(item reflect.Value, changes map[string]interface{}) (reflect.Value, error) {
+	switch item.Kind() {
+	case reflect.Interface:
+		if item.IsNil() {
+			return item, nil
+		}
+		updated, err := withChanges(item.Elem(), changes)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		wrapped := reflect.New(item.Type()).Elem()
+		wrapped.Set(updated)
+		return wrapped, nil
+
+	case reflect.Ptr:
+		if item.IsNil() {
+			return item, nil
+		}
+		updated, err := withChanges(item.Elem(), changes)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(item.Type().Elem())
+		ptr.Elem().Set(updated)
+		return ptr, nil
+
+	case reflect.Struct:
+		copied := reflect.New(item.Type()).Elem()
+		copied.Set(item)
+		for field, value := range changes {
+			target, ok := structField(copied, field)
+			if !ok {
+				return reflect.Value{}, query.InvalidFieldNameError(field)
+			}
+			if err := assignValue(target, value); err != nil {
+				return reflect.Value{}, query.NewFieldError(field, err)
+			}
+		}
+		return copied, nil
+
+	case reflect.Map:
+		if item.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, query.ErrInvalidQuery
+		}
+		copied := reflect.MakeMapWithSize(item.Type(), item.Len()+len(changes))
+		iter := item.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), iter.Value())
+		}
+		for field, value := range changes {
+			target := reflect.New(item.Type().Elem()).Elem()
+			if err := assignValue(target, value); err != nil {
+				return reflect.Value{}, query.NewFieldError(field, err)
+			}
+			copied.SetMapIndex(mapKey(copied, field), target)
+		}
+		return copied, nil
+
+	default:
+		return reflect.Value{}, query.ErrInvalidQuery
+	}
+}
+
+// structField finds the settable field of a struct matched by name, like
+// getFieldValue: by field name or json/bson tag, ignoring case
+func structField(item reflect.Value, name string) (reflect.Value, bool) {
+	typ := item.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if strings.EqualFold(field.Name, name) ||
+			strings.EqualFold(strings.Split(field.Tag.Get("json"), ",")[0], name) ||
+			strings.EqualFold(strings.Split(field.Tag.Get("bson"), ",")[0], name) {
+			return item.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// mapKey returns the existing key of m matching name, ignoring case, or name
+// itself for new keys
+func mapKey(m reflect.Value, name string) reflect.Value {
+	key := reflect.ValueOf(name).Convert(m.Type().Key())
+	if m.MapIndex(key).IsValid() {
+		return key
+	}
+	iter := m.MapRange()
+	for iter.Next() {
+		if strings.EqualFold(iter.Key().String(), name) {
+			return iter.Key()
+		}
+	}
+	return key
+}
+
+// assignValue sets target to value, allocating pointers and converting
+// between numeric types. nil sets the zero value
+func assignValue(target reflect.Value, value interface{}) error {
+	typ := target.Type()
+	if value == nil {
+		target.Set(reflect.Zero(typ))
+		return nil
+	}
+	val := reflect.ValueOf(value)
+	switch {
+	case val.Type().AssignableTo(typ):
+		target.Set(val)
+	case typ.Kind() == reflect.Ptr:
+		ptr := reflect.New(typ.Elem())
+		if err := assignValue(ptr.Elem(), value); err != nil {
+			return err
+		}
+		target.Set(ptr)
+	case isNumericKind(val.Kind()) && isNumericKind(typ.Kind()),
+		val.Kind() == typ.Kind() && val.Type().ConvertibleTo(typ):
+		target.Set(val.Convert(typ))
+	default:
+		return fmt.Errorf("%w: cannot set %T on a %s field", query.ErrTypeMismatch, value, typ)
+	}
+	return nil
+}
+
+// isNumericKind reports whether k is an integer or floating-point kind
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
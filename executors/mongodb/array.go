@@ -0,0 +1,70 @@
+package mongodb
+
+import (
+	"fmt"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// lengthOperators maps comparison operators to aggregation expression operators
+var lengthOperators = map[query.ComparisonOperator]string{
+	query.OpEqual:              "$eq",
+	query.OpNotEqual:           "$ne",
+	query.OpGreaterThan:        "$gt",
+	query.OpGreaterThanOrEqual: "$gte",
+	query.OpLessThan:           "$lt",
+	query.OpLessThanOrEqual:    "$lte",
+}
+
+// buildArrayFilter translates LENGTH, ANY and ALL conditions on an array field:
+//
+//	tags LENGTH = 3         {tags: {$size: 3}}
+//	tags LENGTH > 3         {tags: {$type: "array"}, $expr: {$gt: [{$size: ...}, 3]}}
+//	tags ANY = "usb"        {tags: {$elemMatch: {$eq: "usb"}}}
+//	tags ALL IN [usb, hub]  {tags: {$all: ["usb", "hub"]}}
+//
+// Fields that do not hold arrays never match
+func (e *Executor) buildArrayFilter(n *query.ComparisonNode, field string) (bson.M, error) {
+	if err := query.ValidateArrayCondition(n); err != nil {
+		return nil, err
+	}
+
+	switch n.Modifier {
+	case query.ArrayModifierLength:
+		count, ok := n.Value.(query.IntValue)
+		if !ok {
+			return nil, query.NewFieldError(field, fmt.Errorf("%w: LENGTH must be compared with an integer", query.ErrInvalidQuery))
+		}
+		if n.Operator == query.OpEqual {
+			return bson.M{field: bson.M{"$size": int64(count)}}, nil
+		}
+		// $size fails on other types, so they are replaced by an empty array
+		size := bson.M{"$size": bson.M{"$cond": bson.A{bson.M{"$isArray": "$" + field}, "$" + field, bson.A{}}}}
+		return bson.M{
+			field:   bson.M{"$type": "array"},
+			"$expr": bson.M{lengthOperators[n.Operator]: bson.A{size, int64(count)}},
+		}, nil
+
+	case query.ArrayModifierAny:
+		element, err := e.buildFilter(&query.ComparisonNode{Field: field, Operator: n.Operator, Value: n.Value})
+		if err != nil {
+			return nil, err
+		}
+		cond, ok := element[field].(bson.M)
+		if !ok {
+			cond = bson.M{"$eq": element[field]}
+		}
+		return bson.M{field: bson.M{"$elemMatch": cond}}, nil
+
+	default:
+		arr, err := e.convertArrayValue(field, n.Value)
+		if err != nil {
+			return nil, err
+		}
+		if len(arr) == 0 {
+			return bson.M{field: bson.M{"$type": "array"}}, nil
+		}
+		return bson.M{field: bson.M{"$all": arr}}, nil
+	}
+}
@@ -0,0 +1,40 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ExecuteBatch runs the queries in one causally consistent session, so later
+// queries see at least the data earlier ones read, and with one options
+// snapshot. A session is not safe for concurrent use, so CountAsync counts in
+// line
+func (e *Executor) ExecuteBatch(ctx context.Context, queries []*query.Query, dests []interface{}) ([]executor.BatchResult, error) {
+	if err := executor.ValidateBatch(queries, dests); err != nil {
+		return nil, err
+	}
+	bound := *e.withCurrentOptions()
+	bound.optionsProvider = nil
+	if bound.options.CountMode == query.CountAsync {
+		execOpts := *bound.options.ExecutorOptions
+		execOpts.CountMode = query.CountExact
+		opts := *bound.options
+		opts.ExecutorOptions = &execOpts
+		bound.options = &opts
+	}
+
+	results := make([]executor.BatchResult, len(queries))
+	err := e.collection.Database().Client().UseSession(ctx, func(sc mongo.SessionContext) error {
+		for i, q := range queries {
+			results[i].Result, results[i].Err = bound.Execute(sc, q, "", dests[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, query.NewExecutionError("start session", err)
+	}
+	return results, nil
+}
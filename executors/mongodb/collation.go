@@ -0,0 +1,15 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collation returns the driver collation of ExecutorOptions.Collation, nil
+// when unset
+func (e *Executor) collation() *options.Collation {
+	c := e.options.Collation
+	if c == nil {
+		return nil
+	}
+	return &options.Collation{Locale: c.Locale, Strength: c.Strength}
+}
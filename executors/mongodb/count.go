@@ -0,0 +1,142 @@
+package mongodb
+
+import (
+	"context"
+	"math"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultCountSampleSize is the number of documents sampled when CountSampleSize is 0
+const defaultCountSampleSize = 10000
+
+// countResult is the outcome of a count started by startCount
+type countResult struct {
+	total     int64
+	estimated bool
+	err       error
+}
+
+// startCount starts computing TotalItems for Execute as CountMode asks and
+// returns a function that waits for it. With CountAsync the count runs while
+// the caller fetches the page. hint is the index of the find and joins are the
+// relation stages of buildJoinedFilter
+func (e *Executor) startCount(ctx context.Context, q *query.Query, filter bson.M, hint string, joins mongo.Pipeline) func() countResult {
+	count := func() countResult {
+		if q.DistinctOn != "" {
+			total, err := e.countDistinct(ctx, filter, joins, q.DistinctOn)
+			return countResult{total: total, err: err}
+		}
+		if len(joins) > 0 {
+			// Joined documents are only counted exactly
+			total, err := e.countJoined(ctx, filter, joins)
+			return countResult{total: total, err: err}
+		}
+		total, estimated, err := e.countTotal(ctx, filter, hint)
+		return countResult{total: total, estimated: estimated, err: err}
+	}
+	switch e.options.CountMode {
+	case query.CountNone:
+		return func() countResult { return countResult{total: query.TotalUnknown} }
+	case query.CountAsync:
+		counted := make(chan countResult, 1)
+		go func() { counted <- count() }()
+		return func() countResult { return <-counted }
+	}
+	counted := count()
+	return func() countResult { return counted }
+}
+
+// countTotal computes TotalItems for Execute. Collections larger than
+// CountEstimateThreshold, or any collection with CountEstimated, get an
+// estimate: the collection metadata count for unfiltered queries, or the match
+// ratio of a random sample extrapolated to the collection size. estimated
+// reports whether the total is an estimate.
+func (e *Executor) countTotal(ctx context.Context, filter bson.M, hint string) (total int64, estimated bool, err error) {
+	if e.options.CountEstimateThreshold > 0 || e.options.CountMode == query.CountEstimated {
+		estimateOpts := options.EstimatedDocumentCount()
+		if e.options.MaxTime > 0 {
+			estimateOpts.SetMaxTime(e.options.MaxTime)
+		}
+		size, err := e.collection.EstimatedDocumentCount(ctx, estimateOpts)
+		if err != nil {
+			return 0, false, query.NewExecutionError("estimate document count", err)
+		}
+		// $text must be the first pipeline stage, so text searches are counted exactly
+		if size > e.options.CountEstimateThreshold && !hasTextSearch(filter) {
+			if len(filter) == 0 {
+				return size, true, nil
+			}
+			total, err := e.sampleCount(ctx, filter, size)
+			return total, true, err
+		}
+	}
+
+	total, err = e.collection.CountDocuments(ctx, filter, e.countOptions(hint))
+	if err != nil {
+		return 0, false, query.NewExecutionError("count documents", err)
+	}
+	return total, false, nil
+}
+
+// sampleCount counts filter matches in a $sample of the collection and
+// extrapolates the ratio to size documents
+func (e *Executor) sampleCount(ctx context.Context, filter bson.M, size int64) (int64, error) {
+	sampleSize := e.options.CountSampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultCountSampleSize
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$sample", Value: bson.M{"size": sampleSize}}},
+		{{Key: "$facet", Value: bson.M{
+			"sampled": bson.A{bson.M{"$count": "n"}},
+			"matched": bson.A{bson.M{"$match": filter}, bson.M{"$count": "n"}},
+		}}},
+	}
+	cur, err := e.collection.Aggregate(ctx, pipeline, e.aggregateOptions(""))
+	if err != nil {
+		return 0, query.NewExecutionError("sample count", err)
+	}
+	defer cur.Close(ctx)
+
+	var counts []struct {
+		Sampled []struct {
+			N int64 `bson:"n"`
+		} `bson:"sampled"`
+		Matched []struct {
+			N int64 `bson:"n"`
+		} `bson:"matched"`
+	}
+	if err := cur.All(ctx, &counts); err != nil {
+		return 0, query.NewExecutionError("sample count", err)
+	}
+	var sampled, matched int64
+	if len(counts) > 0 {
+		if len(counts[0].Sampled) > 0 {
+			sampled = counts[0].Sampled[0].N
+		}
+		if len(counts[0].Matched) > 0 {
+			matched = counts[0].Matched[0].N
+		}
+	}
+	return extrapolate(matched, sampled, size), nil
+}
+
+// extrapolate scales the matches found in a sample to the collection size.
+// A sample without matches estimates 0; any match estimates at least 1.
+func extrapolate(matched, sampled, size int64) int64 {
+	if sampled <= 0 || matched <= 0 {
+		return 0
+	}
+	estimate := int64(math.Round(float64(matched) / float64(sampled) * float64(size)))
+	if estimate < 1 {
+		estimate = 1
+	}
+	if estimate > size {
+		estimate = size
+	}
+	return estimate
+}
@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// distinctPipeline pages the first document, in the query's order, of each
+// value of q.DistinctOn: $match, the distinctStages, the order, $skip and $limit
+func (e *Executor) distinctPipeline(q *query.Query, filter bson.M, skip, limit int64) (mongo.Pipeline, error) {
+	stages, err := e.distinctStages(q, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	ordering, sampled, err := e.orderStages(q, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, stages...)
+	pipeline = append(pipeline, ordering...)
+	if !sampled {
+		if skip > 0 {
+			pipeline = append(pipeline, bson.D{{Key: "$skip", Value: skip}})
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+	if q.SortBy == query.MatchCountField {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.M{query.MatchCountField: 0}}})
+	}
+	if q.RandomSeed != 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.M{randomKeyField: 0}}})
+	}
+	return pipeline, nil
+}
+
+// distinctStages returns the stages that keep the first matched document, in
+// the query's order, of each value of q.DistinctOn:
+//
+//	<order>, {$group: {_id: "$field", doc: {$first: "$$ROOT"}}}, {$replaceRoot: {newRoot: "$doc"}}
+//
+// Random order keeps the document with the lowest ID of each value, or the
+// first in random order when the query has a seed. Relevance
+// scores do not survive $group, so _score cannot be combined with distinct_on
+func (e *Executor) distinctStages(q *query.Query, filter bson.M, pageSize int64) (mongo.Pipeline, error) {
+	if err := validFieldPath(q.DistinctOn); err != nil {
+		return nil, err
+	}
+	if q.SortBy == query.ScoreField {
+		return nil, fmt.Errorf("%w: sorting by %s cannot be combined with distinct_on", query.ErrInvalidQuery, query.ScoreField)
+	}
+	first, sampled, err := e.orderStages(q, filter, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if sampled {
+		first = mongo.Pipeline{{{Key: "$sort", Value: bson.D{{Key: e.getIDFieldName(), Value: 1}}}}}
+	}
+	return append(first,
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + q.DistinctOn},
+			{Key: "doc", Value: bson.M{"$first": "$$ROOT"}},
+		}}},
+		bson.D{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+	), nil
+}
+
+// countDistinct counts the distinct values of field among the documents
+// matching filter and the joins of buildJoinedFilter
+func (e *Executor) countDistinct(ctx context.Context, filter bson.M, joins mongo.Pipeline, field string) (int64, error) {
+	if err := validFieldPath(field); err != nil {
+		return 0, err
+	}
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, joins...)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$" + field}}},
+		bson.D{{Key: "$count", Value: "n"}},
+	)
+	cur, err := e.collection.Aggregate(ctx, pipeline, e.aggregateOptions(""))
+	if err != nil {
+		return 0, query.NewExecutionError("count distinct values", err)
+	}
+	defer cur.Close(ctx)
+
+	var counts []struct {
+		N int64 `bson:"n"`
+	}
+	if err := cur.All(ctx, &counts); err != nil {
+		return 0, query.NewExecutionError("count distinct values", err)
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0].N, nil
+}
+
+// validFieldPath rejects field paths that MongoDB would read as variables or
+// operators, such as $$ROOT or $where
+func validFieldPath(field string) error {
+	if field == "" || strings.HasPrefix(field, "$") || strings.ContainsRune(field, 0) {
+		return query.InvalidFieldNameError(field)
+	}
+	return nil
+}
@@ -2,11 +2,8 @@ package mongodb
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/binary"
 	"fmt"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/hadi77ir/go-query/executor"
@@ -15,26 +12,73 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Options configures the MongoDB executor beyond query.ExecutorOptions
+type Options struct {
+	*query.ExecutorOptions
+
+	// QueryOptions are the read preference, index hint, time limit and disk
+	// use of the executor's reads. WithQueryOptions overrides them per call
+	QueryOptions
+
+	// Relations lists the collections filters can reach through a field
+	// prefix, joined with $lookup. See RelationMap
+	Relations RelationMap
+}
+
 // Executor is the MongoDB implementation of the executor interface
 type Executor struct {
-	collection *mongo.Collection
-	options    *query.ExecutorOptions
+	collection      *mongo.Collection
+	options         *Options
+	optionsProvider query.OptionsProvider
 }
 
 // NewExecutor creates a new MongoDB executor
 func NewExecutor(collection *mongo.Collection, opts *query.ExecutorOptions) executor.Executor {
+	return NewExecutorWithOptions(collection, &Options{ExecutorOptions: opts})
+}
+
+// NewExecutorWithOptions creates a new MongoDB executor with MongoDB-specific options.
+// If opts.ExecutorOptions is nil, default options are used
+func NewExecutorWithOptions(collection *mongo.Collection, opts *Options) executor.Executor {
 	if opts == nil {
-		opts = query.DefaultExecutorOptions()
+		opts = &Options{}
+	}
+	resolved := *opts
+	if resolved.ExecutorOptions == nil {
+		resolved.ExecutorOptions = query.DefaultExecutorOptions()
 	}
 	return &Executor{
 		collection: collection,
-		options:    opts,
+		options:    &resolved,
+	}
+}
+
+// NewExecutorWithOptionsProvider creates a new MongoDB executor whose options are
+// fetched from the provider on every Execute/Count call
+// This allows allowlists, page caps and other policies to be changed at runtime
+func NewExecutorWithOptionsProvider(collection *mongo.Collection, provider query.OptionsProvider) executor.Executor {
+	return &Executor{
+		collection:      collection,
+		options:         &Options{ExecutorOptions: query.ResolveOptions(provider)},
+		optionsProvider: provider,
 	}
 }
 
+// withCurrentOptions returns an executor bound to the provider's current options snapshot
+// Executors without a provider are returned unchanged
+func (e *Executor) withCurrentOptions() *Executor {
+	if e.optionsProvider == nil {
+		return e
+	}
+	bound := *e
+	resolved := *e.options
+	resolved.ExecutorOptions = query.ResolveOptions(e.optionsProvider)
+	bound.options = &resolved
+	return &bound
+}
+
 // Name returns the name of this executor
 func (e *Executor) Name() string {
 	return "MongoDB"
@@ -48,6 +92,34 @@ func (e *Executor) Close() error {
 // Execute runs the query and stores results in dest
 // dest must be a pointer to a slice (e.g., &[]MyStruct{} or &[]bson.M{})
 func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam string, dest interface{}) (*query.Result, error) {
+	start := time.Now()
+	e = e.withCurrentOptions().withQueryOptions(ctx)
+	entry := query.NewQueryLog(e.Name(), "execute", q)
+	result, err := e.executeQuery(ctx, q, cursorParam, dest, &entry)
+	if result != nil {
+		result.ExecutionTime = time.Since(start)
+		result.Warnings = e.options.SearchTermWarnings(q)
+	}
+	e.options.LogExecute(ctx, entry, result, err)
+	return query.WrapResult(e.Name(), "execute", result, err)
+}
+
+func (e *Executor) executeQuery(ctx context.Context, q *query.Query, cursorParam string, dest interface{}, entry *query.QueryLog) (*query.Result, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	if err := e.options.ValidateDistinct(q); err != nil {
+		return &query.Result{Error: err}, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
 	result := &query.Result{}
 
 	// Validate and adjust page size
@@ -55,14 +127,17 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 
 	// Build MongoDB filter
 	filter := bson.M{}
+	var hint string
+	var joins mongo.Pipeline
 	if q.Filter != nil {
 		var err error
-		filter, err = e.buildFilter(q.Filter)
+		filter, hint, joins, err = e.buildJoinedFilter(q.Filter)
 		if err != nil {
 			result.Error = err
 			return result, err
 		}
 	}
+	hint = e.indexHint(hint)
 
 	// Handle cursor-based pagination
 	cursorData, err := cursor.Decode(cursorParam)
@@ -70,14 +145,30 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 		result.Error = fmt.Errorf("%w: %v", query.ErrInvalidCursor, err)
 		return result, result.Error
 	}
-
-	// Count total items
-	totalItems, err := e.collection.CountDocuments(ctx, filter)
-	if err != nil {
-		result.Error = query.NewExecutionError("count documents", err)
+	if err := cursorData.CheckQuery(q); err != nil {
+		result.Error = err
 		return result, result.Error
 	}
-	result.TotalItems = totalItems
+	if cursorData == nil {
+		offset, err := e.options.PageOffset(q, pageSize)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		cursorData = cursor.Jump(offset)
+	}
+
+	// Count total items; CountAsync counts while the page is fetched
+	waitCount := e.startCount(ctx, q, filter, hint, joins)
+	finishCount := func() error {
+		counted := waitCount()
+		if counted.err != nil {
+			result.Error = counted.err
+			return result.Error
+		}
+		result.TotalItems, result.TotalItemsEstimated = counted.total, counted.estimated
+		return nil
+	}
 
 	// Handle limit enforcement
 	itemsReturnedSoFar := 0
@@ -88,6 +179,9 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 		remaining := q.Limit - itemsReturnedSoFar
 		if remaining <= 0 {
 			// Limit already reached, return empty result
+			if err := finishCount(); err != nil {
+				return result, err
+			}
 			result.ItemsReturned = 0
 			result.ShowingFrom = 0
 			result.ShowingTo = 0
@@ -100,10 +194,14 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 	}
 
 	// Build find options
-	findOpts := options.Find()
+	findOpts := e.findOptions(hint)
 	findOpts.SetLimit(int64(pageSize + 1)) // Fetch one extra to check if there's a next page
 
 	// Handle sorting
+	if err := e.options.ValidateSortField(q.SortBy); err != nil {
+		result.Error = err
+		return result, result.Error
+	}
 	sortField := q.SortBy
 	if sortField == "" {
 		sortField = e.options.DefaultSortField
@@ -114,36 +212,98 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 		sortOrder = e.options.DefaultSortOrder
 	}
 
-	// Handle random ordering
+	inOrder, err := query.InOrderCondition(q)
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+
+	// Distinct values, preserved IN order, relevance, match counts and random
+	// ordering page by offset instead of by last ID
+	distinct := q.DistinctOn != ""
+	scoreSort := !distinct && inOrder == nil && sortField == query.ScoreField
+	matchSort := !distinct && inOrder == nil && sortField == query.MatchCountField
+	offsetPaging := distinct || inOrder != nil || scoreSort || matchSort || sortOrder == query.SortOrderRandom
+	// Pages jumped to by number skip by offset under any ordering; the pages
+	// after them follow keyset cursors again
+	jumped := cursorData != nil && cursorData.LastID == nil && cursorData.Offset > 0
+
+	// Random order hashes IDs with the seed of earlier pages, so pages of
+	// one order do not overlap
 	var randomSeed int64
 	if sortOrder == query.SortOrderRandom {
 		if !e.options.AllowRandomOrder {
 			result.Error = query.ErrRandomOrderNotAllowed
 			return result, result.Error
 		}
-
-		// Generate or reuse random seed
-		if cursorData != nil && cursorData.RandomSeed != 0 {
-			randomSeed = cursorData.RandomSeed
-		} else {
-			randomSeed = time.Now().UnixNano()
+		var cursorSeed int64
+		if cursorData != nil {
+			cursorSeed = cursorData.RandomSeed
 		}
+		randomSeed = query.ResolveRandomSeed(q, cursorSeed)
+	}
 
-		// For random ordering, we add a random sort key based on seed
-		// We hash each document's _id with the seed to get a consistent random order
-		findOpts.SetSort(bson.D{
-			{Key: "$expr", Value: bson.M{
-				"$mod": bson.A{
-					bson.M{"$toLong": "$_id"},
-					999999,
-				},
-			}},
-		})
+	var pipeline mongo.Pipeline
+	if distinct {
+		var skip int64
+		if cursorData != nil {
+			skip = int64(cursorData.Offset)
+		}
+		seeded := *q
+		seeded.RandomSeed = randomSeed
+		pipeline, err = e.distinctPipeline(&seeded, filter, skip, int64(pageSize+1))
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+	} else if inOrder != nil {
+		values, err := e.convertArrayValue(inOrder.Field, inOrder.Value)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		var skip int64
+		if cursorData != nil {
+			skip = int64(cursorData.Offset)
+		}
+		pipeline = inOrderPipeline(filter, inOrder.Field, values, skip, int64(pageSize+1))
+	} else if matchSort {
+		stages, err := e.matchCountStages(q.Filter)
+		if err != nil {
+			result.Error = err
+			return result, result.Error
+		}
+		pipeline = append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, stages...)
+		if cursorData != nil && cursorData.Offset > 0 {
+			pipeline = append(pipeline, bson.D{{Key: "$skip", Value: int64(cursorData.Offset)}})
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$limit", Value: int64(pageSize + 1)}},
+			bson.D{{Key: "$project", Value: bson.M{query.MatchCountField: 0}}},
+		)
+	} else if scoreSort {
+		// Relevance ordering requires a $text (MATCH) predicate
+		if !hasTextSearch(filter) {
+			result.Error = fmt.Errorf("%w: sorting by %s requires a MATCH condition", query.ErrInvalidQuery, query.ScoreField)
+			return result, result.Error
+		}
+		textScore := bson.M{"$meta": "textScore"}
+		findOpts.SetProjection(bson.M{query.ScoreField: textScore})
+		findOpts.SetSort(bson.D{{Key: query.ScoreField, Value: textScore}})
 
-		// Apply offset for cursor pagination in random mode
+		// Apply offset for cursor pagination in relevance mode
 		if cursorData != nil && cursorData.Offset > 0 {
 			findOpts.SetSkip(int64(cursorData.Offset))
 		}
+	} else if sortOrder == query.SortOrderRandom {
+		pipeline = append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, e.randomStages(randomSeed)...)
+		if cursorData != nil && cursorData.Offset > 0 {
+			pipeline = append(pipeline, bson.D{{Key: "$skip", Value: int64(cursorData.Offset)}})
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$limit", Value: int64(pageSize + 1)}},
+			bson.D{{Key: "$project", Value: bson.M{randomKeyField: 0}}},
+		)
 	} else {
 		// Regular sorting
 		sortOrderInt := 1
@@ -161,23 +321,32 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 			}
 			// Combine with existing filter
 			filter = bson.M{"$and": bson.A{filter, cursorFilter}}
+		} else if jumped {
+			findOpts.SetSkip(int64(cursorData.Offset))
 		}
 	}
 
+	if len(joins) > 0 {
+		pipeline = e.joinPipeline(pipeline, filter, findOpts, joins)
+	}
+
+	if q.ExplainRequested {
+		result.Explain = e.explain(ctx, e.explainCommand(filter, findOpts, pipeline), hint)
+	}
+
 	// Execute query
-	mongoCursor, err := e.collection.Find(ctx, filter, findOpts)
+	var mongoCursor *mongo.Cursor
+	if pipeline != nil {
+		mongoCursor, err = e.collection.Aggregate(ctx, pipeline, e.aggregateOptions(hint))
+	} else {
+		mongoCursor, err = e.collection.Find(ctx, filter, findOpts)
+	}
 	if err != nil {
 		result.Error = query.NewExecutionError("execute query", err)
 		return result, result.Error
 	}
 	defer mongoCursor.Close(ctx)
 
-	// Fetch results into dest
-	if err := mongoCursor.All(ctx, dest); err != nil {
-		result.Error = query.NewExecutionError("fetch results", err)
-		return result, result.Error
-	}
-
 	// Get slice length using reflection to check if there are more results
 	destValue := reflect.ValueOf(dest)
 	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
@@ -185,11 +354,34 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 		return result, result.Error
 	}
 
+	// Fetch results into dest
+	var scores []float64
+	if scoreSort {
+		var raws []bson.Raw
+		if err := mongoCursor.All(ctx, &raws); err != nil {
+			result.Error = query.NewExecutionError("fetch results", err)
+			return result, result.Error
+		}
+		scores, err = decodeScored(raws, destValue.Elem())
+		if err != nil {
+			result.Error = query.NewExecutionError("decode results", err)
+			return result, result.Error
+		}
+	} else if err := mongoCursor.All(ctx, dest); err != nil {
+		result.Error = query.NewExecutionError("fetch results", err)
+		return result, result.Error
+	}
+
+	if err := finishCount(); err != nil {
+		return result, err
+	}
+
 	sliceValue := destValue.Elem()
 	itemsCount := sliceValue.Len()
 
-	// Check if any records were found
-	if itemsCount == 0 && result.TotalItems == 0 {
+	// Check if any records were found; without a total, an empty first page means none
+	noRecords := result.TotalItems == 0 || result.TotalItems == query.TotalUnknown && cursorData == nil
+	if itemsCount == 0 && noRecords && !e.options.AllowEmptyResults {
 		result.Error = query.ErrNoRecordsFound
 		return result, result.Error
 	}
@@ -203,10 +395,13 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 	}
 
 	result.ItemsReturned = itemsCount
+	if scoreSort {
+		result.Scores = scores[:itemsCount]
+	}
 
 	// Calculate showing from/to
 	var currentOffset int
-	if cursorData != nil && sortOrder == query.SortOrderRandom {
+	if cursorData != nil && (offsetPaging || jumped) {
 		currentOffset = cursorData.Offset
 	}
 
@@ -245,9 +440,10 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 			nextCursorData := &cursor.CursorData{
 				Direction:     "next",
 				ItemsReturned: itemsReturnedSoFar + result.ItemsReturned,
+				QueryHash:     cursor.QueryHash(q),
 			}
 
-			if sortOrder == query.SortOrderRandom {
+			if offsetPaging {
 				nextCursorData.Offset = currentOffset + pageSize
 				nextCursorData.RandomSeed = randomSeed
 			} else {
@@ -274,9 +470,10 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 			prevCursorData := &cursor.CursorData{
 				Direction:     "prev",
 				ItemsReturned: prevItemsReturned,
+				QueryHash:     cursor.QueryHash(q),
 			}
 
-			if sortOrder == query.SortOrderRandom {
+			if offsetPaging || jumped {
 				prevOffset := currentOffset - pageSize
 				if prevOffset < 0 {
 					prevOffset = 0
@@ -316,40 +513,57 @@ func (e *Executor) Execute(ctx context.Context, q *query.Query, cursorParam stri
 func (e *Executor) buildFilter(node query.Node) (bson.M, error) {
 	switch n := node.(type) {
 	case *query.BinaryOpNode:
-		left, err := e.buildFilter(n.Left)
-		if err != nil {
-			return nil, err
-		}
-		right, err := e.buildFilter(n.Right)
-		if err != nil {
-			return nil, err
+		if n.Operator != query.BinaryOpAnd && n.Operator != query.BinaryOpOr {
+			return nil, query.ErrInvalidQuery
 		}
-
-		if n.Operator == query.BinaryOpAnd {
-			return bson.M{"$and": bson.A{left, right}}, nil
-		} else if n.Operator == query.BinaryOpOr {
-			return bson.M{"$or": bson.A{left, right}}, nil
+		// Chains of one operator become a single $and or $or
+		var clauses bson.A
+		for _, operand := range query.Operands(n) {
+			clause, err := e.buildFilter(operand)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, clause)
 		}
-		return nil, query.ErrInvalidQuery
+		return bson.M{"$" + n.Operator.String(): clauses}, nil
 
 	case *query.ComparisonNode:
 		// Handle default search field
 		field := n.Field
-		if field == "__DEFAULT_SEARCH__" {
+		if field == query.SearchField {
+			if expanded := e.options.ExpandPhrase(n, true); expanded != n {
+				return e.buildFilter(expanded)
+			}
+			if len(e.options.DefaultSearchFields) > 0 {
+				// Expand bare terms to an OR across all default search fields
+				return e.buildFilter(query.ExpandDefaultSearch(n, e.options.DefaultSearchFields))
+			}
 			field = e.options.DefaultSearchField
 		}
+		if err := validFieldPath(field); err != nil {
+			return nil, err
+		}
+		if n.Modifier != query.ArrayModifierNone {
+			return e.buildArrayFilter(n, field)
+		}
 		switch n.Operator {
 		case query.OpEqual:
 			value, err := e.convertValue(field, n.Value)
 			if err != nil {
 				return nil, err
 			}
+			if lo, hi, ok := e.options.FloatRange(value); ok {
+				return bson.M{field: bson.M{"$gte": lo, "$lte": hi}}, nil
+			}
 			return bson.M{field: value}, nil
 		case query.OpNotEqual:
 			value, err := e.convertValue(field, n.Value)
 			if err != nil {
 				return nil, err
 			}
+			if lo, hi, ok := e.options.FloatRange(value); ok {
+				return bson.M{field: bson.M{"$not": bson.M{"$gte": lo, "$lte": hi}}}, nil
+			}
 			return bson.M{field: bson.M{"$ne": value}}, nil
 		case query.OpGreaterThan:
 			value, err := e.convertValue(field, n.Value)
@@ -425,7 +639,7 @@ func (e *Executor) buildFilter(node query.Node) (bson.M, error) {
 			if err != nil {
 				return nil, err
 			}
-			str := fmt.Sprintf("%v", value)
+			str := e.options.ConditionPattern(n, fmt.Sprintf("%v", value))
 			return bson.M{field: bson.M{"$regex": str, "$options": ""}}, nil
 		case query.OpIn:
 			arr, err := e.convertArrayValue(field, n.Value)
@@ -439,6 +653,14 @@ func (e *Executor) buildFilter(node query.Node) (bson.M, error) {
 				return nil, err
 			}
 			return bson.M{field: bson.M{"$nin": arr}}, nil
+		case query.OpMatch:
+			// $text searches all fields covered by the collection's text index,
+			// so the field name is only used for allowlist purposes
+			value, err := e.convertValue(field, n.Value)
+			if err != nil {
+				return nil, err
+			}
+			return bson.M{"$text": bson.M{"$search": fmt.Sprintf("%v", value)}}, nil
 		default:
 			return nil, query.ErrInvalidQuery
 		}
@@ -543,40 +765,78 @@ func (e *Executor) buildCursorFilter(cursorData *cursor.CursorData, sortField st
 	}, nil
 }
 
-// hashID generates a hash for random ordering
-func hashID(id interface{}, seed int64) int64 {
-	h := md5.New()
-	h.Write([]byte(fmt.Sprintf("%v:%d", id, seed)))
-	sum := h.Sum(nil)
-	return int64(binary.BigEndian.Uint64(sum[:8]))
+// inOrderField holds each document's position in the IN values during aggregation
+const inOrderField = "__in_order"
+
+// inOrderPipeline builds an aggregation that returns the matching documents
+// ordered by the position of field in values ($indexOfArray), then paged
+func inOrderPipeline(filter bson.M, field string, values []interface{}, skip, limit int64) mongo.Pipeline {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{inOrderField: bson.M{"$indexOfArray": bson.A{values, "$" + field}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: inOrderField, Value: 1}}}},
+	}
+	if skip > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: skip}})
+	}
+	return append(pipeline,
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$project", Value: bson.M{inOrderField: 0}}},
+	)
+}
+
+// hasTextSearch reports whether a MongoDB filter contains a $text predicate
+func hasTextSearch(filter interface{}) bool {
+	switch f := filter.(type) {
+	case bson.M:
+		for key, value := range f {
+			if key == "$text" || hasTextSearch(value) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, value := range f {
+			if hasTextSearch(value) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-// likeToRegex converts SQL LIKE pattern to MongoDB regex
+// decodeScored decodes raw documents into the destination slice and returns
+// the text score of each document (read from the projected ScoreField)
+func decodeScored(raws []bson.Raw, slice reflect.Value) ([]float64, error) {
+	scores := make([]float64, len(raws))
+	items := reflect.MakeSlice(slice.Type(), 0, len(raws))
+	for i, raw := range raws {
+		if value, err := raw.LookupErr(query.ScoreField); err == nil {
+			if score, ok := value.DoubleOK(); ok {
+				scores[i] = score
+			}
+		}
+		item := reflect.New(slice.Type().Elem())
+		if err := bson.Unmarshal(raw, item.Interface()); err != nil {
+			return nil, err
+		}
+		items = reflect.Append(items, item.Elem())
+	}
+	slice.Set(items)
+	return scores, nil
+}
+
+// likeToRegex converts SQL LIKE pattern to MongoDB regex, reusing conversions
+// from the shared pattern cache
 func (e *Executor) likeToRegex(field string, value interface{}) (string, error) {
 	converted, err := e.convertValue(field, value)
 	if err != nil {
 		return "", err
 	}
-	str := fmt.Sprintf("%v", converted)
-	// Escape regex special characters except % and _
-	str = escapeRegex(str)
-	// Convert SQL wildcards to regex
-	str = strings.ReplaceAll(str, "%", ".*")
-	str = strings.ReplaceAll(str, "_", ".")
-	return "^" + str + "$", nil
-}
-
-// escapeRegex escapes regex special characters
-func escapeRegex(s string) string {
-	special := []string{".", "+", "*", "?", "^", "$", "(", ")", "[", "]", "{", "}", "|", "\\"}
-	result := s
-	for _, char := range special {
-		if char == "*" || char == "?" {
-			continue // These are handled by LIKE conversion
-		}
-		result = strings.ReplaceAll(result, char, "\\"+char)
+	re, err := query.CompileLike(fmt.Sprintf("%v", converted))
+	if err != nil {
+		return "", err
 	}
-	return result
+	return re.String(), nil
 }
 
 // convertArrayValue converts an array value to a slice for MongoDB and applies ValueConverter if configured
@@ -602,18 +862,51 @@ func (e *Executor) convertArrayValue(field string, val interface{}) ([]interface
 // Count returns the total number of items that would be returned by the given query
 // This does not apply pagination - it counts all matching items
 func (e *Executor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	e = e.withCurrentOptions().withQueryOptions(ctx)
+	entry := query.NewQueryLog(e.Name(), "count", q)
+	count, err := e.countQuery(ctx, q, &entry)
+	e.options.LogCount(ctx, entry, count, err)
+	return count, query.WrapError(e.Name(), "count", err)
+}
+
+func (e *Executor) countQuery(ctx context.Context, q *query.Query, entry *query.QueryLog) (int64, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateDistinct(q); err != nil {
+		return 0, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
 	// Build MongoDB filter
 	filter := bson.M{}
+	var hint string
+	var joins mongo.Pipeline
 	if q.Filter != nil {
 		var err error
-		filter, err = e.buildFilter(q.Filter)
+		filter, hint, joins, err = e.buildJoinedFilter(q.Filter)
 		if err != nil {
 			return 0, err
 		}
 	}
+	countOpts := e.countOptions(e.indexHint(hint))
 
 	// Count total items
-	totalItems, err := e.collection.CountDocuments(ctx, filter)
+	if q.DistinctOn != "" {
+		return e.countDistinct(ctx, filter, joins, q.DistinctOn)
+	}
+	if len(joins) > 0 {
+		return e.countJoined(ctx, filter, joins)
+	}
+	totalItems, err := e.collection.CountDocuments(ctx, filter, countOpts)
 	if err != nil {
 		return 0, query.NewExecutionError("count documents", err)
 	}
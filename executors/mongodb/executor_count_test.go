@@ -183,3 +183,42 @@ func TestMongoDBExecutor_Count(t *testing.T) {
 		}
 	})
 }
+
+func TestMongoDBExecutor_EstimatedTotal(t *testing.T) {
+	mongoC, collection := setupMongoContainer(t)
+	defer mongoC.Terminate(context.Background())
+
+	seedMongoTestData(t, collection)
+	ctx := context.Background()
+
+	opts := query.DefaultExecutorOptions()
+	opts.CountEstimateThreshold = 5
+	executor := NewExecutor(collection, opts)
+
+	t.Run("unfiltered total from collection metadata", func(t *testing.T) {
+		var docs []bson.M
+		result, err := executor.Execute(ctx, &query.Query{PageSize: 3}, "", &docs)
+		require.NoError(t, err)
+		assert.True(t, result.TotalItemsEstimated)
+		assert.Equal(t, int64(10), result.TotalItems)
+	})
+
+	t.Run("filtered total from sample", func(t *testing.T) {
+		// The sample covers the whole collection, so the estimate is exact
+		var docs []bson.M
+		result, err := executor.Execute(ctx, query.F("category").Eq("electronics").Build(), "", &docs)
+		require.NoError(t, err)
+		assert.True(t, result.TotalItemsEstimated)
+		assert.Equal(t, int64(5), result.TotalItems)
+	})
+
+	t.Run("small collections count exactly", func(t *testing.T) {
+		exactOpts := query.DefaultExecutorOptions()
+		exactOpts.CountEstimateThreshold = 100
+		var docs []bson.M
+		result, err := NewExecutor(collection, exactOpts).Execute(ctx, &query.Query{}, "", &docs)
+		require.NoError(t, err)
+		assert.False(t, result.TotalItemsEstimated)
+		assert.Equal(t, int64(10), result.TotalItems)
+	})
+}
@@ -612,9 +612,21 @@ func TestMongoExecutor_EdgeCases(t *testing.T) {
 	})
 
 	t.Run("unicode in search", func(t *testing.T) {
-		// Skip: Unicode bare search has parser limitations
-		// Unicode works fine in quoted strings and field comparisons
-		t.Skip("Unicode bare identifiers not supported by parser - use quoted strings instead")
+		collection.InsertOne(ctx, bson.M{"_id": "101", "name": "日本語 キーボード", "brand": "Sony"})
+		collection.InsertOne(ctx, bson.M{"_id": "102", "name": "کتاب", "brand": "علی"})
+
+		for input, id := range map[string]string{`日本語`: "101", `brand = علی`: "102"} {
+			p, err := parser.NewParser(input)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			var docs []bson.M
+			_, err = executor.Execute(ctx, q, "", &docs)
+			require.NoError(t, err)
+			require.Len(t, docs, 1, input)
+			assert.Equal(t, id, docs[0]["_id"])
+		}
 	})
 
 	t.Run("boolean fields", func(t *testing.T) {
@@ -779,3 +791,57 @@ func TestMongoExecutor_TypedResults(t *testing.T) {
 		}
 	})
 }
+
+func TestMongoExecutor_Explain(t *testing.T) {
+	mongoC, collection := setupMongoContainer(t)
+	defer mongoC.Terminate(context.Background())
+	seedMongoTestData(t, collection)
+
+	ctx := context.Background()
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "brand", Value: 1}}})
+	require.NoError(t, err)
+
+	executor := NewExecutor(collection, query.DefaultExecutorOptions())
+	q := &query.Query{Filter: query.Eq("brand", "Anker"), ExplainRequested: true}
+
+	var products []Product
+	result, err := executor.Execute(ctx, q, "", &products)
+	require.NoError(t, err)
+	require.NotNil(t, result.Explain)
+	assert.Equal(t, "MongoDB", result.Explain.Backend)
+	assert.Contains(t, result.Explain.Statement, `"brand":"Anker"`)
+	assert.Empty(t, result.Explain.PlanError)
+	assert.Contains(t, result.Explain.Plan, "IXSCAN")
+	assert.Equal(t, []string{"brand_1"}, result.Explain.Indexes)
+	assert.Positive(t, int64(result.ExecutionTime))
+}
+
+func TestMongoExecutor_DistinctOn(t *testing.T) {
+	mongoC, collection := setupMongoContainer(t)
+	defer mongoC.Terminate(context.Background())
+	seedMongoTestData(t, collection)
+
+	ctx := context.Background()
+	executor := NewExecutor(collection, query.DefaultExecutorOptions())
+	q := &query.Query{DistinctOn: "brand", SortBy: "price", SortOrder: query.SortOrderDesc, PageSize: 4}
+
+	// One product per brand, the most expensive one
+	var page []Product
+	result, err := executor.Execute(ctx, q, "", &page)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), result.TotalItems)
+	require.Len(t, page, 4)
+	assert.Equal(t, "Sony", page[0].Brand)
+	assert.Equal(t, 69.99, page[2].Price)
+
+	var next []Product
+	_, err = executor.Execute(ctx, q, result.NextPageCursor, &next)
+	require.NoError(t, err)
+	require.Len(t, next, 3)
+	assert.Equal(t, "Anker", next[0].Brand)
+	assert.Equal(t, 39.99, next[0].Price)
+
+	count, err := executor.Count(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+}
@@ -1,6 +1,7 @@
 package mongodb
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -11,11 +12,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestExecutor_BuildFilter(t *testing.T) {
 	executor := &Executor{
-		options: query.DefaultExecutorOptions(),
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
 	}
 
 	tests := []struct {
@@ -37,6 +39,13 @@ func TestExecutor_BuildFilter(t *testing.T) {
 				"name": "John",
 			},
 		},
+		{
+			name:  "like escapes regex characters",
+			input: `file LIKE "%.tar*gz"`,
+			expected: bson.M{
+				"file": bson.M{"$regex": `^.*\.tar\*gz$`, "$options": ""},
+			},
+		},
 		{
 			name:  "greater than",
 			input: "age > 18",
@@ -93,6 +102,17 @@ func TestExecutor_BuildFilter(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "chain of one operator",
+			input: "a = 1 and (b = 2 and c = 3)",
+			expected: bson.M{
+				"$and": bson.A{
+					bson.M{"a": int64(1)},
+					bson.M{"b": int64(2)},
+					bson.M{"c": int64(3)},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -114,7 +134,7 @@ func TestExecutor_BuildFilter(t *testing.T) {
 
 func TestExecutor_ConvertValue(t *testing.T) {
 	executor := &Executor{
-		options: query.DefaultExecutorOptions(),
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
 	}
 
 	tests := []struct {
@@ -165,7 +185,7 @@ func TestExecutor_ConvertValue(t *testing.T) {
 
 func TestExecutor_ConvertValue_ObjectID(t *testing.T) {
 	executor := &Executor{
-		options: query.DefaultExecutorOptions(),
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
 	}
 
 	// Valid ObjectID
@@ -233,7 +253,7 @@ func TestExecutorOptions_ValidatePageSize(t *testing.T) {
 
 func TestExecutor_BuildCursorFilter(t *testing.T) {
 	executor := &Executor{
-		options: query.DefaultExecutorOptions(),
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
 	}
 
 	tests := []struct {
@@ -299,14 +319,48 @@ func TestExecutor_BuildCursorFilter(t *testing.T) {
 
 func TestExecutor_Name(t *testing.T) {
 	executor := &Executor{
-		options: query.DefaultExecutorOptions(),
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
 	}
 	assert.Equal(t, "MongoDB", executor.Name())
 }
 
+func TestExecutor_ExecuteBatchValidates(t *testing.T) {
+	executor := &Executor{
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
+	}
+	_, err := executor.ExecuteBatch(context.Background(), []*query.Query{{}}, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidDestination)
+}
+
+func TestExecutor_BuildUpdate(t *testing.T) {
+	ctx := context.Background()
+	opts := query.DefaultExecutorOptions()
+	opts.AllowWrites = true
+	opts.AllowedFields = []string{"status", "stock", "meta.reviewed"}
+	executor := &Executor{options: &Options{ExecutorOptions: opts}}
+	q := &query.Query{Filter: &query.ComparisonNode{Field: "status", Operator: query.OpEqual, Value: query.StringValue("draft")}}
+
+	update, err := executor.buildUpdate(ctx, q, map[string]interface{}{"status": "archived", "meta.reviewed": true})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$set": bson.M{"status": "archived", "meta.reviewed": true}}, update)
+
+	update, err = executor.buildUpdate(ctx, q, nil)
+	require.NoError(t, err)
+	assert.Nil(t, update)
+
+	_, err = executor.buildUpdate(ctx, q, map[string]interface{}{"owner": "mallory"})
+	assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+	_, err = executor.buildUpdate(ctx, &query.Query{}, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	opts.AllowWrites = false
+	_, err = executor.buildUpdate(ctx, q, nil)
+	assert.ErrorIs(t, err, query.ErrWritesNotAllowed)
+}
+
 func TestExecutor_Close(t *testing.T) {
 	executor := &Executor{
-		options: query.DefaultExecutorOptions(),
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
 	}
 	err := executor.Close()
 	assert.NoError(t, err)
@@ -391,3 +445,125 @@ func TestExecutor_Integration(t *testing.T) {
 	require.NoError(t, err)
 }
 */
+
+func TestExecutor_BuildFilterMatch(t *testing.T) {
+	executor := &Executor{
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
+	}
+
+	p, err := parser.NewParser(`description MATCH "noise cancelling"`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	filter, err := executor.buildFilter(q.Filter)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$text": bson.M{"$search": "noise cancelling"}}, filter)
+}
+
+func TestExecutor_HasTextSearch(t *testing.T) {
+	executor := &Executor{
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
+	}
+
+	p, err := parser.NewParser(`category = electronics AND description MATCH "wireless"`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	filter, err := executor.buildFilter(q.Filter)
+	require.NoError(t, err)
+	assert.True(t, hasTextSearch(filter))
+
+	p, err = parser.NewParser(`category = electronics`)
+	require.NoError(t, err)
+	q, err = p.Parse()
+	require.NoError(t, err)
+	filter, err = executor.buildFilter(q.Filter)
+	require.NoError(t, err)
+	assert.False(t, hasTextSearch(filter))
+}
+
+func TestExecutor_InOrderPipeline(t *testing.T) {
+	filter := bson.M{"id": bson.M{"$in": bson.A{int64(5), int64(1)}}}
+	values := []interface{}{int64(5), int64(1)}
+
+	assert.Equal(t, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{inOrderField: bson.M{"$indexOfArray": bson.A{values, "$id"}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: inOrderField, Value: 1}}}},
+		{{Key: "$skip", Value: int64(20)}},
+		{{Key: "$limit", Value: int64(11)}},
+		{{Key: "$project", Value: bson.M{inOrderField: 0}}},
+	}, inOrderPipeline(filter, "id", values, 20, 11))
+
+	// First page has no $skip stage
+	assert.Len(t, inOrderPipeline(filter, "id", values, 0, 11), 5)
+}
+
+func TestExecutor_BuildFilterMultiFieldSearch(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSearchFields = []string{"name", "description"}
+	executor := &Executor{options: &Options{ExecutorOptions: opts}}
+
+	p, err := parser.NewParser("wireless")
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	filter, err := executor.buildFilter(q.Filter)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"name": bson.M{"$regex": "wireless", "$options": ""}},
+		bson.M{"description": bson.M{"$regex": "wireless", "$options": ""}},
+	}}, filter)
+}
+
+func TestExecutor_BuildFilterFloatTolerance(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.FloatTolerance = 0.5
+	executor := &Executor{options: &Options{ExecutorOptions: opts}}
+
+	filter, err := executor.buildFilter(&query.ComparisonNode{Field: "price", Operator: query.OpEqual, Value: query.FloatValue(10)})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"price": bson.M{"$gte": 9.5, "$lte": 10.5}}, filter)
+
+	filter, err = executor.buildFilter(&query.ComparisonNode{Field: "price", Operator: query.OpNotEqual, Value: query.FloatValue(10)})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"price": bson.M{"$not": bson.M{"$gte": 9.5, "$lte": 10.5}}}, filter)
+
+	filter, err = executor.buildFilter(&query.ComparisonNode{Field: "stock", Operator: query.OpEqual, Value: query.IntValue(10)})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"stock": int64(10)}, filter)
+}
+
+func TestExecutor_FieldPolicy(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.FieldPolicy = map[string][]query.ComparisonOperator{"email": {query.OpEqual}}
+	// The policy is enforced before the collection is touched
+	executor := &Executor{options: &Options{ExecutorOptions: opts}}
+
+	q := &query.Query{Filter: &query.ComparisonNode{Field: "email", Operator: query.OpRegex, Value: query.StringValue(".*")}}
+	result, err := executor.Execute(context.Background(), q, "", &[]bson.M{})
+	assert.ErrorIs(t, err, query.ErrOperatorNotAllowed)
+	assert.ErrorIs(t, result.Error, query.ErrOperatorNotAllowed)
+
+	_, err = executor.Count(context.Background(), q)
+	assert.ErrorIs(t, err, query.ErrOperatorNotAllowed)
+}
+
+func TestExecutor_BuildFilterAnchorRegex(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.AnchorRegex = true
+	executor := &Executor{options: &Options{ExecutorOptions: opts}}
+
+	filter, err := executor.buildFilter(query.F("sku").Regex(`AB-\d+`).Node())
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"sku": bson.M{"$regex": `^(?:AB-\d+)$`, "$options": ""}}, filter)
+}
+
+func TestExtrapolate(t *testing.T) {
+	assert.Equal(t, int64(0), extrapolate(0, 1000, 1_000_000))
+	assert.Equal(t, int64(250_000), extrapolate(250, 1000, 1_000_000))
+	assert.Equal(t, int64(1), extrapolate(1, 10_000, 100)) // any match estimates at least one
+	assert.Equal(t, int64(0), extrapolate(5, 0, 100))
+}
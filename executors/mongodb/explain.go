@@ -0,0 +1,110 @@
+package mongodb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// explainCommand returns the find or aggregate command Execute runs
+func (e *Executor) explainCommand(filter bson.M, findOpts *options.FindOptions, pipeline mongo.Pipeline) bson.D {
+	if pipeline != nil {
+		cmd := bson.D{
+			{Key: "aggregate", Value: e.collection.Name()},
+			{Key: "pipeline", Value: pipeline},
+			{Key: "cursor", Value: bson.M{}},
+		}
+		if findOpts.Collation != nil {
+			cmd = append(cmd, bson.E{Key: "collation", Value: findOpts.Collation.ToDocument()})
+		}
+		return cmd
+	}
+	cmd := bson.D{{Key: "find", Value: e.collection.Name()}, {Key: "filter", Value: filter}}
+	if findOpts.Sort != nil {
+		cmd = append(cmd, bson.E{Key: "sort", Value: findOpts.Sort})
+	}
+	if findOpts.Projection != nil {
+		cmd = append(cmd, bson.E{Key: "projection", Value: findOpts.Projection})
+	}
+	if findOpts.Hint != nil {
+		cmd = append(cmd, bson.E{Key: "hint", Value: findOpts.Hint})
+	}
+	if findOpts.Skip != nil {
+		cmd = append(cmd, bson.E{Key: "skip", Value: *findOpts.Skip})
+	}
+	if findOpts.Limit != nil {
+		cmd = append(cmd, bson.E{Key: "limit", Value: *findOpts.Limit})
+	}
+	if findOpts.Collation != nil {
+		cmd = append(cmd, bson.E{Key: "collation", Value: findOpts.Collation.ToDocument()})
+	}
+	return cmd
+}
+
+// explain returns cmd as extended JSON and the query planner output for it.
+// hint is the index chosen by the range planner, if any; the indexes of the
+// winning plan are added to it
+func (e *Executor) explain(ctx context.Context, cmd bson.D, hint string) *query.Explain {
+	explain := &query.Explain{Backend: e.Name()}
+	if hint != "" {
+		explain.Indexes = []string{hint}
+	}
+	if statement, err := bson.MarshalExtJSON(cmd, false, false); err == nil {
+		explain.Statement = string(statement)
+	}
+
+	var response bson.M
+	err := e.collection.Database().RunCommand(ctx, bson.D{
+		{Key: "explain", Value: cmd},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}).Decode(&response)
+	if err != nil {
+		explain.PlanError = err.Error()
+		return explain
+	}
+	var plan interface{} = response
+	if planner, ok := response["queryPlanner"].(bson.M); ok && planner["winningPlan"] != nil {
+		plan = planner["winningPlan"]
+	}
+	if data, err := bson.MarshalExtJSON(bson.M{"plan": plan}, false, false); err == nil {
+		explain.Plan = string(data)
+	}
+	explain.Indexes = appendIndexNames(explain.Indexes, plan)
+	return explain
+}
+
+// appendIndexNames appends the indexName values found in a decoded plan
+func appendIndexNames(names []string, plan interface{}) []string {
+	switch v := plan.(type) {
+	case bson.M:
+		if name, ok := v["indexName"].(string); ok && !containsString(names, name) {
+			names = append(names, name)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			names = appendIndexNames(names, v[key])
+		}
+	case bson.A:
+		for _, child := range v {
+			names = appendIndexNames(names, child)
+		}
+	}
+	return names
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
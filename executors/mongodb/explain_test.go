@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+func TestExecutor_ExplainCommand(t *testing.T) {
+	// Connect does not dial until a command runs
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+	executor := &Executor{
+		collection: client.Database("testdb").Collection("products"),
+		options:    &Options{ExecutorOptions: query.DefaultExecutorOptions()},
+	}
+
+	filter := bson.M{"brand": "Sony"}
+	findOpts := options.Find().SetLimit(11).SetSort(bson.D{{Key: "price", Value: 1}}).SetHint("price_1")
+	assert.Equal(t, bson.D{
+		{Key: "find", Value: "products"},
+		{Key: "filter", Value: filter},
+		{Key: "sort", Value: bson.D{{Key: "price", Value: 1}}},
+		{Key: "hint", Value: "price_1"},
+		{Key: "limit", Value: int64(11)},
+	}, executor.explainCommand(filter, findOpts, nil))
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	assert.Equal(t, bson.D{
+		{Key: "aggregate", Value: "products"},
+		{Key: "pipeline", Value: pipeline},
+		{Key: "cursor", Value: bson.M{}},
+	}, executor.explainCommand(filter, findOpts, pipeline))
+
+	executor.options.Collation = &query.Collation{Locale: "de", Strength: 2}
+	findOpts.SetCollation(executor.collation())
+	cmd := executor.explainCommand(filter, findOpts, nil)
+	assert.Equal(t, bson.E{Key: "collation", Value: bson.Raw(bsoncore.NewDocumentBuilder().
+		AppendString("locale", "de").
+		AppendInt32("strength", 2).
+		Build())}, cmd[len(cmd)-1])
+}
+
+func TestAppendIndexNames(t *testing.T) {
+	plan := bson.M{
+		"stage": "FETCH",
+		"inputStage": bson.M{
+			"stage": "OR",
+			"inputStages": bson.A{
+				bson.M{"stage": "IXSCAN", "indexName": "brand_1"},
+				bson.M{"stage": "IXSCAN", "indexName": "price_1"},
+				bson.M{"stage": "IXSCAN", "indexName": "brand_1"},
+			},
+		},
+	}
+	assert.Equal(t, []string{"price_1", "brand_1"}, appendIndexNames([]string{"price_1"}, plan))
+	assert.Empty(t, appendIndexNames(nil, bson.M{"stage": "COLLSCAN"}))
+}
@@ -0,0 +1,226 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Facets counts the buckets of every spec in one aggregation: a $match on the
+// query's filter followed by a $facet stage with one sub-pipeline per spec.
+// Date histograms use $dateTrunc, which needs MongoDB 5.0 or later
+func (e *Executor) Facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	e = e.withCurrentOptions().withQueryOptions(ctx)
+	results, err := e.facets(ctx, q, specs)
+	return results, query.WrapError(e.Name(), "facets", err)
+}
+
+func (e *Executor) facets(ctx context.Context, q *query.Query, specs []query.FacetSpec) ([]query.FacetResult, error) {
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return nil, err
+	}
+	specs, err = e.options.ValidateFacets(ctx, specs)
+	if err != nil {
+		return nil, err
+	}
+	q = e.options.ScopedQuery(q)
+	if len(specs) == 0 {
+		return []query.FacetResult{}, nil
+	}
+
+	filter := bson.M{}
+	var hint string
+	if q.Filter != nil {
+		filter, hint, err = e.buildPlannedFilter(q.Filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stage, err := e.facetStage(specs)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := e.collection.Aggregate(ctx, mongo.Pipeline{{{Key: "$match", Value: filter}}, stage}, e.aggregateOptions(e.indexHint(hint)))
+	if err != nil {
+		return nil, query.NewExecutionError("count facets", err)
+	}
+	defer cur.Close(ctx)
+
+	var docs []map[string][]bson.M
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, query.NewExecutionError("count facets", err)
+	}
+	var doc map[string][]bson.M
+	if len(docs) > 0 {
+		doc = docs[0]
+	}
+
+	results := make([]query.FacetResult, len(specs))
+	for i, spec := range specs {
+		rows := doc[facetKey(i)]
+		results[i] = query.FacetResult{Field: spec.Field}
+		if len(spec.Ranges) > 0 {
+			results[i].Buckets = make([]query.FacetBucket, len(spec.Ranges))
+			for j, r := range spec.Ranges {
+				var count int64
+				if len(rows) > 0 {
+					count = facetCount(rows[0][rangeKey(j)])
+				}
+				results[i].Buckets[j] = query.FacetBucket{Value: r.Label, Count: count}
+			}
+			continue
+		}
+		buckets := make([]query.FacetBucket, 0, len(rows))
+		for _, row := range rows {
+			buckets = append(buckets, query.FacetBucket{Value: row["_id"], Count: facetCount(row["count"])})
+		}
+		if spec.IsHistogram() {
+			results[i].Buckets = histogramBuckets(buckets)
+			continue
+		}
+		results[i].Buckets = query.SortFacetBuckets(buckets, spec.Limit)
+	}
+	return results, nil
+}
+
+// facetStage builds the $facet stage for specs. Sub-pipelines are keyed by
+// position, since field paths are not valid $facet names
+func (e *Executor) facetStage(specs []query.FacetSpec) (bson.D, error) {
+	facets := bson.D{}
+	for i, spec := range specs {
+		if err := validFieldPath(spec.Field); err != nil {
+			return nil, err
+		}
+		var pipeline bson.A
+		if len(spec.Ranges) > 0 {
+			group := bson.D{{Key: "_id", Value: nil}}
+			for j, r := range spec.Ranges {
+				cond, err := e.rangeCondition(spec.Field, r)
+				if err != nil {
+					return nil, err
+				}
+				group = append(group, bson.E{Key: rangeKey(j), Value: bson.M{
+					"$sum": bson.M{"$cond": bson.A{cond, 1, 0}},
+				}})
+			}
+			pipeline = bson.A{bson.D{{Key: "$group", Value: group}}}
+		} else if spec.IsHistogram() {
+			pipeline = histogramPipeline(spec)
+		} else {
+			pipeline = bson.A{
+				bson.D{{Key: "$group", Value: bson.D{
+					{Key: "_id", Value: "$" + spec.Field},
+					{Key: "count", Value: bson.M{"$sum": 1}},
+				}}},
+				bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			}
+			if spec.Limit > 0 {
+				pipeline = append(pipeline, bson.D{{Key: "$limit", Value: int64(spec.Limit)}})
+			}
+		}
+		facets = append(facets, bson.E{Key: facetKey(i), Value: pipeline})
+	}
+	return bson.D{{Key: "$facet", Value: facets}}, nil
+}
+
+// histogramPipeline groups the numbers (for Interval) or dates (for
+// DateInterval) of spec.Field by the start of their bin
+func histogramPipeline(spec query.FacetSpec) bson.A {
+	path := "$" + spec.Field
+	kind := "number"
+	bin := interface{}(bson.M{"$multiply": bson.A{
+		bson.M{"$floor": bson.M{"$divide": bson.A{path, spec.Interval}}}, spec.Interval,
+	}})
+	if spec.DateInterval != "" {
+		kind = "date"
+		bin = bson.M{"$dateTrunc": bson.M{"date": path, "unit": string(spec.DateInterval), "startOfWeek": "monday"}}
+	}
+	return bson.A{
+		bson.D{{Key: "$match", Value: bson.M{spec.Field: bson.M{"$type": kind}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bin},
+			{Key: "count", Value: bson.M{"$sum": 1}},
+		}}},
+	}
+}
+
+// histogramBuckets converts the decoded bin starts to float64 and UTC times
+// and orders them
+func histogramBuckets(buckets []query.FacetBucket) []query.FacetBucket {
+	for i, b := range buckets {
+		switch v := b.Value.(type) {
+		case primitive.DateTime:
+			buckets[i].Value = v.Time().UTC()
+		case int32:
+			buckets[i].Value = float64(v)
+		case int64:
+			buckets[i].Value = float64(v)
+		}
+	}
+	return query.SortHistogramBuckets(buckets)
+}
+
+// rangeCondition builds the expression for From <= field < To. Aggregation
+// comparisons order values across types, so the field's type is checked
+// against the bounds first
+func (e *Executor) rangeCondition(field string, r query.FacetRange) (bson.M, error) {
+	path := "$" + field
+	var conditions bson.A
+	if _, ok := firstBound(r).(query.DateTimeValue); ok {
+		conditions = bson.A{bson.M{"$eq": bson.A{bson.M{"$type": path}, "date"}}}
+	} else {
+		conditions = bson.A{bson.M{"$isNumber": path}}
+	}
+	for _, bound := range []struct {
+		op    string
+		value interface{}
+	}{{"$gte", r.From}, {"$lt", r.To}} {
+		if bound.value == nil {
+			continue
+		}
+		value, err := e.convertValue(field, bound.value)
+		if err != nil {
+			return nil, query.NewFieldError(field, err)
+		}
+		conditions = append(conditions, bson.M{bound.op: bson.A{path, value}})
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
+// firstBound returns the bound of r that is set
+func firstBound(r query.FacetRange) interface{} {
+	if r.From != nil {
+		return r.From
+	}
+	return r.To
+}
+
+func facetKey(i int) string {
+	return fmt.Sprintf("f%d", i)
+}
+
+func rangeKey(i int) string {
+	return fmt.Sprintf("r%d", i)
+}
+
+// facetCount reads a $sum result, which is an int32 or int64 depending on its size
+func facetCount(v interface{}) int64 {
+	switch n := v.(type) {
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestExecutor_FacetStage(t *testing.T) {
+	executor := &Executor{
+		options: &Options{ExecutorOptions: query.DefaultExecutorOptions()},
+	}
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	specs, err := executor.options.ValidateFacets(context.Background(), []query.FacetSpec{
+		{Field: "brand", Limit: 5},
+		{Field: "price", Ranges: []query.FacetRange{{To: 20}, {From: 20}}},
+		{Field: "created_at", Ranges: []query.FacetRange{{From: since}}},
+	})
+	require.NoError(t, err)
+
+	stage, err := executor.facetStage(specs)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "$facet", Value: bson.D{
+		{Key: "f0", Value: bson.A{
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: "$brand"},
+				{Key: "count", Value: bson.M{"$sum": 1}},
+			}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+			bson.D{{Key: "$limit", Value: int64(5)}},
+		}},
+		{Key: "f1", Value: bson.A{
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: nil},
+				{Key: "r0", Value: bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$and": bson.A{bson.M{"$isNumber": "$price"}, bson.M{"$lt": bson.A{"$price", int64(20)}}}}, 1, 0,
+				}}}},
+				{Key: "r1", Value: bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$and": bson.A{bson.M{"$isNumber": "$price"}, bson.M{"$gte": bson.A{"$price", int64(20)}}}}, 1, 0,
+				}}}},
+			}}},
+		}},
+		{Key: "f2", Value: bson.A{
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: nil},
+				{Key: "r0", Value: bson.M{"$sum": bson.M{"$cond": bson.A{
+					bson.M{"$and": bson.A{
+						bson.M{"$eq": bson.A{bson.M{"$type": "$created_at"}, "date"}},
+						bson.M{"$gte": bson.A{"$created_at", since}},
+					}}, 1, 0,
+				}}}},
+			}}},
+		}},
+	}}}, stage)
+
+	stage, err = executor.facetStage([]query.FacetSpec{
+		{Field: "created_at", DateInterval: query.DateIntervalWeek},
+		{Field: "price", Interval: 20},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "$facet", Value: bson.D{
+		{Key: "f0", Value: bson.A{
+			bson.D{{Key: "$match", Value: bson.M{"created_at": bson.M{"$type": "date"}}}},
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: bson.M{"$dateTrunc": bson.M{"date": "$created_at", "unit": "week", "startOfWeek": "monday"}}},
+				{Key: "count", Value: bson.M{"$sum": 1}},
+			}}},
+		}},
+		{Key: "f1", Value: bson.A{
+			bson.D{{Key: "$match", Value: bson.M{"price": bson.M{"$type": "number"}}}},
+			bson.D{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: bson.M{"$multiply": bson.A{
+					bson.M{"$floor": bson.M{"$divide": bson.A{"$price", 20.0}}}, 20.0,
+				}}},
+				{Key: "count", Value: bson.M{"$sum": 1}},
+			}}},
+		}},
+	}}}, stage)
+
+	assert.Equal(t, []query.FacetBucket{
+		{Value: since, Count: 1},
+		{Value: since.AddDate(0, 0, 7), Count: 2},
+	}, histogramBuckets([]query.FacetBucket{
+		{Value: primitive.NewDateTimeFromTime(since.AddDate(0, 0, 7)), Count: 2},
+		{Value: primitive.NewDateTimeFromTime(since), Count: 1},
+	}))
+
+	_, err = executor.facetStage([]query.FacetSpec{{Field: "$where"}})
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
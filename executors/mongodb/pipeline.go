@@ -0,0 +1,317 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PipelineOptions configures BuildPipeline
+type PipelineOptions struct {
+	*query.ExecutorOptions
+
+	// Skip is the number of documents before the page (offset pagination)
+	Skip int64
+
+	// Count runs the paging stages inside a $facet that also counts all
+	// matching documents. The single output document has the shape
+	// {items: [...], total: [{count: n}]}; total is empty when nothing matches
+	Count bool
+}
+
+// Output fields of the $facet stage added by PipelineOptions.Count
+const (
+	FacetItems = "items"
+	FacetTotal = "total"
+)
+
+// BuildFilter translates the query's filter into a MongoDB query document, the
+// same way Execute does: the filter is validated against opts, BaseFilter is
+// added and range lists are merged. A query without a filter returns an empty document that matches all.
+// If opts is nil, query.DefaultExecutorOptions() is used. Relative times and
+// @placeholders fail as unresolved; use BuildFilterContext to resolve them
+func BuildFilter(q *query.Query, opts *query.ExecutorOptions) (bson.M, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	if opts == nil {
+		opts = query.DefaultExecutorOptions()
+	}
+	e := &Executor{options: &Options{ExecutorOptions: opts}}
+	if err := opts.ValidateFilter(q.Filter); err != nil {
+		return nil, err
+	}
+	q = opts.ScopedQuery(q)
+	if q.Filter == nil {
+		return bson.M{}, nil
+	}
+	filter, _, err := e.buildPlannedFilter(q.Filter)
+	return filter, err
+}
+
+// BuildFilterContext is BuildFilter for queries with relative times or
+// @placeholders: they are resolved first with opts.ResolvePlaceholders and
+// the request in ctx, like Execute does
+func BuildFilterContext(ctx context.Context, q *query.Query, opts *query.ExecutorOptions) (bson.M, error) {
+	if opts == nil {
+		opts = query.DefaultExecutorOptions()
+	}
+	resolved, err := resolve(ctx, q, opts)
+	if err != nil {
+		return nil, err
+	}
+	return BuildFilter(resolved, opts)
+}
+
+// BuildPipeline translates a query into an aggregation pipeline of $match,
+// $sort, $skip and $limit stages, so callers can append their own stages
+// ($lookup, $project, ...) before running it with Collection.Aggregate.
+//
+// The page size and sort follow the executor options like Execute. Documents
+// are sorted by the ID as a tie-breaker so offset pages are stable. Random order
+// sorts by a hash of the ID and the query's RandomSeed (and adds the _random
+// field), or uses $sample without a seed; _score sorts by text score (and adds the _score field), _matches
+// sorts by the number of CONTAINS conditions matched (and adds the _matches field)
+// and preserve_in_order sorts by the position in the IN values. distinct_on
+// groups the matched documents by the field first; with Count, the total counts
+// the groups. Relative times and @placeholders fail as unresolved; use
+// BuildPipelineContext to resolve them
+func BuildPipeline(q *query.Query, opts *PipelineOptions) (mongo.Pipeline, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	if opts == nil {
+		opts = &PipelineOptions{}
+	}
+	execOpts := opts.ExecutorOptions
+	if execOpts == nil {
+		execOpts = query.DefaultExecutorOptions()
+	}
+	e := &Executor{options: &Options{ExecutorOptions: execOpts}}
+
+	filter, err := BuildFilter(q, execOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := execOpts.ValidateDistinct(q); err != nil {
+		return nil, err
+	}
+	q = execOpts.ScopedQuery(q)
+
+	pageSize := int64(execOpts.ValidatePageSize(q.PageSize))
+	if q.Limit > 0 {
+		// Never page past the query's limit
+		remaining := int64(q.Limit) - opts.Skip
+		if remaining <= 0 {
+			return mongo.Pipeline{
+				{{Key: "$match", Value: filter}},
+				{{Key: "$match", Value: bson.M{"$expr": false}}},
+			}, nil
+		}
+		if pageSize > remaining {
+			pageSize = remaining
+		}
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	if q.DistinctOn != "" {
+		stages, err := e.distinctStages(q, filter, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, stages...)
+	}
+	ordering, sampled, err := e.orderStages(q, filter, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	var paging mongo.Pipeline
+	if sampled {
+		// $sample already picks the page
+		paging = ordering
+	} else {
+		pipeline = append(pipeline, ordering...)
+		if opts.Skip > 0 {
+			paging = append(paging, bson.D{{Key: "$skip", Value: opts.Skip}})
+		}
+		paging = append(paging, bson.D{{Key: "$limit", Value: pageSize}})
+	}
+
+	if !opts.Count {
+		return append(pipeline, paging...), nil
+	}
+	return append(pipeline, bson.D{{Key: "$facet", Value: bson.M{
+		FacetItems: paging,
+		FacetTotal: mongo.Pipeline{{{Key: "$count", Value: "count"}}},
+	}}}), nil
+}
+
+// orderStages returns the stages that order the matched documents. sampled is
+// true for random order without a seed, whose $sample stage also limits the page
+func (e *Executor) orderStages(q *query.Query, filter bson.M, pageSize int64) (mongo.Pipeline, bool, error) {
+	if err := e.options.ValidateSortField(q.SortBy); err != nil {
+		return nil, false, err
+	}
+	sortField := q.SortBy
+	if sortField == "" {
+		sortField = e.options.DefaultSortField
+	}
+	sortOrder := q.SortOrder
+	// If sort order is not explicitly set (remains default), use executor default
+	if sortOrder == query.SortOrderAsc {
+		sortOrder = e.options.DefaultSortOrder
+	}
+
+	inOrder, err := query.InOrderCondition(q)
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case inOrder != nil:
+		values, err := e.convertArrayValue(inOrder.Field, inOrder.Value)
+		if err != nil {
+			return nil, false, err
+		}
+		return mongo.Pipeline{
+			{{Key: "$addFields", Value: bson.M{inOrderField: bson.M{"$indexOfArray": bson.A{values, "$" + inOrder.Field}}}}},
+			{{Key: "$sort", Value: bson.D{{Key: inOrderField, Value: 1}}}},
+			{{Key: "$project", Value: bson.M{inOrderField: 0}}},
+		}, false, nil
+
+	case sortField == query.ScoreField:
+		// Relevance ordering requires a $text (MATCH) predicate
+		if !hasTextSearch(filter) {
+			return nil, false, fmt.Errorf("%w: sorting by %s requires a MATCH condition", query.ErrInvalidQuery, query.ScoreField)
+		}
+		return mongo.Pipeline{
+			{{Key: "$addFields", Value: bson.M{query.ScoreField: bson.M{"$meta": "textScore"}}}},
+			{{Key: "$sort", Value: bson.D{{Key: query.ScoreField, Value: -1}, {Key: e.getIDFieldName(), Value: 1}}}},
+		}, false, nil
+
+	case sortField == query.MatchCountField:
+		stages, err := e.matchCountStages(q.Filter)
+		return stages, false, err
+
+	case sortOrder == query.SortOrderRandom:
+		if !e.options.AllowRandomOrder {
+			return nil, false, query.ErrRandomOrderNotAllowed
+		}
+		if q.RandomSeed != 0 {
+			return e.randomStages(q.RandomSeed), false, nil
+		}
+		return mongo.Pipeline{{{Key: "$sample", Value: bson.M{"size": pageSize}}}}, true, nil
+	}
+
+	direction := 1
+	if sortOrder == query.SortOrderDesc {
+		direction = -1
+	}
+	sort := bson.D{{Key: sortField, Value: direction}}
+	if !e.isIDField(sortField) {
+		sort = append(sort, bson.E{Key: e.getIDFieldName(), Value: direction})
+	}
+	return mongo.Pipeline{{{Key: "$sort", Value: sort}}}, false, nil
+}
+
+// randomKeyField holds the sort key of seeded random order
+const randomKeyField = "_random"
+
+// randomStages order documents by a hash of their ID and seed, with ties
+// broken by ID, so the same seed gives the same order on every page:
+//
+//	{$addFields: {_random: {$toHashedIndexKey: {$concat: [{$toString: "$_id"}, ":seed"]}}}}, {$sort: {_random: 1, _id: 1}}
+//
+// $toHashedIndexKey needs MongoDB 7.0 or later
+func (e *Executor) randomStages(seed int64) mongo.Pipeline {
+	id := e.getIDFieldName()
+	key := bson.M{"$toHashedIndexKey": bson.M{"$concat": bson.A{
+		bson.M{"$toString": "$" + id}, ":" + strconv.FormatInt(seed, 10),
+	}}}
+	return mongo.Pipeline{
+		{{Key: "$addFields", Value: bson.M{randomKeyField: key}}},
+		{{Key: "$sort", Value: bson.D{{Key: randomKeyField, Value: 1}, {Key: id, Value: 1}}}},
+	}
+}
+
+// matchCountStages returns the stages that sort documents by the number of
+// MatchClauses of the filter they match, most first, with ties ordered by ID.
+// The count is stored in the MatchCountField field
+func (e *Executor) matchCountStages(filter query.Node) (mongo.Pipeline, error) {
+	clauses, err := e.options.MatchClauses(filter)
+	if err != nil {
+		return nil, err
+	}
+	terms := make(bson.A, len(clauses))
+	for i, c := range clauses {
+		expr, err := e.matchExpr(c)
+		if err != nil {
+			return nil, err
+		}
+		terms[i] = bson.M{"$cond": bson.A{expr, 1, 0}}
+	}
+	return mongo.Pipeline{
+		{{Key: "$addFields", Value: bson.M{query.MatchCountField: bson.M{"$add": terms}}}},
+		{{Key: "$sort", Value: bson.D{{Key: query.MatchCountField, Value: -1}, {Key: e.getIDFieldName(), Value: 1}}}},
+	}, nil
+}
+
+// matchExpr translates a match clause into an aggregation expression that is
+// true when the document matches it. CONTAINS and ICONTAINS use $regexMatch
+// with the same pattern as the query filter; non-string values never match
+func (e *Executor) matchExpr(node query.Node) (interface{}, error) {
+	switch n := node.(type) {
+	case *query.BinaryOpNode:
+		left, err := e.matchExpr(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.matchExpr(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		if n.Operator == query.BinaryOpAnd {
+			return bson.M{"$and": bson.A{left, right}}, nil
+		}
+		return bson.M{"$or": bson.A{left, right}}, nil
+
+	case *query.ComparisonNode:
+		value, err := e.convertValue(n.Field, n.Value)
+		if err != nil {
+			return nil, err
+		}
+		options := ""
+		if n.Operator == query.OpIContains {
+			options = "i"
+		}
+		input := bson.M{"$convert": bson.M{"input": "$" + n.Field, "to": "string", "onError": "", "onNull": ""}}
+		return bson.M{"$regexMatch": bson.M{"input": input, "regex": fmt.Sprintf("%v", value), "options": options}}, nil
+	}
+	return nil, query.ErrInvalidQuery
+}
+
+// BuildPipelineContext is BuildPipeline for queries with relative times or
+// @placeholders: they are resolved first with the executor options'
+// ResolvePlaceholders and the request in ctx, like Execute does
+func BuildPipelineContext(ctx context.Context, q *query.Query, opts *PipelineOptions) (mongo.Pipeline, error) {
+	execOpts := query.DefaultExecutorOptions()
+	if opts != nil && opts.ExecutorOptions != nil {
+		execOpts = opts.ExecutorOptions
+	}
+	resolved, err := resolve(ctx, q, execOpts)
+	if err != nil {
+		return nil, err
+	}
+	return BuildPipeline(resolved, opts)
+}
+
+// resolve resolves the placeholders and relative times of q for the builders
+func resolve(ctx context.Context, q *query.Query, opts *query.ExecutorOptions) (*query.Query, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	return opts.ResolvePlaceholders(ctx, q)
+}
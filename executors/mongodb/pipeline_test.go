@@ -0,0 +1,230 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestBuildPipeline(t *testing.T) {
+	q := &query.Query{Filter: query.Eq("brand", "Sony"), SortBy: "price", SortOrder: query.SortOrderDesc, PageSize: 20}
+
+	pipeline, err := BuildPipeline(q, &PipelineOptions{Skip: 40})
+	require.NoError(t, err)
+	assert.Equal(t, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"brand": "Sony"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "price", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$skip", Value: int64(40)}},
+		{{Key: "$limit", Value: int64(20)}},
+	}, pipeline)
+}
+
+func TestBuildPipeline_Count(t *testing.T) {
+	pipeline, err := BuildPipeline(&query.Query{PageSize: 5}, &PipelineOptions{Count: true})
+	require.NoError(t, err)
+	assert.Equal(t, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$facet", Value: bson.M{
+			FacetItems: mongo.Pipeline{{{Key: "$limit", Value: int64(5)}}},
+			FacetTotal: mongo.Pipeline{{{Key: "$count", Value: "count"}}},
+		}}},
+	}, pipeline)
+}
+
+func TestBuildPipeline_DistinctOn(t *testing.T) {
+	q := &query.Query{DistinctOn: "brand", SortBy: "price", SortOrder: query.SortOrderDesc, PageSize: 20}
+
+	sort := bson.D{{Key: "$sort", Value: bson.D{{Key: "price", Value: -1}, {Key: "_id", Value: -1}}}}
+	pipeline, err := BuildPipeline(q, nil)
+	require.NoError(t, err)
+	assert.Equal(t, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{}}},
+		sort,
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$brand"}, {Key: "doc", Value: bson.M{"$first": "$$ROOT"}}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+		sort,
+		{{Key: "$limit", Value: int64(20)}},
+	}, pipeline)
+
+	_, err = BuildPipeline(&query.Query{DistinctOn: "$$ROOT"}, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+
+	_, err = BuildPipeline(&query.Query{DistinctOn: "brand", SortBy: query.ScoreField}, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+}
+
+func TestBuildPipeline_Options(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.BaseFilter = query.Eq("tenant", "acme")
+	opts.MaxPageSize = 10
+
+	pipeline, err := BuildPipeline(&query.Query{PageSize: 50, Limit: 25}, &PipelineOptions{ExecutorOptions: opts, Skip: 20})
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "$match", Value: bson.M{"tenant": "acme"}}}, pipeline[0])
+	// Capped by MaxPageSize, then by the 5 items left under the limit
+	assert.Equal(t, bson.D{{Key: "$limit", Value: int64(5)}}, pipeline[len(pipeline)-1])
+
+	pipeline, err = BuildPipeline(&query.Query{Limit: 10}, &PipelineOptions{Skip: 10})
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "$match", Value: bson.M{"$expr": false}}}, pipeline[len(pipeline)-1])
+}
+
+func TestBuildPipeline_Ordering(t *testing.T) {
+	t.Run("random", func(t *testing.T) {
+		pipeline, err := BuildPipeline(&query.Query{SortOrder: query.SortOrderRandom, PageSize: 3}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{}}},
+			{{Key: "$sample", Value: bson.M{"size": int64(3)}}},
+		}, pipeline)
+	})
+
+	t.Run("seeded random", func(t *testing.T) {
+		q := &query.Query{SortOrder: query.SortOrderRandom, RandomSeed: 42, PageSize: 3}
+		pipeline, err := BuildPipeline(q, &PipelineOptions{Skip: 3})
+		require.NoError(t, err)
+		assert.Equal(t, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{}}},
+			{{Key: "$addFields", Value: bson.M{"_random": bson.M{"$toHashedIndexKey": bson.M{"$concat": bson.A{
+				bson.M{"$toString": "$_id"}, ":42",
+			}}}}}},
+			{{Key: "$sort", Value: bson.D{{Key: "_random", Value: 1}, {Key: "_id", Value: 1}}}},
+			{{Key: "$skip", Value: int64(3)}},
+			{{Key: "$limit", Value: int64(3)}},
+		}, pipeline)
+	})
+
+	t.Run("score requires match", func(t *testing.T) {
+		_, err := BuildPipeline(&query.Query{SortBy: query.ScoreField}, nil)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+
+	t.Run("match count", func(t *testing.T) {
+		q := &query.Query{
+			Filter: query.Or(query.F("name").Contains("usb").Node(), query.F("description").IContains("hub").Node()),
+			SortBy: query.MatchCountField,
+		}
+		pipeline, err := BuildPipeline(q, nil)
+		require.NoError(t, err)
+		require.Len(t, pipeline, 4)
+
+		contains := func(field, regex, options string) bson.M {
+			input := bson.M{"$convert": bson.M{"input": "$" + field, "to": "string", "onError": "", "onNull": ""}}
+			return bson.M{"$cond": bson.A{bson.M{"$regexMatch": bson.M{"input": input, "regex": regex, "options": options}}, 1, 0}}
+		}
+		assert.Equal(t, bson.D{{Key: "$addFields", Value: bson.M{query.MatchCountField: bson.M{"$add": bson.A{
+			contains("name", "usb", ""), contains("description", "hub", "i"),
+		}}}}}, pipeline[1])
+		assert.Equal(t, bson.D{{Key: "$sort", Value: bson.D{{Key: query.MatchCountField, Value: -1}, {Key: "_id", Value: 1}}}}, pipeline[2])
+
+		_, err = BuildPipeline(&query.Query{Filter: query.Gt("price", 10), SortBy: query.MatchCountField}, nil)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+
+	t.Run("preserve in order", func(t *testing.T) {
+		q := &query.Query{Filter: query.In("sku", "b", "a"), PreserveInOrder: true}
+		pipeline, err := BuildPipeline(q, nil)
+		require.NoError(t, err)
+		assert.Equal(t, bson.D{{Key: "$addFields", Value: bson.M{inOrderField: bson.M{"$indexOfArray": bson.A{[]interface{}{"b", "a"}, "$sku"}}}}}, pipeline[1])
+	})
+}
+
+func TestBuildFilter(t *testing.T) {
+	filter, err := BuildFilter(&query.Query{Filter: query.Gt("price", 10)}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"price": bson.M{"$gt": int64(10)}}, filter)
+
+	opts := query.DefaultExecutorOptions()
+	opts.DisableRegex = true
+	_, err = BuildFilter(&query.Query{Filter: query.Compare("name", query.OpRegex, "^a")}, opts)
+	assert.ErrorIs(t, err, query.ErrRegexNotSupported)
+}
+
+func TestBuildFilter_QuotedFields(t *testing.T) {
+	filter, err := parser.ParseFilter("`order date` >= 5")
+	require.NoError(t, err)
+	got, err := BuildFilter(&query.Query{Filter: filter}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"order date": bson.M{"$gte": int64(5)}}, got)
+
+	// Quoted names cannot smuggle in operators
+	filter, err = parser.ParseFilter(`["$where"] = "sleep(1000)"`)
+	require.NoError(t, err)
+	_, err = BuildFilter(&query.Query{Filter: filter}, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
+
+func TestBuildFilter_ArrayModifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bson.M
+	}{
+		{"tags LENGTH = 2", bson.M{"tags": bson.M{"$size": int64(2)}}},
+		{"tags LENGTH > 2", bson.M{
+			"tags": bson.M{"$type": "array"},
+			"$expr": bson.M{"$gt": bson.A{
+				bson.M{"$size": bson.M{"$cond": bson.A{bson.M{"$isArray": "$tags"}, "$tags", bson.A{}}}},
+				int64(2),
+			}},
+		}},
+		{"tags ANY = usb", bson.M{"tags": bson.M{"$elemMatch": bson.M{"$eq": "usb"}}}},
+		{"scores ANY >= 5", bson.M{"scores": bson.M{"$elemMatch": bson.M{"$gte": int64(5)}}}},
+		{"tags ANY IN [usb, hub]", bson.M{"tags": bson.M{"$elemMatch": bson.M{"$in": []interface{}{"usb", "hub"}}}}},
+		{"tags ALL IN [usb, hub]", bson.M{"tags": bson.M{"$all": []interface{}{"usb", "hub"}}}},
+		{"tags ALL IN []", bson.M{"tags": bson.M{"$type": "array"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := parser.ParseFilter(tt.input)
+			require.NoError(t, err)
+			got, err := BuildFilter(&query.Query{Filter: filter}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestBuildPipeline_Resolve(t *testing.T) {
+	_, err := BuildFilter(nil, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	_, err = BuildPipeline(nil, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	_, err = BuildFilterContext(context.Background(), nil, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	opts := query.DefaultExecutorOptions()
+	opts.Clock = func() time.Time { return now }
+	opts.Placeholders = map[string]query.PlaceholderResolver{
+		"user": func(ctx context.Context) (interface{}, error) { return ctx.Value(userKey{}), nil },
+	}
+	q := &query.Query{Filter: query.And(
+		&query.ComparisonNode{Field: "owner", Operator: query.OpEqual, Value: query.PlaceholderValue("user")},
+		&query.ComparisonNode{Field: "created_at", Operator: query.OpGreaterThan, Value: query.RelativeTimeValue("now-1d")},
+	)}
+
+	_, err = BuildFilter(q, opts)
+	assert.Error(t, err)
+
+	ctx := context.WithValue(context.Background(), userKey{}, "ann")
+	filter, err := BuildFilterContext(ctx, q, opts)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$and": bson.A{
+		bson.M{"owner": "ann"},
+		bson.M{"created_at": bson.M{"$gt": now.AddDate(0, 0, -1)}},
+	}}, filter)
+
+	pipeline, err := BuildPipelineContext(ctx, q, &PipelineOptions{ExecutorOptions: opts})
+	require.NoError(t, err)
+	assert.Equal(t, bson.E{Key: "$match", Value: filter}, pipeline[0][0])
+}
+
+type userKey struct{}
@@ -0,0 +1,133 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// QueryOptions are driver settings of the reads Execute, Count and Facets
+// run. Options sets them for an executor and WithQueryOptions overrides them
+// for the calls made with a context
+type QueryOptions struct {
+	// ReadPreference selects the members reads are routed to, e.g.
+	// readpref.SecondaryPreferred() keeps heavy user queries off the primary.
+	// Nil uses the collection's read preference
+	ReadPreference *readpref.ReadPref
+
+	// Hint is the name of the index finds, counts and aggregations use. It
+	// takes precedence over RangeIndexHints
+	Hint string
+
+	// MaxTime bounds the server time of each command (maxTimeMS). Commands
+	// exceeding it fail with an ExecutionError. 0 does not bound them
+	MaxTime time.Duration
+
+	// AllowDiskUse lets sorts and aggregations that exceed the server's
+	// memory limit write temporary files instead of failing
+	AllowDiskUse bool
+}
+
+type queryOptionsKey struct{}
+
+// WithQueryOptions returns a context whose Execute, Count and Facets calls
+// use opts over the executor's QueryOptions. Zero fields keep the executor's
+// setting
+//
+//	ctx = mongodb.WithQueryOptions(ctx, mongodb.QueryOptions{
+//	    ReadPreference: readpref.Secondary(),
+//	    MaxTime:        2 * time.Second,
+//	})
+func WithQueryOptions(ctx context.Context, opts QueryOptions) context.Context {
+	return context.WithValue(ctx, queryOptionsKey{}, opts)
+}
+
+// withQueryOptions returns an executor bound to the QueryOptions of ctx, with
+// its collection read with the resolved read preference
+func (e *Executor) withQueryOptions(ctx context.Context) *Executor {
+	override, ok := ctx.Value(queryOptionsKey{}).(QueryOptions)
+	if !ok && e.options.ReadPreference == nil {
+		return e
+	}
+
+	bound := *e
+	resolved := *e.options
+	if override.ReadPreference != nil {
+		resolved.ReadPreference = override.ReadPreference
+	}
+	if override.Hint != "" {
+		resolved.Hint = override.Hint
+	}
+	if override.MaxTime > 0 {
+		resolved.MaxTime = override.MaxTime
+	}
+	if override.AllowDiskUse {
+		resolved.AllowDiskUse = true
+	}
+	bound.options = &resolved
+
+	if resolved.ReadPreference != nil && bound.collection != nil {
+		// Clone only fails for options it cannot apply; a read preference
+		// always applies
+		if coll, err := bound.collection.Clone(options.Collection().SetReadPreference(resolved.ReadPreference)); err == nil {
+			bound.collection = coll
+		}
+	}
+	return &bound
+}
+
+// findOptions returns the options of the find Execute runs with an index hint
+// resolved by indexHint
+func (e *Executor) findOptions(hint string) *options.FindOptions {
+	findOpts := options.Find().SetCollation(e.collation())
+	if hint != "" {
+		findOpts.SetHint(hint)
+	}
+	if e.options.MaxTime > 0 {
+		findOpts.SetMaxTime(e.options.MaxTime)
+	}
+	if e.options.AllowDiskUse {
+		findOpts.SetAllowDiskUse(true)
+	}
+	return findOpts
+}
+
+// countOptions returns the options of countDocuments with an index hint
+// resolved by indexHint
+func (e *Executor) countOptions(hint string) *options.CountOptions {
+	countOpts := options.Count().SetCollation(e.collation())
+	if hint != "" {
+		countOpts.SetHint(hint)
+	}
+	if e.options.MaxTime > 0 {
+		countOpts.SetMaxTime(e.options.MaxTime)
+	}
+	return countOpts
+}
+
+// aggregateOptions returns the options of the aggregations the executor runs
+// with an index hint resolved by indexHint
+func (e *Executor) aggregateOptions(hint string) *options.AggregateOptions {
+	aggOpts := options.Aggregate().SetCollation(e.collation())
+	if hint != "" {
+		aggOpts.SetHint(hint)
+	}
+	if e.options.MaxTime > 0 {
+		aggOpts.SetMaxTime(e.options.MaxTime)
+	}
+	if e.options.AllowDiskUse {
+		aggOpts.SetAllowDiskUse(true)
+	}
+	return aggOpts
+}
+
+// indexHint returns the index named by QueryOptions.Hint, or hint, the index
+// chosen by the range planner, without one
+func (e *Executor) indexHint(hint string) string {
+	if e.options.Hint != "" {
+		return e.options.Hint
+	}
+	return hint
+}
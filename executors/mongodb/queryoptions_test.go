@@ -0,0 +1,54 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestExecutor_QueryOptions(t *testing.T) {
+	e := &Executor{options: &Options{
+		ExecutorOptions: query.DefaultExecutorOptions(),
+		QueryOptions:    QueryOptions{Hint: "brand_1", MaxTime: time.Second},
+	}}
+
+	t.Run("executor settings apply without an override", func(t *testing.T) {
+		bound := e.withQueryOptions(context.Background())
+		assert.Same(t, e, bound)
+		findOpts := bound.findOptions(bound.indexHint("price_1"))
+		assert.Equal(t, "brand_1", findOpts.Hint)
+		assert.Equal(t, time.Second, *findOpts.MaxTime)
+		assert.Nil(t, findOpts.AllowDiskUse)
+	})
+
+	t.Run("overrides replace set fields only", func(t *testing.T) {
+		ctx := WithQueryOptions(context.Background(), QueryOptions{
+			ReadPreference: readpref.SecondaryPreferred(),
+			MaxTime:        5 * time.Second,
+			AllowDiskUse:   true,
+		})
+		bound := e.withQueryOptions(ctx)
+		assert.Equal(t, readpref.SecondaryPreferred().Mode(), bound.options.ReadPreference.Mode())
+		assert.Equal(t, "brand_1", bound.options.Hint)
+
+		aggOpts := bound.aggregateOptions(bound.indexHint(""))
+		assert.Equal(t, "brand_1", aggOpts.Hint)
+		assert.Equal(t, 5*time.Second, *aggOpts.MaxTime)
+		assert.True(t, *aggOpts.AllowDiskUse)
+
+		// The executor itself is unchanged
+		assert.Nil(t, e.options.ReadPreference)
+		assert.Equal(t, time.Second, e.options.MaxTime)
+	})
+
+	t.Run("range hints apply without a hint setting", func(t *testing.T) {
+		plain := &Executor{options: &Options{ExecutorOptions: query.DefaultExecutorOptions()}}
+		countOpts := plain.countOptions(plain.indexHint("price_1"))
+		assert.Equal(t, "price_1", countOpts.Hint)
+		assert.Nil(t, countOpts.MaxTime)
+	})
+}
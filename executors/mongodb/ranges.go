@@ -0,0 +1,71 @@
+package mongodb
+
+import (
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// buildPlannedFilter builds the filter like buildFilter, but compiles a range
+// list (see query.ExecutorOptions.PlanRanges) from its merged ranges: an $or
+// of {field: {$gte: lo, $lte: hi}} predicates the planner can serve with one
+// index range scan each. hint is the RangeIndexHints entry of the range
+// list's field, empty without a range list or entry. Relation fields are
+// rejected; buildJoinedFilter handles them
+func (e *Executor) buildPlannedFilter(node query.Node) (filter bson.M, hint string, err error) {
+	if err := e.checkNoRelations(node); err != nil {
+		return nil, "", err
+	}
+	plan := e.options.PlanRanges(node)
+	if plan == nil {
+		filter, err = e.buildFilter(node)
+		return filter, "", err
+	}
+
+	ranges := make(bson.A, len(plan.Ranges))
+	for i, r := range plan.Ranges {
+		if ranges[i], err = e.buildRange(plan.Field, r); err != nil {
+			return nil, "", err
+		}
+	}
+	if len(ranges) == 1 {
+		filter = ranges[0].(bson.M)
+	} else {
+		filter = bson.M{"$or": ranges}
+	}
+	if plan.Rest != nil {
+		rest, err := e.buildFilter(plan.Rest)
+		if err != nil {
+			return nil, "", err
+		}
+		filter = bson.M{"$and": bson.A{filter, rest}}
+	}
+	return filter, e.options.RangeIndexHints[plan.Field], nil
+}
+
+// buildRange builds the predicate of a single range
+func (e *Executor) buildRange(field string, r query.Range) (bson.M, error) {
+	cond := bson.M{}
+	if r.Lower != nil {
+		value, err := e.convertValue(field, r.Lower)
+		if err != nil {
+			return nil, err
+		}
+		op := "$gt"
+		if r.LowerInclusive {
+			op = "$gte"
+		}
+		cond[op] = value
+	}
+	if r.Upper != nil {
+		value, err := e.convertValue(field, r.Upper)
+		if err != nil {
+			return nil, err
+		}
+		op := "$lt"
+		if r.UpperInclusive {
+			op = "$lte"
+		}
+		cond[op] = value
+	}
+	return bson.M{field: cond}, nil
+}
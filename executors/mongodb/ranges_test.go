@@ -0,0 +1,58 @@
+package mongodb
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestExecutor_BuildFilterRanges(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.RangeIndexHints = map[string]string{"price": "price_1"}
+	e := &Executor{options: &Options{ExecutorOptions: opts}}
+
+	between := func(lo, hi interface{}) query.Node {
+		return query.And(query.Gte("price", lo), query.Lte("price", hi))
+	}
+
+	t.Run("ranges become one predicate each", func(t *testing.T) {
+		filter, hint, err := e.buildPlannedFilter(query.And(
+			query.Or(between(50, 60), between(10, 20), between(15, 30)),
+			query.Eq("brand", "Sony"),
+		))
+		require.NoError(t, err)
+		assert.Equal(t, "price_1", hint)
+		assert.Equal(t, bson.M{"$and": bson.A{
+			bson.M{"$or": bson.A{
+				bson.M{"price": bson.M{"$gte": int64(10), "$lte": int64(30)}},
+				bson.M{"price": bson.M{"$gte": int64(50), "$lte": int64(60)}},
+			}},
+			bson.M{"brand": "Sony"},
+		}}, filter)
+	})
+
+	t.Run("ranges merged into one", func(t *testing.T) {
+		filter, _, err := e.buildPlannedFilter(query.Or(query.Lt("price", 10), between(5, 20)))
+		require.NoError(t, err)
+		assert.Equal(t, bson.M{"price": bson.M{"$lte": int64(20)}}, filter)
+	})
+
+	t.Run("other filters are unchanged", func(t *testing.T) {
+		filter, hint, err := e.buildPlannedFilter(query.Or(query.Lt("stock", 10), query.Eq("brand", "Sony")))
+		require.NoError(t, err)
+		assert.Empty(t, hint)
+		assert.Equal(t, bson.M{"$or": bson.A{
+			bson.M{"stock": bson.M{"$lt": int64(10)}},
+			bson.M{"brand": "Sony"},
+		}}, filter)
+	})
+
+	t.Run("BuildFilter merges ranges", func(t *testing.T) {
+		filter, err := BuildFilter(&query.Query{Filter: query.Or(between(1, 3), between(2, 4))}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, bson.M{"price": bson.M{"$gte": int64(1), "$lte": int64(4)}}, filter)
+	})
+}
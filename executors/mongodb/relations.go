@@ -0,0 +1,243 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Relation is a collection joined with $lookup for the filters on a
+// RelationMap prefix
+type Relation struct {
+	// From is the joined collection
+	From string
+
+	// LocalField is the field of the queried documents holding the key,
+	// e.g. "customer_id"
+	LocalField string
+
+	// ForeignField is the field of From matched against LocalField, e.g. "_id"
+	ForeignField string
+}
+
+// RelationMap maps query field prefixes to the collections they join, so
+// customer.name = "Ada" matches the orders of customers named Ada with
+//
+//	RelationMap{"customer": {From: "customers", LocalField: "customer_id", ForeignField: "_id"}}
+//
+// Queries on relation fields run as an aggregation: a $match on the conditions
+// on the collection's own fields, a $lookup per relation used and a $match on
+// the rest. A condition matches when any joined document matches, like an
+// array field. The joined documents are not part of the results.
+// AllowedFields lists relation fields with their prefix. Relation fields are
+// supported by Execute and Count; facets, writes and change streams reject them
+type RelationMap map[string]Relation
+
+// relationAliasPrefix starts the field $lookup stores the documents joined
+// for a prefix in
+const relationAliasPrefix = "__relation_"
+
+// relationPrefixes returns the RelationMap prefixes of the fields node refers
+// to, sorted. Bare search terms refer to the default search fields
+func (e *Executor) relationPrefixes(node query.Node) []string {
+	if len(e.options.Relations) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	add := func(field string) {
+		if prefix, ok := e.relationOf(field); ok {
+			seen[prefix] = true
+		}
+	}
+	query.Walk(node, func(node query.Node) bool {
+		if n, ok := node.(*query.ComparisonNode); ok {
+			if n.Field != query.SearchField {
+				add(n.Field)
+			} else {
+				for _, field := range e.options.SearchFields() {
+					add(field)
+				}
+			}
+		}
+		return true
+	})
+	prefixes := make([]string, 0, len(seen))
+	for prefix := range seen {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// relationOf returns the RelationMap prefix of field, if it has one
+func (e *Executor) relationOf(field string) (string, bool) {
+	prefix, _, found := strings.Cut(field, ".")
+	if !found {
+		return "", false
+	}
+	_, ok := e.options.Relations[prefix]
+	return prefix, ok
+}
+
+// checkNoRelations rejects filters on relation fields where no $lookup is run
+func (e *Executor) checkNoRelations(node query.Node) error {
+	if prefixes := e.relationPrefixes(node); len(prefixes) > 0 {
+		return fmt.Errorf("%w: relation %s is only supported by Execute and Count", query.ErrInvalidQuery, prefixes[0])
+	}
+	return nil
+}
+
+// buildJoinedFilter builds the filter of Execute and Count. Without relation
+// fields it is buildPlannedFilter. With them, filter holds the top-level AND
+// conditions on the collection's own fields, which can use its indexes, and
+// joins the $lookup stages of the relations followed by a $match on the other
+// conditions
+func (e *Executor) buildJoinedFilter(node query.Node) (filter bson.M, hint string, joins mongo.Pipeline, err error) {
+	if len(e.relationPrefixes(node)) == 0 {
+		filter, hint, err = e.buildPlannedFilter(node)
+		return filter, hint, nil, err
+	}
+
+	operands := []query.Node{node}
+	if n, ok := node.(*query.BinaryOpNode); ok && n.Operator == query.BinaryOpAnd {
+		operands = query.Operands(n)
+	}
+	var own, related query.Node
+	for _, operand := range operands {
+		if len(e.relationPrefixes(operand)) > 0 {
+			related = query.And(related, operand)
+		} else {
+			own = query.And(own, operand)
+		}
+	}
+
+	filter = bson.M{}
+	if own != nil {
+		if filter, hint, err = e.buildPlannedFilter(own); err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	// Relation fields are renamed to the fields their documents are joined in;
+	// values are still converted with the names of the query
+	renamed := make(map[string]string)
+	aliased := e.aliasRelations(related, renamed)
+	execOpts := *e.options.ExecutorOptions
+	execOpts.ValueConverter = func(field string, value interface{}) (interface{}, error) {
+		if original, ok := renamed[field]; ok {
+			field = original
+		}
+		return e.options.ConvertValue(field, value)
+	}
+	joined := &Executor{collection: e.collection, options: &Options{ExecutorOptions: &execOpts}}
+	rest, err := joined.buildFilter(aliased)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, prefix := range e.relationPrefixes(related) {
+		relation := e.options.Relations[prefix]
+		joins = append(joins, bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: relation.From},
+			{Key: "localField", Value: relation.LocalField},
+			{Key: "foreignField", Value: relation.ForeignField},
+			{Key: "as", Value: relationAliasPrefix + prefix},
+		}}})
+	}
+	return filter, hint, append(joins, bson.D{{Key: "$match", Value: rest}}), nil
+}
+
+// aliasRelations returns node with relation fields renamed to the fields
+// their documents are joined in, recording the original names in renamed.
+// Bare search terms are expanded to the default search fields first
+func (e *Executor) aliasRelations(node query.Node, renamed map[string]string) query.Node {
+	return query.Rewrite(node, func(node query.Node) query.Node {
+		n, ok := node.(*query.ComparisonNode)
+		if !ok {
+			return node
+		}
+		if n.Field == query.SearchField {
+			if expanded := e.options.ExpandPhrase(n, true); expanded != n {
+				return e.aliasRelations(expanded, renamed)
+			}
+			if fields := e.options.SearchFields(); len(fields) > 0 {
+				return e.aliasRelations(query.ExpandDefaultSearch(n, fields), renamed)
+			}
+			return node
+		}
+		prefix, ok := e.relationOf(n.Field)
+		if !ok {
+			return node
+		}
+		aliased := *n
+		aliased.Field = relationAliasPrefix + prefix + n.Field[len(prefix):]
+		renamed[aliased.Field] = n.Field
+		return &aliased
+	})
+}
+
+// joinPipeline adds the joins of buildJoinedFilter to the pipeline of Execute,
+// after its first $match, and removes the joined documents from the results.
+// A nil pipeline stands for the Find command of filter and findOpts
+func (e *Executor) joinPipeline(pipeline mongo.Pipeline, filter bson.M, findOpts *options.FindOptions, joins mongo.Pipeline) mongo.Pipeline {
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{{{Key: "$match", Value: filter}}}
+		if findOpts.Projection != nil {
+			// The only projection is the text score, added to the documents
+			pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: findOpts.Projection}})
+		}
+		if findOpts.Sort != nil {
+			pipeline = append(pipeline, bson.D{{Key: "$sort", Value: findOpts.Sort}})
+		}
+		if findOpts.Skip != nil {
+			pipeline = append(pipeline, bson.D{{Key: "$skip", Value: *findOpts.Skip}})
+		}
+		if findOpts.Limit != nil {
+			pipeline = append(pipeline, bson.D{{Key: "$limit", Value: *findOpts.Limit}})
+		}
+	}
+	joined := append(mongo.Pipeline{pipeline[0]}, joins...)
+	joined = append(joined, pipeline[1:]...)
+
+	unset := bson.M{}
+	for _, stage := range joins {
+		lookup, ok := stage[0].Value.(bson.D)
+		if !ok || stage[0].Key != "$lookup" {
+			continue
+		}
+		for _, elem := range lookup {
+			if elem.Key == "as" {
+				unset[elem.Value.(string)] = 0
+			}
+		}
+	}
+	return append(joined, bson.D{{Key: "$project", Value: unset}})
+}
+
+// countJoined counts the documents matching filter and joins exactly
+func (e *Executor) countJoined(ctx context.Context, filter bson.M, joins mongo.Pipeline) (int64, error) {
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, joins...)
+	pipeline = append(pipeline, bson.D{{Key: "$count", Value: "n"}})
+	cur, err := e.collection.Aggregate(ctx, pipeline, e.aggregateOptions(""))
+	if err != nil {
+		return 0, query.NewExecutionError("count documents", err)
+	}
+	defer cur.Close(ctx)
+
+	var counts []struct {
+		N int64 `bson:"n"`
+	}
+	if err := cur.All(ctx, &counts); err != nil {
+		return 0, query.NewExecutionError("count documents", err)
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+	return counts[0].N, nil
+}
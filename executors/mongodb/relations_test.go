@@ -0,0 +1,64 @@
+package mongodb
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestExecutor_BuildFilterRelations(t *testing.T) {
+	e := &Executor{options: &Options{
+		ExecutorOptions: query.DefaultExecutorOptions(),
+		Relations: RelationMap{
+			"customer": {From: "customers", LocalField: "customer_id", ForeignField: "_id"},
+		},
+	}}
+	lookup := bson.D{{Key: "$lookup", Value: bson.D{
+		{Key: "from", Value: "customers"},
+		{Key: "localField", Value: "customer_id"},
+		{Key: "foreignField", Value: "_id"},
+		{Key: "as", Value: "__relation_customer"},
+	}}}
+
+	t.Run("own conditions stay in the filter", func(t *testing.T) {
+		filter, _, joins, err := e.buildJoinedFilter(query.And(query.Eq("customer.name", "Ada"), query.Gt("total", 10)))
+		require.NoError(t, err)
+		assert.Equal(t, bson.M{"total": bson.M{"$gt": int64(10)}}, filter)
+		assert.Equal(t, mongo.Pipeline{
+			lookup,
+			{{Key: "$match", Value: bson.M{"__relation_customer.name": "Ada"}}},
+		}, joins)
+	})
+
+	t.Run("filters without relation fields have no joins", func(t *testing.T) {
+		filter, _, joins, err := e.buildJoinedFilter(query.Eq("status", "paid"))
+		require.NoError(t, err)
+		assert.Equal(t, bson.M{"status": "paid"}, filter)
+		assert.Nil(t, joins)
+	})
+
+	t.Run("find becomes a joined aggregation", func(t *testing.T) {
+		filter, _, joins, err := e.buildJoinedFilter(query.Eq("customer.name", "Ada"))
+		require.NoError(t, err)
+		findOpts := options.Find().SetSort(bson.D{{Key: "total", Value: -1}}).SetLimit(10)
+		pipeline := e.joinPipeline(nil, filter, findOpts, joins)
+		assert.Equal(t, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{}}},
+			lookup,
+			{{Key: "$match", Value: bson.M{"__relation_customer.name": "Ada"}}},
+			{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}}}},
+			{{Key: "$limit", Value: int64(10)}},
+			{{Key: "$project", Value: bson.M{"__relation_customer": 0}}},
+		}, pipeline)
+	})
+
+	t.Run("relation fields are rejected outside Execute and Count", func(t *testing.T) {
+		_, _, err := e.buildPlannedFilter(query.Or(query.Eq("customer.name", "Ada"), query.Gt("total", 10)))
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+	})
+}
@@ -0,0 +1,275 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Change stream operations Watch reports
+const (
+	OperationInsert  = "insert"
+	OperationUpdate  = "update"
+	OperationReplace = "replace"
+)
+
+// changeDocumentField is the field of change events holding the document
+const changeDocumentField = "fullDocument"
+
+// ChangeStream iterates the changes of a watched query, like mongo.ChangeStream:
+//
+//	stream, err := mongodb.Watch(ctx, exec, q, "")
+//	defer stream.Close(ctx)
+//	for stream.Next(ctx) {
+//	    var product Product
+//	    if err := stream.Decode(&product); err != nil { ... }
+//	    resume, _ := stream.Cursor() // store it to continue after a restart
+//	}
+//	err = stream.Err()
+type ChangeStream struct {
+	stream    *mongo.ChangeStream
+	queryHash uint64
+	event     changeEvent
+	err       error
+}
+
+// changeEvent holds the fields of a change event ChangeStream reads
+type changeEvent struct {
+	OperationType string   `bson:"operationType"`
+	FullDocument  bson.Raw `bson:"fullDocument"`
+}
+
+// Watch opens a change stream reporting the documents inserted, updated or
+// replaced in e's collection that match q after the change. e must be a
+// MongoDB executor. See Executor.Watch
+func Watch(ctx context.Context, e executor.Executor, q *query.Query, cursorParam string) (*ChangeStream, error) {
+	mongoExec, ok := e.(*Executor)
+	if !ok {
+		return nil, fmt.Errorf("%w: Watch needs a MongoDB executor, got %s", query.ErrInvalidQuery, e.Name())
+	}
+	return mongoExec.Watch(ctx, q, cursorParam)
+}
+
+// Watch opens a change stream reporting the documents inserted, updated or
+// replaced that match the filter of q after the change, e.g. to stream live
+// results to a client. Updates are matched against the current document
+// (fullDocument: updateLookup). Documents that stop matching or are deleted
+// are not reported. The filter is validated, authorized and scoped like
+// Execute does; placeholders and relative times are resolved when the stream
+// opens. MATCH is not supported. Sorting and pagination are ignored.
+//
+// cursorParam is empty to start at the current time, or a cursor from
+// ChangeStream.Cursor to resume after that event. Cursors are bound to the
+// query like page cursors. Change streams need a replica set or sharded cluster
+func (e *Executor) Watch(ctx context.Context, q *query.Query, cursorParam string) (*ChangeStream, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "watch", q)
+	stream, err := e.watch(ctx, q, cursorParam, &entry)
+	e.options.LogWatch(ctx, entry, err)
+	return stream, query.WrapError(e.Name(), "watch", err)
+}
+
+func (e *Executor) watch(ctx context.Context, q *query.Query, cursorParam string, entry *query.QueryLog) (*ChangeStream, error) {
+	if q == nil {
+		return nil, query.ErrInvalidQuery
+	}
+	q, err := e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return nil, err
+	}
+	if err := e.checkNoRelations(q.Filter); err != nil {
+		return nil, err
+	}
+	pipeline, err := BuildChangeStreamPipeline(q, e.options.ExecutorOptions)
+	if err != nil {
+		return nil, err
+	}
+	scoped := e.options.ScopedQuery(q)
+	entry.Filter = scoped.Filter
+
+	streamOpts, err := changeStreamOptions(cursorParam, scoped)
+	if err != nil {
+		return nil, err
+	}
+	if collation := e.collation(); collation != nil {
+		streamOpts.SetCollation(*collation)
+	}
+	stream, err := e.collection.Watch(ctx, pipeline, streamOpts)
+	if err != nil {
+		return nil, query.NewExecutionError("watch collection", err)
+	}
+	return &ChangeStream{stream: stream, queryHash: cursor.QueryHash(scoped)}, nil
+}
+
+// changeStreamOptions returns the options of a change stream for q, resuming
+// after the event of cursorParam when it is given
+func changeStreamOptions(cursorParam string, q *query.Query) (*options.ChangeStreamOptions, error) {
+	streamOpts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	cursorData, err := cursor.Decode(cursorParam)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", query.ErrInvalidCursor, err)
+	}
+	if cursorData == nil {
+		return streamOpts, nil
+	}
+	if len(cursorData.ResumeToken) == 0 {
+		return nil, fmt.Errorf("%w: not a change stream cursor", query.ErrInvalidCursor)
+	}
+	if err := cursorData.CheckQuery(q); err != nil {
+		return nil, err
+	}
+	return streamOpts.SetResumeAfter(bson.Raw(cursorData.ResumeToken)), nil
+}
+
+// BuildChangeStreamPipeline translates the query's filter into the $match
+// stage of a change stream pipeline: the filter, built like BuildFilter does,
+// is applied to the changed document of insert, update and replace events.
+// Pass it to Collection.Watch with fullDocument set to updateLookup so
+// updates carry the document. If opts is nil, query.DefaultExecutorOptions()
+// is used
+func BuildChangeStreamPipeline(q *query.Query, opts *query.ExecutorOptions) (mongo.Pipeline, error) {
+	filter, err := BuildFilter(q, opts)
+	if err != nil {
+		return nil, err
+	}
+	match, err := prefixFields(filter, changeDocumentField+".")
+	if err != nil {
+		return nil, err
+	}
+	match["operationType"] = bson.M{"$in": bson.A{OperationInsert, OperationUpdate, OperationReplace}}
+	return mongo.Pipeline{{{Key: "$match", Value: match}}}, nil
+}
+
+// prefixFields returns filter with every field path moved under prefix, e.g.
+// {price: {$lt: 10}} becomes {"fullDocument.price": {$lt: 10}}
+func prefixFields(filter bson.M, prefix string) (bson.M, error) {
+	prefixed := make(bson.M, len(filter))
+	for key, value := range filter {
+		switch {
+		case key == "$and" || key == "$or" || key == "$nor":
+			clauses, ok := value.(bson.A)
+			if !ok {
+				return nil, query.ErrInvalidQuery
+			}
+			out := make(bson.A, len(clauses))
+			for i, clause := range clauses {
+				m, ok := clause.(bson.M)
+				if !ok {
+					return nil, query.ErrInvalidQuery
+				}
+				var err error
+				if out[i], err = prefixFields(m, prefix); err != nil {
+					return nil, err
+				}
+			}
+			prefixed[key] = out
+		case key == "$expr":
+			prefixed[key] = prefixExpr(value, prefix)
+		case key == "$text":
+			return nil, fmt.Errorf("%w: MATCH cannot be used in change streams", query.ErrInvalidQuery)
+		case strings.HasPrefix(key, "$"):
+			return nil, fmt.Errorf("%w: %s cannot be used in change streams", query.ErrInvalidQuery, key)
+		default:
+			prefixed[prefix+key] = value
+		}
+	}
+	return prefixed, nil
+}
+
+// prefixExpr returns an aggregation expression with its field references
+// ("$field") moved under prefix
+func prefixExpr(expr interface{}, prefix string) interface{} {
+	switch v := expr.(type) {
+	case string:
+		if strings.HasPrefix(v, "$") && !strings.HasPrefix(v, "$$") {
+			return "$" + prefix + v[1:]
+		}
+		return v
+	case bson.M:
+		out := make(bson.M, len(v))
+		for key, value := range v {
+			out[key] = prefixExpr(value, prefix)
+		}
+		return out
+	case bson.A:
+		out := make(bson.A, len(v))
+		for i, value := range v {
+			out[i] = prefixExpr(value, prefix)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Next waits for the next change, reporting false when the stream ends, ctx
+// is done or the event cannot be read; check Err then
+func (s *ChangeStream) Next(ctx context.Context) bool {
+	if s.err != nil || !s.stream.Next(ctx) {
+		return false
+	}
+	s.event = changeEvent{}
+	if err := s.stream.Decode(&s.event); err != nil {
+		s.err = query.NewExecutionError("decode change event", err)
+		return false
+	}
+	return true
+}
+
+// Operation returns the operation of the current change: OperationInsert,
+// OperationUpdate or OperationReplace
+func (s *ChangeStream) Operation() string {
+	return s.event.OperationType
+}
+
+// Decode decodes the changed document into dest. An update of a document
+// deleted before its lookup has no document and returns query.ErrNoRecordsFound
+func (s *ChangeStream) Decode(dest interface{}) error {
+	if len(s.event.FullDocument) == 0 {
+		return query.ErrNoRecordsFound
+	}
+	if err := bson.Unmarshal(s.event.FullDocument, dest); err != nil {
+		return query.NewExecutionError("decode changed document", err)
+	}
+	return nil
+}
+
+// Cursor returns a cursor resuming the watch after the current change, in the
+// envelope of page cursors. Pass it to Watch with the same query
+func (s *ChangeStream) Cursor() (string, error) {
+	token := s.stream.ResumeToken()
+	if token == nil {
+		return "", nil
+	}
+	return cursor.Encode(&cursor.CursorData{
+		Direction:   "next",
+		QueryHash:   s.queryHash,
+		ResumeToken: token,
+	})
+}
+
+// Err returns the error that ended the stream, if any
+func (s *ChangeStream) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.stream.Err(); err != nil {
+		return query.NewExecutionError("watch collection", err)
+	}
+	return nil
+}
+
+// Close closes the change stream
+func (s *ChangeStream) Close(ctx context.Context) error {
+	return s.stream.Close(ctx)
+}
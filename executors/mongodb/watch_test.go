@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/internal/cursor"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestBuildChangeStreamPipeline(t *testing.T) {
+	q := &query.Query{Filter: query.Or(
+		query.Eq("brand", "Sony"),
+		query.Compare("price", query.OpLessThan, 10),
+	)}
+
+	pipeline, err := BuildChangeStreamPipeline(q, nil)
+	require.NoError(t, err)
+	assert.Equal(t, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"$or": bson.A{
+				bson.M{"fullDocument.brand": "Sony"},
+				bson.M{"fullDocument.price": bson.M{"$lt": int64(10)}},
+			},
+			"operationType": bson.M{"$in": bson.A{OperationInsert, OperationUpdate, OperationReplace}},
+		}}},
+	}, pipeline)
+}
+
+func TestBuildChangeStreamPipeline_MatchUnsupported(t *testing.T) {
+	q := &query.Query{Filter: &query.ComparisonNode{Field: "description", Operator: query.OpMatch, Value: query.StringValue("wireless")}}
+
+	_, err := BuildChangeStreamPipeline(q, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+}
+
+func TestPrefixExpr(t *testing.T) {
+	expr := bson.M{"$gt": bson.A{bson.M{"$size": "$tags"}, 2, "$$ROOT"}}
+	assert.Equal(t, bson.M{"$gt": bson.A{bson.M{"$size": "$fullDocument.tags"}, 2, "$$ROOT"}}, prefixExpr(expr, "fullDocument."))
+}
+
+func TestChangeStreamOptions(t *testing.T) {
+	q := &query.Query{Filter: query.Eq("brand", "Sony")}
+
+	opts, err := changeStreamOptions("", q)
+	require.NoError(t, err)
+	assert.Nil(t, opts.ResumeAfter)
+
+	token := bson.Raw{0x05, 0x00, 0x00, 0x00, 0x00}
+	encoded, err := cursor.Encode(&cursor.CursorData{Direction: "next", QueryHash: cursor.QueryHash(q), ResumeToken: token})
+	require.NoError(t, err)
+	opts, err = changeStreamOptions(encoded, q)
+	require.NoError(t, err)
+	assert.Equal(t, token, opts.ResumeAfter)
+
+	_, err = changeStreamOptions(encoded, &query.Query{Filter: query.Eq("brand", "Apple")})
+	assert.ErrorIs(t, err, query.ErrCursorQueryMismatch)
+
+	pageCursor, err := cursor.Encode(&cursor.CursorData{Direction: "next", Offset: 20})
+	require.NoError(t, err)
+	_, err = changeStreamOptions(pageCursor, q)
+	assert.ErrorIs(t, err, query.ErrInvalidCursor)
+}
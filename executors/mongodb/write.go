@@ -0,0 +1,95 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/hadi77ir/go-query/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeleteWhere deletes the documents matching the query's filter with deleteMany
+func (e *Executor) DeleteWhere(ctx context.Context, q *query.Query) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "delete", q)
+	affected, err := e.write(ctx, q, nil, &entry)
+	e.options.LogWrite(ctx, entry, affected, err)
+	return affected, query.WrapError(e.Name(), "delete", err)
+}
+
+// UpdateWhere sets the fields in changes on the documents matching the
+// query's filter with updateMany and $set. Dotted fields set nested values.
+// It returns the number of documents modified
+func (e *Executor) UpdateWhere(ctx context.Context, q *query.Query, changes map[string]interface{}) (int64, error) {
+	e = e.withCurrentOptions()
+	entry := query.NewQueryLog(e.Name(), "update", q)
+	if changes == nil {
+		changes = map[string]interface{}{}
+	}
+	affected, err := e.write(ctx, q, changes, &entry)
+	e.options.LogWrite(ctx, entry, affected, err)
+	return affected, query.WrapError(e.Name(), "update", err)
+}
+
+// write deletes the documents matching q, or updates them with changes when it is not nil
+func (e *Executor) write(ctx context.Context, q *query.Query, changes map[string]interface{}, entry *query.QueryLog) (int64, error) {
+	update, err := e.buildUpdate(ctx, q, changes)
+	if err != nil {
+		return 0, err
+	}
+	q, err = e.options.ResolvePlaceholders(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	if err := e.options.AuthorizeFields(ctx, q); err != nil {
+		return 0, err
+	}
+	if err := e.options.ValidateFilter(q.Filter); err != nil {
+		return 0, err
+	}
+	q = e.options.ScopedQuery(q)
+	entry.Filter = q.Filter
+
+	if err := e.checkNoRelations(q.Filter); err != nil {
+		return 0, err
+	}
+	filter, err := e.buildFilter(q.Filter)
+	if err != nil {
+		return 0, err
+	}
+	if update == nil {
+		res, err := e.collection.DeleteMany(ctx, filter, options.Delete().SetCollation(e.collation()))
+		if err != nil {
+			return 0, query.NewExecutionError("delete documents", err)
+		}
+		return res.DeletedCount, nil
+	}
+	res, err := e.collection.UpdateMany(ctx, filter, update, options.Update().SetCollation(e.collation()))
+	if err != nil {
+		return 0, query.NewExecutionError("update documents", err)
+	}
+	return res.ModifiedCount, nil
+}
+
+// buildUpdate validates a write and returns the $set document of changes,
+// nil for deletes
+func (e *Executor) buildUpdate(ctx context.Context, q *query.Query, changes map[string]interface{}) (bson.M, error) {
+	if err := e.options.ValidateWrite(q); err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		return nil, nil
+	}
+	changes, err := e.options.ValidateChanges(ctx, changes)
+	if err != nil {
+		return nil, err
+	}
+	set := bson.M{}
+	for field, value := range changes {
+		if err := validFieldPath(field); err != nil {
+			return nil, err
+		}
+		set[field] = value
+	}
+	return bson.M{"$set": set}, nil
+}
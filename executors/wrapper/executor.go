@@ -47,7 +47,7 @@ func (e *WrapperExecutor) Close() error {
 func (e *WrapperExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
 	// Validate all fields in the query
 	if err := e.validateQueryFields(q); err != nil {
-		return nil, err
+		return nil, query.WrapError(e.Name(), "execute", err)
 	}
 
 	// Delegate to inner executor (which will also validate its own allowed fields)
@@ -60,7 +60,7 @@ func (e *WrapperExecutor) Execute(ctx context.Context, q *query.Query, cursor st
 func (e *WrapperExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
 	// Validate all fields in the query
 	if err := e.validateQueryFields(q); err != nil {
-		return 0, err
+		return 0, query.WrapError(e.Name(), "count", err)
 	}
 
 	// Delegate to inner executor
@@ -73,10 +73,15 @@ func (e *WrapperExecutor) validateQueryFields(q *query.Query) error {
 	// Validate sort field
 	if q.SortBy != "" {
 		if !e.isFieldAllowed(q.SortBy) {
-			return query.FieldNotAllowedError(q.SortBy)
+			return query.FieldNotAllowedError(q.SortBy, e.allowedFields...)
 		}
 	}
 
+	// Validate distinct_on field
+	if q.DistinctOn != "" && !e.isFieldAllowed(q.DistinctOn) {
+		return query.FieldNotAllowedError(q.DistinctOn, e.allowedFields...)
+	}
+
 	// Validate fields in filter
 	if q.Filter != nil {
 		if err := e.validateFilterFields(q.Filter); err != nil {
@@ -107,9 +112,9 @@ func (e *WrapperExecutor) validateFilterFields(node query.Node) error {
 		// But we can't resolve it here without executor options context
 		// The inner executor will handle this, but we still need to validate
 		// the field name as-is if it's not the special placeholder
-		if field != "__DEFAULT_SEARCH__" {
+		if field != query.SearchField {
 			if !e.isFieldAllowed(field) {
-				return query.FieldNotAllowedError(field)
+				return query.FieldNotAllowedError(field, e.allowedFields...)
 			}
 		}
 		// For __DEFAULT_SEARCH__, we let the inner executor resolve it
@@ -2,6 +2,7 @@ package wrapper
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/hadi77ir/go-query/executors/memory"
@@ -241,3 +242,25 @@ func TestWrapperExecutor_Name(t *testing.T) {
 
 	assert.Equal(t, "wrapper", wrapperExecutor.Name())
 }
+
+func TestWrapperExecutor_ErrorBackend(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"name"}
+	wrapperExecutor := NewExecutor(memory.NewExecutor(getTestUsers(), opts), []string{"name", "email"})
+	ctx := context.Background()
+
+	var users []User
+	var qerr *query.Error
+
+	// Rejected by the wrapper
+	_, err := wrapperExecutor.Execute(ctx, &query.Query{Filter: query.Eq("ssn", "x")}, "", &users)
+	require.True(t, errors.As(err, &qerr))
+	assert.Equal(t, "wrapper", qerr.Backend)
+	assert.Equal(t, "ssn", qerr.Field)
+
+	// Rejected by the inner executor
+	_, err = wrapperExecutor.Count(ctx, &query.Query{Filter: query.Eq("email", "x")})
+	require.True(t, errors.As(err, &qerr))
+	assert.Equal(t, "memory", qerr.Backend)
+	assert.Equal(t, "email", qerr.Field)
+}
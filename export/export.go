@@ -0,0 +1,370 @@
+// Package export streams every item matching a query to an io.Writer as CSV
+// or JSON Lines, following page cursors until the results or the row limit
+// run out, e.g. for "export search results" downloads:
+//
+//	w.Header().Set("Content-Type", export.ContentType(export.FormatCSV))
+//	rows, err := export.Export[Product](ctx, exec, q, w, &export.Options{
+//	    Format: export.FormatCSV,
+//	    Fields: []string{"name", "brand.name", "price"},
+//	    Limit:  100000,
+//	})
+//
+// Items are converted through encoding/json, so fields are named by their
+// json tags and nested fields are reached with dotted names.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Format is the output format of an export
+type Format int
+
+const (
+	// FormatCSV writes a header row followed by one row per item
+	FormatCSV Format = iota
+	// FormatJSONL writes one JSON object per line
+	FormatJSONL
+)
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatJSONL:
+		return "jsonl"
+	default:
+		return "csv"
+	}
+}
+
+// ParseFormat parses "csv", "jsonl" or "ndjson" into a Format
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	default:
+		return FormatCSV, fmt.Errorf("unknown export format: %q", s)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func ContentType(f Format) string {
+	if f == FormatJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv"
+}
+
+// Progress reports how far an export got
+type Progress struct {
+	// Rows is the number of rows written so far
+	Rows int
+
+	// Pages is the number of pages executed so far
+	Pages int
+
+	// TotalItems is Result.TotalItems of the first page: the number of
+	// matching items, or query.TotalUnknown
+	TotalItems int64
+}
+
+// Options configure an export
+type Options struct {
+	// Format is the output format. The default is FormatCSV
+	Format Format
+
+	// Fields projects the columns (CSV) or keys (JSON Lines) written, in
+	// order. Dotted names such as "author.name" reach into nested objects;
+	// missing fields are written empty (CSV) or null (JSON Lines).
+	// Without Fields, JSON Lines writes whole items and CSV writes the keys
+	// of the first item, sorted
+	Fields []string
+
+	// Limit is the maximum number of rows written. 0 means no limit besides
+	// the query's own limit
+	Limit int
+
+	// Cursor starts the export at a page other than the first
+	Cursor string
+
+	// OnProgress is called after the rows of each page are written.
+	// Returning an error stops the export with that error
+	OnProgress func(Progress) error
+
+	// DisableFormulaEscaping writes CSV cells as they are. By default, text
+	// cells starting with =, +, - or @ are prefixed with ' so spreadsheets
+	// opening the file do not run them as formulas (CSV injection). Numbers
+	// are never escaped
+	DisableFormulaEscaping bool
+}
+
+// Export executes q with e, page by page, and writes every item to w. T is
+// the item type the executor fills, e.g. a model struct or
+// map[string]interface{}. It returns the number of rows written, which is
+// accurate even when an error stops the export part way. A query matching
+// nothing writes no rows, and only the CSV header when Fields are set
+func Export[T any](ctx context.Context, e executor.Executor, q *query.Query, w io.Writer, opts *Options) (int, error) {
+	if q == nil {
+		return 0, query.ErrInvalidQuery
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := newEncoder(bw, opts)
+	progress := Progress{}
+	cursor := opts.Cursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress.Rows, err
+		}
+		var page []T
+		result, err := e.Execute(ctx, q, cursor, &page)
+		if errors.Is(err, query.ErrNoRecordsFound) {
+			// Executors without AllowEmptyResults report an empty result as an
+			// error; it exports as zero rows
+			page, err = nil, nil
+			if result == nil {
+				result = &query.Result{}
+			}
+		}
+		if err != nil {
+			return progress.Rows, err
+		}
+		if progress.Pages == 0 {
+			progress.TotalItems = result.TotalItems
+		}
+		progress.Pages++
+
+		for i := range page {
+			if opts.Limit > 0 && progress.Rows >= opts.Limit {
+				break
+			}
+			if err := enc.encode(&page[i]); err != nil {
+				return progress.Rows, fmt.Errorf("encoding item %d: %w", progress.Rows, err)
+			}
+			progress.Rows++
+		}
+		if err := bw.Flush(); err != nil {
+			return progress.Rows, err
+		}
+		if opts.OnProgress != nil {
+			if err := opts.OnProgress(progress); err != nil {
+				return progress.Rows, err
+			}
+		}
+
+		if len(page) == 0 || !result.HasNextPage() || (opts.Limit > 0 && progress.Rows >= opts.Limit) {
+			break
+		}
+		cursor = result.NextPageCursor
+	}
+	return progress.Rows, enc.finish()
+}
+
+// encoder writes items in an export format
+type encoder struct {
+	w      *bufio.Writer
+	format Format
+	fields []string
+	csv    *csv.Writer
+	header bool
+	escape bool // escape CSV formulas
+}
+
+func newEncoder(w *bufio.Writer, opts *Options) *encoder {
+	enc := &encoder{w: w, format: opts.Format, fields: opts.Fields, escape: !opts.DisableFormulaEscaping}
+	if enc.format == FormatCSV {
+		enc.csv = csv.NewWriter(w)
+	}
+	return enc
+}
+
+// encode writes one item
+func (enc *encoder) encode(item interface{}) error {
+	if enc.format == FormatJSONL && len(enc.fields) == 0 {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if _, err := enc.w.Write(data); err != nil {
+			return err
+		}
+		return enc.w.WriteByte('\n')
+	}
+
+	object, err := toObject(item)
+	if err != nil {
+		return err
+	}
+	if enc.format == FormatJSONL {
+		return enc.writeJSONFields(object)
+	}
+	return enc.writeCSVRow(object)
+}
+
+// finish writes the pending output, including the CSV header of an export
+// without rows when Fields are known
+func (enc *encoder) finish() error {
+	if enc.csv != nil {
+		if !enc.header && len(enc.fields) > 0 {
+			if err := enc.writeCSVHeader(); err != nil {
+				return err
+			}
+		}
+		enc.csv.Flush()
+		if err := enc.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return enc.w.Flush()
+}
+
+func (enc *encoder) writeJSONFields(object map[string]interface{}) error {
+	var line bytes.Buffer
+	line.WriteByte('{')
+	for i, field := range enc.fields {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		key, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(lookup(object, field))
+		if err != nil {
+			return err
+		}
+		line.Write(key)
+		line.WriteByte(':')
+		line.Write(value)
+	}
+	line.WriteString("}\n")
+	_, err := enc.w.Write(line.Bytes())
+	return err
+}
+
+func (enc *encoder) writeCSVRow(object map[string]interface{}) error {
+	if !enc.header {
+		if len(enc.fields) == 0 {
+			for key := range object {
+				enc.fields = append(enc.fields, key)
+			}
+			sort.Strings(enc.fields)
+		}
+		if err := enc.writeCSVHeader(); err != nil {
+			return err
+		}
+		enc.header = true
+	}
+
+	row := make([]string, len(enc.fields))
+	for i, field := range enc.fields {
+		value := lookup(object, field)
+		cell, err := formatCell(value)
+		if err != nil {
+			return err
+		}
+		if _, text := value.(string); text && enc.escape {
+			cell = escapeFormula(cell)
+		}
+		row[i] = cell
+	}
+	if err := enc.csv.Write(row); err != nil {
+		return err
+	}
+	// Flush the csv writer into the buffered writer so Export can flush
+	// complete pages
+	enc.csv.Flush()
+	return enc.csv.Error()
+}
+
+// writeCSVHeader writes the field names. Names taken from the keys of map
+// items may come from users, so they are escaped like cells
+func (enc *encoder) writeCSVHeader() error {
+	header := enc.fields
+	if enc.escape {
+		header = make([]string, len(enc.fields))
+		for i, field := range enc.fields {
+			header[i] = escapeFormula(field)
+		}
+	}
+	return enc.csv.Write(header)
+}
+
+// toObject converts an item into its JSON object form. Numbers are kept as
+// json.Number so integers are written without a float conversion
+func toObject(item interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var object map[string]interface{}
+	if err := dec.Decode(&object); err != nil {
+		return nil, fmt.Errorf("%w: items must encode as JSON objects", query.ErrInvalidDestination)
+	}
+	return object, nil
+}
+
+// lookup returns the value of a possibly dotted field, or nil when it is missing
+func lookup(object map[string]interface{}, field string) interface{} {
+	if value, ok := object[field]; ok {
+		return value
+	}
+	head, rest, found := strings.Cut(field, ".")
+	if !found {
+		return nil
+	}
+	nested, ok := object[head].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return lookup(nested, rest)
+}
+
+// formatCell formats a JSON value as a CSV cell. Objects and arrays are
+// written as JSON
+func formatCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
+
+// escapeFormula prefixes a cell that spreadsheets would run as a formula
+// with '
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,220 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type brand struct {
+	Name string `json:"name"`
+}
+
+type product struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Brand brand    `json:"brand"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// pagingExecutor returns products two at a time; cursors are offsets. Like
+// executors with default options, it reports no products as ErrNoRecordsFound
+type pagingExecutor struct {
+	products []product
+	pages    int
+}
+
+func (p *pagingExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	items, ok := dest.(*[]product)
+	if !ok {
+		return nil, query.ErrInvalidDestination
+	}
+	offset := 0
+	if cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(cursor); err != nil {
+			return nil, query.ErrInvalidCursor
+		}
+	}
+	p.pages++
+	end := offset + 2
+	if end > len(p.products) {
+		end = len(p.products)
+	}
+	*items = append(*items, p.products[offset:end]...)
+	result := &query.Result{TotalItems: int64(len(p.products)), ItemsReturned: end - offset}
+	if end < len(p.products) {
+		result.NextPageCursor = strconv.Itoa(end)
+	}
+	if len(p.products) == 0 {
+		return result, query.ErrNoRecordsFound
+	}
+	return result, nil
+}
+
+func (p *pagingExecutor) Count(ctx context.Context, q *query.Query) (int64, error) {
+	return int64(len(p.products)), nil
+}
+func (p *pagingExecutor) Name() string { return "paging" }
+func (p *pagingExecutor) Close() error { return nil }
+
+func newPagingExecutor() *pagingExecutor {
+	return &pagingExecutor{products: []product{
+		{ID: 1, Name: "Cable, USB-C", Price: 9.5, Brand: brand{Name: "Anker"}, Tags: []string{"usb"}},
+		{ID: 2, Name: "Charger", Price: 25, Brand: brand{Name: "Anker"}},
+		{ID: 3, Name: "Mouse", Price: 19.99, Brand: brand{Name: "Logitech"}},
+	}}
+}
+
+func TestExport_CSV(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("follows cursors and projects fields", func(t *testing.T) {
+		exec := newPagingExecutor()
+		var buf bytes.Buffer
+		rows, err := Export[product](ctx, exec, &query.Query{}, &buf, &Options{
+			Fields: []string{"id", "name", "brand.name", "price", "missing"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, rows)
+		assert.Equal(t, 2, exec.pages)
+		assert.Equal(t, "id,name,brand.name,price,missing\n"+
+			"1,\"Cable, USB-C\",Anker,9.5,\n"+
+			"2,Charger,Anker,25,\n"+
+			"3,Mouse,Logitech,19.99,\n", buf.String())
+	})
+
+	t.Run("without fields uses the keys of the first item", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := Export[product](ctx, newPagingExecutor(), &query.Query{}, &buf, &Options{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "brand,id,name,price,tags\n"+
+			"\"{\"\"name\"\":\"\"Anker\"\"}\",1,\"Cable, USB-C\",9.5,\"[\"\"usb\"\"]\"\n", buf.String())
+	})
+
+	t.Run("escapes formulas", func(t *testing.T) {
+		exec := &pagingExecutor{products: []product{
+			{ID: 1, Name: "=HYPERLINK(\"http://evil\")", Price: -5},
+			{ID: 2, Name: "@SUM(A1)", Brand: brand{Name: "+1"}},
+			{ID: 3, Name: "-2+3", Brand: brand{Name: "a=b"}},
+		}}
+		fields := []string{"name", "brand.name", "price"}
+		var buf bytes.Buffer
+		_, err := Export[product](ctx, exec, &query.Query{}, &buf, &Options{Fields: fields})
+		require.NoError(t, err)
+		assert.Equal(t, "name,brand.name,price\n"+
+			"\"'=HYPERLINK(\"\"http://evil\"\")\",,-5\n"+
+			"'@SUM(A1),'+1,0\n"+
+			"'-2+3,a=b,0\n", buf.String())
+
+		buf.Reset()
+		_, err = Export[product](ctx, exec, &query.Query{}, &buf, &Options{Fields: fields, DisableFormulaEscaping: true})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "\n@SUM(A1),+1,0\n")
+	})
+
+	t.Run("header without rows", func(t *testing.T) {
+		var buf bytes.Buffer
+		rows, err := Export[product](ctx, &pagingExecutor{}, &query.Query{}, &buf, &Options{Fields: []string{"id", "name"}})
+		require.NoError(t, err)
+		assert.Equal(t, 0, rows)
+		assert.Equal(t, "id,name\n", buf.String())
+
+		buf.Reset()
+		rows, err = Export[product](ctx, &pagingExecutor{}, &query.Query{}, &buf, &Options{Format: FormatJSONL})
+		require.NoError(t, err)
+		assert.Equal(t, 0, rows)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestExport_JSONL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("whole items", func(t *testing.T) {
+		var buf bytes.Buffer
+		rows, err := Export[product](ctx, newPagingExecutor(), &query.Query{}, &buf, &Options{Format: FormatJSONL, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 2, rows)
+		assert.Equal(t, `{"id":1,"name":"Cable, USB-C","price":9.5,"brand":{"name":"Anker"},"tags":["usb"]}`+"\n"+
+			`{"id":2,"name":"Charger","price":25,"brand":{"name":"Anker"}}`+"\n", buf.String())
+	})
+
+	t.Run("projected fields keep their order", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := Export[product](ctx, newPagingExecutor(), &query.Query{}, &buf, &Options{
+			Format: FormatJSONL,
+			Fields: []string{"name", "brand.name", "tags"},
+			Limit:  2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"Cable, USB-C","brand.name":"Anker","tags":["usb"]}`+"\n"+
+			`{"name":"Charger","brand.name":"Anker","tags":null}`+"\n", buf.String())
+	})
+}
+
+func TestExport_Progress(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reports each page", func(t *testing.T) {
+		var reports []Progress
+		var buf bytes.Buffer
+		_, err := Export[product](ctx, newPagingExecutor(), &query.Query{}, &buf, &Options{
+			Format: FormatJSONL,
+			OnProgress: func(p Progress) error {
+				reports = append(reports, p)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []Progress{{Rows: 2, Pages: 1, TotalItems: 3}, {Rows: 3, Pages: 2, TotalItems: 3}}, reports)
+	})
+
+	t.Run("stops on a callback error", func(t *testing.T) {
+		stop := errors.New("cancelled by user")
+		exec := newPagingExecutor()
+		var buf bytes.Buffer
+		rows, err := Export[product](ctx, exec, &query.Query{}, &buf, &Options{
+			Format:     FormatJSONL,
+			OnProgress: func(Progress) error { return stop },
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 2, rows)
+		assert.Equal(t, 1, exec.pages)
+	})
+}
+
+func TestExport_Errors(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+
+	_, err := Export[product](ctx, newPagingExecutor(), nil, &buf, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	_, err = Export[string](ctx, newPagingExecutor(), &query.Query{}, &buf, nil)
+	assert.ErrorIs(t, err, query.ErrInvalidDestination)
+
+	_, err = Export[product](ctx, newPagingExecutor(), &query.Query{}, &buf, &Options{Cursor: "bad"})
+	assert.ErrorIs(t, err, query.ErrInvalidCursor)
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("NDJSON")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSONL, f)
+	assert.Equal(t, "application/x-ndjson", ContentType(f))
+
+	f, err = ParseFormat("csv")
+	require.NoError(t, err)
+	assert.Equal(t, "csv", f.String())
+
+	_, err = ParseFormat("xlsx")
+	assert.Error(t, err)
+}
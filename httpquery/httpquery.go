@@ -0,0 +1,191 @@
+// Package httpquery integrates go-query with net/http.
+//
+// The middleware parses the query string parameters of a request into a
+// *query.Query and stores it in the request context:
+//
+//	GET /products?q=category+%3D+electronics&page_size=20&cursor=...
+//
+//	mux.Handle("/products", httpquery.Middleware(nil)(productsHandler))
+//
+//	func productsHandler(w http.ResponseWriter, r *http.Request) {
+//	    q, _ := httpquery.FromContext(r.Context())
+//	    var products []Product
+//	    result, err := exec.Execute(r.Context(), q, httpquery.CursorFromContext(r.Context()), &products)
+//	    if err != nil { ... }
+//	    httpquery.WriteJSON(w, r, http.StatusOK, products, result)
+//	}
+package httpquery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Config configures how requests are parsed
+type Config struct {
+	// QueryParam is the URL parameter holding the query string (default "q")
+	QueryParam string
+
+	// CursorParam is the URL parameter holding the pagination cursor (default "cursor")
+	CursorParam string
+
+	// PageSizeParam is the URL parameter overriding the page size (default "page_size")
+	PageSizeParam string
+
+	// PageParam is the URL parameter jumping to a page number (default "page").
+	// It applies only to requests without a cursor; see query.Query.Page
+	PageParam string
+
+	// MaxQueryLength rejects longer query strings. 0 means no limit
+	MaxQueryLength int
+
+	// Cache is used to parse queries when set
+	Cache *parser.ParserCache
+
+	// ErrorHandler writes the response for requests that cannot be parsed.
+	// Defaults to a 400 Bad Request with a JSON error body
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		QueryParam:    "q",
+		CursorParam:   "cursor",
+		PageSizeParam: "page_size",
+		PageParam:     "page",
+		ErrorHandler:  writeError,
+	}
+}
+
+// withDefaults fills unset fields from DefaultConfig
+func (c *Config) withDefaults() *Config {
+	defaults := DefaultConfig()
+	if c == nil {
+		return defaults
+	}
+	cfg := *c
+	if cfg.QueryParam == "" {
+		cfg.QueryParam = defaults.QueryParam
+	}
+	if cfg.CursorParam == "" {
+		cfg.CursorParam = defaults.CursorParam
+	}
+	if cfg.PageSizeParam == "" {
+		cfg.PageSizeParam = defaults.PageSizeParam
+	}
+	if cfg.PageParam == "" {
+		cfg.PageParam = defaults.PageParam
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = defaults.ErrorHandler
+	}
+	return &cfg
+}
+
+type contextKey int
+
+const (
+	queryKey contextKey = iota
+	cursorKey
+	cursorParamKey
+)
+
+// Middleware parses the request's query parameters and stores the resulting
+// query and cursor in the request context. Requests with an invalid query are
+// answered by cfg.ErrorHandler and not passed to next.
+// A nil cfg uses DefaultConfig.
+func Middleware(cfg *Config) func(http.Handler) http.Handler {
+	cfg = cfg.withDefaults()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q, cursorParam, err := Parse(r, cfg)
+			if err != nil {
+				cfg.ErrorHandler(w, r, err)
+				return
+			}
+			ctx := NewContext(r.Context(), q, cursorParam)
+			ctx = context.WithValue(ctx, cursorParamKey, cfg.CursorParam)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Parse extracts the query and cursor from the request's URL parameters.
+// Errors wrap query.ErrInvalidQuery. A nil cfg uses DefaultConfig.
+func Parse(r *http.Request, cfg *Config) (*query.Query, string, error) {
+	cfg = cfg.withDefaults()
+	params := r.URL.Query()
+
+	queryStr := params.Get(cfg.QueryParam)
+	if cfg.MaxQueryLength > 0 && len(queryStr) > cfg.MaxQueryLength {
+		return nil, "", fmt.Errorf("%w: query exceeds %d characters", query.ErrInvalidQuery, cfg.MaxQueryLength)
+	}
+
+	var (
+		q   *query.Query
+		err error
+	)
+	if cfg.Cache != nil {
+		q, err = cfg.Cache.Parse(queryStr)
+	} else {
+		var p *parser.Parser
+		p, err = parser.NewParser(queryStr)
+		if err == nil {
+			q, err = p.Parse()
+		}
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", query.ErrInvalidQuery, err)
+	}
+
+	// Copy so cached queries are never modified
+	parsed := *q
+	if raw := params.Get(cfg.PageSizeParam); raw != "" {
+		pageSize, err := strconv.Atoi(raw)
+		if err != nil || pageSize <= 0 {
+			return nil, "", fmt.Errorf("%w: invalid %s %q", query.ErrInvalidQuery, cfg.PageSizeParam, raw)
+		}
+		parsed.PageSize = pageSize
+	}
+	if raw := params.Get(cfg.PageParam); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page <= 0 {
+			return nil, "", fmt.Errorf("%w: invalid %s %q", query.ErrInvalidQuery, cfg.PageParam, raw)
+		}
+		parsed.Page = page
+	}
+
+	return &parsed, params.Get(cfg.CursorParam), nil
+}
+
+// NewContext returns a context carrying the query and cursor
+func NewContext(ctx context.Context, q *query.Query, cursor string) context.Context {
+	ctx = context.WithValue(ctx, queryKey, q)
+	return context.WithValue(ctx, cursorKey, cursor)
+}
+
+// FromContext returns the query stored by Middleware
+func FromContext(ctx context.Context) (*query.Query, bool) {
+	q, ok := ctx.Value(queryKey).(*query.Query)
+	return q, ok
+}
+
+// CursorFromContext returns the cursor stored by Middleware, or "" for the first page
+func CursorFromContext(ctx context.Context) string {
+	cursor, _ := ctx.Value(cursorKey).(string)
+	return cursor
+}
+
+// writeError is the default ErrorHandler
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
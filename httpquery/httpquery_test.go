@@ -0,0 +1,178 @@
+package httpquery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest(params url.Values) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/products?"+params.Encode(), nil)
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		got       *query.Query
+		gotCursor string
+	)
+	handler := Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+		gotCursor = CursorFromContext(r.Context())
+	}))
+
+	t.Run("parses query, cursor and page size", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(url.Values{
+			"q":         {"category = electronics sort_by = price"},
+			"cursor":    {"abc"},
+			"page_size": {"25"},
+		}))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, got)
+		assert.Equal(t, &query.ComparisonNode{Field: "category", Operator: query.OpEqual, Value: query.StringValue("electronics")}, got.Filter)
+		assert.Equal(t, "price", got.SortBy)
+		assert.Equal(t, 25, got.PageSize)
+		assert.Equal(t, "abc", gotCursor)
+	})
+
+	t.Run("empty query matches everything", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(url.Values{}))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Nil(t, got.Filter)
+		assert.Empty(t, gotCursor)
+	})
+
+	t.Run("invalid query rejected", func(t *testing.T) {
+		got = nil
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(url.Values{"q": {"name = "}}))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Nil(t, got, "handler must not be called")
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Contains(t, body["error"], "invalid query")
+	})
+
+	t.Run("invalid page size rejected", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(url.Values{"page_size": {"-1"}}))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("page jump", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(url.Values{"page": {"3"}}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 3, got.Page)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest(url.Values{"page": {"0"}}))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestMiddleware_Config(t *testing.T) {
+	cache := parser.NewParserCache(10)
+	var handledErr error
+	cfg := &Config{
+		QueryParam:     "filter",
+		CursorParam:    "after",
+		MaxQueryLength: 20,
+		Cache:          cache,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			handledErr = err
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		},
+	}
+
+	var nextURL string
+	handler := Middleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextURL = PageURL(r, "next-cursor")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(url.Values{"filter": {"stock > 0"}, "page_size": {"5"}}))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, cache.Size())
+	assert.Equal(t, "/products?after=next-cursor&filter=stock+%3E+0&page_size=5", nextURL)
+
+	// The page size override must not leak into the cached query
+	cached, err := cache.Parse("stock > 0")
+	require.NoError(t, err)
+	assert.NotEqual(t, 5, cached.PageSize)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest(url.Values{"filter": {"name = \"a very long query string\""}}))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.True(t, errors.Is(handledErr, query.ErrInvalidQuery))
+}
+
+func TestWriteHeaders(t *testing.T) {
+	r := newRequest(url.Values{"q": {"stock > 0"}, "cursor": {"current"}})
+	result := &query.Result{
+		NextPageCursor: "next",
+		PrevPageCursor: "prev",
+		TotalItems:     42,
+		ShowingFrom:    11,
+		ShowingTo:      20,
+		ItemsReturned:  10,
+	}
+
+	rec := httptest.NewRecorder()
+	WriteHeaders(rec, r, result)
+
+	assert.Equal(t, "42", rec.Header().Get(HeaderTotalCount))
+	assert.Equal(t, "10", rec.Header().Get(HeaderItemsReturned))
+	assert.Equal(t, "11", rec.Header().Get(HeaderShowingFrom))
+	assert.Equal(t, "20", rec.Header().Get(HeaderShowingTo))
+	assert.Equal(t, `</products?cursor=next&q=stock+%3E+0>; rel="next", </products?cursor=prev&q=stock+%3E+0>; rel="prev"`,
+		rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	WriteHeaders(rec, r, &query.Result{TotalItems: 0})
+	assert.Empty(t, rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	WriteHeaders(rec, r, &query.Result{TotalItems: query.TotalUnknown, ItemsReturned: 10})
+	assert.Empty(t, rec.Header().Values(HeaderTotalCount))
+	assert.Equal(t, "10", rec.Header().Get(HeaderItemsReturned))
+}
+
+func TestWriteJSON(t *testing.T) {
+	r := newRequest(url.Values{"q": {"stock > 0"}})
+	result := &query.Result{NextPageCursor: "next", TotalItems: 3, ShowingFrom: 1, ShowingTo: 2, ItemsReturned: 2}
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, WriteJSON(rec, r, http.StatusOK, []string{"a", "b"}, result))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "3", rec.Header().Get(HeaderTotalCount))
+
+	var envelope struct {
+		Data []string `json:"data"`
+		Meta Meta     `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &envelope))
+	assert.Equal(t, []string{"a", "b"}, envelope.Data)
+	assert.Equal(t, Meta{
+		TotalItems:     3,
+		ShowingFrom:    1,
+		ShowingTo:      2,
+		ItemsReturned:  2,
+		NextPageCursor: "next",
+		NextURL:        "/products?cursor=next&q=stock+%3E+0",
+	}, envelope.Meta)
+}
@@ -0,0 +1,105 @@
+package httpquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Response headers written by WriteHeaders
+const (
+	HeaderTotalCount    = "X-Total-Count"
+	HeaderItemsReturned = "X-Items-Returned"
+	HeaderShowingFrom   = "X-Showing-From"
+	HeaderShowingTo     = "X-Showing-To"
+)
+
+// Meta is the pagination metadata of a JSON envelope
+type Meta struct {
+	TotalItems     int64  `json:"total_items"`
+	ShowingFrom    int    `json:"showing_from"`
+	ShowingTo      int    `json:"showing_to"`
+	ItemsReturned  int    `json:"items_returned"`
+	NextPageCursor string `json:"next_page_cursor,omitempty"`
+	PrevPageCursor string `json:"prev_page_cursor,omitempty"`
+	NextURL        string `json:"next_url,omitempty"`
+	PrevURL        string `json:"prev_url,omitempty"`
+}
+
+// Envelope is the JSON body written by WriteJSON
+type Envelope struct {
+	Data interface{} `json:"data"`
+	Meta Meta        `json:"meta"`
+}
+
+// NewMeta builds envelope metadata from a result. Next/previous URLs are the
+// request URL with the cursor parameter replaced.
+func NewMeta(r *http.Request, result *query.Result) Meta {
+	return Meta{
+		TotalItems:     result.TotalItems,
+		ShowingFrom:    result.ShowingFrom,
+		ShowingTo:      result.ShowingTo,
+		ItemsReturned:  result.ItemsReturned,
+		NextPageCursor: result.NextPageCursor,
+		PrevPageCursor: result.PrevPageCursor,
+		NextURL:        PageURL(r, result.NextPageCursor),
+		PrevURL:        PageURL(r, result.PrevPageCursor),
+	}
+}
+
+// PageURL returns the request URL (path and query) pointing at the given cursor,
+// or "" when cursor is empty. The cursor parameter configured on Middleware is
+// replaced; outside the middleware the default "cursor" parameter is used.
+func PageURL(r *http.Request, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	cursorParam, ok := r.Context().Value(cursorParamKey).(string)
+	if !ok {
+		cursorParam = DefaultConfig().CursorParam
+	}
+	u := *r.URL
+	params := u.Query()
+	params.Set(cursorParam, cursor)
+	u.RawQuery = params.Encode()
+	return u.RequestURI()
+}
+
+// WriteHeaders writes result metadata as response headers: totals in
+// X-Total-Count, X-Items-Returned, X-Showing-From and X-Showing-To, and
+// next/previous pages as an RFC 8288 Link header. X-Total-Count is left out
+// when the total is query.TotalUnknown.
+// It must be called before the response body is written.
+func WriteHeaders(w http.ResponseWriter, r *http.Request, result *query.Result) {
+	h := w.Header()
+	if result.TotalItems != query.TotalUnknown {
+		h.Set(HeaderTotalCount, strconv.FormatInt(result.TotalItems, 10))
+	}
+	h.Set(HeaderItemsReturned, strconv.Itoa(result.ItemsReturned))
+	h.Set(HeaderShowingFrom, strconv.Itoa(result.ShowingFrom))
+	h.Set(HeaderShowingTo, strconv.Itoa(result.ShowingTo))
+
+	var links []string
+	if next := PageURL(r, result.NextPageCursor); next != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, next))
+	}
+	if prev := PageURL(r, result.PrevPageCursor); prev != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, prev))
+	}
+	if len(links) > 0 {
+		h.Set("Link", strings.Join(links, ", "))
+	}
+}
+
+// WriteJSON writes data and result metadata as a JSON Envelope.
+// Headers are written as well, so clients can use either form.
+func WriteJSON(w http.ResponseWriter, r *http.Request, status int, data interface{}, result *query.Result) error {
+	WriteHeaders(w, r, result)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(Envelope{Data: data, Meta: NewMeta(r, result)})
+}
@@ -0,0 +1,154 @@
+package httpquery
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// ContentTypeNDJSON is the content type written by StreamNDJSON
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// writerPool reuses buffered writers across responses
+var writerPool = sync.Pool{
+	New: func() interface{} { return bufio.NewWriter(nil) },
+}
+
+// getWriter returns a pooled buffered writer for w; release it with putWriter
+func getWriter(w io.Writer) *bufio.Writer {
+	bw := writerPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw
+}
+
+func putWriter(bw *bufio.Writer) {
+	bw.Reset(nil)
+	writerPool.Put(bw)
+}
+
+// EncodeArray encodes items, a slice or array, to w as a JSON array.
+// Elements are encoded one at a time through a fixed-size buffer, so memory
+// use does not grow with the page size the way marshaling the whole page does.
+func EncodeArray(w io.Writer, items interface{}) error {
+	bw := getWriter(w)
+	defer putWriter(bw)
+	if err := bw.WriteByte('['); err != nil {
+		return err
+	}
+	if err := encodeElements(bw, items, ","); err != nil {
+		return err
+	}
+	if err := bw.WriteByte(']'); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// EncodeNDJSON encodes items, a slice or array, to w as newline-delimited JSON,
+// one element per line
+func EncodeNDJSON(w io.Writer, items interface{}) error {
+	bw := getWriter(w)
+	defer putWriter(bw)
+	if err := encodeElements(bw, items, ""); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// StreamJSON writes the same Envelope as WriteJSON, encoding the data
+// elements one at a time instead of marshaling the whole page at once.
+// Headers and status are written first, so an encoding error can no longer
+// change the status code; it is returned to the caller.
+func StreamJSON(w http.ResponseWriter, r *http.Request, status int, items interface{}, result *query.Result) error {
+	if _, err := sliceValue(items); err != nil {
+		return err
+	}
+
+	WriteHeaders(w, r, result)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	bw := getWriter(w)
+	defer putWriter(bw)
+	if _, err := bw.WriteString(`{"data":[`); err != nil {
+		return err
+	}
+	if err := encodeElements(bw, items, ","); err != nil {
+		return err
+	}
+	if _, err := bw.WriteString(`],"meta":`); err != nil {
+		return err
+	}
+	if err := json.NewEncoder(bw).Encode(NewMeta(r, result)); err != nil {
+		return err
+	}
+	if _, err := bw.WriteString("}\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// StreamNDJSON writes items as newline-delimited JSON. Result metadata is
+// only available in the headers written by WriteHeaders.
+func StreamNDJSON(w http.ResponseWriter, r *http.Request, status int, items interface{}, result *query.Result) error {
+	if _, err := sliceValue(items); err != nil {
+		return err
+	}
+
+	WriteHeaders(w, r, result)
+	w.Header().Set("Content-Type", ContentTypeNDJSON)
+	w.WriteHeader(status)
+	return EncodeNDJSON(w, items)
+}
+
+// encodeElements encodes each element of items, writing sep between elements.
+// json.Encoder terminates every element with a newline, which is valid
+// whitespace inside a JSON array and the record separator of NDJSON.
+func encodeElements(bw *bufio.Writer, items interface{}, sep string) error {
+	v, err := sliceValue(items)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(bw)
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 && sep != "" {
+			if _, err := bw.WriteString(sep); err != nil {
+				return err
+			}
+		}
+		elem := v.Index(i)
+		if elem.CanAddr() {
+			// A pointer avoids copying the element into an interface and,
+			// like json.Marshal of a slice, honors pointer-receiver marshalers
+			elem = elem.Addr()
+		}
+		if err := enc.Encode(elem.Interface()); err != nil {
+			return fmt.Errorf("encoding item %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// sliceValue returns the slice or array behind items, dereferencing pointers.
+// nil is treated as an empty slice.
+func sliceValue(items interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(items)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v, nil
+	case reflect.Invalid, reflect.Pointer:
+		return reflect.ValueOf([]struct{}{}), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("items must be a slice or array, got %T", items)
+	}
+}
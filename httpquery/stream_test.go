@@ -0,0 +1,113 @@
+package httpquery
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type product struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestEncodeArray(t *testing.T) {
+	items := []product{{1, "Mouse"}, {2, "Keyboard"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeArray(&buf, items))
+
+	var decoded []product
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, items, decoded)
+
+	for _, empty := range []interface{}{nil, []product{}, &[]product{}, (*[]product)(nil)} {
+		buf.Reset()
+		require.NoError(t, EncodeArray(&buf, empty))
+		assert.Equal(t, "[]", buf.String())
+	}
+
+	assert.Error(t, EncodeArray(&buf, product{}))
+	assert.Error(t, EncodeArray(&buf, []float64{math.NaN()}))
+}
+
+func TestEncodeNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeNDJSON(&buf, [2]product{{1, "Mouse"}, {2, "Keyboard"}}))
+	assert.Equal(t, "{\"id\":1,\"name\":\"Mouse\"}\n{\"id\":2,\"name\":\"Keyboard\"}\n", buf.String())
+}
+
+func TestStreamJSON(t *testing.T) {
+	r := newRequest(url.Values{"q": {"stock > 0"}})
+	result := &query.Result{NextPageCursor: "next", TotalItems: 3, ShowingFrom: 1, ShowingTo: 2, ItemsReturned: 2}
+	items := []product{{1, "Mouse"}, {2, "Keyboard"}}
+
+	streamed := httptest.NewRecorder()
+	require.NoError(t, StreamJSON(streamed, r, http.StatusOK, items, result))
+	buffered := httptest.NewRecorder()
+	require.NoError(t, WriteJSON(buffered, r, http.StatusOK, items, result))
+
+	assert.Equal(t, http.StatusOK, streamed.Code)
+	assert.Equal(t, buffered.Header(), streamed.Header())
+	assert.JSONEq(t, buffered.Body.String(), streamed.Body.String())
+
+	rec := httptest.NewRecorder()
+	assert.Error(t, StreamJSON(rec, r, http.StatusOK, "not a slice", result))
+	assert.Empty(t, rec.Header(), "nothing is written for invalid items")
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	r := newRequest(url.Values{})
+	rec := httptest.NewRecorder()
+	require.NoError(t, StreamNDJSON(rec, r, http.StatusOK, []product{{1, "Mouse"}, {2, "Keyboard"}}, &query.Result{TotalItems: 2}))
+
+	assert.Equal(t, ContentTypeNDJSON, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "2", rec.Header().Get(HeaderTotalCount))
+
+	var lines []product
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var p product
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &p))
+		lines = append(lines, p)
+	}
+	assert.Equal(t, []product{{1, "Mouse"}, {2, "Keyboard"}}, lines)
+}
+
+// discardResponseWriter measures encoding without buffering the response body
+type discardResponseWriter struct{ header http.Header }
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func BenchmarkStreamJSON(b *testing.B) {
+	items := make([]product, 1000)
+	for i := range items {
+		items[i] = product{ID: i, Name: "product"}
+	}
+	r := newRequest(url.Values{})
+	result := &query.Result{TotalItems: 1000}
+
+	b.Run("WriteJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = WriteJSON(&discardResponseWriter{header: http.Header{}}, r, http.StatusOK, items, result)
+		}
+	})
+	b.Run("StreamJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = StreamJSON(&discardResponseWriter{header: http.Header{}}, r, http.StatusOK, items, result)
+		}
+	})
+}
@@ -3,8 +3,12 @@ package cursor
 import (
 	"encoding/base64"
 	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
+	"github.com/hadi77ir/go-query/query"
 )
 
 // CursorData contains the data encoded in a cursor
@@ -26,6 +30,34 @@ type CursorData struct {
 
 	// ItemsReturned is the cumulative number of items returned so far (for limit enforcement)
 	ItemsReturned int `cbor:"6,keyasint,omitempty"`
+
+	// QueryHash is a hash of the filter and sort specification the cursor was generated for
+	// Zero means the cursor is not bound to a query (cursors generated by older versions)
+	QueryHash uint64 `cbor:"7,keyasint,omitempty"`
+
+	// ResumeToken is the backend token that resumes a change stream after
+	// the last event delivered (MongoDB change streams)
+	ResumeToken []byte `cbor:"8,keyasint,omitempty"`
+
+	// Children holds the position of each child of an executor that merges
+	// several executors (federated executor), in the order of the children
+	Children []ChildCursor `cbor:"9,keyasint,omitempty"`
+}
+
+// ChildCursor is the position of one child executor in a merged cursor
+type ChildCursor struct {
+	// Cursor is the child's cursor for its next items, empty for its first page
+	Cursor string `cbor:"1,keyasint,omitempty"`
+
+	// Done is true when every item of the child has been returned
+	Done bool `cbor:"2,keyasint,omitempty"`
+
+	// TotalItems is the child's total as of the last page it returned
+	TotalItems int64 `cbor:"3,keyasint,omitempty"`
+
+	// Name identifies the child when children are chosen per query, such as
+	// the shards of a sharded executor
+	Name string `cbor:"4,keyasint,omitempty"`
 }
 
 // Encode encodes cursor data into a base64 string using CBOR
@@ -60,3 +92,99 @@ func Decode(cursor string) (*CursorData, error) {
 
 	return &data, nil
 }
+
+// Jump returns the cursor data of the page starting after offset items, for
+// queries that jump to a page (see query.ExecutorOptions.PageOffset) instead
+// of passing a cursor. It has no LastID, so executors skip to the page by
+// offset even under keyset ordering. It returns nil for offset 0
+func Jump(offset int) *CursorData {
+	if offset <= 0 {
+		return nil
+	}
+	return &CursorData{Offset: offset, Direction: "next", ItemsReturned: offset}
+}
+
+// QueryHash computes a hash of the query's filter and sort specification
+// Cursors carry this hash so they cannot be replayed against a different query.
+// Relative times are hashed as written (see query.Query.StableFilter)
+func QueryHash(q *query.Query) uint64 {
+	var sb strings.Builder
+	if q != nil {
+		writeNode(&sb, q.StableFilter())
+		fmt.Fprintf(&sb, "|sort:%s:%s", q.SortBy, q.SortOrder)
+		if q.PreserveInOrder {
+			sb.WriteString("|in_order")
+		}
+		if q.SortOrder == query.SortOrderRandom && q.RandomSeed != 0 {
+			fmt.Fprintf(&sb, "|seed:%d", q.RandomSeed)
+		}
+		if q.IncludeDeleted {
+			sb.WriteString("|include_deleted")
+		}
+		if q.DistinctOn != "" {
+			fmt.Fprintf(&sb, "|distinct_on:%q", q.DistinctOn)
+		} else if q.Distinct {
+			sb.WriteString("|distinct")
+		}
+	}
+	h := fnv.New64a()
+	h.Write([]byte(sb.String()))
+	return h.Sum64()
+}
+
+// CheckQuery returns query.ErrCursorQueryMismatch if the cursor was generated for a different query
+// Cursors without a query hash are accepted for backwards compatibility
+func (d *CursorData) CheckQuery(q *query.Query) error {
+	if d == nil || d.QueryHash == 0 {
+		return nil
+	}
+	if d.QueryHash != QueryHash(q) {
+		return query.ErrCursorQueryMismatch
+	}
+	return nil
+}
+
+// writeNode writes a deterministic representation of a filter node
+func writeNode(sb *strings.Builder, node query.Node) {
+	switch n := node.(type) {
+	case nil:
+		sb.WriteString("nil")
+	case *query.BinaryOpNode:
+		sb.WriteString("(")
+		writeNode(sb, n.Left)
+		fmt.Fprintf(sb, " %s ", n.Operator)
+		writeNode(sb, n.Right)
+		sb.WriteString(")")
+	case *query.ComparisonNode:
+		if n.Modifier != query.ArrayModifierNone {
+			fmt.Fprintf(sb, "%q %s %s ", n.Field, n.Modifier, n.Operator)
+		} else {
+			fmt.Fprintf(sb, "%q %s ", n.Field, n.Operator)
+		}
+		writeValue(sb, n.Value)
+		if n.Phrase {
+			sb.WriteString(" phrase")
+		}
+	default:
+		fmt.Fprintf(sb, "%T", node)
+	}
+}
+
+// writeValue writes a value together with its type so that e.g. "1" and 1 hash differently
+func writeValue(sb *strings.Builder, value interface{}) {
+	switch v := value.(type) {
+	case query.ArrayValue:
+		sb.WriteString("[")
+		for i, elem := range v {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			writeValue(sb, elem)
+		}
+		sb.WriteString("]")
+	case query.DateTimeValue:
+		fmt.Fprintf(sb, "%T:%s", v, time.Time(v).UTC().Format(time.RFC3339Nano))
+	default:
+		fmt.Fprintf(sb, "%T:%#v", v, v)
+	}
+}
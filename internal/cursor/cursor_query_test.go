@@ -0,0 +1,129 @@
+package cursor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryHash(t *testing.T) {
+	base := &query.Query{
+		Filter: &query.ComparisonNode{Field: "status", Operator: query.OpEqual, Value: query.StringValue("active")},
+		SortBy: "name",
+	}
+
+	t.Run("deterministic", func(t *testing.T) {
+		same := &query.Query{
+			Filter:   &query.ComparisonNode{Field: "status", Operator: query.OpEqual, Value: query.StringValue("active")},
+			SortBy:   "name",
+			PageSize: 50, // page size does not affect the binding
+		}
+		assert.Equal(t, QueryHash(base), QueryHash(same))
+	})
+
+	t.Run("different filter", func(t *testing.T) {
+		other := &query.Query{
+			Filter: &query.ComparisonNode{Field: "status", Operator: query.OpEqual, Value: query.StringValue("inactive")},
+			SortBy: "name",
+		}
+		assert.NotEqual(t, QueryHash(base), QueryHash(other))
+	})
+
+	t.Run("different value type", func(t *testing.T) {
+		a := &query.Query{Filter: &query.ComparisonNode{Field: "n", Operator: query.OpEqual, Value: query.StringValue("1")}}
+		b := &query.Query{Filter: &query.ComparisonNode{Field: "n", Operator: query.OpEqual, Value: query.IntValue(1)}}
+		assert.NotEqual(t, QueryHash(a), QueryHash(b))
+	})
+
+	t.Run("different sort", func(t *testing.T) {
+		desc := *base
+		desc.SortOrder = query.SortOrderDesc
+		assert.NotEqual(t, QueryHash(base), QueryHash(&desc))
+	})
+
+	t.Run("preserved IN order", func(t *testing.T) {
+		inOrder := *base
+		inOrder.PreserveInOrder = true
+		assert.NotEqual(t, QueryHash(base), QueryHash(&inOrder))
+	})
+
+	t.Run("soft-deleted rows", func(t *testing.T) {
+		deleted := *base
+		deleted.IncludeDeleted = true
+		assert.NotEqual(t, QueryHash(base), QueryHash(&deleted))
+	})
+
+	t.Run("arrays and dates", func(t *testing.T) {
+		ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		a := &query.Query{Filter: &query.BinaryOpNode{
+			Operator: query.BinaryOpAnd,
+			Left:     &query.ComparisonNode{Field: "id", Operator: query.OpIn, Value: query.ArrayValue{query.IntValue(1), query.IntValue(2)}},
+			Right:    &query.ComparisonNode{Field: "created", Operator: query.OpGreaterThan, Value: query.DateTimeValue(ts)},
+		}}
+		b := &query.Query{Filter: &query.BinaryOpNode{
+			Operator: query.BinaryOpAnd,
+			Left:     &query.ComparisonNode{Field: "id", Operator: query.OpIn, Value: query.ArrayValue{query.IntValue(1), query.IntValue(3)}},
+			Right:    &query.ComparisonNode{Field: "created", Operator: query.OpGreaterThan, Value: query.DateTimeValue(ts)},
+		}}
+		assert.NotEqual(t, QueryHash(a), QueryHash(b))
+	})
+
+	t.Run("phrase", func(t *testing.T) {
+		term := &query.ComparisonNode{Field: "name", Operator: query.OpContains, Value: query.StringValue("usb cable")}
+		phrase := *term
+		phrase.Phrase = true
+		assert.NotEqual(t, QueryHash(&query.Query{Filter: term}), QueryHash(&query.Query{Filter: &phrase}))
+	})
+
+	t.Run("random seed", func(t *testing.T) {
+		a := &query.Query{SortOrder: query.SortOrderRandom, RandomSeed: 1}
+		b := &query.Query{SortOrder: query.SortOrderRandom, RandomSeed: 2}
+		assert.NotEqual(t, QueryHash(a), QueryHash(b))
+		assert.Equal(t, QueryHash(&query.Query{SortOrder: query.SortOrderRandom}), QueryHash(&query.Query{SortOrder: query.SortOrderRandom}))
+	})
+}
+
+func TestCursorData_CheckQuery(t *testing.T) {
+	q := &query.Query{Filter: &query.ComparisonNode{Field: "a", Operator: query.OpEqual, Value: query.IntValue(1)}}
+	other := &query.Query{Filter: &query.ComparisonNode{Field: "a", Operator: query.OpEqual, Value: query.IntValue(2)}}
+
+	encoded, err := Encode(&CursorData{Direction: "next", Offset: 10, QueryHash: QueryHash(q)})
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+
+	assert.NoError(t, decoded.CheckQuery(q))
+	assert.True(t, errors.Is(decoded.CheckQuery(other), query.ErrCursorQueryMismatch))
+
+	// Unbound cursors (no hash) are accepted
+	unbound := &CursorData{Direction: "next"}
+	assert.NoError(t, unbound.CheckQuery(other))
+
+	// Nil cursor is accepted
+	var none *CursorData
+	assert.NoError(t, none.CheckQuery(q))
+}
+
+func TestQueryHash_RelativeTimes(t *testing.T) {
+	q := &query.Query{Filter: query.Gt("created_at", query.RelativeTimeValue("now-7d"))}
+	opts := query.DefaultExecutorOptions()
+
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	opts.Clock = func() time.Time { return now }
+	first, err := opts.ResolvePlaceholders(context.Background(), q)
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	second, err := opts.ResolvePlaceholders(context.Background(), q)
+	require.NoError(t, err)
+
+	// Pages resolved a minute apart share a hash
+	assert.NotEqual(t, first.Filter, second.Filter)
+	assert.Equal(t, QueryHash(first), QueryHash(second))
+	assert.Equal(t, QueryHash(q), QueryHash(first))
+	assert.NotEqual(t, QueryHash(q), QueryHash(&query.Query{Filter: query.Gt("created_at", query.RelativeTimeValue("now-1d"))}))
+}
@@ -51,6 +51,13 @@ func TestCursor_EncodeAndDecode(t *testing.T) {
 				Direction: "prev",
 			},
 		},
+		{
+			name: "change stream cursor",
+			data: &CursorData{
+				Direction:   "next",
+				ResumeToken: []byte{0x1a, 0x00, 0x00, 0x00, 0x02, '_', 'd', 'a', 't', 'a'},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -70,6 +77,7 @@ func TestCursor_EncodeAndDecode(t *testing.T) {
 			assert.Equal(t, tt.data.Direction, decoded.Direction)
 			assert.Equal(t, tt.data.Offset, decoded.Offset)
 			assert.Equal(t, tt.data.RandomSeed, decoded.RandomSeed)
+			assert.Equal(t, tt.data.ResumeToken, decoded.ResumeToken)
 		})
 	}
 }
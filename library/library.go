@@ -0,0 +1,455 @@
+// Package library loads named, parameterized queries from .gq files so teams
+// can version-control a set of standard filters and reference them by name.
+//
+// A .gq file contains directives and query bodies:
+//
+//	# Shared filters for the product catalog
+//	@include "common.gq"
+//
+//	# Electronics that are in stock
+//	@query in_stock_electronics
+//	category = electronics
+//	AND stock > 0
+//
+//	# Products cheaper than a given price in a category
+//	@query cheap(category, max_price)
+//	category = $category
+//	AND price < $max_price
+//	sort_by = price
+//
+// A query body runs from its @query line to the next directive or the end of the
+// file and may span multiple lines. Comment lines directly above @query become
+// the query's documentation. Parameters ($name, or :name as in query.Query.Bind)
+// may only be used as values and are bound with typed Go values, so they cannot
+// change the shape of the query.
+//
+// Outside query bodies, only # comment lines and blank lines are allowed.
+// @include paths are relative to the including file. Each file is loaded once;
+// include cycles and duplicate query names are errors.
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// NamedQuery is a query defined in a library file
+type NamedQuery struct {
+	// Name identifies the query in the library
+	Name string
+
+	// Params are the declared parameter names, in declaration order
+	Params []string
+
+	// Doc is the comment block directly above the @query directive
+	Doc string
+
+	// Source is the query body as written in the file
+	Source string
+
+	// File is the file the query was loaded from (empty for Parse)
+	File string
+
+	template *query.Query
+}
+
+// Library is a set of named queries
+type Library struct {
+	queries map[string]*NamedQuery
+}
+
+// Parse parses library source that does not use @include
+func Parse(src string) (*Library, error) {
+	lib := &Library{queries: make(map[string]*NamedQuery)}
+	if err := lib.parse("", src, nil); err != nil {
+		return nil, err
+	}
+	return lib, nil
+}
+
+// LoadFile loads a library file and everything it includes from disk
+func LoadFile(filename string) (*Library, error) {
+	return LoadFS(os.DirFS(filepath.Dir(filename)), filepath.Base(filename))
+}
+
+// LoadFS loads a library file and everything it includes from a file system
+func LoadFS(fsys fs.FS, name string) (*Library, error) {
+	lib := &Library{queries: make(map[string]*NamedQuery)}
+	l := &loader{fsys: fsys, loaded: make(map[string]bool), active: make(map[string]bool)}
+	if err := l.load(lib, path.Clean(name)); err != nil {
+		return nil, err
+	}
+	return lib, nil
+}
+
+// Names returns the names of all queries in the library, sorted
+func (l *Library) Names() []string {
+	names := make([]string, 0, len(l.queries))
+	for name := range l.queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named query
+func (l *Library) Get(name string) (*NamedQuery, bool) {
+	nq, ok := l.queries[name]
+	return nq, ok
+}
+
+// Query binds params to the named query and returns a query ready to execute
+func (l *Library) Query(name string, params map[string]interface{}) (*query.Query, error) {
+	nq, ok := l.queries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", query.ErrNamedQueryNotFound, name)
+	}
+	return nq.Bind(params)
+}
+
+// Bind substitutes params into the query and returns a new query.
+// Every declared parameter must be given and unknown parameters are rejected.
+func (nq *NamedQuery) Bind(params map[string]interface{}) (*query.Query, error) {
+	declared := make(map[string]bool, len(nq.Params))
+	for _, name := range nq.Params {
+		declared[name] = true
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("%w: query %s: missing parameter $%s", query.ErrInvalidQuery, nq.Name, name)
+		}
+	}
+
+	// Declared parameters the body does not use are accepted but not bound
+	used := make(map[string]interface{}, len(params))
+	for _, name := range nq.template.Parameters() {
+		used[name] = params[name]
+	}
+	for name := range params {
+		if !declared[name] {
+			return nil, fmt.Errorf("%w: query %s: unknown parameter $%s", query.ErrInvalidQuery, nq.Name, name)
+		}
+	}
+
+	q, err := nq.template.Bind(used)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", nq.Name, err)
+	}
+	return q, nil
+}
+
+// loader resolves @include directives against a file system
+type loader struct {
+	fsys   fs.FS
+	loaded map[string]bool
+	active map[string]bool
+}
+
+// load parses a file once, following its includes
+func (l *loader) load(lib *Library, name string) error {
+	if l.active[name] {
+		return fmt.Errorf("%w: include cycle at %s", query.ErrInvalidQuery, name)
+	}
+	if l.loaded[name] {
+		return nil
+	}
+
+	data, err := fs.ReadFile(l.fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read query library: %w", err)
+	}
+
+	l.active[name] = true
+	defer delete(l.active, name)
+
+	include := func(target string) error {
+		return l.load(lib, path.Join(path.Dir(name), target))
+	}
+	if err := lib.parse(name, string(data), include); err != nil {
+		return err
+	}
+	l.loaded[name] = true
+	return nil
+}
+
+// parse reads directives and query bodies from src
+func (l *Library) parse(file, src string, include func(string) error) error {
+	var (
+		current *NamedQuery
+		body    []string
+		doc     []string
+		start   int
+	)
+
+	finish := func() error {
+		if current == nil {
+			return nil
+		}
+		current.Source = strings.TrimSpace(strings.Join(body, "\n"))
+		if err := l.add(current); err != nil {
+			return locate(file, start, err)
+		}
+		current, body = nil, nil
+		return nil
+	}
+
+	for i, line := range strings.Split(src, "\n") {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		if !strings.HasPrefix(trimmed, "@") {
+			if current != nil {
+				body = append(body, line)
+			} else if strings.HasPrefix(trimmed, "#") {
+				doc = append(doc, strings.TrimSpace(strings.TrimPrefix(trimmed, "#")))
+			} else if trimmed == "" {
+				doc = nil
+			} else {
+				return locate(file, lineNo, errors.New("query body outside @query"))
+			}
+			continue
+		}
+
+		// Comment lines directly above a directive document it, not the previous body
+		if current != nil {
+			doc = nil
+			for len(body) > 0 && strings.HasPrefix(strings.TrimSpace(body[len(body)-1]), "#") {
+				last := strings.TrimSpace(body[len(body)-1])
+				doc = append([]string{strings.TrimSpace(strings.TrimPrefix(last, "#"))}, doc...)
+				body = body[:len(body)-1]
+			}
+		}
+		if err := finish(); err != nil {
+			return err
+		}
+
+		directive, arg, _ := strings.Cut(trimmed, " ")
+		arg = strings.TrimSpace(arg)
+		switch directive {
+		case "@include":
+			target := strings.Trim(arg, `"'`)
+			if target == "" {
+				return locate(file, lineNo, errors.New("@include requires a file name"))
+			}
+			if include == nil {
+				return locate(file, lineNo, errors.New("@include is not supported without a file system; use LoadFS or LoadFile"))
+			}
+			if err := include(target); err != nil {
+				return err
+			}
+		case "@query":
+			name, params, err := parseHeader(arg)
+			if err != nil {
+				return locate(file, lineNo, err)
+			}
+			current = &NamedQuery{Name: name, Params: params, Doc: strings.Join(doc, "\n"), File: file}
+			start = lineNo
+		default:
+			return locate(file, lineNo, fmt.Errorf("unknown directive %s", directive))
+		}
+		doc = nil
+	}
+	return finish()
+}
+
+// add compiles a query body and registers it
+func (l *Library) add(nq *NamedQuery) error {
+	if _, exists := l.queries[nq.Name]; exists {
+		return fmt.Errorf("duplicate query %s", nq.Name)
+	}
+
+	src, err := replaceParams(nq.Source, nq.Params)
+	if err != nil {
+		return fmt.Errorf("query %s: %w", nq.Name, err)
+	}
+	if err := checkParamPlacement(src); err != nil {
+		return fmt.Errorf("query %s: %w", nq.Name, err)
+	}
+	p, err := parser.NewParser(src)
+	if err != nil {
+		return fmt.Errorf("query %s: %w", nq.Name, err)
+	}
+	q, err := p.Parse()
+	if err != nil {
+		return fmt.Errorf("query %s: %w", nq.Name, err)
+	}
+	if q.Filter == nil {
+		return fmt.Errorf("query %s has no filter", nq.Name)
+	}
+	declared := make(map[string]bool, len(nq.Params))
+	for _, name := range nq.Params {
+		declared[name] = true
+	}
+	for _, name := range q.Parameters() {
+		if !declared[name] {
+			return fmt.Errorf("query %s: undeclared parameter :%s", nq.Name, name)
+		}
+	}
+
+	nq.template = q
+	l.queries[nq.Name] = nq
+	return nil
+}
+
+// locate wraps an error with its file and line
+func locate(file string, line int, err error) error {
+	if file == "" {
+		return fmt.Errorf("%w: line %d: %v", query.ErrInvalidQuery, line, err)
+	}
+	return fmt.Errorf("%w: %s:%d: %v", query.ErrInvalidQuery, file, line, err)
+}
+
+// parseHeader parses "name" or "name(param1, param2)"
+func parseHeader(header string) (string, []string, error) {
+	name, rest, hasParams := strings.Cut(header, "(")
+	name = strings.TrimSpace(name)
+	if !isIdentifier(name) {
+		return "", nil, fmt.Errorf("invalid query name %q", name)
+	}
+	if !hasParams {
+		return name, nil, nil
+	}
+
+	rest = strings.TrimSpace(rest)
+	if !strings.HasSuffix(rest, ")") {
+		return "", nil, fmt.Errorf("unterminated parameter list for query %s", name)
+	}
+	rest = strings.TrimSpace(strings.TrimSuffix(rest, ")"))
+	if rest == "" {
+		return name, nil, nil
+	}
+
+	var params []string
+	seen := make(map[string]bool)
+	for _, param := range strings.Split(rest, ",") {
+		param = strings.TrimPrefix(strings.TrimSpace(param), "$")
+		if !isIdentifier(param) {
+			return "", nil, fmt.Errorf("invalid parameter name %q in query %s", param, name)
+		}
+		if seen[param] {
+			return "", nil, fmt.Errorf("duplicate parameter %s in query %s", param, name)
+		}
+		seen[param] = true
+		params = append(params, param)
+	}
+	return name, params, nil
+}
+
+// isIdentifier reports whether s is a valid query or parameter name
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceParams rewrites $name placeholders outside quoted strings and comments
+// into :name parameters, which the parser reads as query.ParameterValue
+func replaceParams(src string, params []string) (string, error) {
+	declared := make(map[string]bool, len(params))
+	for _, p := range params {
+		declared[p] = true
+	}
+
+	var sb strings.Builder
+	runes := []rune(src)
+	var quote rune
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if quote != 0 {
+			if r == '\\' && i+1 < len(runes) && runes[i+1] == quote {
+				sb.WriteRune(r)
+				i++
+				sb.WriteRune(runes[i])
+				continue
+			}
+			if r == quote {
+				quote = 0
+			}
+			sb.WriteRune(r)
+			continue
+		}
+		if r == '"' || r == '\'' {
+			quote = r
+			sb.WriteRune(r)
+			continue
+		}
+		if r == '#' || (r == '/' && i+1 < len(runes) && runes[i+1] == '*') {
+			// Copy comments verbatim; the lexer skips them
+			end := "\n"
+			if r == '/' {
+				end = "*/"
+			}
+			rest := string(runes[i:])
+			n := strings.Index(rest, end)
+			if n < 0 {
+				n = len(rest)
+			} else {
+				n += len(end)
+			}
+			comment := []rune(rest[:n])
+			sb.WriteString(string(comment))
+			i += len(comment) - 1
+			continue
+		}
+		if r != '$' {
+			sb.WriteRune(r)
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+			j++
+		}
+		name := string(runes[i+1 : j])
+		if !declared[name] {
+			return "", fmt.Errorf("undeclared parameter $%s", name)
+		}
+		sb.WriteString(":" + name)
+		i = j - 1
+	}
+	return sb.String(), nil
+}
+
+// checkParamPlacement rejects parameters used as field names. A parameter
+// stands for a value only after an operator, IN, "[" or ","
+func checkParamPlacement(src string) error {
+	tokens, err := parser.NewLexer(src).AllTokens()
+	if err != nil {
+		// Reported by the parser
+		return nil
+	}
+	for i, tok := range tokens {
+		if tok.Type != parser.TokenParameter {
+			continue
+		}
+		if i == 0 || !precedesValue(tokens[i-1].Type) {
+			return errors.New("parameters can only be used as values")
+		}
+	}
+	return nil
+}
+
+// precedesValue reports whether a token of type typ is followed by a value
+func precedesValue(typ parser.TokenType) bool {
+	switch typ {
+	case parser.TokenOperator, parser.TokenIn, parser.TokenNotIn, parser.TokenLike, parser.TokenNotLike,
+		parser.TokenContains, parser.TokenIContains, parser.TokenStartsWith, parser.TokenEndsWith,
+		parser.TokenRegex, parser.TokenMatch, parser.TokenLeftBracket, parser.TokenComma:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const catalogSource = `# Product catalog filters
+
+# Electronics that are in stock
+@query in_stock_electronics
+category = electronics
+AND stock > 0   # zero-stock items are hidden
+
+# Products cheaper than a given price
+# in a category
+@query cheap(category, max_price)
+category = $category
+AND price < $max_price
+sort_by = price
+
+@query by_brand($brands)
+brand IN [$brands, "Generic"]
+`
+
+func TestParse(t *testing.T) {
+	lib, err := Parse(catalogSource)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"by_brand", "cheap", "in_stock_electronics"}, lib.Names())
+
+	nq, ok := lib.Get("cheap")
+	require.True(t, ok)
+	assert.Equal(t, []string{"category", "max_price"}, nq.Params)
+	assert.Equal(t, "Products cheaper than a given price\nin a category", nq.Doc)
+	assert.Equal(t, "category = $category\nAND price < $max_price\nsort_by = price", nq.Source)
+
+	nq, _ = lib.Get("in_stock_electronics")
+	assert.Equal(t, "Electronics that are in stock", nq.Doc)
+	assert.Empty(t, nq.Params)
+
+	nq, _ = lib.Get("by_brand")
+	assert.Empty(t, nq.Doc)
+}
+
+func TestLibrary_Query(t *testing.T) {
+	lib, err := Parse(catalogSource)
+	require.NoError(t, err)
+
+	t.Run("without parameters", func(t *testing.T) {
+		q, err := lib.Query("in_stock_electronics", nil)
+		require.NoError(t, err)
+		assert.Equal(t, &query.BinaryOpNode{
+			Operator: query.BinaryOpAnd,
+			Left:     &query.ComparisonNode{Field: "category", Operator: query.OpEqual, Value: query.StringValue("electronics")},
+			Right:    &query.ComparisonNode{Field: "stock", Operator: query.OpGreaterThan, Value: query.IntValue(0)},
+		}, q.Filter)
+	})
+
+	t.Run("typed parameters", func(t *testing.T) {
+		q, err := lib.Query("cheap", map[string]interface{}{"category": `books" OR 1=1`, "max_price": 20.5})
+		require.NoError(t, err)
+		assert.Equal(t, "price", q.SortBy)
+		assert.Equal(t, &query.BinaryOpNode{
+			Operator: query.BinaryOpAnd,
+			Left:     &query.ComparisonNode{Field: "category", Operator: query.OpEqual, Value: query.StringValue(`books" OR 1=1`)},
+			Right:    &query.ComparisonNode{Field: "price", Operator: query.OpLessThan, Value: query.FloatValue(20.5)},
+		}, q.Filter)
+	})
+
+	t.Run("list parameter spliced into array", func(t *testing.T) {
+		q, err := lib.Query("by_brand", map[string]interface{}{"brands": []string{"Anker", "Sony"}})
+		require.NoError(t, err)
+		assert.Equal(t, query.ArrayValue{query.StringValue("Anker"), query.StringValue("Sony"), query.StringValue("Generic")},
+			q.Filter.(*query.ComparisonNode).Value)
+	})
+
+	t.Run("parameters are query parameters", func(t *testing.T) {
+		nq, _ := lib.Get("cheap")
+		assert.Equal(t, []string{"category", "max_price"}, nq.template.Parameters())
+
+		colon, err := Parse("@query cheap(max_price)\nprice < :max_price")
+		require.NoError(t, err)
+		q, err := colon.Query("cheap", map[string]interface{}{"max_price": 3})
+		require.NoError(t, err)
+		assert.Equal(t, query.IntValue(3), q.Filter.(*query.ComparisonNode).Value)
+	})
+
+	t.Run("binding does not modify the template", func(t *testing.T) {
+		_, err := lib.Query("cheap", map[string]interface{}{"category": "a", "max_price": 1})
+		require.NoError(t, err)
+		q, err := lib.Query("cheap", map[string]interface{}{"category": "b", "max_price": 2})
+		require.NoError(t, err)
+		assert.Equal(t, query.StringValue("b"), q.Filter.(*query.BinaryOpNode).Left.(*query.ComparisonNode).Value)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := lib.Query("missing", nil)
+		assert.ErrorIs(t, err, query.ErrNamedQueryNotFound)
+
+		_, err = lib.Query("cheap", map[string]interface{}{"category": "a"})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		assert.Contains(t, err.Error(), "missing parameter $max_price")
+
+		_, err = lib.Query("cheap", map[string]interface{}{"category": "a", "max_price": 1, "extra": 2})
+		assert.Contains(t, err.Error(), "unknown parameter $extra")
+
+		_, err = lib.Query("cheap", map[string]interface{}{"category": struct{}{}, "max_price": 1})
+		assert.Contains(t, err.Error(), "unsupported type")
+	})
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		message string
+	}{
+		{"body outside query", "status = active", "line 1: query body outside @query"},
+		{"unknown directive", "@filter x\nstatus = active", "unknown directive @filter"},
+		{"invalid name", "@query bad-name\nx = 1", `invalid query name "bad-name"`},
+		{"duplicate query", "@query a\nx = 1\n@query a\nx = 2", "line 3: duplicate query a"},
+		{"no filter", "@query a\n# nothing here\n\n@query b\nx = 1", "query a has no filter"},
+		{"undeclared parameter", "@query a\nx = $y", "undeclared parameter $y"},
+		{"undeclared query parameter", "@query a\nx = :y", "undeclared parameter :y"},
+		{"parameter as field", "@query a($f)\n$f = 1", "parameters can only be used as values"},
+		{"parameter as AND operand", "@query a($f)\nx = 1 AND $f", "parameters can only be used as values"},
+		{"duplicate parameter", "@query a(x, x)\nf = $x", "duplicate parameter x"},
+		{"syntax error", "@query a\nx = ", "query a"},
+		{"include without file system", `@include "other.gq"`, "@include is not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, query.ErrInvalidQuery)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestParse_DollarInStringsAndComments(t *testing.T) {
+	lib, err := Parse("@query deals\nlabel = \"$5 off\" # costs $5\n/* $unused */")
+	require.NoError(t, err)
+
+	q, err := lib.Query("deals", nil)
+	require.NoError(t, err)
+	assert.Equal(t, query.StringValue("$5 off"), q.Filter.(*query.ComparisonNode).Value)
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"filters/main.gq":          {Data: []byte("@include \"shared/common.gq\"\n@include \"shared/extra.gq\"\n@query main\nstatus = active")},
+		"filters/shared/common.gq": {Data: []byte("@query common\ndeleted = false")},
+		"filters/shared/extra.gq":  {Data: []byte("@include \"common.gq\"\n@query extra\nfeatured = true")},
+		"cycle/a.gq":               {Data: []byte("@include \"b.gq\"\n@query a\nx = 1")},
+		"cycle/b.gq":               {Data: []byte("@include \"a.gq\"\n@query b\nx = 2")},
+		"dup/main.gq":              {Data: []byte("@include \"other.gq\"\n@query same\nx = 1")},
+		"dup/other.gq":             {Data: []byte("@query same\nx = 2")},
+	}
+
+	t.Run("nested includes loaded once", func(t *testing.T) {
+		lib, err := LoadFS(fsys, "filters/main.gq")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"common", "extra", "main"}, lib.Names())
+
+		nq, _ := lib.Get("common")
+		assert.Equal(t, "filters/shared/common.gq", nq.File)
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		_, err := LoadFS(fsys, "cycle/a.gq")
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		assert.Contains(t, err.Error(), "include cycle at cycle/a.gq")
+	})
+
+	t.Run("duplicate across files", func(t *testing.T) {
+		_, err := LoadFS(fsys, "dup/main.gq")
+		assert.Contains(t, err.Error(), "dup/main.gq:2: duplicate query same")
+	})
+
+	t.Run("missing include", func(t *testing.T) {
+		_, err := LoadFS(fstest.MapFS{"a.gq": {Data: []byte(`@include "nope.gq"`)}}, "a.gq")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.gq"), []byte("@query base\ntenant_id = $tenant"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.gq"), []byte("@include \"base.gq\"\n"), 0o644))
+
+	_, err := LoadFile(filepath.Join(dir, "app.gq"))
+	assert.Contains(t, err.Error(), "undeclared parameter $tenant")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.gq"), []byte("@query base(tenant)\ntenant_id = $tenant"), 0o644))
+	lib, err := LoadFile(filepath.Join(dir, "app.gq"))
+	require.NoError(t, err)
+
+	q, err := lib.Query("base", map[string]interface{}{"tenant": 7})
+	require.NoError(t, err)
+	assert.Equal(t, query.IntValue(7), q.Filter.(*query.ComparisonNode).Value)
+}
@@ -0,0 +1,486 @@
+// Package lint reports queries that are valid but likely to be slow or that
+// say more than they need to, so a UI can warn power users before running
+// expensive searches:
+//
+//	issues := lint.Lint(q, &lint.Options{
+//	    Indexes:        [][]string{{"brand", "price"}, {"created_at"}},
+//	    EstimatedItems: 2_000_000,
+//	})
+//	for _, issue := range issues {
+//	    fmt.Printf("%s: %s (try %s)\n", issue.Condition, issue.Message, issue.Suggestion)
+//	}
+//
+// Issues carry a Suggestion, a cheaper condition with the same meaning, when
+// one exists.
+package lint
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Rule identifies the check that reported an issue
+type Rule string
+
+const (
+	// RuleUnindexedField reports filters on fields no index can serve
+	RuleUnindexedField Rule = "unindexed-field"
+
+	// RuleUnindexedSort reports sorting by a field no index can serve
+	RuleUnindexedSort Rule = "unindexed-sort"
+
+	// RuleLeadingWildcard reports LIKE patterns starting with a wildcard,
+	// which cannot use an index
+	RuleLeadingWildcard Rule = "leading-wildcard"
+
+	// RuleLikeWithoutWildcard reports LIKE patterns without wildcards, which
+	// are equalities
+	RuleLikeWithoutWildcard Rule = "like-without-wildcard"
+
+	// RuleRegexScan reports REGEX conditions, which test every item
+	RuleRegexScan Rule = "regex-scan"
+
+	// RuleRedundantPredicate reports conditions implied by other conditions
+	RuleRedundantPredicate Rule = "redundant-predicate"
+)
+
+// Severity is how much an issue matters
+type Severity int
+
+const (
+	// SeverityWarning marks issues that make a query noticeably slower
+	SeverityWarning Severity = iota
+	// SeverityInfo marks issues worth a hint, e.g. a simpler equivalent
+	SeverityInfo
+)
+
+// String returns "warning" or "info"
+func (s Severity) String() string {
+	if s == SeverityInfo {
+		return "info"
+	}
+	return "warning"
+}
+
+// DefaultLargeCollection is the item count from which REGEX conditions are
+// reported as warnings
+const DefaultLargeCollection = 100000
+
+// Options configure the checks
+type Options struct {
+	// Indexes lists the indexes of the collection or table, each as its
+	// fields in order. A field is served by an index when it is the first
+	// field, or when the fields before it are compared with = or IN. nil
+	// disables RuleUnindexedField and RuleUnindexedSort
+	Indexes [][]string
+
+	// EstimatedItems is the approximate size of the collection; 0 means
+	// unknown. REGEX conditions are warnings from LargeCollection items and
+	// hints otherwise
+	EstimatedItems int64
+
+	// LargeCollection is the size from which a collection is large. 0 uses
+	// DefaultLargeCollection
+	LargeCollection int64
+
+	// Disabled turns rules off
+	Disabled []Rule
+}
+
+// Issue is a problem found in a query
+type Issue struct {
+	Rule     Rule
+	Severity Severity
+
+	// Field is the field of the condition, if any
+	Field string
+
+	// Condition is the offending condition in query syntax
+	Condition string
+
+	// Message explains the issue
+	Message string
+
+	// Suggestion is an equivalent condition in query syntax that avoids the
+	// issue, or empty when there is none
+	Suggestion string
+}
+
+// String formats the issue for logs
+func (i Issue) String() string {
+	s := fmt.Sprintf("%s [%s] %s", i.Severity, i.Rule, i.Message)
+	if i.Suggestion != "" {
+		s += "; use " + i.Suggestion
+	}
+	return s
+}
+
+// Lint checks q and returns its issues in the order of the filter, followed
+// by issues of the sort field. A nil query has none. If opts is nil, all
+// checks except the index checks run
+func Lint(q *query.Query, opts *Options) []Issue {
+	if q == nil {
+		return nil
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	l := &linter{opts: opts, equalities: equalityFields(q.Filter), reported: map[string]bool{}}
+
+	l.checkRedundant(q.Filter)
+	query.Walk(q.Filter, func(node query.Node) bool {
+		if n, ok := node.(*query.ComparisonNode); ok {
+			l.checkComparison(n)
+		}
+		return true
+	})
+	l.checkBounds(q.Filter)
+
+	if q.SortBy != "" && q.SortBy != query.ScoreField && q.SortBy != query.MatchCountField && !l.indexed(q.SortBy) {
+		l.report(Issue{
+			Rule:     RuleUnindexedSort,
+			Severity: SeverityWarning,
+			Field:    q.SortBy,
+			Message:  fmt.Sprintf("sorting by %s is not served by an index; every match is sorted in memory", q.SortBy),
+		})
+	}
+	return l.issues
+}
+
+// linter collects the issues of one query
+type linter struct {
+	opts       *Options
+	equalities map[string]bool
+	reported   map[string]bool
+	issues     []Issue
+}
+
+func (l *linter) report(issue Issue) {
+	for _, rule := range l.opts.Disabled {
+		if rule == issue.Rule {
+			return
+		}
+	}
+	l.issues = append(l.issues, issue)
+}
+
+// checkComparison runs the checks of a single condition
+func (l *linter) checkComparison(n *query.ComparisonNode) {
+	if query.IsSearch(n) {
+		return
+	}
+	condition := query.FormatFilter(n)
+
+	if n.Modifier == query.ArrayModifierNone && !l.reported[n.Field] && !l.indexed(n.Field) {
+		l.reported[n.Field] = true
+		l.report(Issue{
+			Rule:      RuleUnindexedField,
+			Severity:  SeverityWarning,
+			Field:     n.Field,
+			Condition: condition,
+			Message:   fmt.Sprintf("%s is not served by an index; the condition scans every item", n.Field),
+		})
+	}
+
+	pattern, isString := n.Value.(query.StringValue)
+	if !isString {
+		return
+	}
+	switch n.Operator {
+	case query.OpLike, query.OpNotLike:
+		l.checkLike(n, string(pattern), condition)
+	case query.OpRegex:
+		l.checkRegex(n, string(pattern), condition)
+	}
+}
+
+func (l *linter) checkLike(n *query.ComparisonNode, pattern, condition string) {
+	if !strings.ContainsAny(pattern, "%_") {
+		op := query.OpEqual
+		if n.Operator == query.OpNotLike {
+			op = query.OpNotEqual
+		}
+		l.report(Issue{
+			Rule:       RuleLikeWithoutWildcard,
+			Severity:   SeverityInfo,
+			Field:      n.Field,
+			Condition:  condition,
+			Message:    "the LIKE pattern has no wildcards and matches exactly",
+			Suggestion: query.FormatFilter(&query.ComparisonNode{Field: n.Field, Operator: op, Value: query.StringValue(pattern)}),
+		})
+		return
+	}
+	if pattern[0] != '%' && pattern[0] != '_' {
+		return
+	}
+
+	issue := Issue{
+		Rule:      RuleLeadingWildcard,
+		Severity:  SeverityWarning,
+		Field:     n.Field,
+		Condition: condition,
+		Message:   "a LIKE pattern starting with a wildcard cannot use an index",
+	}
+	if n.Operator == query.OpLike {
+		inner := strings.TrimPrefix(pattern, "%")
+		switch {
+		case len(inner) > 1 && strings.HasSuffix(inner, "%") && !strings.ContainsAny(inner[:len(inner)-1], "%_"):
+			issue.Suggestion = query.FormatFilter(&query.ComparisonNode{Field: n.Field, Operator: query.OpContains, Value: query.StringValue(inner[:len(inner)-1])})
+		case pattern[0] == '%' && inner != "" && !strings.ContainsAny(inner, "%_"):
+			issue.Suggestion = query.FormatFilter(&query.ComparisonNode{Field: n.Field, Operator: query.OpEndsWith, Value: query.StringValue(inner)})
+		}
+	}
+	l.report(issue)
+}
+
+func (l *linter) checkRegex(n *query.ComparisonNode, pattern, condition string) {
+	large := l.opts.LargeCollection
+	if large <= 0 {
+		large = DefaultLargeCollection
+	}
+	issue := Issue{
+		Rule:      RuleRegexScan,
+		Severity:  SeverityInfo,
+		Field:     n.Field,
+		Condition: condition,
+		Message:   "REGEX tests every item",
+	}
+	if l.opts.EstimatedItems >= large {
+		issue.Severity = SeverityWarning
+		issue.Message = fmt.Sprintf("REGEX tests every one of about %d items", l.opts.EstimatedItems)
+	}
+	if op, literal, ok := regexLiteral(pattern); ok {
+		issue.Suggestion = query.FormatFilter(&query.ComparisonNode{Field: n.Field, Operator: op, Value: query.StringValue(literal)})
+	}
+	l.report(issue)
+}
+
+// regexLiteral returns the string operator and text equivalent to a regular
+// expression matching a literal, optionally anchored at either end
+func regexLiteral(pattern string) (query.ComparisonOperator, string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0, "", false
+	}
+	re = re.Simplify()
+	parts := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		parts = re.Sub
+	}
+
+	begin, end := false, false
+	if len(parts) > 0 && parts[0].Op == syntax.OpBeginText {
+		begin, parts = true, parts[1:]
+	}
+	if len(parts) > 0 && parts[len(parts)-1].Op == syntax.OpEndText {
+		end, parts = true, parts[:len(parts)-1]
+	}
+	if len(parts) != 1 || parts[0].Op != syntax.OpLiteral {
+		return 0, "", false
+	}
+	literal := parts[0]
+	text := string(literal.Rune)
+	switch {
+	case literal.Flags&syntax.FoldCase != 0:
+		if begin || end {
+			return 0, "", false
+		}
+		return query.OpIContains, strings.ToLower(text), true
+	case begin && end:
+		return query.OpEqual, text, true
+	case begin:
+		return query.OpStartsWith, text, true
+	case end:
+		return query.OpEndsWith, text, true
+	default:
+		return query.OpContains, text, true
+	}
+}
+
+// checkRedundant reports a filter that query.Optimize simplifies, e.g.
+// repeated conditions or equalities that form an IN list
+func (l *linter) checkRedundant(filter query.Node) {
+	if filter == nil {
+		return
+	}
+	// Every simplification of Optimize drops conditions; regrouping the
+	// operands of a chain alone is not worth reporting
+	optimized := query.Optimize(filter)
+	if countConditions(optimized) == countConditions(filter) {
+		return
+	}
+	l.report(Issue{
+		Rule:       RuleRedundantPredicate,
+		Severity:   SeverityInfo,
+		Condition:  query.FormatFilter(filter),
+		Message:    "the filter repeats conditions or can be written more simply",
+		Suggestion: query.FormatFilter(optimized),
+	})
+}
+
+func countConditions(filter query.Node) int {
+	count := 0
+	query.Walk(filter, func(node query.Node) bool {
+		if _, ok := node.(*query.ComparisonNode); ok {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// checkBounds reports range conditions of an AND chain implied by a tighter
+// condition on the same field, e.g. price > 5 in price > 10 AND price > 5
+func (l *linter) checkBounds(node query.Node) {
+	n, ok := node.(*query.BinaryOpNode)
+	if !ok {
+		return
+	}
+	operands := query.Operands(n)
+	for _, operand := range operands {
+		l.checkBounds(operand)
+	}
+	if n.Operator != query.BinaryOpAnd {
+		return
+	}
+
+	for i, operand := range operands {
+		loose, ok := rangeCondition(operand)
+		if !ok {
+			continue
+		}
+		for j, other := range operands {
+			tight, ok := rangeCondition(other)
+			if !ok || i == j || tight.Field != loose.Field || !implies(tight, loose) {
+				continue
+			}
+			// Equal bounds are repeats, which checkRedundant reports
+			if implies(loose, tight) {
+				continue
+			}
+			l.report(Issue{
+				Rule:      RuleRedundantPredicate,
+				Severity:  SeverityInfo,
+				Field:     loose.Field,
+				Condition: query.FormatFilter(loose),
+				Message:   fmt.Sprintf("implied by %s", query.FormatFilter(tight)),
+			})
+			break
+		}
+	}
+}
+
+// rangeCondition returns node as a >, >=, < or <= condition on an ordered value
+func rangeCondition(node query.Node) (*query.ComparisonNode, bool) {
+	n, ok := node.(*query.ComparisonNode)
+	if !ok || n.Modifier != query.ArrayModifierNone || query.IsSearch(n) {
+		return nil, false
+	}
+	switch n.Operator {
+	case query.OpGreaterThan, query.OpGreaterThanOrEqual, query.OpLessThan, query.OpLessThanOrEqual:
+		_, ok := compareValues(n.Value, n.Value)
+		return n, ok
+	}
+	return nil, false
+}
+
+// implies reports whether every value matching range condition a also
+// matches b; both are on the same field
+func implies(a, b *query.ComparisonNode) bool {
+	lower := func(op query.ComparisonOperator) bool {
+		return op == query.OpGreaterThan || op == query.OpGreaterThanOrEqual
+	}
+	if lower(a.Operator) != lower(b.Operator) {
+		return false
+	}
+	cmp, ok := compareValues(a.Value, b.Value)
+	if !ok {
+		return false
+	}
+	if !lower(a.Operator) {
+		cmp = -cmp
+	}
+	// For lower bounds a implies b when a's bound is higher, or equal with a
+	// strict or b inclusive; upper bounds mirror it
+	strict := a.Operator == query.OpGreaterThan || a.Operator == query.OpLessThan
+	inclusive := b.Operator == query.OpGreaterThanOrEqual || b.Operator == query.OpLessThanOrEqual
+	return cmp > 0 || (cmp == 0 && (strict || inclusive))
+}
+
+// compareValues compares two numbers or two times
+func compareValues(a, b interface{}) (int, bool) {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	x, ok := a.(query.DateTimeValue)
+	if !ok {
+		return 0, false
+	}
+	y, ok := b.(query.DateTimeValue)
+	if !ok {
+		return 0, false
+	}
+	return time.Time(x).Compare(time.Time(y)), true
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case query.IntValue:
+		return float64(n), true
+	case query.FloatValue:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// indexed reports whether an index serves field
+func (l *linter) indexed(field string) bool {
+	if l.opts.Indexes == nil {
+		return true
+	}
+	for _, index := range l.opts.Indexes {
+		for _, column := range index {
+			if column == field {
+				return true
+			}
+			if !l.equalities[column] {
+				break
+			}
+		}
+	}
+	return false
+}
+
+// equalityFields returns the fields compared with = or IN in the top-level
+// AND chain of filter; they can lead a compound index
+func equalityFields(filter query.Node) map[string]bool {
+	fields := map[string]bool{}
+	operands := []query.Node{filter}
+	if n, ok := filter.(*query.BinaryOpNode); ok {
+		if n.Operator != query.BinaryOpAnd {
+			return fields
+		}
+		operands = query.Operands(n)
+	}
+	for _, operand := range operands {
+		n, ok := operand.(*query.ComparisonNode)
+		if ok && n.Modifier == query.ArrayModifierNone && (n.Operator == query.OpEqual || n.Operator == query.OpIn) {
+			fields[n.Field] = true
+		}
+	}
+	return fields
+}
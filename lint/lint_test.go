@@ -0,0 +1,138 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t *testing.T, input string) *query.Query {
+	t.Helper()
+	p, err := parser.NewParser(input)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	return q
+}
+
+func rules(issues []Issue) []Rule {
+	var out []Rule
+	for _, issue := range issues {
+		out = append(out, issue.Rule)
+	}
+	return out
+}
+
+func TestLint_Indexes(t *testing.T) {
+	opts := &Options{Indexes: [][]string{{"brand", "price"}, {"created_at"}}}
+
+	t.Run("compound index after equality", func(t *testing.T) {
+		issues := Lint(mustParse(t, `brand = Sony AND price < 100 sort_by = created_at`), opts)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("unindexed field and sort", func(t *testing.T) {
+		issues := Lint(mustParse(t, `price < 100 AND (color = red OR color = blue) sort_by = name`), opts)
+		require.Len(t, issues, 4)
+		assert.Equal(t, []Rule{RuleRedundantPredicate, RuleUnindexedField, RuleUnindexedField, RuleUnindexedSort}, rules(issues))
+		assert.Equal(t, "price", issues[1].Field)
+		assert.Equal(t, "color", issues[2].Field)
+		assert.Equal(t, `color = "red"`, issues[2].Condition)
+		assert.Equal(t, "name", issues[3].Field)
+	})
+
+	t.Run("without indexes", func(t *testing.T) {
+		assert.Empty(t, Lint(mustParse(t, `color = red sort_by = name`), nil))
+	})
+}
+
+func TestLint_Like(t *testing.T) {
+	tests := []struct {
+		input      string
+		rule       Rule
+		suggestion string
+	}{
+		{`name LIKE "%cable%"`, RuleLeadingWildcard, `name CONTAINS "cable"`},
+		{`name LIKE "%.pdf"`, RuleLeadingWildcard, `name ENDS_WITH ".pdf"`},
+		{`name LIKE "_x%y"`, RuleLeadingWildcard, ""},
+		{`name NOT LIKE "%cable%"`, RuleLeadingWildcard, ""},
+		{`name LIKE "cable"`, RuleLikeWithoutWildcard, `name = "cable"`},
+		{`name NOT LIKE "cable"`, RuleLikeWithoutWildcard, `name != "cable"`},
+	}
+	for _, tt := range tests {
+		issues := Lint(mustParse(t, tt.input), nil)
+		require.Len(t, issues, 1, tt.input)
+		assert.Equal(t, tt.rule, issues[0].Rule, tt.input)
+		assert.Equal(t, tt.suggestion, issues[0].Suggestion, tt.input)
+	}
+
+	assert.Empty(t, Lint(mustParse(t, `name LIKE "cable%"`), nil))
+}
+
+func TestLint_Regex(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		suggestion string
+	}{
+		{`^usb`, `name STARTS_WITH "usb"`},
+		{`cable$`, `name ENDS_WITH "cable"`},
+		{`^usb-c$`, `name = "usb-c"`},
+		{`cable`, `name CONTAINS "cable"`},
+		{`(?i)cable`, `name ICONTAINS "cable"`},
+		{`^usb.*c$`, ""},
+	}
+	for _, tt := range tests {
+		q := &query.Query{Filter: query.Compare("name", query.OpRegex, tt.pattern)}
+		issues := Lint(q, nil)
+		require.Len(t, issues, 1, tt.pattern)
+		assert.Equal(t, RuleRegexScan, issues[0].Rule)
+		assert.Equal(t, SeverityInfo, issues[0].Severity)
+		assert.Equal(t, tt.suggestion, issues[0].Suggestion, tt.pattern)
+	}
+
+	q := &query.Query{Filter: query.Compare("name", query.OpRegex, "a+b")}
+	issues := Lint(q, &Options{EstimatedItems: 5000000})
+	require.Len(t, issues, 1)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+}
+
+func TestLint_Redundant(t *testing.T) {
+	t.Run("repeated conditions", func(t *testing.T) {
+		issues := Lint(mustParse(t, `brand = Sony OR brand = JBL OR brand = Sony`), nil)
+		require.Len(t, issues, 1)
+		assert.Equal(t, RuleRedundantPredicate, issues[0].Rule)
+		assert.Equal(t, `brand IN ["Sony", "JBL"]`, issues[0].Suggestion)
+	})
+
+	t.Run("implied bounds", func(t *testing.T) {
+		issues := Lint(mustParse(t, `price > 5 AND price >= 10 AND price < 100 AND price <= 100 AND rating > 4`), nil)
+		require.Len(t, issues, 2)
+		assert.Equal(t, "price > 5", issues[0].Condition)
+		assert.Equal(t, "implied by price >= 10", issues[0].Message)
+		assert.Equal(t, "price <= 100", issues[1].Condition)
+	})
+
+	t.Run("equal bounds", func(t *testing.T) {
+		issues := Lint(mustParse(t, `price > 5 AND price > 5.0`), nil)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "price > 5", issues[0].Suggestion)
+	})
+
+	t.Run("bounds in OR are independent", func(t *testing.T) {
+		assert.Empty(t, Lint(mustParse(t, `price > 5 OR price > 10`), nil))
+	})
+}
+
+func TestLint_Disabled(t *testing.T) {
+	issues := Lint(mustParse(t, `name LIKE "%cable%"`), &Options{Disabled: []Rule{RuleLeadingWildcard}})
+	assert.Empty(t, issues)
+	assert.Nil(t, Lint(nil, nil))
+}
+
+func TestIssue_String(t *testing.T) {
+	issue := Issue{Rule: RuleLikeWithoutWildcard, Severity: SeverityInfo, Message: "exact", Suggestion: `name = "x"`}
+	assert.Equal(t, `info [like-without-wildcard] exact; use name = "x"`, issue.String())
+}
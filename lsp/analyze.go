@@ -0,0 +1,335 @@
+// Package lsp provides editor support for the query language: diagnostics for
+// parse and validation errors, hover documentation for fields and operators, and
+// context-aware completion.
+//
+// Diagnostics, HoverAt and Complete work on plain text and can back embedded
+// editors such as Monaco directly. Server speaks the Language Server Protocol
+// over a stream for desktop editors:
+//
+//	srv := lsp.NewServer(&lsp.Options{Schema: schema})
+//	err := srv.Serve(ctx, os.Stdin, os.Stdout)
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hadi77ir/go-query/completion"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Options configures analysis
+type Options struct {
+	// Schema types fields for hover, completion and type-checking diagnostics.
+	// When set, fields missing from the schema are reported as warnings
+	Schema query.Schema
+
+	// Descriptions documents fields in hover and completion
+	Descriptions map[string]string
+
+	// ParserOptions are used to parse documents, e.g. for keyword aliases
+	ParserOptions *parser.ParserOptions
+
+	// ExecutorOptions, if set, also reports queries the executor would reject
+	// (AllowedFields, FieldPolicy, limits, SortableFields)
+	ExecutorOptions *query.ExecutorOptions
+
+	// Values returns the values completed after a field's operator, e.g. the
+	// values of an enumerated field
+	Values completion.ValuesFunc
+}
+
+// diagnosticSource is the Source of every diagnostic
+const diagnosticSource = "go-query"
+
+// Keyword tokens documented on hover
+var keywordDocs = map[parser.TokenType]string{
+	parser.TokenAnd:        "Both conditions must match.",
+	parser.TokenOr:         "Either condition must match.",
+	parser.TokenNot:        "Negates LIKE or IN: `NOT LIKE`, `NOT IN`.",
+	parser.TokenLike:       "SQL LIKE pattern: `%` matches any text, `_` one character.",
+	parser.TokenContains:   "Contains the text (case-sensitive).",
+	parser.TokenIContains:  "Contains the text (case-insensitive).",
+	parser.TokenStartsWith: "Starts with the text.",
+	parser.TokenEndsWith:   "Ends with the text.",
+	parser.TokenRegex:      "Matches the regular expression.",
+	parser.TokenIn:         "Equal to one of the listed values: `[a, b]`.",
+	parser.TokenMatch:      "Full-text match of all search terms.",
+}
+
+// errorPosition extracts the byte offset from parser and lexer errors
+var errorPosition = regexp.MustCompile(`at position (\d+)`)
+
+// Diagnostics parses text and returns its problems: parse errors, schema type
+// mismatches, unknown fields and anything ExecutorOptions would reject
+func Diagnostics(text string, opts *Options) []Diagnostic {
+	opts = resolveOptions(opts)
+	doc := newDocument(text)
+	spans, _ := lex(text, opts.ParserOptions)
+
+	p, err := parser.NewParserWithOptions(text, opts.ParserOptions)
+	var q *query.Query
+	if err == nil {
+		q, err = p.Parse()
+	}
+	if err != nil {
+		rng := doc.rangeOf(0, len(text))
+		if m := errorPosition.FindStringSubmatch(err.Error()); m != nil {
+			offset, _ := strconv.Atoi(m[1])
+			rng = doc.rangeOf(offset, offset)
+			if s, ok := spanAt(spans, offset); ok {
+				rng = doc.rangeOf(s.start, s.end)
+			}
+		}
+		return []Diagnostic{{Range: rng, Severity: SeverityError, Source: diagnosticSource, Message: err.Error()}}
+	}
+
+	var diags []Diagnostic
+	report := func(err error, severity DiagnosticSeverity) {
+		if err == nil {
+			return
+		}
+		rng := doc.rangeOf(0, len(text))
+		if field := errorField(err); field != "" {
+			if s, ok := identifierSpan(spans, field); ok {
+				rng = doc.rangeOf(s.start, s.end)
+			}
+		}
+		diags = append(diags, Diagnostic{Range: rng, Severity: severity, Source: diagnosticSource, Message: err.Error()})
+	}
+
+	if len(opts.Schema) > 0 {
+		report(query.ValidateAgainstSchema(q, opts.Schema), SeverityError)
+		for _, field := range filterFields(q.Filter) {
+			if _, ok := opts.Schema[field]; !ok {
+				report(query.UnknownFieldError(field, opts.Schema.FieldNames()...), SeverityWarning)
+			}
+		}
+		report(query.ValidateSortField(q.SortBy, opts.Schema.FieldNames()), SeverityError)
+	}
+	if opts.ExecutorOptions != nil {
+		for _, placeholder := range filterPlaceholders(q.Filter) {
+			if _, ok := opts.ExecutorOptions.Placeholders[placeholder.name]; !ok {
+				report(query.NewFieldError(placeholder.field, fmt.Errorf("%w: @%s", query.ErrUnknownPlaceholder, placeholder.name)), SeverityError)
+			}
+		}
+		// Placeholder values are only known when the query executes
+		report(opts.ExecutorOptions.ValidateFilter(withoutPlaceholders(q.Filter)), SeverityError)
+		for _, field := range filterFields(q.Filter) {
+			if !opts.ExecutorOptions.IsFieldAllowed(field) {
+				report(query.FieldNotAllowedError(field, opts.ExecutorOptions.AllowedFields...), SeverityError)
+			}
+		}
+		report(opts.ExecutorOptions.ValidateSortField(q.SortBy), SeverityError)
+		report(opts.ExecutorOptions.ValidateDistinct(q), SeverityError)
+	}
+	return diags
+}
+
+// HoverAt returns documentation for the field, operator or query option at pos,
+// or nil if there is nothing to show
+func HoverAt(text string, pos Position, opts *Options) *Hover {
+	opts = resolveOptions(opts)
+	doc := newDocument(text)
+	spans, _ := lex(text, opts.ParserOptions)
+	offset := doc.offset(pos)
+
+	for i, s := range spans {
+		if offset < s.start || offset > s.end || s.tok.Type == parser.TokenEOF {
+			continue
+		}
+		var markdown string
+		switch s.tok.Type {
+		case parser.TokenQuotedIdentifier:
+			markdown = fieldMarkdown(s.tok.Value, opts)
+		case parser.TokenIdentifier:
+			name := s.tok.Value
+			if option, ok := completion.OptionName(name, opts.ParserOptions); ok && i+1 < len(spans) && spans[i+1].tok.Value == "=" {
+				markdown = "**" + option + "**\n\n" + completion.OptionDoc(option)
+			} else {
+				markdown = fieldMarkdown(name, opts)
+			}
+		case parser.TokenOperator:
+			markdown = operatorMarkdown(query.ParseComparisonOperator(s.tok.Value))
+		default:
+			if help, ok := keywordDocs[s.tok.Type]; ok {
+				markdown = "**" + strings.ToUpper(s.tok.Value) + "**\n\n" + help
+			}
+		}
+		if markdown == "" {
+			return nil
+		}
+		rng := doc.rangeOf(s.start, s.end)
+		return &Hover{Contents: MarkupContent{Kind: "markdown", Value: markdown}, Range: &rng}
+	}
+	return nil
+}
+
+// Complete returns completion items for pos: fields and query options where a
+// condition starts, operators after a field, values for options and boolean
+// fields, and AND/OR after a complete condition
+func Complete(text string, pos Position, opts *Options) []CompletionItem {
+	opts = resolveOptions(opts)
+	result := completion.Complete(text, newDocument(text).offset(pos), &completion.Options{
+		Schema:          opts.Schema,
+		Descriptions:    opts.Descriptions,
+		ParserOptions:   opts.ParserOptions,
+		ExecutorOptions: opts.ExecutorOptions,
+		Values:          opts.Values,
+	})
+
+	var items []CompletionItem
+	for _, candidate := range result.Candidates {
+		items = append(items, CompletionItem{
+			Label:         candidate.Label,
+			Kind:          completionKinds[candidate.Kind],
+			Detail:        candidate.Detail,
+			Documentation: candidate.Documentation,
+		})
+	}
+	return items
+}
+
+// completionKinds maps candidate kinds to LSP item kinds
+var completionKinds = map[completion.Kind]CompletionItemKind{
+	completion.KindField:    CompletionKindField,
+	completion.KindOperator: CompletionKindOperator,
+	completion.KindValue:    CompletionKindValue,
+	completion.KindKeyword:  CompletionKindKeyword,
+}
+
+// fieldMarkdown documents a field, or returns "" for unknown fields
+func fieldMarkdown(name string, opts *Options) string {
+	kind, typed := opts.Schema[name]
+	description := opts.Descriptions[name]
+	if !typed && description == "" {
+		return ""
+	}
+	markdown := "**" + name + "**"
+	if typed {
+		markdown += " `" + kind.String() + "`"
+	}
+	if description != "" {
+		markdown += "\n\n" + description
+	}
+	return markdown
+}
+
+// operatorMarkdown documents a comparison operator
+func operatorMarkdown(op query.ComparisonOperator) string {
+	if doc := completion.OperatorDoc(op); doc != "" {
+		return "**" + op.String() + "**\n\n" + doc
+	}
+	return ""
+}
+
+// filterFields returns the fields compared in a filter, without duplicates and bare search terms
+func filterFields(node query.Node) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	var walk func(query.Node)
+	walk = func(node query.Node) {
+		switch n := node.(type) {
+		case *query.BinaryOpNode:
+			walk(n.Left)
+			walk(n.Right)
+		case *query.ComparisonNode:
+			if n.Field != query.SearchField && !seen[n.Field] {
+				seen[n.Field] = true
+				fields = append(fields, n.Field)
+			}
+		}
+	}
+	walk(node)
+	return fields
+}
+
+// placeholderUse is a placeholder and the field it is compared with
+type placeholderUse struct {
+	field string
+	name  string
+}
+
+// filterPlaceholders returns the placeholders used in a filter
+func filterPlaceholders(node query.Node) []placeholderUse {
+	var uses []placeholderUse
+	var walk func(query.Node)
+	walk = func(node query.Node) {
+		switch n := node.(type) {
+		case *query.BinaryOpNode:
+			walk(n.Left)
+			walk(n.Right)
+		case *query.ComparisonNode:
+			values := []interface{}{n.Value}
+			if arr, ok := n.Value.(query.ArrayValue); ok {
+				values = arr
+			}
+			for _, value := range values {
+				if name, ok := value.(query.PlaceholderValue); ok {
+					uses = append(uses, placeholderUse{field: n.Field, name: string(name)})
+				}
+			}
+		}
+	}
+	walk(node)
+	return uses
+}
+
+// withoutPlaceholders returns a copy of node with placeholder and parameter
+// values replaced by empty values, so the rest of the filter can be validated
+func withoutPlaceholders(node query.Node) query.Node {
+	return query.Rewrite(node, func(node query.Node) query.Node {
+		n, ok := node.(*query.ComparisonNode)
+		if !ok {
+			return node
+		}
+		value := n.Value
+		switch v := n.Value.(type) {
+		case query.PlaceholderValue, query.ParameterValue:
+			value = query.StringValue("")
+			if n.Operator == query.OpIn || n.Operator == query.OpNotIn {
+				value = query.ArrayValue{}
+			}
+		case query.ArrayValue:
+			arr := make(query.ArrayValue, 0, len(v))
+			for _, elem := range v {
+				switch elem.(type) {
+				case query.PlaceholderValue, query.ParameterValue:
+				default:
+					arr = append(arr, elem)
+				}
+			}
+			value = arr
+		}
+		stripped := *n
+		stripped.Value = value
+		return &stripped
+	})
+}
+
+// errorField returns the field an error refers to, or ""
+func errorField(err error) string {
+	var fieldErr *query.FieldError
+	var opErr *query.OperatorError
+	var sortErr *query.SortFieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		return fieldErr.Field
+	case errors.As(err, &opErr):
+		return opErr.Field
+	case errors.As(err, &sortErr):
+		return sortErr.Field
+	}
+	return ""
+}
+
+func resolveOptions(opts *Options) *Options {
+	if opts == nil {
+		return &Options{}
+	}
+	return opts
+}
@@ -0,0 +1,176 @@
+package lsp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = &Options{
+	Schema: query.Schema{
+		"name":     query.FieldKindString,
+		"price":    query.FieldKindFloat,
+		"featured": query.FieldKindBool,
+		"tags":     query.FieldKindArray,
+	},
+	Descriptions: map[string]string{"price": "Unit price in USD"},
+}
+
+func labels(items []CompletionItem) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.Label)
+	}
+	return result
+}
+
+func TestDiagnostics_ParseError(t *testing.T) {
+	diags := Diagnostics(`name = "x" AND`, testOptions)
+	require.Len(t, diags, 1)
+	assert.Equal(t, SeverityError, diags[0].Severity)
+	assert.Contains(t, diags[0].Message, "incomplete AND expression")
+
+	diags = Diagnostics("name = \"x\"\nAND price > 'open", testOptions)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "unterminated string")
+	assert.Equal(t, Position{Line: 1, Character: 12}, diags[0].Range.Start)
+}
+
+func TestDiagnostics_Schema(t *testing.T) {
+	assert.Empty(t, Diagnostics(`name = "x" AND price < 10 sort_by = price`, testOptions))
+
+	diags := Diagnostics(`name = "x" AND price contains "1"`, testOptions)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "type mismatch")
+	assert.Equal(t, Range{Start: Position{0, 15}, End: Position{0, 20}}, diags[0].Range)
+
+	diags = Diagnostics(`colour = red sort_by = prise`, testOptions)
+	require.Len(t, diags, 2)
+	assert.Equal(t, SeverityWarning, diags[0].Severity)
+	assert.Contains(t, diags[0].Message, "colour")
+	assert.Equal(t, SeverityError, diags[1].Severity)
+	assert.Contains(t, diags[1].Message, "did you mean: price")
+
+	diags = Diagnostics(`nme = "x"`, testOptions)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "field 'nme': unknown field (did you mean: name?)", diags[0].Message)
+}
+
+func TestDiagnostics_ExecutorOptions(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"name"}
+
+	diags := Diagnostics(`name = "x" AND secret = 1`, &Options{ExecutorOptions: opts})
+	require.Len(t, diags, 1)
+	assert.Equal(t, Range{Start: Position{0, 15}, End: Position{0, 21}}, diags[0].Range)
+
+	diags = Diagnostics(`nam = "x"`, &Options{ExecutorOptions: opts})
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "did you mean: name?")
+}
+
+func TestDiagnostics_Placeholders(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.Placeholders = map[string]query.PlaceholderResolver{
+		"current_user": func(context.Context) (interface{}, error) { return 1, nil },
+	}
+	lspOpts := &Options{Schema: query.Schema{"owner_id": query.FieldKindInt}, ExecutorOptions: opts}
+
+	assert.Empty(t, Diagnostics(`owner_id = @current_user`, lspOpts))
+	assert.Empty(t, Diagnostics(`owner_id > :min AND owner_id IN [1, :ids]`, lspOpts))
+
+	diags := Diagnostics(`owner_id > 0 AND owner_id IN [1, @tenant]`, lspOpts)
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Message, "unknown placeholder: @tenant")
+	assert.Equal(t, Range{Start: Position{0, 0}, End: Position{0, 8}}, diags[0].Range)
+}
+
+func TestHoverAt(t *testing.T) {
+	text := `price >= 10 AND name icontains "tv" sort_by = price`
+
+	hover := HoverAt(text, Position{0, 2}, testOptions)
+	require.NotNil(t, hover)
+	assert.Equal(t, "**price** `float`\n\nUnit price in USD", hover.Contents.Value)
+	assert.Equal(t, Range{Start: Position{0, 0}, End: Position{0, 5}}, *hover.Range)
+
+	hover = HoverAt(text, Position{0, 6}, testOptions)
+	require.NotNil(t, hover)
+	assert.Contains(t, hover.Contents.Value, "Greater than or equal")
+
+	hover = HoverAt(text, Position{0, 23}, testOptions)
+	require.NotNil(t, hover)
+	assert.Contains(t, hover.Contents.Value, "case-insensitive")
+
+	hover = HoverAt(text, Position{0, 38}, testOptions)
+	require.NotNil(t, hover)
+	assert.Contains(t, hover.Contents.Value, "**sort_by**")
+
+	assert.Nil(t, HoverAt(text, Position{0, 33}, testOptions)) // string value
+	assert.Nil(t, HoverAt(`unknown = 1`, Position{0, 1}, testOptions))
+
+	aliased := &Options{ParserOptions: &parser.ParserOptions{OptionAliases: map[string]string{"per_page": "page_size"}}}
+	hover = HoverAt("`price` > 1", Position{0, 3}, testOptions)
+	require.NotNil(t, hover)
+	assert.Equal(t, Range{Start: Position{0, 0}, End: Position{0, 7}}, *hover.Range)
+	assert.Contains(t, hover.Contents.Value, "Unit price in USD")
+
+	hover = HoverAt(`per_page = 5`, Position{0, 2}, aliased)
+	require.NotNil(t, hover)
+	assert.Contains(t, hover.Contents.Value, "**page_size**")
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		contains []string
+		excludes []string
+	}{
+		{"start", ``, []string{"featured", "name", "price", "sort_by"}, []string{"AND"}},
+		{"field prefix", `pr`, []string{"price", "preserve_in_order"}, []string{"name"}},
+		{"operators for float", `price `, []string{"=", ">=", "IN", "NOT IN"}, []string{"CONTAINS", "LIKE"}},
+		{"operators for string", `name `, []string{"CONTAINS", "ICONTAINS", "MATCH"}, nil},
+		{"modifiers for array", `tags `, []string{"CONTAINS", "LENGTH", "ANY", "ALL"}, []string{"MATCH"}},
+		{"after LENGTH", `tags LENGTH `, []string{">", "="}, []string{"IN", "ANY"}},
+		{"after ALL", `tags ALL `, []string{"IN"}, []string{"=", "NOT IN"}},
+		{"value after ANY", `tags ANY = "usb" `, []string{"AND"}, nil},
+		{"after NOT", `name NOT `, []string{"LIKE", "IN"}, []string{"CONTAINS", "NOT LIKE"}},
+		{"bool values", `featured = `, []string{"true", "false"}, nil},
+		{"after value", `price > 10 `, []string{"AND", "OR", "sort_by"}, []string{"price"}},
+		{"after AND", `price > 10 AND `, []string{"name"}, []string{"AND"}},
+		{"sort_by value", `sort_by = `, []string{"price", "_score", "_matches"}, []string{"asc"}},
+		{"sort_order value", `sort_order = d`, []string{"desc"}, []string{"asc"}},
+		{"inside list", `name IN ["a", `, nil, []string{"AND", "name"}},
+		{"after list", `name IN ["a"] `, []string{"AND"}, nil},
+		{"after quoted field", "`price` ", []string{">=", "IN"}, []string{"CONTAINS"}},
+		{"after bracket field", `price > 1 ["price"] `, []string{">=", "IN"}, []string{"CONTAINS"}},
+		{"after bracket field value", `["price"] > 1 `, []string{"AND"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := labels(Complete(tt.text, Position{0, len(tt.text)}, testOptions))
+			for _, want := range tt.contains {
+				assert.Contains(t, got, want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, got, unwanted)
+			}
+		})
+	}
+
+	assert.Empty(t, Complete(`name = "unfinished `, Position{0, 19}, testOptions))
+}
+
+func TestDocumentPositions_UTF16(t *testing.T) {
+	// "é" is one UTF-16 unit and two bytes; "😀" is two units and four bytes
+	doc := newDocument("a = \"é😀\"\nb")
+	assert.Equal(t, 11, doc.offset(Position{0, 8}))
+	assert.Equal(t, Position{0, 8}, doc.position(11))
+	assert.Equal(t, Position{1, 1}, doc.position(len(doc.text)))
+	assert.Equal(t, len(doc.text), doc.offset(Position{5, 0}))
+}
@@ -0,0 +1,131 @@
+package lsp
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/hadi77ir/go-query/parser"
+)
+
+// document converts between byte offsets and LSP positions
+type document struct {
+	text       string
+	lineStarts []int
+}
+
+func newDocument(text string) *document {
+	starts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &document{text: text, lineStarts: starts}
+}
+
+// offset returns the byte offset of pos, clamped to the document
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+	i := d.lineStarts[pos.Line]
+	// LSP characters count UTF-16 code units
+	for units := 0; units < pos.Character && i < len(d.text) && d.text[i] != '\n'; {
+		r, width := utf8.DecodeRuneInString(d.text[i:])
+		units += utf16Len(r)
+		i += width
+	}
+	return i
+}
+
+// position returns the LSP position of a byte offset
+func (d *document) position(offset int) Position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	line := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+	character := 0
+	for _, r := range d.text[d.lineStarts[line]:offset] {
+		character += utf16Len(r)
+	}
+	return Position{Line: line, Character: character}
+}
+
+// rangeOf returns the range between two byte offsets
+func (d *document) rangeOf(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// span is a token with its byte range in the source
+type span struct {
+	tok        parser.Token
+	start, end int
+}
+
+// lex tokenizes text up to the first lexer error. The tokens read before the
+// error are returned with it
+func lex(text string, opts *parser.ParserOptions) ([]span, error) {
+	l, err := parser.NewLexerWithOptions(text, opts)
+	if err != nil {
+		return nil, err
+	}
+	var spans []span
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			return spans, err
+		}
+		spans = append(spans, span{tok: tok, start: tok.Pos, end: tokenEnd(text, tok)})
+		if tok.Type == parser.TokenEOF {
+			return spans, nil
+		}
+	}
+}
+
+// tokenEnd returns the byte offset just past a token
+func tokenEnd(text string, tok parser.Token) int {
+	if tok.Type != parser.TokenString && tok.Type != parser.TokenQuotedIdentifier {
+		return tok.Pos + len(tok.Value)
+	}
+	// Strings and quoted fields lose their quotes and escapes; find the closing quote
+	quote := text[tok.Pos]
+	for i := tok.Pos + 1; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && i+1 < len(text) && text[i+1] == quote:
+			i++
+		case text[i] == quote:
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// spanAt returns the token starting at offset
+func spanAt(spans []span, offset int) (span, bool) {
+	for _, s := range spans {
+		if s.start == offset && s.tok.Type != parser.TokenEOF {
+			return s, true
+		}
+	}
+	return span{}, false
+}
+
+// identifierSpan returns the first identifier token naming field
+func identifierSpan(spans []span, field string) (span, bool) {
+	for _, s := range spans {
+		if s.tok.Type == parser.TokenIdentifier && s.tok.Value == field {
+			return s, true
+		}
+	}
+	return span{}, false
+}
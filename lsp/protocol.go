@@ -0,0 +1,69 @@
+package lsp
+
+// The types below are the subset of the Language Server Protocol used by this
+// package. Field names and JSON tags follow the LSP specification so values can
+// be sent to editors (or Monaco providers) unchanged.
+
+// Position is a zero-based line and UTF-16 character offset in a document
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a half-open span between two positions
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// DiagnosticSeverity is the severity of a diagnostic
+type DiagnosticSeverity int
+
+const (
+	// SeverityError marks problems that make the query invalid
+	SeverityError DiagnosticSeverity = 1
+	// SeverityWarning marks suspicious but valid queries
+	SeverityWarning DiagnosticSeverity = 2
+)
+
+// Diagnostic is a problem found in a query
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Source   string             `json:"source"`
+	Message  string             `json:"message"`
+}
+
+// MarkupContent is Markdown shown by the editor
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Hover is the information shown for the token under the cursor
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+	Range    *Range        `json:"range,omitempty"`
+}
+
+// CompletionItemKind classifies completion items for editor icons
+type CompletionItemKind int
+
+const (
+	// CompletionKindKeyword is used for query options and logical keywords
+	CompletionKindKeyword CompletionItemKind = 14
+	// CompletionKindField is used for schema fields
+	CompletionKindField CompletionItemKind = 5
+	// CompletionKindOperator is used for comparison operators
+	CompletionKindOperator CompletionItemKind = 24
+	// CompletionKindValue is used for enumerated values such as asc/desc
+	CompletionKindValue CompletionItemKind = 12
+)
+
+// CompletionItem is a single completion suggestion
+type CompletionItem struct {
+	Label         string             `json:"label"`
+	Kind          CompletionItemKind `json:"kind"`
+	Detail        string             `json:"detail,omitempty"`
+	Documentation string             `json:"documentation,omitempty"`
+}
@@ -0,0 +1,285 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+)
+
+// Server is a Language Server Protocol server for query documents.
+// Documents are synchronized in full; diagnostics are published on every change.
+type Server struct {
+	opts *Options
+
+	mu   sync.Mutex
+	docs map[string]string
+
+	writeMu sync.Mutex
+	w       io.Writer
+}
+
+// NewServer creates a new language server
+func NewServer(opts *Options) *Server {
+	return &Server{opts: resolveOptions(opts), docs: make(map[string]string)}
+}
+
+// message is a JSON-RPC 2.0 request, notification or response
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+	Position     Position         `json:"position"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentItem `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// Serve reads LSP messages from r and writes responses and notifications to w
+// until the client sends exit, r is exhausted or ctx is canceled.
+// ctx is checked between messages.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.w = w
+	reader := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		body, err := readMessage(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.write(message{JSONRPC: "2.0", ID: nullID(), Error: &rpcError{Code: codeParseError, Message: err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification
+func (s *Server) handle(msg *message) error {
+	var result interface{}
+	var rpcErr *rpcError
+	invalid := func(err error) *rpcError {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // Full
+				"hoverProvider":      true,
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{" ", "="}},
+			},
+			"serverInfo": map[string]string{"name": "go-query"},
+		}
+
+	case "shutdown":
+		result = nil
+
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument textDocumentItem `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.setDocument(params.TextDocument.URI, params.TextDocument.Text)
+		return s.publish(params.TextDocument.URI, params.TextDocument.Text)
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		s.setDocument(params.TextDocument.URI, text)
+		return s.publish(params.TextDocument.URI, text)
+
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument textDocumentItem `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.mu.Lock()
+		delete(s.docs, params.TextDocument.URI)
+		s.mu.Unlock()
+		return s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         params.TextDocument.URI,
+			"diagnostics": []Diagnostic{},
+		})
+
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			rpcErr = invalid(err)
+			break
+		}
+		if hover := HoverAt(s.document(params.TextDocument.URI), params.Position, s.opts); hover != nil {
+			result = hover
+		}
+
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			rpcErr = invalid(err)
+			break
+		}
+		items := Complete(s.document(params.TextDocument.URI), params.Position, s.opts)
+		if items == nil {
+			items = []CompletionItem{}
+		}
+		result = items
+
+	default:
+		if msg.ID == nil {
+			// Unknown notifications, including initialized, are ignored
+			return nil
+		}
+		rpcErr = &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+	}
+
+	if msg.ID == nil {
+		return nil
+	}
+	if rpcErr != nil {
+		return s.write(message{JSONRPC: "2.0", ID: msg.ID, Error: rpcErr})
+	}
+	return s.writeResult(msg.ID, result)
+}
+
+func (s *Server) setDocument(uri, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[uri] = text
+}
+
+func (s *Server) document(uri string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.docs[uri]
+}
+
+// publish sends the diagnostics of a document
+func (s *Server) publish(uri, text string) error {
+	diags := Diagnostics(text, s.opts)
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diags,
+	})
+}
+
+// notify sends a notification
+func (s *Server) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// writeResult sends a response; a nil result is sent as null
+func (s *Server) writeResult(id *json.RawMessage, result interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	raw := json.RawMessage(data)
+	return s.write(struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Result  json.RawMessage  `json:"result"`
+	}{"2.0", id, raw})
+}
+
+// write frames and sends a message
+func (s *Server) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = s.w.Write(data)
+	return err
+}
+
+// readMessage reads one Content-Length framed message body
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	return body, nil
+}
+
+func nullID() *json.RawMessage {
+	raw := json.RawMessage("null")
+	return &raw
+}
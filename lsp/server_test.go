@@ -0,0 +1,103 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func frame(t *testing.T, msgs ...interface{}) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		data, err := json.Marshal(msg)
+		require.NoError(t, err)
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	}
+	return &buf
+}
+
+func readAll(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var msgs []map[string]interface{}
+	r := bufio.NewReader(out)
+	for {
+		body, err := readMessage(r)
+		if err != nil {
+			return msgs
+		}
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &msg))
+		msgs = append(msgs, msg)
+	}
+}
+
+func request(id int, method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+}
+
+func notification(method string, params interface{}) map[string]interface{} {
+	return map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+}
+
+func TestServer(t *testing.T) {
+	uri := "file:///saved.gq"
+	doc := map[string]interface{}{"uri": uri}
+	in := frame(t,
+		request(1, "initialize", map[string]interface{}{}),
+		notification("initialized", map[string]interface{}{}),
+		notification("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "text": "price >"},
+		}),
+		notification("textDocument/didChange", map[string]interface{}{
+			"textDocument":   doc,
+			"contentChanges": []map[string]interface{}{{"text": "price > 10 "}},
+		}),
+		request(2, "textDocument/hover", map[string]interface{}{"textDocument": doc, "position": Position{0, 1}}),
+		request(3, "textDocument/completion", map[string]interface{}{"textDocument": doc, "position": Position{0, 11}}),
+		request(4, "workspace/unknown", nil),
+		request(5, "shutdown", nil),
+		notification("exit", nil),
+		request(6, "initialize", nil), // never read
+	)
+
+	var out bytes.Buffer
+	require.NoError(t, NewServer(testOptions).Serve(context.Background(), in, &out))
+	msgs := readAll(t, &out)
+	require.Len(t, msgs, 7)
+
+	capabilities := msgs[0]["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Equal(t, true, capabilities["hoverProvider"])
+
+	// Diagnostics for the incomplete and the fixed document
+	assert.Equal(t, "textDocument/publishDiagnostics", msgs[1]["method"])
+	assert.Len(t, msgs[1]["params"].(map[string]interface{})["diagnostics"], 1)
+	assert.Empty(t, msgs[2]["params"].(map[string]interface{})["diagnostics"])
+
+	hover := msgs[3]["result"].(map[string]interface{})["contents"].(map[string]interface{})
+	assert.True(t, strings.HasPrefix(hover["value"].(string), "**price**"))
+
+	var completion []string
+	for _, item := range msgs[4]["result"].([]interface{}) {
+		completion = append(completion, item.(map[string]interface{})["label"].(string))
+	}
+	assert.Contains(t, completion, "AND")
+
+	assert.Equal(t, float64(codeMethodNotFound), msgs[5]["error"].(map[string]interface{})["code"])
+	assert.Contains(t, msgs[6], "result")
+	assert.Nil(t, msgs[6]["result"])
+}
+
+func TestServer_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := NewServer(nil).Serve(ctx, frame(t, request(1, "initialize", nil)), &bytes.Buffer{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
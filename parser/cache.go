@@ -22,6 +22,7 @@ type ParserCache struct {
 	mu      sync.RWMutex
 	cache   map[string]*cacheEntry
 	maxSize int
+	opts    *ParserOptions
 	now     func() time.Time // For testing
 }
 
@@ -35,6 +36,14 @@ func NewParserCache(maxSize int) *ParserCache {
 	}
 }
 
+// NewParserCacheWithOptions creates a new parser cache whose cache misses are
+// parsed with the given parser options
+func NewParserCacheWithOptions(maxSize int, opts *ParserOptions) *ParserCache {
+	c := NewParserCache(maxSize)
+	c.opts = opts
+	return c
+}
+
 // Parse parses the query string, checking the cache first
 // If cache miss, calls the parser and stores the result
 // Returns (*query.Query, error)
@@ -66,7 +75,7 @@ func (c *ParserCache) Parse(queryStr string) (*query.Query, error) {
 
 // parseDirect parses a query string without using cache
 func (c *ParserCache) parseDirect(queryStr string) (*query.Query, error) {
-	parser, err := NewParser(queryStr)
+	parser, err := NewParserWithOptions(queryStr, c.opts)
 	if err != nil {
 		return nil, err
 	}
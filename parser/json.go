@@ -0,0 +1,361 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// ParseJSON parses a structured JSON query into the same AST the string parser produces.
+// Programmatic clients can build queries as data instead of concatenating strings.
+//
+// The document is either a filter node or an object with a "filter" node and query options:
+//
+//	{
+//	  "filter": {"and": [
+//	    {"field": "price", "op": ">", "value": 10},
+//	    {"or": [
+//	      {"field": "brand", "op": "IN", "value": ["Anker", "Sony"]},
+//	      {"search": "wireless"}
+//	    ]}
+//	  ]},
+//	  "sort_by": "price", "sort_order": "desc", "page_size": 20, "limit": 100
+//	}
+//
+// The options are sort_by, sort_order, page_size, page, limit, preserve_in_order,
+// include_deleted, distinct, distinct_on and random_seed.
+//
+// Nodes are {"and": [...]}, {"or": [...]}, {"field", "op", "value"} comparisons and
+// {"search": "term"} bare searches, or {"phrase": "usb cable"} for quoted ones. Comparisons on array fields take an optional
+// "modifier" of "LENGTH", "ANY" or "ALL", e.g.
+// {"field": "tags", "modifier": "ANY", "op": "=", "value": "usb"}. Values are JSON
+// strings, numbers (integers without a fraction or exponent become IntValue),
+// booleans, arrays, {"$date": "2024-01-15T10:30:00Z"}, relative times such as
+// {"$date": "now-7d"}, {"$placeholder": "current_user"} for @current_user and
+// {"$param": "min_price"} for :min_price. Unknown keys are rejected.
+func ParseJSON(data []byte) (*query.Query, error) {
+	var doc map[string]json.RawMessage
+	if err := decodeJSON(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON query: %v", err)
+	}
+
+	q := &query.Query{
+		PageSize:  10, // default
+		SortOrder: query.SortOrderAsc,
+	}
+
+	// A document without "filter" and options is a bare filter node
+	_, hasFilter := doc["filter"]
+	if !hasFilter && !hasQueryOptions(doc) {
+		if len(doc) == 0 {
+			return q, nil
+		}
+		filter, err := parseJSONNode(doc, "filter")
+		if err != nil {
+			return nil, err
+		}
+		q.Filter = filter
+		return q, nil
+	}
+
+	for _, key := range sortedKeys(doc) {
+		raw := doc[key]
+		switch key {
+		case "filter":
+			var node map[string]json.RawMessage
+			if err := decodeJSON(raw, &node); err != nil {
+				return nil, fmt.Errorf("filter: expected object: %v", err)
+			}
+			if len(node) == 0 {
+				continue
+			}
+			filter, err := parseJSONNode(node, "filter")
+			if err != nil {
+				return nil, err
+			}
+			q.Filter = filter
+		case "sort_by":
+			if err := decodeJSON(raw, &q.SortBy); err != nil {
+				return nil, fmt.Errorf("sort_by: expected string")
+			}
+		case "sort_order":
+			var order string
+			if err := decodeJSON(raw, &order); err != nil {
+				return nil, fmt.Errorf("sort_order: expected string")
+			}
+			q.SortOrder = query.ParseSortOrder(order)
+		case "page_size":
+			if err := decodeJSON(raw, &q.PageSize); err != nil {
+				return nil, fmt.Errorf("invalid page_size: %s", raw)
+			}
+		case "page":
+			if err := decodeJSON(raw, &q.Page); err != nil {
+				return nil, fmt.Errorf("invalid page: %s", raw)
+			}
+			if q.Page < 1 {
+				return nil, fmt.Errorf("page must be at least 1, got: %d", q.Page)
+			}
+		case "limit":
+			if err := decodeJSON(raw, &q.Limit); err != nil {
+				return nil, fmt.Errorf("invalid limit: %s", raw)
+			}
+			if q.Limit < 0 {
+				return nil, fmt.Errorf("limit must be non-negative, got: %d", q.Limit)
+			}
+		case "preserve_in_order":
+			if err := decodeJSON(raw, &q.PreserveInOrder); err != nil {
+				return nil, fmt.Errorf("invalid preserve_in_order: %s", raw)
+			}
+		case "include_deleted":
+			if err := decodeJSON(raw, &q.IncludeDeleted); err != nil {
+				return nil, fmt.Errorf("invalid include_deleted: %s", raw)
+			}
+		case "distinct":
+			if err := decodeJSON(raw, &q.Distinct); err != nil {
+				return nil, fmt.Errorf("invalid distinct: %s", raw)
+			}
+		case "distinct_on":
+			if err := decodeJSON(raw, &q.DistinctOn); err != nil {
+				return nil, fmt.Errorf("distinct_on: expected string")
+			}
+		case "random_seed":
+			if err := decodeJSON(raw, &q.RandomSeed); err != nil {
+				return nil, fmt.Errorf("invalid random_seed: %s", raw)
+			}
+		default:
+			return nil, fmt.Errorf("unknown query key %q", key)
+		}
+	}
+	return q, nil
+}
+
+// hasQueryOptions reports whether a document contains top-level query options
+func hasQueryOptions(doc map[string]json.RawMessage) bool {
+	for _, key := range []string{"sort_by", "sort_order", "page_size", "page", "limit", "preserve_in_order", "include_deleted", "distinct", "distinct_on", "random_seed"} {
+		if _, ok := doc[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseJSONNode parses a filter node; path locates the node in error messages
+func parseJSONNode(node map[string]json.RawMessage, path string) (query.Node, error) {
+	if raw, ok := node["and"]; ok {
+		return parseJSONLogical(node, raw, query.BinaryOpAnd, path+".and")
+	}
+	if raw, ok := node["or"]; ok {
+		return parseJSONLogical(node, raw, query.BinaryOpOr, path+".or")
+	}
+	for _, key := range []string{"search", "phrase"} {
+		raw, ok := node[key]
+		if !ok {
+			continue
+		}
+		if len(node) != 1 {
+			return nil, fmt.Errorf("%s: %s node cannot have other keys", path, key)
+		}
+		var term string
+		if err := decodeJSON(raw, &term); err != nil || term == "" {
+			return nil, fmt.Errorf("%s.%s: expected non-empty string", path, key)
+		}
+		return &query.ComparisonNode{
+			Field:    query.SearchField,
+			Operator: query.OpContains,
+			Value:    query.StringValue(term),
+			Phrase:   key == "phrase",
+		}, nil
+	}
+	return parseJSONComparison(node, path)
+}
+
+// parseJSONLogical parses an and/or node, folding operands left to right like the string parser
+func parseJSONLogical(node map[string]json.RawMessage, raw json.RawMessage, op query.BinaryOperator, path string) (query.Node, error) {
+	if len(node) != 1 {
+		return nil, fmt.Errorf("%s: logical node cannot have other keys", path)
+	}
+	var operands []map[string]json.RawMessage
+	if err := decodeJSON(raw, &operands); err != nil {
+		return nil, fmt.Errorf("%s: expected array of nodes", path)
+	}
+	if len(operands) == 0 {
+		return nil, fmt.Errorf("%s: expected at least one node", path)
+	}
+
+	var result query.Node
+	for i, operand := range operands {
+		child, err := parseJSONNode(operand, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = child
+			continue
+		}
+		result = &query.BinaryOpNode{Operator: op, Left: result, Right: child}
+	}
+	return result, nil
+}
+
+// parseJSONComparison parses a {"field", "op", "value"} node with an optional "modifier"
+func parseJSONComparison(node map[string]json.RawMessage, path string) (query.Node, error) {
+	for key := range node {
+		if key != "field" && key != "op" && key != "value" && key != "modifier" {
+			return nil, fmt.Errorf("%s: unknown key %q", path, key)
+		}
+	}
+
+	var field, op string
+	if err := decodeJSON(node["field"], &field); err != nil || field == "" {
+		return nil, fmt.Errorf("%s.field: expected non-empty string", path)
+	}
+	if err := decodeJSON(node["op"], &op); err != nil {
+		return nil, fmt.Errorf("%s.op: expected string", path)
+	}
+	op = strings.ToUpper(strings.Join(strings.Fields(op), " "))
+	if !query.IsValidOperator(op) {
+		return nil, fmt.Errorf("%s.op: unknown operator %q", path, op)
+	}
+	operator := query.ParseComparisonOperator(op)
+
+	modifier := query.ArrayModifierNone
+	if raw, ok := node["modifier"]; ok {
+		var name string
+		if err := decodeJSON(raw, &name); err != nil {
+			return nil, fmt.Errorf("%s.modifier: expected string", path)
+		}
+		if modifier, ok = query.ParseArrayModifier(name); !ok {
+			return nil, fmt.Errorf("%s.modifier: unknown modifier %q", path, name)
+		}
+	}
+
+	rawValue, ok := node["value"]
+	if !ok {
+		return nil, fmt.Errorf("%s.value: missing", path)
+	}
+	value, err := parseJSONValue(rawValue, path+".value")
+	if err != nil {
+		return nil, err
+	}
+
+	_, isArray := value.(query.ArrayValue)
+	if (operator == query.OpIn || operator == query.OpNotIn) != isArray {
+		if isArray {
+			return nil, fmt.Errorf("%s.value: array values require IN or NOT IN", path)
+		}
+		return nil, fmt.Errorf("%s.value: %s requires an array", path, operator)
+	}
+
+	comparison := &query.ComparisonNode{Field: field, Operator: operator, Value: value, Modifier: modifier}
+	if err := query.ValidateArrayCondition(comparison); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return comparison, nil
+}
+
+// parseJSONValue converts a JSON literal into a query value
+func parseJSONValue(raw json.RawMessage, path string) (interface{}, error) {
+	var v interface{}
+	if err := decodeJSON(raw, &v); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	switch val := v.(type) {
+	case string:
+		return query.StringValue(val), nil
+	case bool:
+		return query.BoolValue(val), nil
+	case json.Number:
+		s := val.String()
+		if !strings.ContainsAny(s, ".eE") {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return query.IntValue(i), nil
+			}
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid number: %s", path, s)
+		}
+		return query.FloatValue(f), nil
+	case []interface{}:
+		var elems []json.RawMessage
+		if err := decodeJSON(raw, &elems); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		arr := make(query.ArrayValue, 0, len(elems))
+		for i, elem := range elems {
+			ev, err := parseJSONValue(elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			if _, nested := ev.(query.ArrayValue); nested {
+				return nil, fmt.Errorf("%s[%d]: nested arrays are not supported", path, i)
+			}
+			arr = append(arr, ev)
+		}
+		return arr, nil
+	case map[string]interface{}:
+		if name, ok := val["$placeholder"].(string); ok && len(val) == 1 {
+			if name == "" {
+				return nil, fmt.Errorf("%s: empty placeholder name", path)
+			}
+			return query.PlaceholderValue(name), nil
+		}
+		if name, ok := val["$param"].(string); ok && len(val) == 1 {
+			if name == "" {
+				return nil, fmt.Errorf("%s: empty parameter name", path)
+			}
+			return query.ParameterValue(name), nil
+		}
+		date, ok := val["$date"].(string)
+		if !ok || len(val) != 1 {
+			return nil, fmt.Errorf(`%s: objects must be {"$date": "..."}, {"$placeholder": "..."} or {"$param": "..."}`, path)
+		}
+		if rel, ok := query.ParseRelativeTime(date); ok {
+			return rel, nil
+		}
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			if t, err = parseDateTime(date); err != nil {
+				return nil, fmt.Errorf("%s: invalid date %q", path, date)
+			}
+		}
+		return query.DateTimeValue(t), nil
+	case nil:
+		return nil, fmt.Errorf("%s: null is not supported", path)
+	default:
+		return nil, fmt.Errorf("%s: unsupported value", path)
+	}
+}
+
+// decodeJSON decodes a single JSON value, keeping numbers as json.Number
+func decodeJSON(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("missing value")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// sortedKeys returns map keys in a stable order for deterministic errors
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	query "github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON_EquivalentToDSL(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		dsl  string
+	}{
+		{
+			name: "single comparison",
+			json: `{"field": "category", "op": "=", "value": "electronics"}`,
+			dsl:  `category = electronics`,
+		},
+		{
+			name: "and with nested or",
+			json: `{"and": [
+				{"field": "price", "op": ">", "value": 10},
+				{"or": [
+					{"field": "brand", "op": "in", "value": ["Anker", "Sony"]},
+					{"search": "wireless"}
+				]}
+			]}`,
+			dsl: `price > 10 AND (brand IN [Anker, Sony] OR wireless)`,
+		},
+		{
+			name: "phrase",
+			json: `{"and": [{"phrase": "usb cable"}, {"search": "hub"}]}`,
+			dsl:  `"usb cable" hub`,
+		},
+		{
+			name: "operands fold left",
+			json: `{"or": [
+				{"field": "a", "op": "=", "value": 1},
+				{"field": "b", "op": "=", "value": 2.5},
+				{"field": "c", "op": "=", "value": true}
+			]}`,
+			dsl: `a = 1 OR b = 2.5 OR c = true`,
+		},
+		{
+			name: "word operators",
+			json: `{"and": [
+				{"field": "name", "op": "not  like", "value": "%refurb%"},
+				{"field": "sku", "op": "NOT IN", "value": [1, 2]},
+				{"field": "description", "op": "MATCH", "value": "noise cancelling"}
+			]}`,
+			dsl: `name NOT LIKE "%refurb%" AND sku NOT IN [1, 2] AND description MATCH "noise cancelling"`,
+		},
+		{
+			name: "filter with options",
+			json: `{
+				"filter": {"field": "stock", "op": ">=", "value": 0},
+				"sort_by": "price", "sort_order": "desc", "page_size": 25, "limit": 100
+			}`,
+			dsl: `stock >= 0 sort_by = price sort_order = desc page_size = 25 limit = 100`,
+		},
+		{
+			name: "preserve_in_order",
+			json: `{"filter": {"field": "id", "op": "IN", "value": [5, 1, 9]}, "preserve_in_order": true}`,
+			dsl:  `id IN [5, 1, 9] preserve_in_order = true`,
+		},
+		{
+			name: "include_deleted",
+			json: `{"filter": {"field": "status", "op": "=", "value": "archived"}, "include_deleted": true}`,
+			dsl:  `status = archived include_deleted = true`,
+		},
+		{
+			name: "distinct",
+			json: `{"filter": {"field": "status", "op": "=", "value": "active"}, "distinct": true, "distinct_on": "email"}`,
+			dsl:  `status = active distinct = true distinct_on = email`,
+		},
+		{
+			name: "options only",
+			json: `{"sort_order": "random"}`,
+			dsl:  `sort_order = random`,
+		},
+		{
+			name: "page",
+			json: `{"page_size": 20, "page": 3}`,
+			dsl:  `page_size = 20 page = 3`,
+		},
+		{
+			name: "random seed",
+			json: `{"sort_order": "random", "random_seed": 42}`,
+			dsl:  `sort_order = random random_seed = 42`,
+		},
+		{
+			name: "empty document",
+			json: `{}`,
+			dsl:  ``,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSON([]byte(tt.json))
+			require.NoError(t, err)
+
+			p, err := NewParser(tt.dsl)
+			require.NoError(t, err)
+			want, err := p.Parse()
+			require.NoError(t, err)
+
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestParseJSON_Values(t *testing.T) {
+	q, err := ParseJSON([]byte(`{"and": [
+		{"field": "created_at", "op": ">", "value": {"$date": "2024-01-15T10:30:00Z"}},
+		{"field": "views", "op": "<", "value": 1e3},
+		{"field": "id", "op": "=", "value": 9007199254740993}
+	]}`))
+	require.NoError(t, err)
+
+	root := q.Filter.(*query.BinaryOpNode)
+	left := root.Left.(*query.BinaryOpNode)
+
+	created := left.Left.(*query.ComparisonNode).Value
+	assert.Equal(t, query.DateTimeValue(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)), created)
+	assert.Equal(t, query.FloatValue(1000), left.Right.(*query.ComparisonNode).Value)
+	// Large integers keep full precision
+	assert.Equal(t, query.IntValue(9007199254740993), root.Right.(*query.ComparisonNode).Value)
+}
+
+func TestParseJSON_RelativeTime(t *testing.T) {
+	q, err := ParseJSON([]byte(`{"field": "created_at", "op": ">", "value": {"$date": "now-7d"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, query.RelativeTimeValue("now-7d"), q.Filter.(*query.ComparisonNode).Value)
+
+	_, err = ParseJSON([]byte(`{"field": "created_at", "op": ">", "value": {"$date": "now-7x"}}`))
+	assert.ErrorContains(t, err, `invalid date "now-7x"`)
+}
+
+func TestParseJSON_Placeholders(t *testing.T) {
+	q, err := ParseJSON([]byte(`{"field": "owner_id", "op": "=", "value": {"$placeholder": "current_user"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, query.PlaceholderValue("current_user"), q.Filter.(*query.ComparisonNode).Value)
+
+	_, err = ParseJSON([]byte(`{"field": "owner_id", "op": "=", "value": {"$placeholder": ""}}`))
+	assert.ErrorContains(t, err, "empty placeholder name")
+
+	q, err = ParseJSON([]byte(`{"field": "price", "op": ">", "value": {"$param": "min_price"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, query.ParameterValue("min_price"), q.Filter.(*query.ComparisonNode).Value)
+
+	_, err = ParseJSON([]byte(`{"field": "price", "op": ">", "value": {"$param": ""}}`))
+	assert.ErrorContains(t, err, "empty parameter name")
+}
+
+func TestParseJSON_ArrayModifier(t *testing.T) {
+	q, err := ParseJSON([]byte(`{"field": "tags", "modifier": "any", "op": "=", "value": "wireless"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &query.ComparisonNode{
+		Field: "tags", Operator: query.OpEqual, Value: query.StringValue("wireless"), Modifier: query.ArrayModifierAny,
+	}, q.Filter)
+
+	q, err = ParseJSON([]byte(`{"field": "tags", "modifier": "LENGTH", "op": ">", "value": 3}`))
+	require.NoError(t, err)
+	assert.Equal(t, query.ArrayModifierLength, q.Filter.(*query.ComparisonNode).Modifier)
+}
+
+func TestParseJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		errText string
+	}{
+		{"malformed", `{"field": `, "invalid JSON query"},
+		{"not an object", `[1, 2]`, "invalid JSON query"},
+		{"trailing data", `{} {}`, "invalid JSON query"},
+		{"unknown operator", `{"field": "a", "op": "SOUNDS_LIKE", "value": 1}`, `filter.op: unknown operator "SOUNDS_LIKE"`},
+		{"missing field", `{"op": "=", "value": 1}`, "filter.field: expected non-empty string"},
+		{"missing value", `{"field": "a", "op": "="}`, "filter.value: missing"},
+		{"null value", `{"field": "a", "op": "=", "value": null}`, "null is not supported"},
+		{"unknown node key", `{"field": "a", "op": "=", "value": 1, "boost": 2}`, `filter: unknown key "boost"`},
+		{"unknown query key", `{"filter": {"search": "x"}, "offset": 2}`, `unknown query key "offset"`},
+		{"empty and", `{"and": []}`, "filter.and: expected at least one node"},
+		{"mixed logical node", `{"and": [{"search": "x"}], "or": []}`, "logical node cannot have other keys"},
+		{"nested path", `{"and": [{"search": "x"}, {"or": [{"field": "a", "op": "=", "value": {}}]}]}`, "filter.and[1].or[0].value"},
+		{"array without IN", `{"field": "a", "op": "=", "value": [1]}`, "array values require IN or NOT IN"},
+		{"IN without array", `{"field": "a", "op": "IN", "value": 1}`, "IN requires an array"},
+		{"nested array", `{"field": "a", "op": "IN", "value": [[1]]}`, "nested arrays are not supported"},
+		{"invalid date", `{"field": "a", "op": ">", "value": {"$date": "yesterday"}}`, `invalid date "yesterday"`},
+		{"unknown modifier", `{"field": "tags", "modifier": "SOME", "op": "=", "value": "a"}`, `filter.modifier: unknown modifier "SOME"`},
+		{"ALL without IN", `{"field": "tags", "modifier": "ALL", "op": "=", "value": "a"}`, "ALL must be followed by IN"},
+		{"negative limit", `{"limit": -1}`, "limit must be non-negative"},
+		{"invalid page_size", `{"page_size": "ten"}`, "invalid page_size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSON([]byte(tt.json))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // TokenType represents the type of token
@@ -32,8 +33,49 @@ const (
 	TokenIn
 	TokenNotIn
 	TokenNot
+	TokenMatch
+	TokenPlaceholder
+	TokenQuotedIdentifier
+	TokenParameter
 )
 
+var tokenNames = [...]string{
+	TokenEOF:              "end of input",
+	TokenIdentifier:       "identifier",
+	TokenString:           "string",
+	TokenNumber:           "number",
+	TokenOperator:         "operator",
+	TokenAnd:              "AND",
+	TokenOr:               "OR",
+	TokenLeftParen:        "'('",
+	TokenRightParen:       "')'",
+	TokenComma:            "','",
+	TokenLeftBracket:      "'['",
+	TokenRightBracket:     "']'",
+	TokenLike:             "LIKE",
+	TokenNotLike:          "NOT LIKE",
+	TokenContains:         "CONTAINS",
+	TokenIContains:        "ICONTAINS",
+	TokenStartsWith:       "STARTS_WITH",
+	TokenEndsWith:         "ENDS_WITH",
+	TokenRegex:            "REGEX",
+	TokenIn:               "IN",
+	TokenNotIn:            "NOT IN",
+	TokenNot:              "NOT",
+	TokenMatch:            "MATCH",
+	TokenPlaceholder:      "placeholder",
+	TokenQuotedIdentifier: "quoted identifier",
+	TokenParameter:        "parameter",
+}
+
+// String returns the name of the token type used in error messages
+func (t TokenType) String() string {
+	if t >= 0 && int(t) < len(tokenNames) {
+		return tokenNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
 // Token represents a lexical token
 type Token struct {
 	Type  TokenType
@@ -41,11 +83,28 @@ type Token struct {
 	Pos   int
 }
 
+// keywords maps lowercase keywords to their token types
+var keywords = map[string]TokenType{
+	"and":         TokenAnd,
+	"or":          TokenOr,
+	"not":         TokenNot,
+	"like":        TokenLike,
+	"contains":    TokenContains,
+	"icontains":   TokenIContains,
+	"starts_with": TokenStartsWith,
+	"ends_with":   TokenEndsWith,
+	"regex":       TokenRegex,
+	"in":          TokenIn,
+	"match":       TokenMatch,
+}
+
 // Lexer tokenizes the input query string
 type Lexer struct {
-	input string
-	pos   int
-	ch    rune
+	input   string
+	pos     int // byte offset of the next character
+	chPos   int // byte offset of ch
+	ch      rune
+	aliases map[string]string // lowercase alias -> canonical keyword
 }
 
 // NewLexer creates a new lexer for the given input
@@ -55,14 +114,31 @@ func NewLexer(input string) *Lexer {
 	return l
 }
 
-// readChar reads the next character
+// NewLexerWithOptions creates a new lexer that recognizes the keyword aliases in opts.
+// nil options behave like NewLexer.
+func NewLexerWithOptions(input string, opts *ParserOptions) (*Lexer, error) {
+	l := NewLexer(input)
+	if opts != nil {
+		aliases, err := opts.resolveAliases()
+		if err != nil {
+			return nil, err
+		}
+		l.aliases = aliases
+	}
+	return l, nil
+}
+
+// readChar reads the next character, decoding UTF-8
 func (l *Lexer) readChar() {
+	l.chPos = l.pos
 	if l.pos >= len(l.input) {
 		l.ch = 0
-	} else {
-		l.ch = rune(l.input[l.pos])
+		l.pos++
+		return
 	}
-	l.pos++
+	r, width := utf8.DecodeRuneInString(l.input[l.pos:])
+	l.ch = r
+	l.pos += width
 }
 
 // peekChar looks at the next character without advancing
@@ -70,7 +146,8 @@ func (l *Lexer) peekChar() rune {
 	if l.pos >= len(l.input) {
 		return 0
 	}
-	return rune(l.input[l.pos])
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+	return r
 }
 
 // skipWhitespace skips over whitespace characters
@@ -80,11 +157,41 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
+// skipWhitespaceAndComments skips whitespace, line comments (# ...) and block comments (/* ... */)
+// Comments are skipped in place, so token positions still refer to the original input
+func (l *Lexer) skipWhitespaceAndComments() error {
+	for {
+		l.skipWhitespace()
+		switch {
+		case l.ch == '#':
+			for l.ch != '\n' && l.ch != 0 {
+				l.readChar()
+			}
+		case l.ch == '/' && l.peekChar() == '*':
+			startPos := l.chPos
+			l.readChar()
+			l.readChar()
+			for !(l.ch == '*' && l.peekChar() == '/') {
+				if l.ch == 0 {
+					return fmt.Errorf("unterminated block comment at position %d", startPos)
+				}
+				l.readChar()
+			}
+			l.readChar() // consume '*'
+			l.readChar() // consume '/'
+		default:
+			return nil
+		}
+	}
+}
+
 // NextToken returns the next token from the input
 func (l *Lexer) NextToken() (Token, error) {
-	l.skipWhitespace()
+	if err := l.skipWhitespaceAndComments(); err != nil {
+		return Token{}, err
+	}
 
-	startPos := l.pos - 1
+	startPos := l.chPos
 
 	switch l.ch {
 	case 0:
@@ -111,19 +218,25 @@ func (l *Lexer) NextToken() (Token, error) {
 		return tok, nil
 	case '"', '\'':
 		return l.readString()
+	case '`':
+		return l.readQuotedIdentifier()
 	case '=', '!', '>', '<':
 		return l.readOperator()
+	case '@':
+		return l.readNamed(TokenPlaceholder, "placeholder")
+	case ':':
+		return l.readNamed(TokenParameter, "parameter")
 	default:
-		if unicode.IsLetter(l.ch) || l.ch == '_' {
+		if isIdentStart(l.ch) {
 			return l.readIdentifier()
 		}
-		if unicode.IsDigit(l.ch) {
+		if isDigit(l.ch) {
 			return l.readNumber()
 		}
 		if l.ch == '-' {
 			// Look ahead to determine if this is a negative number or part of an identifier
 			next := l.peekChar()
-			if unicode.IsDigit(next) {
+			if isDigit(next) {
 				return l.readNumber()
 			}
 			// Otherwise treat it as part of identifier (for dates like 2020-01-03-0415)
@@ -134,48 +247,52 @@ func (l *Lexer) NextToken() (Token, error) {
 }
 
 // readIdentifier reads an identifier or keyword
+// Dots are allowed after the first character for nested and relation fields (author.name)
 func (l *Lexer) readIdentifier() (Token, error) {
-	startPos := l.pos - 1
+	startPos := l.chPos
 	var sb strings.Builder
 
-	for unicode.IsLetter(l.ch) || unicode.IsDigit(l.ch) || l.ch == '_' || l.ch == ':' || l.ch == '-' {
+	// '+' continues relative times such as now+1h
+	for isIdentPart(l.ch) || l.ch == ':' || l.ch == '-' || l.ch == '.' || l.ch == '+' {
 		sb.WriteRune(l.ch)
 		l.readChar()
 	}
 
 	value := sb.String()
 	lowerValue := strings.ToLower(value)
-	
+	if canonical, ok := l.aliases[lowerValue]; ok {
+		lowerValue = canonical
+	}
+
 	// Check for keywords
-	switch lowerValue {
-	case "and":
-		return Token{Type: TokenAnd, Value: value, Pos: startPos}, nil
-	case "or":
-		return Token{Type: TokenOr, Value: value, Pos: startPos}, nil
-	case "not":
-		return Token{Type: TokenNot, Value: value, Pos: startPos}, nil
-	case "like":
-		return Token{Type: TokenLike, Value: value, Pos: startPos}, nil
-	case "contains":
-		return Token{Type: TokenContains, Value: value, Pos: startPos}, nil
-	case "icontains":
-		return Token{Type: TokenIContains, Value: value, Pos: startPos}, nil
-	case "starts_with":
-		return Token{Type: TokenStartsWith, Value: value, Pos: startPos}, nil
-	case "ends_with":
-		return Token{Type: TokenEndsWith, Value: value, Pos: startPos}, nil
-	case "regex":
-		return Token{Type: TokenRegex, Value: value, Pos: startPos}, nil
-	case "in":
-		return Token{Type: TokenIn, Value: value, Pos: startPos}, nil
+	if tokType, ok := keywords[lowerValue]; ok {
+		return Token{Type: tokType, Value: value, Pos: startPos}, nil
 	}
-	
+
 	return Token{Type: TokenIdentifier, Value: value, Pos: startPos}, nil
 }
 
+// readNamed reads a context placeholder (@name) or a parameter (:name); the
+// token value is the name
+func (l *Lexer) readNamed(typ TokenType, kind string) (Token, error) {
+	startPos := l.chPos
+	sigil := l.ch
+	l.readChar()
+
+	var sb strings.Builder
+	for isIdentPart(l.ch) {
+		sb.WriteRune(l.ch)
+		l.readChar()
+	}
+	if sb.Len() == 0 {
+		return Token{}, fmt.Errorf("expected %s name after '%c' at position %d", kind, sigil, startPos)
+	}
+	return Token{Type: typ, Value: sb.String(), Pos: startPos}, nil
+}
+
 // readNumber reads a number token
 func (l *Lexer) readNumber() (Token, error) {
-	startPos := l.pos - 1
+	startPos := l.chPos
 	var sb strings.Builder
 
 	// Handle negative numbers
@@ -184,16 +301,17 @@ func (l *Lexer) readNumber() (Token, error) {
 		l.readChar()
 	}
 
-	for unicode.IsDigit(l.ch) || l.ch == '.' {
+	for isDigit(l.ch) || l.ch == '.' {
 		sb.WriteRune(l.ch)
 		l.readChar()
 	}
 
 	// Check if this might be a date/datetime (e.g., 2020-01-03 or 2020-01-03-0415)
 	// If we see a hyphen followed by digits, continue reading as identifier
-	if l.ch == '-' && unicode.IsDigit(l.peekChar()) {
-		// This looks like a date, switch to identifier mode
-		for unicode.IsLetter(l.ch) || unicode.IsDigit(l.ch) || l.ch == '_' || l.ch == ':' || l.ch == '-' {
+	if l.ch == '-' && isDigit(l.peekChar()) {
+		// This looks like a date, switch to identifier mode; '.' allows
+		// fractional seconds as in 2024-01-02T03:04:05.25
+		for isIdentPart(l.ch) || l.ch == ':' || l.ch == '-' || l.ch == '.' {
 			sb.WriteRune(l.ch)
 			l.readChar()
 		}
@@ -205,7 +323,7 @@ func (l *Lexer) readNumber() (Token, error) {
 
 // readString reads a quoted string
 func (l *Lexer) readString() (Token, error) {
-	startPos := l.pos - 1
+	startPos := l.chPos
 	quote := l.ch
 	l.readChar()
 
@@ -229,9 +347,24 @@ func (l *Lexer) readString() (Token, error) {
 	return Token{Type: TokenString, Value: sb.String(), Pos: startPos}, nil
 }
 
+// readQuotedIdentifier reads a backtick-quoted field name such as `order date`;
+// the token value is the name without quotes
+func (l *Lexer) readQuotedIdentifier() (Token, error) {
+	startPos := l.chPos
+	tok, err := l.readString()
+	if err != nil {
+		return Token{}, fmt.Errorf("unterminated quoted identifier at position %d", startPos)
+	}
+	if tok.Value == "" {
+		return Token{}, fmt.Errorf("empty quoted identifier at position %d", startPos)
+	}
+	tok.Type = TokenQuotedIdentifier
+	return tok, nil
+}
+
 // readOperator reads an operator token
 func (l *Lexer) readOperator() (Token, error) {
-	startPos := l.pos - 1
+	startPos := l.chPos
 	var sb strings.Builder
 
 	sb.WriteRune(l.ch)
@@ -261,3 +394,22 @@ func (l *Lexer) AllTokens() ([]Token, error) {
 	}
 	return tokens, nil
 }
+
+// isDigit reports whether r is an ASCII digit. Only ASCII digits start
+// numbers; other scripts' digits are read as part of identifiers
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isIdentStart reports whether r can start an identifier: a letter in any
+// script, a letter number such as Ⅻ, '_' or a non-ASCII digit such as ۱
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Nl, r) || r == '_' || (r > unicode.MaxASCII && unicode.IsDigit(r))
+}
+
+// isIdentPart reports whether r can continue an identifier. Besides letters
+// and digits this allows combining marks, as in हिन्दी, and the zero-width
+// joiners used inside Persian and Indic words
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '\u200c' || r == '\u200d'
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"testing"
 
+	"github.com/hadi77ir/go-query/query"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -160,6 +161,7 @@ func TestLexer_Strings(t *testing.T) {
 		{"single quotes", `name = 'Jane'`, "Jane"},
 		{"with spaces", `text = "hello world"`, "hello world"},
 		{"escaped quotes", `text = "He said \"hi\""`, `He said "hi"`},
+		{"unicode", `name = "Müller – café"`, "Müller – café"},
 	}
 
 	for _, tt := range tests {
@@ -192,6 +194,77 @@ func TestLexer_Errors(t *testing.T) {
 	}
 }
 
+func TestLexer_UnicodeIdentifiers(t *testing.T) {
+	tokens, err := NewLexer(`größe = groß AND ö = 1`).AllTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 8)
+	assert.Equal(t, Token{Type: TokenIdentifier, Value: "größe", Pos: 0}, tokens[0])
+	assert.Equal(t, Token{Type: TokenIdentifier, Value: "groß", Pos: 10}, tokens[2])
+	// Positions are byte offsets into the input
+	assert.Equal(t, Token{Type: TokenAnd, Value: "AND", Pos: 16}, tokens[3])
+}
+
+func TestLexer_UnicodeScripts(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token
+	}{
+		{"persian", `نام = علی`, []Token{{TokenIdentifier, "نام", 0}, {TokenOperator, "=", 7}, {TokenIdentifier, "علی", 9}}},
+		{"japanese", `日本語 コーヒー`, []Token{{TokenIdentifier, "日本語", 0}, {TokenIdentifier, "コーヒー", 10}}},
+		{"zero-width non-joiner", "می\u200cخواهم", []Token{{TokenIdentifier, "می\u200cخواهم", 0}}},
+		{"combining marks", `हिन्दी`, []Token{{TokenIdentifier, "हिन्दी", 0}}},
+		{"letter number", `Ⅻ`, []Token{{TokenIdentifier, "Ⅻ", 0}}},
+		{"non-ASCII digits are identifiers", `کد = ۱۲۳`, []Token{{TokenIdentifier, "کد", 0}, {TokenOperator, "=", 5}, {TokenIdentifier, "۱۲۳", 7}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := NewLexer(tt.input).AllTokens()
+			require.NoError(t, err)
+			assert.Equal(t, append(tt.want, Token{Type: TokenEOF, Pos: len(tt.input)}), tokens)
+		})
+	}
+}
+
+func TestLexer_DottedIdentifiers(t *testing.T) {
+	tokens, err := NewLexer(`author.name = "Alice" AND version = v1.2`).AllTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 8)
+	assert.Equal(t, Token{Type: TokenIdentifier, Value: "author.name", Pos: 0}, tokens[0])
+	assert.Equal(t, Token{Type: TokenIdentifier, Value: "v1.2", Pos: 36}, tokens[6])
+}
+
+func TestLexer_QuotedIdentifiers(t *testing.T) {
+	tokens, err := NewLexer("`order date` = 1 AND `a\\`b` = 2").AllTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 8)
+	assert.Equal(t, Token{Type: TokenQuotedIdentifier, Value: "order date", Pos: 0}, tokens[0])
+	assert.Equal(t, Token{Type: TokenQuotedIdentifier, Value: "a`b", Pos: 21}, tokens[4])
+
+	_, err = NewLexer("`open").AllTokens()
+	assert.ErrorContains(t, err, "unterminated quoted identifier at position 0")
+	_, err = NewLexer("a = 1 ``").AllTokens()
+	assert.ErrorContains(t, err, "empty quoted identifier at position 6")
+}
+
+func TestLexer_Placeholders(t *testing.T) {
+	tokens, err := NewLexer(`owner_id = @current_user`).AllTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 4)
+	assert.Equal(t, Token{Type: TokenPlaceholder, Value: "current_user", Pos: 11}, tokens[2])
+}
+
+func TestLexer_Parameters(t *testing.T) {
+	tokens, err := NewLexer(`price > :min_price`).AllTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 4)
+	assert.Equal(t, Token{Type: TokenParameter, Value: "min_price", Pos: 8}, tokens[2])
+
+	_, err = NewLexer(`price > : 1`).AllTokens()
+	assert.ErrorContains(t, err, "expected parameter name after ':' at position 8")
+}
+
 func TestLexer_ComplexQuery(t *testing.T) {
 	input := `tag=account:123 and (created_at >= 2020-01-03-0415 or updated_at >= 2020-01-03-0415)`
 	lexer := NewLexer(input)
@@ -202,3 +275,64 @@ func TestLexer_ComplexQuery(t *testing.T) {
 	assert.Greater(t, len(tokens), 10)
 	assert.Equal(t, TokenEOF, tokens[len(tokens)-1].Type)
 }
+
+func TestLexer_Comments(t *testing.T) {
+	t.Run("line comments", func(t *testing.T) {
+		input := "# active users only\nstatus = active # trailing comment\nAND age > 18"
+		tokens, err := NewLexer(input).AllTokens()
+		require.NoError(t, err)
+
+		var types []TokenType
+		for _, tok := range tokens {
+			types = append(types, tok.Type)
+		}
+		assert.Equal(t, []TokenType{
+			TokenIdentifier, TokenOperator, TokenIdentifier,
+			TokenAnd, TokenIdentifier, TokenOperator, TokenNumber, TokenEOF,
+		}, types)
+	})
+
+	t.Run("block comments keep positions", func(t *testing.T) {
+		input := "status /* must be active */ = active"
+		tokens, err := NewLexer(input).AllTokens()
+		require.NoError(t, err)
+		require.Len(t, tokens, 4)
+		assert.Equal(t, TokenOperator, tokens[1].Type)
+		assert.Equal(t, 28, tokens[1].Pos)
+		assert.Equal(t, "active", tokens[2].Value)
+	})
+
+	t.Run("comment markers inside strings are preserved", func(t *testing.T) {
+		tokens, err := NewLexer(`tag = "#1 /* not a comment */"`).AllTokens()
+		require.NoError(t, err)
+		assert.Equal(t, "#1 /* not a comment */", tokens[2].Value)
+	})
+
+	t.Run("unterminated block comment", func(t *testing.T) {
+		_, err := NewLexer("status = active /* oops").AllTokens()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unterminated block comment at position 16")
+	})
+}
+
+func TestParser_Comments(t *testing.T) {
+	input := `
+# Saved search: featured electronics
+category = electronics   # main category
+/* only featured items,
+   newest first */
+featured = true
+sort_by = created_at sort_order = desc
+`
+	p, err := NewParser(input)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	and, ok := q.Filter.(*query.BinaryOpNode)
+	require.True(t, ok)
+	assert.Equal(t, "category", and.Left.(*query.ComparisonNode).Field)
+	assert.Equal(t, "featured", and.Right.(*query.ComparisonNode).Field)
+	assert.Equal(t, "created_at", q.SortBy)
+	assert.Equal(t, query.SortOrderDesc, q.SortOrder)
+}
@@ -0,0 +1,504 @@
+// Package odata parses OData $filter expressions and system query options into
+// the same query AST the string parser produces, so APIs that document OData
+// can run on go-query executors without changing their syntax:
+//
+//	// GET /products?$filter=price lt 10 and contains(name,'usb')&$orderby=price desc&$top=20
+//	q, err := odata.Parse(r.URL.Query())
+//	result, err := executor.Execute(ctx, q, "", &products)
+//
+// The supported subset of $filter is:
+//
+//   - eq, ne, gt, ge, lt and le comparisons of a property with a literal
+//   - in with a list, e.g. brand in ('Anker','Sony')
+//   - contains, startswith, endswith and matchesPattern, and contains on
+//     tolower(property) for case-insensitive matches
+//   - and, or, not and parentheses
+//   - string literals in single quotes, with quotes inside doubled, and
+//     integer, decimal, true/false, date (2024-01-15) and date-time
+//     (2024-01-15T10:30:00Z) literals
+//
+// Property paths such as address/city become dotted fields (address.city).
+// not is applied by negating the operators beneath it, e.g. not (price lt 10)
+// becomes price >= 10; conditions without an inverse, such as matchesPattern,
+// cannot be negated. null, arithmetic and lambda operators are not supported.
+package odata
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Parse converts the OData system query options of a URL query into a query:
+// $filter into the filter, $orderby (one property, optionally followed by asc
+// or desc) into the sort, $top into the page size and $skip, which must be a
+// multiple of $top, into the page. Parameters not starting with $ are ignored;
+// other system query options are rejected
+func Parse(values url.Values) (*query.Query, error) {
+	q := &query.Query{
+		PageSize:  10, // default
+		SortOrder: query.SortOrderAsc,
+	}
+	skip := 0
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "$") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		vals := values[key]
+		if len(vals) != 1 {
+			return nil, fmt.Errorf("%s: expected one value", key)
+		}
+		val := strings.TrimSpace(vals[0])
+		switch key {
+		case "$filter":
+			filter, err := ParseFilter(val)
+			if err != nil {
+				return nil, err
+			}
+			q.Filter = filter
+		case "$orderby":
+			if strings.Contains(val, ",") {
+				return nil, fmt.Errorf("$orderby: sorting by more than one property is not supported")
+			}
+			parts := strings.Fields(val)
+			if len(parts) == 0 || len(parts) > 2 {
+				return nil, fmt.Errorf("$orderby: expected a property and an optional direction, got %q", val)
+			}
+			q.SortBy = fieldName(parts[0])
+			if len(parts) == 2 {
+				switch strings.ToLower(parts[1]) {
+				case "asc":
+					q.SortOrder = query.SortOrderAsc
+				case "desc":
+					q.SortOrder = query.SortOrderDesc
+				default:
+					return nil, fmt.Errorf("$orderby: unknown direction %q", parts[1])
+				}
+			}
+		case "$top":
+			top, err := strconv.Atoi(val)
+			if err != nil || top < 1 {
+				return nil, fmt.Errorf("$top: expected a positive integer, got %q", val)
+			}
+			q.PageSize = top
+		case "$skip":
+			var err error
+			skip, err = strconv.Atoi(val)
+			if err != nil || skip < 0 {
+				return nil, fmt.Errorf("$skip: expected a non-negative integer, got %q", val)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported OData option %s", key)
+		}
+	}
+	if skip > 0 {
+		if skip%q.PageSize != 0 {
+			return nil, fmt.Errorf("$skip: %d is not a multiple of the page size %d", skip, q.PageSize)
+		}
+		q.Page = skip/q.PageSize + 1
+	}
+	return q, nil
+}
+
+// ParseFilter parses an OData $filter expression into a filter node.
+// An empty expression returns a nil filter
+func ParseFilter(filter string) (query.Node, error) {
+	tokens, err := lex(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return node, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a $filter expression into words (names, keywords and unquoted
+// literals), quoted strings, parentheses and commas
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case r == '\'':
+			// Quotes inside strings are doubled: 'it''s'
+			start := i
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			tokens = append(tokens, token{tokenString, sb.String(), start})
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+// isWordRune reports whether r can appear in a property path or an unquoted
+// literal such as -1.5e3 or 2024-01-15T10:30:00Z
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_./-+:", r)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword reports whether the next token is the keyword and consumes it if so
+func (p *parser) keyword(name string) bool {
+	if tok := p.peek(); tok.kind == tokenWord && strings.EqualFold(tok.text, name) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if tok := p.next(); tok.kind != kind {
+		return fmt.Errorf("expected %q at position %d", text, tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (query.Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &query.BinaryOpNode{Operator: query.BinaryOpOr, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (query.Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &query.BinaryOpNode{Operator: query.BinaryOpAnd, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (query.Node, error) {
+	if tok := p.peek(); p.keyword("not") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		negated, err := negate(node)
+		if err != nil {
+			return nil, fmt.Errorf("not at position %d: %w", tok.pos, err)
+		}
+		return negated, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (query.Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case tokenWord:
+		if p.peek().kind == tokenLParen {
+			return p.parseFunction(tok)
+		}
+		return p.parseComparison(tok)
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of filter")
+	default:
+		return nil, fmt.Errorf("expected a property or function at position %d, got %q", tok.pos, tok.text)
+	}
+}
+
+// comparisonOperators maps the OData comparison operators to query operators
+var comparisonOperators = map[string]query.ComparisonOperator{
+	"eq": query.OpEqual,
+	"ne": query.OpNotEqual,
+	"gt": query.OpGreaterThan,
+	"ge": query.OpGreaterThanOrEqual,
+	"lt": query.OpLessThan,
+	"le": query.OpLessThanOrEqual,
+}
+
+// parseComparison parses "property op literal" and "property in (literals)"
+func (p *parser) parseComparison(property token) (query.Node, error) {
+	field := fieldName(property.text)
+	opTok := p.next()
+	if opTok.kind != tokenWord {
+		return nil, fmt.Errorf("expected an operator after %q at position %d", property.text, opTok.pos)
+	}
+	name := strings.ToLower(opTok.text)
+	if name == "in" {
+		if err := p.expect(tokenLParen, "("); err != nil {
+			return nil, err
+		}
+		var values query.ArrayValue
+		for {
+			value, err := p.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return &query.ComparisonNode{Field: field, Operator: query.OpIn, Value: values}, nil
+	}
+	op, ok := comparisonOperators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown operator %q at position %d", opTok.text, opTok.pos)
+	}
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	return &query.ComparisonNode{Field: field, Operator: op, Value: value}, nil
+}
+
+// functionOperators maps the supported boolean functions to query operators
+var functionOperators = map[string]query.ComparisonOperator{
+	"contains":       query.OpContains,
+	"startswith":     query.OpStartsWith,
+	"endswith":       query.OpEndsWith,
+	"matchespattern": query.OpRegex,
+}
+
+// parseFunction parses a boolean function call such as contains(name,'usb')
+func (p *parser) parseFunction(fn token) (query.Node, error) {
+	op, ok := functionOperators[strings.ToLower(fn.text)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported function %q at position %d", fn.text, fn.pos)
+	}
+	p.next() // (
+	property := p.next()
+	if property.kind != tokenWord {
+		return nil, fmt.Errorf("expected a property in %s at position %d", fn.text, property.pos)
+	}
+	// contains(tolower(name),'usb') matches ignoring case
+	if strings.EqualFold(property.text, "tolower") && p.peek().kind == tokenLParen {
+		if op != query.OpContains {
+			return nil, fmt.Errorf("tolower is only supported in contains, at position %d", property.pos)
+		}
+		op = query.OpIContains
+		p.next()
+		if property = p.next(); property.kind != tokenWord {
+			return nil, fmt.Errorf("expected a property in tolower at position %d", property.pos)
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect(tokenComma, ","); err != nil {
+		return nil, err
+	}
+	arg := p.next()
+	if arg.kind != tokenString {
+		return nil, fmt.Errorf("%s expects a string at position %d", fn.text, arg.pos)
+	}
+	if err := p.expect(tokenRParen, ")"); err != nil {
+		return nil, err
+	}
+	value := arg.text
+	if op == query.OpIContains {
+		value = strings.ToLower(value)
+	}
+	return &query.ComparisonNode{Field: fieldName(property.text), Operator: op, Value: query.StringValue(value)}, nil
+}
+
+// parseLiteral parses a string, number, boolean, date or date-time literal
+func (p *parser) parseLiteral() (interface{}, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return query.StringValue(tok.text), nil
+	case tokenWord:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return query.BoolValue(true), nil
+		case "false":
+			return query.BoolValue(false), nil
+		case "null":
+			return nil, fmt.Errorf("null at position %d is not supported", tok.pos)
+		}
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return query.IntValue(i), nil
+		}
+		if f, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			return query.FloatValue(f), nil
+		}
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, tok.text); err == nil {
+				return query.DateTimeValue(t), nil
+			}
+		}
+		return nil, fmt.Errorf("invalid literal %q at position %d", tok.text, tok.pos)
+	default:
+		return nil, fmt.Errorf("expected a literal at position %d", tok.pos)
+	}
+}
+
+// fieldName converts an OData property path to a dotted field name
+func fieldName(path string) string {
+	return strings.ReplaceAll(path, "/", ".")
+}
+
+// negatedOperators maps the operators that have an inverse to it
+var negatedOperators = map[query.ComparisonOperator]query.ComparisonOperator{
+	query.OpEqual:              query.OpNotEqual,
+	query.OpNotEqual:           query.OpEqual,
+	query.OpGreaterThan:        query.OpLessThanOrEqual,
+	query.OpGreaterThanOrEqual: query.OpLessThan,
+	query.OpLessThan:           query.OpGreaterThanOrEqual,
+	query.OpLessThanOrEqual:    query.OpGreaterThan,
+	query.OpIn:                 query.OpNotIn,
+	query.OpNotIn:              query.OpIn,
+	query.OpLike:               query.OpNotLike,
+	query.OpNotLike:            query.OpLike,
+}
+
+// negate returns the inverse of node by De Morgan's laws and inverted
+// operators. contains, startswith and endswith become NOT LIKE
+func negate(node query.Node) (query.Node, error) {
+	switch n := node.(type) {
+	case *query.BinaryOpNode:
+		left, err := negate(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := negate(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		op := query.BinaryOpOr
+		if n.Operator == query.BinaryOpOr {
+			op = query.BinaryOpAnd
+		}
+		return &query.BinaryOpNode{Operator: op, Left: left, Right: right}, nil
+	case *query.ComparisonNode:
+		negated := *n
+		if op, ok := negatedOperators[n.Operator]; ok {
+			negated.Operator = op
+			return &negated, nil
+		}
+		value, _ := n.Value.(query.StringValue)
+		if strings.ContainsAny(string(value), "%_") {
+			return nil, fmt.Errorf("cannot negate %s %q, which contains a LIKE wildcard", n.Operator, value)
+		}
+		switch n.Operator {
+		case query.OpContains:
+			negated.Value = "%" + value + "%"
+		case query.OpStartsWith:
+			negated.Value = value + "%"
+		case query.OpEndsWith:
+			negated.Value = "%" + value
+		default:
+			return nil, fmt.Errorf("cannot negate %s", n.Operator)
+		}
+		negated.Operator = query.OpNotLike
+		return &negated, nil
+	}
+	return nil, fmt.Errorf("cannot negate %T", node)
+}
@@ -0,0 +1,88 @@
+package odata
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{"comparison", `price lt 10`, `price < 10`},
+		{"logical", `price ge 1.5 and (brand eq 'Sony' or featured eq true)`, `price >= 1.5 AND (brand = "Sony" OR featured = true)`},
+		{"precedence", `a eq 1 or b eq 2 and c eq 3`, `a = 1 OR (b = 2 AND c = 3)`},
+		{"in", `brand in ('Anker','Sony')`, `brand IN ["Anker", "Sony"]`},
+		{"functions", `contains(name,'usb') and startswith(sku,'A-') and endswith(name,'hub')`, `(name CONTAINS "usb" AND sku STARTS_WITH "A-") AND name ENDS_WITH "hub"`},
+		{"tolower", `contains(tolower(name),'USB')`, `name ICONTAINS "usb"`},
+		{"matchesPattern", `matchesPattern(sku,'^A[0-9]+$')`, `sku REGEX "^A[0-9]+$"`},
+		{"escaped quote", `name eq 'it''s'`, `name = "it's"`},
+		{"path", `address/city eq 'Berlin'`, `address.city = "Berlin"`},
+		{"date", `created_at gt 2024-01-15T10:30:00Z`, `created_at > 2024-01-15T10:30:00`},
+		{"not", `not (price lt 10 or brand in ('Sony'))`, `price >= 10 AND brand NOT IN ["Sony"]`},
+		{"not contains", `not contains(name,'usb')`, `name NOT LIKE "%usb%"`},
+		{"empty", ``, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, query.FormatFilter(node))
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, filter := range []string{
+		`price lt`,
+		`price lt 10 and`,
+		`price between 1`,
+		`(price lt 10`,
+		`name eq 'open`,
+		`name eq null`,
+		`length(name) gt 3`,
+		`not matchesPattern(name,'a')`,
+		`not contains(name,'50%')`,
+		`price lt 10 price`,
+	} {
+		_, err := ParseFilter(filter)
+		assert.Error(t, err, filter)
+	}
+}
+
+func TestParse(t *testing.T) {
+	q, err := Parse(url.Values{
+		"$filter":  {"price lt 10"},
+		"$orderby": {"price desc"},
+		"$top":     {"20"},
+		"$skip":    {"40"},
+		"api_key":  {"ignored"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `price < 10`, query.FormatFilter(q.Filter))
+	assert.Equal(t, "price", q.SortBy)
+	assert.Equal(t, query.SortOrderDesc, q.SortOrder)
+	assert.Equal(t, 20, q.PageSize)
+	assert.Equal(t, 3, q.Page)
+
+	q, err = Parse(url.Values{})
+	require.NoError(t, err)
+	assert.Nil(t, q.Filter)
+	assert.Equal(t, 10, q.PageSize)
+
+	for _, values := range []url.Values{
+		{"$skip": {"5"}},
+		{"$orderby": {"price, name"}},
+		{"$top": {"0"}},
+		{"$expand": {"orders"}},
+	} {
+		_, err := Parse(values)
+		assert.Error(t, err, values)
+	}
+}
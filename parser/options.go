@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// ParserOptions configures a Parser
+type ParserOptions struct {
+	// KeywordAliases maps extra words to the canonical keyword they stand for,
+	// e.g. {"y": "and", "o": "or", "contiene": "contains"}. Matching is
+	// case-insensitive and the parsed AST is the same as for the canonical keyword.
+	//
+	// Valid canonical keywords are and, or, not, like, contains, icontains,
+	// starts_with, ends_with, regex, in and match. An alias becomes a reserved
+	// word, so it can no longer be used as a field name or bare search term;
+	// quote it to search for the literal word.
+	KeywordAliases map[string]string
+
+	// OptionAliases maps extra names to the canonical query option they stand
+	// for, e.g. {"per_page": "page_size", "orden": "sort_order"}. Matching is
+	// case-insensitive and the canonical names keep working.
+	//
+	// Valid canonical options are sort_by, sort_order, page_size, page, limit,
+	// preserve_in_order, include_deleted, distinct, distinct_on and
+	// random_seed. An alias
+	// followed by = is read as the option, so it can no longer be used as a
+	// field name in an equality comparison.
+	OptionAliases map[string]string
+
+	// DisableImplicitAnd rejects adjacent terms that are not joined by AND or
+	// OR, so "a = 1 b = 2" is an error instead of "a = 1 AND b = 2". Query
+	// options may still appear anywhere.
+	DisableImplicitAnd bool
+
+	// DisableBareSearch rejects bare search terms, both quoted strings and
+	// identifiers without an operator, instead of matching them against the
+	// default search field.
+	DisableBareSearch bool
+
+	// BareTermOperator joins bare search terms that follow each other without
+	// AND or OR. The default, query.BinaryOpAnd, requires every term to match.
+	// With query.BinaryOpOr items matching any of the terms are returned, as
+	// most search engines do, and the terms are grouped before they combine
+	// with field conditions: "brand = Sony usb cable" is
+	// brand = Sony AND (usb OR cable).
+	// Terms joined by an explicit AND are not grouped
+	BareTermOperator query.BinaryOperator
+
+	// GroupBareTerms joins the bare search terms of a condition chain with
+	// BareTermOperator even when field conditions separate them, so
+	// "usb brand = Sony cable" is (usb OR cable) AND brand = Sony with
+	// BareTermOperator OR. The group sits where its first term is; an explicit
+	// AND starts a new group
+	GroupBareTerms bool
+}
+
+// queryOptions lists the canonical query option names
+var queryOptions = map[string]bool{
+	"sort_by":           true,
+	"sort_order":        true,
+	"page_size":         true,
+	"page":              true,
+	"limit":             true,
+	"preserve_in_order": true,
+	"include_deleted":   true,
+	"distinct":          true,
+	"distinct_on":       true,
+	"random_seed":       true,
+}
+
+// resolveAliases validates the keyword aliases and returns them keyed by lowercase alias
+func (o *ParserOptions) resolveAliases() (map[string]string, error) {
+	if len(o.KeywordAliases) == 0 {
+		return nil, nil
+	}
+
+	aliases := make(map[string]string, len(o.KeywordAliases))
+	for alias, keyword := range o.KeywordAliases {
+		lowerAlias := strings.ToLower(alias)
+		canonical := strings.ToLower(keyword)
+
+		if _, ok := keywords[canonical]; !ok {
+			return nil, fmt.Errorf("invalid keyword alias %q: unknown keyword %q", alias, keyword)
+		}
+		if !isAliasWord(lowerAlias) {
+			return nil, fmt.Errorf("invalid keyword alias %q: must be a single word", alias)
+		}
+		if _, ok := keywords[lowerAlias]; ok && lowerAlias != canonical {
+			return nil, fmt.Errorf("invalid keyword alias %q: already a keyword", alias)
+		}
+		if existing, ok := aliases[lowerAlias]; ok && existing != canonical {
+			return nil, fmt.Errorf("invalid keyword alias %q: conflicting keywords %q and %q", alias, existing, canonical)
+		}
+		aliases[lowerAlias] = canonical
+	}
+	return aliases, nil
+}
+
+// resolveOptionAliases validates the option aliases and returns them keyed by lowercase alias
+func (o *ParserOptions) resolveOptionAliases() (map[string]string, error) {
+	if len(o.OptionAliases) == 0 {
+		return nil, nil
+	}
+
+	aliases := make(map[string]string, len(o.OptionAliases))
+	for alias, option := range o.OptionAliases {
+		lowerAlias := strings.ToLower(alias)
+		canonical := strings.ToLower(option)
+
+		if !queryOptions[canonical] {
+			return nil, fmt.Errorf("invalid option alias %q: unknown option %q", alias, option)
+		}
+		if !isAliasWord(lowerAlias) {
+			return nil, fmt.Errorf("invalid option alias %q: must be a single word", alias)
+		}
+		if _, ok := keywords[lowerAlias]; ok {
+			return nil, fmt.Errorf("invalid option alias %q: already a keyword", alias)
+		}
+		if queryOptions[lowerAlias] && lowerAlias != canonical {
+			return nil, fmt.Errorf("invalid option alias %q: already an option", alias)
+		}
+		if existing, ok := aliases[lowerAlias]; ok && existing != canonical {
+			return nil, fmt.Errorf("invalid option alias %q: conflicting options %q and %q", alias, existing, canonical)
+		}
+		aliases[lowerAlias] = canonical
+	}
+	return aliases, nil
+}
+
+// isAliasWord reports whether s would be read by the lexer as a single identifier
+func isAliasWord(s string) bool {
+	for i, r := range s {
+		if i == 0 && !isIdentStart(r) {
+			return false
+		}
+		if !isIdentPart(r) {
+			return false
+		}
+	}
+	return s != ""
+}
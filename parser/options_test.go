@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseWithOptions(t *testing.T, input string, opts *ParserOptions) *query.Query {
+	t.Helper()
+	p, err := NewParserWithOptions(input, opts)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	return q
+}
+
+func TestParser_KeywordAliases(t *testing.T) {
+	spanish := &ParserOptions{KeywordAliases: map[string]string{
+		"y":        "and",
+		"o":        "or",
+		"no":       "not",
+		"como":     "like",
+		"contiene": "contains",
+		"en":       "in",
+	}}
+
+	tests := []struct {
+		name      string
+		localized string
+		canonical string
+	}{
+		{"logical", `precio > 10 y (marca = Sony o marca = Anker)`, `precio > 10 and (marca = Sony or marca = Anker)`},
+		{"case-insensitive", `a = 1 Y b = 2 O c = 3`, `a = 1 AND b = 2 OR c = 3`},
+		{"string operator", `nombre CONTIENE "auriculares"`, `nombre CONTAINS "auriculares"`},
+		{"negated operators", `nombre no como "%usado%" y id no en [1, 2]`, `nombre NOT LIKE "%usado%" AND id NOT IN [1, 2]`},
+		{"canonical keywords still work", `a = 1 AND b CONTAINS x`, `a = 1 AND b CONTAINS x`},
+		{"quoted alias is a literal", `nombre = "y"`, `nombre = "y"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseWithOptions(t, tt.localized, spanish)
+			want := parseWithOptions(t, tt.canonical, nil)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("non-latin alias", func(t *testing.T) {
+		opts := &ParserOptions{KeywordAliases: map[string]string{"и": "and", "содержит": "contains"}}
+		got := parseWithOptions(t, `имя СОДЕРЖИТ кофе и цена < 5`, opts)
+		want := parseWithOptions(t, `имя CONTAINS кофе AND цена < 5`, nil)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("aliases are not active by default", func(t *testing.T) {
+		q := parseWithOptions(t, `a = 1 y`, nil)
+		root, ok := q.Filter.(*query.BinaryOpNode)
+		require.True(t, ok)
+		assert.Equal(t, "__DEFAULT_SEARCH__", root.Right.(*query.ComparisonNode).Field)
+	})
+}
+
+func TestParser_KeywordAliasErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases map[string]string
+		errText string
+	}{
+		{"unknown keyword", map[string]string{"y": "xor"}, `unknown keyword "xor"`},
+		{"operator symbol", map[string]string{"y": "="}, `unknown keyword "="`},
+		{"multiple words", map[string]string{"no como": "like"}, "must be a single word"},
+		{"empty alias", map[string]string{"": "and"}, "must be a single word"},
+		{"redefines keyword", map[string]string{"or": "and"}, "already a keyword"},
+		{"conflicting case variants", map[string]string{"Y": "and", "y": "or"}, "conflicting keywords"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParserWithOptions("a = 1", &ParserOptions{KeywordAliases: tt.aliases})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+}
+
+func TestParser_OptionAliases(t *testing.T) {
+	opts := &ParserOptions{OptionAliases: map[string]string{
+		"per_page": "page_size",
+		"orden":    "sort_order",
+		"ordenar":  "sort_by",
+	}}
+
+	got := parseWithOptions(t, `nombre = cafe PER_PAGE=5 ordenar=precio orden=desc`, opts)
+	want := parseWithOptions(t, `nombre = cafe page_size=5 sort_by=precio sort_order=desc`, nil)
+	assert.Equal(t, want, got)
+
+	t.Run("canonical options still work", func(t *testing.T) {
+		q := parseWithOptions(t, `page_size=7`, opts)
+		assert.Equal(t, 7, q.PageSize)
+	})
+
+	t.Run("aliases are not active by default", func(t *testing.T) {
+		q := parseWithOptions(t, `per_page=5`, nil)
+		assert.Equal(t, 10, q.PageSize)
+		assert.Equal(t, "per_page", q.Filter.(*query.ComparisonNode).Field)
+	})
+}
+
+func TestParser_OptionAliasErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases map[string]string
+		errText string
+	}{
+		{"unknown option", map[string]string{"skip": "offset"}, `unknown option "offset"`},
+		{"multiple words", map[string]string{"per page": "page_size"}, "must be a single word"},
+		{"keyword", map[string]string{"and": "limit"}, "already a keyword"},
+		{"redefines option", map[string]string{"limit": "page_size"}, "already an option"},
+		{"conflicting case variants", map[string]string{"Max": "limit", "max": "page_size"}, "conflicting options"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParserWithOptions("a = 1", &ParserOptions{OptionAliases: tt.aliases})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+}
+
+func TestParser_DisableImplicitAnd(t *testing.T) {
+	opts := &ParserOptions{DisableImplicitAnd: true}
+
+	got := parseWithOptions(t, `a = 1 AND (b = 2 OR c = 3) page_size=5`, opts)
+	assert.Equal(t, parseWithOptions(t, `a = 1 AND (b = 2 OR c = 3) page_size=5`, nil), got)
+
+	for _, input := range []string{`a = 1 b = 2`, `a = 1 (b = 2)`, `a = 1 "hello"`, `(a = 1 b = 2) OR c = 3`} {
+		t.Run(input, func(t *testing.T) {
+			p, err := NewParserWithOptions(input, opts)
+			require.NoError(t, err)
+			_, err = p.Parse()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "expected AND or OR")
+		})
+	}
+}
+
+func TestParser_DisableBareSearch(t *testing.T) {
+	opts := &ParserOptions{DisableBareSearch: true}
+
+	got := parseWithOptions(t, `name CONTAINS hello AND a = 1`, opts)
+	assert.Equal(t, parseWithOptions(t, `name CONTAINS hello AND a = 1`, nil), got)
+
+	tests := []struct {
+		input   string
+		errText string
+	}{
+		{`hello`, `bare search term "hello" at position 0`},
+		{`"hello world"`, `bare search term "hello world" at position 0`},
+		{`a = 1 AND hello`, `bare search term "hello" at position 10`},
+		{`a = 1 OR (b = 2 "x")`, `bare search term "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := NewParserWithOptions(tt.input, opts)
+			require.NoError(t, err)
+			_, err = p.Parse()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errText)
+		})
+	}
+}
+
+func TestParser_BareTermOperator(t *testing.T) {
+	format := func(input string, opts *ParserOptions) string {
+		return query.FormatFilter(parseWithOptions(t, input, opts).Filter)
+	}
+	or := &ParserOptions{BareTermOperator: query.BinaryOpOr}
+	grouped := &ParserOptions{BareTermOperator: query.BinaryOpOr, GroupBareTerms: true}
+
+	// The default is unchanged
+	assert.Equal(t, `(usb AND cable) AND brand = "Sony"`, format(`usb cable brand = Sony`, nil))
+
+	tests := []struct {
+		input string
+		opts  *ParserOptions
+		want  string
+	}{
+		{`usb cable`, or, `usb OR cable`},
+		{`brand = Sony usb cable`, or, `brand = "Sony" AND (usb OR cable)`},
+		{`usb brand = Sony cable`, or, `(usb AND brand = "Sony") AND cable`},
+		{`usb AND cable hdmi`, or, `usb AND (cable OR hdmi)`},
+		{`usb cable OR price < 5`, or, `(usb OR cable) OR price < 5`},
+		{`usb brand = Sony cable`, grouped, `(usb OR cable) AND brand = "Sony"`},
+		{`usb brand = Sony AND cable hdmi`, grouped, `(usb AND brand = "Sony") AND (cable OR hdmi)`},
+		{`usb brand = Sony cable`, &ParserOptions{GroupBareTerms: true}, `(usb AND cable) AND brand = "Sony"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, format(tt.input, tt.opts))
+		})
+	}
+}
+
+func TestParserCache_WithOptions(t *testing.T) {
+	cache := NewParserCacheWithOptions(10, &ParserOptions{KeywordAliases: map[string]string{"und": "and"}})
+	q, err := cache.Parse(`a = 1 und b = 2`)
+	require.NoError(t, err)
+	assert.Equal(t, parseWithOptions(t, `a = 1 AND b = 2`, nil), q)
+}
+
+func TestLexer_KeywordAliases(t *testing.T) {
+	l, err := NewLexerWithOptions(`a y b`, &ParserOptions{KeywordAliases: map[string]string{"y": "and"}})
+	require.NoError(t, err)
+	tokens, err := l.AllTokens()
+	require.NoError(t, err)
+	assert.Equal(t, TokenAnd, tokens[1].Type)
+	assert.Equal(t, "y", tokens[1].Value)
+
+	_, err = NewLexerWithOptions(`a`, &ParserOptions{KeywordAliases: map[string]string{"y": "unknown"}})
+	assert.Error(t, err)
+}
@@ -14,11 +14,36 @@ type Parser struct {
 	lexer   *Lexer
 	curTok  Token
 	peekTok Token
+
+	optionAliases      map[string]string
+	disableImplicitAnd bool
+	disableBareSearch  bool
+	bareTermOperator   query.BinaryOperator
+	groupBareTerms     bool
 }
 
 // NewParser creates a new parser for the given input
 func NewParser(input string) (*Parser, error) {
-	p := &Parser{lexer: NewLexer(input)}
+	return NewParserWithOptions(input, nil)
+}
+
+// NewParserWithOptions creates a new parser for the given input using the given options.
+// nil options behave like NewParser.
+func NewParserWithOptions(input string, opts *ParserOptions) (*Parser, error) {
+	lexer, err := NewLexerWithOptions(input, opts)
+	if err != nil {
+		return nil, err
+	}
+	p := &Parser{lexer: lexer}
+	if opts != nil {
+		if p.optionAliases, err = opts.resolveOptionAliases(); err != nil {
+			return nil, err
+		}
+		p.disableImplicitAnd = opts.DisableImplicitAnd
+		p.disableBareSearch = opts.DisableBareSearch
+		p.bareTermOperator = opts.BareTermOperator
+		p.groupBareTerms = opts.GroupBareTerms
+	}
 
 	// Read two tokens to initialize curTok and peekTok
 	if err := p.nextToken(); err != nil {
@@ -59,6 +84,24 @@ func (p *Parser) Parse() (*query.Query, error) {
 	return q, nil
 }
 
+// ParseFilter parses a query string that contains only a filter expression,
+// such as a server-side ExecutorOptions.BaseFilter. Query options like sort_by
+// or page_size are rejected because they have no meaning in a filter.
+func ParseFilter(input string) (query.Node, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if q.SortBy != "" || q.SortOrder != query.SortOrderAsc || q.PageSize != 10 || q.Page != 0 || q.Limit != 0 || q.PreserveInOrder || q.IncludeDeleted || q.Distinct || q.DistinctOn != "" || q.RandomSeed != 0 {
+		return nil, fmt.Errorf("filter cannot contain query options: %s", input)
+	}
+	return q.Filter, nil
+}
+
 // parseExpressionWithOptions parses an expression while extracting query options
 func (p *Parser) parseExpressionWithOptions(q *query.Query) (query.Node, error) {
 	return p.parseOrExpressionWithOptions(q)
@@ -140,6 +183,7 @@ func (p *Parser) parseAndExpressionWithOptions(q *query.Query) (query.Node, erro
 		return nil, nil
 	}
 
+	chain := p.newTermChain(left)
 	for {
 		// Try to extract query option first
 		if extracted, err := p.tryExtractQueryOption(q); err != nil {
@@ -167,38 +211,91 @@ func (p *Parser) parseAndExpressionWithOptions(q *query.Query) (query.Node, erro
 			if err != nil {
 				return nil, err
 			}
-			left = &query.BinaryOpNode{
-				Operator: query.BinaryOpAnd,
-				Left:     left,
-				Right:    right,
-			}
+			chain.add(right, false)
 			continue
 		}
 
 		// Implicit AND - if we encounter another term without OR/AND/EOF/), treat it as AND
-		if p.curTok.Type == TokenIdentifier || p.curTok.Type == TokenString || p.curTok.Type == TokenLeftParen {
+		if startsTerm(p.curTok) {
 			// But not if we're at the end or before a closing paren or explicit OR
 			if p.curTok.Type == TokenRightParen || p.curTok.Type == TokenEOF {
 				break
 			}
+			if p.disableImplicitAnd {
+				return nil, fmt.Errorf("expected AND or OR at position %d", p.curTok.Pos)
+			}
 
 			// Parse the next comparison with implicit AND
 			right, err := p.parseComparisonWithOptions(q)
 			if err != nil {
 				return nil, err
 			}
-			left = &query.BinaryOpNode{
-				Operator: query.BinaryOpAnd,
-				Left:     left,
-				Right:    right,
-			}
+			chain.add(right, true)
 			continue
 		}
 
 		break
 	}
 
-	return left, nil
+	return chain.node(), nil
+}
+
+// termChain collects the operands of an AND chain, joining bare search terms
+// that are not separated by an explicit AND with the BareTermOperator
+type termChain struct {
+	operands []query.Node
+	// group is the index of the operand bare terms join, or -1
+	group int
+	op    query.BinaryOperator
+	// grouping is set when bare terms are grouped rather than ANDed in turn
+	grouping bool
+	// acrossFields keeps the group open across field conditions
+	acrossFields bool
+}
+
+func (p *Parser) newTermChain(first query.Node) *termChain {
+	c := &termChain{
+		group:        -1,
+		op:           p.bareTermOperator,
+		grouping:     p.bareTermOperator == query.BinaryOpOr || p.groupBareTerms,
+		acrossFields: p.groupBareTerms,
+	}
+	c.add(first, false)
+	return c
+}
+
+// add appends an operand; implicit is set when it followed the previous
+// operand without AND
+func (c *termChain) add(node query.Node, implicit bool) {
+	if !c.grouping {
+		c.operands = append(c.operands, node)
+		return
+	}
+	if !implicit {
+		c.group = -1
+	}
+	if !query.IsSearch(node) {
+		if !c.acrossFields {
+			c.group = -1
+		}
+		c.operands = append(c.operands, node)
+		return
+	}
+	if c.group >= 0 {
+		c.operands[c.group] = &query.BinaryOpNode{Operator: c.op, Left: c.operands[c.group], Right: node}
+		return
+	}
+	c.group = len(c.operands)
+	c.operands = append(c.operands, node)
+}
+
+// node returns the operands joined by AND, left to right
+func (c *termChain) node() query.Node {
+	node := c.operands[0]
+	for _, operand := range c.operands[1:] {
+		node = &query.BinaryOpNode{Operator: query.BinaryOpAnd, Left: node, Right: operand}
+	}
+	return node
 }
 
 // parseComparisonWithOptions parses a comparison expression while extracting query options
@@ -238,27 +335,43 @@ func (p *Parser) parseComparisonWithOptions(q *query.Query) (query.Node, error)
 
 	// Handle bare strings (e.g., "hello" or unquoted) as search terms
 	if p.curTok.Type == TokenString {
+		if p.disableBareSearch {
+			return nil, fmt.Errorf("bare search term %q at position %d is not allowed", p.curTok.Value, p.curTok.Pos)
+		}
 		searchTerm := p.curTok.Value
 		if err := p.nextToken(); err != nil {
 			return nil, err
 		}
-		// Create a CONTAINS comparison on the default search field
+		// Create a CONTAINS comparison on the default search field; quotes make it a phrase
 		return &query.ComparisonNode{
-			Field:    "__DEFAULT_SEARCH__", // Special marker for default field
+			Field:    query.SearchField, // Special marker for default field
 			Operator: query.OpContains,
 			Value:    query.StringValue(searchTerm),
+			Phrase:   true,
 		}, nil
 	}
 
-	if p.curTok.Type != TokenIdentifier {
-		return nil, fmt.Errorf("expected identifier at position %d, got %v", p.curTok.Pos, p.curTok.Type)
-	}
-
+	p.matchAsIdentifier()
 	field := p.curTok.Value
+	fieldPos := p.curTok.Pos
+	quoted := true
+	switch p.curTok.Type {
+	case TokenIdentifier:
+		quoted = false
+	case TokenQuotedIdentifier:
+	case TokenLeftBracket:
+		name, err := p.parseBracketField()
+		if err != nil {
+			return nil, err
+		}
+		field = name
+	default:
+		return nil, fmt.Errorf("expected identifier at position %d, got %s", p.curTok.Pos, p.curTok.Type)
+	}
 
 	// Check if this is a query option (identifier followed by =)
 	// We check peekTok without advancing yet
-	isQueryOption := p.peekTok.Type == TokenOperator && p.peekTok.Value == "="
+	isQueryOption := !quoted && p.peekTok.Type == TokenOperator && p.peekTok.Value == "="
 	if isQueryOption {
 		// Try to extract as query option
 		if extracted, err := p.tryExtractQueryOptionFromField(q, field); err != nil {
@@ -275,23 +388,25 @@ func (p *Parser) parseComparisonWithOptions(q *query.Query) (query.Node, error)
 		return nil, err
 	}
 
+	// An array modifier may come between the field and the operator
+	modifier, err := p.parseArrayModifier()
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if this is a bare identifier (search term) or a field name
 	// If no operator follows, treat it as a search term
-	isOperator := p.curTok.Type == TokenOperator ||
-		p.curTok.Type == TokenLike ||
-		p.curTok.Type == TokenNotLike ||
-		p.curTok.Type == TokenContains ||
-		p.curTok.Type == TokenIContains ||
-		p.curTok.Type == TokenStartsWith ||
-		p.curTok.Type == TokenEndsWith ||
-		p.curTok.Type == TokenRegex ||
-		p.curTok.Type == TokenIn ||
-		p.curTok.Type == TokenNot
-
-	if !isOperator {
+	if !p.atOperator() {
+		// Quoted names are always fields
+		if quoted {
+			return nil, fmt.Errorf("expected operator after field %q at position %d", field, fieldPos)
+		}
 		// This is a bare search term (identifier without operator)
+		if p.disableBareSearch {
+			return nil, fmt.Errorf("bare search term %q at position %d is not allowed", field, fieldPos)
+		}
 		return &query.ComparisonNode{
-			Field:    "__DEFAULT_SEARCH__",
+			Field:    query.SearchField,
 			Operator: query.OpContains,
 			Value:    query.StringValue(field),
 		}, nil
@@ -316,6 +431,8 @@ func (p *Parser) parseComparisonWithOptions(q *query.Query) (query.Node, error)
 		operator = query.OpRegex
 	case TokenIn:
 		operator = query.OpIn
+	case TokenMatch:
+		operator = query.OpMatch
 	case TokenNot:
 		// Check for NOT LIKE or NOT IN
 		if err := p.nextToken(); err != nil {
@@ -338,8 +455,6 @@ func (p *Parser) parseComparisonWithOptions(q *query.Query) (query.Node, error)
 
 	// Parse value - could be single value or array for IN/NOT IN
 	var value interface{}
-	var err error
-
 	if operator == query.OpIn || operator == query.OpNotIn {
 		// Expect array
 		value, err = p.parseArray()
@@ -355,11 +470,95 @@ func (p *Parser) parseComparisonWithOptions(q *query.Query) (query.Node, error)
 		return nil, err
 	}
 
-	return &query.ComparisonNode{
+	node := &query.ComparisonNode{
 		Field:    field,
 		Operator: operator,
 		Value:    value,
-	}, nil
+		Modifier: modifier,
+	}
+	if err := query.ValidateArrayCondition(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// parseArrayModifier consumes a LENGTH, ANY or ALL keyword between a field and its
+// operator. The words are only keywords in that position, so fields and search
+// terms can still be named e.g. "length"
+func (p *Parser) parseArrayModifier() (query.ArrayModifier, error) {
+	if p.curTok.Type != TokenIdentifier || !isOperatorToken(p.peekTok) {
+		return query.ArrayModifierNone, nil
+	}
+	modifier, ok := query.ParseArrayModifier(p.curTok.Value)
+	if !ok {
+		return query.ArrayModifierNone, nil
+	}
+	if err := p.nextToken(); err != nil {
+		return query.ArrayModifierNone, err
+	}
+	return modifier, nil
+}
+
+// parseBracketField parses a bracket-quoted field name such as ["order date"],
+// leaving the parser on the closing bracket
+func (p *Parser) parseBracketField() (string, error) {
+	if err := p.nextToken(); err != nil {
+		return "", err
+	}
+	if p.curTok.Type != TokenString {
+		return "", fmt.Errorf("expected quoted field name after '[' at position %d", p.curTok.Pos)
+	}
+	name := p.curTok.Value
+	if name == "" {
+		return "", fmt.Errorf("empty field name at position %d", p.curTok.Pos)
+	}
+	if err := p.nextToken(); err != nil {
+		return "", err
+	}
+	if p.curTok.Type != TokenRightBracket {
+		return "", fmt.Errorf("expected ']' at position %d", p.curTok.Pos)
+	}
+	return name, nil
+}
+
+// startsTerm reports whether tok can start a comparison or search term
+func startsTerm(tok Token) bool {
+	switch tok.Type {
+	case TokenIdentifier, TokenQuotedIdentifier, TokenString, TokenLeftParen, TokenLeftBracket, TokenMatch:
+		return true
+	}
+	return false
+}
+
+// matchAsIdentifier reads a MATCH keyword in field or search term position as
+// the plain word, so fields and search terms can still be named "match"
+func (p *Parser) matchAsIdentifier() {
+	if p.curTok.Type == TokenMatch {
+		p.curTok.Type = TokenIdentifier
+	}
+}
+
+// atOperator reports whether the current token is a comparison operator. MATCH
+// is only an operator when a value follows it; otherwise it is the next term
+func (p *Parser) atOperator() bool {
+	if p.curTok.Type != TokenMatch {
+		return isOperatorToken(p.curTok)
+	}
+	switch p.peekTok.Type {
+	case TokenString, TokenNumber, TokenIdentifier, TokenPlaceholder, TokenParameter:
+		return true
+	}
+	return false
+}
+
+// isOperatorToken reports whether tok starts a comparison operator
+func isOperatorToken(tok Token) bool {
+	switch tok.Type {
+	case TokenOperator, TokenLike, TokenNotLike, TokenContains, TokenIContains, TokenStartsWith,
+		TokenEndsWith, TokenRegex, TokenIn, TokenNot, TokenMatch:
+		return true
+	}
+	return false
 }
 
 // tryExtractQueryOption tries to extract a query option from the current position
@@ -382,6 +581,9 @@ func (p *Parser) tryExtractQueryOptionFromField(q *query.Query, key string) (boo
 	}
 
 	lowerKey := strings.ToLower(key)
+	if canonical, ok := p.optionAliases[lowerKey]; ok {
+		lowerKey = canonical
+	}
 
 	switch lowerKey {
 	case "sort_by":
@@ -437,6 +639,30 @@ func (p *Parser) tryExtractQueryOptionFromField(q *query.Query, key string) (boo
 		}
 		return true, nil
 
+	case "page":
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		if p.curTok.Type != TokenOperator || p.curTok.Value != "=" {
+			return false, fmt.Errorf("expected '=' after page")
+		}
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		val := p.getValue()
+		page, err := strconv.Atoi(val)
+		if err != nil {
+			return false, fmt.Errorf("invalid page: %s", val)
+		}
+		if page < 1 {
+			return false, fmt.Errorf("page must be at least 1, got: %d", page)
+		}
+		q.Page = page
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		return true, nil
+
 	case "limit":
 		if err := p.nextToken(); err != nil {
 			return false, err
@@ -461,6 +687,106 @@ func (p *Parser) tryExtractQueryOptionFromField(q *query.Query, key string) (boo
 		}
 		return true, nil
 
+	case "preserve_in_order":
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		if p.curTok.Type != TokenOperator || p.curTok.Value != "=" {
+			return false, fmt.Errorf("expected '=' after preserve_in_order")
+		}
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		val := p.getValue()
+		preserve, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("invalid preserve_in_order: %s", val)
+		}
+		q.PreserveInOrder = preserve
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		return true, nil
+
+	case "include_deleted":
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		if p.curTok.Type != TokenOperator || p.curTok.Value != "=" {
+			return false, fmt.Errorf("expected '=' after include_deleted")
+		}
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		val := p.getValue()
+		include, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("invalid include_deleted: %s", val)
+		}
+		q.IncludeDeleted = include
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		return true, nil
+
+	case "distinct":
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		if p.curTok.Type != TokenOperator || p.curTok.Value != "=" {
+			return false, fmt.Errorf("expected '=' after distinct")
+		}
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		val := p.getValue()
+		distinct, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("invalid distinct: %s", val)
+		}
+		q.Distinct = distinct
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		return true, nil
+
+	case "distinct_on":
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		if p.curTok.Type != TokenOperator || p.curTok.Value != "=" {
+			return false, fmt.Errorf("expected '=' after distinct_on")
+		}
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		q.DistinctOn = p.getValue()
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		return true, nil
+
+	case "random_seed":
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		if p.curTok.Type != TokenOperator || p.curTok.Value != "=" {
+			return false, fmt.Errorf("expected '=' after random_seed")
+		}
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		val := p.getValue()
+		seed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid random_seed: %s", val)
+		}
+		q.RandomSeed = seed
+		if err := p.nextToken(); err != nil {
+			return false, err
+		}
+		return true, nil
+
 		// Note: cursor is no longer part of Query - it should be passed separately to Execute
 	}
 
@@ -523,7 +849,7 @@ func (p *Parser) parseAndExpression() (query.Node, error) {
 		}
 
 		// Implicit AND - if we encounter another term without OR/AND/EOF/), treat it as AND
-		if p.curTok.Type == TokenIdentifier || p.curTok.Type == TokenString || p.curTok.Type == TokenLeftParen {
+		if p.curTok.Type == TokenIdentifier || p.curTok.Type == TokenMatch || p.curTok.Type == TokenString || p.curTok.Type == TokenLeftParen {
 			// But not if we're at the end or before a closing paren or explicit OR
 			if p.curTok.Type == TokenRightParen || p.curTok.Type == TokenEOF {
 				break
@@ -573,16 +899,18 @@ func (p *Parser) parseComparison() (query.Node, error) {
 		if err := p.nextToken(); err != nil {
 			return nil, err
 		}
-		// Create a CONTAINS comparison on the default search field
+		// Create a CONTAINS comparison on the default search field; quotes make it a phrase
 		return &query.ComparisonNode{
-			Field:    "__DEFAULT_SEARCH__", // Special marker for default field
+			Field:    query.SearchField, // Special marker for default field
 			Operator: query.OpContains,
 			Value:    query.StringValue(searchTerm),
+			Phrase:   true,
 		}, nil
 	}
 
+	p.matchAsIdentifier()
 	if p.curTok.Type != TokenIdentifier {
-		return nil, fmt.Errorf("expected identifier at position %d, got %v", p.curTok.Pos, p.curTok.Type)
+		return nil, fmt.Errorf("expected identifier at position %d, got %s", p.curTok.Pos, p.curTok.Type)
 	}
 
 	field := p.curTok.Value
@@ -590,23 +918,18 @@ func (p *Parser) parseComparison() (query.Node, error) {
 		return nil, err
 	}
 
+	// An array modifier may come between the field and the operator
+	modifier, err := p.parseArrayModifier()
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if this is a bare identifier (search term) or a field name
 	// If no operator follows, treat it as a search term
-	isOperator := p.curTok.Type == TokenOperator ||
-		p.curTok.Type == TokenLike ||
-		p.curTok.Type == TokenNotLike ||
-		p.curTok.Type == TokenContains ||
-		p.curTok.Type == TokenIContains ||
-		p.curTok.Type == TokenStartsWith ||
-		p.curTok.Type == TokenEndsWith ||
-		p.curTok.Type == TokenRegex ||
-		p.curTok.Type == TokenIn ||
-		p.curTok.Type == TokenNot
-
-	if !isOperator {
+	if !p.atOperator() {
 		// This is a bare search term (identifier without operator)
 		return &query.ComparisonNode{
-			Field:    "__DEFAULT_SEARCH__",
+			Field:    query.SearchField,
 			Operator: query.OpContains,
 			Value:    query.StringValue(field),
 		}, nil
@@ -631,6 +954,8 @@ func (p *Parser) parseComparison() (query.Node, error) {
 		operator = query.OpRegex
 	case TokenIn:
 		operator = query.OpIn
+	case TokenMatch:
+		operator = query.OpMatch
 	case TokenNot:
 		// Check for NOT LIKE or NOT IN
 		if err := p.nextToken(); err != nil {
@@ -653,8 +978,6 @@ func (p *Parser) parseComparison() (query.Node, error) {
 
 	// Parse value - could be single value or array for IN/NOT IN
 	var value interface{}
-	var err error
-
 	if operator == query.OpIn || operator == query.OpNotIn {
 		// Expect array
 		value, err = p.parseArray()
@@ -670,11 +993,16 @@ func (p *Parser) parseComparison() (query.Node, error) {
 		return nil, err
 	}
 
-	return &query.ComparisonNode{
+	node := &query.ComparisonNode{
 		Field:    field,
 		Operator: operator,
 		Value:    value,
-	}, nil
+		Modifier: modifier,
+	}
+	if err := query.ValidateArrayCondition(node); err != nil {
+		return nil, err
+	}
+	return node, nil
 }
 
 // parseValue parses a value (string, number, or identifier)
@@ -709,8 +1037,16 @@ func (p *Parser) parseValue() (interface{}, error) {
 		if strings.ToLower(val) == "false" {
 			return query.BoolValue(false), nil
 		}
+		// Relative times (now-7d, startOfMonth) resolve when the query executes
+		if rel, ok := query.ParseRelativeTime(val); ok {
+			return rel, nil
+		}
 		// Treat as string
 		return query.StringValue(val), nil
+	case TokenPlaceholder:
+		return query.PlaceholderValue(p.curTok.Value), nil
+	case TokenParameter:
+		return query.ParameterValue(p.curTok.Value), nil
 	default:
 		return nil, fmt.Errorf("unexpected token type for value at position %d", p.curTok.Pos)
 	}
@@ -719,7 +1055,7 @@ func (p *Parser) parseValue() (interface{}, error) {
 // getValue returns the string value of the current token
 func (p *Parser) getValue() string {
 	switch p.curTok.Type {
-	case TokenString, TokenIdentifier, TokenNumber:
+	case TokenString, TokenIdentifier, TokenQuotedIdentifier, TokenNumber:
 		return p.curTok.Value
 	default:
 		return ""
@@ -728,6 +1064,13 @@ func (p *Parser) getValue() string {
 
 // parseArray parses an array literal [value1, value2, ...]
 func (p *Parser) parseArray() (interface{}, error) {
+	// A placeholder or parameter can stand for the whole list (group_id IN @my_groups)
+	switch p.curTok.Type {
+	case TokenPlaceholder:
+		return query.PlaceholderValue(p.curTok.Value), nil
+	case TokenParameter:
+		return query.ParameterValue(p.curTok.Value), nil
+	}
 	if p.curTok.Type != TokenLeftBracket {
 		return nil, fmt.Errorf("expected '[' at position %d", p.curTok.Pos)
 	}
@@ -108,6 +108,26 @@ func TestParser_BareSearchTerms(t *testing.T) {
 				require.NotNil(t, q.Filter)
 			},
 		},
+		{
+			name:  "unicode bare term",
+			input: `日本語`,
+			expected: func(t *testing.T, q *query.Query) {
+				comp, ok := q.Filter.(*query.ComparisonNode)
+				require.True(t, ok)
+				assert.Equal(t, "__DEFAULT_SEARCH__", comp.Field)
+				assert.Equal(t, query.StringValue("日本語"), comp.Value)
+			},
+		},
+		{
+			name:  "unicode field and value",
+			input: `نام = علی کتاب`,
+			expected: func(t *testing.T, q *query.Query) {
+				binOp, ok := q.Filter.(*query.BinaryOpNode)
+				require.True(t, ok)
+				assert.Equal(t, &query.ComparisonNode{Field: "نام", Operator: query.OpEqual, Value: query.StringValue("علی")}, binOp.Left)
+				assert.Equal(t, query.StringValue("کتاب"), binOp.Right.(*query.ComparisonNode).Value)
+			},
+		},
 	}
 
 	for _, tt := range tests {
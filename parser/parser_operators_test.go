@@ -242,3 +242,96 @@ func TestParser_ComplexWithNewOperators(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_MatchOperator(t *testing.T) {
+	p, err := NewParser(`description MATCH "noise cancelling" AND price < 300`)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+
+	and, ok := q.Filter.(*query.BinaryOpNode)
+	require.True(t, ok)
+	comp, ok := and.Left.(*query.ComparisonNode)
+	require.True(t, ok)
+	assert.Equal(t, "description", comp.Field)
+	assert.Equal(t, query.OpMatch, comp.Operator)
+	assert.Equal(t, query.StringValue("noise cancelling"), comp.Value)
+
+	// Case-insensitive keyword
+	p, err = NewParser(`description match wireless`)
+	require.NoError(t, err)
+	q, err = p.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, query.OpMatch, q.Filter.(*query.ComparisonNode).Operator)
+}
+
+func TestParser_MatchAsWord(t *testing.T) {
+	search := func(term string) *query.ComparisonNode {
+		return &query.ComparisonNode{Field: query.SearchField, Operator: query.OpContains, Value: query.StringValue(term)}
+	}
+	tests := []struct {
+		input    string
+		expected query.Node
+	}{
+		{"match = 1", &query.ComparisonNode{Field: "match", Operator: query.OpEqual, Value: query.IntValue(1)}},
+		{"match", search("match")},
+		{"MATCH MATCH x", &query.ComparisonNode{Field: "MATCH", Operator: query.OpMatch, Value: query.StringValue("x")}},
+		{"title match", &query.BinaryOpNode{Operator: query.BinaryOpAnd, Left: search("title"), Right: search("match")}},
+		{"a = 1 AND match != 2", &query.BinaryOpNode{
+			Operator: query.BinaryOpAnd,
+			Left:     &query.ComparisonNode{Field: "a", Operator: query.OpEqual, Value: query.IntValue(1)},
+			Right:    &query.ComparisonNode{Field: "match", Operator: query.OpNotEqual, Value: query.IntValue(2)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := ParseFilter(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+
+	t.Run("token named in error", func(t *testing.T) {
+		_, err := ParseFilter("= 1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "got operator")
+	})
+}
+
+func TestParser_ArrayModifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected *query.ComparisonNode
+	}{
+		{"tags LENGTH > 3", &query.ComparisonNode{Field: "tags", Operator: query.OpGreaterThan, Value: query.IntValue(3), Modifier: query.ArrayModifierLength}},
+		{`tags any = "wireless"`, &query.ComparisonNode{Field: "tags", Operator: query.OpEqual, Value: query.StringValue("wireless"), Modifier: query.ArrayModifierAny}},
+		{"scores ANY >= 90", &query.ComparisonNode{Field: "scores", Operator: query.OpGreaterThanOrEqual, Value: query.IntValue(90), Modifier: query.ArrayModifierAny}},
+		{"tags ANY NOT IN [a, b]", &query.ComparisonNode{Field: "tags", Operator: query.OpNotIn, Value: query.ArrayValue{query.StringValue("a"), query.StringValue("b")}, Modifier: query.ArrayModifierAny}},
+		{"tags ALL IN [a, b]", &query.ComparisonNode{Field: "tags", Operator: query.OpIn, Value: query.ArrayValue{query.StringValue("a"), query.StringValue("b")}, Modifier: query.ArrayModifierAll}},
+		// The modifier words are plain identifiers elsewhere
+		{"length = 5", &query.ComparisonNode{Field: "length", Operator: query.OpEqual, Value: query.IntValue(5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := ParseFilter(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+
+			p, err := NewParser(tt.input + " page_size = 5")
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, q.Filter)
+		})
+	}
+
+	// A search term followed by another word stays a search
+	filter, err := ParseFilter("all any")
+	require.NoError(t, err)
+	assert.Equal(t, query.And(query.Search("all"), query.Search("any")), filter)
+
+	for _, input := range []string{"tags ALL = a", "tags LENGTH CONTAINS 3", `tags LENGTH > "x"`, "tags ANY MATCH x"} {
+		_, err := ParseFilter(input)
+		assert.ErrorIs(t, err, query.ErrInvalidQuery, input)
+	}
+}
@@ -274,6 +274,38 @@ func TestParser_QueryOptions(t *testing.T) {
 				require.NotNil(t, q.Filter)
 			},
 		},
+		{
+			name:  "preserve_in_order",
+			input: "id IN [5, 1, 9] preserve_in_order = true",
+			expected: func(t *testing.T, q *query.Query) {
+				assert.True(t, q.PreserveInOrder)
+				require.NotNil(t, q.Filter)
+			},
+		},
+		{
+			name:  "include_deleted",
+			input: "status = archived include_deleted = true",
+			expected: func(t *testing.T, q *query.Query) {
+				assert.True(t, q.IncludeDeleted)
+				require.NotNil(t, q.Filter)
+			},
+		},
+		{
+			name:  "distinct",
+			input: "status = active distinct = true",
+			expected: func(t *testing.T, q *query.Query) {
+				assert.True(t, q.Distinct)
+				require.NotNil(t, q.Filter)
+			},
+		},
+		{
+			name:  "distinct_on",
+			input: "status = active distinct_on = email",
+			expected: func(t *testing.T, q *query.Query) {
+				assert.Equal(t, "email", q.DistinctOn)
+				require.NotNil(t, q.Filter)
+			},
+		},
 		{
 			name:  "options mixed with AND",
 			input: "status = active and page_size = 20 and name = test",
@@ -298,6 +330,44 @@ func TestParser_QueryOptions(t *testing.T) {
 	}
 }
 
+func TestParser_InvalidPreserveInOrder(t *testing.T) {
+	parser, err := NewParser("id IN [1] preserve_in_order = sometimes")
+	require.NoError(t, err)
+	_, err = parser.Parse()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid preserve_in_order: sometimes")
+}
+
+func TestParser_Page(t *testing.T) {
+	parser, err := NewParser("page_size = 20 page = 3")
+	require.NoError(t, err)
+	q, err := parser.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, 3, q.Page)
+
+	for _, input := range []string{"page = 0", "page = next"} {
+		parser, err := NewParser(input)
+		require.NoError(t, err)
+		_, err = parser.Parse()
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParser_RandomSeed(t *testing.T) {
+	parser, err := NewParser("sort_order = random random_seed = 42")
+	require.NoError(t, err)
+	q, err := parser.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, query.SortOrderRandom, q.SortOrder)
+	assert.Equal(t, int64(42), q.RandomSeed)
+
+	parser, err = NewParser("sort_order = random random_seed = often")
+	require.NoError(t, err)
+	_, err = parser.Parse()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid random_seed: often")
+}
+
 func TestParser_ComplexExpressions(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -405,3 +475,156 @@ func TestParser_BoolValues(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter(`tenant_id = 5 AND deleted = false`)
+	require.NoError(t, err)
+	assert.Equal(t, &query.BinaryOpNode{
+		Operator: query.BinaryOpAnd,
+		Left:     &query.ComparisonNode{Field: "tenant_id", Operator: query.OpEqual, Value: query.IntValue(5)},
+		Right:    &query.ComparisonNode{Field: "deleted", Operator: query.OpEqual, Value: query.BoolValue(false)},
+	}, filter)
+
+	for _, input := range []string{`tenant_id = 5 sort_by = name`, `tenant_id = 5 page_size = 20`, `tenant_id = 5 limit = 1`, `tenant_id = `} {
+		_, err := ParseFilter(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParser_Placeholders(t *testing.T) {
+	filter, err := ParseFilter(`owner_id = @current_user AND group_id IN @my_groups AND tag IN [a, @tag]`)
+	require.NoError(t, err)
+	assert.Equal(t, query.And(
+		&query.ComparisonNode{Field: "owner_id", Operator: query.OpEqual, Value: query.PlaceholderValue("current_user")},
+		&query.ComparisonNode{Field: "group_id", Operator: query.OpIn, Value: query.PlaceholderValue("my_groups")},
+		&query.ComparisonNode{Field: "tag", Operator: query.OpIn, Value: query.ArrayValue{query.StringValue("a"), query.PlaceholderValue("tag")}},
+	), filter)
+
+	// Quoted values are plain strings
+	filter, err = ParseFilter(`email = "@current_user"`)
+	require.NoError(t, err)
+	assert.Equal(t, query.StringValue("@current_user"), filter.(*query.ComparisonNode).Value)
+
+	for _, input := range []string{`owner_id = @`, `@current_user = 1`} {
+		_, err := ParseFilter(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParser_Parameters(t *testing.T) {
+	filter, err := ParseFilter(`price > :min_price AND brand IN :brands AND tag IN [a, :tag]`)
+	require.NoError(t, err)
+	assert.Equal(t, query.And(
+		&query.ComparisonNode{Field: "price", Operator: query.OpGreaterThan, Value: query.ParameterValue("min_price")},
+		&query.ComparisonNode{Field: "brand", Operator: query.OpIn, Value: query.ParameterValue("brands")},
+		&query.ComparisonNode{Field: "tag", Operator: query.OpIn, Value: query.ArrayValue{query.StringValue("a"), query.ParameterValue("tag")}},
+	), filter)
+
+	// Colons inside identifiers and quoted values are not parameters
+	filter, err = ParseFilter(`tag = account:123 AND note = ":x"`)
+	require.NoError(t, err)
+	assert.Equal(t, query.And(query.Eq("tag", "account:123"), query.Eq("note", ":x")), filter)
+
+	for _, input := range []string{`price > :`, `:field = 1`} {
+		_, err := ParseFilter(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParser_RelativeTimes(t *testing.T) {
+	filter, err := ParseFilter(`created_at > now-7d AND due <= startofmonth+1M-1d AND seen IN [today, now+1h] AND tag = today-special`)
+	require.NoError(t, err)
+	assert.Equal(t, query.And(
+		&query.ComparisonNode{Field: "created_at", Operator: query.OpGreaterThan, Value: query.RelativeTimeValue("now-7d")},
+		&query.ComparisonNode{Field: "due", Operator: query.OpLessThanOrEqual, Value: query.RelativeTimeValue("startOfMonth+1M-1d")},
+		&query.ComparisonNode{Field: "seen", Operator: query.OpIn, Value: query.ArrayValue{query.RelativeTimeValue("today"), query.RelativeTimeValue("now+1h")}},
+		&query.ComparisonNode{Field: "tag", Operator: query.OpEqual, Value: query.StringValue("today-special")},
+	), filter)
+
+	// Quoted values are plain strings
+	filter, err = ParseFilter(`status = "now"`)
+	require.NoError(t, err)
+	assert.Equal(t, query.StringValue("now"), filter.(*query.ComparisonNode).Value)
+}
+
+func TestParser_QuotedFields(t *testing.T) {
+	tests := []struct {
+		input string
+		want  query.Node
+	}{
+		{"`order date` >= 10", &query.ComparisonNode{Field: "order date", Operator: query.OpGreaterThanOrEqual, Value: query.IntValue(10)}},
+		{`["weird field"] = x`, &query.ComparisonNode{Field: "weird field", Operator: query.OpEqual, Value: query.StringValue("x")}},
+		{`[ 'a.b-c' ] != 1`, &query.ComparisonNode{Field: "a.b-c", Operator: query.OpNotEqual, Value: query.IntValue(1)}},
+		{"`say \\`hi\\`` = 1", &query.ComparisonNode{Field: "say `hi`", Operator: query.OpEqual, Value: query.IntValue(1)}},
+		{"`and` = 1", &query.ComparisonNode{Field: "and", Operator: query.OpEqual, Value: query.IntValue(1)}},
+		{"`limit` = 5", &query.ComparisonNode{Field: "limit", Operator: query.OpEqual, Value: query.IntValue(5)}},
+		{"`tag list` LENGTH > 2", &query.ComparisonNode{Field: "tag list", Operator: query.OpGreaterThan, Value: query.IntValue(2), Modifier: query.ArrayModifierLength}},
+		{"`a b` NOT IN [1]", &query.ComparisonNode{Field: "a b", Operator: query.OpNotIn, Value: query.ArrayValue{query.IntValue(1)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := ParseFilter(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, filter)
+		})
+	}
+
+	t.Run("implicit AND", func(t *testing.T) {
+		filter, err := ParseFilter(`a = 1 ["b c"] = 2 ` + "`d e` = 3")
+		require.NoError(t, err)
+		assert.Equal(t, "(a = 1 AND `b c` = 2) AND `d e` = 3", query.FormatFilter(filter))
+	})
+
+	t.Run("sort_by", func(t *testing.T) {
+		p, err := NewParser("sort_by = `order date`")
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		assert.Equal(t, "order date", q.SortBy)
+	})
+
+	for _, input := range []string{"`order date`", "`order date` AND a = 1", `["a"]`, `[a] = 1`, `["a" = 1`, `[""] = 1`, "`` = 1", "`open = 1"} {
+		t.Run("error "+input, func(t *testing.T) {
+			_, err := ParseFilter(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParser_FormatFilterRoundTrip(t *testing.T) {
+	inputs := []string{
+		`status = "active" AND price > 10`,
+		`a = true OR (b = 1 AND c != 2.5)`,
+		`brand IN ["Sony", "JBL"] AND name NOT LIKE "%pro%"`,
+		`name CONTAINS "say \"hi\"" AND title STARTS_WITH "x"`,
+		`created >= 2024-01-02T03:04:05 AND owner_id = @current_user`,
+		`created >= 2024-01-02T03:04:05.123456789 AND price = 1000000.5 AND x = -0.000001 AND y = 2.0`,
+		`created > now-7d AND updated < startOfMonth`,
+		`price >= :min AND brand IN [a, :brand] AND id IN :ids`,
+		`headphones AND price < 100`,
+		"`order date` >= 2024-01-02T00:00:00 AND `say \\`hi\\`` = 1",
+	}
+	for _, input := range inputs {
+		filter, err := ParseFilter(input)
+		require.NoError(t, err, input)
+		reparsed, err := ParseFilter(query.FormatFilter(filter))
+		require.NoError(t, err, query.FormatFilter(filter))
+		assert.Equal(t, filter, reparsed, input)
+	}
+}
+
+func TestParser_FormatRoundTrip(t *testing.T) {
+	parse := func(input string) *query.Query {
+		p, err := NewParser(input)
+		require.NoError(t, err)
+		q, err := p.Parse()
+		require.NoError(t, err)
+		return q
+	}
+	q := parse(`a = 1 OR b = 2 AND c = 3 sort_by = ` + "`order date`" + ` sort_order = desc page_size = 5 distinct = true`)
+
+	// Precedence is made explicit
+	formatted := query.Format(q)
+	assert.Equal(t, "a = 1 OR (b = 2 AND c = 3) sort_by = `order date` sort_order = desc page_size = 5 distinct = true", formatted)
+	assert.Equal(t, q, parse(formatted))
+}
@@ -0,0 +1,398 @@
+// Package rsql parses RSQL and FIQL filters into the same query AST the string
+// parser produces, so APIs that document RSQL can run on go-query executors
+// without changing their syntax:
+//
+//	// GET /products?filter=category==electronics;(price=lt=50,brand=in=(Anker,Sony))
+//	filter, err := rsql.ParseFilter(r.URL.Query().Get("filter"))
+//
+// The comparators are == and != (with * wildcards they become LIKE and
+// NOT LIKE, or STARTS_WITH, ENDS_WITH, CONTAINS and REGEX when the argument
+// contains % or _, which match literally), =lt=, =le=, =gt=, =ge=, their RSQL
+// aliases <, <=, > and >=, and
+// =in= and =out= with a parenthesized list. ; and "and" join conditions with
+// AND, which binds tighter than , and "or". Arguments are quoted with ' or "
+// (with \ escapes) when they contain reserved characters. Unquoted arguments
+// become integers, decimals, booleans or date-times when they parse as one;
+// quoted arguments are always strings.
+package rsql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Parse parses an RSQL filter into a query with the default options of the
+// string parser
+func Parse(filter string) (*query.Query, error) {
+	node, err := ParseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &query.Query{
+		Filter:    node,
+		PageSize:  10, // default
+		SortOrder: query.SortOrderAsc,
+	}, nil
+}
+
+// ParseFilter parses an RSQL filter into a filter node. An empty filter
+// returns a nil filter
+func ParseFilter(filter string) (query.Node, error) {
+	tokens, err := lex(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return node, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenComparator
+	tokenAnd
+	tokenOr
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// reserved are the characters that end an unquoted selector or argument
+const reserved = `"'();,=!~<>`
+
+// lex splits a filter into selectors and arguments, quoted strings,
+// comparators, logical operators and parentheses
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case r == ';':
+			tokens = append(tokens, token{tokenAnd, ";", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenOr, ",", i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					sb.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == r {
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+			}
+			tokens = append(tokens, token{tokenString, sb.String(), start})
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			comparator, err := lexComparator(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i += len([]rune(comparator))
+			tokens = append(tokens, token{tokenComparator, comparator, start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(reserved, runes[i]) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+			word := string(runes[start:i])
+			switch word {
+			case "and":
+				tokens = append(tokens, token{tokenAnd, word, start})
+			case "or":
+				tokens = append(tokens, token{tokenOr, word, start})
+			default:
+				tokens = append(tokens, token{tokenWord, word, start})
+			}
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(runes)}), nil
+}
+
+// lexComparator reads the comparator starting at runes[i]: ==, !=, <, <=, >,
+// >= or a FIQL comparator such as =lt=
+func lexComparator(runes []rune, i int) (string, error) {
+	rest := string(runes[i:])
+	for _, comparator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(rest, comparator) {
+			return comparator, nil
+		}
+	}
+	if runes[i] == '=' {
+		j := i + 1
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || runes[j] == '-') {
+			j++
+		}
+		if j > i+1 && j < len(runes) && runes[j] == '=' {
+			return string(runes[i : j+1]), nil
+		}
+	}
+	return "", fmt.Errorf("invalid comparator at position %d", i)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (query.Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &query.BinaryOpNode{Operator: query.BinaryOpOr, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (query.Node, error) {
+	left, err := p.parseConstraint()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseConstraint()
+		if err != nil {
+			return nil, err
+		}
+		left = &query.BinaryOpNode{Operator: query.BinaryOpAnd, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseConstraint() (query.Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at position %d", closing.pos)
+		}
+		return node, nil
+	case tokenWord:
+		return p.parseComparison(tok)
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of filter")
+	default:
+		return nil, fmt.Errorf("expected a selector at position %d, got %q", tok.pos, tok.text)
+	}
+}
+
+// comparators maps the comparators of single arguments to query operators
+var comparators = map[string]query.ComparisonOperator{
+	"==":   query.OpEqual,
+	"!=":   query.OpNotEqual,
+	"=lt=": query.OpLessThan,
+	"<":    query.OpLessThan,
+	"=le=": query.OpLessThanOrEqual,
+	"<=":   query.OpLessThanOrEqual,
+	"=gt=": query.OpGreaterThan,
+	">":    query.OpGreaterThan,
+	"=ge=": query.OpGreaterThanOrEqual,
+	">=":   query.OpGreaterThanOrEqual,
+}
+
+// parseComparison parses "selector comparator argument"
+func (p *parser) parseComparison(selector token) (query.Node, error) {
+	compTok := p.next()
+	if compTok.kind != tokenComparator {
+		return nil, fmt.Errorf("expected a comparator after %q at position %d", selector.text, compTok.pos)
+	}
+	comparator := strings.ToLower(compTok.text)
+	if comparator == "=in=" || comparator == "=out=" {
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		op := query.OpIn
+		if comparator == "=out=" {
+			op = query.OpNotIn
+		}
+		return &query.ComparisonNode{Field: selector.text, Operator: op, Value: values}, nil
+	}
+	op, ok := comparators[comparator]
+	if !ok {
+		return nil, fmt.Errorf("unknown comparator %q at position %d", compTok.text, compTok.pos)
+	}
+	value, err := p.parseArgument()
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := value.(query.StringValue); ok && strings.Contains(string(s), "*") && (op == query.OpEqual || op == query.OpNotEqual) {
+		return wildcardComparison(selector.text, string(s), op == query.OpNotEqual, compTok.pos)
+	}
+	return &query.ComparisonNode{Field: selector.text, Operator: op, Value: value}, nil
+}
+
+// wildcardComparison compares field with an argument containing * wildcards,
+// e.g. name==*usb*, as LIKE or NOT LIKE. * is the only wildcard of RSQL, so
+// arguments containing LIKE's % or _ must match them literally: a leading or
+// trailing * becomes STARTS_WITH, ENDS_WITH or CONTAINS and other patterns an
+// anchored REGEX. Those have no negated form, so != rejects such arguments
+func wildcardComparison(field, arg string, negate bool, pos int) (query.Node, error) {
+	if !strings.ContainsAny(arg, "%_") {
+		op := query.OpLike
+		if negate {
+			op = query.OpNotLike
+		}
+		return &query.ComparisonNode{Field: field, Operator: op, Value: query.StringValue(strings.ReplaceAll(arg, "*", "%"))}, nil
+	}
+	if negate {
+		return nil, fmt.Errorf("!= with * wildcards cannot match %% or _ at position %d", pos)
+	}
+
+	inner := strings.Trim(arg, "*")
+	node := &query.ComparisonNode{Field: field, Value: query.StringValue(inner)}
+	switch {
+	case strings.Contains(inner, "*"):
+		parts := strings.Split(arg, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		node.Operator = query.OpRegex
+		node.Value = query.StringValue("^" + strings.Join(parts, ".*") + "$")
+	case strings.HasPrefix(arg, "*") && strings.HasSuffix(arg, "*"):
+		node.Operator = query.OpContains
+	case strings.HasPrefix(arg, "*"):
+		node.Operator = query.OpEndsWith
+	default:
+		node.Operator = query.OpStartsWith
+	}
+	return node, nil
+}
+
+// parseList parses the parenthesized arguments of =in= and =out=. A single
+// argument may be written without parentheses
+func (p *parser) parseList() (query.ArrayValue, error) {
+	if p.peek().kind != tokenLParen {
+		value, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		return query.ArrayValue{value}, nil
+	}
+	p.next()
+	var values query.ArrayValue
+	for {
+		value, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			return values, nil
+		}
+		if tok.kind != tokenOr {
+			return nil, fmt.Errorf("expected ',' or ')' at position %d", tok.pos)
+		}
+	}
+}
+
+// parseArgument parses a quoted string or an unquoted argument, typed by
+// what it parses as
+func (p *parser) parseArgument() (interface{}, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return query.StringValue(tok.text), nil
+	case tokenWord, tokenAnd, tokenOr:
+		if tok.text == "," || tok.text == ";" {
+			break
+		}
+		return typedValue(tok.text), nil
+	}
+	return nil, fmt.Errorf("expected an argument at position %d", tok.pos)
+}
+
+// typedValue converts an unquoted argument to an integer, decimal, boolean
+// or date-time value, or a string when it is none of them
+func typedValue(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return query.IntValue(i)
+	}
+	// ParseFloat also accepts words such as "inf" and "nan"
+	if f, err := strconv.ParseFloat(s, 64); err == nil && strings.IndexFunc(s, unicode.IsLetter) < 0 {
+		return query.FloatValue(f)
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		return query.BoolValue(true)
+	case "false":
+		return query.BoolValue(false)
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return query.DateTimeValue(t)
+		}
+	}
+	return query.StringValue(s)
+}
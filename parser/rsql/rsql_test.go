@@ -0,0 +1,75 @@
+package rsql
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{"equal", `brand==Sony`, `brand = "Sony"`},
+		{"fiql comparators", `price=gt=10;price=le=99.5`, `price > 10 AND price <= 99.5`},
+		{"rsql comparators", `price>10 and stock<5`, `price > 10 AND stock < 5`},
+		{"precedence", `a==1,b==2;c==3`, `a = 1 OR (b = 2 AND c = 3)`},
+		{"groups", `category==electronics;(price=lt=50,brand=in=(Anker,Sony))`, `category = "electronics" AND (price < 50 OR brand IN ["Anker", "Sony"])`},
+		{"out", `brand=out=(Anker, "Sony Corp")`, `brand NOT IN ["Anker", "Sony Corp"]`},
+		{"quoted", `name=="usb, \"hub\""`, `name = "usb, \"hub\""`},
+		{"quoted numbers stay strings", `sku=='42'`, `sku = "42"`},
+		{"wildcards", `name==*usb*;name!=*hub`, `name LIKE "%usb%" AND name NOT LIKE "%hub"`},
+		{"literal % and _", `name==foo_bar*;name==*50%;name==*a_b*`, `(name STARTS_WITH "foo_bar" AND name ENDS_WITH "50%") AND name CONTAINS "a_b"`},
+		{"typed", `featured==true;created_at=ge=2024-01-15`, `featured = true AND created_at >= 2024-01-15T00:00:00`},
+		{"or keyword", `brand==Sony or brand==JBL`, `brand = "Sony" OR brand = "JBL"`},
+		{"empty", ``, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, query.FormatFilter(node))
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, filter := range []string{
+		`brand`,
+		`brand==`,
+		`brand=like=x`,
+		`brand==x;`,
+		`(brand==x`,
+		`brand=="open`,
+		`brand=in=(a,`,
+		`brand==x brand==y`,
+		`name!=foo_*`,
+	} {
+		_, err := ParseFilter(filter)
+		assert.Error(t, err, filter)
+	}
+}
+
+func TestParseFilter_LiteralLikeWildcards(t *testing.T) {
+	node, err := ParseFilter(`name==a%b*c_d`)
+	require.NoError(t, err)
+	n := node.(*query.ComparisonNode)
+	require.Equal(t, query.OpRegex, n.Operator)
+
+	re, err := query.CompileRegex(string(n.Value.(query.StringValue)))
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("a%b and c_d"))
+	assert.False(t, re.MatchString("aXb and c_d"))
+	assert.False(t, re.MatchString("a%b and cXd"))
+}
+
+func TestParse(t *testing.T) {
+	q, err := Parse(`price=lt=10`)
+	require.NoError(t, err)
+	assert.Equal(t, `price < 10`, query.FormatFilter(q.Filter))
+	assert.Equal(t, 10, q.PageSize)
+}
@@ -0,0 +1,282 @@
+// Package policy evaluates declarative access rules against a parsed query
+// before it is executed.
+//
+// Rules can be written in Go or loaded from YAML:
+//
+//	default_search_field: name
+//	rules:
+//	  - name: no-regex-on-email
+//	    roles: [viewer, guest]
+//	    deny:
+//	      fields: [email]
+//	      operators: [REGEX, LIKE]
+//	  - name: tenant-scope
+//	    require:
+//	      field: tenant_id
+//	      operators: ["="]
+//
+// Deny rules reject comparisons matching the listed fields and operators.
+// Require rules demand that a predicate on the given field is ANDed into the
+// top level of the filter, so it cannot be bypassed with OR.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hadi77ir/go-query/query"
+	"gopkg.in/yaml.v3"
+)
+
+// AnyRole matches every role when used in Rule.Roles
+const AnyRole = "*"
+
+// Policy is a set of rules evaluated against queries
+type Policy struct {
+	// DefaultSearchField resolves bare search terms to a field name
+	// If empty, bare search terms are checked against the "__DEFAULT_SEARCH__" marker
+	DefaultSearchField string `yaml:"default_search_field"`
+
+	// Rules are evaluated in order; all violations are collected
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a single policy rule. Exactly one of Deny or Require must be set.
+type Rule struct {
+	// Name identifies the rule in violations
+	Name string `yaml:"name"`
+
+	// Roles the rule applies to. Empty list or "*" means all roles.
+	Roles []string `yaml:"roles"`
+
+	// Deny rejects comparisons matching the given fields and operators
+	Deny *DenyRule `yaml:"deny"`
+
+	// Require demands a top-level predicate on a field
+	Require *RequireRule `yaml:"require"`
+}
+
+// DenyRule rejects comparisons on Fields using Operators
+// Empty Fields matches every field; empty Operators matches every operator
+type DenyRule struct {
+	Fields    []string `yaml:"fields"`
+	Operators []string `yaml:"operators"`
+}
+
+// RequireRule demands that the filter contains a predicate on Field, ANDed at the top level
+// Empty Operators accepts any operator
+type RequireRule struct {
+	Field     string   `yaml:"field"`
+	Operators []string `yaml:"operators"`
+}
+
+// Violation describes a single rule violation
+type Violation struct {
+	Rule     string
+	Field    string
+	Operator string
+	Reason   string
+}
+
+// Decision is the outcome of evaluating a policy against a query
+type Decision struct {
+	Allowed    bool
+	Violations []Violation
+}
+
+// Err returns nil if the query is allowed, otherwise an error wrapping query.ErrPolicyViolation
+func (d Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	reasons := make([]string, len(d.Violations))
+	for i, v := range d.Violations {
+		reasons[i] = fmt.Sprintf("%s: %s", v.Rule, v.Reason)
+	}
+	return fmt.Errorf("%w: %s", query.ErrPolicyViolation, strings.Join(reasons, "; "))
+}
+
+// Parse parses a YAML policy document and validates its rules
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// LoadFile reads and parses a YAML policy file
+func LoadFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Validate checks that every rule is well-formed
+func (p *Policy) Validate() error {
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule #%d", i+1)
+		}
+		if (rule.Deny == nil) == (rule.Require == nil) {
+			return fmt.Errorf("%s: exactly one of deny or require must be set", name)
+		}
+		var ops []string
+		if rule.Deny != nil {
+			ops = rule.Deny.Operators
+		} else {
+			if rule.Require.Field == "" {
+				return fmt.Errorf("%s: require rule must specify a field", name)
+			}
+			ops = rule.Require.Operators
+		}
+		for _, op := range ops {
+			if !query.IsValidOperator(strings.ToUpper(op)) {
+				return fmt.Errorf("%s: unknown operator %q", name, op)
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates the policy against a query for the given role
+func (p *Policy) Evaluate(q *query.Query, role string) Decision {
+	var violations []Violation
+	for i, rule := range p.Rules {
+		if !rule.appliesTo(role) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule #%d", i+1)
+		}
+		if rule.Deny != nil {
+			violations = append(violations, p.evaluateDeny(name, rule.Deny, q)...)
+		}
+		if rule.Require != nil {
+			if v, ok := p.evaluateRequire(name, rule.Require, q); !ok {
+				violations = append(violations, v)
+			}
+		}
+	}
+	return Decision{Allowed: len(violations) == 0, Violations: violations}
+}
+
+// Check evaluates the policy and returns an error if the query is denied
+func (p *Policy) Check(q *query.Query, role string) error {
+	return p.Evaluate(q, role).Err()
+}
+
+// appliesTo reports whether the rule applies to the role
+func (r *Rule) appliesTo(role string) bool {
+	if len(r.Roles) == 0 {
+		return true
+	}
+	for _, candidate := range r.Roles {
+		if candidate == AnyRole || candidate == role {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateDeny collects a violation for every comparison matching the deny rule
+func (p *Policy) evaluateDeny(name string, rule *DenyRule, q *query.Query) []Violation {
+	if q == nil || q.Filter == nil {
+		return nil
+	}
+	var violations []Violation
+	walkComparisons(q.Filter, func(n *query.ComparisonNode) {
+		field := p.resolveField(n.Field)
+		if len(rule.Fields) > 0 && !containsFold(rule.Fields, field) {
+			return
+		}
+		if len(rule.Operators) > 0 && !containsOperator(rule.Operators, n.Operator) {
+			return
+		}
+		violations = append(violations, Violation{
+			Rule:     name,
+			Field:    field,
+			Operator: n.Operator.String(),
+			Reason:   fmt.Sprintf("operator %s not allowed on field '%s'", n.Operator, field),
+		})
+	})
+	return violations
+}
+
+// evaluateRequire checks that a matching predicate is ANDed at the top level of the filter
+func (p *Policy) evaluateRequire(name string, rule *RequireRule, q *query.Query) (Violation, bool) {
+	violation := Violation{
+		Rule:   name,
+		Field:  rule.Field,
+		Reason: fmt.Sprintf("required predicate on field '%s' is missing", rule.Field),
+	}
+	if q == nil || q.Filter == nil {
+		return violation, false
+	}
+	for _, n := range topLevelConjuncts(q.Filter) {
+		cmp, ok := n.(*query.ComparisonNode)
+		// Array modifiers test the elements or length, not the field's value
+		if !ok || cmp.Modifier != query.ArrayModifierNone || !strings.EqualFold(p.resolveField(cmp.Field), rule.Field) {
+			continue
+		}
+		if len(rule.Operators) == 0 || containsOperator(rule.Operators, cmp.Operator) {
+			return Violation{}, true
+		}
+	}
+	return violation, false
+}
+
+// resolveField maps the default search marker to the configured field
+func (p *Policy) resolveField(field string) string {
+	if field == query.SearchField && p.DefaultSearchField != "" {
+		return p.DefaultSearchField
+	}
+	return field
+}
+
+// walkComparisons calls fn for every comparison node in the tree
+func walkComparisons(node query.Node, fn func(*query.ComparisonNode)) {
+	query.Walk(node, func(node query.Node) bool {
+		if n, ok := node.(*query.ComparisonNode); ok {
+			fn(n)
+		}
+		return true
+	})
+}
+
+// topLevelConjuncts flattens the top-level AND chain of a filter
+// Nodes beneath an OR are returned as a single conjunct
+func topLevelConjuncts(node query.Node) []query.Node {
+	if bin, ok := node.(*query.BinaryOpNode); ok && bin.Operator == query.BinaryOpAnd {
+		return append(topLevelConjuncts(bin.Left), topLevelConjuncts(bin.Right)...)
+	}
+	return []query.Node{node}
+}
+
+// containsFold reports whether list contains s (case-insensitive)
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsOperator reports whether list contains the operator
+func containsOperator(list []string, op query.ComparisonOperator) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), op.String()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicyYAML = `
+default_search_field: name
+rules:
+  - name: no-regex-on-email
+    roles: [viewer]
+    deny:
+      fields: [email]
+      operators: [REGEX, LIKE]
+  - name: no-search-for-guests
+    roles: [guest]
+    deny:
+      fields: [name]
+  - name: tenant-scope
+    require:
+      field: tenant_id
+      operators: ["="]
+`
+
+func parseQuery(t *testing.T, input string) *query.Query {
+	p, err := parser.NewParser(input)
+	require.NoError(t, err)
+	q, err := p.Parse()
+	require.NoError(t, err)
+	return q
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	pol, err := Parse([]byte(testPolicyYAML))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		input      string
+		role       string
+		allowed    bool
+		violations int
+	}{
+		{"scoped query allowed", `tenant_id = 7 AND email = "a@b.c"`, "viewer", true, 0},
+		{"regex on email denied for viewer", `tenant_id = 7 AND email REGEX ".*"`, "viewer", false, 1},
+		{"regex on email allowed for admin", `tenant_id = 7 AND email REGEX ".*"`, "admin", true, 0},
+		{"missing tenant predicate", `email = "a@b.c"`, "admin", false, 1},
+		{"tenant predicate under OR is not enough", `tenant_id = 7 OR email = "a@b.c"`, "admin", false, 1},
+		{"wrong tenant operator", `tenant_id != 7`, "admin", false, 1},
+		{"array modifier is not a tenant predicate", `tenant_id ANY = 7`, "admin", false, 1},
+		{"empty filter requires tenant", ``, "admin", false, 1},
+		{"bare search resolved to default field", `tenant_id = 7 AND wireless`, "guest", false, 1},
+		{"multiple violations collected", `email LIKE "%a" OR email REGEX "b"`, "viewer", false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := pol.Evaluate(parseQuery(t, tt.input), tt.role)
+			assert.Equal(t, tt.allowed, decision.Allowed)
+			assert.Len(t, decision.Violations, tt.violations)
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	pol := &Policy{Rules: []Rule{
+		{Name: "no-regex", Deny: &DenyRule{Operators: []string{"REGEX"}}},
+	}}
+
+	require.NoError(t, pol.Check(parseQuery(t, `name = foo`), "any"))
+
+	err := pol.Check(parseQuery(t, `name REGEX "^a"`), "any")
+	assert.True(t, errors.Is(err, query.ErrPolicyViolation))
+	assert.Contains(t, err.Error(), "no-regex")
+
+	decision := pol.Evaluate(parseQuery(t, `name REGEX "^a"`), "any")
+	assert.Equal(t, "name", decision.Violations[0].Field)
+	assert.Equal(t, "REGEX", decision.Violations[0].Operator)
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"no deny or require", "rules:\n  - name: empty\n"},
+		{"both deny and require", "rules:\n  - deny: {fields: [a]}\n    require: {field: b}\n"},
+		{"require without field", "rules:\n  - require: {operators: ['=']}\n"},
+		{"unknown operator", "rules:\n  - deny: {operators: [SOUNDS_LIKE]}\n"},
+		{"malformed yaml", "rules: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			assert.Error(t, err)
+		})
+	}
+}
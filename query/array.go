@@ -0,0 +1,60 @@
+package query
+
+import "fmt"
+
+// ValidateArrayCondition checks that an array modifier is used with an
+// operator and value it supports:
+//
+//   - LENGTH takes =, !=, >, >=, < or <= and an integer
+//   - ANY takes any operator but MATCH
+//   - ALL takes IN with a list of values
+//
+// Comparisons without a modifier, and LENGTH with an unresolved placeholder,
+// are accepted. Errors are FieldErrors wrapping ErrInvalidQuery
+func ValidateArrayCondition(n *ComparisonNode) error {
+	if n.Modifier == ArrayModifierNone {
+		return nil
+	}
+	if n.Field == SearchField {
+		return fmt.Errorf("%w: %s cannot be applied to a search term", ErrInvalidQuery, n.Modifier)
+	}
+	if !n.Modifier.AllowsOperator(n.Operator) {
+		if n.Modifier == ArrayModifierAll {
+			return NewFieldError(n.Field, fmt.Errorf("%w: ALL must be followed by IN", ErrInvalidQuery))
+		}
+		return NewFieldError(n.Field, fmt.Errorf("%w: operator %s cannot be used with %s", ErrInvalidQuery, n.Operator, n.Modifier))
+	}
+	switch n.Modifier {
+	case ArrayModifierLength:
+		switch n.Value.(type) {
+		case IntValue, PlaceholderValue, ParameterValue:
+			return nil
+		}
+		return NewFieldError(n.Field, fmt.Errorf("%w: LENGTH must be compared with an integer", ErrInvalidQuery))
+	case ArrayModifierAll:
+		if _, ok := n.Value.(ArrayValue); !ok {
+			return NewFieldError(n.Field, fmt.Errorf("%w: ALL IN requires an array value", ErrInvalidQuery))
+		}
+	}
+	return nil
+}
+
+// AllowsOperator reports whether the operator can follow the modifier
+func (m ArrayModifier) AllowsOperator(op ComparisonOperator) bool {
+	switch m {
+	case ArrayModifierNone:
+		return true
+	case ArrayModifierLength:
+		switch op {
+		case OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanOrEqual, OpLessThan, OpLessThanOrEqual:
+			return true
+		}
+		return false
+	case ArrayModifierAny:
+		return op != OpMatch
+	case ArrayModifierAll:
+		return op == OpIn
+	default:
+		return false
+	}
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateArrayCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    *ComparisonNode
+		wantErr bool
+	}{
+		{"no modifier", &ComparisonNode{Field: "tags", Operator: OpMatch, Value: StringValue("x")}, false},
+		{"length", &ComparisonNode{Field: "tags", Operator: OpLessThanOrEqual, Value: IntValue(2), Modifier: ArrayModifierLength}, false},
+		{"length placeholder", &ComparisonNode{Field: "tags", Operator: OpEqual, Value: PlaceholderValue("n"), Modifier: ArrayModifierLength}, false},
+		{"length float", &ComparisonNode{Field: "tags", Operator: OpEqual, Value: FloatValue(2.5), Modifier: ArrayModifierLength}, true},
+		{"length in", &ComparisonNode{Field: "tags", Operator: OpIn, Value: ArrayValue{IntValue(1)}, Modifier: ArrayModifierLength}, true},
+		{"any regex", &ComparisonNode{Field: "tags", Operator: OpRegex, Value: StringValue("^a"), Modifier: ArrayModifierAny}, false},
+		{"any match", &ComparisonNode{Field: "tags", Operator: OpMatch, Value: StringValue("a"), Modifier: ArrayModifierAny}, true},
+		{"all in", &ComparisonNode{Field: "tags", Operator: OpIn, Value: ArrayValue{StringValue("a")}, Modifier: ArrayModifierAll}, false},
+		{"all not in", &ComparisonNode{Field: "tags", Operator: OpNotIn, Value: ArrayValue{StringValue("a")}, Modifier: ArrayModifierAll}, true},
+		{"search term", &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue("a"), Modifier: ArrayModifierAny}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArrayCondition(tt.node)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidQuery)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseArrayModifier(t *testing.T) {
+	for _, m := range []ArrayModifier{ArrayModifierLength, ArrayModifierAny, ArrayModifierAll} {
+		parsed, ok := ParseArrayModifier(m.String())
+		assert.True(t, ok)
+		assert.Equal(t, m, parsed)
+	}
+	_, ok := ParseArrayModifier("some")
+	assert.False(t, ok)
+}
@@ -69,6 +69,14 @@ type ComparisonNode struct {
 	Field    string
 	Operator ComparisonOperator
 	Value    interface{}
+
+	// Modifier applies the comparison to the length or the elements of an
+	// array field; see ValidateArrayCondition
+	Modifier ArrayModifier
+
+	// Phrase marks a bare search term that was quoted, "usb cable", to be
+	// matched as a phrase; see ExecutorOptions.PhraseMatch
+	Phrase bool
 }
 
 func (n *ComparisonNode) Type() NodeType { return NodeTypeComparison }
@@ -80,6 +88,24 @@ type FloatValue float64
 type BoolValue bool
 type DateTimeValue time.Time
 
+// PlaceholderValue is a value resolved from the request context at execution
+// time (@name in the query language); see ExecutorOptions.Placeholders
+type PlaceholderValue string
+
+// ParameterValue is a value bound by the application before execution
+// (:name in the query language); see Query.Bind
+type ParameterValue string
+
+// ScoreField is the pseudo-field used to sort by relevance (sort_by = _score)
+// Relevance is computed from MATCH conditions and bare search terms; the most
+// relevant items always come first.
+const ScoreField = "_score"
+
+// MatchCountField is the pseudo-field used to sort by the number of CONTAINS and
+// ICONTAINS conditions each item matches (sort_by = _matches); see MatchClauses.
+// Items matching the most conditions always come first.
+const MatchCountField = "_matches"
+
 // SortOrder represents the sort order direction
 type SortOrder int
 
@@ -129,4 +155,59 @@ type Query struct {
 	SortOrder SortOrder
 	PageSize  int
 	Limit     int // Maximum total items that can be returned (0 means no limit)
+
+	// Page jumps to the 1-based page number (page = 7) by skipping
+	// (Page-1)*PageSize items when the query runs without a cursor; pages after
+	// it follow the returned cursors. 0 and 1 start at the first page.
+	// Executors reject jumps past ExecutorOptions.MaxPageOffset
+	Page int
+
+	// PreserveInOrder returns results in the order of the values of the
+	// query's IN condition (preserve_in_order = true); see InOrderCondition
+	PreserveInOrder bool
+
+	// IncludeDeleted includes soft-deleted rows (include_deleted = true).
+	// Executors reject it unless ExecutorOptions.AllowIncludeDeleted is set
+	IncludeDeleted bool
+
+	// Distinct drops items equal to an earlier item (distinct = true)
+	Distinct bool
+
+	// DistinctOn keeps only the first item, in sort order, of each value of
+	// the field (distinct_on = field). It takes precedence over Distinct
+	DistinctOn string
+
+	// RandomSeed fixes the order of sort_order = random (random_seed = 42):
+	// the same seed returns the same order. 0 picks a new seed, which cursors
+	// carry to later pages; see ResolveRandomSeed
+	RandomSeed int64
+
+	// Metadata carries caller values through decorators and hooks, such as
+	// trace IDs. Executors do not read it and it is not part of cursors
+	Metadata map[string]interface{}
+
+	// ExplainRequested asks executors to describe how they ran the query in
+	// Result.Explain. Getting a plan can take an extra database round trip.
+	// It is not part of cursors
+	ExplainRequested bool
+
+	// HighlightRequested asks executors to return the fragments of the
+	// returned items that matched the filter in Result.Highlights. Only the
+	// memory executor supports it. It is not part of cursors
+	HighlightRequested bool
+
+	// unresolved is Filter before ResolvePlaceholders resolved its relative
+	// times; see StableFilter
+	unresolved Node
+}
+
+// StableFilter returns the filter with relative times such as now-7d as
+// written, even after ResolvePlaceholders replaced them by the instants they
+// stood for at execution. Cursors hash it, so the pages of a query share a
+// hash although each page resolves now anew
+func (q *Query) StableFilter() Node {
+	if q.unresolved != nil {
+		return q.unresolved
+	}
+	return q.Filter
 }
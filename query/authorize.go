@@ -0,0 +1,48 @@
+package query
+
+import "context"
+
+// SortOperator is the operator FieldAuthorizer receives for the fields of
+// sort_by and distinct_on, which order and group results instead of comparing
+const SortOperator ComparisonOperator = -1
+
+// AuthorizeFields checks every field q uses with FieldAuthorizer. Bare search
+// terms check each default search field. Executors call it after
+// ResolvePlaceholders, with the context of the request
+func (o *ExecutorOptions) AuthorizeFields(ctx context.Context, q *Query) error {
+	if o.FieldAuthorizer == nil || q == nil {
+		return nil
+	}
+	var err error
+	Walk(q.Filter, func(node Node) bool {
+		if n, ok := node.(*ComparisonNode); ok {
+			err = o.authorizeCondition(ctx, n)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, field := range []string{q.SortBy, q.DistinctOn} {
+		if field == "" || field == ScoreField || field == MatchCountField {
+			continue
+		}
+		if err := o.FieldAuthorizer(ctx, field, SortOperator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeCondition checks the field of n, or each default search field
+func (o *ExecutorOptions) authorizeCondition(ctx context.Context, n *ComparisonNode) error {
+	if n.Field != SearchField {
+		return o.FieldAuthorizer(ctx, n.Field, n.Operator)
+	}
+	for _, field := range o.SearchFields() {
+		if err := o.FieldAuthorizer(ctx, field, n.Operator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
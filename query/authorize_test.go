@@ -0,0 +1,57 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorOptions_AuthorizeFields(t *testing.T) {
+	type check struct {
+		field string
+		op    ComparisonOperator
+	}
+	var checked []check
+	opts := &ExecutorOptions{
+		DefaultSearchFields: []string{"name", "notes"},
+		BaseFilter:          Eq("tenant_id", 5),
+		FieldAuthorizer: func(ctx context.Context, field string, op ComparisonOperator) error {
+			checked = append(checked, check{field, op})
+			if field == "notes" && ctx.Value(roleKey{}) != "admin" {
+				return FieldNotAllowedError(field)
+			}
+			return nil
+		},
+	}
+	admin := context.WithValue(context.Background(), roleKey{}, "admin")
+
+	q := &Query{Filter: And(Eq("status", "active"), Search("usb")), SortBy: "price", DistinctOn: "brand"}
+	require.NoError(t, opts.AuthorizeFields(admin, q))
+	assert.Equal(t, []check{
+		{"status", OpEqual},
+		{"name", OpContains},
+		{"notes", OpContains},
+		{"price", SortOperator},
+		{"brand", SortOperator},
+	}, checked)
+
+	checked = nil
+	err := opts.AuthorizeFields(context.Background(), q)
+	assert.ErrorIs(t, err, ErrFieldNotAllowed)
+	assert.Len(t, checked, 3, "checking stops at the first error")
+
+	t.Run("errors are returned as is", func(t *testing.T) {
+		denied := errors.New("denied")
+		opts := &ExecutorOptions{FieldAuthorizer: func(context.Context, string, ComparisonOperator) error { return denied }}
+		assert.Same(t, denied, opts.AuthorizeFields(context.Background(), &Query{SortBy: "price"}))
+		assert.NoError(t, opts.AuthorizeFields(context.Background(), &Query{SortBy: ScoreField}))
+	})
+
+	assert.NoError(t, (&ExecutorOptions{}).AuthorizeFields(context.Background(), q))
+	assert.Equal(t, "SORT", SortOperator.String())
+}
+
+type roleKey struct{}
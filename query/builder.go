@@ -0,0 +1,324 @@
+package query
+
+// Field starts a condition on a field; see F
+type Field struct {
+	name     string
+	modifier ArrayModifier
+}
+
+// F starts a condition on the named field:
+//
+//	q := query.F("price").Gt(50).
+//		And(query.F("brand").In("Sony", "JBL")).
+//		SortBy("price").Desc().PageSize(20).Build()
+//
+// Plain Go values are converted to the value types the parser produces
+// (string to StringValue, ints to IntValue, floats to FloatValue, bool to
+// BoolValue and time.Time to DateTimeValue), so built and parsed queries
+// are interchangeable.
+func F(name string) Field {
+	return Field{name: name}
+}
+
+// Length compares the number of elements of the array field:
+//
+//	query.F("tags").Length().Gt(3) // tags LENGTH > 3
+func (f Field) Length() Field { return Field{name: f.name, modifier: ArrayModifierLength} }
+
+// Any matches when at least one element of the array field satisfies the comparison:
+//
+//	query.F("tags").Any().Eq("wireless") // tags ANY = "wireless"
+func (f Field) Any() Field { return Field{name: f.name, modifier: ArrayModifierAny} }
+
+// All matches when the array field contains every value of the following In:
+//
+//	query.F("tags").All().In("a", "b") // tags ALL IN ["a", "b"]
+func (f Field) All() Field { return Field{name: f.name, modifier: ArrayModifierAll} }
+
+// Eq builds field = value
+func (f Field) Eq(value interface{}) *Condition { return f.compare(OpEqual, value) }
+
+// Ne builds field != value
+func (f Field) Ne(value interface{}) *Condition { return f.compare(OpNotEqual, value) }
+
+// Gt builds field > value
+func (f Field) Gt(value interface{}) *Condition { return f.compare(OpGreaterThan, value) }
+
+// Gte builds field >= value
+func (f Field) Gte(value interface{}) *Condition { return f.compare(OpGreaterThanOrEqual, value) }
+
+// Lt builds field < value
+func (f Field) Lt(value interface{}) *Condition { return f.compare(OpLessThan, value) }
+
+// Lte builds field <= value
+func (f Field) Lte(value interface{}) *Condition { return f.compare(OpLessThanOrEqual, value) }
+
+// Like builds field LIKE pattern
+func (f Field) Like(pattern string) *Condition { return f.compare(OpLike, pattern) }
+
+// NotLike builds field NOT LIKE pattern
+func (f Field) NotLike(pattern string) *Condition { return f.compare(OpNotLike, pattern) }
+
+// Contains builds field CONTAINS s
+func (f Field) Contains(s string) *Condition { return f.compare(OpContains, s) }
+
+// IContains builds field ICONTAINS s
+func (f Field) IContains(s string) *Condition { return f.compare(OpIContains, s) }
+
+// StartsWith builds field STARTS_WITH s
+func (f Field) StartsWith(s string) *Condition { return f.compare(OpStartsWith, s) }
+
+// EndsWith builds field ENDS_WITH s
+func (f Field) EndsWith(s string) *Condition { return f.compare(OpEndsWith, s) }
+
+// Regex builds field REGEX pattern
+func (f Field) Regex(pattern string) *Condition { return f.compare(OpRegex, pattern) }
+
+// Match builds field MATCH text
+func (f Field) Match(text string) *Condition { return f.compare(OpMatch, text) }
+
+// In builds field IN [values...]
+func (f Field) In(values ...interface{}) *Condition {
+	return f.condition(In(f.name, values...))
+}
+
+// NotIn builds field NOT IN [values...]
+func (f Field) NotIn(values ...interface{}) *Condition {
+	return f.condition(NotIn(f.name, values...))
+}
+
+func (f Field) compare(op ComparisonOperator, value interface{}) *Condition {
+	return f.condition(Compare(f.name, op, value))
+}
+
+// condition wraps a comparison built for the field, applying its array modifier
+func (f Field) condition(node Node) *Condition {
+	node.(*ComparisonNode).Modifier = f.modifier
+	return &Condition{node: node}
+}
+
+// Condition is a filter expression under construction.
+// A nil or empty Condition has no filter and is skipped when combined.
+type Condition struct {
+	node Node
+}
+
+// Cond wraps an existing filter node, e.g. the filter of a parsed user query,
+// so it can be combined with built conditions
+func Cond(node Node) *Condition {
+	return &Condition{node: node}
+}
+
+// Node returns the filter node of the condition
+func (c *Condition) Node() Node {
+	if c == nil {
+		return nil
+	}
+	return c.node
+}
+
+// And combines the condition with others using AND
+func (c *Condition) And(others ...*Condition) *Condition {
+	return &Condition{node: And(c.Node(), conditionNodes(others)...)}
+}
+
+// Or combines the condition with others using OR
+func (c *Condition) Or(others ...*Condition) *Condition {
+	return &Condition{node: Or(c.Node(), conditionNodes(others)...)}
+}
+
+// SortBy starts a query filtered by the condition and sorted by field
+func (c *Condition) SortBy(field string) *Builder { return Where(c).SortBy(field) }
+
+// PageSize starts a query filtered by the condition with the given page size
+func (c *Condition) PageSize(size int) *Builder { return Where(c).PageSize(size) }
+
+// Page starts a query filtered by the condition that jumps to the given page
+func (c *Condition) Page(page int) *Builder { return Where(c).Page(page) }
+
+// Limit starts a query filtered by the condition with the given limit
+func (c *Condition) Limit(limit int) *Builder { return Where(c).Limit(limit) }
+
+// Random starts a query filtered by the condition in random order; see Builder.Random
+func (c *Condition) Random(seed int64) *Builder { return Where(c).Random(seed) }
+
+// PreserveInOrder starts a query filtered by the condition that keeps the order of its IN values
+func (c *Condition) PreserveInOrder() *Builder { return Where(c).PreserveInOrder() }
+
+// IncludeDeleted starts a query filtered by the condition that includes soft-deleted rows
+func (c *Condition) IncludeDeleted() *Builder { return Where(c).IncludeDeleted() }
+
+// Distinct starts a query filtered by the condition that drops duplicate items
+func (c *Condition) Distinct() *Builder { return Where(c).Distinct() }
+
+// DistinctOn starts a query filtered by the condition that keeps the first item of each value of field
+func (c *Condition) DistinctOn(field string) *Builder { return Where(c).DistinctOn(field) }
+
+// Build returns a query filtered by the condition with default options
+func (c *Condition) Build() *Query { return Where(c).Build() }
+
+// And joins nodes with AND, left to right like the parser. nil nodes are
+// skipped; the result is nil when all nodes are nil.
+func And(first Node, rest ...Node) Node {
+	return join(BinaryOpAnd, first, rest)
+}
+
+// Or joins nodes with OR, left to right like the parser. nil nodes are
+// skipped; the result is nil when all nodes are nil.
+func Or(first Node, rest ...Node) Node {
+	return join(BinaryOpOr, first, rest)
+}
+
+func join(op BinaryOperator, first Node, rest []Node) Node {
+	result := first
+	for _, node := range rest {
+		if node == nil {
+			continue
+		}
+		if result == nil {
+			result = node
+			continue
+		}
+		result = &BinaryOpNode{Operator: op, Left: result, Right: node}
+	}
+	return result
+}
+
+func conditionNodes(conditions []*Condition) []Node {
+	nodes := make([]Node, 0, len(conditions))
+	for _, c := range conditions {
+		nodes = append(nodes, c.Node())
+	}
+	return nodes
+}
+
+// Builder assembles a Query. The zero options match the parser defaults:
+// page size 10 and ascending order.
+type Builder struct {
+	q Query
+}
+
+// Where starts a query filtered by the condition
+func Where(c *Condition) *Builder {
+	return &Builder{q: Query{Filter: c.Node(), PageSize: 10, SortOrder: SortOrderAsc}}
+}
+
+// From starts a builder from a copy of an existing query, e.g. to add
+// server-enforced filters to a parsed user query:
+//
+//	q = query.From(userQuery).Where(query.F("tenant_id").Eq(tenantID)).Build()
+func From(q *Query) *Builder {
+	if q == nil {
+		return Where(nil)
+	}
+	return &Builder{q: *q}
+}
+
+// Where adds a condition, combined with the current filter using AND.
+// The current filter stays grouped, so OR expressions in it cannot escape
+// the added condition.
+func (b *Builder) Where(c *Condition) *Builder {
+	b.q.Filter = And(b.q.Filter, c.Node())
+	return b
+}
+
+// SortBy sets the sort field
+func (b *Builder) SortBy(field string) *Builder {
+	b.q.SortBy = field
+	return b
+}
+
+// SortOrder sets the sort order
+func (b *Builder) SortOrder(order SortOrder) *Builder {
+	b.q.SortOrder = order
+	return b
+}
+
+// Asc sorts in ascending order
+func (b *Builder) Asc() *Builder { return b.SortOrder(SortOrderAsc) }
+
+// Desc sorts in descending order
+func (b *Builder) Desc() *Builder { return b.SortOrder(SortOrderDesc) }
+
+// Random sorts in random order. A non-zero seed returns the same order every
+// time; 0 picks a new one
+func (b *Builder) Random(seed int64) *Builder {
+	b.q.SortOrder = SortOrderRandom
+	b.q.RandomSeed = seed
+	return b
+}
+
+// PageSize sets the page size
+func (b *Builder) PageSize(size int) *Builder {
+	b.q.PageSize = size
+	return b
+}
+
+// Page jumps to the 1-based page number when the query runs without a cursor
+func (b *Builder) Page(page int) *Builder {
+	b.q.Page = page
+	return b
+}
+
+// Limit sets the maximum total number of items (0 means no limit)
+func (b *Builder) Limit(limit int) *Builder {
+	b.q.Limit = limit
+	return b
+}
+
+// PreserveInOrder returns results in the order of the values of the IN
+// condition instead of sorting them; see InOrderCondition
+func (b *Builder) PreserveInOrder() *Builder {
+	b.q.PreserveInOrder = true
+	return b
+}
+
+// IncludeDeleted includes soft-deleted rows; see ExecutorOptions.AllowIncludeDeleted
+func (b *Builder) IncludeDeleted() *Builder {
+	b.q.IncludeDeleted = true
+	return b
+}
+
+// Distinct drops items equal to an earlier item
+func (b *Builder) Distinct() *Builder {
+	b.q.Distinct = true
+	return b
+}
+
+// DistinctOn keeps only the first item of each value of field
+func (b *Builder) DistinctOn(field string) *Builder {
+	b.q.DistinctOn = field
+	return b
+}
+
+// Metadata sets a metadata value on the query
+func (b *Builder) Metadata(key string, value interface{}) *Builder {
+	b.q.SetMetadata(key, value)
+	return b
+}
+
+// Build returns the assembled query. The builder can be reused; each call
+// returns a new Query.
+func (b *Builder) Build() *Query {
+	q := b.q
+	q.Metadata = copyMetadata(b.q.Metadata)
+	return &q
+}
+
+// toValue converts plain Go values to the value types produced by the parser
+// with ToValue. Values ToValue rejects are returned unchanged.
+func toValue(v interface{}) interface{} {
+	converted, err := ToValue(v)
+	if err != nil {
+		return v
+	}
+	return converted
+}
+
+func toArrayValue(values []interface{}) ArrayValue {
+	arr := make(ArrayValue, 0, len(values))
+	for _, v := range values {
+		arr = append(arr, toValue(v))
+	}
+	return arr
+}
@@ -0,0 +1,141 @@
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	q := F("price").Gt(50).
+		And(F("brand").In("Sony", "JBL")).
+		SortBy("price").Desc().PageSize(20).Build()
+
+	assert.Equal(t, &Query{
+		Filter: &BinaryOpNode{
+			Operator: BinaryOpAnd,
+			Left:     &ComparisonNode{Field: "price", Operator: OpGreaterThan, Value: IntValue(50)},
+			Right:    &ComparisonNode{Field: "brand", Operator: OpIn, Value: ArrayValue{StringValue("Sony"), StringValue("JBL")}},
+		},
+		SortBy:    "price",
+		SortOrder: SortOrderDesc,
+		PageSize:  20,
+	}, q)
+}
+
+func TestBuilder_PreserveInOrder(t *testing.T) {
+	q := F("id").In(5, 1, 9).PreserveInOrder().Build()
+	assert.True(t, q.PreserveInOrder)
+
+	n, err := InOrderCondition(q)
+	assert.NoError(t, err)
+	assert.Same(t, q.Filter, Node(n))
+}
+
+func TestBuilder_IncludeDeleted(t *testing.T) {
+	q := F("status").Eq("archived").IncludeDeleted().Build()
+	assert.True(t, q.IncludeDeleted)
+}
+
+func TestBuilder_Distinct(t *testing.T) {
+	q := F("status").Eq("active").Distinct().Build()
+	assert.True(t, q.Distinct)
+
+	q = F("status").Eq("active").DistinctOn("email").Build()
+	assert.Equal(t, "email", q.DistinctOn)
+}
+
+func TestBuilder_ArrayModifiers(t *testing.T) {
+	filter := F("tags").Length().Gt(3).And(F("tags").Any().Eq("wireless"), F("tags").All().In("a", "b")).Node()
+	assert.Equal(t, `(tags LENGTH > 3 AND tags ANY = "wireless") AND tags ALL IN ["a", "b"]`, FormatFilter(filter))
+
+	// The modifier applies to one condition only
+	tags := F("tags")
+	tags.Any()
+	assert.Equal(t, ArrayModifierNone, tags.Eq("x").Node().(*ComparisonNode).Modifier)
+}
+
+func TestBuilder_Defaults(t *testing.T) {
+	q := F("active").Eq(true).Build()
+	assert.Equal(t, &Query{
+		Filter:    &ComparisonNode{Field: "active", Operator: OpEqual, Value: BoolValue(true)},
+		SortOrder: SortOrderAsc,
+		PageSize:  10,
+	}, q)
+
+	assert.Equal(t, &Query{SortOrder: SortOrderAsc, PageSize: 10}, Where(nil).Build())
+}
+
+func TestBuilder_Values(t *testing.T) {
+	created := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		cond *Condition
+		want interface{}
+	}{
+		{"int", F("a").Eq(int32(3)), IntValue(3)},
+		{"uint", F("a").Eq(uint(3)), IntValue(3)},
+		{"uint above MaxInt64", F("a").Gt(uint64(math.MaxUint64 - 1)), FloatValue(math.MaxUint64 - 1)},
+		{"float", F("a").Lt(float32(1.5)), FloatValue(1.5)},
+		{"time", F("a").Gte(created), DateTimeValue(created)},
+		{"query value kept", F("a").Eq(StringValue("x")), StringValue("x")},
+		{"placeholder kept", F("a").Eq(PlaceholderValue("user")), PlaceholderValue("user")},
+		{"string operator", F("a").StartsWith("pre"), StringValue("pre")},
+		{"not in", F("a").NotIn(1, "b", 2.5), ArrayValue{IntValue(1), StringValue("b"), FloatValue(2.5)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cond.Node().(*ComparisonNode).Value)
+		})
+	}
+}
+
+func TestBuilder_OrGrouping(t *testing.T) {
+	// (a = 1 OR b = 2) AND c = 3
+	cond := F("a").Eq(1).Or(F("b").Eq(2)).And(F("c").Eq(3))
+	root := cond.Node().(*BinaryOpNode)
+	assert.Equal(t, BinaryOpAnd, root.Operator)
+	assert.Equal(t, BinaryOpOr, root.Left.(*BinaryOpNode).Operator)
+}
+
+func TestBuilder_From(t *testing.T) {
+	userFilter := Or(
+		&ComparisonNode{Field: "name", Operator: OpContains, Value: StringValue("phone")},
+		&ComparisonNode{Field: "tenant_id", Operator: OpEqual, Value: IntValue(2)},
+	)
+	user := &Query{Filter: userFilter, SortBy: "name", PageSize: 5}
+
+	q := From(user).Where(F("tenant_id").Eq(1)).Build()
+
+	// The user's OR stays grouped under the enforced AND
+	assert.Equal(t, &BinaryOpNode{
+		Operator: BinaryOpAnd,
+		Left:     userFilter,
+		Right:    &ComparisonNode{Field: "tenant_id", Operator: OpEqual, Value: IntValue(1)},
+	}, q.Filter)
+	assert.Equal(t, "name", q.SortBy)
+	assert.Equal(t, 5, q.PageSize)
+	assert.Same(t, userFilter, user.Filter, "original query must not be modified")
+
+	// Empty user filter
+	q = From(&Query{PageSize: 5}).Where(F("tenant_id").Eq(1)).Build()
+	assert.Equal(t, &ComparisonNode{Field: "tenant_id", Operator: OpEqual, Value: IntValue(1)}, q.Filter)
+}
+
+func TestAndOr(t *testing.T) {
+	a := &ComparisonNode{Field: "a", Operator: OpEqual, Value: IntValue(1)}
+	b := &ComparisonNode{Field: "b", Operator: OpEqual, Value: IntValue(2)}
+
+	assert.Nil(t, And(nil))
+	assert.Nil(t, Or(nil, nil))
+	assert.Same(t, a, And(nil, a, nil))
+	assert.Equal(t, &BinaryOpNode{Operator: BinaryOpOr, Left: a, Right: b}, Or(a, nil, b))
+
+	// Nil conditions match everything
+	var none *Condition
+	assert.Equal(t, Node(a), Cond(a).And(none).Node())
+	assert.Equal(t, Node(a), none.And(Cond(a)).Node())
+}
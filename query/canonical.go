@@ -0,0 +1,76 @@
+package query
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// CanonicalFilter returns a filter equivalent to node in which nested AND and
+// OR chains are flattened and their operands sorted, so filters that differ
+// only in operand order or grouping, e.g. a = 1 AND (b = 2 AND c = 3) and
+// c = 3 AND b = 2 AND a = 1, become identical. Use it to build cache keys.
+// node is not modified
+func CanonicalFilter(node Node) Node {
+	return canonicalize(node, FormatFilter)
+}
+
+// canonicalize flattens and sorts the AND and OR chains of node by key
+func canonicalize(node Node, key func(Node) string) Node {
+	n, ok := node.(*BinaryOpNode)
+	if !ok {
+		return node
+	}
+	operands := flatten(n, n.Operator, nil)
+	keys := make(map[Node]string, len(operands))
+	for i, operand := range operands {
+		operands[i] = canonicalize(operand, key)
+		keys[operands[i]] = key(operands[i])
+	}
+	sort.SliceStable(operands, func(i, j int) bool {
+		return keys[operands[i]] < keys[operands[j]]
+	})
+
+	canonical := operands[0]
+	for _, operand := range operands[1:] {
+		canonical = &BinaryOpNode{Operator: n.Operator, Left: canonical, Right: operand}
+	}
+	return canonical
+}
+
+// flatten appends the operands of the op chain rooted at node, left to right
+func flatten(node Node, op BinaryOperator, operands []Node) []Node {
+	if n, ok := node.(*BinaryOpNode); ok && n.Operator == op {
+		return flatten(n.Right, op, flatten(n.Left, op, operands))
+	}
+	return append(operands, node)
+}
+
+// Normalize returns a copy of q whose filter is in canonical form (see
+// CanonicalFilter), so queries that differ only in operand order or grouping
+// compare, hash and format the same. q is not modified
+func Normalize(q *Query) *Query {
+	if q == nil {
+		return nil
+	}
+	normalized := *q
+	normalized.Filter = CanonicalFilter(q.Filter)
+	if q.unresolved != nil {
+		normalized.unresolved = CanonicalFilter(q.unresolved)
+	}
+	return &normalized
+}
+
+// Fingerprint returns a stable hash of the shape of q: its fields, operators
+// and options with every value left out, so email = 'a@x.com' AND age > 30
+// and age > 18 AND email = 'b@y.com' share a fingerprint. Use it to group
+// logs or rate limit by query shape. Page size and limit are values and are
+// left out too; relative times count as written, before resolution
+func Fingerprint(q *Query) string {
+	h := fnv.New64a()
+	if q != nil {
+		fmt.Fprintf(h, "%s|%s|%s|%t|%t|%t|%s", NormalizeFilter(canonicalize(q.StableFilter(), NormalizeFilter)),
+			q.SortBy, q.SortOrder, q.PreserveInOrder, q.IncludeDeleted, q.Distinct, q.DistinctOn)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
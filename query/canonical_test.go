@@ -0,0 +1,67 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalFilter(t *testing.T) {
+	a, b, c := Eq("a", 1), Eq("b", 2), Eq("c", 3)
+
+	left := And(a, And(b, c))
+	right := And(And(c, b), a)
+	assert.Equal(t, FormatFilter(CanonicalFilter(left)), FormatFilter(CanonicalFilter(right)))
+	assert.Equal(t, `(a = 1 AND b = 2) AND c = 3`, FormatFilter(CanonicalFilter(right)))
+
+	// Nested chains of the other operator are sorted but kept as a group
+	mixed := And(Or(c, b), a)
+	assert.Equal(t, `a = 1 AND (b = 2 OR c = 3)`, FormatFilter(CanonicalFilter(mixed)))
+
+	// The input is not modified
+	assert.Equal(t, `(c = 3 OR b = 2) AND a = 1`, FormatFilter(mixed))
+
+	assert.Nil(t, CanonicalFilter(nil))
+	assert.Same(t, a, CanonicalFilter(a))
+}
+
+func TestNormalize(t *testing.T) {
+	q := &Query{Filter: And(Eq("b", 2), And(Eq("c", 3), Eq("a", 1))), SortBy: "a", PageSize: 10}
+
+	normalized := Normalize(q)
+	assert.Equal(t, `(a = 1 AND b = 2) AND c = 3`, FormatFilter(normalized.Filter))
+	assert.Equal(t, "a", normalized.SortBy)
+	assert.Equal(t, 10, normalized.PageSize)
+
+	// The input is not modified
+	assert.Equal(t, `b = 2 AND (c = 3 AND a = 1)`, FormatFilter(q.Filter))
+
+	assert.Nil(t, Normalize(nil))
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(filter Node, sortBy string) string {
+		return Fingerprint(&Query{Filter: filter, SortBy: sortBy})
+	}
+
+	base := fingerprint(And(Eq("email", "a@x.com"), Gt("age", 30)), "age")
+	assert.Len(t, base, 16)
+
+	// Values, operand order and grouping do not matter
+	assert.Equal(t, base, fingerprint(And(Gt("age", 18), Eq("email", "b@y.com")), "age"))
+	assert.Equal(t, base, Fingerprint(&Query{Filter: And(Eq("email", "c"), Gt("age", 1)), SortBy: "age", PageSize: 50}))
+
+	// Values do not decide the order of operands that differ in shape
+	left := Or(And(Eq("a", 1), Eq("b", 1)), And(Eq("a", 10), Eq("c", 1)))
+	right := Or(And(Eq("a", 2), Eq("c", 1)), And(Eq("a", 1), Eq("b", 1)))
+	assert.Equal(t, fingerprint(left, ""), fingerprint(right, ""))
+
+	// Fields, operators and options do
+	assert.NotEqual(t, base, fingerprint(And(Eq("email", "a"), Lt("age", 30)), "age"))
+	assert.NotEqual(t, base, fingerprint(And(Eq("mail", "a"), Gt("age", 30)), "age"))
+	assert.NotEqual(t, base, fingerprint(And(Eq("email", "a"), Gt("age", 30)), "email"))
+	assert.NotEqual(t, base, Fingerprint(&Query{Filter: And(Eq("email", "a"), Gt("age", 30)), SortBy: "age", Distinct: true}))
+	assert.NotEqual(t, base, fingerprint(Or(Eq("email", "a"), Gt("age", 30)), "age"))
+
+	assert.Equal(t, Fingerprint(nil), Fingerprint(nil))
+}
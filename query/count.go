@@ -0,0 +1,41 @@
+package query
+
+// CountMode selects how Execute computes Result.TotalItems. Count always
+// counts exactly
+type CountMode int
+
+const (
+	// CountExact counts every matching item before the page is fetched (default)
+	CountExact CountMode = iota
+	// CountEstimated reads the total from statistics the database keeps where
+	// it can, such as the collection metadata count on MongoDB, pg_class
+	// reltuples on PostgreSQL and system.tables on ClickHouse, and sets
+	// Result.TotalItemsEstimated. Statistics only cover unfiltered queries, so
+	// filtered queries are counted exactly, except on MongoDB, which samples
+	// the collection, and ClickHouse with CountSampleRatio
+	CountEstimated
+	// CountNone skips the count. TotalItems is TotalUnknown; pages and cursors
+	// are unaffected
+	CountNone
+	// CountAsync counts exactly while the page is fetched, so the total costs
+	// no extra latency unless counting is the slower of the two. Executors
+	// without a database round trip count as with CountExact
+	CountAsync
+)
+
+// TotalUnknown is Result.TotalItems when CountNone skipped the count
+const TotalUnknown int64 = -1
+
+// String returns the string representation of CountMode
+func (m CountMode) String() string {
+	switch m {
+	case CountEstimated:
+		return "estimated"
+	case CountNone:
+		return "none"
+	case CountAsync:
+		return "async"
+	default:
+		return "exact"
+	}
+}
@@ -3,6 +3,8 @@ package query
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Sentinel errors - use with errors.Is() for matching
@@ -16,12 +18,18 @@ var (
 	// ErrFieldNotAllowed is returned when a field is not in the AllowedFields whitelist
 	ErrFieldNotAllowed = errors.New("field not allowed")
 
+	// ErrUnknownField is returned when a field is missing from the schema
+	ErrUnknownField = errors.New("unknown field")
+
 	// ErrInvalidQuery is returned when the query structure is invalid
 	ErrInvalidQuery = errors.New("invalid query")
 
 	// ErrInvalidCursor is returned when a cursor string cannot be decoded
 	ErrInvalidCursor = errors.New("invalid cursor")
 
+	// ErrCursorQueryMismatch is returned when a cursor is presented with a different query than the one that generated it
+	ErrCursorQueryMismatch = errors.New("cursor does not match query")
+
 	// ErrPageSizeExceeded is returned when requested page size exceeds maximum
 	ErrPageSizeExceeded = errors.New("page size exceeds maximum")
 
@@ -31,21 +39,83 @@ var (
 	// ErrRandomOrderNotAllowed is returned when random order is requested but disabled
 	ErrRandomOrderNotAllowed = errors.New("random order not allowed")
 
+	// ErrIncludeDeletedNotAllowed is returned when a query asks for soft-deleted rows but AllowIncludeDeleted is off
+	ErrIncludeDeletedNotAllowed = errors.New("include_deleted not allowed")
+
+	// ErrUnknownPlaceholder is returned when a query uses an @placeholder with no registered resolver
+	ErrUnknownPlaceholder = errors.New("unknown placeholder")
+
+	// ErrUnboundParameter is returned when a query uses a :parameter that was not bound with Query.Bind
+	ErrUnboundParameter = errors.New("unbound parameter")
+
 	// ErrExecutionFailed is returned when query execution fails at database level
 	ErrExecutionFailed = errors.New("query execution failed")
 
 	// ErrInvalidDestination is returned when the destination parameter is invalid
 	ErrInvalidDestination = errors.New("invalid destination")
+
+	// ErrTypeMismatch is returned when an operator or value does not match the field's schema type
+	ErrTypeMismatch = errors.New("type mismatch")
+
+	// ErrCircuitOpen is returned when a circuit breaker rejects calls after repeated execution failures
+	ErrCircuitOpen = errors.New("circuit breaker open")
+
+	// ErrPolicyViolation is returned when a query is denied by a policy rule
+	ErrPolicyViolation = errors.New("policy violation")
+
+	// ErrNamedQueryNotFound is returned when a saved query library has no query with the requested name
+	ErrNamedQueryNotFound = errors.New("named query not found")
+
+	// ErrInvalidSortField is returned when sort_by names a field that cannot be sorted on
+	ErrInvalidSortField = errors.New("invalid sort field")
+
+	// ErrOperatorNotAllowed is returned when FieldPolicy forbids an operator on a field
+	ErrOperatorNotAllowed = errors.New("operator not allowed")
+
+	// ErrQueryTooComplex is returned when a filter exceeds a complexity limit such as MaxFilterDepth
+	ErrQueryTooComplex = errors.New("query too complex")
+
+	// ErrUnsafeRegex is returned when SafeRegex rejects a REGEX pattern
+	ErrUnsafeRegex = errors.New("unsafe regex")
+
+	// ErrRegexTimeout is returned when REGEX evaluation exceeds RegexTimeout
+	ErrRegexTimeout = errors.New("regex evaluation timed out")
+
+	// ErrPageTooDeep is returned when a page jump skips more items than MaxPageOffset allows
+	ErrPageTooDeep = errors.New("page too deep")
+
+	// ErrFacetsNotSupported is returned when an executor cannot count facets
+	ErrFacetsNotSupported = errors.New("facets not supported")
+
+	// ErrWritesNotAllowed is returned by DeleteWhere and UpdateWhere without AllowWrites
+	ErrWritesNotAllowed = errors.New("writes not allowed")
+
+	// ErrWritesNotSupported is returned when an executor cannot write
+	ErrWritesNotSupported = errors.New("writes not supported")
+
+	// ErrSearchTermsIgnored is returned when every condition of a filter is a
+	// search term ignored by StopWords or MinSearchTermLength
+	ErrSearchTermsIgnored = errors.New("all search terms ignored")
+
+	// ErrRateLimited is returned when a rate or concurrency limit rejects a call
+	ErrRateLimited = errors.New("rate limited")
 )
 
 // FieldError wraps an error with field name information
 type FieldError struct {
 	Field string
 	Err   error
+
+	// Suggestions are known fields close to Field, closest first, when Field
+	// is unknown or not allowed (see SuggestFields)
+	Suggestions []string
 }
 
 func (e *FieldError) Error() string {
-	return fmt.Sprintf("field '%s': %v", e.Field, e.Err)
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("field '%s': %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("field '%s': %v (did you mean: %s?)", e.Field, e.Err, strings.Join(e.Suggestions, ", "))
 }
 
 func (e *FieldError) Unwrap() error {
@@ -65,9 +135,52 @@ func InvalidFieldNameError(field string) error {
 	return NewFieldError(field, ErrInvalidFieldName)
 }
 
-// FieldNotAllowedError creates an error for fields not in AllowedFields
-func FieldNotAllowedError(field string) error {
-	return NewFieldError(field, ErrFieldNotAllowed)
+// FieldNotAllowedError creates an error for fields not in AllowedFields.
+// The allowed fields closest to field are suggested
+func FieldNotAllowedError(field string, allowed ...string) error {
+	return &FieldError{Field: field, Err: ErrFieldNotAllowed, Suggestions: SuggestFields(field, allowed)}
+}
+
+// UnknownFieldError creates an error for fields missing from a schema.
+// The known fields closest to field are suggested
+func UnknownFieldError(field string, known ...string) error {
+	return &FieldError{Field: field, Err: ErrUnknownField, Suggestions: SuggestFields(field, known)}
+}
+
+// TypeMismatchError creates an error for operators or values that don't match the field's schema type
+func TypeMismatchError(field string, detail string) error {
+	return NewFieldError(field, fmt.Errorf("%w: %s", ErrTypeMismatch, detail))
+}
+
+// SortFieldError reports an unknown sort field together with close matches
+type SortFieldError struct {
+	Field       string
+	Suggestions []string
+}
+
+func (e *SortFieldError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("%v '%s'", ErrInvalidSortField, e.Field)
+	}
+	return fmt.Sprintf("%v '%s' (did you mean: %s?)", ErrInvalidSortField, e.Field, strings.Join(e.Suggestions, ", "))
+}
+
+func (e *SortFieldError) Unwrap() error {
+	return ErrInvalidSortField
+}
+
+// OperatorError reports an operator that FieldPolicy does not allow on a field
+type OperatorError struct {
+	Field    string
+	Operator ComparisonOperator
+}
+
+func (e *OperatorError) Error() string {
+	return fmt.Sprintf("field '%s': %v: %s", e.Field, ErrOperatorNotAllowed, e.Operator)
+}
+
+func (e *OperatorError) Unwrap() error {
+	return ErrOperatorNotAllowed
 }
 
 // ExecutionError wraps a database execution error
@@ -94,3 +207,93 @@ func NewExecutionError(operation string, err error) error {
 		Err:       err,
 	}
 }
+
+// RateLimitError is returned when a rate or concurrency limit rejects a call.
+// It matches ErrRateLimited with errors.Is
+type RateLimitError struct {
+	// Key is the rate limit key the call was counted under, empty for limits
+	// shared by all calls
+	Key string
+
+	// RetryAfter is how long until the call would be allowed, 0 when unknown
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	msg := ErrRateLimited.Error()
+	if e.Key != "" {
+		msg += fmt.Sprintf(" for key %q", e.Key)
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %v", e.RetryAfter)
+	}
+	return msg
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// NewRateLimitError creates a new RateLimitError
+func NewRateLimitError(key string, retryAfter time.Duration) error {
+	return &RateLimitError{Key: key, RetryAfter: retryAfter}
+}
+
+// Error is returned by every executor's Execute and Count. It records which
+// executor and operation failed and, for field-specific errors, the field.
+// Err is the underlying error, so errors.Is and errors.As still match the
+// sentinel errors, FieldError, ExecutionError and the other error types
+type Error struct {
+	Backend string // executor name, e.g. "memory" or "GORM"
+	Op      string // "execute" or "count"
+	Field   string // field the error is about, empty when not field-specific
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Backend, e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WrapError wraps err in an *Error for backend and op, filling Field from
+// FieldError, OperatorError or SortFieldError. Errors that are already an
+// *Error keep the backend that produced them. Returns nil for a nil err
+func WrapError(backend, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var wrapped *Error
+	if errors.As(err, &wrapped) {
+		return err
+	}
+	return &Error{Backend: backend, Op: op, Field: errorField(err), Err: err}
+}
+
+// WrapResult wraps err like WrapError and stores it in result.Error
+func WrapResult(backend, op string, result *Result, err error) (*Result, error) {
+	err = WrapError(backend, op, err)
+	if result != nil && err != nil {
+		result.Error = err
+	}
+	return result, err
+}
+
+// errorField returns the field an error is about, if any
+func errorField(err error) string {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Field
+	}
+	var opErr *OperatorError
+	if errors.As(err, &opErr) {
+		return opErr.Field
+	}
+	var sortErr *SortFieldError
+	if errors.As(err, &sortErr) {
+		return sortErr.Field
+	}
+	return ""
+}
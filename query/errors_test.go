@@ -0,0 +1,62 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapError(t *testing.T) {
+	assert.Nil(t, WrapError("memory", "execute", nil))
+
+	err := WrapError("GORM", "execute", FieldNotAllowedError("password"))
+	var wrapped *Error
+	assert.True(t, errors.As(err, &wrapped))
+	assert.Equal(t, &Error{Backend: "GORM", Op: "execute", Field: "password", Err: FieldNotAllowedError("password")}, wrapped)
+	assert.Equal(t, "GORM execute: field 'password': field not allowed", err.Error())
+	assert.True(t, errors.Is(err, ErrFieldNotAllowed))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+
+	// The executor that produced the error is kept
+	assert.Same(t, err, WrapError("wrapper", "count", err))
+
+	err = WrapError("memory", "count", &OperatorError{Field: "name", Operator: OpRegex})
+	assert.True(t, errors.As(err, &wrapped))
+	assert.Equal(t, "name", wrapped.Field)
+
+	err = WrapError("memory", "count", NewExecutionError("count items", errors.New("timeout")))
+	assert.True(t, errors.As(err, &wrapped))
+	assert.Equal(t, "", wrapped.Field)
+	assert.Equal(t, "memory count: count items: timeout", err.Error())
+}
+
+func TestWrapResult(t *testing.T) {
+	result, err := WrapResult("memory", "execute", &Result{}, ErrNoRecordsFound)
+	assert.True(t, errors.Is(err, ErrNoRecordsFound))
+	assert.Equal(t, err, result.Error)
+
+	result, err = WrapResult("memory", "execute", nil, ErrInvalidQuery)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+
+	result, err = WrapResult("memory", "execute", &Result{TotalItems: 1}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, result.Error)
+}
+
+func TestFieldError_Suggestions(t *testing.T) {
+	err := FieldNotAllowedError("catagory", "category", "price", "name")
+	assert.Equal(t, "field 'catagory': field not allowed (did you mean: category?)", err.Error())
+	assert.True(t, errors.Is(err, ErrFieldNotAllowed))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, []string{"category"}, fieldErr.Suggestions)
+
+	err = UnknownFieldError("secret", "category", "price")
+	assert.Equal(t, "field 'secret': unknown field", err.Error())
+	assert.True(t, errors.Is(err, ErrUnknownField))
+}
@@ -0,0 +1,51 @@
+package query
+
+// SearchTermExpander returns the terms a search term stands for, such as its
+// synonyms or its stem: "tv" -> ["tv", "television"], "mice" -> ["mouse"].
+// The returned terms replace the term, so include it to keep matching it.
+// A nil or empty result leaves the term as it is
+type SearchTermExpander func(term string) []string
+
+// ExpandSearchTerms rewrites the bare search terms and the CONTAINS and
+// ICONTAINS conditions of node with expand: each becomes an OR of the same
+// condition for every term expand returns. Conditions on array elements and
+// values that are not strings are left as they are. node is not modified.
+//
+// Example:
+//
+//	// "mice" with expand returning ["mouse", "mice"]
+//	// => "mouse" OR "mice"
+//	filter = ExpandSearchTerms(filter, expand)
+func ExpandSearchTerms(node Node, expand SearchTermExpander) Node {
+	if expand == nil {
+		return node
+	}
+	return Rewrite(node, func(node Node) Node {
+		n, ok := node.(*ComparisonNode)
+		if !ok || n.Modifier != ArrayModifierNone {
+			return node
+		}
+		if n.Operator != OpContains && n.Operator != OpIContains {
+			return node
+		}
+		term, ok := n.Value.(StringValue)
+		if !ok {
+			return node
+		}
+		terms := expand(string(term))
+		if len(terms) == 0 {
+			return node
+		}
+		var expanded Node
+		for _, t := range terms {
+			comparison := *n
+			comparison.Value = StringValue(t)
+			if expanded == nil {
+				expanded = &comparison
+				continue
+			}
+			expanded = &BinaryOpNode{Operator: BinaryOpOr, Left: expanded, Right: &comparison}
+		}
+		return expanded
+	})
+}
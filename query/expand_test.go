@@ -0,0 +1,51 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandSearchTerms(t *testing.T) {
+	synonyms := map[string][]string{
+		"tv":   {"tv", "television"},
+		"mice": {"mouse"},
+	}
+	expand := func(term string) []string { return synonyms[term] }
+
+	tests := []struct {
+		name   string
+		filter Node
+		want   string
+	}{
+		{"bare term", Search("tv"), `tv OR television`},
+		{"stem", And(Search("mice"), Lt("price", 10)), `mouse AND price < 10`},
+		{"contains", Compare("name", OpIContains, "tv"), `name ICONTAINS "tv" OR name ICONTAINS "television"`},
+		{"other operators", Eq("name", "tv"), `name = "tv"`},
+		{"unknown term", Search("radio"), `radio`},
+		{"array elements", &ComparisonNode{Field: "tags", Operator: OpContains, Value: StringValue("tv"), Modifier: ArrayModifierAny}, `tags ANY CONTAINS "tv"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatFilter(ExpandSearchTerms(tt.filter, expand)))
+		})
+	}
+
+	phrase := &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue("tv"), Phrase: true}
+	expanded := ExpandSearchTerms(phrase, expand).(*BinaryOpNode)
+	assert.True(t, expanded.Right.(*ComparisonNode).Phrase, "expanded phrases stay phrases")
+	assert.Equal(t, StringValue("tv"), phrase.Value, "the filter is not modified")
+
+	filter := Search("tv")
+	assert.Same(t, filter, ExpandSearchTerms(filter, nil))
+}
+
+func TestExecutorOptions_ScopedQuery_SearchTermExpander(t *testing.T) {
+	opts := &ExecutorOptions{
+		BaseFilter:         Compare("region", OpContains, "eu"),
+		SearchTermExpander: func(term string) []string { return []string{term, term + "s"} },
+	}
+	q := &Query{Filter: Search("cable")}
+	assert.Equal(t, `(cable OR cables) AND region CONTAINS "eu"`, FormatFilter(opts.ScopedQuery(q).Filter), "BaseFilter is not expanded")
+	assert.Equal(t, `cable`, FormatFilter(q.Filter))
+}
@@ -0,0 +1,151 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Explain describes how an executor ran a query. Executors fill it in
+// Result.Explain when Query.ExplainRequested is set; what a backend cannot
+// provide is left empty
+type Explain struct {
+	// Backend is the name of the executor, e.g. "GORM"
+	Backend string `json:"backend"`
+
+	// Statement is the generated query: SQL for GORM and ClickHouse, the filter
+	// as extended JSON for MongoDB and the filter in query syntax otherwise
+	Statement string `json:"statement,omitempty"`
+
+	// Args are the arguments bound to the placeholders of a SQL statement
+	Args []interface{} `json:"args,omitempty"`
+
+	// Plan is the backend's plan for the statement, such as the rows of a SQL
+	// EXPLAIN or the MongoDB winning plan, or how the memory executor scanned items
+	Plan string `json:"plan,omitempty"`
+
+	// PlanError is set when the plan could not be obtained; the query still ran
+	PlanError string `json:"plan_error,omitempty"`
+
+	// Indexes lists the indexes the query used or was hinted to use
+	Indexes []string `json:"indexes,omitempty"`
+}
+
+// FormatFilter renders a filter in query syntax, e.g. for logs and Explain.
+// A nil filter renders as an empty string
+func FormatFilter(node Node) string {
+	var sb strings.Builder
+	writeFilter(&sb, node, false, false)
+	return sb.String()
+}
+
+// NormalizeFilter renders a filter like FormatFilter with every value replaced
+// by ?, e.g. status = ? AND price > ?. Filters differing only in values render
+// the same, which suits span names and metric labels and keeps values out of
+// telemetry
+func NormalizeFilter(node Node) string {
+	var sb strings.Builder
+	writeFilter(&sb, node, false, true)
+	return sb.String()
+}
+
+// writeFilter writes node; nested parenthesizes binary operations and
+// normalize writes ? for values
+func writeFilter(sb *strings.Builder, node Node, nested, normalize bool) {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		if nested {
+			sb.WriteString("(")
+		}
+		writeFilter(sb, n.Left, true, normalize)
+		fmt.Fprintf(sb, " %s ", strings.ToUpper(n.Operator.String()))
+		writeFilter(sb, n.Right, true, normalize)
+		if nested {
+			sb.WriteString(")")
+		}
+	case *ComparisonNode:
+		if n.Modifier != ArrayModifierNone {
+			fmt.Fprintf(sb, "%s %s %s ", formatField(n.Field), n.Modifier, n.Operator)
+		} else if n.Field != SearchField {
+			fmt.Fprintf(sb, "%s %s ", formatField(n.Field), n.Operator)
+		}
+		if normalize {
+			sb.WriteString("?")
+			return
+		}
+		if word, ok := n.Value.(StringValue); ok && n.Field == SearchField && !n.Phrase && isBareWord(string(word)) {
+			// Quotes would make the term a phrase
+			sb.WriteString(string(word))
+			return
+		}
+		writeValue(sb, n.Value)
+	}
+}
+
+// formatField returns field as written in query syntax: as is when it is a
+// plain identifier and quoted with backticks otherwise, e.g. `order date`
+func formatField(field string) string {
+	for i, r := range field {
+		plain := unicode.IsLetter(r) || r == '_' || (i > 0 && (unicode.IsDigit(r) || unicode.IsMark(r) || r == '.'))
+		if !plain {
+			return "`" + strings.ReplaceAll(field, "`", "\\`") + "`"
+		}
+	}
+	return field
+}
+
+// reservedWords are the keywords of the query language, which bare terms
+// cannot be written as
+var reservedWords = map[string]bool{
+	"and": true, "or": true, "not": true, "like": true, "contains": true, "icontains": true,
+	"starts_with": true, "ends_with": true, "regex": true, "in": true, "match": true,
+}
+
+// isBareWord reports whether word can be written as an unquoted search term
+func isBareWord(word string) bool {
+	return word != "" && formatField(word) == word && !reservedWords[strings.ToLower(word)]
+}
+
+// formatFloat writes f without an exponent and with a decimal point, so it
+// parses back as the same FloatValue rather than an int or a search term
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		s += ".0"
+	}
+	return s
+}
+
+func writeValue(sb *strings.Builder, v interface{}) {
+	switch val := v.(type) {
+	case StringValue:
+		sb.WriteString(`"` + strings.ReplaceAll(string(val), `"`, `\"`) + `"`)
+	case IntValue:
+		sb.WriteString(strconv.FormatInt(int64(val), 10))
+	case FloatValue:
+		sb.WriteString(formatFloat(float64(val)))
+	case BoolValue:
+		sb.WriteString(strconv.FormatBool(bool(val)))
+	case DateTimeValue:
+		// The parser reads times without an offset as UTC
+		sb.WriteString(time.Time(val).UTC().Format("2006-01-02T15:04:05.999999999"))
+	case PlaceholderValue:
+		sb.WriteString("@" + string(val))
+	case ParameterValue:
+		sb.WriteString(":" + string(val))
+	case ArrayValue:
+		sb.WriteString("[")
+		for i, elem := range val {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeValue(sb, elem)
+		}
+		sb.WriteString("]")
+	default:
+		fmt.Fprintf(sb, "%v", val)
+	}
+}
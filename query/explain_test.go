@@ -0,0 +1,45 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatFilter(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter Node
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"comparison", Eq("status", "active"), `status = "active"`},
+		{"escaped quote", Eq("name", `say "hi"`), `name = "say \"hi\""`},
+		{"numbers", And(Gt("price", 10), Lte("rating", 4.5)), `price > 10 AND rating <= 4.5`},
+		{"nested", Or(Eq("a", true), And(Eq("b", 1), Eq("c", 2))), `a = true OR (b = 1 AND c = 2)`},
+		{"array", In("brand", "Sony", "JBL"), `brand IN ["Sony", "JBL"]`},
+		{"datetime", F("created").Gte(created).Node(), `created >= 2024-01-02T03:04:05`},
+		{"datetime in UTC", F("created").Gte(created.Add(250 * time.Millisecond).In(time.FixedZone("", 2*3600))).Node(), `created >= 2024-01-02T03:04:05.25`},
+		{"floats", And(Eq("price", 1000000.5), And(Eq("x", -0.000001), Eq("y", 3.0))), `price = 1000000.5 AND (x = -0.000001 AND y = 3.0)`},
+		{"placeholder", &ComparisonNode{Field: "id", Operator: OpEqual, Value: PlaceholderValue("id")}, `id = @id`},
+		{"search", Search("headphones"), `headphones`},
+		{"phrase", &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue("usb cable"), Phrase: true}, `"usb cable"`},
+		{"search keyword", Search("and"), `"and"`},
+		{"dotted field", Eq("author.name", "x"), `author.name = "x"`},
+		{"quoted field", And(Eq("order date", 1), Eq("a`b", 2)), "`order date` = 1 AND `a\\`b` = 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatFilter(tt.filter))
+		})
+	}
+}
+
+func TestNormalizeFilter(t *testing.T) {
+	filter := And(Or(Eq("status", "active"), In("brand", "Sony", "JBL")), Search("headphones"))
+	assert.Equal(t, `(status = ? OR brand IN ?) AND ?`, NormalizeFilter(filter))
+	assert.Equal(t, NormalizeFilter(Eq("a", 1)), NormalizeFilter(Eq("a", "x")))
+	assert.Equal(t, "", NormalizeFilter(nil))
+}
@@ -0,0 +1,232 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// FacetSpec requests the bucket counts of one field under a query's filter,
+// e.g. the number of matching products of each brand for a filter sidebar
+type FacetSpec struct {
+	// Field is the field to count
+	Field string
+
+	// Ranges buckets the values of Field into ranges, e.g. price bands.
+	// Without ranges every distinct value is a bucket
+	Ranges []FacetRange
+
+	// Interval buckets numbers into a histogram of bins this wide; each bin
+	// starts at a multiple of Interval
+	Interval float64
+
+	// DateInterval buckets times by calendar unit, e.g. created_at by day
+	DateInterval DateInterval
+
+	// Limit keeps the most frequent value buckets. It does not apply to
+	// ranges or histograms. 0 means no limit
+	Limit int
+}
+
+// IsHistogram reports whether the spec buckets by Interval or DateInterval
+func (s FacetSpec) IsHistogram() bool {
+	return s.Interval != 0 || s.DateInterval != ""
+}
+
+// DateInterval is the calendar unit of a date histogram. Times are bucketed
+// in UTC
+type DateInterval string
+
+const (
+	DateIntervalHour  DateInterval = "hour"
+	DateIntervalDay   DateInterval = "day"
+	DateIntervalWeek  DateInterval = "week" // weeks start on Monday
+	DateIntervalMonth DateInterval = "month"
+	DateIntervalYear  DateInterval = "year"
+)
+
+// Truncate returns the start of the bucket holding t, in UTC
+func (d DateInterval) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	y, m, day := t.Date()
+	switch d {
+	case DateIntervalHour:
+		return t.Truncate(time.Hour)
+	case DateIntervalDay:
+		return time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+	case DateIntervalWeek:
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		return time.Date(y, m, day-offset, 0, 0, 0, 0, time.UTC)
+	case DateIntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case DateIntervalYear:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// valid reports whether d is one of the DateInterval constants
+func (d DateInterval) valid() bool {
+	switch d {
+	case DateIntervalHour, DateIntervalDay, DateIntervalWeek, DateIntervalMonth, DateIntervalYear:
+		return true
+	}
+	return false
+}
+
+// HistogramBin returns the start of the Interval-wide bin holding v
+func HistogramBin(v, interval float64) float64 {
+	return math.Floor(v/interval) * interval
+}
+
+// FacetRange is one range bucket: From <= value < To. A nil bound leaves that
+// side open. Bounds are numbers or times, like the values of a query
+type FacetRange struct {
+	// Label names the bucket in the result; see Name
+	Label string
+
+	From interface{}
+	To   interface{}
+}
+
+// Name returns Label, or the bounds of the range when it has none, e.g.
+// "10-50", "*-10" or "50-*"
+func (r FacetRange) Name() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	bound := func(v interface{}) string {
+		switch val := toValue(v).(type) {
+		case nil:
+			return "*"
+		case DateTimeValue:
+			return time.Time(val).Format(time.RFC3339)
+		default:
+			return fmt.Sprintf("%v", val)
+		}
+	}
+	return bound(r.From) + "-" + bound(r.To)
+}
+
+// FacetBucket is the number of matching items with one value, in one range
+// or in one histogram bin
+type FacetBucket struct {
+	// Value is the field value as the backend returns it, nil for items
+	// without one, the Name of the range for range facets, or the start of
+	// the bin for histograms: a float64 for Interval and a UTC time.Time for
+	// DateInterval
+	Value interface{}
+	Count int64
+}
+
+// FacetResult holds the buckets of one FacetSpec. Value buckets come most
+// frequent first; range buckets come in the order of FacetSpec.Ranges;
+// histogram bins come in ascending order and empty bins are left out
+type FacetResult struct {
+	Field   string
+	Buckets []FacetBucket
+}
+
+// ValidateFacets checks facet requests before executors count them: fields
+// must be allowed, pass FieldAuthorizer with SortOperator, range bounds must
+// be numbers or times, and histograms need a positive Interval or a known
+// DateInterval. It returns the specs with range bounds converted
+// to query values
+func (o *ExecutorOptions) ValidateFacets(ctx context.Context, specs []FacetSpec) ([]FacetSpec, error) {
+	validated := make([]FacetSpec, len(specs))
+	for i, spec := range specs {
+		if spec.Field == "" {
+			return nil, fmt.Errorf("%w: facet without a field", ErrInvalidQuery)
+		}
+		if !o.IsFieldAllowed(spec.Field) {
+			return nil, FieldNotAllowedError(spec.Field, o.AllowedFields...)
+		}
+		if o.FieldAuthorizer != nil {
+			if err := o.FieldAuthorizer(ctx, spec.Field, SortOperator); err != nil {
+				return nil, err
+			}
+		}
+		if spec.Limit < 0 {
+			return nil, NewFieldError(spec.Field, fmt.Errorf("%w: negative facet limit %d", ErrInvalidQuery, spec.Limit))
+		}
+		if err := validateHistogram(spec); err != nil {
+			return nil, NewFieldError(spec.Field, err)
+		}
+		validated[i] = spec
+		if len(spec.Ranges) == 0 {
+			continue
+		}
+		validated[i].Ranges = make([]FacetRange, len(spec.Ranges))
+		for j, r := range spec.Ranges {
+			from, to := toValue(r.From), toValue(r.To)
+			if (from == nil && to == nil) || !isFacetBound(from) || !isFacetBound(to) {
+				return nil, NewFieldError(spec.Field, fmt.Errorf("%w: facet range %s needs number or time bounds", ErrInvalidQuery, r.Name()))
+			}
+			validated[i].Ranges[j] = FacetRange{Label: r.Name(), From: from, To: to}
+		}
+	}
+	return validated, nil
+}
+
+// validateHistogram checks that a spec buckets in at most one way and that its
+// interval is usable
+func validateHistogram(spec FacetSpec) error {
+	kinds := 0
+	for _, set := range []bool{len(spec.Ranges) > 0, spec.Interval != 0, spec.DateInterval != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return fmt.Errorf("%w: facet sets more than one of Ranges, Interval and DateInterval", ErrInvalidQuery)
+	}
+	if spec.Interval < 0 || math.IsNaN(spec.Interval) || math.IsInf(spec.Interval, 0) {
+		return fmt.Errorf("%w: invalid histogram interval %v", ErrInvalidQuery, spec.Interval)
+	}
+	if spec.DateInterval != "" && !spec.DateInterval.valid() {
+		return fmt.Errorf("%w: unknown date interval %q", ErrInvalidQuery, spec.DateInterval)
+	}
+	return nil
+}
+
+// isFacetBound reports whether v can bound a facet range; nil is an open bound
+func isFacetBound(v interface{}) bool {
+	switch v.(type) {
+	case nil, IntValue, FloatValue, DateTimeValue:
+		return true
+	}
+	return false
+}
+
+// SortHistogramBuckets orders histogram bins by their start, ascending
+func SortHistogramBuckets(buckets []FacetBucket) []FacetBucket {
+	sort.SliceStable(buckets, func(i, j int) bool {
+		switch a := buckets[i].Value.(type) {
+		case float64:
+			b, _ := buckets[j].Value.(float64)
+			return a < b
+		case time.Time:
+			b, _ := buckets[j].Value.(time.Time)
+			return a.Before(b)
+		}
+		return false
+	})
+	return buckets
+}
+
+// SortFacetBuckets orders value buckets most frequent first, then by value,
+// and keeps the first limit of them (0 keeps all)
+func SortFacetBuckets(buckets []FacetBucket, limit int) []FacetBucket {
+	sort.SliceStable(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return fmt.Sprintf("%v", buckets[i].Value) < fmt.Sprintf("%v", buckets[j].Value)
+	})
+	if limit > 0 && len(buckets) > limit {
+		buckets = buckets[:limit]
+	}
+	return buckets
+}
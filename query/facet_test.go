@@ -0,0 +1,103 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFacetRange_Name(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "cheap", FacetRange{Label: "cheap", To: 10}.Name())
+	assert.Equal(t, "*-10", FacetRange{To: 10}.Name())
+	assert.Equal(t, "10-50.5", FacetRange{From: 10, To: 50.5}.Name())
+	assert.Equal(t, "2024-01-01T00:00:00Z-*", FacetRange{From: since}.Name())
+}
+
+func TestExecutorOptions_ValidateFacets(t *testing.T) {
+	ctx := context.Background()
+	opts := DefaultExecutorOptions()
+	opts.AllowedFields = []string{"brand", "price"}
+
+	specs, err := opts.ValidateFacets(ctx, []FacetSpec{{Field: "price", Ranges: []FacetRange{{From: 10}}}})
+	require.NoError(t, err)
+	assert.Equal(t, []FacetRange{{Label: "10-*", From: IntValue(10)}}, specs[0].Ranges)
+
+	_, err = opts.ValidateFacets(ctx, []FacetSpec{{Field: "secret"}})
+	assert.ErrorIs(t, err, ErrFieldNotAllowed)
+	_, err = opts.ValidateFacets(ctx, []FacetSpec{{}})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = opts.ValidateFacets(ctx, []FacetSpec{{Field: "brand", Limit: -1}})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = opts.ValidateFacets(ctx, []FacetSpec{{Field: "price", Ranges: []FacetRange{{From: "low"}}}})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	denied := errors.New("denied")
+	opts.FieldAuthorizer = func(ctx context.Context, field string, op ComparisonOperator) error {
+		if field == "price" && op == SortOperator {
+			return denied
+		}
+		return nil
+	}
+	_, err = opts.ValidateFacets(ctx, []FacetSpec{{Field: "price"}})
+	assert.ErrorIs(t, err, denied)
+}
+
+func TestSortFacetBuckets(t *testing.T) {
+	buckets := SortFacetBuckets([]FacetBucket{
+		{Value: "b", Count: 1},
+		{Value: "c", Count: 2},
+		{Value: "a", Count: 1},
+	}, 2)
+	assert.Equal(t, []FacetBucket{{Value: "c", Count: 2}, {Value: "a", Count: 1}}, buckets)
+}
+
+func TestDateInterval_Truncate(t *testing.T) {
+	// Wednesday 2024-01-10 13:45 in UTC+2
+	at := time.Date(2024, 1, 10, 15, 45, 0, 0, time.FixedZone("EET", 2*3600))
+	assert.Equal(t, time.Date(2024, 1, 10, 13, 0, 0, 0, time.UTC), DateIntervalHour.Truncate(at))
+	assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), DateIntervalDay.Truncate(at))
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), DateIntervalWeek.Truncate(at))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), DateIntervalMonth.Truncate(at))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), DateIntervalYear.Truncate(at))
+
+	// Sundays belong to the week that started the Monday before
+	sunday := time.Date(2024, 1, 14, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), DateIntervalWeek.Truncate(sunday))
+}
+
+func TestHistogramBin(t *testing.T) {
+	assert.Equal(t, 20.0, HistogramBin(39.99, 20))
+	assert.Equal(t, -20.0, HistogramBin(-0.5, 20))
+	assert.Equal(t, 0.5, HistogramBin(0.75, 0.5))
+}
+
+func TestExecutorOptions_ValidateHistograms(t *testing.T) {
+	ctx := context.Background()
+	opts := DefaultExecutorOptions()
+
+	_, err := opts.ValidateFacets(ctx, []FacetSpec{
+		{Field: "price", Interval: 10},
+		{Field: "created_at", DateInterval: DateIntervalMonth},
+	})
+	require.NoError(t, err)
+
+	for _, spec := range []FacetSpec{
+		{Field: "price", Interval: -1},
+		{Field: "created_at", DateInterval: "fortnight"},
+		{Field: "price", Interval: 10, Ranges: []FacetRange{{To: 10}}},
+		{Field: "created_at", Interval: 10, DateInterval: DateIntervalDay},
+	} {
+		_, err := opts.ValidateFacets(ctx, []FacetSpec{spec})
+		assert.ErrorIs(t, err, ErrInvalidQuery, "%+v", spec)
+	}
+}
+
+func TestSortHistogramBuckets(t *testing.T) {
+	buckets := SortHistogramBuckets([]FacetBucket{{Value: 20.0, Count: 1}, {Value: -10.0, Count: 4}})
+	assert.Equal(t, []FacetBucket{{Value: -10.0, Count: 4}, {Value: 20.0, Count: 1}}, buckets)
+}
@@ -0,0 +1,59 @@
+package query
+
+import "sort"
+
+// Fields returns the fields q references, sorted and without duplicates: the
+// fields of its filter conditions, sort_by and distinct_on. Bare search terms
+// are reported as SearchField; ExecutorOptions.Fields expands them to the
+// configured search fields. The pseudo sort fields ScoreField and
+// MatchCountField are left out. Use it to check a query against a schema or
+// to decide which indexes, joins or preloads it needs before executing it.
+func Fields(q *Query) []string {
+	if q == nil {
+		return nil
+	}
+	return collectFields(q.Filter, q, nil)
+}
+
+// Fields returns the fields q references when it runs with these options:
+// Fields(q) with bare search terms expanded to SearchFields and the fields of
+// BaseFilter added
+func (o *ExecutorOptions) Fields(q *Query) []string {
+	if q == nil {
+		return nil
+	}
+	return collectFields(And(q.Filter, o.BaseFilter), q, o.SearchFields())
+}
+
+// collectFields returns the fields of filter and of the options of q, with
+// SearchField replaced by searchFields when they are given
+func collectFields(filter Node, q *Query, searchFields []string) []string {
+	set := make(map[string]bool)
+	Walk(filter, func(node Node) bool {
+		n, ok := node.(*ComparisonNode)
+		if !ok {
+			return true
+		}
+		if n.Field == SearchField && len(searchFields) > 0 {
+			for _, field := range searchFields {
+				set[field] = true
+			}
+		} else {
+			set[n.Field] = true
+		}
+		return true
+	})
+	if q.SortBy != "" && q.SortBy != ScoreField && q.SortBy != MatchCountField {
+		set[q.SortBy] = true
+	}
+	if q.DistinctOn != "" {
+		set[q.DistinctOn] = true
+	}
+
+	fields := make([]string, 0, len(set))
+	for field := range set {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,35 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields(t *testing.T) {
+	q := &Query{
+		Filter:     And(Eq("status", "active"), Or(Gt("price", 10), And(Search("usb"), Lt("price", 5)))),
+		SortBy:     "created_at",
+		DistinctOn: "brand",
+	}
+	assert.Equal(t, []string{SearchField, "brand", "created_at", "price", "status"}, Fields(q))
+
+	t.Run("pseudo sort fields are left out", func(t *testing.T) {
+		assert.Equal(t, []string{"name"}, Fields(&Query{Filter: F("name").Contains("usb").Node(), SortBy: ScoreField}))
+		assert.Equal(t, []string{"name"}, Fields(&Query{Filter: F("name").Contains("usb").Node(), SortBy: MatchCountField}))
+	})
+
+	t.Run("with executor options", func(t *testing.T) {
+		opts := &ExecutorOptions{DefaultSearchFields: []string{"title", "description"}, BaseFilter: Eq("tenant_id", 5)}
+		assert.Equal(t, []string{"brand", "created_at", "description", "price", "status", "tenant_id", "title"}, opts.Fields(q))
+
+		opts = &ExecutorOptions{DefaultSearchField: "name"}
+		assert.Equal(t, []string{"name"}, opts.Fields(&Query{Filter: Search("usb")}))
+
+		// Without search fields the marker is kept
+		assert.Equal(t, []string{SearchField}, (&ExecutorOptions{}).Fields(&Query{Filter: Search("usb")}))
+	})
+
+	assert.Nil(t, Fields(nil))
+	assert.Empty(t, Fields(&Query{}))
+}
@@ -0,0 +1,217 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format renders q in query syntax with normalized spacing, every nested AND
+// and OR in parentheses, and the options that differ from their defaults
+// after the filter, e.g.
+//
+//	(brand = "Sony" OR price < 10) AND featured = true sort_by = price page_size = 20
+//
+// The result parses back to an equivalent query. Floats always have a decimal
+// point and no exponent, times are rendered in UTC with their fractional
+// seconds, and relative times are rendered as written. A nil query renders as
+// an empty string
+func Format(q *Query) string {
+	if q == nil {
+		return ""
+	}
+	parts := []string{}
+	if filter := FormatFilter(q.StableFilter()); filter != "" {
+		parts = append(parts, filter)
+	}
+	for _, option := range queryOptions(q) {
+		parts = append(parts, option[0]+" = "+option[1])
+	}
+	return strings.Join(parts, " ")
+}
+
+// queryOptions returns the name and value of the options of q that differ
+// from their defaults, in the order Format writes them
+func queryOptions(q *Query) [][2]string {
+	var options [][2]string
+	add := func(name, value string) {
+		options = append(options, [2]string{name, value})
+	}
+	if q.SortBy != "" {
+		add("sort_by", formatField(q.SortBy))
+	}
+	if q.SortOrder != SortOrderAsc {
+		add("sort_order", q.SortOrder.String())
+	}
+	if q.PageSize > 0 {
+		add("page_size", strconv.Itoa(q.PageSize))
+	}
+	if q.Page > 1 {
+		add("page", strconv.Itoa(q.Page))
+	}
+	if q.Limit > 0 {
+		add("limit", strconv.Itoa(q.Limit))
+	}
+	if q.PreserveInOrder {
+		add("preserve_in_order", "true")
+	}
+	if q.IncludeDeleted {
+		add("include_deleted", "true")
+	}
+	if q.Distinct {
+		add("distinct", "true")
+	}
+	if q.DistinctOn != "" {
+		add("distinct_on", formatField(q.DistinctOn))
+	}
+	if q.RandomSeed != 0 {
+		add("random_seed", strconv.FormatInt(q.RandomSeed, 10))
+	}
+	return options
+}
+
+// DumpAST renders the syntax tree of q as an indented tree, one node per line,
+// with the type of each value, followed by the options. Use it to see how a
+// query was grouped:
+//
+//	AND
+//	  OR
+//	    brand = "Sony" (string)
+//	    price < 10 (int)
+//	  featured = true (bool)
+//	sort_by = price
+func DumpAST(q *Query) string {
+	if q == nil {
+		return ""
+	}
+	var sb strings.Builder
+	dumpNode(&sb, q.StableFilter(), 0)
+	for _, option := range queryOptions(q) {
+		fmt.Fprintf(&sb, "%s = %s\n", option[0], option[1])
+	}
+	return sb.String()
+}
+
+func dumpNode(sb *strings.Builder, node Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		fmt.Fprintf(sb, "%s%s\n", indent, strings.ToUpper(n.Operator.String()))
+		dumpNode(sb, n.Left, depth+1)
+		dumpNode(sb, n.Right, depth+1)
+	case *ComparisonNode:
+		if n.Field == SearchField {
+			kind := "SEARCH"
+			if n.Phrase {
+				kind = "PHRASE"
+			}
+			fmt.Fprintf(sb, "%s%s %s (%s)\n", indent, kind, formatValue(n.Value), valueType(n.Value))
+			return
+		}
+		fmt.Fprintf(sb, "%s%s (%s)\n", indent, FormatFilter(n), valueType(n.Value))
+	}
+}
+
+// astNode is the JSON form of a node written by DumpASTJSON
+type astNode struct {
+	// Type is "and", "or", "comparison", "search" or "phrase"
+	Type      string   `json:"type"`
+	Left      *astNode `json:"left,omitempty"`
+	Right     *astNode `json:"right,omitempty"`
+	Field     string   `json:"field,omitempty"`
+	Operator  string   `json:"operator,omitempty"`
+	Modifier  string   `json:"modifier,omitempty"`
+	Value     string   `json:"value,omitempty"`
+	ValueType string   `json:"value_type,omitempty"`
+}
+
+// DumpASTJSON is DumpAST as indented JSON: the filter tree under "filter",
+// with values in query syntax, and the options under "options"
+func DumpASTJSON(q *Query) ([]byte, error) {
+	out := struct {
+		Filter  *astNode          `json:"filter"`
+		Options map[string]string `json:"options,omitempty"`
+	}{}
+	if q != nil {
+		out.Filter = toASTNode(q.StableFilter())
+		for _, option := range queryOptions(q) {
+			if out.Options == nil {
+				out.Options = make(map[string]string)
+			}
+			out.Options[option[0]] = option[1]
+		}
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+func toASTNode(node Node) *astNode {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		return &astNode{Type: n.Operator.String(), Left: toASTNode(n.Left), Right: toASTNode(n.Right)}
+	case *ComparisonNode:
+		a := &astNode{Type: "comparison", Field: n.Field, Operator: n.Operator.String(), Value: formatValue(n.Value), ValueType: valueType(n.Value)}
+		if n.Field == SearchField {
+			a.Type, a.Field, a.Operator = "search", "", ""
+			if n.Phrase {
+				a.Type = "phrase"
+			}
+		}
+		if n.Modifier != ArrayModifierNone {
+			a.Modifier = n.Modifier.String()
+		}
+		return a
+	}
+	return nil
+}
+
+func formatValue(v interface{}) string {
+	var sb strings.Builder
+	writeValue(&sb, v)
+	return sb.String()
+}
+
+// valueType names the type of a value in DumpAST
+func valueType(v interface{}) string {
+	switch val := v.(type) {
+	case StringValue:
+		return "string"
+	case IntValue:
+		return "int"
+	case FloatValue:
+		return "float"
+	case BoolValue:
+		return "bool"
+	case DateTimeValue:
+		return "datetime"
+	case RelativeTimeValue:
+		return "relative time"
+	case PlaceholderValue:
+		return "placeholder"
+	case ParameterValue:
+		return "parameter"
+	case ArrayValue:
+		if len(val) == 0 {
+			return "array"
+		}
+		types := make([]string, len(val))
+		for i, elem := range val {
+			types[i] = valueType(elem)
+		}
+		return "array of " + strings.Join(uniqueStrings(types), ", ")
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,65 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	q := &Query{
+		Filter:    And(Or(Eq("brand", "Sony"), Lt("price", 10)), Eq("featured", true)),
+		SortBy:    "price",
+		SortOrder: SortOrderDesc,
+		PageSize:  20,
+	}
+	assert.Equal(t, `(brand = "Sony" OR price < 10) AND featured = true sort_by = price sort_order = desc page_size = 20`, Format(q))
+
+	assert.Equal(t, "page_size = 5", Format(&Query{PageSize: 5}))
+	assert.Equal(t, "", Format(&Query{}))
+	assert.Equal(t, "", Format(nil))
+}
+
+func TestDumpAST(t *testing.T) {
+	q := &Query{
+		Filter: And(Or(Eq("brand", "Sony"), Lt("price", 10)), And(Search("usb"), In("tags", "a", 2))),
+		SortBy: "price",
+	}
+	assert.Equal(t, `AND
+  OR
+    brand = "Sony" (string)
+    price < 10 (int)
+  AND
+    SEARCH "usb" (string)
+    tags IN ["a", 2] (array of string, int)
+sort_by = price
+`, DumpAST(q))
+
+	data, err := DumpASTJSON(q)
+	require.NoError(t, err)
+	var dump struct {
+		Filter struct {
+			Type string `json:"type"`
+			Left struct {
+				Type string `json:"type"`
+				Left struct {
+					Field     string `json:"field"`
+					Operator  string `json:"operator"`
+					Value     string `json:"value"`
+					ValueType string `json:"value_type"`
+				} `json:"left"`
+			} `json:"left"`
+		} `json:"filter"`
+		Options map[string]string `json:"options"`
+	}
+	require.NoError(t, json.Unmarshal(data, &dump))
+	assert.Equal(t, "and", dump.Filter.Type)
+	assert.Equal(t, "or", dump.Filter.Left.Type)
+	assert.Equal(t, "brand", dump.Filter.Left.Left.Field)
+	assert.Equal(t, "=", dump.Filter.Left.Left.Operator)
+	assert.Equal(t, `"Sony"`, dump.Filter.Left.Left.Value)
+	assert.Equal(t, "string", dump.Filter.Left.Left.ValueType)
+	assert.Equal(t, map[string]string{"sort_by": "price"}, dump.Options)
+}
@@ -0,0 +1,118 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Highlight marks a fragment of a field value that matched a condition of the
+// filter, so UIs can emphasize it without matching the query again. Offsets
+// count runes, not bytes
+type Highlight struct {
+	// Field is the field whose value matched
+	Field string `json:"field"`
+
+	// Start is the offset of the first rune of the fragment
+	Start int `json:"start"`
+
+	// End is the offset just past the last rune of the fragment
+	End int `json:"end"`
+}
+
+// MatchSpans returns the fragments of text matched by the condition n as
+// [start, end) rune offsets, in order: every occurrence of the value for
+// CONTAINS and ICONTAINS, the literal parts of the pattern for LIKE and every
+// occurrence of the terms for MATCH. Other operators, conditions on array
+// elements and text the condition does not match return nil.
+//
+// Example:
+//
+//	n := Compare("name", OpIContains, "usb")
+//	MatchSpans(n, "USB-C to USB cable") // [[0 3] [9 12]]
+func MatchSpans(n *ComparisonNode, text string) [][2]int {
+	if n.Modifier != ArrayModifierNone {
+		return nil
+	}
+	value := fmt.Sprintf("%v", n.Value)
+	var spans [][2]int
+	switch n.Operator {
+	case OpContains:
+		for offset := 0; value != ""; {
+			i := strings.Index(text[offset:], value)
+			if i < 0 {
+				break
+			}
+			spans = append(spans, [2]int{offset + i, offset + i + len(value)})
+			offset += i + len(value)
+		}
+	case OpIContains:
+		if value != "" {
+			// (?i) keeps the offsets of text, which lowercasing may shift
+			for _, loc := range regexp.MustCompile(`(?i)`+regexp.QuoteMeta(value)).FindAllStringIndex(text, -1) {
+				spans = append(spans, [2]int{loc[0], loc[1]})
+			}
+		}
+	case OpLike:
+		spans = likeSpans(text, value)
+	case OpMatch:
+		terms := make(map[string]bool)
+		for _, term := range SearchTerms(value) {
+			terms[term] = true
+		}
+		start := -1
+		for i, r := range text + " " {
+			if !isTermSeparator(r) {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			if start >= 0 && terms[strings.ToLower(text[start:i])] {
+				spans = append(spans, [2]int{start, i})
+			}
+			start = -1
+		}
+	}
+	return runeSpans(text, spans)
+}
+
+// likeSpans returns the byte offsets of the parts of text matched by the
+// literal runs of the LIKE pattern, the parts between % wildcards
+func likeSpans(text, pattern string) [][2]int {
+	re, err := CompileLike(pattern)
+	if err != nil || !re.MatchString(text) {
+		return nil
+	}
+	parts := strings.Split(pattern, "%")
+	var spans [][2]int
+	offset := 0
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		expr := strings.ReplaceAll(regexp.QuoteMeta(part), "_", ".")
+		switch {
+		case i == 0:
+			expr = "^" + expr
+		case i == len(parts)-1:
+			expr += "$"
+		}
+		loc := regexp.MustCompile(expr).FindStringIndex(text[offset:])
+		if loc == nil {
+			return nil
+		}
+		spans = append(spans, [2]int{offset + loc[0], offset + loc[1]})
+		offset += loc[1]
+	}
+	return spans
+}
+
+// runeSpans converts byte offsets into text to rune offsets
+func runeSpans(text string, spans [][2]int) [][2]int {
+	for i, span := range spans {
+		spans[i] = [2]int{utf8.RuneCountInString(text[:span[0]]), utf8.RuneCountInString(text[:span[1]])}
+	}
+	return spans
+}
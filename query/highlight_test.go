@@ -0,0 +1,31 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchSpans(t *testing.T) {
+	tests := []struct {
+		name string
+		node *ComparisonNode
+		text string
+		want [][2]int
+	}{
+		{"contains", Compare("name", OpContains, "USB").(*ComparisonNode), "USB-C to USB cable", [][2]int{{0, 3}, {9, 12}}},
+		{"contains is case sensitive", Compare("name", OpContains, "usb").(*ComparisonNode), "USB cable", nil},
+		{"icontains", Compare("name", OpIContains, "usb").(*ComparisonNode), "USB-C to usb cable", [][2]int{{0, 3}, {9, 12}}},
+		{"rune offsets", Compare("name", OpIContains, "کابل").(*ComparisonNode), "یک کابل", [][2]int{{3, 7}}},
+		{"like", Compare("name", OpLike, "usb%cable%").(*ComparisonNode), "usb-c cable x", [][2]int{{0, 3}, {6, 11}}},
+		{"like suffix", Compare("name", OpLike, "%.go").(*ComparisonNode), "a.go.go", [][2]int{{4, 7}}},
+		{"like no match", Compare("name", OpLike, "usb%").(*ComparisonNode), "a usb", nil},
+		{"match", Compare("name", OpMatch, "usb cable").(*ComparisonNode), "USB-C cable, usb", [][2]int{{0, 3}, {6, 11}, {13, 16}}},
+		{"other operators", Eq("name", "usb").(*ComparisonNode), "usb", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchSpans(tt.node, tt.text))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package query
+
+import "fmt"
+
+// InOrderCondition returns the IN condition whose value order determines the
+// result order of a query with PreserveInOrder set, or nil when the option is off.
+//
+// The condition is the first IN comparison that must hold for every result,
+// i.e. the filter itself or one of its top-level AND operands. The option
+// replaces sorting, so it cannot be combined with sort_by or random order.
+//
+// Example:
+//
+//	// id IN [5, 1, 9] AND status = active preserve_in_order = true
+//	// => results ordered 5, 1, 9
+//	n, err := InOrderCondition(q)
+func InOrderCondition(q *Query) (*ComparisonNode, error) {
+	if q == nil || !q.PreserveInOrder {
+		return nil, nil
+	}
+	if q.SortBy != "" {
+		return nil, fmt.Errorf("%w: preserve_in_order cannot be combined with sort_by", ErrInvalidQuery)
+	}
+	if q.SortOrder == SortOrderRandom {
+		return nil, fmt.Errorf("%w: preserve_in_order cannot be combined with random order", ErrInvalidQuery)
+	}
+	if n := findInCondition(q.Filter); n != nil {
+		return n, nil
+	}
+	return nil, fmt.Errorf("%w: preserve_in_order requires an IN condition joined with AND", ErrInvalidQuery)
+}
+
+// findInCondition searches the node and its AND operands, left to right
+func findInCondition(node Node) *ComparisonNode {
+	switch n := node.(type) {
+	case *ComparisonNode:
+		if n.Operator == OpIn && n.Modifier == ArrayModifierNone {
+			return n
+		}
+	case *BinaryOpNode:
+		if n.Operator != BinaryOpAnd {
+			return nil
+		}
+		if found := findInCondition(n.Left); found != nil {
+			return found
+		}
+		return findInCondition(n.Right)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInOrderCondition(t *testing.T) {
+	ids := &ComparisonNode{Field: "id", Operator: OpIn, Value: ArrayValue{IntValue(5), IntValue(1)}}
+	status := &ComparisonNode{Field: "status", Operator: OpEqual, Value: StringValue("active")}
+	tags := &ComparisonNode{Field: "tag", Operator: OpIn, Value: ArrayValue{StringValue("a")}}
+
+	t.Run("disabled", func(t *testing.T) {
+		n, err := InOrderCondition(&Query{Filter: ids})
+		require.NoError(t, err)
+		assert.Nil(t, n)
+
+		n, err = InOrderCondition(nil)
+		require.NoError(t, err)
+		assert.Nil(t, n)
+	})
+
+	t.Run("first IN among AND operands", func(t *testing.T) {
+		filter := And(status, ids, tags)
+		n, err := InOrderCondition(&Query{Filter: filter, PreserveInOrder: true})
+		require.NoError(t, err)
+		assert.Same(t, ids, n)
+	})
+
+	tests := []struct {
+		name string
+		q    *Query
+	}{
+		{"no IN", &Query{Filter: status, PreserveInOrder: true}},
+		{"IN under OR", &Query{Filter: Or(ids, status), PreserveInOrder: true}},
+		{"NOT IN", &Query{Filter: &ComparisonNode{Field: "id", Operator: OpNotIn, Value: ArrayValue{}}, PreserveInOrder: true}},
+		{"with sort_by", &Query{Filter: ids, SortBy: "name", PreserveInOrder: true}},
+		{"with random order", &Query{Filter: ids, SortOrder: SortOrderRandom, PreserveInOrder: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := InOrderCondition(tt.q)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+		})
+	}
+}
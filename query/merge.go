@@ -0,0 +1,116 @@
+package query
+
+// Merge returns a query matching items that match both q and other, with
+// their options reconciled; see AndQueries. Neither query is modified.
+//
+// Example:
+//
+//	// Route constraints applied to the user's query
+//	q = userQuery.Merge(&query.Query{Filter: query.Eq("published", true), PageSize: 20})
+func (q *Query) Merge(other *Query) *Query {
+	return AndQueries(q, other)
+}
+
+// AndQueries combines queries so items must match all of their filters. Each
+// filter stays grouped, so an OR in one cannot escape the others. nil queries
+// are skipped; the result is nil when all are nil. Options are reconciled:
+//
+//   - PageSize and Limit take the smallest value set (0 counts as unset).
+//     Parsed and built queries default to a page size of 10; set PageSize
+//     to 0 in constraint queries that should not cap it
+//   - Page is taken from the last query that sets it
+//   - an explicit sort (sort_by, random order and its seed, or preserve_in_order) wins
+//     over none; between explicit sorts the last one wins, so pass the query
+//     whose order must apply last. distinct_on is resolved the same way
+//   - Distinct, ExplainRequested and HighlightRequested are set if any query sets them,
+//     IncludeDeleted only if all of them do
+//   - Metadata is merged; later queries win on key conflicts
+func AndQueries(queries ...*Query) *Query {
+	return combineQueries(BinaryOpAnd, queries)
+}
+
+// OrQueries combines queries so items may match any of their filters, with
+// options reconciled like in AndQueries. A query without a filter matches
+// every item, and so does the result
+func OrQueries(queries ...*Query) *Query {
+	return combineQueries(BinaryOpOr, queries)
+}
+
+// combineQueries joins the filters of queries with op and reconciles their options
+func combineQueries(op BinaryOperator, queries []*Query) *Query {
+	var combined *Query
+	var filters, stable []Node
+	unresolved := false
+	for _, q := range queries {
+		if q == nil {
+			continue
+		}
+		filters = append(filters, q.Filter)
+		stable = append(stable, q.StableFilter())
+		unresolved = unresolved || q.unresolved != nil
+		if combined == nil {
+			first := *q
+			first.Metadata = copyMetadata(q.Metadata)
+			combined = &first
+			continue
+		}
+		combined.mergeOptions(q)
+	}
+	if combined == nil {
+		return nil
+	}
+
+	if op == BinaryOpOr && containsNil(filters) {
+		combined.Filter = nil
+		combined.unresolved = nil
+		return combined
+	}
+	combined.Filter = join(op, nil, filters)
+	combined.unresolved = nil
+	if unresolved {
+		combined.unresolved = join(op, nil, stable)
+	}
+	return combined
+}
+
+// mergeOptions reconciles the options of q with those of other
+func (q *Query) mergeOptions(other *Query) {
+	q.PageSize = smallestSet(q.PageSize, other.PageSize)
+	q.Limit = smallestSet(q.Limit, other.Limit)
+	if other.Page > 0 {
+		q.Page = other.Page
+	}
+	if other.SortBy != "" || other.SortOrder == SortOrderRandom || other.PreserveInOrder {
+		q.SortBy = other.SortBy
+		q.SortOrder = other.SortOrder
+		q.PreserveInOrder = other.PreserveInOrder
+		q.RandomSeed = other.RandomSeed
+	}
+	if other.DistinctOn != "" {
+		q.DistinctOn = other.DistinctOn
+	}
+	q.Distinct = q.Distinct || other.Distinct
+	q.ExplainRequested = q.ExplainRequested || other.ExplainRequested
+	q.HighlightRequested = q.HighlightRequested || other.HighlightRequested
+	q.IncludeDeleted = q.IncludeDeleted && other.IncludeDeleted
+	for k, v := range other.Metadata {
+		q.SetMetadata(k, v)
+	}
+}
+
+// smallestSet returns the smaller of a and b, ignoring values that are not set
+func smallestSet(a, b int) int {
+	if a <= 0 || b > 0 && b < a {
+		return b
+	}
+	return a
+}
+
+func containsNil(nodes []Node) bool {
+	for _, node := range nodes {
+		if node == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_Merge(t *testing.T) {
+	user := &Query{
+		Filter:   Or(Eq("brand", "Sony"), Eq("brand", "JBL")),
+		PageSize: 50,
+		Page:     3,
+		Metadata: map[string]interface{}{"trace": "a"},
+	}
+	route := &Query{
+		Filter:   Eq("published", true),
+		PageSize: 20,
+		SortBy:   "price",
+		Limit:    100,
+		Metadata: map[string]interface{}{"route": "/products"},
+	}
+
+	merged := user.Merge(route)
+	assert.Equal(t, `(brand = "Sony" OR brand = "JBL") AND published = true`, FormatFilter(merged.Filter))
+	assert.Equal(t, 20, merged.PageSize)
+	assert.Equal(t, 100, merged.Limit)
+	assert.Equal(t, 3, merged.Page)
+	assert.Equal(t, "price", merged.SortBy)
+	assert.Equal(t, map[string]interface{}{"trace": "a", "route": "/products"}, merged.Metadata)
+
+	// The inputs are not modified
+	assert.Equal(t, 50, user.PageSize)
+	assert.Equal(t, map[string]interface{}{"trace": "a"}, user.Metadata)
+
+	t.Run("explicit sort wins", func(t *testing.T) {
+		sorted := &Query{SortBy: "name", SortOrder: SortOrderDesc}
+		assert.Equal(t, "name", sorted.Merge(&Query{Filter: Eq("a", 1)}).SortBy)
+		assert.Equal(t, SortOrderDesc, sorted.Merge(&Query{Filter: Eq("a", 1)}).SortOrder)
+		assert.Equal(t, "price", sorted.Merge(route).SortBy)
+		assert.Equal(t, SortOrderAsc, sorted.Merge(route).SortOrder)
+		assert.Equal(t, SortOrderRandom, sorted.Merge(&Query{SortOrder: SortOrderRandom}).SortOrder)
+		assert.Empty(t, sorted.Merge(&Query{SortOrder: SortOrderRandom}).SortBy)
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		merged := AndQueries(&Query{IncludeDeleted: true, Distinct: true}, &Query{ExplainRequested: true})
+		assert.False(t, merged.IncludeDeleted)
+		assert.True(t, merged.Distinct)
+		assert.True(t, merged.ExplainRequested)
+		assert.True(t, AndQueries(&Query{IncludeDeleted: true}, &Query{IncludeDeleted: true}).IncludeDeleted)
+	})
+
+	t.Run("nil queries and filters", func(t *testing.T) {
+		assert.Nil(t, AndQueries(nil, nil))
+		assert.Equal(t, route.Filter, AndQueries(nil, &Query{}, route).Filter)
+		var none *Query
+		assert.Equal(t, route.Filter, none.Merge(route).Filter)
+	})
+}
+
+func TestOrQueries(t *testing.T) {
+	a := &Query{Filter: And(Eq("a", 1), Eq("b", 2)), PageSize: 10}
+	b := &Query{Filter: Eq("c", 3), PageSize: 5}
+
+	combined := OrQueries(a, b)
+	assert.Equal(t, `(a = 1 AND b = 2) OR c = 3`, FormatFilter(combined.Filter))
+	assert.Equal(t, 5, combined.PageSize)
+
+	// A query without a filter matches everything
+	assert.Nil(t, OrQueries(a, &Query{}).Filter)
+}
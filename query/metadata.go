@@ -0,0 +1,47 @@
+package query
+
+// SetMetadata stores a metadata value on the query, allocating the map on first use
+func (q *Query) SetMetadata(key string, value interface{}) {
+	if q.Metadata == nil {
+		q.Metadata = make(map[string]interface{})
+	}
+	q.Metadata[key] = value
+}
+
+// GetMetadata returns a metadata value of the query and whether it was set
+func (q *Query) GetMetadata(key string) (interface{}, bool) {
+	if q == nil {
+		return nil, false
+	}
+	value, ok := q.Metadata[key]
+	return value, ok
+}
+
+// SetMetadata stores a metadata value on the result, allocating the map on first use
+func (r *Result) SetMetadata(key string, value interface{}) {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]interface{})
+	}
+	r.Metadata[key] = value
+}
+
+// GetMetadata returns a metadata value of the result and whether it was set
+func (r *Result) GetMetadata(key string) (interface{}, bool) {
+	if r == nil {
+		return nil, false
+	}
+	value, ok := r.Metadata[key]
+	return value, ok
+}
+
+// copyMetadata returns a shallow copy of a metadata map, or nil for an empty one
+func copyMetadata(m map[string]interface{}) map[string]interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryMetadata(t *testing.T) {
+	q := &Query{}
+	_, ok := q.GetMetadata("trace_id")
+	assert.False(t, ok)
+
+	q.SetMetadata("trace_id", "abc")
+	value, ok := q.GetMetadata("trace_id")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", value)
+
+	var nilQuery *Query
+	_, ok = nilQuery.GetMetadata("trace_id")
+	assert.False(t, ok)
+}
+
+func TestBuilderMetadata(t *testing.T) {
+	b := Where(F("status").Eq("active")).Metadata("trace_id", "abc")
+	first := b.Build()
+	first.SetMetadata("trace_id", "changed")
+
+	// Each built query owns its metadata
+	value, _ := b.Build().GetMetadata("trace_id")
+	assert.Equal(t, "abc", value)
+}
+
+func TestResultMetadata_JSON(t *testing.T) {
+	r := &Result{}
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "metadata")
+
+	r.SetMetadata("degraded", "count skipped")
+	data, err = json.Marshal(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"metadata":{"degraded":"count skipped"}`)
+}
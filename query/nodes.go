@@ -0,0 +1,70 @@
+package query
+
+// SearchField is the field marker the parser puts on bare search terms.
+// Executors resolve it to DefaultSearchField or DefaultSearchFields.
+const SearchField = "__DEFAULT_SEARCH__"
+
+// IsSearch reports whether node is a bare search term
+func IsSearch(node Node) bool {
+	n, ok := node.(*ComparisonNode)
+	return ok && n.Field == SearchField
+}
+
+// Compare builds a comparison node. Plain Go values are converted like in F,
+// and the values of IN and NOT IN are always wrapped in an ArrayValue:
+// a slice is used as the list, any other value becomes a single-element list.
+//
+// Example:
+//
+//	filter := query.And(
+//		query.Eq("status", "active"),
+//		query.In("brand", "Sony", "JBL"),
+//		query.Search("headphones"),
+//	)
+func Compare(field string, op ComparisonOperator, value interface{}) Node {
+	if op == OpIn || op == OpNotIn {
+		switch values := value.(type) {
+		case ArrayValue:
+			return &ComparisonNode{Field: field, Operator: op, Value: toArrayValue(values)}
+		case []interface{}:
+			return &ComparisonNode{Field: field, Operator: op, Value: toArrayValue(values)}
+		default:
+			return &ComparisonNode{Field: field, Operator: op, Value: toArrayValue([]interface{}{value})}
+		}
+	}
+	return &ComparisonNode{Field: field, Operator: op, Value: toValue(value)}
+}
+
+// Eq builds field = value
+func Eq(field string, value interface{}) Node { return Compare(field, OpEqual, value) }
+
+// Ne builds field != value
+func Ne(field string, value interface{}) Node { return Compare(field, OpNotEqual, value) }
+
+// Gt builds field > value
+func Gt(field string, value interface{}) Node { return Compare(field, OpGreaterThan, value) }
+
+// Gte builds field >= value
+func Gte(field string, value interface{}) Node { return Compare(field, OpGreaterThanOrEqual, value) }
+
+// Lt builds field < value
+func Lt(field string, value interface{}) Node { return Compare(field, OpLessThan, value) }
+
+// Lte builds field <= value
+func Lte(field string, value interface{}) Node { return Compare(field, OpLessThanOrEqual, value) }
+
+// In builds field IN [values...]
+func In(field string, values ...interface{}) Node {
+	return &ComparisonNode{Field: field, Operator: OpIn, Value: toArrayValue(values)}
+}
+
+// NotIn builds field NOT IN [values...]
+func NotIn(field string, values ...interface{}) Node {
+	return &ComparisonNode{Field: field, Operator: OpNotIn, Value: toArrayValue(values)}
+}
+
+// Search builds a bare search term, matched against the default search fields
+// like an unqualified word in a query string
+func Search(term string) Node {
+	return &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue(term)}
+}
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeConstructors(t *testing.T) {
+	filter := And(Eq("status", "active"), In("brand", "Sony", "JBL"), Search("headphones"))
+
+	assert.Equal(t, &BinaryOpNode{
+		Operator: BinaryOpAnd,
+		Left: &BinaryOpNode{
+			Operator: BinaryOpAnd,
+			Left:     &ComparisonNode{Field: "status", Operator: OpEqual, Value: StringValue("active")},
+			Right:    &ComparisonNode{Field: "brand", Operator: OpIn, Value: ArrayValue{StringValue("Sony"), StringValue("JBL")}},
+		},
+		Right: &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue("headphones")},
+	}, filter)
+}
+
+func TestNodeConstructors_MatchBuilder(t *testing.T) {
+	assert.Equal(t, F("price").Gte(10).Node(), Gte("price", 10))
+	assert.Equal(t, F("price").Lt(2.5).Node(), Lt("price", 2.5))
+	assert.Equal(t, F("id").NotIn(1, 2).Node(), NotIn("id", 1, 2))
+	assert.Equal(t, F("name").Ne("x").Node(), Ne("name", "x"))
+}
+
+func TestCompare_InValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  ArrayValue
+	}{
+		{"slice", []interface{}{1, "a"}, ArrayValue{IntValue(1), StringValue("a")}},
+		{"array value", ArrayValue{IntValue(1)}, ArrayValue{IntValue(1)}},
+		{"single value", 7, ArrayValue{IntValue(7)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Compare("id", OpIn, tt.value).(*ComparisonNode)
+			assert.Equal(t, tt.want, n.Value)
+		})
+	}
+}
+
+func TestIsSearch(t *testing.T) {
+	assert.True(t, IsSearch(Search("phone")))
+	assert.False(t, IsSearch(Eq("name", "phone")))
+	assert.False(t, IsSearch(And(Search("a"), Search("b"))))
+	assert.False(t, IsSearch(nil))
+}
@@ -26,6 +26,9 @@ const (
 	// Array/Set operators
 	OpIn
 	OpNotIn
+
+	// Full-text search operator
+	OpMatch
 )
 
 // String returns the string representation of ComparisonOperator
@@ -61,6 +64,10 @@ func (co ComparisonOperator) String() string {
 		return "IN"
 	case OpNotIn:
 		return "NOT IN"
+	case OpMatch:
+		return "MATCH"
+	case SortOperator:
+		return "SORT"
 	default:
 		return "=" // Default to equal
 	}
@@ -100,6 +107,8 @@ func ParseComparisonOperator(s string) ComparisonOperator {
 		return OpIn
 	case "NOT IN":
 		return OpNotIn
+	case "MATCH":
+		return OpMatch
 	default:
 		return OpEqual // Default to equal
 	}
@@ -122,6 +131,7 @@ const (
 	OpRegexStr              = "REGEX"
 	OpInStr                 = "IN"
 	OpNotInStr              = "NOT IN"
+	OpMatchStr              = "MATCH"
 )
 
 // IsValidOperator checks if an operator string is valid
@@ -134,3 +144,49 @@ func IsValidOperator(op string) bool {
 
 // ArrayValue represents an array of values
 type ArrayValue []interface{}
+
+// ArrayModifier applies a comparison to an array field as a whole or to its
+// elements instead of to the field value
+type ArrayModifier int
+
+const (
+	// ArrayModifierNone compares the field value itself
+	ArrayModifierNone ArrayModifier = iota
+	// ArrayModifierLength compares the number of elements (tags LENGTH > 3)
+	ArrayModifierLength
+	// ArrayModifierAny matches when at least one element satisfies the
+	// comparison (tags ANY = "wireless")
+	ArrayModifierAny
+	// ArrayModifierAll matches when the array contains every value of an IN
+	// list (tags ALL IN [a, b])
+	ArrayModifierAll
+)
+
+// String returns the keyword of the ArrayModifier, or "" for ArrayModifierNone
+func (m ArrayModifier) String() string {
+	switch m {
+	case ArrayModifierLength:
+		return "LENGTH"
+	case ArrayModifierAny:
+		return "ANY"
+	case ArrayModifierAll:
+		return "ALL"
+	default:
+		return ""
+	}
+}
+
+// ParseArrayModifier parses an array modifier keyword, case-insensitively.
+// Returns false for anything else
+func ParseArrayModifier(s string) (ArrayModifier, bool) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "LENGTH":
+		return ArrayModifierLength, true
+	case "ANY":
+		return ArrayModifierAny, true
+	case "ALL":
+		return ArrayModifierAll, true
+	default:
+		return ArrayModifierNone, false
+	}
+}
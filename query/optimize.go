@@ -0,0 +1,246 @@
+package query
+
+import "math"
+
+// Optimize returns a simpler filter equivalent to node:
+//
+//   - nested AND and OR chains are flattened, keeping the operand order
+//   - repeated operands of a chain are dropped, e.g. a = 1 AND a = 1
+//   - constant conditions are folded: an empty NOT IN always holds and an
+//     empty IN never does, so a = 1 AND b NOT IN [] becomes a = 1
+//   - equalities and IN conditions on one field joined with OR become a
+//     single IN, e.g. a = 1 OR a = 2 OR a IN [3] becomes a IN [1, 2, 3]
+//
+// CONTAINS, ICONTAINS and MATCH conditions and bare search terms are kept as
+// written, since sort_by = _matches and _score count them. node is not modified.
+// Executors apply it before translation with ExecutorOptions.OptimizeFilter
+func Optimize(node Node) Node {
+	n, ok := node.(*BinaryOpNode)
+	if !ok {
+		return node
+	}
+	var operands []Node
+	for _, operand := range flatten(n, n.Operator, nil) {
+		operands = flatten(Optimize(operand), n.Operator, operands)
+	}
+
+	operands = foldConstants(n.Operator, operands)
+	if n.Operator == BinaryOpOr {
+		operands = mergeEqualities(operands)
+	}
+	operands = dropRepeated(operands)
+
+	optimized := operands[0]
+	for _, operand := range operands[1:] {
+		optimized = &BinaryOpNode{Operator: n.Operator, Left: optimized, Right: operand}
+	}
+	return optimized
+}
+
+// Operands returns the operands of the AND or OR chain rooted at n, left to
+// right, so translators can write a = 1 AND b = 2 AND c = 3 without nesting
+func Operands(n *BinaryOpNode) []Node {
+	return flatten(n, n.Operator, nil)
+}
+
+// foldConstants drops operands that cannot change the result of the op chain,
+// or reduces the chain to the operand that decides it
+func foldConstants(op BinaryOperator, operands []Node) []Node {
+	neutral, absorbing := isAlwaysTrue, isAlwaysFalse
+	if op == BinaryOpOr {
+		neutral, absorbing = isAlwaysFalse, isAlwaysTrue
+	}
+	var folded []Node
+	for _, operand := range operands {
+		if absorbing(operand) {
+			if op == BinaryOpAnd {
+				return []Node{operand}
+			}
+			// Relevance conditions still rank the items an OR lets through
+			return append(relevanceOperands(operands), operand)
+		}
+		if !neutral(operand) {
+			folded = append(folded, operand)
+		}
+	}
+	if len(folded) == 0 {
+		return operands[:1]
+	}
+	return folded
+}
+
+// isAlwaysTrue reports whether node is an empty NOT IN
+func isAlwaysTrue(node Node) bool {
+	return isEmptyList(node, OpNotIn)
+}
+
+// isAlwaysFalse reports whether node is an empty IN
+func isAlwaysFalse(node Node) bool {
+	return isEmptyList(node, OpIn)
+}
+
+func isEmptyList(node Node, op ComparisonOperator) bool {
+	n, ok := node.(*ComparisonNode)
+	if !ok || n.Operator != op || n.Modifier != ArrayModifierNone || n.Field == SearchField {
+		return false
+	}
+	values, ok := n.Value.(ArrayValue)
+	return ok && len(values) == 0
+}
+
+// relevanceOperands returns the operands that contain relevance conditions
+func relevanceOperands(operands []Node) []Node {
+	var relevant []Node
+	for _, operand := range operands {
+		if hasRelevance(operand) {
+			relevant = append(relevant, operand)
+		}
+	}
+	return relevant
+}
+
+// hasRelevance reports whether node contains a condition counted by
+// sort_by = _matches or _score
+func hasRelevance(node Node) bool {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		return hasRelevance(n.Left) || hasRelevance(n.Right)
+	case *ComparisonNode:
+		return n.Field == SearchField || n.Operator == OpContains || n.Operator == OpIContains || n.Operator == OpMatch
+	}
+	return false
+}
+
+// mergeEqualities joins the equalities and IN conditions of an OR chain on
+// each field into one IN condition in place of the first of them
+func mergeEqualities(operands []Node) []Node {
+	lists := make(map[string]*ComparisonNode)
+	counts := make(map[string]int)
+	for _, operand := range operands {
+		if field, ok := mergeableField(operand); ok {
+			counts[field]++
+		}
+	}
+
+	var merged []Node
+	for _, operand := range operands {
+		field, ok := mergeableField(operand)
+		if !ok || counts[field] < 2 {
+			merged = append(merged, operand)
+			continue
+		}
+		list, seen := lists[field]
+		if !seen {
+			list = &ComparisonNode{Field: field, Operator: OpIn, Value: ArrayValue{}}
+			lists[field] = list
+			merged = append(merged, list)
+		}
+		n := operand.(*ComparisonNode)
+		if values, ok := n.Value.(ArrayValue); ok {
+			list.Value = appendUnique(list.Value.(ArrayValue), values...)
+		} else {
+			list.Value = appendUnique(list.Value.(ArrayValue), n.Value)
+		}
+	}
+	return merged
+}
+
+// mergeableField returns the field of an equality or IN condition whose
+// values can be listed in an IN condition
+func mergeableField(node Node) (string, bool) {
+	n, ok := node.(*ComparisonNode)
+	if !ok || n.Modifier != ArrayModifierNone || n.Field == SearchField {
+		return "", false
+	}
+	switch n.Operator {
+	case OpEqual:
+		return n.Field, isListable(n.Value)
+	case OpIn:
+		values, ok := n.Value.(ArrayValue)
+		if !ok {
+			return "", false
+		}
+		for _, v := range values {
+			if !isListable(v) {
+				return "", false
+			}
+		}
+		return n.Field, true
+	}
+	return "", false
+}
+
+// isListable reports whether v is a literal that compares the same with =
+// and in an IN list
+func isListable(v interface{}) bool {
+	switch v.(type) {
+	case StringValue, IntValue, FloatValue, BoolValue, DateTimeValue:
+		return true
+	}
+	return false
+}
+
+func appendUnique(values ArrayValue, add ...interface{}) ArrayValue {
+	for _, v := range add {
+		duplicate := false
+		for _, existing := range values {
+			if existing == v {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// repeatKey renders a condition for dropRepeated. Whole floats render like
+// ints, so price > 5 and price > 5.0 are the same condition
+func repeatKey(node Node) string {
+	return FormatFilter(Rewrite(node, func(node Node) Node {
+		n, ok := node.(*ComparisonNode)
+		if !ok {
+			return node
+		}
+		keyed := *n
+		keyed.Value = wholeFloatsAsInts(n.Value)
+		return &keyed
+	}))
+}
+
+// wholeFloatsAsInts converts whole FloatValues to IntValues, including in lists
+func wholeFloatsAsInts(v interface{}) interface{} {
+	switch val := v.(type) {
+	case FloatValue:
+		if f := float64(val); f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return IntValue(int64(f))
+		}
+	case ArrayValue:
+		converted := make(ArrayValue, len(val))
+		for i, elem := range val {
+			converted[i] = wholeFloatsAsInts(elem)
+		}
+		return converted
+	}
+	return v
+}
+
+// dropRepeated drops operands written earlier in the chain. Relevance
+// conditions are kept, since each of them counts
+func dropRepeated(operands []Node) []Node {
+	seen := make(map[string]bool, len(operands))
+	var kept []Node
+	for _, operand := range operands {
+		if !hasRelevance(operand) {
+			key := repeatKey(operand)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept = append(kept, operand)
+	}
+	return kept
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Node
+		expected string
+	}{
+		{
+			name:     "nested chains are flattened in order",
+			filter:   And(Eq("c", 3), And(Eq("a", 1), And(Eq("b", 2), Eq("d", 4)))),
+			expected: `((c = 3 AND a = 1) AND b = 2) AND d = 4`,
+		},
+		{
+			name:     "repeated predicates are dropped",
+			filter:   And(Eq("a", 1), And(Gt("b", 2), Eq("a", 1))),
+			expected: `a = 1 AND b > 2`,
+		},
+		{
+			name:     "empty NOT IN always holds",
+			filter:   And(Eq("a", 1), NotIn("b")),
+			expected: `a = 1`,
+		},
+		{
+			name:     "empty IN never holds",
+			filter:   And(Eq("a", 1), In("b")),
+			expected: `b IN []`,
+		},
+		{
+			name:     "OR drops conditions that never hold",
+			filter:   Or(In("b"), Eq("a", 1)),
+			expected: `a = 1`,
+		},
+		{
+			name:     "OR with a condition that always holds",
+			filter:   Or(Eq("a", 1), NotIn("b")),
+			expected: `b NOT IN []`,
+		},
+		{
+			name:     "OR of equalities becomes IN",
+			filter:   Or(Eq("brand", "Sony"), Or(Eq("price", 5), Or(Eq("brand", "JBL"), In("brand", "Bose", "Sony")))),
+			expected: `brand IN ["Sony", "JBL", "Bose"] OR price = 5`,
+		},
+		{
+			name:     "equalities inside AND are left alone",
+			filter:   And(Eq("a", 1), Eq("a", 2)),
+			expected: `a = 1 AND a = 2`,
+		},
+		{
+			name:     "placeholders are not merged",
+			filter:   Or(Eq("a", PlaceholderValue("user")), Eq("a", 2)),
+			expected: `a = @user OR a = 2`,
+		},
+		{
+			name:     "collapsed operands are flattened again",
+			filter:   And(Eq("c", 3), Or(And(Eq("a", 1), Eq("b", 2)), And(Eq("a", 1), Eq("b", 2)))),
+			expected: `(c = 3 AND a = 1) AND b = 2`,
+		},
+		{
+			name:     "relevance conditions are kept",
+			filter:   Or(And(Search("usb"), Search("usb")), Or(F("name").Contains("hub").Node(), NotIn("b"))),
+			expected: `((usb AND usb) OR name CONTAINS "hub") OR b NOT IN []`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatFilter(Optimize(tt.filter)))
+		})
+	}
+
+	t.Run("input is not modified", func(t *testing.T) {
+		filter := Or(Eq("a", 1), Eq("a", 2))
+		Optimize(filter)
+		assert.Equal(t, `a = 1 OR a = 2`, FormatFilter(filter))
+	})
+
+	assert.Nil(t, Optimize(nil))
+	a := Eq("a", 1)
+	assert.Same(t, a, Optimize(a))
+}
+
+func TestOperands(t *testing.T) {
+	a, b, c := Eq("a", 1), Eq("b", 2), Eq("c", 3)
+	assert.Equal(t, []Node{a, b, c}, Operands(And(a, And(b, c)).(*BinaryOpNode)))
+	assert.Equal(t, []Node{a, Or(b, c)}, Operands(And(a, Or(b, c)).(*BinaryOpNode)))
+}
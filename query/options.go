@@ -1,5 +1,13 @@
 package query
 
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
 // ValueConverter is a function that converts query values to their underlying representation.
 // This is useful for converting enum strings (e.g., "usbc", "bluetooth") to their
 // numeric representations (e.g., 2, 3) that are stored in the database.
@@ -27,6 +35,16 @@ package query
 //	}
 type ValueConverter func(field string, value interface{}) (interface{}, error)
 
+// Collation selects the locale rules strings are compared and sorted by
+type Collation struct {
+	// Locale is an ICU locale such as "en", "de" or "fr_CA"
+	Locale string
+
+	// Strength is the level of comparison: 1 compares base letters only,
+	// 2 also accents, 3 (the default when 0) also case
+	Strength int
+}
+
 // ExecutorOptions contains configuration options for query executors
 type ExecutorOptions struct {
 	// MaxPageSize is the maximum allowed page size
@@ -35,6 +53,12 @@ type ExecutorOptions struct {
 	// DefaultPageSize is the default page size when not specified
 	DefaultPageSize int
 
+	// MaxPageOffset is the largest number of items a page jump (page = 7) may
+	// skip. Jumps run as offsets, whose cost grows with depth, so UIs can offer
+	// numbered pages near the start while deeper pages are reached with
+	// cursors. Deeper jumps fail with ErrPageTooDeep. 0 means no limit
+	MaxPageOffset int
+
 	// DefaultSortField is the default field to sort by
 	DefaultSortField string
 
@@ -44,11 +68,94 @@ type ExecutorOptions struct {
 	// AllowRandomOrder determines if random ordering is allowed
 	AllowRandomOrder bool
 
+	// AllowEmptyResults returns queries that match nothing with a nil error
+	// instead of ErrNoRecordsFound. Pages past the last match are never an error
+	AllowEmptyResults bool
+
+	// AllowWrites enables DeleteWhere and UpdateWhere, which change every item
+	// matching a query's filter. Without it they fail with ErrWritesNotAllowed
+	AllowWrites bool
+
 	// RandomFunctionName is the SQL function name to use for random ordering
-	// Defaults to "RANDOM()" (PostgreSQL, SQLite). Use "RAND()" for MySQL
-	// This only applies to SQL-based executors (GORM)
+	// Defaults to "RANDOM()", for which GORM orders by a hash of the ID and the
+	// query's seed instead, so pages of one order do not overlap. Another name
+	// is used as is, without a seed. This only applies to SQL-based executors (GORM)
 	RandomFunctionName string
 
+	// FullTextTemplate overrides the SQL used for the MATCH operator.
+	// The field name replaces %s and the search text is bound to ?.
+	// Example: "%s MATCH ?" for SQLite FTS5 tables.
+	// When empty, GORM picks a dialect-specific implementation (PostgreSQL tsvector,
+	// MySQL MATCH ... AGAINST, otherwise tokenized LIKE matching).
+	// This only applies to SQL-based executors (GORM)
+	FullTextTemplate string
+
+	// LargeInThreshold is the number of values above which IN/NOT IN lists are not
+	// sent as individual placeholders. SQL executors switch to a temporary table
+	// (or unnest on PostgreSQL) to avoid parameter limits and planner blowups.
+	// 0 disables the optimization. This only applies to SQL-based executors (GORM)
+	LargeInThreshold int
+
+	// RelationMap maps query field prefixes to model associations, so
+	// author.name = "Alice" filters on the name column of the Author association
+	// with RelationMap{"author": "Author"}. AllowedFields lists relation fields
+	// with their prefix. This only applies to SQL-based executors (GORM)
+	RelationMap map[string]string
+
+	// IncludeDeleted includes soft-deleted rows (models with a gorm.DeletedAt field)
+	// in every query. When false, Execute and Count both exclude them.
+	// This only applies to SQL-based executors (GORM)
+	IncludeDeleted bool
+
+	// AllowIncludeDeleted lets queries include soft-deleted rows with
+	// include_deleted = true. This only applies to SQL-based executors (GORM)
+	AllowIncludeDeleted bool
+
+	// RangeStrategy selects how OR-ed ranges over one field, such as
+	// (price >= 10 and price <= 20) or (price >= 50 and price <= 60), are compiled.
+	// The zero value lets PlanRanges choose. GORM and MongoDB honor it
+	RangeStrategy RangeStrategy
+
+	// RangeIndexHints maps fields to the index used when a filter is planned as a
+	// range list on that field (see PlanRanges), e.g. {"price": "price_1"}.
+	// This only applies to MongoDB
+	RangeIndexHints map[string]string
+
+	// FloatTolerance makes = and != comparisons against float literals approximate.
+	// A stored value matches when it lies within ±FloatTolerance of the literal,
+	// so price = 29.99 also matches a stored 29.990000000000002.
+	// Memory compares the difference directly, GORM uses BETWEEN and MongoDB uses
+	// a $gte/$lte range. Integer literals and other operators are unaffected.
+	// 0 keeps exact equality
+	FloatTolerance float64
+
+	// CountMode selects how Execute computes Result.TotalItems: exactly (the
+	// default), from database statistics, not at all, or concurrently with the
+	// page fetch. See CountMode
+	CountMode CountMode
+
+	// CountEstimateThreshold makes Execute estimate TotalItems on collections with
+	// more documents than this, since an exact count with the user filter can dominate
+	// latency on very large collections. Unfiltered queries use the collection metadata
+	// count; filtered queries extrapolate the match ratio of a random sample of
+	// CountSampleSize documents. Result.TotalItemsEstimated marks estimated totals.
+	// Count always counts exactly. 0 disables estimates, unless CountMode is
+	// CountEstimated, which estimates on collections of any size. This only
+	// applies to MongoDB
+	CountEstimateThreshold int64
+
+	// CountSampleSize is the number of documents sampled for estimated totals.
+	// Defaults to 10000 when 0. This only applies to MongoDB
+	CountSampleSize int
+
+	// Collation makes string comparisons and sorting follow the rules of a
+	// locale instead of comparing code points, e.g. &Collation{Locale: "de",
+	// Strength: 2} sorts umlauts with their base letters and matches
+	// name = "müller" against "Müller". Indexes are only used for collated
+	// queries when they were built with the same collation. Nil compares code
+	// points. This only applies to MongoDB
+	Collation *Collation
+
 	// IDFieldName is the name of the ID field used for cursor-based pagination
 	// Defaults to "_id" for MongoDB, "id" for GORM, empty for Memory executor
 	// This field is used when sorting by a different field to handle ties
@@ -59,11 +166,47 @@ type ExecutorOptions struct {
 	// it will search this field using CONTAINS
 	DefaultSearchField string
 
+	// DefaultSearchFields lists several fields searched by bare strings.
+	// When set, it takes precedence over DefaultSearchField and a bare term
+	// expands to an OR across all listed fields (e.g., name, description, tags)
+	DefaultSearchFields []string
+
+	// SearchFieldWeights assigns relevance weights to default search fields.
+	// Fields not listed weigh 1. Weights affect relevance scoring only
+	// (sort_by = _score), never which items match
+	SearchFieldWeights map[string]float64
+
+	// PhraseMatch decides how quoted bare search terms match: as substrings
+	// (the default), as whole words, or word by word; see ExpandPhrase
+	PhraseMatch PhraseMatch
+
+	// SearchTermExpander, if set, expands the terms of bare searches and of
+	// CONTAINS and ICONTAINS conditions before translation, e.g. into synonyms
+	// or stems, in every executor. See ExpandSearchTerms
+	SearchTermExpander SearchTermExpander
+
+	// StopWords are bare search terms dropped from filters, ignoring case,
+	// e.g. "a", "the" and "of", which match nearly every item. Quoted phrases
+	// are kept. Results list the dropped terms in a WarningIgnoredTerms
+	// warning; a filter of nothing but ignored terms is rejected with
+	// ErrSearchTermsIgnored
+	StopWords []string
+
+	// MinSearchTermLength drops bare search terms shorter than this many
+	// characters, like StopWords. 0 keeps every term
+	MinSearchTermLength int
+
 	// AllowedFields is a whitelist of fields that can be queried
 	// Empty list means all fields are allowed (no restriction)
 	// This is a security feature to prevent querying sensitive fields
 	AllowedFields []string
 
+	// SortableFields lists the fields that sort_by may name.
+	// Empty list falls back to AllowedFields; if both are empty any field is accepted.
+	// DefaultSortField, IDFieldName and "_score" are always accepted.
+	// Unknown fields are rejected with a *SortFieldError that suggests close matches
+	SortableFields []string
+
 	// DisableRegex disables REGEX operator support
 	// Set to true for databases that don't support regex (e.g., SQLite without extension)
 	// When disabled, queries with REGEX will return a clear error
@@ -73,8 +216,83 @@ type ExecutorOptions struct {
 	// Useful for converting enum strings to integers, or any other value transformation.
 	// If nil, no conversion is performed.
 	ValueConverter ValueConverter
+
+	// BaseFilter is ANDed into every executed query before translation, so
+	// mandatory predicates such as tenant_id = 5 apply regardless of user input.
+	// Fields referenced by BaseFilter are accepted even when they are missing
+	// from AllowedFields; user conditions on them can only narrow the scope.
+	// Use parser.ParseFilter to build it from a query string.
+	BaseFilter Node
+
+	// OptimizeFilter simplifies filters with Optimize before translation:
+	// repeated and constant conditions are dropped and equalities on one field
+	// joined with OR become IN. Limits and policies see the filter as written
+	OptimizeFilter bool
+
+	// Placeholders resolve @name values from the request context when a query
+	// executes, e.g. owner_id = @current_user, so saved queries adapt to the
+	// requesting user without string substitution. See ResolvePlaceholders
+	Placeholders map[string]PlaceholderResolver
+
+	// Clock returns the instant relative times such as now-7d resolve against.
+	// It is read once per execution. Defaults to time.Now; set it in tests
+	Clock func() time.Time
+
+	// FieldPolicy restricts the operators allowed on each field, e.g. only = and IN
+	// on email or only range operators on created_at. The AnyField key applies to
+	// fields that are not listed; fields not covered by either are unrestricted.
+	// Disallowed operators fail with an *OperatorError wrapping ErrOperatorNotAllowed
+	FieldPolicy map[string][]ComparisonOperator
+
+	// FieldAuthorizer decides per request which fields a query may use, e.g. so
+	// admins can filter on internal fields that other users must not see. It is
+	// called with the execution context for the field and operator of every
+	// condition, and with SortOperator for sort_by and distinct_on; an error
+	// rejects the query and is returned as is. BaseFilter is not checked.
+	// See AuthorizeFields
+	FieldAuthorizer func(ctx context.Context, field string, op ComparisonOperator) error
+
+	// MaxFilterDepth limits the nesting depth of the filter; a single condition has depth 1.
+	// Violations fail with ErrQueryTooComplex. 0 means no limit
+	MaxFilterDepth int
+
+	// MaxConditions limits the number of comparisons in the filter. 0 means no limit
+	MaxConditions int
+
+	// MaxInArraySize limits the number of values in a single IN or NOT IN list. 0 means no limit
+	MaxInArraySize int
+
+	// MaxRegexLength limits the length of REGEX patterns. 0 means no limit
+	MaxRegexLength int
+
+	// SafeRegex rejects REGEX patterns that could backtrack catastrophically:
+	// patterns must be valid RE2 and must not nest unbounded quantifiers.
+	// Violations fail with ErrUnsafeRegex before the query reaches the database.
+	// See ValidateRegex
+	SafeRegex bool
+
+	// AnchorRegex makes REGEX patterns match the whole value, as if wrapped in
+	// ^(...)$. Anchored patterns also let MongoDB use an index on the field
+	AnchorRegex bool
+
+	// RegexTimeout bounds the time the memory executor spends evaluating REGEX
+	// conditions for one Execute or Count. Exceeding it fails with ErrRegexTimeout.
+	// Go's regexp runs in linear time, so the budget is checked between matches.
+	// 0 means no limit. This only applies to the memory executor
+	RegexTimeout time.Duration
+
+	// QueryLogger receives every Execute and Count call with the filter that
+	// ran, its duration and result counts. See QueryLog
+	QueryLogger QueryLogger
+
+	// RedactQueryLog replaces the values of logged filters with ?, so audit logs
+	// record which fields and operators were used without the searched values
+	RedactQueryLog bool
 }
 
+// AnyField is the FieldPolicy key for fields without their own entry
+const AnyField = "*"
+
 // DefaultExecutorOptions returns default executor options
 func DefaultExecutorOptions() *ExecutorOptions {
 	return &ExecutorOptions{
@@ -83,7 +301,7 @@ func DefaultExecutorOptions() *ExecutorOptions {
 		DefaultSortField:   "_id",
 		DefaultSortOrder:   SortOrderAsc,
 		AllowRandomOrder:   true,
-		RandomFunctionName: "RANDOM()", // PostgreSQL, SQLite default. Use "RAND()" for MySQL
+		RandomFunctionName: "RANDOM()", // GORM uses the dialect's function for the default
 		DefaultSearchField: "name",     // Default to searching "name" field
 	}
 }
@@ -100,6 +318,23 @@ func (o *ExecutorOptions) ValidatePageSize(size int) int {
 	return size
 }
 
+// PageOffset returns the number of items before the page q.Page jumps to,
+// with the page size from ValidatePageSize. Jumps past MaxPageOffset fail with
+// ErrPageTooDeep. Executors apply it only when no cursor is given
+func (o *ExecutorOptions) PageOffset(q *Query, pageSize int) (int, error) {
+	if q == nil || q.Page <= 1 || pageSize <= 0 {
+		return 0, nil
+	}
+	limit := math.MaxInt
+	if o.MaxPageOffset > 0 {
+		limit = o.MaxPageOffset
+	}
+	if q.Page-1 > limit/pageSize {
+		return 0, fmt.Errorf("%w: page %d starts after more than %d items; continue from a cursor instead", ErrPageTooDeep, q.Page, limit)
+	}
+	return (q.Page - 1) * pageSize, nil
+}
+
 // IsFieldAllowed checks if a field is in the allowed fields list
 // Returns true if AllowedFields is empty (no restriction) or field is in the list
 func (o *ExecutorOptions) IsFieldAllowed(field string) bool {
@@ -108,6 +343,11 @@ func (o *ExecutorOptions) IsFieldAllowed(field string) bool {
 		return true
 	}
 
+	// Fields scoped by the base filter are trusted
+	if referencesField(o.BaseFilter, field) {
+		return true
+	}
+
 	// Check if field is in allowed list
 	for _, allowed := range o.AllowedFields {
 		if allowed == field {
@@ -117,6 +357,86 @@ func (o *ExecutorOptions) IsFieldAllowed(field string) bool {
 	return false
 }
 
+// IsFieldListed reports whether field is listed in AllowedFields. SQL
+// executors accept field names that are not plain identifiers, such as
+// "order date", only when they are listed
+func (o *ExecutorOptions) IsFieldListed(field string) bool {
+	for _, allowed := range o.AllowedFields {
+		if allowed == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopedQuery returns a copy of q without the search terms StopWords and
+// MinSearchTermLength ignore, with its search terms expanded by
+// SearchTermExpander, BaseFilter ANDed into its filter and, with
+// OptimizeFilter, the filter simplified by Optimize. q is returned unchanged
+// when none is configured. BaseFilter is not expanded.
+// The user's filter stays on the left so PreserveInOrder picks its IN condition first.
+func (o *ExecutorOptions) ScopedQuery(q *Query) *Query {
+	if o.BaseFilter == nil && !o.OptimizeFilter && o.SearchTermExpander == nil && len(o.StopWords) == 0 && o.MinSearchTermLength <= 0 || q == nil {
+		return q
+	}
+	scoped := *q
+	scoped.Filter, _ = o.DropIgnoredTerms(q.Filter)
+	scoped.Filter = ExpandSearchTerms(scoped.Filter, o.SearchTermExpander)
+	if o.BaseFilter != nil {
+		scoped.Filter = And(scoped.Filter, o.BaseFilter)
+		if q.unresolved != nil {
+			scoped.unresolved = And(q.unresolved, o.BaseFilter)
+		}
+	}
+	if o.OptimizeFilter {
+		scoped.Filter = Optimize(scoped.Filter)
+	}
+	return &scoped
+}
+
+// IncludesDeleted reports whether soft-deleted rows are included for q: always
+// with IncludeDeleted, otherwise when q sets IncludeDeleted and AllowIncludeDeleted is on.
+// Returns ErrIncludeDeletedNotAllowed when q asks for them without permission
+func (o *ExecutorOptions) IncludesDeleted(q *Query) (bool, error) {
+	if o.IncludeDeleted {
+		return true, nil
+	}
+	if q == nil || !q.IncludeDeleted {
+		return false, nil
+	}
+	if !o.AllowIncludeDeleted {
+		return false, ErrIncludeDeletedNotAllowed
+	}
+	return true, nil
+}
+
+// ValidateDistinct checks the distinct_on field of q against AllowedFields.
+// The _score and _matches pseudo-fields cannot be deduplicated on
+func (o *ExecutorOptions) ValidateDistinct(q *Query) error {
+	if q == nil || q.DistinctOn == "" {
+		return nil
+	}
+	if q.DistinctOn == ScoreField || q.DistinctOn == MatchCountField {
+		return fmt.Errorf("%w: distinct_on cannot use %s", ErrInvalidQuery, q.DistinctOn)
+	}
+	if !o.IsFieldAllowed(q.DistinctOn) {
+		return FieldNotAllowedError(q.DistinctOn, o.AllowedFields...)
+	}
+	return nil
+}
+
+// referencesField reports whether node compares field anywhere
+func referencesField(node Node, field string) bool {
+	found := false
+	Walk(node, func(node Node) bool {
+		if n, ok := node.(*ComparisonNode); ok && n.Field == field {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
 // ConvertValue applies the ValueConverter if configured, otherwise returns the original value
 func (o *ExecutorOptions) ConvertValue(field string, value interface{}) (interface{}, error) {
 	if o.ValueConverter == nil {
@@ -124,3 +444,129 @@ func (o *ExecutorOptions) ConvertValue(field string, value interface{}) (interfa
 	}
 	return o.ValueConverter(field, value)
 }
+
+// OptionsProvider supplies executor options at execution time.
+// Executors constructed with a provider call Get at the start of every Execute/Count,
+// so allowlists, page caps and other policies can be changed at runtime
+// (e.g. from a config service) without recreating the executor.
+// Get must be safe for concurrent use and should return a snapshot that is not mutated afterwards.
+type OptionsProvider interface {
+	Get() *ExecutorOptions
+}
+
+// OptionsProviderFunc adapts a function to the OptionsProvider interface
+type OptionsProviderFunc func() *ExecutorOptions
+
+// Get calls f()
+func (f OptionsProviderFunc) Get() *ExecutorOptions {
+	return f()
+}
+
+// StaticOptions returns a provider that always returns the same options
+func StaticOptions(opts *ExecutorOptions) OptionsProvider {
+	return OptionsProviderFunc(func() *ExecutorOptions { return opts })
+}
+
+// ReloadableOptions is a concurrency-safe OptionsProvider whose options can be swapped at runtime
+//
+// Example:
+//
+//	provider := query.NewReloadableOptions(query.DefaultExecutorOptions())
+//	executor := gorm.NewExecutorWithOptionsProvider(db, provider)
+//
+//	// Later, e.g. when the config service pushes an update:
+//	updated := query.DefaultExecutorOptions()
+//	updated.AllowedFields = []string{"name", "price"}
+//	provider.Set(updated)
+type ReloadableOptions struct {
+	current atomic.Pointer[ExecutorOptions]
+}
+
+// NewReloadableOptions creates a reloadable provider with the given initial options
+// If opts is nil, default options are used
+func NewReloadableOptions(opts *ExecutorOptions) *ReloadableOptions {
+	r := &ReloadableOptions{}
+	r.Set(opts)
+	return r
+}
+
+// Get returns the current options snapshot
+func (r *ReloadableOptions) Get() *ExecutorOptions {
+	return r.current.Load()
+}
+
+// Set replaces the current options. Executions already in progress keep using the previous snapshot.
+// If opts is nil, default options are used
+func (r *ReloadableOptions) Set(opts *ExecutorOptions) {
+	if opts == nil {
+		opts = DefaultExecutorOptions()
+	}
+	r.current.Store(opts)
+}
+
+// ResolveOptions returns the provider's current options, falling back to defaults
+// when the provider is nil or returns nil
+func ResolveOptions(provider OptionsProvider) *ExecutorOptions {
+	if provider == nil {
+		return DefaultExecutorOptions()
+	}
+	if opts := provider.Get(); opts != nil {
+		return opts
+	}
+	return DefaultExecutorOptions()
+}
+
+// SearchFields returns the fields searched by bare terms.
+// DefaultSearchFields takes precedence over DefaultSearchField when set.
+func (o *ExecutorOptions) SearchFields() []string {
+	if len(o.DefaultSearchFields) > 0 {
+		return o.DefaultSearchFields
+	}
+	if o.DefaultSearchField == "" {
+		return nil
+	}
+	return []string{o.DefaultSearchField}
+}
+
+// SearchFieldWeight returns the relevance weight of a default search field.
+// Fields without an explicit weight in SearchFieldWeights weigh 1.
+func (o *ExecutorOptions) SearchFieldWeight(field string) float64 {
+	if weight, ok := o.SearchFieldWeights[field]; ok {
+		return weight
+	}
+	return 1
+}
+
+// FloatRange returns the inclusive range matched by an approximate equality
+// comparison against value. ok is false when FloatTolerance is disabled or the
+// value is not a float, in which case exact equality applies.
+func (o *ExecutorOptions) FloatRange(value interface{}) (lo, hi float64, ok bool) {
+	if o.FloatTolerance <= 0 {
+		return 0, 0, false
+	}
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case FloatValue:
+		f = float64(v)
+	default:
+		return 0, 0, false
+	}
+	return f - o.FloatTolerance, f + o.FloatTolerance, true
+}
+
+// ValidateSortField checks a requested sort field against SortableFields
+// (or AllowedFields when SortableFields is empty)
+func (o *ExecutorOptions) ValidateSortField(field string) error {
+	known := o.SortableFields
+	if len(known) == 0 {
+		known = o.AllowedFields
+	}
+	if len(known) == 0 || field == o.DefaultSortField || (o.IDFieldName != "" && field == o.IDFieldName) {
+		return nil
+	}
+	return ValidateSortField(field, known)
+}
@@ -1,9 +1,11 @@
 package query
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExecutorOptions_ValidatePageSize(t *testing.T) {
@@ -32,6 +34,38 @@ func TestExecutorOptions_ValidatePageSize(t *testing.T) {
 	}
 }
 
+func TestExecutorOptions_PageOffset(t *testing.T) {
+	opts := &ExecutorOptions{MaxPageOffset: 50}
+
+	tests := []struct {
+		name     string
+		page     int
+		expected int
+	}{
+		{"unset starts at first page", 0, 0},
+		{"first page", 1, 0},
+		{"within limit", 3, 20},
+		{"at limit", 6, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, err := opts.PageOffset(&Query{Page: tt.page}, 10)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, offset)
+		})
+	}
+
+	_, err := opts.PageOffset(&Query{Page: 7}, 10)
+	assert.ErrorIs(t, err, ErrPageTooDeep)
+
+	unlimited := &ExecutorOptions{}
+	offset, err := unlimited.PageOffset(&Query{Page: 1001}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 10000, offset)
+	_, err = unlimited.PageOffset(&Query{Page: math.MaxInt}, 10)
+	assert.ErrorIs(t, err, ErrPageTooDeep, "offsets that overflow are too deep")
+}
+
 func TestDefaultExecutorOptions(t *testing.T) {
 	opts := DefaultExecutorOptions()
 
@@ -121,3 +155,127 @@ func TestExecutorOptions_IsFieldAllowed(t *testing.T) {
 		assert.False(t, opts.IsFieldAllowed("user.password"))
 	})
 }
+
+func TestExecutorOptions_IsFieldListed(t *testing.T) {
+	assert.False(t, (&ExecutorOptions{}).IsFieldListed("order date"))
+
+	opts := &ExecutorOptions{
+		AllowedFields: []string{"name", "order date"},
+		BaseFilter:    Eq("tenant id", 1),
+	}
+	assert.True(t, opts.IsFieldListed("order date"))
+	assert.False(t, opts.IsFieldListed("order_date"))
+	assert.False(t, opts.IsFieldListed("tenant id")) // allowed through the base filter, not listed
+	assert.True(t, opts.IsFieldAllowed("tenant id"))
+}
+
+func TestOptionsProviders(t *testing.T) {
+	t.Run("static options", func(t *testing.T) {
+		opts := &ExecutorOptions{MaxPageSize: 5}
+		provider := StaticOptions(opts)
+		assert.Same(t, opts, provider.Get())
+	})
+
+	t.Run("reloadable options swap", func(t *testing.T) {
+		provider := NewReloadableOptions(&ExecutorOptions{MaxPageSize: 5})
+		assert.Equal(t, 5, provider.Get().MaxPageSize)
+
+		provider.Set(&ExecutorOptions{MaxPageSize: 50})
+		assert.Equal(t, 50, provider.Get().MaxPageSize)
+	})
+
+	t.Run("reloadable nil uses defaults", func(t *testing.T) {
+		provider := NewReloadableOptions(nil)
+		assert.Equal(t, DefaultExecutorOptions(), provider.Get())
+	})
+
+	t.Run("resolve falls back to defaults", func(t *testing.T) {
+		assert.Equal(t, DefaultExecutorOptions(), ResolveOptions(nil))
+		assert.Equal(t, DefaultExecutorOptions(), ResolveOptions(OptionsProviderFunc(func() *ExecutorOptions { return nil })))
+
+		opts := &ExecutorOptions{MaxPageSize: 7}
+		assert.Same(t, opts, ResolveOptions(StaticOptions(opts)))
+	})
+}
+
+func TestExecutorOptions_SearchFields(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	assert.Equal(t, []string{"name"}, opts.SearchFields())
+
+	opts.DefaultSearchFields = []string{"name", "description"}
+	assert.Equal(t, []string{"name", "description"}, opts.SearchFields())
+
+	assert.Nil(t, (&ExecutorOptions{}).SearchFields())
+
+	opts.SearchFieldWeights = map[string]float64{"name": 3}
+	assert.Equal(t, 3.0, opts.SearchFieldWeight("name"))
+	assert.Equal(t, 1.0, opts.SearchFieldWeight("description"))
+}
+
+func TestExecutorOptions_FloatRange(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	_, _, ok := opts.FloatRange(29.99)
+	assert.False(t, ok, "tolerance disabled by default")
+
+	opts.FloatTolerance = 0.001
+	lo, hi, ok := opts.FloatRange(FloatValue(29.99))
+	assert.True(t, ok)
+	assert.InDelta(t, 29.989, lo, 1e-12)
+	assert.InDelta(t, 29.991, hi, 1e-12)
+
+	_, _, ok = opts.FloatRange(int64(30))
+	assert.False(t, ok, "integers compare exactly")
+	_, _, ok = opts.FloatRange("29.99")
+	assert.False(t, ok)
+}
+
+func TestExecutorOptions_BaseFilter(t *testing.T) {
+	tenant := &ComparisonNode{Field: "tenant_id", Operator: OpEqual, Value: IntValue(5)}
+	user := &ComparisonNode{Field: "name", Operator: OpContains, Value: StringValue("phone")}
+
+	opts := &ExecutorOptions{}
+	q := &Query{Filter: user, PageSize: 10}
+	assert.Same(t, q, opts.ScopedQuery(q), "no base filter leaves the query untouched")
+
+	opts.BaseFilter = tenant
+	scoped := opts.ScopedQuery(q)
+	assert.Equal(t, &BinaryOpNode{Operator: BinaryOpAnd, Left: user, Right: tenant}, scoped.Filter)
+	assert.Equal(t, 10, scoped.PageSize)
+	assert.Same(t, user, q.Filter, "original query must not be modified")
+
+	assert.Same(t, tenant, opts.ScopedQuery(&Query{}).Filter)
+
+	t.Run("optimized", func(t *testing.T) {
+		opts := &ExecutorOptions{BaseFilter: tenant, OptimizeFilter: true}
+		q := &Query{Filter: Or(Eq("brand", "Sony"), Eq("brand", "JBL"))}
+		assert.Equal(t, `brand IN ["Sony", "JBL"] AND tenant_id = 5`, FormatFilter(opts.ScopedQuery(q).Filter))
+		assert.Equal(t, `brand = "Sony" OR brand = "JBL"`, FormatFilter(q.Filter))
+	})
+
+	t.Run("base filter fields are allowed", func(t *testing.T) {
+		opts := &ExecutorOptions{AllowedFields: []string{"name"}, BaseFilter: And(tenant, user)}
+		assert.True(t, opts.IsFieldAllowed("tenant_id"))
+		assert.True(t, opts.IsFieldAllowed("name"))
+		assert.False(t, opts.IsFieldAllowed("password"))
+	})
+}
+
+func TestExecutorOptions_IncludesDeleted(t *testing.T) {
+	opts := &ExecutorOptions{}
+	include, err := opts.IncludesDeleted(&Query{})
+	assert.NoError(t, err)
+	assert.False(t, include)
+
+	_, err = opts.IncludesDeleted(&Query{IncludeDeleted: true})
+	assert.ErrorIs(t, err, ErrIncludeDeletedNotAllowed)
+
+	opts.AllowIncludeDeleted = true
+	include, err = opts.IncludesDeleted(&Query{IncludeDeleted: true})
+	assert.NoError(t, err)
+	assert.True(t, include)
+
+	opts = &ExecutorOptions{IncludeDeleted: true}
+	include, err = opts.IncludesDeleted(&Query{})
+	assert.NoError(t, err)
+	assert.True(t, include)
+}
@@ -0,0 +1,132 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Bind returns a copy of q with every :name parameter replaced by its value in
+// params, converted with ToValue. Slices bind to lists, and a list parameter
+// inside [...] is spliced into the list. Every parameter of the query must be
+// given and unknown names are rejected, so a typo cannot silently drop a
+// condition. Parameters can only stand for values, so bound values never
+// change the shape of the query.
+//
+// Example:
+//
+//	tmpl, _ := parser.NewParser("price > :min_price and brand IN :brands")
+//	q, _ := tmpl.Parse()
+//	bound, err := q.Bind(map[string]interface{}{"min_price": 10, "brands": []string{"Sony", "JBL"}})
+func (q *Query) Bind(params map[string]interface{}) (*Query, error) {
+	declared := make(map[string]bool)
+	for _, name := range q.Parameters() {
+		declared[name] = true
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("%w: :%s", ErrUnboundParameter, name)
+		}
+	}
+
+	values := make(map[ParameterValue]interface{}, len(params))
+	for name, raw := range params {
+		if !declared[name] {
+			return nil, fmt.Errorf("%w: unknown parameter :%s", ErrInvalidQuery, name)
+		}
+		value, err := ToValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parameter :%s: %v", ErrInvalidQuery, name, err)
+		}
+		values[ParameterValue(name)] = value
+	}
+
+	bound := *q
+	if len(values) > 0 {
+		bound.Filter = bindParameters(q.Filter, values)
+	}
+	return &bound, nil
+}
+
+// Parameters returns the names of the :name parameters in the filter of q, sorted
+func (q *Query) Parameters() []string {
+	seen := make(map[string]bool)
+	var walk func(node Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case *BinaryOpNode:
+			walk(n.Left)
+			walk(n.Right)
+		case *ComparisonNode:
+			values := []interface{}{n.Value}
+			if arr, ok := n.Value.(ArrayValue); ok {
+				values = arr
+			}
+			for _, value := range values {
+				if name, ok := value.(ParameterValue); ok {
+					seen[string(name)] = true
+				}
+			}
+		}
+	}
+	walk(q.Filter)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bindParameters returns a copy of node with parameters replaced by their values
+func bindParameters(node Node, values map[ParameterValue]interface{}) Node {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		return &BinaryOpNode{
+			Operator: n.Operator,
+			Left:     bindParameters(n.Left, values),
+			Right:    bindParameters(n.Right, values),
+		}
+	case *ComparisonNode:
+		bound := *n
+		bound.Value = bindParameterValue(n.Value, values)
+		return &bound
+	default:
+		return node
+	}
+}
+
+// bindParameterValue binds a parameter value, including inside lists
+func bindParameterValue(v interface{}, values map[ParameterValue]interface{}) interface{} {
+	switch val := v.(type) {
+	case ArrayValue:
+		bound := make(ArrayValue, 0, len(val))
+		for _, elem := range val {
+			value := bindParameterValue(elem, values)
+			// A list parameter inside [...] is spliced into the list
+			if inner, ok := value.(ArrayValue); ok {
+				bound = append(bound, inner...)
+				continue
+			}
+			bound = append(bound, value)
+		}
+		return bound
+	case ParameterValue:
+		return values[val]
+	default:
+		return v
+	}
+}
+
+// parameterIn returns the first parameter in a value or list
+func parameterIn(v interface{}) (ParameterValue, bool) {
+	switch val := v.(type) {
+	case ParameterValue:
+		return val, true
+	case ArrayValue:
+		for _, elem := range val {
+			if name, ok := elem.(ParameterValue); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
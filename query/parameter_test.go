@@ -0,0 +1,81 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_Bind(t *testing.T) {
+	template := &Query{
+		Filter: And(
+			Gt("price", ParameterValue("min_price")),
+			Or(
+				In("brand", ParameterValue("brands")),
+				In("tag", "sale", ParameterValue("tags")),
+			),
+		),
+		PageSize: 20,
+	}
+	assert.Equal(t, []string{"brands", "min_price", "tags"}, template.Parameters())
+
+	bound, err := template.Bind(map[string]interface{}{
+		"min_price": 10,
+		"brands":    []string{"Sony", "JBL"},
+		"tags":      []string{"new"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, And(
+		Gt("price", IntValue(10)),
+		Or(
+			In("brand", StringValue("Sony"), StringValue("JBL")),
+			In("tag", StringValue("sale"), StringValue("new")),
+		),
+	), bound.Filter)
+	assert.Equal(t, 20, bound.PageSize)
+	assert.Empty(t, bound.Parameters())
+
+	// The template is not modified and can be bound again
+	assert.Equal(t, ParameterValue("min_price"), template.Filter.(*BinaryOpNode).Left.(*ComparisonNode).Value)
+	again, err := template.Bind(map[string]interface{}{"min_price": 99.5, "brands": "Anker", "tags": []string{}})
+	require.NoError(t, err)
+	assert.Equal(t, FloatValue(99.5), again.Filter.(*BinaryOpNode).Left.(*ComparisonNode).Value)
+
+	// Bound comparisons keep their other attributes
+	phrase := &Query{Filter: &ComparisonNode{Field: "name", Operator: OpContains, Value: ParameterValue("term"), Phrase: true}}
+	bound, err = phrase.Bind(map[string]interface{}{"term": "usb cable"})
+	require.NoError(t, err)
+	assert.True(t, bound.Filter.(*ComparisonNode).Phrase)
+
+	// Queries without parameters bind to a copy
+	plain := &Query{Filter: Eq("status", "active")}
+	copied, err := plain.Bind(nil)
+	require.NoError(t, err)
+	assert.Equal(t, plain, copied)
+}
+
+func TestQuery_BindErrors(t *testing.T) {
+	template := &Query{Filter: Gt("price", ParameterValue("min_price"))}
+
+	_, err := template.Bind(nil)
+	assert.True(t, errors.Is(err, ErrUnboundParameter))
+	assert.ErrorContains(t, err, ":min_price")
+
+	_, err = template.Bind(map[string]interface{}{"min_price": 1, "max_price": 2})
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+	assert.ErrorContains(t, err, "unknown parameter :max_price")
+
+	_, err = template.Bind(map[string]interface{}{"min_price": struct{}{}})
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+}
+
+func TestValidateFilter_UnboundParameter(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	err := opts.ValidateFilter(In("group_id", 1, ParameterValue("groups")))
+	assert.True(t, errors.Is(err, ErrUnboundParameter))
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "group_id", fieldErr.Field)
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"container/list"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultPatternCacheSize is the number of compiled patterns kept by the
+// shared cache used by CompileRegex and CompileLike
+const DefaultPatternCacheSize = 1024
+
+// patterns is the cache shared by all executors in the process
+var patterns = NewPatternCache(DefaultPatternCacheSize)
+
+// CompileRegex compiles a REGEX pattern, reusing the compiled form from a
+// process-wide LRU cache. Invalid patterns are cached too, so they fail fast.
+// The returned regexp is safe for concurrent use and must not be modified.
+func CompileRegex(pattern string) (*regexp.Regexp, error) {
+	return patterns.Regex(pattern)
+}
+
+// CompileLike compiles a LIKE pattern (see LikeToRegex) through the shared cache
+func CompileLike(pattern string) (*regexp.Regexp, error) {
+	return patterns.Like(pattern)
+}
+
+// LikeToRegex converts a SQL LIKE pattern into an anchored regular expression:
+// % matches any run of characters, _ matches one character and everything else
+// matches literally. The result is valid for RE2 and PCRE.
+//
+// Example:
+//
+//	LikeToRegex("%.go") // ^.*\.go$
+func LikeToRegex(pattern string) string {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, "%", ".*")
+	quoted = strings.ReplaceAll(quoted, "_", ".")
+	return "^" + quoted + "$"
+}
+
+// PatternCache is a concurrency-safe LRU cache of compiled patterns.
+// Most code should use CompileRegex and CompileLike, which share one cache.
+type PatternCache struct {
+	mu      sync.Mutex
+	maxSize int
+	order   *list.List // front is the most recently used
+	entries map[string]*list.Element
+}
+
+// patternEntry is a cached compilation result
+type patternEntry struct {
+	key string
+	re  *regexp.Regexp
+	err error
+}
+
+// NewPatternCache creates a cache holding up to maxSize patterns.
+// maxSize <= 0 disables caching: every call compiles its pattern
+func NewPatternCache(maxSize int) *PatternCache {
+	return &PatternCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Regex returns the compiled form of a regular expression
+func (c *PatternCache) Regex(pattern string) (*regexp.Regexp, error) {
+	return c.get("regex:"+pattern, func() string { return pattern })
+}
+
+// Like returns the compiled form of a LIKE pattern
+func (c *PatternCache) Like(pattern string) (*regexp.Regexp, error) {
+	return c.get("like:"+pattern, func() string { return LikeToRegex(pattern) })
+}
+
+// Len returns the number of cached patterns
+func (c *PatternCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// get returns the cached compilation for key, compiling source() on a miss.
+// Compilation runs outside the lock, so a slow pattern never blocks other lookups
+func (c *PatternCache) get(key string, source func() string) (*regexp.Regexp, error) {
+	if c.maxSize <= 0 {
+		return regexp.Compile(source())
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*patternEntry)
+		c.mu.Unlock()
+		return entry.re, entry.err
+	}
+	c.mu.Unlock()
+
+	re, err := regexp.Compile(source())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		// Another caller compiled it meanwhile
+		c.order.MoveToFront(elem)
+		entry := elem.Value.(*patternEntry)
+		return entry.re, entry.err
+	}
+	c.entries[key] = c.order.PushFront(&patternEntry{key: key, re: re, err: err})
+	if c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*patternEntry).key)
+	}
+	return re, err
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikeToRegex(t *testing.T) {
+	assert.Equal(t, `^.*\.go$`, LikeToRegex("%.go"))
+	assert.Equal(t, `^a.c$`, LikeToRegex("a_c"))
+	assert.Equal(t, `^\(1\+1\)\*\?$`, LikeToRegex("(1+1)*?"))
+
+	re, err := CompileLike("%wireless%")
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("Ergonomic wireless mouse"))
+	assert.False(t, re.MatchString("Wired mouse"))
+
+	re, err = CompileLike("a*b")
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("a*b"))
+	assert.False(t, re.MatchString("aab"))
+}
+
+func TestPatternCache(t *testing.T) {
+	c := NewPatternCache(2)
+
+	first, err := c.Regex("^a+$")
+	require.NoError(t, err)
+	again, err := c.Regex("^a+$")
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+
+	// LIKE and REGEX patterns with the same text are cached separately
+	like, err := c.Like("a%")
+	require.NoError(t, err)
+	assert.Equal(t, "^a.*$", like.String())
+	assert.Equal(t, 2, c.Len())
+
+	t.Run("least recently used patterns are evicted", func(t *testing.T) {
+		_, _ = c.Regex("^a+$") // now more recent than the LIKE pattern
+		_, err := c.Regex("b")
+		require.NoError(t, err)
+		assert.Equal(t, 2, c.Len())
+
+		again, _ := c.Regex("^a+$")
+		assert.Same(t, first, again)
+		relike, _ := c.Like("a%")
+		assert.NotSame(t, like, relike)
+	})
+
+	t.Run("invalid patterns are cached", func(t *testing.T) {
+		_, err := c.Regex("(")
+		assert.Error(t, err)
+		_, err = c.Regex("(")
+		assert.Error(t, err)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c := NewPatternCache(0)
+		a, _ := c.Regex("x")
+		b, _ := c.Regex("x")
+		assert.NotSame(t, a, b)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		c := NewPatternCache(8)
+		var wg sync.WaitGroup
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					re, err := c.Like(fmt.Sprintf("%%%d%%", (i+j)%12))
+					assert.NoError(t, err)
+					assert.True(t, re.MatchString(fmt.Sprintf("x%dy", (i+j)%12)))
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 8, c.Len())
+	})
+}
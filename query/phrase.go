@@ -0,0 +1,84 @@
+package query
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PhraseMatch is how executors match quoted bare search terms, such as
+// "usb cable". Unquoted terms are always matched independently
+type PhraseMatch int
+
+const (
+	// PhraseMatchSubstring matches the phrase as written anywhere in the
+	// text, like CONTAINS: "usb cable" matches "usb cables"
+	PhraseMatchSubstring PhraseMatch = iota
+	// PhraseMatchWords matches the phrase only as whole words: "usb cable"
+	// matches "a usb cable" but not "usb cables". Backends without regular
+	// expressions, and executors with DisableRegex, match substrings instead
+	PhraseMatchWords
+	// PhraseMatchTerms ignores the quotes and matches each word of the phrase
+	// on its own, like unquoted terms
+	PhraseMatchTerms
+)
+
+// String returns the name of the mode
+func (m PhraseMatch) String() string {
+	switch m {
+	case PhraseMatchWords:
+		return "words"
+	case PhraseMatchTerms:
+		return "terms"
+	default:
+		return "substring"
+	}
+}
+
+// ExpandPhrase rewrites a quoted bare search term according to PhraseMatch:
+// into a whole-word REGEX condition with PhraseMatchWords, or into an AND of
+// unquoted terms with PhraseMatchTerms. regex reports whether the backend runs
+// regular expressions; without it whole words are matched as substrings.
+// Other nodes, and phrases matched as substrings, are returned unchanged.
+//
+// Executors call it on bare search terms before resolving the search fields:
+//
+//	if expanded := e.options.ExpandPhrase(n, true); expanded != n {
+//		return e.buildFilter(expanded)
+//	}
+func (o *ExecutorOptions) ExpandPhrase(n *ComparisonNode, regex bool) Node {
+	phrase, ok := n.Value.(StringValue)
+	if !n.Phrase || n.Field != SearchField || n.Operator != OpContains || !ok {
+		return n
+	}
+	words := strings.Fields(string(phrase))
+	switch {
+	case o.PhraseMatch == PhraseMatchTerms && len(words) > 0:
+		var expanded Node
+		for _, word := range words {
+			term := &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue(word)}
+			if expanded == nil {
+				expanded = term
+				continue
+			}
+			expanded = &BinaryOpNode{Operator: BinaryOpAnd, Left: expanded, Right: term}
+		}
+		return expanded
+	case o.PhraseMatch == PhraseMatchWords && regex && !o.DisableRegex && len(words) > 0:
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		pattern := `(?:^|\W)` + strings.Join(words, `\s+`) + `(?:\W|$)`
+		return &ComparisonNode{Field: SearchField, Operator: OpRegex, Value: StringValue(pattern), Phrase: true}
+	}
+	return n
+}
+
+// ConditionPattern returns the pattern an executor should run for the REGEX
+// condition n with the given value: RegexPattern(pattern), or pattern as is for
+// the whole-word pattern of a phrase, which AnchorRegex must not anchor
+func (o *ExecutorOptions) ConditionPattern(n *ComparisonNode, pattern string) string {
+	if n.Phrase {
+		return pattern
+	}
+	return o.RegexPattern(pattern)
+}
@@ -0,0 +1,209 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// PlaceholderResolver returns the value of a placeholder for the request in ctx,
+// e.g. the ID of the authenticated user. The value is converted with ToValue;
+// slices resolve to lists, so "group_id IN @my_groups" works too
+type PlaceholderResolver func(ctx context.Context) (interface{}, error)
+
+// ResolvePlaceholders returns a copy of q with every @name value replaced by
+// the value of its resolver in Placeholders, and every relative time such as
+// now-7d by the instant it stands for on Clock, which is read once. Each
+// placeholder is resolved once. q is returned unchanged when it has neither.
+// Executors call it before ValidateFilter, so limits and policies see the
+// resolved values; unresolved placeholders fail validation with ErrUnknownPlaceholder
+func (o *ExecutorOptions) ResolvePlaceholders(ctx context.Context, q *Query) (*Query, error) {
+	if q == nil {
+		return q, nil
+	}
+	_, relative := relativeTimeIn(q.Filter)
+	if !relative && !hasPlaceholders(q.Filter) {
+		return q, nil
+	}
+	bound := *q
+	if hasPlaceholders(q.Filter) {
+		filter, err := o.resolveNode(ctx, q.Filter, make(map[PlaceholderValue]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		bound.Filter = filter
+	}
+	if relative {
+		filter, err := resolveRelativeTimes(bound.Filter, o.now())
+		if err != nil {
+			return nil, err
+		}
+		bound.unresolved = bound.Filter
+		bound.Filter = filter
+	}
+	return &bound, nil
+}
+
+// HasDynamicValues reports whether node has placeholders or relative times,
+// whose values ResolvePlaceholders fills in on every call
+func HasDynamicValues(node Node) bool {
+	_, relative := relativeTimeIn(node)
+	return relative || hasPlaceholders(node)
+}
+
+// now reads Clock, or the system clock when it is not set
+func (o *ExecutorOptions) now() time.Time {
+	if o.Clock != nil {
+		return o.Clock()
+	}
+	return time.Now()
+}
+
+// resolveNode returns a copy of node with placeholders replaced by their values
+func (o *ExecutorOptions) resolveNode(ctx context.Context, node Node, resolved map[PlaceholderValue]interface{}) (Node, error) {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		left, err := o.resolveNode(ctx, n.Left, resolved)
+		if err != nil {
+			return nil, err
+		}
+		right, err := o.resolveNode(ctx, n.Right, resolved)
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryOpNode{Operator: n.Operator, Left: left, Right: right}, nil
+	case *ComparisonNode:
+		value, err := o.resolveValue(ctx, n.Value, resolved)
+		if err != nil {
+			return nil, NewFieldError(n.Field, err)
+		}
+		bound := *n
+		bound.Value = value
+		return &bound, nil
+	default:
+		return node, nil
+	}
+}
+
+// resolveValue resolves a placeholder value, including inside lists
+func (o *ExecutorOptions) resolveValue(ctx context.Context, v interface{}, resolved map[PlaceholderValue]interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case ArrayValue:
+		bound := make(ArrayValue, 0, len(val))
+		for _, elem := range val {
+			value, err := o.resolveValue(ctx, elem, resolved)
+			if err != nil {
+				return nil, err
+			}
+			// A list placeholder inside [...] is spliced into the list
+			if inner, ok := value.(ArrayValue); ok {
+				bound = append(bound, inner...)
+				continue
+			}
+			bound = append(bound, value)
+		}
+		return bound, nil
+	case PlaceholderValue:
+		if value, ok := resolved[val]; ok {
+			return value, nil
+		}
+		resolver, ok := o.Placeholders[string(val)]
+		if !ok || resolver == nil {
+			return nil, fmt.Errorf("%w: @%s", ErrUnknownPlaceholder, val)
+		}
+		raw, err := resolver(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolve @%s: %w", val, err)
+		}
+		value, err := ToValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: @%s: %v", ErrInvalidQuery, val, err)
+		}
+		resolved[val] = value
+		return value, nil
+	default:
+		return v, nil
+	}
+}
+
+// hasPlaceholders reports whether node uses any placeholder
+func hasPlaceholders(node Node) bool {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		return hasPlaceholders(n.Left) || hasPlaceholders(n.Right)
+	case *ComparisonNode:
+		_, ok := placeholderIn(n.Value)
+		return ok
+	}
+	return false
+}
+
+// placeholderIn returns the first placeholder in a value or list
+func placeholderIn(v interface{}) (PlaceholderValue, bool) {
+	switch val := v.(type) {
+	case PlaceholderValue:
+		return val, true
+	case ArrayValue:
+		for _, elem := range val {
+			if name, ok := placeholderIn(elem); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ToValue converts a Go value into a query value type: strings, booleans,
+// integers, floats, time.Time and slices of them. Unsigned integers above
+// math.MaxInt64 become FloatValue rather than wrapping around. Query values,
+// including placeholders, parameters and relative times, are returned unchanged
+func ToValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case StringValue, IntValue, FloatValue, BoolValue, DateTimeValue, ArrayValue,
+		PlaceholderValue, ParameterValue, RelativeTimeValue:
+		return val, nil
+	case string:
+		return StringValue(val), nil
+	case bool:
+		return BoolValue(val), nil
+	case time.Time:
+		return DateTimeValue(val), nil
+	case nil:
+		return nil, errors.New("nil value")
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntValue(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintValue(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return FloatValue(rv.Float()), nil
+	case reflect.String:
+		return StringValue(rv.String()), nil
+	case reflect.Slice, reflect.Array:
+		arr := make(ArrayValue, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			elem, err := ToValue(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, elem)
+		}
+		return arr, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", v)
+}
+
+// uintValue converts an unsigned integer to an IntValue, or to a FloatValue
+// above math.MaxInt64 where an IntValue would wrap around to a negative number
+func uintValue(u uint64) interface{} {
+	if u > math.MaxInt64 {
+		return FloatValue(u)
+	}
+	return IntValue(u)
+}
@@ -0,0 +1,134 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type userKey struct{}
+
+func TestResolvePlaceholders(t *testing.T) {
+	calls := 0
+	opts := DefaultExecutorOptions()
+	opts.Placeholders = map[string]PlaceholderResolver{
+		"current_user": func(ctx context.Context) (interface{}, error) {
+			calls++
+			return ctx.Value(userKey{}), nil
+		},
+		"my_groups": func(ctx context.Context) (interface{}, error) {
+			return []int{3, 4}, nil
+		},
+	}
+	ctx := context.WithValue(context.Background(), userKey{}, 42)
+
+	q := &Query{
+		Filter: And(
+			Eq("owner_id", PlaceholderValue("current_user")),
+			Or(
+				Eq("reviewer_id", PlaceholderValue("current_user")),
+				In("group_id", 1, PlaceholderValue("my_groups")),
+			),
+		),
+		PageSize: 20,
+	}
+	resolved, err := opts.ResolvePlaceholders(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, And(
+		Eq("owner_id", IntValue(42)),
+		Or(
+			Eq("reviewer_id", IntValue(42)),
+			In("group_id", IntValue(1), IntValue(3), IntValue(4)),
+		),
+	), resolved.Filter)
+	assert.Equal(t, 20, resolved.PageSize)
+	assert.Equal(t, 1, calls, "each placeholder is resolved once")
+
+	// The query is not modified
+	assert.Equal(t, PlaceholderValue("current_user"), q.Filter.(*BinaryOpNode).Left.(*ComparisonNode).Value)
+
+	// Resolved comparisons keep their other attributes
+	phrase := &ComparisonNode{Field: "name", Operator: OpContains, Value: PlaceholderValue("current_user"), Phrase: true}
+	resolved, err = opts.ResolvePlaceholders(ctx, &Query{Filter: phrase})
+	require.NoError(t, err)
+	assert.True(t, resolved.Filter.(*ComparisonNode).Phrase)
+
+	// Queries without placeholders are returned unchanged
+	plain := &Query{Filter: Eq("status", "active")}
+	same, err := opts.ResolvePlaceholders(ctx, plain)
+	require.NoError(t, err)
+	assert.Same(t, plain, same)
+}
+
+func TestResolvePlaceholders_Errors(t *testing.T) {
+	errNoUser := errors.New("no user")
+	opts := DefaultExecutorOptions()
+	opts.Placeholders = map[string]PlaceholderResolver{
+		"current_user": func(context.Context) (interface{}, error) { return nil, errNoUser },
+		"session":      func(context.Context) (interface{}, error) { return struct{}{}, nil },
+	}
+
+	_, err := opts.ResolvePlaceholders(context.Background(), &Query{Filter: Eq("owner_id", PlaceholderValue("tenant"))})
+	assert.True(t, errors.Is(err, ErrUnknownPlaceholder))
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "owner_id", fieldErr.Field)
+
+	_, err = opts.ResolvePlaceholders(context.Background(), &Query{Filter: Eq("owner_id", PlaceholderValue("current_user"))})
+	assert.True(t, errors.Is(err, errNoUser))
+
+	_, err = opts.ResolvePlaceholders(context.Background(), &Query{Filter: Eq("session", PlaceholderValue("session"))})
+	assert.True(t, errors.Is(err, ErrInvalidQuery))
+}
+
+func TestValidateFilter_UnresolvedPlaceholder(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	err := opts.ValidateFilter(In("group_id", PlaceholderValue("my_groups")))
+	assert.True(t, errors.Is(err, ErrUnknownPlaceholder))
+}
+
+func TestHasDynamicValues(t *testing.T) {
+	week, ok := ParseRelativeTime("now-7d")
+	require.True(t, ok)
+
+	assert.False(t, HasDynamicValues(nil))
+	assert.False(t, HasDynamicValues(And(Eq("status", "active"), Gt("price", 10))))
+	assert.True(t, HasDynamicValues(And(Eq("status", "active"), Eq("owner_id", PlaceholderValue("current_user")))))
+	assert.True(t, HasDynamicValues(Or(Eq("status", "active"), Gt("created_at", week))))
+}
+
+func TestToValue(t *testing.T) {
+	type status string
+	tests := []struct {
+		in       interface{}
+		expected interface{}
+	}{
+		{"a", StringValue("a")},
+		{status("active"), StringValue("active")},
+		{int32(7), IntValue(7)},
+		{uint8(7), IntValue(7)},
+		{uint64(math.MaxUint64), FloatValue(math.MaxUint64)},
+		{uint(math.MaxInt64), IntValue(math.MaxInt64)},
+		{1.5, FloatValue(1.5)},
+		{true, BoolValue(true)},
+		{[]string{"a", "b"}, ArrayValue{StringValue("a"), StringValue("b")}},
+		{IntValue(3), IntValue(3)},
+		{PlaceholderValue("user"), PlaceholderValue("user")},
+		{ParameterValue("min"), ParameterValue("min")},
+		{RelativeTimeValue("now-7d"), RelativeTimeValue("now-7d")},
+	}
+	for _, tt := range tests {
+		v, err := ToValue(tt.in)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, v)
+	}
+
+	_, err := ToValue(nil)
+	assert.Error(t, err)
+	_, err = ToValue(map[string]int{})
+	assert.Error(t, err)
+}
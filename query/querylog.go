@@ -0,0 +1,123 @@
+package query
+
+import (
+	"context"
+	"time"
+)
+
+// QueryLogger receives every query an executor runs, e.g. so security teams
+// can audit what users search for. Set it in ExecutorOptions.QueryLogger.
+// LogQuery runs on the goroutine of the call after it completes, so slow
+// loggers should hand entries off
+type QueryLogger interface {
+	LogQuery(ctx context.Context, entry QueryLog)
+}
+
+// QueryLoggerFunc adapts a function to the QueryLogger interface
+type QueryLoggerFunc func(ctx context.Context, entry QueryLog)
+
+// LogQuery calls f
+func (f QueryLoggerFunc) LogQuery(ctx context.Context, entry QueryLog) {
+	f(ctx, entry)
+}
+
+// QueryLog describes one Execute or Count call
+type QueryLog struct {
+	// Backend is the name of the executor
+	Backend string
+
+	// Operation is "execute", "count", "delete", "update" or "watch"
+	Operation string
+
+	// Query is Filter in query syntax. With RedactQueryLog its values are
+	// replaced by ?, e.g. email = ? AND age > ?
+	Query string
+
+	// Filter is the filter that ran: placeholders resolved and BaseFilter
+	// applied. Queries rejected before resolution log the filter as given.
+	// Nil with RedactQueryLog
+	Filter Node
+
+	// Fingerprint identifies the shape of the query, values left out; see
+	// Fingerprint. It groups entries of the same query with different values
+	Fingerprint string
+
+	// SortBy, PageSize and Limit are taken from the query as given
+	SortBy   string
+	PageSize int
+	Limit    int
+
+	// Duration is how long the call took
+	Duration time.Duration
+
+	// ItemsReturned is the number of items in the page (Execute only)
+	ItemsReturned int
+
+	// TotalItems is the total number of matching items; for Count, the count
+	TotalItems int64
+
+	// ItemsAffected is the number of items deleted or updated (writes only)
+	ItemsAffected int64
+
+	// Err is the error returned by the call, if any
+	Err error
+
+	start time.Time
+}
+
+// NewQueryLog starts the log entry of a call. Executors update Filter once the
+// query is resolved and pass the entry to LogExecute or LogCount
+func NewQueryLog(backend, operation string, q *Query) QueryLog {
+	entry := QueryLog{Backend: backend, Operation: operation, start: time.Now()}
+	if q != nil {
+		entry.Filter = q.Filter
+		entry.Fingerprint = Fingerprint(q)
+		entry.SortBy = q.SortBy
+		entry.PageSize = q.PageSize
+		entry.Limit = q.Limit
+	}
+	return entry
+}
+
+// LogExecute completes entry with the outcome of Execute and passes it to QueryLogger
+func (o *ExecutorOptions) LogExecute(ctx context.Context, entry QueryLog, result *Result, err error) {
+	if result != nil {
+		entry.ItemsReturned = result.ItemsReturned
+		entry.TotalItems = result.TotalItems
+	}
+	o.logQuery(ctx, entry, err)
+}
+
+// LogCount completes entry with the outcome of Count and passes it to QueryLogger
+func (o *ExecutorOptions) LogCount(ctx context.Context, entry QueryLog, count int64, err error) {
+	entry.TotalItems = count
+	o.logQuery(ctx, entry, err)
+}
+
+// LogWrite completes entry with the outcome of DeleteWhere or UpdateWhere and
+// passes it to QueryLogger
+func (o *ExecutorOptions) LogWrite(ctx context.Context, entry QueryLog, affected int64, err error) {
+	entry.ItemsAffected = affected
+	o.logQuery(ctx, entry, err)
+}
+
+// LogWatch completes entry with the outcome of opening a watch (a live query)
+// and passes it to QueryLogger. Events delivered later are not logged
+func (o *ExecutorOptions) LogWatch(ctx context.Context, entry QueryLog, err error) {
+	o.logQuery(ctx, entry, err)
+}
+
+func (o *ExecutorOptions) logQuery(ctx context.Context, entry QueryLog, err error) {
+	if o == nil || o.QueryLogger == nil {
+		return
+	}
+	entry.Duration = time.Since(entry.start)
+	entry.Err = err
+	if o.RedactQueryLog {
+		entry.Query = NormalizeFilter(entry.Filter)
+		entry.Filter = nil
+	} else {
+		entry.Query = FormatFilter(entry.Filter)
+	}
+	o.QueryLogger.LogQuery(ctx, entry)
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorOptions_LogExecute(t *testing.T) {
+	var logged []QueryLog
+	opts := DefaultExecutorOptions()
+	opts.QueryLogger = QueryLoggerFunc(func(ctx context.Context, entry QueryLog) {
+		logged = append(logged, entry)
+	})
+	q := &Query{Filter: And(Eq("email", "a@example.com"), Gt("age", 30)), SortBy: "age", PageSize: 5}
+
+	entry := NewQueryLog("memory", "execute", q)
+	opts.LogExecute(context.Background(), entry, &Result{ItemsReturned: 2, TotalItems: 7}, nil)
+
+	require.Len(t, logged, 1)
+	got := logged[0]
+	assert.Equal(t, "memory", got.Backend)
+	assert.Equal(t, "execute", got.Operation)
+	assert.Equal(t, `email = "a@example.com" AND age > 30`, got.Query)
+	assert.Equal(t, q.Filter, got.Filter)
+	assert.Equal(t, Fingerprint(q), got.Fingerprint)
+	assert.Equal(t, "age", got.SortBy)
+	assert.Equal(t, 5, got.PageSize)
+	assert.Equal(t, 2, got.ItemsReturned)
+	assert.Equal(t, int64(7), got.TotalItems)
+	assert.GreaterOrEqual(t, int64(got.Duration), int64(0))
+	assert.NoError(t, got.Err)
+}
+
+func TestExecutorOptions_LogCountRedacted(t *testing.T) {
+	var logged []QueryLog
+	opts := DefaultExecutorOptions()
+	opts.RedactQueryLog = true
+	opts.QueryLogger = QueryLoggerFunc(func(ctx context.Context, entry QueryLog) {
+		logged = append(logged, entry)
+	})
+	failure := errors.New("boom")
+
+	entry := NewQueryLog("gorm", "count", &Query{Filter: Eq("email", "a@example.com")})
+	opts.LogCount(context.Background(), entry, 0, failure)
+
+	require.Len(t, logged, 1)
+	assert.Equal(t, "email = ?", logged[0].Query)
+	assert.Nil(t, logged[0].Filter)
+	assert.Equal(t, failure, logged[0].Err)
+}
+
+func TestExecutorOptions_LogWithoutLogger(t *testing.T) {
+	entry := NewQueryLog("memory", "execute", nil)
+	assert.NotPanics(t, func() {
+		DefaultExecutorOptions().LogExecute(context.Background(), entry, nil, nil)
+		var opts *ExecutorOptions
+		opts.LogCount(context.Background(), entry, 1, nil)
+	})
+}
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+)
+
+// ResolveRandomSeed returns the seed that orders a page of a random query:
+// the seed carried by the page's cursor, else the query's RandomSeed, else a
+// new random seed. The result is never 0, so executors can tell a seed from
+// its absence in cursors.
+func ResolveRandomSeed(q *Query, cursorSeed int64) int64 {
+	if cursorSeed != 0 {
+		return cursorSeed
+	}
+	if q != nil && q.RandomSeed != 0 {
+		return q.RandomSeed
+	}
+	for {
+		if seed := rand.Int63(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// RandomKey returns the sort key of an item with the given ID under seed.
+// Sorting by it, with ties broken by ID, gives an order that depends only on
+// the seed and the IDs, not on the order items are stored in.
+func RandomKey(id interface{}, seed int64) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d:%v", seed, id)
+	return h.Sum64()
+}
@@ -0,0 +1,28 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveRandomSeed(t *testing.T) {
+	q := &Query{SortOrder: SortOrderRandom, RandomSeed: 7}
+
+	assert.Equal(t, int64(9), ResolveRandomSeed(q, 9), "cursor seed wins")
+	assert.Equal(t, int64(7), ResolveRandomSeed(q, 0))
+	assert.NotZero(t, ResolveRandomSeed(&Query{SortOrder: SortOrderRandom}, 0))
+	assert.NotZero(t, ResolveRandomSeed(nil, 0))
+}
+
+func TestRandomKey(t *testing.T) {
+	assert.Equal(t, RandomKey(1, 7), RandomKey(1, 7))
+	assert.NotEqual(t, RandomKey(1, 7), RandomKey(2, 7))
+	assert.NotEqual(t, RandomKey(1, 7), RandomKey(1, 8))
+}
+
+func TestBuilder_Random(t *testing.T) {
+	q := F("category").Eq("books").Random(42).Build()
+	assert.Equal(t, SortOrderRandom, q.SortOrder)
+	assert.Equal(t, int64(42), q.RandomSeed)
+}
@@ -0,0 +1,344 @@
+package query
+
+import (
+	"sort"
+	"time"
+)
+
+// RangeStrategy selects how a range list (OR-ed ranges over one field) is
+// compiled by executors
+type RangeStrategy int
+
+const (
+	// RangeStrategyAuto lets PlanRanges choose the strategy (default)
+	RangeStrategyAuto RangeStrategy = iota
+	// RangeStrategyOr compiles the list to OR-ed range predicates, one per
+	// merged range, that the database can serve with index range scans
+	RangeStrategyOr
+	// RangeStrategyUnionAll runs one range scan per range and combines the
+	// results with UNION ALL. This only applies to SQL-based executors (GORM);
+	// other executors use RangeStrategyOr
+	RangeStrategyUnionAll
+)
+
+// String returns the string representation of RangeStrategy
+func (s RangeStrategy) String() string {
+	switch s {
+	case RangeStrategyOr:
+		return "or"
+	case RangeStrategyUnionAll:
+		return "union_all"
+	default:
+		return "auto"
+	}
+}
+
+// MaxUnionRanges is the largest range list RangeStrategyAuto runs as a UNION ALL
+const MaxUnionRanges = 16
+
+// Range is an interval of field values. A nil bound leaves that side open
+type Range struct {
+	Lower          interface{}
+	LowerInclusive bool
+	Upper          interface{}
+	UpperInclusive bool
+}
+
+// Closed reports whether both bounds are set
+func (r Range) Closed() bool {
+	return r.Lower != nil && r.Upper != nil
+}
+
+// Node returns the comparisons that select the range on field
+func (r Range) Node(field string) Node {
+	var lower, upper Node
+	if r.Lower != nil {
+		op := OpGreaterThan
+		if r.LowerInclusive {
+			op = OpGreaterThanOrEqual
+		}
+		lower = &ComparisonNode{Field: field, Operator: op, Value: r.Lower}
+	}
+	if r.Upper != nil {
+		op := OpLessThan
+		if r.UpperInclusive {
+			op = OpLessThanOrEqual
+		}
+		upper = &ComparisonNode{Field: field, Operator: op, Value: r.Upper}
+	}
+	return And(lower, upper)
+}
+
+// RangeList is a set of disjoint ranges over one field, sorted by lower bound
+type RangeList struct {
+	Field  string
+	Ranges []Range
+}
+
+// Node returns the filter matching the list: the ranges joined with OR
+func (l *RangeList) Node() Node {
+	var result Node
+	for _, r := range l.Ranges {
+		result = Or(result, r.Node(l.Field))
+	}
+	return result
+}
+
+// ExtractRanges reports whether node is an OR of ranges over a single field,
+// such as (price >= 10 and price <= 20) or (price >= 50 and price <= 60).
+// Each branch is a >, >=, < or <= comparison or an AND of one lower and one
+// upper bound. Bounds must all be numbers or all be datetimes.
+// The returned ranges are sorted, and overlapping or touching ranges are
+// merged, so they are disjoint; empty ranges are dropped. Lists that match
+// nothing or every value are not range lists.
+func ExtractRanges(node Node) (*RangeList, bool) {
+	var field string
+	var ranges []Range
+	var collect func(Node) bool
+	collect = func(node Node) bool {
+		if n, ok := node.(*BinaryOpNode); ok && n.Operator == BinaryOpOr {
+			return collect(n.Left) && collect(n.Right)
+		}
+		f, r, ok := branchRange(node)
+		if !ok || (field != "" && f != field) {
+			return false
+		}
+		field = f
+		ranges = append(ranges, r)
+		return true
+	}
+	if !collect(node) || !comparableBounds(ranges) {
+		return nil, false
+	}
+	merged := mergeRanges(ranges)
+	if len(merged) == 0 || (merged[0].Lower == nil && merged[0].Upper == nil) {
+		// Nothing or every non-null value matches; neither is a range list
+		return nil, false
+	}
+	return &RangeList{Field: field, Ranges: merged}, true
+}
+
+// branchRange returns the range selected by a single OR branch
+func branchRange(node Node) (string, Range, bool) {
+	switch n := node.(type) {
+	case *ComparisonNode:
+		var r Range
+		if !applyBound(&r, n) {
+			return "", Range{}, false
+		}
+		return n.Field, r, true
+	case *BinaryOpNode:
+		left, lok := n.Left.(*ComparisonNode)
+		right, rok := n.Right.(*ComparisonNode)
+		if n.Operator != BinaryOpAnd || !lok || !rok || left.Field != right.Field {
+			return "", Range{}, false
+		}
+		var r Range
+		if !applyBound(&r, left) || !applyBound(&r, right) || !r.Closed() {
+			return "", Range{}, false
+		}
+		return left.Field, r, true
+	}
+	return "", Range{}, false
+}
+
+// applyBound sets the bound of r given by a range comparison. It fails for
+// other operators, search terms, array conditions and bounds that are already set
+func applyBound(r *Range, n *ComparisonNode) bool {
+	if n.Field == SearchField || n.Modifier != ArrayModifierNone || rangeValueKind(n.Value) == 0 {
+		return false
+	}
+	switch n.Operator {
+	case OpGreaterThan, OpGreaterThanOrEqual:
+		if r.Lower != nil {
+			return false
+		}
+		r.Lower, r.LowerInclusive = n.Value, n.Operator == OpGreaterThanOrEqual
+	case OpLessThan, OpLessThanOrEqual:
+		if r.Upper != nil {
+			return false
+		}
+		r.Upper, r.UpperInclusive = n.Value, n.Operator == OpLessThanOrEqual
+	default:
+		return false
+	}
+	return true
+}
+
+// rangeValueKind classifies bound values: 1 for numbers, 2 for datetimes and
+// 0 for values that cannot be ordered
+func rangeValueKind(v interface{}) int {
+	switch v.(type) {
+	case IntValue, FloatValue:
+		return 1
+	case DateTimeValue:
+		return 2
+	}
+	return 0
+}
+
+// comparableBounds reports whether all bounds have the same kind
+func comparableBounds(ranges []Range) bool {
+	kind := 0
+	for _, r := range ranges {
+		for _, bound := range []interface{}{r.Lower, r.Upper} {
+			if bound == nil {
+				continue
+			}
+			k := rangeValueKind(bound)
+			if kind != 0 && k != kind {
+				return false
+			}
+			kind = k
+		}
+	}
+	return true
+}
+
+// compareBounds compares two bound values of the same kind
+func compareBounds(a, b interface{}) int {
+	if ta, ok := a.(DateTimeValue); ok {
+		tb := b.(DateTimeValue)
+		return time.Time(ta).Compare(time.Time(tb))
+	}
+	fa, fb := boundFloat(a), boundFloat(b)
+	switch {
+	case fa < fb:
+		return -1
+	case fa > fb:
+		return 1
+	}
+	return 0
+}
+
+func boundFloat(v interface{}) float64 {
+	if i, ok := v.(IntValue); ok {
+		return float64(i)
+	}
+	return float64(v.(FloatValue))
+}
+
+// mergeRanges sorts ranges by lower bound and merges overlapping or touching ones
+func mergeRanges(ranges []Range) []Range {
+	nonEmpty := make([]Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Closed() {
+			c := compareBounds(r.Lower, r.Upper)
+			if c > 0 || (c == 0 && !(r.LowerInclusive && r.UpperInclusive)) {
+				continue
+			}
+		}
+		nonEmpty = append(nonEmpty, r)
+	}
+	sort.SliceStable(nonEmpty, func(i, j int) bool {
+		a, b := nonEmpty[i], nonEmpty[j]
+		if a.Lower == nil || b.Lower == nil {
+			return a.Lower == nil && b.Lower != nil
+		}
+		c := compareBounds(a.Lower, b.Lower)
+		return c < 0 || (c == 0 && a.LowerInclusive && !b.LowerInclusive)
+	})
+
+	var merged []Range
+	for _, r := range nonEmpty {
+		if len(merged) == 0 {
+			merged = append(merged, r)
+			continue
+		}
+		last := &merged[len(merged)-1]
+		if last.Upper != nil && r.Lower != nil {
+			c := compareBounds(last.Upper, r.Lower)
+			if c < 0 || (c == 0 && !last.UpperInclusive && !r.LowerInclusive) {
+				// Disjoint
+				merged = append(merged, r)
+				continue
+			}
+		}
+		// Overlapping or touching: extend the upper bound
+		switch {
+		case last.Upper == nil:
+		case r.Upper == nil:
+			last.Upper, last.UpperInclusive = nil, false
+		default:
+			c := compareBounds(r.Upper, last.Upper)
+			if c > 0 || (c == 0 && r.UpperInclusive) {
+				last.Upper, last.UpperInclusive = r.Upper, r.UpperInclusive
+			}
+		}
+	}
+	return merged
+}
+
+// RangePlan is the result of PlanRanges: the filter is the range list ANDed
+// with the remaining conditions
+type RangePlan struct {
+	*RangeList
+
+	// Rest holds the other conditions of the filter; nil when there are none
+	Rest Node
+
+	// Strategy is the resolved strategy, never RangeStrategyAuto
+	Strategy RangeStrategy
+}
+
+// Node returns the planned filter: the merged range list ANDed with Rest
+func (p *RangePlan) Node() Node {
+	return And(p.RangeList.Node(), p.Rest)
+}
+
+// PlanRanges is the optimizer pass for range lists. It looks for an OR of
+// ranges over one field (see ExtractRanges) among the AND-ed conditions of
+// filter and picks how executors compile it. Without a RangeStrategy,
+// a UNION ALL is chosen for 2 to MaxUnionRanges closed ranges, where one
+// index range scan per range beats a scan filtered by OR; other lists use OR.
+//
+// PlanRanges returns nil when the filter has no range list, and when a
+// ValueConverter is configured, since converted bounds may not keep their order.
+// Other conditions are kept as they are in Rest.
+func (o *ExecutorOptions) PlanRanges(filter Node) *RangePlan {
+	if filter == nil || o.ValueConverter != nil {
+		return nil
+	}
+	conditions := conjuncts(filter)
+	for i, condition := range conditions {
+		if n, ok := condition.(*BinaryOpNode); !ok || n.Operator != BinaryOpOr {
+			continue
+		}
+		ranges, ok := ExtractRanges(condition)
+		if !ok {
+			continue
+		}
+		var rest Node
+		for j, other := range conditions {
+			if j != i {
+				rest = And(rest, other)
+			}
+		}
+		return &RangePlan{RangeList: ranges, Rest: rest, Strategy: o.rangeStrategy(ranges)}
+	}
+	return nil
+}
+
+// rangeStrategy resolves RangeStrategy for a range list
+func (o *ExecutorOptions) rangeStrategy(ranges *RangeList) RangeStrategy {
+	if o.RangeStrategy != RangeStrategyAuto {
+		return o.RangeStrategy
+	}
+	if len(ranges.Ranges) < 2 || len(ranges.Ranges) > MaxUnionRanges {
+		return RangeStrategyOr
+	}
+	for _, r := range ranges.Ranges {
+		if !r.Closed() {
+			return RangeStrategyOr
+		}
+	}
+	return RangeStrategyUnionAll
+}
+
+// conjuncts flattens the AND-ed conditions of node, left to right
+func conjuncts(node Node) []Node {
+	if n, ok := node.(*BinaryOpNode); ok && n.Operator == BinaryOpAnd {
+		return append(conjuncts(n.Left), conjuncts(n.Right)...)
+	}
+	return []Node{node}
+}
@@ -0,0 +1,148 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func between(field string, lo, hi interface{}) Node {
+	return And(Gte(field, lo), Lte(field, hi))
+}
+
+func TestExtractRanges(t *testing.T) {
+	t.Run("disjoint closed ranges", func(t *testing.T) {
+		list, ok := ExtractRanges(Or(between("price", 50, 60), between("price", 10, 20)))
+		require.True(t, ok)
+		assert.Equal(t, "price", list.Field)
+		assert.Equal(t, []Range{
+			{Lower: IntValue(10), LowerInclusive: true, Upper: IntValue(20), UpperInclusive: true},
+			{Lower: IntValue(50), LowerInclusive: true, Upper: IntValue(60), UpperInclusive: true},
+		}, list.Ranges)
+	})
+
+	t.Run("overlapping and touching ranges merge", func(t *testing.T) {
+		list, ok := ExtractRanges(Or(
+			between("price", 10, 20),
+			between("price", 15, 30.5),
+			And(Gt("price", 30.5), Lt("price", 40)),
+			Gt("price", 100),
+		))
+		require.True(t, ok)
+		assert.Equal(t, []Range{
+			{Lower: IntValue(10), LowerInclusive: true, Upper: IntValue(40)},
+			{Lower: IntValue(100)},
+		}, list.Ranges)
+	})
+
+	t.Run("exclusive bounds do not touch", func(t *testing.T) {
+		list, ok := ExtractRanges(Or(Lt("price", 10), Gt("price", 10)))
+		require.True(t, ok)
+		assert.Len(t, list.Ranges, 2)
+	})
+
+	t.Run("empty ranges are dropped", func(t *testing.T) {
+		list, ok := ExtractRanges(Or(between("price", 20, 10), between("price", 1, 2)))
+		require.True(t, ok)
+		assert.Len(t, list.Ranges, 1)
+
+		_, ok = ExtractRanges(Or(between("price", 20, 10), And(Gt("price", 5), Lt("price", 5))))
+		assert.False(t, ok)
+	})
+
+	t.Run("ranges covering every value", func(t *testing.T) {
+		_, ok := ExtractRanges(Or(Lt("price", 10), Gte("price", 5)))
+		assert.False(t, ok)
+	})
+
+	t.Run("datetimes", func(t *testing.T) {
+		day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+		list, ok := ExtractRanges(Or(between("created_at", day(10), day(12)), between("created_at", day(1), day(3))))
+		require.True(t, ok)
+		assert.Equal(t, DateTimeValue(day(1)), list.Ranges[0].Lower)
+	})
+
+	t.Run("not range lists", func(t *testing.T) {
+		for name, node := range map[string]Node{
+			"different fields": Or(between("price", 1, 2), between("stock", 1, 2)),
+			"equality":         Or(Eq("price", 1), between("price", 5, 6)),
+			"strings":          Or(Lt("name", "b"), Gt("name", "x")),
+			"mixed kinds":      Or(Lt("price", 1), Gt("price", time.Now())),
+			"two lower bounds": Or(And(Gt("price", 1), Gt("price", 2)), Lt("price", 0)),
+			"and":              between("price", 1, 2),
+		} {
+			list, ok := ExtractRanges(node)
+			if name == "and" {
+				// A single range is a range list of one
+				assert.True(t, ok, name)
+				assert.Len(t, list.Ranges, 1)
+				continue
+			}
+			assert.False(t, ok, name)
+		}
+	})
+}
+
+func TestRangeList_Node(t *testing.T) {
+	list := &RangeList{Field: "price", Ranges: []Range{
+		{Lower: IntValue(10), LowerInclusive: true, Upper: IntValue(20)},
+		{Upper: IntValue(5), UpperInclusive: true},
+	}}
+	assert.Equal(t, Or(
+		And(Gte("price", 10), Lt("price", 20)),
+		Lte("price", 5),
+	), list.Node())
+}
+
+func TestExecutorOptions_PlanRanges(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	status := Eq("status", "active")
+	ranges := Or(between("price", 10, 20), between("price", 50, 60))
+
+	t.Run("closed ranges use union all", func(t *testing.T) {
+		plan := opts.PlanRanges(And(status, ranges))
+		require.NotNil(t, plan)
+		assert.Equal(t, RangeStrategyUnionAll, plan.Strategy)
+		assert.Same(t, status, plan.Rest)
+		assert.Equal(t, And(plan.RangeList.Node(), status), plan.Node())
+	})
+
+	t.Run("open ranges use or", func(t *testing.T) {
+		plan := opts.PlanRanges(Or(Lt("price", 10), Gt("price", 100)))
+		require.NotNil(t, plan)
+		assert.Equal(t, RangeStrategyOr, plan.Strategy)
+		assert.Nil(t, plan.Rest)
+	})
+
+	t.Run("merged to a single range", func(t *testing.T) {
+		plan := opts.PlanRanges(Or(between("price", 10, 20), between("price", 15, 30)))
+		require.NotNil(t, plan)
+		assert.Equal(t, RangeStrategyOr, plan.Strategy)
+	})
+
+	t.Run("configured strategy", func(t *testing.T) {
+		forced := DefaultExecutorOptions()
+		forced.RangeStrategy = RangeStrategyOr
+		assert.Equal(t, RangeStrategyOr, forced.PlanRanges(ranges).Strategy)
+	})
+
+	t.Run("no range list", func(t *testing.T) {
+		assert.Nil(t, opts.PlanRanges(nil))
+		assert.Nil(t, opts.PlanRanges(status))
+		assert.Nil(t, opts.PlanRanges(between("price", 1, 2)))
+	})
+
+	t.Run("value converter", func(t *testing.T) {
+		converted := DefaultExecutorOptions()
+		converted.ValueConverter = func(field string, value interface{}) (interface{}, error) { return value, nil }
+		assert.Nil(t, converted.PlanRanges(ranges))
+	})
+}
+
+func TestRangeStrategy_String(t *testing.T) {
+	assert.Equal(t, "auto", RangeStrategyAuto.String())
+	assert.Equal(t, "or", RangeStrategyOr.String())
+	assert.Equal(t, "union_all", RangeStrategyUnionAll.String())
+}
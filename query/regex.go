@@ -0,0 +1,51 @@
+package query
+
+import (
+	"fmt"
+	"regexp/syntax"
+)
+
+// ValidateRegex checks that a REGEX pattern is safe to hand to a backtracking
+// regex engine such as MongoDB's PCRE or MySQL's REGEXP. The pattern must be
+// valid RE2 syntax, which rules out backreferences and lookarounds, and must
+// not nest unbounded quantifiers, as in (a+)+ or (\d*)*, the classic source
+// of catastrophic backtracking. Failures wrap ErrUnsafeRegex.
+//
+// The check is conservative: a pattern such as ((ab)*c)+ is rejected although
+// its literal separator keeps it linear.
+func ValidateRegex(pattern string) error {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsafeRegex, err)
+	}
+	if nested := nestedRepeat(re, false); nested != nil {
+		return fmt.Errorf("%w: nested quantifier %s", ErrUnsafeRegex, nested)
+	}
+	return nil
+}
+
+// nestedRepeat returns the first unbounded repetition found inside another
+// unbounded repetition, or nil
+func nestedRepeat(re *syntax.Regexp, inRepeat bool) *syntax.Regexp {
+	unbounded := re.Op == syntax.OpStar || re.Op == syntax.OpPlus ||
+		(re.Op == syntax.OpRepeat && re.Max == -1)
+	if unbounded && inRepeat {
+		return re
+	}
+	for _, sub := range re.Sub {
+		if nested := nestedRepeat(sub, inRepeat || unbounded); nested != nil {
+			return nested
+		}
+	}
+	return nil
+}
+
+// RegexPattern returns the pattern an executor should run for a REGEX
+// condition. With AnchorRegex the pattern is wrapped in ^(?:...)$ so it
+// must match the whole value.
+func (o *ExecutorOptions) RegexPattern(pattern string) string {
+	if !o.AnchorRegex {
+		return pattern
+	}
+	return "^(?:" + pattern + ")$"
+}
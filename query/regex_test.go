@@ -0,0 +1,54 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		safe    bool
+	}{
+		{`^[A-Z].*`, true},
+		{`a+b+`, true},
+		{`(ab){2,5}`, true},
+		{`(a+)+$`, false},
+		{`(?:\d*)*x`, false},
+		{`(a|b{2,})*`, false},
+		{`(\w)\1`, false},     // backreference
+		{`foo(?=bar)`, false}, // lookahead
+		{`[`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			err := ValidateRegex(tt.pattern)
+			if tt.safe {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrUnsafeRegex), "got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateFilter_SafeRegex(t *testing.T) {
+	opts := &ExecutorOptions{SafeRegex: true}
+	err := opts.ValidateFilter(And(Eq("a", 1), F("name").Regex(`(x+)+y`).Node()))
+	assert.True(t, errors.Is(err, ErrUnsafeRegex))
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "name", fieldErr.Field)
+
+	assert.NoError(t, opts.ValidateFilter(F("name").Regex(`^x+y$`).Node()))
+	assert.NoError(t, (&ExecutorOptions{}).ValidateFilter(F("name").Regex(`(x+)+y`).Node()))
+}
+
+func TestRegexPattern(t *testing.T) {
+	assert.Equal(t, "ab|cd", (&ExecutorOptions{}).RegexPattern("ab|cd"))
+	assert.Equal(t, "^(?:ab|cd)$", (&ExecutorOptions{AnchorRegex: true}).RegexPattern("ab|cd"))
+}
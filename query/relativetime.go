@@ -0,0 +1,199 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RelativeTimeValue is a time relative to the execution instant, written as an
+// anchor followed by offsets: now, now-7d, today+1h, startOfMonth-1M.
+// Anchors are now, today (or startOfDay), startOfWeek (Monday), startOfMonth
+// and startOfYear, in the location of the clock. Offset units are s, m, h, d,
+// w, M (months) and y; days, weeks, months and years follow the calendar.
+// Executors resolve it to a DateTimeValue with ResolvePlaceholders
+type RelativeTimeValue string
+
+// relativeAnchors maps lowercase anchors to their canonical spelling
+var relativeAnchors = map[string]string{
+	"now":          "now",
+	"today":        "today",
+	"startofday":   "startOfDay",
+	"startofweek":  "startOfWeek",
+	"startofmonth": "startOfMonth",
+	"startofyear":  "startOfYear",
+}
+
+// relativeOffset is one signed offset of a relative time
+type relativeOffset struct {
+	amount int
+	unit   byte
+}
+
+// ParseRelativeTime parses a relative time such as now-7d. Anchors are
+// case-insensitive and returned in their canonical spelling
+func ParseRelativeTime(s string) (RelativeTimeValue, bool) {
+	anchor, offsets, ok := parseRelativeTime(s)
+	if !ok {
+		return "", false
+	}
+	var sb strings.Builder
+	sb.WriteString(anchor)
+	for _, o := range offsets {
+		if o.amount >= 0 {
+			sb.WriteString("+")
+		}
+		sb.WriteString(strconv.Itoa(o.amount))
+		sb.WriteByte(o.unit)
+	}
+	return RelativeTimeValue(sb.String()), true
+}
+
+// parseRelativeTime splits a relative time into its canonical anchor and offsets
+func parseRelativeTime(s string) (string, []relativeOffset, bool) {
+	end := strings.IndexAny(s, "+-")
+	if end < 0 {
+		end = len(s)
+	}
+	anchor, ok := relativeAnchors[strings.ToLower(s[:end])]
+	if !ok {
+		return "", nil, false
+	}
+
+	var offsets []relativeOffset
+	for rest := s[end:]; rest != ""; {
+		sign := 1
+		if rest[0] == '-' {
+			sign = -1
+		}
+		digits := 1
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits == 1 || digits == len(rest) || !strings.ContainsRune("smhdwMy", rune(rest[digits])) {
+			return "", nil, false
+		}
+		amount, err := strconv.Atoi(rest[1:digits])
+		if err != nil {
+			return "", nil, false
+		}
+		offsets = append(offsets, relativeOffset{amount: sign * amount, unit: rest[digits]})
+		rest = rest[digits+1:]
+	}
+	return anchor, offsets, true
+}
+
+// Resolve returns the time v stands for at the instant now
+func (v RelativeTimeValue) Resolve(now time.Time) (time.Time, error) {
+	anchor, offsets, ok := parseRelativeTime(string(v))
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: invalid relative time %q", ErrInvalidQuery, string(v))
+	}
+
+	t := now
+	year, month, day := now.Date()
+	switch anchor {
+	case "today", "startOfDay":
+		t = time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	case "startOfWeek":
+		// Weeks start on Monday
+		t = time.Date(year, month, day-(int(now.Weekday())+6)%7, 0, 0, 0, 0, now.Location())
+	case "startOfMonth":
+		t = time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+	case "startOfYear":
+		t = time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+	}
+
+	for _, o := range offsets {
+		switch o.unit {
+		case 's':
+			t = t.Add(time.Duration(o.amount) * time.Second)
+		case 'm':
+			t = t.Add(time.Duration(o.amount) * time.Minute)
+		case 'h':
+			t = t.Add(time.Duration(o.amount) * time.Hour)
+		case 'd':
+			t = t.AddDate(0, 0, o.amount)
+		case 'w':
+			t = t.AddDate(0, 0, 7*o.amount)
+		case 'M':
+			t = t.AddDate(0, o.amount, 0)
+		case 'y':
+			t = t.AddDate(o.amount, 0, 0)
+		}
+	}
+	return t, nil
+}
+
+// resolveRelativeTimes returns a copy of node with relative times resolved at now
+func resolveRelativeTimes(node Node, now time.Time) (Node, error) {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		left, err := resolveRelativeTimes(n.Left, now)
+		if err != nil {
+			return nil, err
+		}
+		right, err := resolveRelativeTimes(n.Right, now)
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryOpNode{Operator: n.Operator, Left: left, Right: right}, nil
+	case *ComparisonNode:
+		value, err := resolveRelativeValue(n.Value, now)
+		if err != nil {
+			return nil, NewFieldError(n.Field, err)
+		}
+		resolved := *n
+		resolved.Value = value
+		return &resolved, nil
+	default:
+		return node, nil
+	}
+}
+
+// resolveRelativeValue resolves a relative time value, including inside lists
+func resolveRelativeValue(v interface{}, now time.Time) (interface{}, error) {
+	switch val := v.(type) {
+	case RelativeTimeValue:
+		t, err := val.Resolve(now)
+		if err != nil {
+			return nil, err
+		}
+		return DateTimeValue(t), nil
+	case ArrayValue:
+		resolved := make(ArrayValue, len(val))
+		for i, elem := range val {
+			value, err := resolveRelativeValue(elem, now)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = value
+		}
+		return resolved, nil
+	default:
+		return v, nil
+	}
+}
+
+// relativeTimeIn returns the first relative time in a filter
+func relativeTimeIn(node Node) (RelativeTimeValue, bool) {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		if v, ok := relativeTimeIn(n.Left); ok {
+			return v, true
+		}
+		return relativeTimeIn(n.Right)
+	case *ComparisonNode:
+		values := []interface{}{n.Value}
+		if arr, ok := n.Value.(ArrayValue); ok {
+			values = arr
+		}
+		for _, value := range values {
+			if v, ok := value.(RelativeTimeValue); ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,110 @@
+package query
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRelativeTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected RelativeTimeValue
+		ok       bool
+	}{
+		{"now", "now", true},
+		{"NOW-7d", "now-7d", true},
+		{"now+1h-30m", "now+1h-30m", true},
+		{"startofmonth-1M", "startOfMonth-1M", true},
+		{"today", "today", true},
+		{"startOfWeek+2d", "startOfWeek+2d", true},
+		{"now-", "", false},
+		{"now-7", "", false},
+		{"now-7x", "", false},
+		{"now-d", "", false},
+		{"today-special", "", false},
+		{"nowhere", "", false},
+		{"yesterday", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, ok := ParseRelativeTime(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestRelativeTimeValue_Resolve(t *testing.T) {
+	// A Thursday
+	now := time.Date(2024, 3, 14, 15, 30, 45, 0, time.UTC)
+	tests := []struct {
+		value    RelativeTimeValue
+		expected time.Time
+	}{
+		{"now", now},
+		{"now-7d", time.Date(2024, 3, 7, 15, 30, 45, 0, time.UTC)},
+		{"now+1h-30m", time.Date(2024, 3, 14, 16, 0, 45, 0, time.UTC)},
+		{"now-10s", time.Date(2024, 3, 14, 15, 30, 35, 0, time.UTC)},
+		{"today", time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"startOfDay+9h", time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC)},
+		{"startOfWeek", time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"startOfWeek-1w", time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"startOfMonth", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"startOfMonth-1M", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"startOfYear+1y", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.value), func(t *testing.T) {
+			got, err := tt.value.Resolve(now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	// Sundays belong to the week starting the Monday before
+	sunday := time.Date(2024, 3, 17, 8, 0, 0, 0, time.UTC)
+	got, err := RelativeTimeValue("startOfWeek").Resolve(sunday)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), got)
+
+	_, err = RelativeTimeValue("later").Resolve(now)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestResolvePlaceholders_RelativeTimes(t *testing.T) {
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	reads := 0
+	opts := DefaultExecutorOptions()
+	opts.Clock = func() time.Time {
+		reads++
+		return now
+	}
+	opts.BaseFilter = Eq("tenant_id", 7)
+
+	q := &Query{Filter: And(
+		Gt("created_at", RelativeTimeValue("now-7d")),
+		In("due", RelativeTimeValue("today"), RelativeTimeValue("today+1d")),
+	)}
+	resolved, err := opts.ResolvePlaceholders(context.Background(), q)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reads, "the clock is read once per execution")
+	assert.Equal(t, And(
+		Gt("created_at", DateTimeValue(now.AddDate(0, 0, -7))),
+		In("due", DateTimeValue(time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)), DateTimeValue(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC))),
+	), resolved.Filter)
+	require.NoError(t, opts.ValidateFilter(resolved.Filter))
+
+	// The stable filter keeps the relative times, also once scoped
+	assert.Equal(t, q.Filter, resolved.StableFilter())
+	assert.Equal(t, And(q.Filter, opts.BaseFilter), opts.ScopedQuery(resolved).StableFilter())
+	assert.Equal(t, q.Filter, q.StableFilter())
+
+	// Unresolved relative times fail validation
+	err = opts.ValidateFilter(q.Filter)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	assert.Contains(t, err.Error(), "now-7d is not resolved")
+}
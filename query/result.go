@@ -1,5 +1,7 @@
 package query
 
+import "time"
+
 // Result represents the standardized result of a query execution
 // Note: Actual data is stored in the destination variable passed to Execute
 type Result struct {
@@ -9,9 +11,15 @@ type Result struct {
 	// PrevPageCursor is the cursor for the previous page (empty if no previous page)
 	PrevPageCursor string `json:"prev_page_cursor"`
 
-	// TotalItems is the total number of items matching the query
+	// TotalItems is the total number of items matching the query, or
+	// TotalUnknown when ExecutorOptions.CountMode is CountNone
 	TotalItems int64 `json:"total_items"`
 
+	// TotalItemsEstimated is true when TotalItems is an estimate rather than an
+	// exact count, e.g. with CountEstimated or CountEstimateThreshold on large
+	// MongoDB collections
+	TotalItemsEstimated bool `json:"total_items_estimated,omitempty"`
+
 	// ShowingFrom is the starting index (1-based) of items in current page
 	ShowingFrom int `json:"showing_from"`
 
@@ -21,6 +29,29 @@ type Result struct {
 	// ItemsReturned is the number of items returned in this page
 	ItemsReturned int `json:"items_returned"`
 
+	// Scores holds the relevance score of each returned item, in the same order as
+	// the destination slice. Only populated when sorting by ScoreField ("_score").
+	Scores []float64 `json:"scores,omitempty"`
+
+	// Highlights holds the fragments of each returned item that matched the
+	// filter, in the same order as the destination slice. Only populated when
+	// Query.HighlightRequested is set and the executor supports it
+	Highlights [][]Highlight `json:"highlights,omitempty"`
+
+	// Metadata carries values added while the query ran, such as the cache
+	// status set by the cache decorator or notes about degraded results
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// ExecutionTime is how long Execute took
+	ExecutionTime time.Duration `json:"execution_time,omitempty"`
+
+	// Explain describes how the query ran, when Query.ExplainRequested is set
+	Explain *Explain `json:"explain,omitempty"`
+
+	// Warnings describe parts of the query that were ignored, such as search
+	// terms dropped by StopWords; see SearchTermWarnings
+	Warnings []Warning `json:"warnings,omitempty"`
+
 	// Error contains any error that occurred during execution
 	Error error `json:"error,omitempty"`
 }
@@ -0,0 +1,242 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldKind represents the value type stored in a field
+type FieldKind int
+
+const (
+	// FieldKindString is a text field
+	FieldKindString FieldKind = iota
+	// FieldKindInt is an integer field
+	FieldKindInt
+	// FieldKindFloat is a floating point field
+	FieldKindFloat
+	// FieldKindBool is a boolean field
+	FieldKindBool
+	// FieldKindDateTime is a date/time field
+	FieldKindDateTime
+	// FieldKindArray is an array/list field
+	FieldKindArray
+)
+
+// String returns the string representation of FieldKind
+func (k FieldKind) String() string {
+	switch k {
+	case FieldKindString:
+		return "string"
+	case FieldKindInt:
+		return "int"
+	case FieldKindFloat:
+		return "float"
+	case FieldKindBool:
+		return "bool"
+	case FieldKindDateTime:
+		return "datetime"
+	case FieldKindArray:
+		return "array"
+	default:
+		return "string" // Default to string
+	}
+}
+
+// ParseFieldKind parses a string into a FieldKind enum value
+// Returns FieldKindString as default for empty or invalid values
+func ParseFieldKind(s string) FieldKind {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "int", "integer":
+		return FieldKindInt
+	case "float", "number":
+		return FieldKindFloat
+	case "bool", "boolean":
+		return FieldKindBool
+	case "datetime", "date", "time":
+		return FieldKindDateTime
+	case "array", "list":
+		return FieldKindArray
+	default:
+		return FieldKindString // Default to string
+	}
+}
+
+// Schema maps field names to their value kinds
+// Fields that are not listed in the schema are not type-checked
+//
+// Example:
+//
+//	schema := query.Schema{
+//	    "name":       query.FieldKindString,
+//	    "price":      query.FieldKindFloat,
+//	    "featured":   query.FieldKindBool,
+//	    "created_at": query.FieldKindDateTime,
+//	    "tags":       query.FieldKindArray,
+//	}
+type Schema map[string]FieldKind
+
+// FieldNames returns the schema's field names, sorted.
+// Pass it to ValidateSortField to reject sorting on fields outside the schema.
+func (s Schema) FieldNames() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateAgainstSchema checks that every comparison in the query uses an operator
+// and value type that make sense for the field's declared kind.
+// This catches mistakes such as `price CONTAINS "x"` or `featured > 5` before the
+// query reaches the database, instead of surfacing backend-specific errors.
+//
+// Bare search terms (default search field) and fields missing from the schema are skipped.
+// Returned errors are FieldErrors wrapping ErrTypeMismatch.
+func ValidateAgainstSchema(q *Query, schema Schema) error {
+	if q == nil || q.Filter == nil || len(schema) == 0 {
+		return nil
+	}
+	return validateNodeAgainstSchema(q.Filter, schema)
+}
+
+// validateNodeAgainstSchema recursively validates a filter node against the schema
+func validateNodeAgainstSchema(node Node, schema Schema) error {
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		if err := validateNodeAgainstSchema(n.Left, schema); err != nil {
+			return err
+		}
+		return validateNodeAgainstSchema(n.Right, schema)
+	case *ComparisonNode:
+		if n.Field == SearchField {
+			return nil
+		}
+		kind, ok := schema[n.Field]
+		if !ok {
+			return nil
+		}
+		return validateComparisonKind(n, kind)
+	default:
+		return ErrInvalidQuery
+	}
+}
+
+// validateComparisonKind validates a single comparison against the field kind
+func validateComparisonKind(n *ComparisonNode, kind FieldKind) error {
+	if n.Modifier != ArrayModifierNone {
+		return validateArrayComparisonKind(n, kind)
+	}
+	if !isOperatorAllowedForKind(n.Operator, kind) {
+		return TypeMismatchError(n.Field, fmt.Sprintf("operator %s not supported for %s field", n.Operator, kind))
+	}
+
+	// Placeholder and parameter values are checked when they are resolved
+	switch n.Value.(type) {
+	case PlaceholderValue, ParameterValue:
+		return nil
+	}
+
+	// IN/NOT IN carry an array of values; each element must match the field kind
+	if n.Operator == OpIn || n.Operator == OpNotIn {
+		arr, ok := n.Value.(ArrayValue)
+		if !ok {
+			return TypeMismatchError(n.Field, fmt.Sprintf("operator %s requires an array value", n.Operator))
+		}
+		for _, elem := range arr {
+			if !isValueCompatibleWithKind(elem, kind) {
+				return TypeMismatchError(n.Field, fmt.Sprintf("value %v is not compatible with %s field", elem, kind))
+			}
+		}
+		return nil
+	}
+
+	if !isValueCompatibleWithKind(n.Value, kind) {
+		return TypeMismatchError(n.Field, fmt.Sprintf("value %v is not compatible with %s field", n.Value, kind))
+	}
+	return nil
+}
+
+// validateArrayComparisonKind validates a LENGTH, ANY or ALL comparison, which
+// needs an array field. Elements are not typed, so they are checked like text
+func validateArrayComparisonKind(n *ComparisonNode, kind FieldKind) error {
+	if kind != FieldKindArray {
+		return TypeMismatchError(n.Field, fmt.Sprintf("%s requires an array field, not %s", n.Modifier, kind))
+	}
+	if n.Modifier == ArrayModifierLength {
+		if !isValueCompatibleWithKind(n.Value, FieldKindInt) {
+			return TypeMismatchError(n.Field, fmt.Sprintf("value %v is not compatible with LENGTH", n.Value))
+		}
+		return nil
+	}
+	return validateComparisonKind(&ComparisonNode{Field: n.Field, Operator: n.Operator, Value: n.Value}, FieldKindString)
+}
+
+// AllowsOperator reports whether the operator can be applied to a field of this kind
+func (k FieldKind) AllowsOperator(op ComparisonOperator) bool {
+	return isOperatorAllowedForKind(op, k)
+}
+
+// isOperatorAllowedForKind reports whether an operator can be applied to a field of the given kind
+func isOperatorAllowedForKind(op ComparisonOperator, kind FieldKind) bool {
+	switch kind {
+	case FieldKindString:
+		return true
+	case FieldKindInt, FieldKindFloat, FieldKindDateTime:
+		switch op {
+		case OpEqual, OpNotEqual, OpGreaterThan, OpGreaterThanOrEqual,
+			OpLessThan, OpLessThanOrEqual, OpIn, OpNotIn:
+			return true
+		}
+		return false
+	case FieldKindBool:
+		switch op {
+		case OpEqual, OpNotEqual, OpIn, OpNotIn:
+			return true
+		}
+		return false
+	case FieldKindArray:
+		switch op {
+		case OpEqual, OpNotEqual, OpContains, OpIn, OpNotIn:
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// isValueCompatibleWithKind reports whether a literal value can be compared with a field of the given kind
+func isValueCompatibleWithKind(value interface{}, kind FieldKind) bool {
+	switch value.(type) {
+	case PlaceholderValue, ParameterValue:
+		return true
+	}
+	switch kind {
+	case FieldKindString, FieldKindArray:
+		// Any scalar literal can be compared with text or array elements
+		// (unquoted numbers such as zip codes are parsed as IntValue)
+		_, isArray := value.(ArrayValue)
+		return !isArray
+	case FieldKindInt, FieldKindFloat:
+		switch value.(type) {
+		case IntValue, FloatValue:
+			return true
+		}
+		return false
+	case FieldKindBool:
+		_, ok := value.(BoolValue)
+		return ok
+	case FieldKindDateTime:
+		switch value.(type) {
+		case DateTimeValue, RelativeTimeValue:
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSchema() Schema {
+	return Schema{
+		"name":       FieldKindString,
+		"price":      FieldKindFloat,
+		"stock":      FieldKindInt,
+		"featured":   FieldKindBool,
+		"created_at": FieldKindDateTime,
+		"tags":       FieldKindArray,
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Node
+		wantErr bool
+	}{
+		{"string contains", &ComparisonNode{Field: "name", Operator: OpContains, Value: StringValue("mouse")}, false},
+		{"string equals number", &ComparisonNode{Field: "name", Operator: OpEqual, Value: IntValue(90210)}, false},
+		{"float greater than int", &ComparisonNode{Field: "price", Operator: OpGreaterThan, Value: IntValue(10)}, false},
+		{"float contains rejected", &ComparisonNode{Field: "price", Operator: OpContains, Value: StringValue("x")}, true},
+		{"int equals string rejected", &ComparisonNode{Field: "stock", Operator: OpEqual, Value: StringValue("many")}, true},
+		{"bool equals", &ComparisonNode{Field: "featured", Operator: OpEqual, Value: BoolValue(true)}, false},
+		{"bool greater than rejected", &ComparisonNode{Field: "featured", Operator: OpGreaterThan, Value: IntValue(5)}, true},
+		{"datetime range", &ComparisonNode{Field: "created_at", Operator: OpGreaterThanOrEqual, Value: DateTimeValue(time.Now())}, false},
+		{"datetime like rejected", &ComparisonNode{Field: "created_at", Operator: OpLike, Value: StringValue("2024%")}, true},
+		{"array contains", &ComparisonNode{Field: "tags", Operator: OpContains, Value: StringValue("sale")}, false},
+		{"array greater than rejected", &ComparisonNode{Field: "tags", Operator: OpGreaterThan, Value: IntValue(1)}, true},
+		{"array length", &ComparisonNode{Field: "tags", Operator: OpGreaterThan, Value: IntValue(3), Modifier: ArrayModifierLength}, false},
+		{"array length string rejected", &ComparisonNode{Field: "tags", Operator: OpEqual, Value: StringValue("3"), Modifier: ArrayModifierLength}, true},
+		{"array any like", &ComparisonNode{Field: "tags", Operator: OpLike, Value: StringValue("usb%"), Modifier: ArrayModifierAny}, false},
+		{"length of non-array rejected", &ComparisonNode{Field: "name", Operator: OpGreaterThan, Value: IntValue(3), Modifier: ArrayModifierLength}, true},
+		{"int in ints", &ComparisonNode{Field: "stock", Operator: OpIn, Value: ArrayValue{IntValue(1), IntValue(2)}}, false},
+		{"int in mixed rejected", &ComparisonNode{Field: "stock", Operator: OpIn, Value: ArrayValue{IntValue(1), StringValue("x")}}, true},
+		{"unknown field skipped", &ComparisonNode{Field: "other", Operator: OpGreaterThan, Value: StringValue("x")}, false},
+		{"default search skipped", &ComparisonNode{Field: "__DEFAULT_SEARCH__", Operator: OpContains, Value: StringValue("x")}, false},
+		{"nested error found", &BinaryOpNode{
+			Operator: BinaryOpOr,
+			Left:     &ComparisonNode{Field: "name", Operator: OpEqual, Value: StringValue("a")},
+			Right:    &ComparisonNode{Field: "featured", Operator: OpLike, Value: StringValue("t%")},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAgainstSchema(&Query{Filter: tt.filter}, testSchema())
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.True(t, errors.Is(err, ErrTypeMismatch))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAgainstSchema_FieldError(t *testing.T) {
+	q := &Query{Filter: &ComparisonNode{Field: "price", Operator: OpContains, Value: StringValue("x")}}
+	err := ValidateAgainstSchema(q, testSchema())
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "price", fieldErr.Field)
+	assert.Contains(t, err.Error(), "CONTAINS")
+}
+
+func TestValidateAgainstSchema_EmptyInputs(t *testing.T) {
+	assert.NoError(t, ValidateAgainstSchema(nil, testSchema()))
+	assert.NoError(t, ValidateAgainstSchema(&Query{}, testSchema()))
+	assert.NoError(t, ValidateAgainstSchema(&Query{
+		Filter: &ComparisonNode{Field: "price", Operator: OpContains, Value: StringValue("x")},
+	}, nil))
+}
+
+func TestParseFieldKind(t *testing.T) {
+	assert.Equal(t, FieldKindInt, ParseFieldKind("int"))
+	assert.Equal(t, FieldKindFloat, ParseFieldKind("Float"))
+	assert.Equal(t, FieldKindBool, ParseFieldKind("boolean"))
+	assert.Equal(t, FieldKindDateTime, ParseFieldKind("datetime"))
+	assert.Equal(t, FieldKindArray, ParseFieldKind("array"))
+	assert.Equal(t, FieldKindString, ParseFieldKind("unknown"))
+	assert.Equal(t, "datetime", FieldKindDateTime.String())
+}
+
+func TestSchema_FieldNames(t *testing.T) {
+	names := testSchema().FieldNames()
+	assert.Equal(t, []string{"created_at", "featured", "name", "price", "stock", "tags"}, names)
+	assert.ErrorIs(t, ValidateSortField("prise", names), ErrInvalidSortField)
+}
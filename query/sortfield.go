@@ -0,0 +1,86 @@
+package query
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions limits the number of suggestions in a SortFieldError
+const maxSuggestions = 3
+
+// ValidateSortField checks that field is one of the known fields.
+// Empty fields, ScoreField, MatchCountField and an empty known list are always accepted.
+// Unknown fields return a *SortFieldError wrapping ErrInvalidSortField,
+// with the closest known fields as suggestions.
+func ValidateSortField(field string, known []string) error {
+	if field == "" || field == ScoreField || field == MatchCountField || len(known) == 0 {
+		return nil
+	}
+	for _, k := range known {
+		if k == field {
+			return nil
+		}
+	}
+	return &SortFieldError{Field: field, Suggestions: SuggestFields(field, known)}
+}
+
+// SuggestFields returns up to three known fields similar to field, closest first.
+// Similarity is the case-insensitive edit distance; fields sharing a prefix
+// with field (e.g. "created" and "created_at") are also suggested.
+func SuggestFields(field string, known []string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	target := strings.ToLower(field)
+	maxDistance := len(target) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var candidates []candidate
+	for _, k := range known {
+		name := strings.ToLower(k)
+		distance := editDistance(target, name)
+		if distance > maxDistance && !strings.HasPrefix(name, target) && !strings.HasPrefix(target, name) {
+			continue
+		}
+		candidates = append(candidates, candidate{name: k, distance: distance})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
@@ -0,0 +1,66 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSortField(t *testing.T) {
+	known := []string{"name", "price", "created_at", "updated_at", "stock"}
+
+	assert.NoError(t, ValidateSortField("price", known))
+	assert.NoError(t, ValidateSortField("", known))
+	assert.NoError(t, ValidateSortField(ScoreField, known))
+	assert.NoError(t, ValidateSortField("anything", nil))
+
+	err := ValidateSortField("pric", known)
+	assert.True(t, errors.Is(err, ErrInvalidSortField))
+
+	var sortErr *SortFieldError
+	assert.True(t, errors.As(err, &sortErr))
+	assert.Equal(t, "pric", sortErr.Field)
+	assert.Equal(t, []string{"price"}, sortErr.Suggestions)
+	assert.Equal(t, "invalid sort field 'pric' (did you mean: price?)", err.Error())
+
+	err = ValidateSortField("password", known)
+	assert.Equal(t, "invalid sort field 'password'", err.Error())
+}
+
+func TestSuggestFields(t *testing.T) {
+	known := []string{"name", "price", "created_at", "updated_at", "stock"}
+
+	tests := []struct {
+		field    string
+		expected []string
+	}{
+		{"Price", []string{"price"}},
+		{"stok", []string{"stock"}},
+		{"created", []string{"created_at"}},
+		{"updated_at_", []string{"updated_at"}},
+		{"xyz", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SuggestFields(tt.field, known))
+		})
+	}
+}
+
+func TestExecutorOptions_ValidateSortField(t *testing.T) {
+	opts := DefaultExecutorOptions()
+	assert.NoError(t, opts.ValidateSortField("anything"), "no restriction by default")
+
+	opts.AllowedFields = []string{"name", "price"}
+	assert.NoError(t, opts.ValidateSortField("price"))
+	assert.NoError(t, opts.ValidateSortField(opts.DefaultSortField))
+	assert.ErrorIs(t, opts.ValidateSortField("email"), ErrInvalidSortField)
+
+	opts.SortableFields = []string{"price"}
+	assert.ErrorIs(t, opts.ValidateSortField("name"), ErrInvalidSortField, "SortableFields takes precedence")
+
+	opts.IDFieldName = "id"
+	assert.NoError(t, opts.ValidateSortField("id"))
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Warning describes something about a query that did not stop it from
+// running, such as search terms that were ignored. Executors return warnings
+// in Result.Warnings
+type Warning struct {
+	// Code identifies the kind of warning, e.g. WarningIgnoredTerms
+	Code string `json:"code"`
+
+	// Message describes the warning
+	Message string `json:"message"`
+
+	// Terms lists the search terms a WarningIgnoredTerms warning is about
+	Terms []string `json:"terms,omitempty"`
+}
+
+// WarningIgnoredTerms is the code of the warning listing the bare search terms
+// dropped as stop words or for being shorter than MinSearchTermLength
+const WarningIgnoredTerms = "ignored_terms"
+
+// IsIgnoredTerm reports whether the bare search term is dropped from filters:
+// it is one of StopWords, ignoring case, or has fewer than MinSearchTermLength
+// characters
+func (o *ExecutorOptions) IsIgnoredTerm(term string) bool {
+	if o.MinSearchTermLength > 0 && utf8.RuneCountInString(term) < o.MinSearchTermLength {
+		return true
+	}
+	for _, word := range o.StopWords {
+		if strings.EqualFold(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// DropIgnoredTerms returns node without its ignored bare search terms (see
+// IsIgnoredTerm) and the terms it dropped. Quoted phrases are kept. node is
+// not modified; the result is nil when every condition was dropped
+func (o *ExecutorOptions) DropIgnoredTerms(node Node) (Node, []string) {
+	if len(o.StopWords) == 0 && o.MinSearchTermLength <= 0 {
+		return node, nil
+	}
+	var dropped []string
+	kept := Rewrite(node, func(node Node) Node {
+		n, ok := node.(*ComparisonNode)
+		if !ok || n.Field != SearchField || n.Phrase {
+			return node
+		}
+		if term, ok := n.Value.(StringValue); ok && o.IsIgnoredTerm(string(term)) {
+			dropped = append(dropped, string(term))
+			return nil
+		}
+		return node
+	})
+	return kept, dropped
+}
+
+// SearchTermWarnings returns the warnings executors add to the result of q:
+// a WarningIgnoredTerms warning when bare search terms were dropped
+func (o *ExecutorOptions) SearchTermWarnings(q *Query) []Warning {
+	if q == nil {
+		return nil
+	}
+	_, dropped := o.DropIgnoredTerms(q.Filter)
+	if len(dropped) == 0 {
+		return nil
+	}
+	return []Warning{{
+		Code:    WarningIgnoredTerms,
+		Message: fmt.Sprintf("ignored search terms: %s", strings.Join(dropped, ", ")),
+		Terms:   dropped,
+	}}
+}
@@ -0,0 +1,64 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorOptions_DropIgnoredTerms(t *testing.T) {
+	opts := &ExecutorOptions{StopWords: []string{"a", "the", "of"}, MinSearchTermLength: 2}
+	phrase := &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue("the"), Phrase: true}
+
+	tests := []struct {
+		name    string
+		filter  Node
+		want    string
+		dropped []string
+	}{
+		{"stop words", And(Search("The"), Search("usb"), Search("of")), `usb`, []string{"The", "of"}},
+		{"short terms", And(Search("x"), Search("cable")), `cable`, []string{"x"}},
+		{"fields kept", And(Search("a"), Eq("name", "a")), `name = "a"`, []string{"a"}},
+		{"phrases kept", phrase, `"the"`, nil},
+		{"all dropped", Or(Search("a"), Search("the")), ``, []string{"a", "the"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, dropped := opts.DropIgnoredTerms(tt.filter)
+			assert.Equal(t, tt.want, FormatFilter(kept))
+			assert.Equal(t, tt.dropped, dropped)
+		})
+	}
+
+	filter := Search("a")
+	kept, dropped := (&ExecutorOptions{}).DropIgnoredTerms(filter)
+	assert.Same(t, filter, kept)
+	assert.Empty(t, dropped)
+}
+
+func TestExecutorOptions_SearchTermWarnings(t *testing.T) {
+	opts := &ExecutorOptions{StopWords: []string{"the"}}
+
+	warnings := opts.SearchTermWarnings(&Query{Filter: And(Search("the"), Search("usb"))})
+	require.Len(t, warnings, 1)
+	assert.Equal(t, WarningIgnoredTerms, warnings[0].Code)
+	assert.Equal(t, []string{"the"}, warnings[0].Terms)
+	assert.Equal(t, "ignored search terms: the", warnings[0].Message)
+
+	assert.Empty(t, opts.SearchTermWarnings(&Query{Filter: Search("usb")}))
+	assert.Empty(t, opts.SearchTermWarnings(nil))
+
+	assert.Equal(t, `usb`, FormatFilter(opts.ScopedQuery(&Query{Filter: And(Search("the"), Search("usb"))}).Filter))
+}
+
+func TestExecutorOptions_ValidateFilter_IgnoredTerms(t *testing.T) {
+	opts := &ExecutorOptions{StopWords: []string{"a", "the"}}
+
+	err := opts.ValidateFilter(And(Search("a"), Search("the")))
+	assert.ErrorIs(t, err, ErrSearchTermsIgnored)
+	assert.EqualError(t, err, "all search terms ignored: a, the")
+
+	assert.NoError(t, opts.ValidateFilter(And(Search("a"), Search("usb"))))
+	assert.NoError(t, opts.ValidateFilter(nil))
+}
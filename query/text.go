@@ -0,0 +1,85 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SearchTerms splits text into lowercase search terms on any character that is
+// not a letter, digit, combining mark or zero-width joiner. It is used by executors that implement MATCH without a
+// native full-text engine.
+//
+// Example:
+//
+//	SearchTerms("Noise-cancelling headphones!") // ["noise", "cancelling", "headphones"]
+func SearchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isTermSeparator)
+}
+
+// isTermSeparator reports whether r separates the terms of SearchTerms
+func isTermSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) && r != '\u200c' && r != '\u200d'
+}
+
+// ExpandDefaultSearch rewrites a bare search comparison (field "__DEFAULT_SEARCH__")
+// into an OR of the same comparison across each of the given fields.
+// Other nodes are returned unchanged, as is a bare search when no fields are given.
+//
+// Example:
+//
+//	// "wireless" with fields [name, description]
+//	// => name CONTAINS "wireless" OR description CONTAINS "wireless"
+//	node := ExpandDefaultSearch(n, []string{"name", "description"})
+func ExpandDefaultSearch(n *ComparisonNode, fields []string) Node {
+	if n.Field != SearchField || len(fields) == 0 {
+		return n
+	}
+
+	var expanded Node
+	for _, field := range fields {
+		comparison := &ComparisonNode{Field: field, Operator: n.Operator, Value: n.Value, Phrase: n.Phrase}
+		if expanded == nil {
+			expanded = comparison
+			continue
+		}
+		expanded = &BinaryOpNode{Operator: BinaryOpOr, Left: expanded, Right: comparison}
+	}
+	return expanded
+}
+
+// MatchClauses returns the conditions counted by sort_by = _matches: every
+// CONTAINS and ICONTAINS comparison in the filter, at any depth. A bare search
+// term is expanded across SearchFields into one clause, so it counts once however
+// many of its fields match. Returns an error wrapping ErrInvalidQuery when the
+// filter has no such conditions.
+//
+// Example:
+//
+//	// name CONTAINS "usb" OR name CONTAINS "hub" OR description ICONTAINS "usb-c"
+//	// => 3 clauses; an item matching "usb" and "usb-c" sorts before one matching only "hub"
+func (o *ExecutorOptions) MatchClauses(filter Node) ([]Node, error) {
+	var clauses []Node
+	var collect func(node Node)
+	collect = func(node Node) {
+		switch n := node.(type) {
+		case *BinaryOpNode:
+			collect(n.Left)
+			collect(n.Right)
+		case *ComparisonNode:
+			if n.Operator != OpContains && n.Operator != OpIContains || n.Modifier != ArrayModifierNone {
+				return
+			}
+			if n.Field != SearchField {
+				clauses = append(clauses, n)
+			} else if fields := o.SearchFields(); len(fields) > 0 {
+				clauses = append(clauses, ExpandDefaultSearch(n, fields))
+			}
+		}
+	}
+	collect(filter)
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("%w: sorting by %s requires a CONTAINS or ICONTAINS condition", ErrInvalidQuery, MatchCountField)
+	}
+	return clauses, nil
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTerms(t *testing.T) {
+	assert.Equal(t, []string{"noise", "cancelling", "headphones"}, SearchTerms("Noise-cancelling headphones!"))
+	assert.Equal(t, []string{"usb", "c", "3", "0"}, SearchTerms("USB-C 3.0"))
+	assert.Equal(t, []string{"café", "über"}, SearchTerms("Café, Über"))
+	assert.Equal(t, []string{"می\u200cخواهم", "हिन्दी"}, SearchTerms("می\u200cخواهم, हिन्दी"))
+	assert.Empty(t, SearchTerms("  --  "))
+}
+
+func TestExpandDefaultSearch(t *testing.T) {
+	bare := &ComparisonNode{Field: "__DEFAULT_SEARCH__", Operator: OpContains, Value: StringValue("wireless")}
+
+	expanded := ExpandDefaultSearch(bare, []string{"name", "description", "tags"})
+	assert.Equal(t, &BinaryOpNode{
+		Operator: BinaryOpOr,
+		Left: &BinaryOpNode{
+			Operator: BinaryOpOr,
+			Left:     &ComparisonNode{Field: "name", Operator: OpContains, Value: StringValue("wireless")},
+			Right:    &ComparisonNode{Field: "description", Operator: OpContains, Value: StringValue("wireless")},
+		},
+		Right: &ComparisonNode{Field: "tags", Operator: OpContains, Value: StringValue("wireless")},
+	}, expanded)
+
+	assert.Equal(t, &ComparisonNode{Field: "name", Operator: OpContains, Value: StringValue("wireless")},
+		ExpandDefaultSearch(bare, []string{"name"}))
+	assert.Same(t, bare, ExpandDefaultSearch(bare, nil))
+
+	field := &ComparisonNode{Field: "name", Operator: OpEqual, Value: StringValue("x")}
+	assert.Same(t, field, ExpandDefaultSearch(field, []string{"description"}))
+}
+
+func TestExecutorOptions_MatchClauses(t *testing.T) {
+	usb := F("name").Contains("usb")
+	hub := F("description").IContains("hub")
+	filter := And(Or(usb.Node(), hub.Node()), F("price").Lt(50).Node())
+
+	opts := &ExecutorOptions{}
+	clauses, err := opts.MatchClauses(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []Node{usb.Node(), hub.Node()}, clauses)
+
+	t.Run("bare terms count once across search fields", func(t *testing.T) {
+		bare := &ComparisonNode{Field: SearchField, Operator: OpContains, Value: StringValue("usb")}
+		opts := &ExecutorOptions{DefaultSearchFields: []string{"name", "description"}}
+		clauses, err := opts.MatchClauses(Or(bare, hub.Node()))
+		assert.NoError(t, err)
+		assert.Equal(t, []Node{ExpandDefaultSearch(bare, opts.DefaultSearchFields), hub.Node()}, clauses)
+
+		// Without search fields a bare term cannot be counted
+		_, err = (&ExecutorOptions{}).MatchClauses(bare)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	})
+
+	t.Run("no contains conditions", func(t *testing.T) {
+		_, err := opts.MatchClauses(F("price").Lt(50).Node())
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+		_, err = opts.MatchClauses(nil)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	})
+}
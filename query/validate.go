@@ -0,0 +1,120 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateFilter checks a user filter against FieldPolicy, SafeRegex and the complexity
+// limits (MaxFilterDepth, MaxConditions, MaxInArraySize, MaxRegexLength) before
+// it is translated, so pathological queries never reach the database.
+// Placeholders must already be resolved (see ResolvePlaceholders).
+// Bare search terms are checked against every default search field.
+// Executors call it before applying BaseFilter, which is trusted.
+func (o *ExecutorOptions) ValidateFilter(node Node) error {
+	if node == nil {
+		return nil
+	}
+	conditions := 0
+	if err := o.validateNode(node, 1, &conditions); err != nil {
+		return err
+	}
+	if o.MaxConditions > 0 && conditions > o.MaxConditions {
+		return fmt.Errorf("%w: %d conditions exceed the maximum of %d", ErrQueryTooComplex, conditions, o.MaxConditions)
+	}
+	// Dropping every term would match every item
+	if kept, dropped := o.DropIgnoredTerms(node); kept == nil && len(dropped) > 0 {
+		return fmt.Errorf("%w: %s", ErrSearchTermsIgnored, strings.Join(dropped, ", "))
+	}
+	return nil
+}
+
+func (o *ExecutorOptions) validateNode(node Node, depth int, conditions *int) error {
+	if o.MaxFilterDepth > 0 && depth > o.MaxFilterDepth {
+		return fmt.Errorf("%w: filter nesting exceeds the maximum depth of %d", ErrQueryTooComplex, o.MaxFilterDepth)
+	}
+
+	switch n := node.(type) {
+	case *BinaryOpNode:
+		if err := o.validateNode(n.Left, depth+1, conditions); err != nil {
+			return err
+		}
+		return o.validateNode(n.Right, depth+1, conditions)
+	case *ComparisonNode:
+		*conditions++
+		if name, ok := placeholderIn(n.Value); ok {
+			// Executors resolve placeholders before validating
+			return NewFieldError(n.Field, fmt.Errorf("%w: @%s is not resolved", ErrUnknownPlaceholder, name))
+		}
+		if name, ok := parameterIn(n.Value); ok {
+			return NewFieldError(n.Field, fmt.Errorf("%w: :%s", ErrUnboundParameter, name))
+		}
+		if rel, ok := relativeTimeIn(n); ok {
+			return NewFieldError(n.Field, fmt.Errorf("%w: %s is not resolved", ErrInvalidQuery, rel))
+		}
+		if err := o.checkLimits(n); err != nil {
+			return err
+		}
+		if err := ValidateArrayCondition(n); err != nil {
+			return err
+		}
+		if n.Field == SearchField {
+			for _, field := range o.SearchFields() {
+				if err := o.CheckOperator(field, n.Operator); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return o.CheckOperator(n.Field, n.Operator)
+	}
+	return nil
+}
+
+// checkLimits enforces the per-condition size limits
+func (o *ExecutorOptions) checkLimits(n *ComparisonNode) error {
+	switch n.Operator {
+	case OpIn, OpNotIn:
+		if arr, ok := n.Value.(ArrayValue); ok && o.MaxInArraySize > 0 && len(arr) > o.MaxInArraySize {
+			return NewFieldError(n.Field, fmt.Errorf("%w: %s list has %d values, the maximum is %d",
+				ErrQueryTooComplex, n.Operator, len(arr), o.MaxInArraySize))
+		}
+	case OpRegex:
+		pattern, ok := n.Value.(StringValue)
+		if !ok {
+			break
+		}
+		if o.MaxRegexLength > 0 {
+			if length := utf8.RuneCountInString(string(pattern)); length > o.MaxRegexLength {
+				return NewFieldError(n.Field, fmt.Errorf("%w: regex pattern has %d characters, the maximum is %d",
+					ErrQueryTooComplex, length, o.MaxRegexLength))
+			}
+		}
+		if o.SafeRegex {
+			if err := ValidateRegex(string(pattern)); err != nil {
+				return NewFieldError(n.Field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckOperator returns an *OperatorError if FieldPolicy forbids op on field
+func (o *ExecutorOptions) CheckOperator(field string, op ComparisonOperator) error {
+	if len(o.FieldPolicy) == 0 {
+		return nil
+	}
+	allowed, ok := o.FieldPolicy[field]
+	if !ok {
+		if allowed, ok = o.FieldPolicy[AnyField]; !ok {
+			return nil
+		}
+	}
+	for _, a := range allowed {
+		if a == op {
+			return nil
+		}
+	}
+	return &OperatorError{Field: field, Operator: op}
+}
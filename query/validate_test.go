@@ -0,0 +1,105 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorOptions_FieldPolicy(t *testing.T) {
+	opts := &ExecutorOptions{
+		DefaultSearchFields: []string{"name", "email"},
+		FieldPolicy: map[string][]ComparisonOperator{
+			"email":      {OpEqual, OpIn},
+			"created_at": {OpGreaterThan, OpGreaterThanOrEqual, OpLessThan, OpLessThanOrEqual},
+			AnyField:     {OpEqual, OpNotEqual, OpContains, OpIn},
+		},
+	}
+	cmp := func(field string, op ComparisonOperator) *ComparisonNode {
+		return &ComparisonNode{Field: field, Operator: op, Value: StringValue("x")}
+	}
+
+	tests := []struct {
+		name    string
+		node    Node
+		errText string
+	}{
+		{"nil filter", nil, ""},
+		{"listed operator", cmp("email", OpEqual), ""},
+		{"range only", cmp("created_at", OpGreaterThanOrEqual), ""},
+		{"equality on range field", cmp("created_at", OpEqual), "field 'created_at': operator not allowed: ="},
+		{"listed field ignores default", cmp("email", OpContains), "field 'email': operator not allowed: CONTAINS"},
+		{"default entry", cmp("name", OpContains), ""},
+		{"no regex anywhere", cmp("name", OpRegex), "field 'name': operator not allowed: REGEX"},
+		{"nested", And(cmp("name", OpEqual), Or(cmp("email", OpIn), cmp("email", OpLike))), "field 'email': operator not allowed: LIKE"},
+		{"bare search checks search fields", cmp("__DEFAULT_SEARCH__", OpContains), "field 'email': operator not allowed: CONTAINS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := opts.ValidateFilter(tt.node)
+			if tt.errText == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.errText)
+			assert.True(t, errors.Is(err, ErrOperatorNotAllowed))
+			var opErr *OperatorError
+			assert.True(t, errors.As(err, &opErr))
+		})
+	}
+
+	t.Run("no default entry leaves other fields unrestricted", func(t *testing.T) {
+		opts := &ExecutorOptions{FieldPolicy: map[string][]ComparisonOperator{"email": {OpEqual}}}
+		assert.NoError(t, opts.ValidateFilter(cmp("name", OpRegex)))
+		assert.ErrorIs(t, opts.ValidateFilter(cmp("email", OpRegex)), ErrOperatorNotAllowed)
+	})
+}
+
+func TestExecutorOptions_ComplexityLimits(t *testing.T) {
+	cmp := func(field string) Node {
+		return &ComparisonNode{Field: field, Operator: OpEqual, Value: IntValue(1)}
+	}
+	// a = 1 AND (b = 1 OR (c = 1 AND d = 1)) has depth 4 and 4 conditions
+	nested := And(cmp("a"), Or(cmp("b"), And(cmp("c"), cmp("d"))))
+	in := func(n int) Node {
+		arr := make(ArrayValue, n)
+		for i := range arr {
+			arr[i] = IntValue(int64(i))
+		}
+		return &ComparisonNode{Field: "id", Operator: OpNotIn, Value: arr}
+	}
+	regex := &ComparisonNode{Field: "name", Operator: OpRegex, Value: StringValue("^(a+)+$")}
+
+	tests := []struct {
+		name    string
+		opts    ExecutorOptions
+		node    Node
+		errText string
+	}{
+		{"no limits", ExecutorOptions{}, nested, ""},
+		{"depth within limit", ExecutorOptions{MaxFilterDepth: 4}, nested, ""},
+		{"depth exceeded", ExecutorOptions{MaxFilterDepth: 3}, nested, "query too complex: filter nesting exceeds the maximum depth of 3"},
+		{"conditions within limit", ExecutorOptions{MaxConditions: 4}, nested, ""},
+		{"conditions exceeded", ExecutorOptions{MaxConditions: 3}, nested, "query too complex: 4 conditions exceed the maximum of 3"},
+		{"in list within limit", ExecutorOptions{MaxInArraySize: 3}, in(3), ""},
+		{"in list exceeded", ExecutorOptions{MaxInArraySize: 3}, in(4), "field 'id': query too complex: NOT IN list has 4 values, the maximum is 3"},
+		{"regex within limit", ExecutorOptions{MaxRegexLength: 7}, regex, ""},
+		{"regex exceeded", ExecutorOptions{MaxRegexLength: 6}, regex, "field 'name': query too complex: regex pattern has 7 characters, the maximum is 6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.ValidateFilter(tt.node)
+			if tt.errText == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.errText)
+			assert.ErrorIs(t, err, ErrQueryTooComplex)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package query
+
+// Walk calls fn for node and then, depth first and left to right, for every
+// node below it. When fn returns false the nodes below that node are skipped.
+// nil nodes are not visited.
+//
+// Example:
+//
+//	// Collect the fields a filter references
+//	fields := map[string]bool{}
+//	query.Walk(filter, func(node query.Node) bool {
+//		if n, ok := node.(*query.ComparisonNode); ok && !query.IsSearch(n) {
+//			fields[n.Field] = true
+//		}
+//		return true
+//	})
+func Walk(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+	if n, ok := node.(*BinaryOpNode); ok {
+		Walk(n.Left, fn)
+		Walk(n.Right, fn)
+	}
+}
+
+// Rewrite returns a copy of node transformed by fn. It works bottom up: the
+// operands of an AND or OR are rewritten first, then fn receives the operation
+// rebuilt from them. fn returns the replacement of the node it is given, the
+// node itself to keep it, or nil to remove it; the other operand then takes the
+// place of the operation. Removing an operand of AND widens the filter, so
+// check what is left when stripping predicates for security.
+//
+// fn must not modify the nodes it receives; node is not modified.
+//
+// Example:
+//
+//	// Map API field names to column names
+//	filter = query.Rewrite(filter, func(node query.Node) query.Node {
+//		if n, ok := node.(*query.ComparisonNode); ok && n.Field == "created" {
+//			renamed := *n
+//			renamed.Field = "created_at"
+//			return &renamed
+//		}
+//		return node
+//	})
+func Rewrite(node Node, fn func(Node) Node) Node {
+	if node == nil {
+		return nil
+	}
+	if n, ok := node.(*BinaryOpNode); ok {
+		left, right := Rewrite(n.Left, fn), Rewrite(n.Right, fn)
+		switch {
+		case left == nil:
+			return right
+		case right == nil:
+			return left
+		}
+		node = &BinaryOpNode{Operator: n.Operator, Left: left, Right: right}
+	}
+	return fn(node)
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	filter := And(Eq("a", 1), Or(Eq("b", 2), Search("usb")))
+
+	var visited []string
+	Walk(filter, func(node Node) bool {
+		visited = append(visited, FormatFilter(node))
+		return true
+	})
+	assert.Equal(t, []string{`a = 1 AND (b = 2 OR usb)`, `a = 1`, `b = 2 OR usb`, `b = 2`, `usb`}, visited)
+
+	t.Run("skip children", func(t *testing.T) {
+		var fields []string
+		Walk(filter, func(node Node) bool {
+			switch n := node.(type) {
+			case *BinaryOpNode:
+				return n.Operator == BinaryOpAnd
+			case *ComparisonNode:
+				fields = append(fields, n.Field)
+			}
+			return true
+		})
+		assert.Equal(t, []string{"a"}, fields)
+	})
+
+	assert.NotPanics(t, func() {
+		Walk(nil, func(Node) bool { t.Fatal("nil must not be visited"); return true })
+	})
+}
+
+func TestRewrite(t *testing.T) {
+	filter := And(Eq("created", 1), Or(Eq("secret", 2), Eq("b", 3)))
+
+	t.Run("map fields", func(t *testing.T) {
+		renamed := Rewrite(filter, func(node Node) Node {
+			if n, ok := node.(*ComparisonNode); ok && n.Field == "created" {
+				c := *n
+				c.Field = "created_at"
+				return &c
+			}
+			return node
+		})
+		assert.Equal(t, `created_at = 1 AND (secret = 2 OR b = 3)`, FormatFilter(renamed))
+		assert.Equal(t, `created = 1 AND (secret = 2 OR b = 3)`, FormatFilter(filter), "input is not modified")
+	})
+
+	t.Run("remove predicates", func(t *testing.T) {
+		strip := func(field string) func(Node) Node {
+			return func(node Node) Node {
+				if n, ok := node.(*ComparisonNode); ok && n.Field == field {
+					return nil
+				}
+				return node
+			}
+		}
+		assert.Equal(t, `created = 1 AND b = 3`, FormatFilter(Rewrite(filter, strip("secret"))))
+		assert.Nil(t, Rewrite(Eq("secret", 1), strip("secret")))
+		assert.Nil(t, Rewrite(Or(Eq("secret", 1), Eq("secret", 2)), strip("secret")))
+	})
+
+	t.Run("operations are rebuilt before fn sees them", func(t *testing.T) {
+		var ops []string
+		Rewrite(filter, func(node Node) Node {
+			if n, ok := node.(*BinaryOpNode); ok {
+				ops = append(ops, FormatFilter(n))
+			}
+			return node
+		})
+		assert.Equal(t, []string{`secret = 2 OR b = 3`, `created = 1 AND (secret = 2 OR b = 3)`}, ops)
+	})
+
+	assert.Nil(t, Rewrite(nil, func(node Node) Node { return node }))
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+	"fmt"
+)
+
+// WriteOperator is the operator FieldAuthorizer receives for the fields
+// UpdateWhere changes
+const WriteOperator ComparisonOperator = -2
+
+// ValidateWrite checks a DeleteWhere or UpdateWhere call before executors
+// validate its filter like Count does: AllowWrites must be set, and the query
+// needs a filter, so a blank search cannot change every item
+func (o *ExecutorOptions) ValidateWrite(q *Query) error {
+	if !o.AllowWrites {
+		return ErrWritesNotAllowed
+	}
+	if q == nil || q.Filter == nil {
+		return fmt.Errorf("%w: writes need a filter", ErrInvalidQuery)
+	}
+	return nil
+}
+
+// ValidateChanges checks the changes of UpdateWhere: there must be at least
+// one, and every field must be allowed and pass FieldAuthorizer with
+// WriteOperator. It returns the changes with ValueConverter applied
+func (o *ExecutorOptions) ValidateChanges(ctx context.Context, changes map[string]interface{}) (map[string]interface{}, error) {
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: update without changes", ErrInvalidQuery)
+	}
+	converted := make(map[string]interface{}, len(changes))
+	for field, value := range changes {
+		if field == "" || field == SearchField || field == ScoreField || field == MatchCountField {
+			return nil, InvalidFieldNameError(field)
+		}
+		if !o.IsFieldAllowed(field) {
+			return nil, FieldNotAllowedError(field, o.AllowedFields...)
+		}
+		if o.FieldAuthorizer != nil {
+			if err := o.FieldAuthorizer(ctx, field, WriteOperator); err != nil {
+				return nil, err
+			}
+		}
+		value, err := o.ConvertValue(field, value)
+		if err != nil {
+			return nil, NewFieldError(field, err)
+		}
+		converted[field] = value
+	}
+	return converted, nil
+}
@@ -0,0 +1,53 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorOptions_ValidateWrite(t *testing.T) {
+	q := &Query{Filter: &ComparisonNode{Field: "status", Operator: OpEqual, Value: StringValue("draft")}}
+
+	opts := DefaultExecutorOptions()
+	assert.ErrorIs(t, opts.ValidateWrite(q), ErrWritesNotAllowed)
+
+	opts.AllowWrites = true
+	assert.NoError(t, opts.ValidateWrite(q))
+	assert.ErrorIs(t, opts.ValidateWrite(&Query{}), ErrInvalidQuery)
+	assert.ErrorIs(t, opts.ValidateWrite(nil), ErrInvalidQuery)
+}
+
+func TestExecutorOptions_ValidateChanges(t *testing.T) {
+	ctx := context.Background()
+	opts := DefaultExecutorOptions()
+	opts.AllowedFields = []string{"status", "priority", "owner"}
+	opts.ValueConverter = func(field string, value interface{}) (interface{}, error) {
+		if field == "priority" && value == "high" {
+			return 3, nil
+		}
+		return value, nil
+	}
+	opts.FieldAuthorizer = func(ctx context.Context, field string, op ComparisonOperator) error {
+		if field == "owner" && op == WriteOperator {
+			return errors.New("owner is read-only")
+		}
+		return nil
+	}
+
+	changes, err := opts.ValidateChanges(ctx, map[string]interface{}{"status": "done", "priority": "high"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"status": "done", "priority": 3}, changes)
+
+	_, err = opts.ValidateChanges(ctx, nil)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = opts.ValidateChanges(ctx, map[string]interface{}{"secret": 1})
+	assert.ErrorIs(t, err, ErrFieldNotAllowed)
+	_, err = opts.ValidateChanges(ctx, map[string]interface{}{ScoreField: 1})
+	assert.ErrorIs(t, err, ErrInvalidFieldName)
+	_, err = opts.ValidateChanges(ctx, map[string]interface{}{"owner": "mallory"})
+	assert.EqualError(t, err, "owner is read-only")
+}
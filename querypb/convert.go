@@ -0,0 +1,357 @@
+// Package querypb provides a protobuf representation of queries and results
+// so services can pass parsed queries to each other without re-parsing strings.
+//
+// The receiving service converts the message back with FromProto and executes it
+// with its own executor options, so AllowedFields, policies and schema
+// validation still apply on that side. Relative times such as now-7d and
+// @placeholders are sent unresolved, so the receiver resolves them with its own
+// clock and request context, and unbound :parameters can be bound remotely with
+// query.Query.Bind.
+package querypb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProto converts a query into its protobuf representation
+func ToProto(q *query.Query) (*Query, error) {
+	if q == nil {
+		return nil, nil
+	}
+	filter, err := nodeToProto(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{
+		Filter:    filter,
+		SortBy:    q.SortBy,
+		SortOrder: SortOrder(q.SortOrder),
+		PageSize:  int32(q.PageSize),
+		Limit:     int32(q.Limit),
+
+		PreserveInOrder: q.PreserveInOrder,
+		IncludeDeleted:  q.IncludeDeleted,
+		Distinct:        q.Distinct,
+		DistinctOn:      q.DistinctOn,
+		RandomSeed:      q.RandomSeed,
+		Page:            int32(q.Page),
+
+		HighlightRequested: q.HighlightRequested,
+		ExplainRequested:   q.ExplainRequested,
+	}, nil
+}
+
+// FromProto converts a protobuf query back into a query.
+// Malformed messages return errors wrapping query.ErrInvalidQuery.
+func FromProto(pb *Query) (*query.Query, error) {
+	if pb == nil {
+		return nil, nil
+	}
+	filter, err := nodeFromProto(pb.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	var sortOrder query.SortOrder
+	switch pb.GetSortOrder() {
+	case SortOrder_SORT_ORDER_ASC:
+		sortOrder = query.SortOrderAsc
+	case SortOrder_SORT_ORDER_DESC:
+		sortOrder = query.SortOrderDesc
+	case SortOrder_SORT_ORDER_RANDOM:
+		sortOrder = query.SortOrderRandom
+	default:
+		return nil, fmt.Errorf("%w: unknown sort order %d", query.ErrInvalidQuery, pb.GetSortOrder())
+	}
+
+	return &query.Query{
+		Filter:    filter,
+		SortBy:    pb.GetSortBy(),
+		SortOrder: sortOrder,
+		PageSize:  int(pb.GetPageSize()),
+		Limit:     int(pb.GetLimit()),
+
+		PreserveInOrder: pb.GetPreserveInOrder(),
+		IncludeDeleted:  pb.GetIncludeDeleted(),
+		Distinct:        pb.GetDistinct(),
+		DistinctOn:      pb.GetDistinctOn(),
+		RandomSeed:      pb.GetRandomSeed(),
+		Page:            int(pb.GetPage()),
+
+		HighlightRequested: pb.GetHighlightRequested(),
+		ExplainRequested:   pb.GetExplainRequested(),
+	}, nil
+}
+
+// ResultToProto converts result metadata into its protobuf representation.
+// Metadata, ExecutionTime and the Args of Explain are not transported
+func ResultToProto(r *query.Result) *Result {
+	if r == nil {
+		return nil
+	}
+	pb := &Result{
+		NextPageCursor: r.NextPageCursor,
+		PrevPageCursor: r.PrevPageCursor,
+		TotalItems:     r.TotalItems,
+		ShowingFrom:    int32(r.ShowingFrom),
+		ShowingTo:      int32(r.ShowingTo),
+		ItemsReturned:  int32(r.ItemsReturned),
+		Scores:         r.Scores,
+
+		TotalItemsEstimated: r.TotalItemsEstimated,
+	}
+	for _, item := range r.Highlights {
+		highlights := &ItemHighlights{}
+		for _, h := range item {
+			highlights.Highlights = append(highlights.Highlights, &Highlight{Field: h.Field, Start: int32(h.Start), End: int32(h.End)})
+		}
+		pb.Highlights = append(pb.Highlights, highlights)
+	}
+	for _, w := range r.Warnings {
+		pb.Warnings = append(pb.Warnings, &Warning{Code: w.Code, Message: w.Message, Terms: w.Terms})
+	}
+	if r.Explain != nil {
+		pb.Explain = &Explain{
+			Backend:   r.Explain.Backend,
+			Statement: r.Explain.Statement,
+			Plan:      r.Explain.Plan,
+			PlanError: r.Explain.PlanError,
+			Indexes:   r.Explain.Indexes,
+		}
+	}
+	if r.Error != nil {
+		pb.Error = r.Error.Error()
+	}
+	return pb
+}
+
+// ResultFromProto converts protobuf result metadata back into a result.
+// A transported error is restored as an opaque error with the same message.
+func ResultFromProto(pb *Result) *query.Result {
+	if pb == nil {
+		return nil
+	}
+	r := &query.Result{
+		NextPageCursor: pb.GetNextPageCursor(),
+		PrevPageCursor: pb.GetPrevPageCursor(),
+		TotalItems:     pb.GetTotalItems(),
+		ShowingFrom:    int(pb.GetShowingFrom()),
+		ShowingTo:      int(pb.GetShowingTo()),
+		ItemsReturned:  int(pb.GetItemsReturned()),
+		Scores:         pb.GetScores(),
+
+		TotalItemsEstimated: pb.GetTotalItemsEstimated(),
+	}
+	for _, item := range pb.GetHighlights() {
+		var highlights []query.Highlight
+		for _, h := range item.GetHighlights() {
+			highlights = append(highlights, query.Highlight{Field: h.GetField(), Start: int(h.GetStart()), End: int(h.GetEnd())})
+		}
+		r.Highlights = append(r.Highlights, highlights)
+	}
+	for _, w := range pb.GetWarnings() {
+		r.Warnings = append(r.Warnings, query.Warning{Code: w.GetCode(), Message: w.GetMessage(), Terms: w.GetTerms()})
+	}
+	if explain := pb.GetExplain(); explain != nil {
+		r.Explain = &query.Explain{
+			Backend:   explain.GetBackend(),
+			Statement: explain.GetStatement(),
+			Plan:      explain.GetPlan(),
+			PlanError: explain.GetPlanError(),
+			Indexes:   explain.GetIndexes(),
+		}
+	}
+	if pb.GetError() != "" {
+		r.Error = fmt.Errorf("%s", pb.GetError())
+	}
+	return r
+}
+
+// nodeToProto converts a filter node
+func nodeToProto(node query.Node) (*Node, error) {
+	switch n := node.(type) {
+	case nil:
+		return nil, nil
+	case *query.BinaryOpNode:
+		left, err := nodeToProto(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := nodeToProto(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Node: &Node_Binary{Binary: &BinaryOp{
+			Operator: BinaryOperator(n.Operator),
+			Left:     left,
+			Right:    right,
+		}}}, nil
+	case *query.ComparisonNode:
+		value, err := valueToProto(n.Value)
+		if err != nil {
+			return nil, query.NewFieldError(n.Field, err)
+		}
+		operator := n.Operator.String()
+		if n.Modifier != query.ArrayModifierNone {
+			// Array modifiers prefix the operator like in the query language, e.g. "ANY ="
+			operator = n.Modifier.String() + " " + operator
+		}
+		return &Node{Node: &Node_Comparison{Comparison: &Comparison{
+			Field:    n.Field,
+			Operator: operator,
+			Value:    value,
+			Phrase:   n.Phrase,
+		}}}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown node type %T", query.ErrInvalidQuery, node)
+	}
+}
+
+// nodeFromProto converts a protobuf filter node
+func nodeFromProto(pb *Node) (query.Node, error) {
+	if pb == nil {
+		return nil, nil
+	}
+	switch n := pb.GetNode().(type) {
+	case *Node_Binary:
+		if n.Binary.GetLeft() == nil || n.Binary.GetRight() == nil {
+			return nil, fmt.Errorf("%w: binary operation requires two operands", query.ErrInvalidQuery)
+		}
+		left, err := nodeFromProto(n.Binary.GetLeft())
+		if err != nil {
+			return nil, err
+		}
+		right, err := nodeFromProto(n.Binary.GetRight())
+		if err != nil {
+			return nil, err
+		}
+		var op query.BinaryOperator
+		switch n.Binary.GetOperator() {
+		case BinaryOperator_BINARY_OPERATOR_AND:
+			op = query.BinaryOpAnd
+		case BinaryOperator_BINARY_OPERATOR_OR:
+			op = query.BinaryOpOr
+		default:
+			return nil, fmt.Errorf("%w: unknown binary operator %d", query.ErrInvalidQuery, n.Binary.GetOperator())
+		}
+		return &query.BinaryOpNode{Operator: op, Left: left, Right: right}, nil
+	case *Node_Comparison:
+		c := n.Comparison
+		if c.GetField() == "" {
+			return nil, fmt.Errorf("%w: comparison without field", query.ErrInvalidQuery)
+		}
+		operator, modifier := c.GetOperator(), query.ArrayModifierNone
+		if prefix, rest, ok := strings.Cut(operator, " "); ok {
+			if m, isModifier := query.ParseArrayModifier(prefix); isModifier {
+				operator, modifier = rest, m
+			}
+		}
+		if !query.IsValidOperator(operator) {
+			return nil, query.NewFieldError(c.GetField(), fmt.Errorf("%w: unknown operator %q", query.ErrInvalidQuery, c.GetOperator()))
+		}
+		value, err := valueFromProto(c.GetValue())
+		if err != nil {
+			return nil, query.NewFieldError(c.GetField(), err)
+		}
+		comparison := &query.ComparisonNode{
+			Field:    c.GetField(),
+			Operator: query.ParseComparisonOperator(operator),
+			Value:    value,
+			Modifier: modifier,
+			Phrase:   c.GetPhrase(),
+		}
+		if err := query.ValidateArrayCondition(comparison); err != nil {
+			return nil, err
+		}
+		return comparison, nil
+	default:
+		return nil, fmt.Errorf("%w: empty filter node", query.ErrInvalidQuery)
+	}
+}
+
+// valueToProto converts a query value
+func valueToProto(v interface{}) (*Value, error) {
+	switch val := v.(type) {
+	case query.StringValue:
+		return &Value{Kind: &Value_StringValue{StringValue: string(val)}}, nil
+	case query.IntValue:
+		return &Value{Kind: &Value_IntValue{IntValue: int64(val)}}, nil
+	case query.FloatValue:
+		return &Value{Kind: &Value_FloatValue{FloatValue: float64(val)}}, nil
+	case query.BoolValue:
+		return &Value{Kind: &Value_BoolValue{BoolValue: bool(val)}}, nil
+	case query.DateTimeValue:
+		return &Value{Kind: &Value_DatetimeValue{DatetimeValue: timestamppb.New(time.Time(val))}}, nil
+	case query.RelativeTimeValue:
+		// Sent unresolved so the receiver resolves it with its own clock
+		return &Value{Kind: &Value_RelativeTime{RelativeTime: string(val)}}, nil
+	case query.PlaceholderValue:
+		return &Value{Kind: &Value_Placeholder{Placeholder: string(val)}}, nil
+	case query.ParameterValue:
+		return &Value{Kind: &Value_Parameter{Parameter: string(val)}}, nil
+	case query.ArrayValue:
+		arr := &ArrayValue{Values: make([]*Value, 0, len(val))}
+		for _, elem := range val {
+			pv, err := valueToProto(elem)
+			if err != nil {
+				return nil, err
+			}
+			arr.Values = append(arr.Values, pv)
+		}
+		return &Value{Kind: &Value_ArrayValue{ArrayValue: arr}}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported value type %T", query.ErrInvalidQuery, v)
+	}
+}
+
+// valueFromProto converts a protobuf value
+func valueFromProto(pb *Value) (interface{}, error) {
+	switch kind := pb.GetKind().(type) {
+	case *Value_StringValue:
+		return query.StringValue(kind.StringValue), nil
+	case *Value_IntValue:
+		return query.IntValue(kind.IntValue), nil
+	case *Value_FloatValue:
+		return query.FloatValue(kind.FloatValue), nil
+	case *Value_BoolValue:
+		return query.BoolValue(kind.BoolValue), nil
+	case *Value_DatetimeValue:
+		if err := kind.DatetimeValue.CheckValid(); err != nil {
+			return nil, fmt.Errorf("%w: %v", query.ErrInvalidQuery, err)
+		}
+		return query.DateTimeValue(kind.DatetimeValue.AsTime()), nil
+	case *Value_RelativeTime:
+		relative, ok := query.ParseRelativeTime(kind.RelativeTime)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid relative time %q", query.ErrInvalidQuery, kind.RelativeTime)
+		}
+		return relative, nil
+	case *Value_Placeholder:
+		if kind.Placeholder == "" {
+			return nil, fmt.Errorf("%w: empty placeholder name", query.ErrInvalidQuery)
+		}
+		return query.PlaceholderValue(kind.Placeholder), nil
+	case *Value_Parameter:
+		if kind.Parameter == "" {
+			return nil, fmt.Errorf("%w: empty parameter name", query.ErrInvalidQuery)
+		}
+		return query.ParameterValue(kind.Parameter), nil
+	case *Value_ArrayValue:
+		arr := make(query.ArrayValue, 0, len(kind.ArrayValue.GetValues()))
+		for _, elem := range kind.ArrayValue.GetValues() {
+			v, err := valueFromProto(elem)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("%w: missing value", query.ErrInvalidQuery)
+	}
+}
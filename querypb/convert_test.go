@@ -0,0 +1,151 @@
+package querypb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRoundTrip(t *testing.T) {
+	inputs := []string{
+		`category = electronics`,
+		`(price >= 10.5 AND stock > 0) OR featured = true sort_by = price sort_order = desc page_size = 25 limit = 100`,
+		`brand IN [Anker, "Sony", 3] AND name NOT LIKE "%refurb%"`,
+		`description MATCH "noise cancelling" wireless`,
+		`"usb cable" hub`,
+		`sort_order = random`,
+		`sort_order = random random_seed = 42`,
+		`id IN [5, 1, 9] preserve_in_order = true`,
+		`status = archived include_deleted = true`,
+		`category = audio page = 3 page_size = 20`,
+		`tags LENGTH > 3 AND tags ANY = wireless AND tags ALL IN [usb, hub]`,
+		`category = audio distinct = true`,
+		`category = audio distinct_on = brand`,
+		`created_at > now-7d AND updated_at < startOfMonth`,
+		`owner = @user_id AND team IN [@team, core]`,
+		`price > :min AND brand IN [:brands, Generic]`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			p, err := parser.NewParser(input)
+			require.NoError(t, err)
+			q, err := p.Parse()
+			require.NoError(t, err)
+
+			pb, err := ToProto(q)
+			require.NoError(t, err)
+
+			// Through the wire format
+			data, err := proto.Marshal(pb)
+			require.NoError(t, err)
+			var decoded Query
+			require.NoError(t, proto.Unmarshal(data, &decoded))
+
+			got, err := FromProto(&decoded)
+			require.NoError(t, err)
+			assert.Equal(t, q, got)
+		})
+	}
+}
+
+func TestRoundTrip_DateTime(t *testing.T) {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	q := &query.Query{
+		Filter:   &query.ComparisonNode{Field: "created_at", Operator: query.OpGreaterThan, Value: query.DateTimeValue(created)},
+		PageSize: 10,
+	}
+
+	pb, err := ToProto(q)
+	require.NoError(t, err)
+	assert.Equal(t, created.Unix(), pb.GetFilter().GetComparison().GetValue().GetDatetimeValue().GetSeconds())
+
+	got, err := FromProto(pb)
+	require.NoError(t, err)
+	assert.True(t, created.Equal(time.Time(got.Filter.(*query.ComparisonNode).Value.(query.DateTimeValue))))
+}
+
+func TestRoundTrip_Requests(t *testing.T) {
+	q := &query.Query{Filter: query.Compare("name", query.OpContains, "usb"), PageSize: 10, HighlightRequested: true, ExplainRequested: true}
+	pb, err := ToProto(q)
+	require.NoError(t, err)
+	got, err := FromProto(pb)
+	require.NoError(t, err)
+	assert.Equal(t, q, got)
+}
+
+func TestFromProto_Invalid(t *testing.T) {
+	value := &Value{Kind: &Value_StringValue{StringValue: "x"}}
+	comparison := func(field, op string, v *Value) *Node {
+		return &Node{Node: &Node_Comparison{Comparison: &Comparison{Field: field, Operator: op, Value: v}}}
+	}
+
+	tests := []struct {
+		name string
+		pb   *Query
+	}{
+		{"unknown operator", &Query{Filter: comparison("name", "SOUNDS_LIKE", value)}},
+		{"missing field", &Query{Filter: comparison("", "=", value)}},
+		{"missing value", &Query{Filter: comparison("name", "=", nil)}},
+		{"empty node", &Query{Filter: &Node{}}},
+		{"missing operand", &Query{Filter: &Node{Node: &Node_Binary{Binary: &BinaryOp{Left: comparison("name", "=", value)}}}}},
+		{"unknown binary operator", &Query{Filter: &Node{Node: &Node_Binary{Binary: &BinaryOp{
+			Operator: 7, Left: comparison("a", "=", value), Right: comparison("b", "=", value),
+		}}}}},
+		{"unknown sort order", &Query{SortOrder: 9}},
+		{"ALL without IN", &Query{Filter: comparison("tags", "ALL =", value)}},
+		{"unknown modifier", &Query{Filter: comparison("tags", "SOME =", value)}},
+		{"empty placeholder", &Query{Filter: comparison("owner", "=", &Value{Kind: &Value_Placeholder{}})}},
+		{"empty parameter", &Query{Filter: comparison("price", ">", &Value{Kind: &Value_Parameter{}})}},
+		{"invalid relative time", &Query{Filter: comparison("created_at", ">", &Value{Kind: &Value_RelativeTime{RelativeTime: "yesterday"}})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromProto(tt.pb)
+			assert.True(t, errors.Is(err, query.ErrInvalidQuery), "got %v", err)
+		})
+	}
+}
+
+func TestToProto_UnsupportedValue(t *testing.T) {
+	_, err := ToProto(&query.Query{Filter: &query.ComparisonNode{Field: "name", Operator: query.OpEqual, Value: struct{}{}}})
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+	var fieldErr *query.FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "name", fieldErr.Field)
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	r := &query.Result{
+		NextPageCursor: "next",
+		PrevPageCursor: "prev",
+		TotalItems:     42,
+		ShowingFrom:    11,
+		ShowingTo:      20,
+		ItemsReturned:  10,
+		Scores:         []float64{0.9, 0.5},
+
+		TotalItemsEstimated: true,
+		Highlights:          [][]query.Highlight{{{Field: "name", Start: 0, End: 3}, {Field: "tags", Start: 4, End: 7}}, nil},
+		Warnings:            []query.Warning{{Code: query.WarningIgnoredTerms, Message: "ignored search terms: the", Terms: []string{"the"}}},
+		Explain:             &query.Explain{Backend: "GORM", Statement: "SELECT 1", Plan: "SCAN products", Indexes: []string{"idx_name"}},
+	}
+
+	got := ResultFromProto(ResultToProto(r))
+	assert.Equal(t, r, got)
+
+	r.Error = query.ErrExecutionFailed
+	got = ResultFromProto(ResultToProto(r))
+	assert.EqualError(t, got.Error, "query execution failed")
+
+	assert.Nil(t, ResultToProto(nil))
+	assert.Nil(t, ResultFromProto(nil))
+}
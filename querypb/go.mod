@@ -0,0 +1,17 @@
+module github.com/hadi77ir/go-query/querypb
+
+go 1.24.0
+
+require (
+	github.com/hadi77ir/go-query v1.4.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/hadi77ir/go-query => ../
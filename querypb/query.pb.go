@@ -0,0 +1,1303 @@
+// Protobuf representation of go-query queries and results.
+//
+// Regenerate query.pb.go with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative query.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v5.27.1
+// source: query.proto
+
+package querypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SortOrder int32
+
+const (
+	SortOrder_SORT_ORDER_ASC    SortOrder = 0
+	SortOrder_SORT_ORDER_DESC   SortOrder = 1
+	SortOrder_SORT_ORDER_RANDOM SortOrder = 2
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_ASC",
+		1: "SORT_ORDER_DESC",
+		2: "SORT_ORDER_RANDOM",
+	}
+	SortOrder_value = map[string]int32{
+		"SORT_ORDER_ASC":    0,
+		"SORT_ORDER_DESC":   1,
+		"SORT_ORDER_RANDOM": 2,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_query_proto_enumTypes[0].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_query_proto_enumTypes[0]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{0}
+}
+
+type BinaryOperator int32
+
+const (
+	BinaryOperator_BINARY_OPERATOR_AND BinaryOperator = 0
+	BinaryOperator_BINARY_OPERATOR_OR  BinaryOperator = 1
+)
+
+// Enum value maps for BinaryOperator.
+var (
+	BinaryOperator_name = map[int32]string{
+		0: "BINARY_OPERATOR_AND",
+		1: "BINARY_OPERATOR_OR",
+	}
+	BinaryOperator_value = map[string]int32{
+		"BINARY_OPERATOR_AND": 0,
+		"BINARY_OPERATOR_OR":  1,
+	}
+)
+
+func (x BinaryOperator) Enum() *BinaryOperator {
+	p := new(BinaryOperator)
+	*p = x
+	return p
+}
+
+func (x BinaryOperator) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BinaryOperator) Descriptor() protoreflect.EnumDescriptor {
+	return file_query_proto_enumTypes[1].Descriptor()
+}
+
+func (BinaryOperator) Type() protoreflect.EnumType {
+	return &file_query_proto_enumTypes[1]
+}
+
+func (x BinaryOperator) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BinaryOperator.Descriptor instead.
+func (BinaryOperator) EnumDescriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{1}
+}
+
+// Query is a parsed query: filter tree plus query options.
+type Query struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Filter             *Node                  `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	SortBy             string                 `protobuf:"bytes,2,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortOrder          SortOrder              `protobuf:"varint,3,opt,name=sort_order,json=sortOrder,proto3,enum=goquery.v1.SortOrder" json:"sort_order,omitempty"`
+	PageSize           int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Limit              int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	PreserveInOrder    bool                   `protobuf:"varint,6,opt,name=preserve_in_order,json=preserveInOrder,proto3" json:"preserve_in_order,omitempty"`
+	IncludeDeleted     bool                   `protobuf:"varint,7,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	Distinct           bool                   `protobuf:"varint,8,opt,name=distinct,proto3" json:"distinct,omitempty"`
+	DistinctOn         string                 `protobuf:"bytes,9,opt,name=distinct_on,json=distinctOn,proto3" json:"distinct_on,omitempty"`
+	RandomSeed         int64                  `protobuf:"varint,10,opt,name=random_seed,json=randomSeed,proto3" json:"random_seed,omitempty"`
+	Page               int32                  `protobuf:"varint,11,opt,name=page,proto3" json:"page,omitempty"`
+	HighlightRequested bool                   `protobuf:"varint,12,opt,name=highlight_requested,json=highlightRequested,proto3" json:"highlight_requested,omitempty"`
+	ExplainRequested   bool                   `protobuf:"varint,13,opt,name=explain_requested,json=explainRequested,proto3" json:"explain_requested,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Query) Reset() {
+	*x = Query{}
+	mi := &file_query_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Query) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Query) ProtoMessage() {}
+
+func (x *Query) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Query.ProtoReflect.Descriptor instead.
+func (*Query) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Query) GetFilter() *Node {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *Query) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *Query) GetSortOrder() SortOrder {
+	if x != nil {
+		return x.SortOrder
+	}
+	return SortOrder_SORT_ORDER_ASC
+}
+
+func (x *Query) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *Query) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *Query) GetPreserveInOrder() bool {
+	if x != nil {
+		return x.PreserveInOrder
+	}
+	return false
+}
+
+func (x *Query) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+func (x *Query) GetDistinct() bool {
+	if x != nil {
+		return x.Distinct
+	}
+	return false
+}
+
+func (x *Query) GetDistinctOn() string {
+	if x != nil {
+		return x.DistinctOn
+	}
+	return ""
+}
+
+func (x *Query) GetRandomSeed() int64 {
+	if x != nil {
+		return x.RandomSeed
+	}
+	return 0
+}
+
+func (x *Query) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *Query) GetHighlightRequested() bool {
+	if x != nil {
+		return x.HighlightRequested
+	}
+	return false
+}
+
+func (x *Query) GetExplainRequested() bool {
+	if x != nil {
+		return x.ExplainRequested
+	}
+	return false
+}
+
+// Node is a filter tree node.
+type Node struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Node:
+	//
+	//	*Node_Binary
+	//	*Node_Comparison
+	Node          isNode_Node `protobuf_oneof:"node"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_query_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Node) GetNode() isNode_Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+func (x *Node) GetBinary() *BinaryOp {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Binary); ok {
+			return x.Binary
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetComparison() *Comparison {
+	if x != nil {
+		if x, ok := x.Node.(*Node_Comparison); ok {
+			return x.Comparison
+		}
+	}
+	return nil
+}
+
+type isNode_Node interface {
+	isNode_Node()
+}
+
+type Node_Binary struct {
+	Binary *BinaryOp `protobuf:"bytes,1,opt,name=binary,proto3,oneof"`
+}
+
+type Node_Comparison struct {
+	Comparison *Comparison `protobuf:"bytes,2,opt,name=comparison,proto3,oneof"`
+}
+
+func (*Node_Binary) isNode_Node() {}
+
+func (*Node_Comparison) isNode_Node() {}
+
+// BinaryOp combines two nodes with AND or OR.
+type BinaryOp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Operator      BinaryOperator         `protobuf:"varint,1,opt,name=operator,proto3,enum=goquery.v1.BinaryOperator" json:"operator,omitempty"`
+	Left          *Node                  `protobuf:"bytes,2,opt,name=left,proto3" json:"left,omitempty"`
+	Right         *Node                  `protobuf:"bytes,3,opt,name=right,proto3" json:"right,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BinaryOp) Reset() {
+	*x = BinaryOp{}
+	mi := &file_query_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BinaryOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BinaryOp) ProtoMessage() {}
+
+func (x *BinaryOp) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BinaryOp.ProtoReflect.Descriptor instead.
+func (*BinaryOp) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BinaryOp) GetOperator() BinaryOperator {
+	if x != nil {
+		return x.Operator
+	}
+	return BinaryOperator_BINARY_OPERATOR_AND
+}
+
+func (x *BinaryOp) GetLeft() *Node {
+	if x != nil {
+		return x.Left
+	}
+	return nil
+}
+
+func (x *BinaryOp) GetRight() *Node {
+	if x != nil {
+		return x.Right
+	}
+	return nil
+}
+
+// Comparison compares a field with a value.
+// Bare search terms keep the "__DEFAULT_SEARCH__" field marker so the receiving
+// executor resolves them with its own options.
+type Comparison struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Field string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// Canonical operator string, e.g. "=", "NOT LIKE", "CONTAINS", "MATCH".
+	// Array modifiers prefix it: "LENGTH >", "ANY =", "ALL IN".
+	Operator string `protobuf:"bytes,2,opt,name=operator,proto3" json:"operator,omitempty"`
+	Value    *Value `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	// Set on quoted bare search terms, matched as phrases.
+	Phrase        bool `protobuf:"varint,4,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Comparison) Reset() {
+	*x = Comparison{}
+	mi := &file_query_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Comparison) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Comparison) ProtoMessage() {}
+
+func (x *Comparison) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Comparison.ProtoReflect.Descriptor instead.
+func (*Comparison) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Comparison) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Comparison) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+func (x *Comparison) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Comparison) GetPhrase() bool {
+	if x != nil {
+		return x.Phrase
+	}
+	return false
+}
+
+// Value is a typed literal.
+type Value struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Value_StringValue
+	//	*Value_IntValue
+	//	*Value_FloatValue
+	//	*Value_BoolValue
+	//	*Value_DatetimeValue
+	//	*Value_ArrayValue
+	//	*Value_RelativeTime
+	//	*Value_Placeholder
+	//	*Value_Parameter
+	Kind          isValue_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	mi := &file_query_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Value) GetKind() isValue_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Value) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetIntValue() int64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetFloatValue() float64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_FloatValue); ok {
+			return x.FloatValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetBoolValue() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BoolValue); ok {
+			return x.BoolValue
+		}
+	}
+	return false
+}
+
+func (x *Value) GetDatetimeValue() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_DatetimeValue); ok {
+			return x.DatetimeValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetArrayValue() *ArrayValue {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_ArrayValue); ok {
+			return x.ArrayValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetRelativeTime() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_RelativeTime); ok {
+			return x.RelativeTime
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetPlaceholder() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_Placeholder); ok {
+			return x.Placeholder
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetParameter() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_Parameter); ok {
+			return x.Parameter
+		}
+	}
+	return ""
+}
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"varint,2,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Value_FloatValue struct {
+	FloatValue float64 `protobuf:"fixed64,3,opt,name=float_value,json=floatValue,proto3,oneof"`
+}
+
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,4,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type Value_DatetimeValue struct {
+	DatetimeValue *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=datetime_value,json=datetimeValue,proto3,oneof"`
+}
+
+type Value_ArrayValue struct {
+	ArrayValue *ArrayValue `protobuf:"bytes,6,opt,name=array_value,json=arrayValue,proto3,oneof"`
+}
+
+type Value_RelativeTime struct {
+	// Relative time such as "now-7d", resolved by the receiver with its own clock.
+	RelativeTime string `protobuf:"bytes,7,opt,name=relative_time,json=relativeTime,proto3,oneof"`
+}
+
+type Value_Placeholder struct {
+	// Placeholder name without "@", resolved by the receiver from its request context.
+	Placeholder string `protobuf:"bytes,8,opt,name=placeholder,proto3,oneof"`
+}
+
+type Value_Parameter struct {
+	// Parameter name without ":", bound by the receiver with Query.Bind.
+	Parameter string `protobuf:"bytes,9,opt,name=parameter,proto3,oneof"`
+}
+
+func (*Value_StringValue) isValue_Kind() {}
+
+func (*Value_IntValue) isValue_Kind() {}
+
+func (*Value_FloatValue) isValue_Kind() {}
+
+func (*Value_BoolValue) isValue_Kind() {}
+
+func (*Value_DatetimeValue) isValue_Kind() {}
+
+func (*Value_ArrayValue) isValue_Kind() {}
+
+func (*Value_RelativeTime) isValue_Kind() {}
+
+func (*Value_Placeholder) isValue_Kind() {}
+
+func (*Value_Parameter) isValue_Kind() {}
+
+type ArrayValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []*Value               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArrayValue) Reset() {
+	*x = ArrayValue{}
+	mi := &file_query_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArrayValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArrayValue) ProtoMessage() {}
+
+func (x *ArrayValue) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArrayValue.ProtoReflect.Descriptor instead.
+func (*ArrayValue) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ArrayValue) GetValues() []*Value {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// Result is the pagination metadata of an executed query.
+type Result struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	NextPageCursor string                 `protobuf:"bytes,1,opt,name=next_page_cursor,json=nextPageCursor,proto3" json:"next_page_cursor,omitempty"`
+	PrevPageCursor string                 `protobuf:"bytes,2,opt,name=prev_page_cursor,json=prevPageCursor,proto3" json:"prev_page_cursor,omitempty"`
+	TotalItems     int64                  `protobuf:"varint,3,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	ShowingFrom    int32                  `protobuf:"varint,4,opt,name=showing_from,json=showingFrom,proto3" json:"showing_from,omitempty"`
+	ShowingTo      int32                  `protobuf:"varint,5,opt,name=showing_to,json=showingTo,proto3" json:"showing_to,omitempty"`
+	ItemsReturned  int32                  `protobuf:"varint,6,opt,name=items_returned,json=itemsReturned,proto3" json:"items_returned,omitempty"`
+	Scores         []float64              `protobuf:"fixed64,7,rep,packed,name=scores,proto3" json:"scores,omitempty"`
+	Error          string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	// Set when total_items is an estimate rather than an exact count.
+	TotalItemsEstimated bool `protobuf:"varint,9,opt,name=total_items_estimated,json=totalItemsEstimated,proto3" json:"total_items_estimated,omitempty"`
+	// Matched fragments of each returned item, in item order.
+	Highlights []*ItemHighlights `protobuf:"bytes,10,rep,name=highlights,proto3" json:"highlights,omitempty"`
+	// Parts of the query that were ignored, such as dropped search terms.
+	Warnings []*Warning `protobuf:"bytes,11,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// How the query ran, when explain_requested is set.
+	Explain       *Explain `protobuf:"bytes,12,opt,name=explain,proto3" json:"explain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_query_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Result) GetNextPageCursor() string {
+	if x != nil {
+		return x.NextPageCursor
+	}
+	return ""
+}
+
+func (x *Result) GetPrevPageCursor() string {
+	if x != nil {
+		return x.PrevPageCursor
+	}
+	return ""
+}
+
+func (x *Result) GetTotalItems() int64 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *Result) GetShowingFrom() int32 {
+	if x != nil {
+		return x.ShowingFrom
+	}
+	return 0
+}
+
+func (x *Result) GetShowingTo() int32 {
+	if x != nil {
+		return x.ShowingTo
+	}
+	return 0
+}
+
+func (x *Result) GetItemsReturned() int32 {
+	if x != nil {
+		return x.ItemsReturned
+	}
+	return 0
+}
+
+func (x *Result) GetScores() []float64 {
+	if x != nil {
+		return x.Scores
+	}
+	return nil
+}
+
+func (x *Result) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Result) GetTotalItemsEstimated() bool {
+	if x != nil {
+		return x.TotalItemsEstimated
+	}
+	return false
+}
+
+func (x *Result) GetHighlights() []*ItemHighlights {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+func (x *Result) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *Result) GetExplain() *Explain {
+	if x != nil {
+		return x.Explain
+	}
+	return nil
+}
+
+// ItemHighlights are the highlights of one returned item.
+type ItemHighlights struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Highlights    []*Highlight           `protobuf:"bytes,1,rep,name=highlights,proto3" json:"highlights,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemHighlights) Reset() {
+	*x = ItemHighlights{}
+	mi := &file_query_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemHighlights) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemHighlights) ProtoMessage() {}
+
+func (x *ItemHighlights) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemHighlights.ProtoReflect.Descriptor instead.
+func (*ItemHighlights) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ItemHighlights) GetHighlights() []*Highlight {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+// Highlight is a fragment of a field value that matched the filter, as
+// [start, end) rune offsets.
+type Highlight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Start         int32                  `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32                  `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Highlight) Reset() {
+	*x = Highlight{}
+	mi := &file_query_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Highlight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Highlight) ProtoMessage() {}
+
+func (x *Highlight) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Highlight.ProtoReflect.Descriptor instead.
+func (*Highlight) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Highlight) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Highlight) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Highlight) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+// Warning describes something about a query that did not stop it from running.
+type Warning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Terms         []string               `protobuf:"bytes,3,rep,name=terms,proto3" json:"terms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	mi := &file_query_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Warning) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Warning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Warning) GetTerms() []string {
+	if x != nil {
+		return x.Terms
+	}
+	return nil
+}
+
+// Explain describes how an executor ran a query. The arguments bound to SQL
+// statements are not transported.
+type Explain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Backend       string                 `protobuf:"bytes,1,opt,name=backend,proto3" json:"backend,omitempty"`
+	Statement     string                 `protobuf:"bytes,2,opt,name=statement,proto3" json:"statement,omitempty"`
+	Plan          string                 `protobuf:"bytes,3,opt,name=plan,proto3" json:"plan,omitempty"`
+	PlanError     string                 `protobuf:"bytes,4,opt,name=plan_error,json=planError,proto3" json:"plan_error,omitempty"`
+	Indexes       []string               `protobuf:"bytes,5,rep,name=indexes,proto3" json:"indexes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Explain) Reset() {
+	*x = Explain{}
+	mi := &file_query_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Explain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Explain) ProtoMessage() {}
+
+func (x *Explain) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Explain.ProtoReflect.Descriptor instead.
+func (*Explain) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Explain) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *Explain) GetStatement() string {
+	if x != nil {
+		return x.Statement
+	}
+	return ""
+}
+
+func (x *Explain) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *Explain) GetPlanError() string {
+	if x != nil {
+		return x.PlanError
+	}
+	return ""
+}
+
+func (x *Explain) GetIndexes() []string {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
+var File_query_proto protoreflect.FileDescriptor
+
+const file_query_proto_rawDesc = "" +
+	"\n" +
+	"\vquery.proto\x12\n" +
+	"goquery.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd8\x03\n" +
+	"\x05Query\x12(\n" +
+	"\x06filter\x18\x01 \x01(\v2\x10.goquery.v1.NodeR\x06filter\x12\x17\n" +
+	"\asort_by\x18\x02 \x01(\tR\x06sortBy\x124\n" +
+	"\n" +
+	"sort_order\x18\x03 \x01(\x0e2\x15.goquery.v1.SortOrderR\tsortOrder\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12*\n" +
+	"\x11preserve_in_order\x18\x06 \x01(\bR\x0fpreserveInOrder\x12'\n" +
+	"\x0finclude_deleted\x18\a \x01(\bR\x0eincludeDeleted\x12\x1a\n" +
+	"\bdistinct\x18\b \x01(\bR\bdistinct\x12\x1f\n" +
+	"\vdistinct_on\x18\t \x01(\tR\n" +
+	"distinctOn\x12\x1f\n" +
+	"\vrandom_seed\x18\n" +
+	" \x01(\x03R\n" +
+	"randomSeed\x12\x12\n" +
+	"\x04page\x18\v \x01(\x05R\x04page\x12/\n" +
+	"\x13highlight_requested\x18\f \x01(\bR\x12highlightRequested\x12+\n" +
+	"\x11explain_requested\x18\r \x01(\bR\x10explainRequested\"x\n" +
+	"\x04Node\x12.\n" +
+	"\x06binary\x18\x01 \x01(\v2\x14.goquery.v1.BinaryOpH\x00R\x06binary\x128\n" +
+	"\n" +
+	"comparison\x18\x02 \x01(\v2\x16.goquery.v1.ComparisonH\x00R\n" +
+	"comparisonB\x06\n" +
+	"\x04node\"\x90\x01\n" +
+	"\bBinaryOp\x126\n" +
+	"\boperator\x18\x01 \x01(\x0e2\x1a.goquery.v1.BinaryOperatorR\boperator\x12$\n" +
+	"\x04left\x18\x02 \x01(\v2\x10.goquery.v1.NodeR\x04left\x12&\n" +
+	"\x05right\x18\x03 \x01(\v2\x10.goquery.v1.NodeR\x05right\"\x7f\n" +
+	"\n" +
+	"Comparison\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x1a\n" +
+	"\boperator\x18\x02 \x01(\tR\boperator\x12'\n" +
+	"\x05value\x18\x03 \x01(\v2\x11.goquery.v1.ValueR\x05value\x12\x16\n" +
+	"\x06phrase\x18\x04 \x01(\bR\x06phrase\"\x82\x03\n" +
+	"\x05Value\x12#\n" +
+	"\fstring_value\x18\x01 \x01(\tH\x00R\vstringValue\x12\x1d\n" +
+	"\tint_value\x18\x02 \x01(\x03H\x00R\bintValue\x12!\n" +
+	"\vfloat_value\x18\x03 \x01(\x01H\x00R\n" +
+	"floatValue\x12\x1f\n" +
+	"\n" +
+	"bool_value\x18\x04 \x01(\bH\x00R\tboolValue\x12C\n" +
+	"\x0edatetime_value\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\rdatetimeValue\x129\n" +
+	"\varray_value\x18\x06 \x01(\v2\x16.goquery.v1.ArrayValueH\x00R\n" +
+	"arrayValue\x12%\n" +
+	"\rrelative_time\x18\a \x01(\tH\x00R\frelativeTime\x12\"\n" +
+	"\vplaceholder\x18\b \x01(\tH\x00R\vplaceholder\x12\x1e\n" +
+	"\tparameter\x18\t \x01(\tH\x00R\tparameterB\x06\n" +
+	"\x04kind\"7\n" +
+	"\n" +
+	"ArrayValue\x12)\n" +
+	"\x06values\x18\x01 \x03(\v2\x11.goquery.v1.ValueR\x06values\"\xe4\x03\n" +
+	"\x06Result\x12(\n" +
+	"\x10next_page_cursor\x18\x01 \x01(\tR\x0enextPageCursor\x12(\n" +
+	"\x10prev_page_cursor\x18\x02 \x01(\tR\x0eprevPageCursor\x12\x1f\n" +
+	"\vtotal_items\x18\x03 \x01(\x03R\n" +
+	"totalItems\x12!\n" +
+	"\fshowing_from\x18\x04 \x01(\x05R\vshowingFrom\x12\x1d\n" +
+	"\n" +
+	"showing_to\x18\x05 \x01(\x05R\tshowingTo\x12%\n" +
+	"\x0eitems_returned\x18\x06 \x01(\x05R\ritemsReturned\x12\x16\n" +
+	"\x06scores\x18\a \x03(\x01R\x06scores\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x122\n" +
+	"\x15total_items_estimated\x18\t \x01(\bR\x13totalItemsEstimated\x12:\n" +
+	"\n" +
+	"highlights\x18\n" +
+	" \x03(\v2\x1a.goquery.v1.ItemHighlightsR\n" +
+	"highlights\x12/\n" +
+	"\bwarnings\x18\v \x03(\v2\x13.goquery.v1.WarningR\bwarnings\x12-\n" +
+	"\aexplain\x18\f \x01(\v2\x13.goquery.v1.ExplainR\aexplain\"G\n" +
+	"\x0eItemHighlights\x125\n" +
+	"\n" +
+	"highlights\x18\x01 \x03(\v2\x15.goquery.v1.HighlightR\n" +
+	"highlights\"I\n" +
+	"\tHighlight\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x05R\x03end\"M\n" +
+	"\aWarning\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
+	"\x05terms\x18\x03 \x03(\tR\x05terms\"\x8e\x01\n" +
+	"\aExplain\x12\x18\n" +
+	"\abackend\x18\x01 \x01(\tR\abackend\x12\x1c\n" +
+	"\tstatement\x18\x02 \x01(\tR\tstatement\x12\x12\n" +
+	"\x04plan\x18\x03 \x01(\tR\x04plan\x12\x1d\n" +
+	"\n" +
+	"plan_error\x18\x04 \x01(\tR\tplanError\x12\x18\n" +
+	"\aindexes\x18\x05 \x03(\tR\aindexes*K\n" +
+	"\tSortOrder\x12\x12\n" +
+	"\x0eSORT_ORDER_ASC\x10\x00\x12\x13\n" +
+	"\x0fSORT_ORDER_DESC\x10\x01\x12\x15\n" +
+	"\x11SORT_ORDER_RANDOM\x10\x02*A\n" +
+	"\x0eBinaryOperator\x12\x17\n" +
+	"\x13BINARY_OPERATOR_AND\x10\x00\x12\x16\n" +
+	"\x12BINARY_OPERATOR_OR\x10\x01B&Z$github.com/hadi77ir/go-query/querypbb\x06proto3"
+
+var (
+	file_query_proto_rawDescOnce sync.Once
+	file_query_proto_rawDescData []byte
+)
+
+func file_query_proto_rawDescGZIP() []byte {
+	file_query_proto_rawDescOnce.Do(func() {
+		file_query_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_query_proto_rawDesc), len(file_query_proto_rawDesc)))
+	})
+	return file_query_proto_rawDescData
+}
+
+var file_query_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_query_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_query_proto_goTypes = []any{
+	(SortOrder)(0),                // 0: goquery.v1.SortOrder
+	(BinaryOperator)(0),           // 1: goquery.v1.BinaryOperator
+	(*Query)(nil),                 // 2: goquery.v1.Query
+	(*Node)(nil),                  // 3: goquery.v1.Node
+	(*BinaryOp)(nil),              // 4: goquery.v1.BinaryOp
+	(*Comparison)(nil),            // 5: goquery.v1.Comparison
+	(*Value)(nil),                 // 6: goquery.v1.Value
+	(*ArrayValue)(nil),            // 7: goquery.v1.ArrayValue
+	(*Result)(nil),                // 8: goquery.v1.Result
+	(*ItemHighlights)(nil),        // 9: goquery.v1.ItemHighlights
+	(*Highlight)(nil),             // 10: goquery.v1.Highlight
+	(*Warning)(nil),               // 11: goquery.v1.Warning
+	(*Explain)(nil),               // 12: goquery.v1.Explain
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_query_proto_depIdxs = []int32{
+	3,  // 0: goquery.v1.Query.filter:type_name -> goquery.v1.Node
+	0,  // 1: goquery.v1.Query.sort_order:type_name -> goquery.v1.SortOrder
+	4,  // 2: goquery.v1.Node.binary:type_name -> goquery.v1.BinaryOp
+	5,  // 3: goquery.v1.Node.comparison:type_name -> goquery.v1.Comparison
+	1,  // 4: goquery.v1.BinaryOp.operator:type_name -> goquery.v1.BinaryOperator
+	3,  // 5: goquery.v1.BinaryOp.left:type_name -> goquery.v1.Node
+	3,  // 6: goquery.v1.BinaryOp.right:type_name -> goquery.v1.Node
+	6,  // 7: goquery.v1.Comparison.value:type_name -> goquery.v1.Value
+	13, // 8: goquery.v1.Value.datetime_value:type_name -> google.protobuf.Timestamp
+	7,  // 9: goquery.v1.Value.array_value:type_name -> goquery.v1.ArrayValue
+	6,  // 10: goquery.v1.ArrayValue.values:type_name -> goquery.v1.Value
+	9,  // 11: goquery.v1.Result.highlights:type_name -> goquery.v1.ItemHighlights
+	11, // 12: goquery.v1.Result.warnings:type_name -> goquery.v1.Warning
+	12, // 13: goquery.v1.Result.explain:type_name -> goquery.v1.Explain
+	10, // 14: goquery.v1.ItemHighlights.highlights:type_name -> goquery.v1.Highlight
+	15, // [15:15] is the sub-list for method output_type
+	15, // [15:15] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_query_proto_init() }
+func file_query_proto_init() {
+	if File_query_proto != nil {
+		return
+	}
+	file_query_proto_msgTypes[1].OneofWrappers = []any{
+		(*Node_Binary)(nil),
+		(*Node_Comparison)(nil),
+	}
+	file_query_proto_msgTypes[4].OneofWrappers = []any{
+		(*Value_StringValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_FloatValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_DatetimeValue)(nil),
+		(*Value_ArrayValue)(nil),
+		(*Value_RelativeTime)(nil),
+		(*Value_Placeholder)(nil),
+		(*Value_Parameter)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_query_proto_rawDesc), len(file_query_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_query_proto_goTypes,
+		DependencyIndexes: file_query_proto_depIdxs,
+		EnumInfos:         file_query_proto_enumTypes,
+		MessageInfos:      file_query_proto_msgTypes,
+	}.Build()
+	File_query_proto = out.File
+	file_query_proto_goTypes = nil
+	file_query_proto_depIdxs = nil
+}
@@ -0,0 +1,93 @@
+package querystore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// MemoryStore is a Store that keeps saved queries in memory. It is useful for
+// tests and for seeding a persistent store
+type MemoryStore struct {
+	mu      sync.RWMutex
+	queries map[string]*SavedQuery
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{queries: make(map[string]*SavedQuery)}
+}
+
+// Put saves a copy of sq
+func (s *MemoryStore) Put(ctx context.Context, sq *SavedQuery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[sq.Name] = clone(sq)
+	return nil
+}
+
+// Get returns a copy of the named query
+func (s *MemoryStore) Get(ctx context.Context, name string) (*SavedQuery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sq, ok := s.queries[name]
+	if !ok {
+		return nil, notFound(name)
+	}
+	return clone(sq), nil
+}
+
+// Delete removes the named query
+func (s *MemoryStore) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.queries[name]; !ok {
+		return notFound(name)
+	}
+	delete(s.queries, name)
+	return nil
+}
+
+// List returns copies of all saved queries, sorted by name
+func (s *MemoryStore) List(ctx context.Context) ([]*SavedQuery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]*SavedQuery, 0, len(s.queries))
+	for _, sq := range s.queries {
+		list = append(list, clone(sq))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// clone copies sq, its metadata and its query. Filter nodes are shared; they
+// are not changed once parsed
+func clone(sq *SavedQuery) *SavedQuery {
+	copied := *sq
+	copied.Metadata = cloneMetadata(sq.Metadata)
+	if sq.Query != nil {
+		q := *sq.Query
+		copied.Query = &q
+	}
+	return &copied
+}
+
+// cloneMetadata copies m, keeping nil as nil
+func cloneMetadata(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// isNotFound reports whether err means a saved query does not exist
+func isNotFound(err error) bool {
+	return errors.Is(err, query.ErrNamedQueryNotFound)
+}
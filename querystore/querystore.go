@@ -0,0 +1,246 @@
+// Package querystore saves queries under a name so users can keep and rerun
+// them, e.g. "saved searches" in a UI.
+//
+// A Registry parses and validates a query once, when it is saved, and a Store
+// persists it. MemoryStore keeps queries in memory; persistent stores
+// implement Store on top of a database:
+//
+//	reg := querystore.New(querystore.NewMemoryStore(), &querystore.Options{Schema: schema})
+//	_, err := reg.Save(ctx, querystore.SavedQuery{
+//	    Name:  "cheap_electronics",
+//	    Text:  "category = electronics AND price < 100 sort_by = price",
+//	    Owner: userID,
+//	})
+//	...
+//	var products []Product
+//	result, err := reg.Execute(ctx, exec, "cheap_electronics", "", &products)
+//
+// Stores that only keep the raw text may return saved queries without Query;
+// the Registry parses the text again when they are loaded.
+package querystore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/executor"
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+)
+
+// SavedQuery is a named query kept in a Store
+type SavedQuery struct {
+	// Name identifies the query in the store
+	Name string
+
+	// Text is the query as written by its owner
+	Text string
+
+	// Query is the parsed AST of Text
+	Query *query.Query
+
+	// Owner is the user or tenant that saved the query
+	Owner string
+
+	// Metadata holds application data such as a title or description
+	Metadata map[string]string
+
+	// CreatedAt is when the query was first saved
+	CreatedAt time.Time
+
+	// UpdatedAt is when the query was last saved
+	UpdatedAt time.Time
+}
+
+// Store persists saved queries. Implementations must be safe for concurrent
+// use and must not keep or return the SavedQuery values they are given, so
+// callers cannot change stored queries by accident
+type Store interface {
+	// Put saves sq, replacing a saved query with the same name
+	Put(ctx context.Context, sq *SavedQuery) error
+
+	// Get returns the named query, or an error wrapping
+	// query.ErrNamedQueryNotFound
+	Get(ctx context.Context, name string) (*SavedQuery, error)
+
+	// Delete removes the named query, or returns an error wrapping
+	// query.ErrNamedQueryNotFound
+	Delete(ctx context.Context, name string) error
+
+	// List returns all saved queries, sorted by name
+	List(ctx context.Context) ([]*SavedQuery, error)
+}
+
+// Options configure how a Registry parses and validates queries
+type Options struct {
+	// ParserOptions parse saved query text. nil uses the parser defaults
+	ParserOptions *parser.ParserOptions
+
+	// Schema, when set, is checked at save time: every field must be in the
+	// schema, comparisons must suit the field kinds (see
+	// query.ValidateAgainstSchema) and sort_by must be a schema field
+	Schema query.Schema
+
+	// Validate runs after the schema checks at save time, e.g. to apply
+	// ExecutorOptions.ValidateFilter or a policy
+	Validate func(ctx context.Context, q *query.Query) error
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Registry saves, loads and executes named queries kept in a Store
+type Registry struct {
+	store   Store
+	options Options
+}
+
+// New returns a Registry for store. opts may be nil
+func New(store Store, opts *Options) *Registry {
+	r := &Registry{store: store}
+	if opts != nil {
+		r.options = *opts
+	}
+	if r.options.Now == nil {
+		r.options.Now = time.Now
+	}
+	return r
+}
+
+// Store returns the registry's store
+func (r *Registry) Store() Store {
+	return r.store
+}
+
+// Save parses and validates sq.Text and stores it under sq.Name, keeping the
+// creation time of a query saved before under the same name. sq.Query and
+// the timestamps are set by Save. It returns the saved query
+func (r *Registry) Save(ctx context.Context, sq SavedQuery) (*SavedQuery, error) {
+	if err := validName(sq.Name); err != nil {
+		return nil, err
+	}
+	q, err := r.parse(sq.Text)
+	if err != nil {
+		return nil, fmt.Errorf("saved query %s: %w", sq.Name, err)
+	}
+	if err := r.validate(ctx, q); err != nil {
+		return nil, fmt.Errorf("saved query %s: %w", sq.Name, err)
+	}
+
+	now := r.options.Now()
+	sq.Query = q
+	sq.Metadata = cloneMetadata(sq.Metadata)
+	sq.CreatedAt, sq.UpdatedAt = now, now
+	if existing, err := r.store.Get(ctx, sq.Name); err == nil {
+		sq.CreatedAt = existing.CreatedAt
+	} else if !isNotFound(err) {
+		return nil, err
+	}
+	if err := r.store.Put(ctx, &sq); err != nil {
+		return nil, err
+	}
+	return &sq, nil
+}
+
+// Load returns the named query, parsing its text when the store did not keep
+// the AST
+func (r *Registry) Load(ctx context.Context, name string) (*SavedQuery, error) {
+	sq, err := r.store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if sq.Query == nil {
+		if sq.Query, err = r.parse(sq.Text); err != nil {
+			return nil, fmt.Errorf("saved query %s: %w", name, err)
+		}
+	}
+	return sq, nil
+}
+
+// Query returns a copy of the named query's AST, ready to execute or to
+// change, e.g. to set a page size
+func (r *Registry) Query(ctx context.Context, name string) (*query.Query, error) {
+	sq, err := r.Load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	q := *sq.Query
+	return &q, nil
+}
+
+// Execute loads the named query and executes it with e. The executor's own
+// options still apply, so queries saved before a schema or policy change
+// are checked again
+func (r *Registry) Execute(ctx context.Context, e executor.Executor, name, cursor string, dest interface{}) (*query.Result, error) {
+	q, err := r.Query(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return e.Execute(ctx, q, cursor, dest)
+}
+
+// Delete removes the named query
+func (r *Registry) Delete(ctx context.Context, name string) error {
+	return r.store.Delete(ctx, name)
+}
+
+// List returns the saved queries of owner, or of every owner when owner is
+// empty, sorted by name
+func (r *Registry) List(ctx context.Context, owner string) ([]*SavedQuery, error) {
+	all, err := r.store.List(ctx)
+	if err != nil || owner == "" {
+		return all, err
+	}
+	owned := make([]*SavedQuery, 0, len(all))
+	for _, sq := range all {
+		if sq.Owner == owner {
+			owned = append(owned, sq)
+		}
+	}
+	return owned, nil
+}
+
+// parse parses query text with the registry's parser options
+func (r *Registry) parse(text string) (*query.Query, error) {
+	p, err := parser.NewParserWithOptions(text, r.options.ParserOptions)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse()
+}
+
+// validate checks q against the schema and the Validate hook
+func (r *Registry) validate(ctx context.Context, q *query.Query) error {
+	if schema := r.options.Schema; len(schema) > 0 {
+		if err := query.ValidateSortField(q.SortBy, schema.FieldNames()); err != nil {
+			return err
+		}
+		for _, field := range query.Fields(q) {
+			if _, ok := schema[field]; !ok && field != query.SearchField {
+				return query.InvalidFieldNameError(field)
+			}
+		}
+		if err := query.ValidateAgainstSchema(q, schema); err != nil {
+			return err
+		}
+	}
+	if r.options.Validate != nil {
+		return r.options.Validate(ctx, q)
+	}
+	return nil
+}
+
+// validName rejects empty names and names with surrounding whitespace
+func validName(name string) error {
+	if name == "" || strings.TrimSpace(name) != name {
+		return fmt.Errorf("%w: invalid saved query name %q", query.ErrInvalidQuery, name)
+	}
+	return nil
+}
+
+// notFound returns the error stores return for a missing query
+func notFound(name string) error {
+	return fmt.Errorf("%w: %s", query.ErrNamedQueryNotFound, name)
+}
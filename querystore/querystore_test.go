@@ -0,0 +1,150 @@
+package querystore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var productSchema = query.Schema{
+	"name":     query.FieldKindString,
+	"category": query.FieldKindString,
+	"price":    query.FieldKindFloat,
+}
+
+// recordingExecutor keeps the last query it executed
+type recordingExecutor struct {
+	last *query.Query
+}
+
+func (r *recordingExecutor) Execute(ctx context.Context, q *query.Query, cursor string, dest interface{}) (*query.Result, error) {
+	r.last = q
+	return &query.Result{}, nil
+}
+
+func (r *recordingExecutor) Count(ctx context.Context, q *query.Query) (int64, error) { return 0, nil }
+func (r *recordingExecutor) Name() string                                             { return "recording" }
+func (r *recordingExecutor) Close() error                                             { return nil }
+
+// textOnlyStore drops the parsed query, like a store persisting only text
+type textOnlyStore struct {
+	*MemoryStore
+}
+
+func (s textOnlyStore) Put(ctx context.Context, sq *SavedQuery) error {
+	copied := *sq
+	copied.Query = nil
+	return s.MemoryStore.Put(ctx, &copied)
+}
+
+func TestRegistry_Save(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	reg := New(NewMemoryStore(), &Options{Schema: productSchema, Now: func() time.Time { return now }})
+
+	t.Run("parses and stores the query", func(t *testing.T) {
+		sq, err := reg.Save(ctx, SavedQuery{
+			Name:     "cheap",
+			Text:     "category = electronics AND price < 100 sort_by = price",
+			Owner:    "alice",
+			Metadata: map[string]string{"title": "Cheap electronics"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "price", sq.Query.SortBy)
+		assert.Equal(t, now, sq.CreatedAt)
+
+		loaded, err := reg.Load(ctx, "cheap")
+		require.NoError(t, err)
+		assert.Equal(t, sq, loaded)
+	})
+
+	t.Run("keeps the creation time when overwriting", func(t *testing.T) {
+		created := now
+		now = now.Add(time.Hour)
+		sq, err := reg.Save(ctx, SavedQuery{Name: "cheap", Text: "price < 50", Owner: "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, created, sq.CreatedAt)
+		assert.Equal(t, now, sq.UpdatedAt)
+	})
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		_, err := reg.Save(ctx, SavedQuery{Name: "broken", Text: "price <"})
+		assert.Error(t, err)
+
+		_, err = reg.Save(ctx, SavedQuery{Name: "unknown", Text: "color = red"})
+		assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+
+		_, err = reg.Save(ctx, SavedQuery{Name: "mismatch", Text: "price CONTAINS cheap"})
+		assert.ErrorIs(t, err, query.ErrTypeMismatch)
+
+		_, err = reg.Save(ctx, SavedQuery{Name: "sort", Text: "sort_by = color"})
+		var sortErr *query.SortFieldError
+		assert.ErrorAs(t, err, &sortErr)
+
+		_, err = reg.Save(ctx, SavedQuery{Name: " padded", Text: "price > 1"})
+		assert.ErrorIs(t, err, query.ErrInvalidQuery)
+
+		_, err = reg.Load(ctx, "unknown")
+		assert.ErrorIs(t, err, query.ErrNamedQueryNotFound)
+	})
+
+	t.Run("runs the Validate hook", func(t *testing.T) {
+		opts := &query.ExecutorOptions{MaxConditions: 1}
+		reg := New(NewMemoryStore(), &Options{Validate: func(ctx context.Context, q *query.Query) error {
+			return opts.ValidateFilter(q.Filter)
+		}})
+		_, err := reg.Save(ctx, SavedQuery{Name: "big", Text: "price > 1 AND price < 5"})
+		assert.ErrorIs(t, err, query.ErrQueryTooComplex)
+	})
+}
+
+func TestRegistry_Execute(t *testing.T) {
+	ctx := context.Background()
+	reg := New(textOnlyStore{NewMemoryStore()}, nil)
+	_, err := reg.Save(ctx, SavedQuery{Name: "books", Text: "category = books"})
+	require.NoError(t, err)
+
+	exec := &recordingExecutor{}
+	_, err = reg.Execute(ctx, exec, "books", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, &query.ComparisonNode{Field: "category", Operator: query.OpEqual, Value: query.StringValue("books")}, exec.last.Filter)
+
+	// Changing the returned query does not change the saved one
+	exec.last.PageSize = 99
+	q, err := reg.Query(ctx, "books")
+	require.NoError(t, err)
+	assert.Equal(t, 10, q.PageSize)
+
+	_, err = reg.Execute(ctx, exec, "missing", "", nil)
+	assert.ErrorIs(t, err, query.ErrNamedQueryNotFound)
+}
+
+func TestRegistry_ListAndDelete(t *testing.T) {
+	ctx := context.Background()
+	reg := New(NewMemoryStore(), nil)
+	for _, sq := range []SavedQuery{
+		{Name: "b", Text: "x = 1", Owner: "alice"},
+		{Name: "a", Text: "x = 2", Owner: "bob"},
+		{Name: "c", Text: "x = 3", Owner: "alice"},
+	} {
+		_, err := reg.Save(ctx, sq)
+		require.NoError(t, err)
+	}
+
+	all, err := reg.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "a", all[0].Name)
+
+	owned, err := reg.List(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, owned, 2)
+	assert.Equal(t, []string{"b", "c"}, []string{owned[0].Name, owned[1].Name})
+
+	require.NoError(t, reg.Delete(ctx, "a"))
+	assert.ErrorIs(t, reg.Delete(ctx, "a"), query.ErrNamedQueryNotFound)
+}
@@ -0,0 +1,481 @@
+// Package sql translates query filters into dialect-specific SQL WHERE clauses
+// and positional arguments without a database connection or ORM session.
+// The output can be embedded in hand-written statements, logged, or passed to
+// EXPLAIN tooling:
+//
+//	t := sql.NewTranslator(&sql.Options{Dialect: sql.DialectPostgres})
+//	where, args, err := t.Where(q.Filter)
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM products WHERE "+where, args...)
+//
+// Operators translate as in the GORM executor. Field names are validated to
+// prevent SQL injection and values are always passed as arguments.
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-query/query"
+)
+
+// Dialect selects the SQL flavor of the generated clause
+type Dialect int
+
+const (
+	// DialectGeneric uses ? placeholders, REGEXP and LIKE-based full-text search
+	DialectGeneric Dialect = iota
+	// DialectPostgres uses $n placeholders, ILIKE, ~ and tsvector full-text search
+	DialectPostgres
+	// DialectMySQL uses ? placeholders, REGEXP and MATCH ... AGAINST
+	DialectMySQL
+	// DialectSQLite uses ? placeholders and REGEXP (requires a regexp function)
+	DialectSQLite
+	// DialectSQLServer uses @pn placeholders and has no REGEX support
+	DialectSQLServer
+)
+
+// String returns the string representation of Dialect
+func (d Dialect) String() string {
+	switch d {
+	case DialectPostgres:
+		return "postgres"
+	case DialectMySQL:
+		return "mysql"
+	case DialectSQLite:
+		return "sqlite"
+	case DialectSQLServer:
+		return "sqlserver"
+	default:
+		return "generic"
+	}
+}
+
+// ParseDialect parses a dialect name such as "postgres" or "mysql"
+// Returns DialectGeneric for empty or unknown names
+func ParseDialect(s string) Dialect {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "postgres", "postgresql", "pgx":
+		return DialectPostgres
+	case "mysql", "mariadb":
+		return DialectMySQL
+	case "sqlite", "sqlite3":
+		return DialectSQLite
+	case "sqlserver", "mssql":
+		return DialectSQLServer
+	default:
+		return DialectGeneric
+	}
+}
+
+// Options configures a Translator
+type Options struct {
+	// ExecutorOptions supplies AllowedFields, default search fields, ValueConverter,
+	// DisableRegex, AnchorRegex, FloatEpsilon, FullTextTemplate and BaseFilter.
+	// If nil, query.DefaultExecutorOptions() is used
+	*query.ExecutorOptions
+
+	// Dialect selects placeholders, operators and identifier quoting
+	Dialect Dialect
+
+	// FirstArg is the number of the first placeholder for dialects with numbered
+	// placeholders ($1, @p1), so the clause can follow other arguments. Defaults to 1
+	FirstArg int
+
+	// QuoteIdentifiers quotes field names ("price", `price` or [price])
+	QuoteIdentifiers bool
+}
+
+// Translator converts filters to SQL. It is safe for concurrent use.
+type Translator struct {
+	options *Options
+}
+
+// NewTranslator creates a new translator
+func NewTranslator(opts *Options) *Translator {
+	if opts == nil {
+		opts = &Options{}
+	}
+	resolved := *opts
+	if resolved.ExecutorOptions == nil {
+		resolved.ExecutorOptions = query.DefaultExecutorOptions()
+	}
+	if resolved.FirstArg <= 0 {
+		resolved.FirstArg = 1
+	}
+	return &Translator{options: &resolved}
+}
+
+// Where translates a filter into a WHERE expression (without the WHERE keyword)
+// and its arguments. A nil filter returns an empty clause.
+func (t *Translator) Where(node query.Node) (string, []interface{}, error) {
+	if node == nil {
+		return "", nil, nil
+	}
+	b := &builder{t: t}
+	clause, err := b.build(node)
+	if err != nil {
+		return "", nil, err
+	}
+	return clause, b.args, nil
+}
+
+// WhereQuery validates the query's filter against the executor options, adds
+// BaseFilter and translates the result, as an executor would before running it
+func (t *Translator) WhereQuery(q *query.Query) (string, []interface{}, error) {
+	if err := t.options.ValidateFilter(q.Filter); err != nil {
+		return "", nil, err
+	}
+	return t.Where(t.options.ScopedQuery(q).Filter)
+}
+
+// builder accumulates the arguments of one translation
+type builder struct {
+	t    *Translator
+	args []interface{}
+}
+
+// arg records a value and returns its placeholder
+func (b *builder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	n := b.t.options.FirstArg + len(b.args) - 1
+	switch b.t.options.Dialect {
+	case DialectPostgres:
+		return "$" + strconv.Itoa(n)
+	case DialectSQLServer:
+		return "@p" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// build converts the AST filter into SQL
+func (b *builder) build(node query.Node) (string, error) {
+	switch n := node.(type) {
+	case *query.BinaryOpNode:
+		if n.Operator != query.BinaryOpAnd && n.Operator != query.BinaryOpOr {
+			return "", query.ErrInvalidQuery
+		}
+		// Chains of one operator are written flat: (a) AND (b) AND (c)
+		var clauses []string
+		for _, operand := range query.Operands(n) {
+			clause, err := b.build(operand)
+			if err != nil {
+				return "", err
+			}
+			clauses = append(clauses, "("+clause+")")
+		}
+		return strings.Join(clauses, " "+strings.ToUpper(n.Operator.String())+" "), nil
+
+	case *query.ComparisonNode:
+		opts := b.t.options
+		field := n.Field
+		if field == query.SearchField {
+			if expanded := opts.ExpandPhrase(n, opts.Dialect != DialectSQLServer); expanded != n {
+				return b.build(expanded)
+			}
+			if len(opts.DefaultSearchFields) > 0 {
+				// Expand bare terms to an OR across all default search fields
+				return b.build(query.ExpandDefaultSearch(n, opts.DefaultSearchFields))
+			}
+			field = opts.DefaultSearchField
+		}
+
+		// Check if field is in allowed list (security)
+		if !opts.IsFieldAllowed(field) {
+			return "", query.FieldNotAllowedError(field, opts.AllowedFields...)
+		}
+		// Validate field name to prevent SQL injection
+		column, err := b.t.column(field)
+		if err != nil {
+			return "", err
+		}
+		if n.Modifier != query.ArrayModifierNone {
+			return b.arrayComparison(field, column, n)
+		}
+		return b.comparison(field, column, n)
+
+	default:
+		return "", query.ErrInvalidQuery
+	}
+}
+
+// comparison translates a single comparison of column, which holds field
+func (b *builder) comparison(field, column string, n *query.ComparisonNode) (string, error) {
+	opts := b.t.options
+
+	if n.Operator == query.OpIn || n.Operator == query.OpNotIn {
+		arr, err := b.t.convertArrayValue(field, n.Value)
+		if err != nil {
+			return "", err
+		}
+		if len(arr) == 0 {
+			if n.Operator == query.OpIn {
+				return "1 = 0", nil // Empty IN clause
+			}
+			return "1 = 1", nil // Empty NOT IN clause
+		}
+		placeholders := make([]string, len(arr))
+		for i, v := range arr {
+			placeholders[i] = b.arg(v)
+		}
+		keyword := "IN"
+		if n.Operator == query.OpNotIn {
+			keyword = "NOT IN"
+		}
+		return fmt.Sprintf("%s %s (%s)", column, keyword, strings.Join(placeholders, ", ")), nil
+	}
+
+	val, err := b.t.convertValue(field, n.Value)
+	if err != nil {
+		return "", err
+	}
+	str := fmt.Sprintf("%v", val)
+
+	switch n.Operator {
+	case query.OpEqual:
+		if lo, hi, ok := opts.FloatRange(val); ok {
+			return fmt.Sprintf("%s BETWEEN %s AND %s", column, b.arg(lo), b.arg(hi)), nil
+		}
+		return fmt.Sprintf("%s = %s", column, b.arg(val)), nil
+	case query.OpNotEqual:
+		if lo, hi, ok := opts.FloatRange(val); ok {
+			return fmt.Sprintf("%s NOT BETWEEN %s AND %s", column, b.arg(lo), b.arg(hi)), nil
+		}
+		return fmt.Sprintf("%s != %s", column, b.arg(val)), nil
+	case query.OpGreaterThan:
+		return fmt.Sprintf("%s > %s", column, b.arg(val)), nil
+	case query.OpGreaterThanOrEqual:
+		return fmt.Sprintf("%s >= %s", column, b.arg(val)), nil
+	case query.OpLessThan:
+		return fmt.Sprintf("%s < %s", column, b.arg(val)), nil
+	case query.OpLessThanOrEqual:
+		return fmt.Sprintf("%s <= %s", column, b.arg(val)), nil
+	case query.OpLike:
+		return fmt.Sprintf("%s LIKE %s", column, b.arg(val)), nil
+	case query.OpNotLike:
+		return fmt.Sprintf("%s NOT LIKE %s", column, b.arg(val)), nil
+	case query.OpContains:
+		return fmt.Sprintf("%s LIKE %s", column, b.arg("%"+str+"%")), nil
+	case query.OpIContains:
+		if opts.Dialect == DialectPostgres {
+			return fmt.Sprintf("%s ILIKE %s", column, b.arg("%"+str+"%")), nil
+		}
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, b.arg("%"+str+"%")), nil
+	case query.OpStartsWith:
+		return fmt.Sprintf("%s LIKE %s", column, b.arg(str+"%")), nil
+	case query.OpEndsWith:
+		return fmt.Sprintf("%s LIKE %s", column, b.arg("%"+str)), nil
+	case query.OpRegex:
+		if opts.DisableRegex || opts.Dialect == DialectSQLServer {
+			return "", query.ErrRegexNotSupported
+		}
+		pattern := opts.ConditionPattern(n, str)
+		if opts.Dialect == DialectPostgres {
+			return fmt.Sprintf("%s ~ %s", column, b.arg(pattern)), nil
+		}
+		return fmt.Sprintf("%s REGEXP %s", column, b.arg(pattern)), nil
+	case query.OpMatch:
+		return b.match(column, str), nil
+	default:
+		return "", query.ErrInvalidQuery
+	}
+}
+
+// arrayElementColumn is the column of the table of array elements
+const arrayElementColumn = "value"
+
+// arrayComparison translates LENGTH, ANY and ALL conditions on a column holding
+// a JSON array, as the GORM executor does:
+//
+//	tags LENGTH > 3         json_array_length(tags) > ?
+//	tags ANY = "usb"        EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)
+//	tags ALL IN [usb, hub]  EXISTS (... value = ?) AND EXISTS (... value = ?)
+//
+// DialectGeneric has no JSON functions and returns ErrInvalidQuery
+func (b *builder) arrayComparison(field, column string, n *query.ComparisonNode) (string, error) {
+	if err := query.ValidateArrayCondition(n); err != nil {
+		return "", err
+	}
+	length, elements, ok := b.t.jsonArray(column)
+	if !ok {
+		return "", fmt.Errorf("%w: %s is not supported on %q", query.ErrInvalidQuery, n.Modifier, b.t.options.Dialect)
+	}
+
+	switch n.Modifier {
+	case query.ArrayModifierLength:
+		count, ok := n.Value.(query.IntValue)
+		if !ok {
+			return "", query.NewFieldError(field, fmt.Errorf("%w: LENGTH must be compared with an integer", query.ErrInvalidQuery))
+		}
+		return fmt.Sprintf("%s %s %s", length, n.Operator, b.arg(int64(count))), nil
+
+	case query.ArrayModifierAny:
+		element := &query.ComparisonNode{Field: n.Field, Operator: n.Operator, Value: n.Value}
+		cond, err := b.comparison(field, arrayElementColumn, element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", elements, cond), nil
+
+	default:
+		values := n.Value.(query.ArrayValue)
+		if len(values) == 0 {
+			return "1 = 1", nil
+		}
+		clauses := make([]string, len(values))
+		for i, value := range values {
+			element := &query.ComparisonNode{Field: n.Field, Operator: query.OpEqual, Value: value}
+			cond, err := b.comparison(field, arrayElementColumn, element)
+			if err != nil {
+				return "", err
+			}
+			clauses[i] = fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", elements, cond)
+		}
+		return strings.Join(clauses, " AND "), nil
+	}
+}
+
+// jsonArray returns the expression counting the elements of the JSON array in
+// column, and a table of its elements with a value column:
+//   - PostgreSQL: jsonb_array_length, jsonb_array_elements_text (elements are text)
+//   - MySQL 8: JSON_LENGTH, JSON_TABLE
+//   - SQLite: json_array_length, json_each
+//   - SQL Server: OPENJSON
+//
+// ok is false for DialectGeneric
+func (t *Translator) jsonArray(column string) (length, elements string, ok bool) {
+	switch t.options.Dialect {
+	case DialectPostgres:
+		return fmt.Sprintf("jsonb_array_length(CAST(%s AS jsonb))", column),
+			fmt.Sprintf("jsonb_array_elements_text(CAST(%s AS jsonb)) AS _elements(%s)", column, arrayElementColumn), true
+	case DialectMySQL:
+		return fmt.Sprintf("JSON_LENGTH(%s)", column),
+			fmt.Sprintf("JSON_TABLE(%s, '$[*]' COLUMNS (%s LONGTEXT PATH '$')) AS _elements", column, arrayElementColumn), true
+	case DialectSQLite:
+		return fmt.Sprintf("json_array_length(%s)", column), fmt.Sprintf("json_each(%s)", column), true
+	case DialectSQLServer:
+		return fmt.Sprintf("(SELECT COUNT(*) FROM OPENJSON(%s))", column), fmt.Sprintf("OPENJSON(%s)", column), true
+	}
+	return "", "", false
+}
+
+// match builds a full-text MATCH clause for the dialect
+//   - FullTextTemplate, if set; its ? is replaced by the search placeholder
+//   - PostgreSQL: to_tsvector/plainto_tsquery
+//   - MySQL: MATCH ... AGAINST in natural language mode (requires a FULLTEXT index)
+//   - Others: every search term must appear in the field (tokenized LIKE)
+func (b *builder) match(column string, search string) string {
+	opts := b.t.options
+	if opts.FullTextTemplate != "" {
+		return strings.Replace(fmt.Sprintf(opts.FullTextTemplate, column), "?", b.arg(search), 1)
+	}
+
+	switch opts.Dialect {
+	case DialectPostgres:
+		return fmt.Sprintf("to_tsvector(%s) @@ plainto_tsquery(%s)", column, b.arg(search))
+	case DialectMySQL:
+		return fmt.Sprintf("MATCH(%s) AGAINST (%s IN NATURAL LANGUAGE MODE)", column, b.arg(search))
+	}
+
+	terms := query.SearchTerms(search)
+	if len(terms) == 0 {
+		return "1 = 0"
+	}
+	clauses := make([]string, len(terms))
+	for i, term := range terms {
+		clauses[i] = fmt.Sprintf("LOWER(%s) LIKE %s", column, b.arg("%"+term+"%"))
+	}
+	return "(" + strings.Join(clauses, " AND ") + ")"
+}
+
+// column returns the column of field. Plain identifiers are quoted when
+// QuoteIdentifiers is set; other names, such as "order date", are accepted
+// only when listed in AllowedFields and are always quoted
+func (t *Translator) column(field string) (string, error) {
+	if isValidField(field) {
+		return t.quote(field), nil
+	}
+	if !t.options.IsFieldListed(field) {
+		return "", query.InvalidFieldNameError(field)
+	}
+	return t.quoteIdentifier(field), nil
+}
+
+// quote quotes an identifier for the dialect when QuoteIdentifiers is set
+func (t *Translator) quote(field string) string {
+	if !t.options.QuoteIdentifiers {
+		return field
+	}
+	return t.quoteIdentifier(field)
+}
+
+// quoteIdentifier quotes an identifier for the dialect, doubling any closing
+// quote character inside it
+func (t *Translator) quoteIdentifier(field string) string {
+	switch t.options.Dialect {
+	case DialectMySQL:
+		return "`" + strings.ReplaceAll(field, "`", "``") + "`"
+	case DialectSQLServer:
+		return "[" + strings.ReplaceAll(field, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+	}
+}
+
+// convertValue converts query values to driver values and applies ValueConverter if configured
+func (t *Translator) convertValue(field string, val interface{}) (interface{}, error) {
+	var baseValue interface{}
+	switch v := val.(type) {
+	case query.StringValue:
+		baseValue = string(v)
+	case query.IntValue:
+		baseValue = int64(v)
+	case query.FloatValue:
+		baseValue = float64(v)
+	case query.BoolValue:
+		baseValue = bool(v)
+	case query.DateTimeValue:
+		baseValue = time.Time(v)
+	default:
+		baseValue = val
+	}
+	return t.options.ConvertValue(field, baseValue)
+}
+
+// convertArrayValue converts an array value to a slice and applies ValueConverter if configured
+func (t *Translator) convertArrayValue(field string, val interface{}) ([]interface{}, error) {
+	arr, ok := val.(query.ArrayValue)
+	if !ok {
+		arr = query.ArrayValue{val}
+	}
+	result := make([]interface{}, len(arr))
+	for i, v := range arr {
+		converted, err := t.convertValue(field, v)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = converted
+	}
+	return result, nil
+}
+
+// isValidField validates field names to prevent SQL injection
+// Only allows alphanumeric characters and underscores, must start with letter or underscore
+func isValidField(field string) bool {
+	if len(field) == 0 {
+		return false
+	}
+	first := field[0]
+	if !((first >= 'a' && first <= 'z') || (first >= 'A' && first <= 'Z') || first == '_') {
+		return false
+	}
+	for i := 1; i < len(field); i++ {
+		c := field[i]
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-query/parser"
+	"github.com/hadi77ir/go-query/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhere_Dialects(t *testing.T) {
+	filter, err := parser.ParseFilter(`name icontains "tv" AND (price >= 10 OR brand IN [Sony, LG]) AND sku regex "^A"`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectGeneric, "(LOWER(name) LIKE LOWER(?)) AND ((price >= ?) OR (brand IN (?, ?))) AND (sku REGEXP ?)"},
+		{DialectPostgres, "(name ILIKE $1) AND ((price >= $2) OR (brand IN ($3, $4))) AND (sku ~ $5)"},
+		{DialectMySQL, "(LOWER(name) LIKE LOWER(?)) AND ((price >= ?) OR (brand IN (?, ?))) AND (sku REGEXP ?)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.String(), func(t *testing.T) {
+			where, args, err := NewTranslator(&Options{Dialect: tt.dialect}).Where(filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, where)
+			assert.Equal(t, []interface{}{"%tv%", int64(10), "Sony", "LG", "^A"}, args)
+		})
+	}
+
+	_, _, err = NewTranslator(&Options{Dialect: DialectSQLServer}).Where(filter)
+	assert.ErrorIs(t, err, query.ErrRegexNotSupported)
+}
+
+func TestWhere_Placeholders(t *testing.T) {
+	filter := query.And(query.Eq("a", 1), query.Ne("b", "x"))
+
+	where, _, err := NewTranslator(&Options{Dialect: DialectPostgres, FirstArg: 3}).Where(filter)
+	require.NoError(t, err)
+	assert.Equal(t, "(a = $3) AND (b != $4)", where)
+
+	where, _, err = NewTranslator(&Options{Dialect: DialectSQLServer, QuoteIdentifiers: true}).Where(filter)
+	require.NoError(t, err)
+	assert.Equal(t, "([a] = @p1) AND ([b] != @p2)", where)
+
+	where, _, err = NewTranslator(&Options{Dialect: DialectMySQL, QuoteIdentifiers: true}).Where(filter)
+	require.NoError(t, err)
+	assert.Equal(t, "(`a` = ?) AND (`b` != ?)", where)
+}
+
+func TestWhere_QuotedFields(t *testing.T) {
+	filter, err := parser.ParseFilter("`order date` >= 5 AND [\"tag \\\"list\\\"\"] LENGTH > 1 AND price < 9")
+	require.NoError(t, err)
+
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"order date", `tag "list"`, "price"}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, `("order date" >= $1) AND (jsonb_array_length(CAST("tag ""list""" AS jsonb)) > $2) AND (price < $3)`},
+		{DialectMySQL, "(`order date` >= ?) AND (JSON_LENGTH(`tag \"list\"`) > ?) AND (price < ?)"},
+		{DialectSQLServer, `([order date] >= @p1) AND ((SELECT COUNT(*) FROM OPENJSON([tag "list"])) > @p2) AND (price < @p3)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.String(), func(t *testing.T) {
+			where, _, err := NewTranslator(&Options{Dialect: tt.dialect, ExecutorOptions: opts}).Where(filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, where)
+		})
+	}
+
+	// Names that are not identifiers must be listed, even when every field is allowed
+	_, _, err = NewTranslator(nil).Where(query.Eq("order date", 1))
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+}
+
+func TestWhere_Operators(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.DefaultSearchFields = []string{"name", "description"}
+	tr := NewTranslator(&Options{ExecutorOptions: opts})
+
+	tests := []struct {
+		input string
+		where string
+		args  []interface{}
+	}{
+		{`name starts_with "ab"`, "name LIKE ?", []interface{}{"ab%"}},
+		{`name ends_with "ab"`, "name LIKE ?", []interface{}{"%ab"}},
+		{`name contains "ab"`, "name LIKE ?", []interface{}{"%ab%"}},
+		{`name NOT LIKE "a%"`, "name NOT LIKE ?", []interface{}{"a%"}},
+		{`id IN []`, "1 = 0", nil},
+		{`id NOT IN []`, "1 = 1", nil},
+		{`body MATCH "red shoes"`, "(LOWER(body) LIKE ? AND LOWER(body) LIKE ?)", []interface{}{"%red%", "%shoes%"}},
+		{`laptop`, "(name LIKE ?) OR (description LIKE ?)", []interface{}{"%laptop%", "%laptop%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			filter, err := parser.ParseFilter(tt.input)
+			require.NoError(t, err)
+			where, args, err := tr.Where(filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.where, where)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestWhere_ArrayModifiers(t *testing.T) {
+	tests := []struct {
+		input   string
+		dialect Dialect
+		where   string
+		args    []interface{}
+	}{
+		{"tags LENGTH > 2", DialectPostgres, "jsonb_array_length(CAST(tags AS jsonb)) > $1", []interface{}{int64(2)}},
+		{"tags LENGTH = 0", DialectSQLServer, "(SELECT COUNT(*) FROM OPENJSON(tags)) = @p1", []interface{}{int64(0)}},
+		{`tags ANY STARTS_WITH "bl"`, DialectSQLite, "EXISTS (SELECT 1 FROM json_each(tags) WHERE value LIKE ?)", []interface{}{"bl%"}},
+		{"tags ALL IN [usb, hub]", DialectMySQL,
+			"EXISTS (SELECT 1 FROM JSON_TABLE(tags, '$[*]' COLUMNS (value LONGTEXT PATH '$')) AS _elements WHERE value = ?) AND " +
+				"EXISTS (SELECT 1 FROM JSON_TABLE(tags, '$[*]' COLUMNS (value LONGTEXT PATH '$')) AS _elements WHERE value = ?)",
+			[]interface{}{"usb", "hub"}},
+		{"tags ALL IN []", DialectSQLite, "1 = 1", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.String()+" "+tt.input, func(t *testing.T) {
+			filter, err := parser.ParseFilter(tt.input)
+			require.NoError(t, err)
+			where, args, err := NewTranslator(&Options{Dialect: tt.dialect}).Where(filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.where, where)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+
+	filter, err := parser.ParseFilter("tags ANY = usb")
+	require.NoError(t, err)
+	_, _, err = NewTranslator(nil).Where(filter)
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+}
+
+func TestWhere_FullTextTemplate(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.FullTextTemplate = "%s MATCH ?"
+	where, args, err := NewTranslator(&Options{ExecutorOptions: opts, Dialect: DialectPostgres}).Where(
+		query.And(query.Eq("a", 1), query.Compare("body", query.OpMatch, "red")))
+	require.NoError(t, err)
+	assert.Equal(t, "(a = $1) AND (body MATCH $2)", where)
+	assert.Equal(t, []interface{}{int64(1), "red"}, args)
+}
+
+func TestWhere_Phrase(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.PhraseMatch = query.PhraseMatchWords
+	opts.AnchorRegex = true
+	phrase := &query.ComparisonNode{Field: query.SearchField, Operator: query.OpContains, Value: query.StringValue("usb cable"), Phrase: true}
+
+	where, args, err := NewTranslator(&Options{ExecutorOptions: opts, Dialect: DialectPostgres}).Where(phrase)
+	require.NoError(t, err)
+	assert.Equal(t, "name ~ $1", where)
+	assert.Equal(t, []interface{}{`(?:^|\W)usb\s+cable(?:\W|$)`}, args)
+
+	// SQL Server has no regular expressions and matches the phrase as a substring
+	where, args, err = NewTranslator(&Options{ExecutorOptions: opts, Dialect: DialectSQLServer}).Where(phrase)
+	require.NoError(t, err)
+	assert.Equal(t, "name LIKE @p1", where)
+	assert.Equal(t, []interface{}{"%usb cable%"}, args)
+}
+
+func TestWhere_Errors(t *testing.T) {
+	tr := NewTranslator(nil)
+	_, _, err := tr.Where(query.Eq("name; DROP TABLE users", 1))
+	assert.ErrorIs(t, err, query.ErrInvalidFieldName)
+
+	opts := query.DefaultExecutorOptions()
+	opts.AllowedFields = []string{"name"}
+	_, _, err = NewTranslator(&Options{ExecutorOptions: opts}).Where(query.Eq("secret", 1))
+	assert.ErrorIs(t, err, query.ErrFieldNotAllowed)
+
+	where, args, err := tr.Where(nil)
+	require.NoError(t, err)
+	assert.Empty(t, where)
+	assert.Nil(t, args)
+}
+
+func TestWhereQuery_BaseFilter(t *testing.T) {
+	opts := query.DefaultExecutorOptions()
+	opts.BaseFilter = query.Eq("tenant_id", 7)
+	opts.MaxConditions = 2
+	tr := NewTranslator(&Options{ExecutorOptions: opts, Dialect: DialectPostgres})
+
+	where, args, err := tr.WhereQuery(&query.Query{Filter: query.Gt("price", 5)})
+	require.NoError(t, err)
+	assert.Equal(t, "(price > $1) AND (tenant_id = $2)", where)
+	assert.Equal(t, []interface{}{int64(5), int64(7)}, args)
+
+	_, _, err = tr.WhereQuery(&query.Query{Filter: query.And(query.Eq("a", 1), query.Eq("b", 2), query.Eq("c", 3))})
+	assert.ErrorIs(t, err, query.ErrQueryTooComplex)
+}
+
+func TestParseDialect(t *testing.T) {
+	assert.Equal(t, DialectPostgres, ParseDialect("PostgreSQL"))
+	assert.Equal(t, DialectMySQL, ParseDialect("mariadb"))
+	assert.Equal(t, DialectSQLite, ParseDialect("sqlite3"))
+	assert.Equal(t, DialectSQLServer, ParseDialect("mssql"))
+	assert.Equal(t, DialectGeneric, ParseDialect(""))
+}